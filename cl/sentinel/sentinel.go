@@ -581,6 +581,16 @@ func (s *Sentinel) GossipManager() *GossipManager {
 	return s.subManager
 }
 
+// RegisterTopic advertises the node under the discv5 topic until ctx is done or the sentinel stops.
+func (s *Sentinel) RegisterTopic(ctx context.Context, topic string) {
+	s.listener.RegisterTopic(ctx, discover.NewTopic(topic))
+}
+
+// TopicNodes returns an iterator of the nodes advertised under the discv5 topic.
+func (s *Sentinel) TopicNodes(topic string) enode.Iterator {
+	return s.listener.TopicNodes(discover.NewTopic(topic))
+}
+
 func (s *Sentinel) Config() *SentinelConfig {
 	return s.cfg
 }
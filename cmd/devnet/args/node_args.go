@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"net"
 	"path/filepath"
+	"slices"
 	"strconv"
 
 	"github.com/erigontech/erigon-lib/chain/networkname"
@@ -175,6 +176,23 @@ func (m *BlockProducer) Configure(baseNode NodeArgs, nodeNumber int) error {
 		core.DevnetEtherbase = m.account.Address
 		core.DevnetSignPrivateKey = m.account.SigKey()
 
+	case networkname.CliqueDevnet:
+		if m.DevPeriod == 0 {
+			m.DevPeriod = 5
+		}
+		m.account = accounts.NewAccount(m.GetName() + "-etherbase")
+		if !slices.Contains(core.CliqueDevnetSigners, m.account.Address) {
+			core.CliqueDevnetSigners = append(core.CliqueDevnetSigners, m.account.Address)
+		}
+		m.HttpApi += ",clique"
+
+	case networkname.QBFTDevnet:
+		m.account = accounts.NewAccount(m.GetName() + "-etherbase")
+		if !slices.Contains(core.QBFTDevnetValidators, m.account.Address) {
+			core.QBFTDevnetValidators = append(core.QBFTDevnetValidators, m.account.Address)
+		}
+		m.HttpApi += ",qbft"
+
 	case networkname.BorDevnet:
 		m.account = accounts.NewAccount(m.GetName() + "-etherbase")
 
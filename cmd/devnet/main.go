@@ -355,6 +355,19 @@ func allScenarios(cliCtx *cli.Context, runCtx devnet.Context) scenarios.Scenario
 				{Text: "SendTxLoad", Args: []any{recipientAddress, accounts.DevAddress, sendValue, cliCtx.Uint(txCountFlag.Name)}},
 			},
 		},
+		"clique-signers": {
+			Context: runCtx.WithCurrentNetwork(0),
+			Steps: []*scenarios.Step{
+				{Text: "PingErigonRpc"},
+				{Text: "CheckCliqueSigners"},
+				{Text: "ProposeCliqueSigner", Args: []any{"clique-signer", true}},
+				{Text: "AwaitCliqueSigner", Args: []any{"clique-signer", true, 2 * time.Minute}},
+				{Text: "ProposeCliqueSigner", Args: []any{"clique-signer", false}},
+				{Text: "AwaitCliqueSigner", Args: []any{"clique-signer", false, 2 * time.Minute}},
+				{Text: "CheckCliqueSigners"},
+				{Text: "CheckCliqueRotation", Args: []any{6}},
+			},
+		},
 	}
 }
 
@@ -433,6 +446,12 @@ func initDevnet(ctx *cli.Context, logger log.Logger) (devnet.Devnet, error) {
 	case networkname.Dev:
 		return networks.NewDevDevnet(dataDir, baseRpcHost, baseRpcPort, producerCount, gasLimit, logger, consoleLogLevel, dirLogLevel), nil
 
+	case networkname.CliqueDevnet:
+		return networks.NewCliqueDevnet(dataDir, baseRpcHost, baseRpcPort, producerCount, gasLimit, logger, consoleLogLevel, dirLogLevel), nil
+
+	case networkname.QBFTDevnet:
+		return networks.NewQBFTDevnet(dataDir, baseRpcHost, baseRpcPort, producerCount, gasLimit, logger, consoleLogLevel, dirLogLevel), nil
+
 	default:
 		return nil, fmt.Errorf("unknown network: '%s'", chainName)
 	}
@@ -0,0 +1,62 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package requests
+
+import (
+	"context"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/rpc"
+)
+
+func (reqGen *requestGenerator) CliqueGetSigners(ctx context.Context, blockNum rpc.BlockNumber) ([]libcommon.Address, error) {
+	var result []libcommon.Address
+
+	if err := reqGen.rpcCall(ctx, &result, Methods.CliqueGetSigners, blockNum); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (reqGen *requestGenerator) CliqueGetSigner(ctx context.Context, blockNum rpc.BlockNumber) (libcommon.Address, error) {
+	var result libcommon.Address
+
+	if err := reqGen.rpcCall(ctx, &result, Methods.CliqueGetSigner, blockNum); err != nil {
+		return libcommon.Address{}, err
+	}
+
+	return result, nil
+}
+
+func (reqGen *requestGenerator) CliqueProposals(ctx context.Context) (map[libcommon.Address]bool, error) {
+	var result map[libcommon.Address]bool
+
+	if err := reqGen.rpcCall(ctx, &result, Methods.CliqueProposals); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (reqGen *requestGenerator) CliquePropose(ctx context.Context, address libcommon.Address, authorize bool) error {
+	return reqGen.rpcCall(ctx, nil, Methods.CliquePropose, address, authorize)
+}
+
+func (reqGen *requestGenerator) CliqueDiscard(ctx context.Context, address libcommon.Address) error {
+	return reqGen.rpcCall(ctx, nil, Methods.CliqueDiscard, address)
+}
@@ -118,3 +118,23 @@ func (n NopRequestGenerator) GasPrice() (*big.Int, error) {
 func (n NopRequestGenerator) GetRootHash(ctx context.Context, startBlock uint64, endBlock uint64) (libcommon.Hash, error) {
 	return libcommon.Hash{}, ErrNotImplemented
 }
+
+func (n NopRequestGenerator) CliqueGetSigners(ctx context.Context, blockNum rpc.BlockNumber) ([]libcommon.Address, error) {
+	return nil, ErrNotImplemented
+}
+
+func (n NopRequestGenerator) CliqueGetSigner(ctx context.Context, blockNum rpc.BlockNumber) (libcommon.Address, error) {
+	return libcommon.Address{}, ErrNotImplemented
+}
+
+func (n NopRequestGenerator) CliqueProposals(ctx context.Context) (map[libcommon.Address]bool, error) {
+	return nil, ErrNotImplemented
+}
+
+func (n NopRequestGenerator) CliquePropose(ctx context.Context, address libcommon.Address, authorize bool) error {
+	return ErrNotImplemented
+}
+
+func (n NopRequestGenerator) CliqueDiscard(ctx context.Context, address libcommon.Address) error {
+	return ErrNotImplemented
+}
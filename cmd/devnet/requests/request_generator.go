@@ -91,6 +91,12 @@ type RequestGenerator interface {
 	GasPrice() (*big.Int, error)
 
 	GetRootHash(ctx context.Context, startBlock uint64, endBlock uint64) (libcommon.Hash, error)
+
+	CliqueGetSigners(ctx context.Context, blockNum rpc.BlockNumber) ([]libcommon.Address, error)
+	CliqueGetSigner(ctx context.Context, blockNum rpc.BlockNumber) (libcommon.Address, error)
+	CliqueProposals(ctx context.Context) (map[libcommon.Address]bool, error)
+	CliquePropose(ctx context.Context, address libcommon.Address, authorize bool) error
+	CliqueDiscard(ctx context.Context, address libcommon.Address) error
 }
 
 type requestGenerator struct {
@@ -144,6 +150,11 @@ var Methods = struct {
 	ETHGetTransactionReceipt RPCMethod
 	BorGetRootHash           RPCMethod
 	ETHCall                  RPCMethod
+	CliqueGetSigners         RPCMethod
+	CliqueGetSigner          RPCMethod
+	CliqueProposals          RPCMethod
+	CliquePropose            RPCMethod
+	CliqueDiscard            RPCMethod
 }{
 	ETHGetTransactionCount:   "eth_getTransactionCount",
 	ETHGetBalance:            "eth_getBalance",
@@ -167,6 +178,11 @@ var Methods = struct {
 	ETHGetTransactionReceipt: "eth_getTransactionReceipt",
 	BorGetRootHash:           "bor_getRootHash",
 	ETHCall:                  "eth_call",
+	CliqueGetSigners:         "clique_getSigners",
+	CliqueGetSigner:          "clique_getSigner",
+	CliqueProposals:          "clique_proposals",
+	CliquePropose:            "clique_propose",
+	CliqueDiscard:            "clique_discard",
 }
 
 func (req *requestGenerator) rpcCallJSON(method RPCMethod, body string, response interface{}) callResult {
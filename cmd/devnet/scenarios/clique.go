@@ -0,0 +1,205 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package scenarios
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/cmd/devnet/accounts"
+	"github.com/erigontech/erigon/cmd/devnet/devnet"
+	"github.com/erigontech/erigon/rpc"
+)
+
+func init() {
+	MustRegisterStepHandlers(
+		StepHandler(CheckCliqueSigners),
+		StepHandler(ProposeCliqueSigner),
+		StepHandler(DiscardCliqueProposal),
+		StepHandler(AwaitCliqueSigner),
+		StepHandler(CheckCliqueRotation),
+	)
+}
+
+// cliqueAddress resolves a named devnet account or a hex address, unknown names
+// create a new account so that signers which don't run a node can be voted in
+func cliqueAddress(account string) libcommon.Address {
+	if acc := accounts.GetAccount(account); acc != nil {
+		return acc.Address
+	}
+
+	if libcommon.IsHexAddress(account) {
+		return libcommon.HexToAddress(account)
+	}
+
+	return accounts.NewAccount(account).Address
+}
+
+// CheckCliqueSigners checks that the latest block is authorized by the accounts
+// of the network's block producers and no one else
+func CheckCliqueSigners(ctx context.Context) error {
+	signers, err := devnet.SelectNode(ctx).CliqueGetSigners(ctx, rpc.LatestBlockNumber)
+
+	if err != nil {
+		return fmt.Errorf("failed to get clique signers: %w", err)
+	}
+
+	var producers []libcommon.Address
+
+	for _, node := range devnet.CurrentNetwork(ctx).Nodes {
+		if node.IsBlockProducer() && node.Account() != nil {
+			producers = append(producers, node.Account().Address)
+		}
+	}
+
+	if len(signers) != len(producers) {
+		return fmt.Errorf("expected %d clique signers, got %d: %v", len(producers), len(signers), signers)
+	}
+
+	for _, producer := range producers {
+		if !slices.Contains(signers, producer) {
+			return fmt.Errorf("block producer %s is not a clique signer", producer)
+		}
+	}
+
+	devnet.Logger(ctx).Info("Clique signers", "count", len(signers), "signers", signers)
+	return nil
+}
+
+// ProposeCliqueSigner makes every block producer vote for adding (authorize) or
+// removing the signer
+func ProposeCliqueSigner(ctx context.Context, account string, authorize bool) error {
+	address := cliqueAddress(account)
+
+	for _, node := range devnet.CurrentNetwork(ctx).Nodes {
+		if !node.IsBlockProducer() {
+			continue
+		}
+
+		if err := node.CliquePropose(ctx, address, authorize); err != nil {
+			return fmt.Errorf("node %s failed to propose %s: %w", node.GetName(), address, err)
+		}
+	}
+
+	devnet.Logger(ctx).Info("Proposed clique signer", "address", address, "authorize", authorize)
+	return nil
+}
+
+// DiscardCliqueProposal drops the pending vote for the signer on every block producer
+func DiscardCliqueProposal(ctx context.Context, account string) error {
+	address := cliqueAddress(account)
+
+	for _, node := range devnet.CurrentNetwork(ctx).Nodes {
+		if !node.IsBlockProducer() {
+			continue
+		}
+
+		if err := node.CliqueDiscard(ctx, address); err != nil {
+			return fmt.Errorf("node %s failed to discard %s: %w", node.GetName(), address, err)
+		}
+	}
+
+	return nil
+}
+
+// AwaitCliqueSigner waits until the votes in flight add (authorized) or remove
+// the signer from the signer set
+func AwaitCliqueSigner(ctx context.Context, account string, authorized bool, timeout time.Duration) error {
+	node := devnet.SelectNode(ctx)
+	address := cliqueAddress(account)
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		signers, err := node.CliqueGetSigners(ctx, rpc.LatestBlockNumber)
+
+		if err == nil && slices.Contains(signers, address) == authorized {
+			devnet.Logger(ctx).Info("Clique signers updated", "address", address, "authorized", authorized, "signers", signers)
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			if err != nil {
+				return fmt.Errorf("timed out waiting for clique signer %s: %w", address, err)
+			}
+			return fmt.Errorf("timed out waiting for clique signer %s, authorized: %t, signers: %v", address, authorized, signers)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// CheckCliqueRotation checks that the last blocks were sealed by authorized
+// signers which took turns, no signer may seal twice within the recents window
+func CheckCliqueRotation(ctx context.Context, blocks int) error {
+	node := devnet.SelectNode(ctx)
+
+	latest, err := node.BlockNumber()
+
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	if latest < uint64(blocks) {
+		return fmt.Errorf("chain too short to check rotation: %d < %d", latest, blocks)
+	}
+
+	sealed := map[libcommon.Address]int{}
+	recent := map[libcommon.Address]uint64{}
+
+	for number := latest - uint64(blocks) + 1; number <= latest; number++ {
+		blockNum := rpc.BlockNumber(number)
+
+		signer, err := node.CliqueGetSigner(ctx, blockNum)
+
+		if err != nil {
+			return fmt.Errorf("failed to get signer of block %d: %w", number, err)
+		}
+
+		// the signers authorized to seal the block are the ones of its parent
+		signers, err := node.CliqueGetSigners(ctx, blockNum-1)
+
+		if err != nil {
+			return fmt.Errorf("failed to get signers of block %d: %w", number-1, err)
+		}
+
+		if !slices.Contains(signers, signer) {
+			return fmt.Errorf("block %d sealed by unauthorized signer %s", number, signer)
+		}
+
+		if last, ok := recent[signer]; ok && number-last < uint64(len(signers)/2+1) {
+			return fmt.Errorf("signer %s sealed blocks %d and %d, recently signed", signer, last, number)
+		}
+
+		recent[signer] = number
+		sealed[signer]++
+	}
+
+	if len(sealed) < 2 {
+		return fmt.Errorf("signers don't rotate, %d blocks sealed by %v", blocks, sealed)
+	}
+
+	devnet.Logger(ctx).Info("Clique rotation", "blocks", blocks, "sealed", sealed)
+	return nil
+}
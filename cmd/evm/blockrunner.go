@@ -0,0 +1,140 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/tracers/logger"
+	"github.com/erigontech/erigon/tests"
+)
+
+var RunFlag = cli.StringFlag{
+	Name:  "run",
+	Value: ".*",
+	Usage: "Run only those tests matching the regular expression.",
+}
+
+var blockTestCommand = cli.Command{
+	Action:    blockTestCmd,
+	Name:      "blocktest",
+	Usage:     "executes the given blockchain tests",
+	ArgsUsage: "<file>",
+	Flags:     []cli.Flag{&RunFlag},
+}
+
+// BlocktestResult contains the execution status after running a blockchain test
+type BlocktestResult struct {
+	Name  string `json:"name"`
+	Pass  bool   `json:"pass"`
+	Fork  string `json:"fork"`
+	Error string `json:"error,omitempty"`
+}
+
+func blockTestCmd(ctx *cli.Context) error {
+	machineFriendlyOutput := ctx.Bool(MachineFlag.Name)
+	if machineFriendlyOutput {
+		log.Root().SetHandler(log.DiscardHandler())
+	} else {
+		log.Root().SetHandler(log.LvlFilterHandler(log.LvlWarn, log.StderrHandler))
+	}
+	re, err := regexp.Compile(ctx.String(RunFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid regex -%s: %v", RunFlag.Name, err)
+	}
+
+	// Configure the EVM logger: traces of all executed txns go to stderr
+	cfg := &vm.Config{SuperInstructions: ctx.Bool(SuperInstructionsFlag.Name)}
+	if machineFriendlyOutput {
+		config := &logger.LogConfig{
+			DisableMemory:     ctx.Bool(DisableMemoryFlag.Name),
+			DisableStack:      ctx.Bool(DisableStackFlag.Name),
+			DisableStorage:    ctx.Bool(DisableStorageFlag.Name),
+			DisableReturnData: ctx.Bool(DisableReturnDataFlag.Name),
+		}
+		cfg.Tracer = logger.NewJSONLogger(config, os.Stderr).Tracer().Hooks
+	}
+
+	if len(ctx.Args().First()) != 0 {
+		return runBlockTest(ctx.Args().First(), re, cfg)
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fname := scanner.Text()
+		if len(fname) == 0 {
+			return nil
+		}
+		if err := runBlockTest(fname, re, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runBlockTest loads the blockchain tests given by fname, and executes the tests matching re.
+func runBlockTest(fname string, re *regexp.Regexp, cfg *vm.Config) error {
+	src, err := os.ReadFile(fname)
+	if err != nil {
+		return err
+	}
+	var blockTests map[string]*tests.BlockTest
+	if err = json.Unmarshal(src, &blockTests); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(blockTests))
+	for name := range blockTests {
+		if re.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	results := make([]BlocktestResult, 0, len(names))
+	for _, name := range names {
+		test := blockTests[name]
+		result := BlocktestResult{Name: name, Fork: test.Network(), Pass: true}
+		if err := runBlockTestCase(test, cfg); err != nil {
+			result.Pass, result.Error = false, err.Error()
+		}
+		results = append(results, result)
+	}
+
+	out, _ := json.MarshalIndent(results, "", "  ")
+	fmt.Println(string(out))
+	return nil
+}
+
+func runBlockTestCase(test *tests.BlockTest, cfg *vm.Config) (err error) {
+	// the mock backing the test panics on setup failures when it runs without testing.TB
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%v", rec)
+		}
+	}()
+	return test.RunWithVMConfig(nil, true /* checkStateRoot */, cfg)
+}
@@ -0,0 +1,411 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package t8ntool
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/math"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+
+	"github.com/erigontech/erigon/consensus/clique"
+	"github.com/erigontech/erigon/consensus/ethash"
+	"github.com/erigontech/erigon/consensus/ethash/ethashcfg"
+	"github.com/erigontech/erigon/core/types"
+)
+
+const (
+	ErrorRlp    = 12
+	ErrorConfig = 13
+)
+
+// bbHeader - header of block to build. Roots of txns, ommers and withdrawals are derived from
+// the block body if not given.
+type bbHeader struct {
+	ParentHash            libcommon.Hash        `json:"parentHash"`
+	OmmerHash             *libcommon.Hash       `json:"sha3Uncles"`
+	Coinbase              libcommon.Address     `json:"miner"`
+	Root                  libcommon.Hash        `json:"stateRoot"`
+	TxHash                *libcommon.Hash       `json:"transactionsRoot"`
+	ReceiptHash           libcommon.Hash        `json:"receiptsRoot"`
+	Bloom                 types.Bloom           `json:"logsBloom"`
+	Difficulty            *math.HexOrDecimal256 `json:"difficulty"`
+	Number                *math.HexOrDecimal256 `json:"number"`
+	GasLimit              math.HexOrDecimal64   `json:"gasLimit"`
+	GasUsed               math.HexOrDecimal64   `json:"gasUsed"`
+	Time                  math.HexOrDecimal64   `json:"timestamp"`
+	Extra                 hexutil.Bytes         `json:"extraData"`
+	MixDigest             libcommon.Hash        `json:"mixHash"`
+	Nonce                 types.BlockNonce      `json:"nonce"`
+	BaseFee               *math.HexOrDecimal256 `json:"baseFeePerGas"`
+	WithdrawalsHash       *libcommon.Hash       `json:"withdrawalsRoot"`
+	BlobGasUsed           *math.HexOrDecimal64  `json:"blobGasUsed"`
+	ExcessBlobGas         *math.HexOrDecimal64  `json:"excessBlobGas"`
+	ParentBeaconBlockRoot *libcommon.Hash       `json:"parentBeaconBlockRoot"`
+	RequestsHash          *libcommon.Hash       `json:"requestsHash"`
+}
+
+type bbInput struct {
+	Header      *bbHeader           `json:"header,omitempty"`
+	OmmersRlp   []string            `json:"ommers,omitempty"`
+	TxRlp       string              `json:"txs,omitempty"`
+	Withdrawals []*types.Withdrawal `json:"withdrawals,omitempty"`
+	Clique      *cliqueInput        `json:"clique,omitempty"`
+
+	Ethash    bool                `json:"-"`
+	EthashDir string              `json:"-"`
+	PowMode   ethashcfg.Mode      `json:"-"`
+	Ommers    []*types.Header     `json:"-"`
+	Txs       []types.Transaction `json:"-"`
+}
+
+type cliqueInput struct {
+	Key       *ecdsa.PrivateKey
+	Voted     *libcommon.Address
+	Authorize *bool
+	Vanity    libcommon.Hash
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (c *cliqueInput) UnmarshalJSON(input []byte) error {
+	var x struct {
+		Key       *libcommon.Hash    `json:"secretKey"`
+		Voted     *libcommon.Address `json:"voted"`
+		Authorize *bool              `json:"authorize"`
+		Vanity    libcommon.Hash     `json:"vanity"`
+	}
+	if err := json.Unmarshal(input, &x); err != nil {
+		return err
+	}
+	if x.Key == nil {
+		return errors.New("missing required field 'secretKey' for cliqueInput")
+	}
+	k, err := crypto.ToECDSA(x.Key[:])
+	if err != nil {
+		return err
+	}
+	c.Key = k
+	c.Voted = x.Voted
+	c.Authorize = x.Authorize
+	c.Vanity = x.Vanity
+	return nil
+}
+
+// ToBlock - converts input into block, deriving missing roots from body
+func (i *bbInput) ToBlock() *types.Block {
+	h := i.Header
+	header := &types.Header{
+		ParentHash:            h.ParentHash,
+		UncleHash:             types.EmptyUncleHash,
+		Coinbase:              h.Coinbase,
+		Root:                  h.Root,
+		TxHash:                types.EmptyRootHash,
+		ReceiptHash:           h.ReceiptHash,
+		Bloom:                 h.Bloom,
+		Difficulty:            new(big.Int),
+		Number:                new(big.Int),
+		GasLimit:              uint64(h.GasLimit),
+		GasUsed:               uint64(h.GasUsed),
+		Time:                  uint64(h.Time),
+		Extra:                 h.Extra,
+		MixDigest:             h.MixDigest,
+		Nonce:                 h.Nonce,
+		WithdrawalsHash:       h.WithdrawalsHash,
+		ParentBeaconBlockRoot: h.ParentBeaconBlockRoot,
+		RequestsHash:          h.RequestsHash,
+	}
+	if h.Difficulty != nil {
+		header.Difficulty = (*big.Int)(h.Difficulty)
+	}
+	if h.Number != nil {
+		header.Number = (*big.Int)(h.Number)
+	}
+	if h.BaseFee != nil {
+		header.BaseFee = (*big.Int)(h.BaseFee)
+	}
+	if h.BlobGasUsed != nil {
+		header.BlobGasUsed = (*uint64)(h.BlobGasUsed)
+	}
+	if h.ExcessBlobGas != nil {
+		header.ExcessBlobGas = (*uint64)(h.ExcessBlobGas)
+	}
+
+	if h.OmmerHash != nil {
+		header.UncleHash = *h.OmmerHash
+	} else if len(i.Ommers) != 0 {
+		header.UncleHash = types.CalcUncleHash(i.Ommers)
+	}
+	if h.TxHash != nil {
+		header.TxHash = *h.TxHash
+	} else if len(i.Txs) != 0 {
+		header.TxHash = types.DeriveSha(types.Transactions(i.Txs))
+	}
+	if h.WithdrawalsHash == nil && i.Withdrawals != nil {
+		withdrawalsHash := types.DeriveSha(types.Withdrawals(i.Withdrawals))
+		header.WithdrawalsHash = &withdrawalsHash
+	}
+	return types.NewBlockFromNetwork(header, &types.Body{Transactions: i.Txs, Uncles: i.Ommers, Withdrawals: i.Withdrawals})
+}
+
+// sealClique - signs header by clique signer, vote (if any) is put into coinbase and nonce
+func (i *bbInput) sealClique(block *types.Block) (*types.Block, error) {
+	header := block.Header()
+	if i.Clique.Voted != nil {
+		if i.Header.Coinbase != (libcommon.Address{}) {
+			return nil, NewError(ErrorConfig, errors.New("sealing with clique will overwrite provided coinbase"))
+		}
+		header.Coinbase = *i.Clique.Voted
+	}
+	if i.Clique.Authorize != nil {
+		if i.Header.Nonce != (types.BlockNonce{}) {
+			return nil, NewError(ErrorConfig, errors.New("sealing with clique and voting will overwrite provided nonce"))
+		}
+		if *i.Clique.Authorize {
+			header.Nonce = types.BlockNonce{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+		} else {
+			header.Nonce = types.BlockNonce{}
+		}
+	}
+	// Extra is vanity (32 bytes) + signers (only at checkpoints) + seal (65 bytes)
+	if len(header.Extra) != 0 {
+		return nil, NewError(ErrorConfig, errors.New("sealing with clique will overwrite provided extra data"))
+	}
+	header.Extra = make([]byte, clique.ExtraVanity+clique.ExtraSeal)
+	copy(header.Extra, i.Clique.Vanity[:])
+
+	sighash, err := crypto.Sign(clique.SealHash(header).Bytes(), i.Clique.Key)
+	if err != nil {
+		return nil, NewError(ErrorConfig, fmt.Errorf("failed to sign header: %w", err))
+	}
+	copy(header.Extra[len(header.Extra)-clique.ExtraSeal:], sighash)
+	return block.WithSeal(header), nil
+}
+
+// sealEthash - searches for the ethash nonce and mix digest of the block
+func (i *bbInput) sealEthash(block *types.Block) (*types.Block, error) {
+	if i.Header.Nonce != (types.BlockNonce{}) {
+		return nil, NewError(ErrorConfig, errors.New("sealing with ethash will overwrite provided nonce"))
+	}
+	if i.Header.MixDigest != (libcommon.Hash{}) {
+		return nil, NewError(ErrorConfig, errors.New("sealing with ethash will overwrite provided mixHash"))
+	}
+	engine := ethash.New(ethashcfg.Config{
+		CachesInMem:    2,
+		DatasetDir:     i.EthashDir,
+		DatasetsInMem:  1,
+		DatasetsOnDisk: 2,
+		PowMode:        i.PowMode,
+	}, nil, false)
+	defer engine.Close()
+	header, err := engine.Mine(block.Header())
+	if err != nil {
+		return nil, NewError(ErrorConfig, fmt.Errorf("failed to seal block: %w", err))
+	}
+	return block.WithSeal(header), nil
+}
+
+// BuildBlock - b11r: assembles block of given header, ommers, txns and withdrawals, optionally seals it
+// and outputs its RLP and hash.
+func BuildBlock(ctx *cli.Context) error {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StderrHandler))
+	baseDir, err := createBasedir(ctx)
+	if err != nil {
+		return NewError(ErrorIO, fmt.Errorf("failed creating output basedir: %v", err))
+	}
+	inputData, err := readBbInput(ctx)
+	if err != nil {
+		return err
+	}
+	block := inputData.ToBlock()
+	if inputData.Ethash {
+		if block, err = inputData.sealEthash(block); err != nil {
+			return err
+		}
+	} else if inputData.Clique != nil {
+		if block, err = inputData.sealClique(block); err != nil {
+			return err
+		}
+	}
+	return dispatchBlock(ctx, baseDir, block)
+}
+
+func createBasedir(ctx *cli.Context) (string, error) {
+	baseDir := ""
+	if ctx.IsSet(OutputBasedir.Name) {
+		if base := ctx.String(OutputBasedir.Name); len(base) > 0 {
+			if err := os.MkdirAll(base, 0755); err != nil {
+				return "", err
+			}
+			baseDir = base
+		}
+	}
+	return baseDir, nil
+}
+
+func readBbInput(ctx *cli.Context) (*bbInput, error) {
+	var (
+		headerStr      = ctx.String(InputHeaderFlag.Name)
+		ommersStr      = ctx.String(InputOmmersFlag.Name)
+		withdrawalsStr = ctx.String(InputWithdrawalsFlag.Name)
+		txsStr         = ctx.String(InputTxsRlpFlag.Name)
+		cliqueStr      = ctx.String(SealCliqueFlag.Name)
+		inputData      = &bbInput{}
+	)
+	if headerStr == stdinSelector || ommersStr == stdinSelector || txsStr == stdinSelector || cliqueStr == stdinSelector || withdrawalsStr == stdinSelector {
+		decoder := json.NewDecoder(os.Stdin)
+		if err := decoder.Decode(inputData); err != nil {
+			return nil, NewError(ErrorJson, fmt.Errorf("failed unmarshaling input: %v", err))
+		}
+	}
+	if ctx.Bool(SealEthashFlag.Name) {
+		inputData.Ethash = true
+		inputData.EthashDir = ctx.String(SealEthashDirFlag.Name)
+		switch mode := ctx.String(SealEthashModeFlag.Name); mode {
+		case "normal":
+			inputData.PowMode = ethashcfg.ModeNormal
+		case "test":
+			inputData.PowMode = ethashcfg.ModeTest
+		case "fake":
+			inputData.PowMode = ethashcfg.ModeFake
+		default:
+			return nil, NewError(ErrorConfig, fmt.Errorf("unknown pow mode: %s, supported modes: test, fake, normal", mode))
+		}
+	}
+	if cliqueStr != stdinSelector && cliqueStr != "" {
+		var clique cliqueInput
+		if err := readFile(cliqueStr, "clique", &clique); err != nil {
+			return nil, err
+		}
+		inputData.Clique = &clique
+	}
+	if inputData.Ethash && inputData.Clique != nil {
+		return nil, NewError(ErrorConfig, errors.New("both ethash and clique sealing specified, only one may be chosen"))
+	}
+	if headerStr != stdinSelector {
+		var header bbHeader
+		if err := readFile(headerStr, "header", &header); err != nil {
+			return nil, err
+		}
+		inputData.Header = &header
+	}
+	if inputData.Header == nil {
+		return nil, NewError(ErrorJson, errors.New("header is missing"))
+	}
+	if ommersStr != stdinSelector && ommersStr != "" {
+		if err := readFile(ommersStr, "ommers", &inputData.OmmersRlp); err != nil {
+			return nil, err
+		}
+	}
+	if withdrawalsStr != stdinSelector && withdrawalsStr != "" {
+		var withdrawals []*types.Withdrawal
+		if err := readFile(withdrawalsStr, "withdrawals", &withdrawals); err != nil {
+			return nil, err
+		}
+		inputData.Withdrawals = withdrawals
+	}
+	if txsStr != stdinSelector && txsStr != "" {
+		var txs string
+		if err := readFile(txsStr, "txs", &txs); err != nil {
+			return nil, err
+		}
+		inputData.TxRlp = txs
+	}
+
+	if err := decodeBbBody(inputData); err != nil {
+		return nil, err
+	}
+	return inputData, nil
+}
+
+// decodeBbBody - decodes RLP of ommers and txns
+func decodeBbBody(bb *bbInput) error {
+	for _, str := range bb.OmmersRlp {
+		var ommer types.Header
+		if err := rlp.DecodeBytes(libcommon.FromHex(str), &ommer); err != nil {
+			return NewError(ErrorRlp, fmt.Errorf("unable to decode ommer: %v", err))
+		}
+		bb.Ommers = append(bb.Ommers, &ommer)
+	}
+	if len(bb.TxRlp) != 0 {
+		// txns are encoded as in block body: rlp list of legacy txns and rlp strings of typed txns
+		var rawTxs []rlp.RawValue
+		if err := rlp.DecodeBytes(libcommon.FromHex(strings.TrimSpace(bb.TxRlp)), &rawTxs); err != nil {
+			return NewError(ErrorRlp, fmt.Errorf("unable to decode transactions from rlp data: %v", err))
+		}
+		for _, raw := range rawTxs {
+			txn, err := types.DecodeTransaction(raw)
+			if err != nil {
+				return NewError(ErrorRlp, fmt.Errorf("unable to decode transaction: %v", err))
+			}
+			bb.Txs = append(bb.Txs, txn)
+		}
+	}
+	return nil
+}
+
+func readFile(path, desc string, dest interface{}) error {
+	inFile, err := os.Open(path)
+	if err != nil {
+		return NewError(ErrorIO, fmt.Errorf("failed reading %s file: %v", desc, err))
+	}
+	defer inFile.Close()
+	if err = json.NewDecoder(inFile).Decode(dest); err != nil {
+		return NewError(ErrorJson, fmt.Errorf("failed unmarshaling %s file: %v", desc, err))
+	}
+	return nil
+}
+
+// dispatchBlock writes the output data to either stderr or stdout, or to the specified file
+func dispatchBlock(ctx *cli.Context, baseDir string, block *types.Block) error {
+	raw, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return NewError(ErrorRlp, fmt.Errorf("failed encoding block: %v", err))
+	}
+	enc := struct {
+		Rlp  hexutil.Bytes  `json:"rlp"`
+		Hash libcommon.Hash `json:"hash"`
+	}{raw, block.Hash()}
+	b, err := json.MarshalIndent(enc, "", "  ")
+	if err != nil {
+		return NewError(ErrorJson, fmt.Errorf("failed marshalling output: %v", err))
+	}
+	switch dest := ctx.String(OutputBlockFlag.Name); dest {
+	case "stdout":
+		os.Stdout.Write(b)
+		os.Stdout.WriteString("\n")
+	case "stderr":
+		os.Stderr.Write(b)
+		os.Stderr.WriteString("\n")
+	default:
+		if err := saveFile(baseDir, dest, enc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
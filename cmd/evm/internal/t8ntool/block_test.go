@@ -0,0 +1,98 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package t8ntool
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/math"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/rlp"
+
+	"github.com/erigontech/erigon/consensus/clique"
+	"github.com/erigontech/erigon/consensus/ethash"
+	"github.com/erigontech/erigon/consensus/ethash/ethashcfg"
+	"github.com/erigontech/erigon/core/types"
+)
+
+func TestBuildBlock(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	to := libcommon.HexToAddress("0xaaaa")
+	txn, err := types.SignTx(types.NewTransaction(0, to, uint256.NewInt(1), 21000, uint256.NewInt(10), nil), *types.LatestSignerForChainID(nil), key)
+	require.NoError(t, err)
+	txsRlp, err := rlp.EncodeToBytes(types.Transactions{txn})
+	require.NoError(t, err)
+
+	input := fmt.Sprintf(`{
+		"header": {"parentHash": "0x%x", "number": "0x1", "gasLimit": "0x1000000", "timestamp": "0x10", "baseFeePerGas": "0x7"},
+		"txs": "0x%x",
+		"withdrawals": [{"index": "0x0", "validatorIndex": "0x1", "address": "0x000000000000000000000000000000000000bbbb", "amount": "0x2"}]
+	}`, libcommon.Hash{1}, txsRlp)
+	var bb bbInput
+	require.NoError(t, json.Unmarshal([]byte(input), &bb))
+	require.NoError(t, decodeBbBody(&bb))
+
+	block := bb.ToBlock()
+	require.Equal(t, types.DeriveSha(types.Transactions{txn}), block.TxHash())
+	require.Equal(t, types.EmptyUncleHash, block.UncleHash())
+	require.NotNil(t, block.Header().WithdrawalsHash)
+	require.Equal(t, uint64(7), block.BaseFee().Uint64())
+
+	// RLP of block decodes into the same block
+	enc, err := rlp.EncodeToBytes(block)
+	require.NoError(t, err)
+	var decoded types.Block
+	require.NoError(t, rlp.DecodeBytes(enc, &decoded))
+	require.Equal(t, block.Hash(), decoded.Hash())
+	require.Equal(t, txn.Hash(), decoded.Transactions()[0].Hash())
+
+	// clique seal is made by given key
+	bb.Clique = &cliqueInput{Key: key}
+	sealed, err := bb.sealClique(block)
+	require.NoError(t, err)
+	extra := sealed.Extra()
+	require.Len(t, extra, clique.ExtraVanity+clique.ExtraSeal)
+	pub, err := crypto.SigToPub(clique.SealHash(sealed.Header()).Bytes(), extra[len(extra)-clique.ExtraSeal:])
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(*pub))
+}
+
+func TestSealEthash(t *testing.T) {
+	bb := bbInput{
+		Header:  &bbHeader{Number: (*math.HexOrDecimal256)(big.NewInt(1)), Difficulty: (*math.HexOrDecimal256)(big.NewInt(1000)), GasLimit: 0x1000000},
+		Ethash:  true,
+		PowMode: ethashcfg.ModeTest,
+	}
+	sealed, err := bb.sealEthash(bb.ToBlock())
+	require.NoError(t, err)
+
+	engine := ethash.NewTester(nil, false)
+	defer engine.Close()
+	require.NoError(t, engine.VerifySeal(nil, sealed.Header()))
+
+	// provided nonce is not overwritten
+	bb.Header.Nonce = types.EncodeNonce(1)
+	_, err = bb.sealEthash(bb.ToBlock())
+	require.Error(t, err)
+}
@@ -133,6 +133,10 @@ var (
 		Name:  "noreturndata",
 		Usage: "disable return data output",
 	}
+	SuperInstructionsFlag = cli.BoolFlag{
+		Name:  "superinstructions",
+		Usage: "run contracts by pre-decoded instruction stream with fused instructions (ignored when tracing)",
+	}
 )
 
 var stateTransitionCommand = cli.Command{
@@ -158,6 +162,26 @@ var stateTransitionCommand = cli.Command{
 	},
 }
 
+var blockBuilderCommand = cli.Command{
+	Name:    "block-builder",
+	Aliases: []string{"b11r"},
+	Usage:   "builds a block",
+	Action:  t8ntool.BuildBlock,
+	Flags: []cli.Flag{
+		&t8ntool.OutputBasedir,
+		&t8ntool.OutputBlockFlag,
+		&t8ntool.InputHeaderFlag,
+		&t8ntool.InputOmmersFlag,
+		&t8ntool.InputWithdrawalsFlag,
+		&t8ntool.InputTxsRlpFlag,
+		&t8ntool.SealCliqueFlag,
+		&t8ntool.SealEthashFlag,
+		&t8ntool.SealEthashDirFlag,
+		&t8ntool.SealEthashModeFlag,
+		&t8ntool.VerbosityFlag,
+	},
+}
+
 func init() {
 	app.Flags = []cli.Flag{
 		&BenchFlag,
@@ -183,8 +207,11 @@ func init() {
 		&DisableStackFlag,
 		&DisableStorageFlag,
 		&DisableReturnDataFlag,
+		&SuperInstructionsFlag,
 	}
 	app.Commands = []*cli.Command{
+		&blockBuilderCommand,
+		&blockTestCommand,
 		&compileCommand,
 		&disasmCommand,
 		&runCommand,
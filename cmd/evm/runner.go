@@ -264,9 +264,12 @@ func runCmd(ctx *cli.Context) error {
 		Coinbase:    genesisConfig.Coinbase,
 		BlockNumber: new(big.Int).SetUint64(genesisConfig.Number),
 		EVMConfig: vm.Config{
-			Tracer: tracer.Hooks,
+			SuperInstructions: ctx.Bool(SuperInstructionsFlag.Name),
 		},
 	}
+	if tracer != nil {
+		runtimeConfig.EVMConfig.Tracer = tracer.Hooks
+	}
 
 	if cpuProfilePath := ctx.String(CPUProfileFlag.Name); cpuProfilePath != "" {
 		f, err := os.Create(cpuProfilePath)
@@ -80,7 +80,7 @@ func stateTestCmd(ctx *cli.Context) error {
 		DisableStorage:    ctx.Bool(DisableStorageFlag.Name),
 		DisableReturnData: ctx.Bool(DisableReturnDataFlag.Name),
 	}
-	cfg := vm.Config{}
+	cfg := vm.Config{SuperInstructions: ctx.Bool(SuperInstructionsFlag.Name)}
 	if machineFriendlyOutput {
 		cfg.Tracer = logger.NewJSONLogger(config, os.Stderr).Tracer().Hooks
 	} else if ctx.Bool(DebugFlag.Name) {
@@ -0,0 +1,154 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/urfave/cli/v2"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	rpcdaemoncli "github.com/erigontech/erigon/cmd/rpcdaemon/cli"
+	"github.com/erigontech/erigon/node"
+	erigonapp "github.com/erigontech/erigon/turbo/app"
+	erigoncli "github.com/erigontech/erigon/turbo/cli"
+	"github.com/erigontech/erigon/turbo/debug"
+	"github.com/erigontech/erigon/turbo/engineapi"
+	enode "github.com/erigontech/erigon/turbo/node"
+)
+
+var (
+	engineReplayRecording      string
+	engineReplayStopOnMismatch bool
+	engineReplayReportFile     string
+)
+
+// engineReplayNodeArgs - node flags applied before the ones given after `--`: the replayed recording must be
+// the only source of new blocks, so the node runs without embedded CL, peers and downloader
+var engineReplayNodeArgs = []string{"--externalcl", "--nodiscover", "--maxpeers=0", "--no-downloader"}
+
+var cmdEngineReplay = &cobra.Command{
+	Use:   "engine-replay [-- <erigon flags>]",
+	Short: "Start a node from --datadir in-process and replay an Engine API recording (made with --engine.record.dir) into it",
+	Long: `Starts an Erigon node from --datadir in this process, without embedded CL, peers and downloader, and feeds
+the recorded newPayload/forkchoiceUpdated/getPayload calls to its Engine API. Erigon flags after "--" configure
+the node (e.g. --chain, --authrpc.port), as they would for the erigon binary.`,
+	Example: "integration engine-replay --datadir=<datadir> --recording=<datadir>/engine-records -- --chain=hoodi",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		nodeArgs := append([]string{"erigon", "--datadir=" + datadirCli}, engineReplayNodeArgs...)
+		nodeArgs = append(nodeArgs, args...)
+		app := erigonapp.MakeApp("engine-replay", func(cliCtx *cli.Context) error {
+			return engineReplay(cliCtx, logger)
+		}, erigoncli.DefaultFlags)
+		return app.RunContext(ctx, nodeArgs)
+	},
+}
+
+func engineReplay(cliCtx *cli.Context, logger log.Logger) error {
+	ctx := cliCtx.Context
+	nodeCfg, err := enode.NewNodConfigUrfave(cliCtx, logger)
+	if err != nil {
+		return err
+	}
+	ethCfg := enode.NewEthConfigUrfave(cliCtx, nodeCfg, logger)
+	if ethCfg.InternalCL {
+		return errors.New("engine-replay requires --externalcl: embedded CL would import blocks on its own")
+	}
+	ethNode, err := enode.New(ctx, nodeCfg, ethCfg, logger, nil)
+	if err != nil {
+		return err
+	}
+	defer ethNode.Close()
+	node.StartNode(ethNode.Node())
+
+	addr := net.JoinHostPort(nodeCfg.Http.AuthRpcHTTPListenAddress, strconv.Itoa(nodeCfg.Http.AuthRpcPort))
+	if err := waitForEngineApi(ctx, addr, time.Minute); err != nil {
+		return err
+	}
+	// read after the server is up: it generates the secret if the datadir has none
+	jwtSecret, err := rpcdaemoncli.ObtainJWTSecret(&nodeCfg.Http, logger)
+	if err != nil {
+		return err
+	}
+	client, err := engineapi.DialJsonRpcClient("http://"+addr, jwtSecret, logger)
+	if err != nil {
+		return err
+	}
+	report, err := engineapi.NewEngineReplayer(client, engineReplayStopOnMismatch, logger).Replay(ctx, engineReplayRecording)
+	if err != nil {
+		return err
+	}
+	logger.Info("[EngineReplay] done", "calls", report.Calls, "skipped", report.Skipped, "mismatches", len(report.Mismatches))
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if engineReplayReportFile != "" {
+		if err := os.WriteFile(engineReplayReportFile, out, 0644); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(string(out))
+	}
+	if len(report.Mismatches) > 0 {
+		return errors.New("replay diverged from the recording")
+	}
+	return nil
+}
+
+// waitForEngineApi - Engine API server is started asynchronously by the node, wait until it accepts connections
+func waitForEngineApi(ctx context.Context, addr string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		if err == nil {
+			return conn.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("engine api at %s is not up: %w", addr, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+func init() {
+	cmdEngineReplay.Flags().StringVar(&datadirCli, "datadir", "", "data directory of the node the recording is replayed into")
+	must(cmdEngineReplay.MarkFlagRequired("datadir"))
+	must(cmdEngineReplay.MarkFlagDirname("datadir"))
+	cmdEngineReplay.Flags().StringVar(&engineReplayRecording, "recording", "", "recording file or directory")
+	must(cmdEngineReplay.MarkFlagRequired("recording"))
+	cmdEngineReplay.Flags().BoolVar(&engineReplayStopOnMismatch, "stop-on-mismatch", false, "stop at the first call whose outcome differs from the recording")
+	cmdEngineReplay.Flags().StringVar(&engineReplayReportFile, "report", "", "write the json report to this file instead of stdout")
+	rootCmd.AddCommand(cmdEngineReplay)
+}
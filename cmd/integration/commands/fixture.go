@@ -0,0 +1,101 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon/cmd/hack/tool/fromdb"
+	"github.com/erigontech/erigon/tests"
+	"github.com/erigontech/erigon/turbo/debug"
+)
+
+var (
+	fixtureTxIndex int
+	fixtureOutFile string
+)
+
+var cmdFixture = &cobra.Command{
+	Use:     "fixture",
+	Short:   "Extract a self-contained block test, or a state test of a single transaction, from a synced datadir",
+	Example: "integration fixture --datadir=<datadir> --block=<number> [--txn=<index>] --out=fixture.json",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := debug.SetupCobra(cmd, "integration")
+		ctx, _ := common.RootContext()
+
+		db, err := openDB(dbCfg(kv.ChainDB, chaindata), true, logger)
+		if err != nil {
+			return fmt.Errorf("opening db: %w", err)
+		}
+		defer db.Close()
+
+		dirs := datadir.New(datadirCli)
+		blockReader, _ := blocksIO(db, logger)
+		chainConfig := fromdb.ChainConfig(db)
+		engine, _ := initConsensusEngine(ctx, chainConfig, dirs.DataDir, db, blockReader, logger)
+		generator := tests.NewFixtureGenerator(chainConfig, engine, blockReader, dirs.Tmp, logger)
+
+		tx, err := db.BeginTemporalRo(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		var name string
+		var fixture any
+		if fixtureTxIndex < 0 {
+			name = fmt.Sprintf("block_%d", block)
+			fixture, err = generator.BlockTest(ctx, tx, block)
+		} else {
+			name = fmt.Sprintf("block_%d_txn_%d", block, fixtureTxIndex)
+			fixture, err = generator.StateTest(ctx, tx, block, fixtureTxIndex)
+		}
+		if err != nil {
+			return err
+		}
+		// fixture files map test names to tests
+		out, err := json.MarshalIndent(map[string]any{name: fixture}, "", "  ")
+		if err != nil {
+			return err
+		}
+		if fixtureOutFile == "" {
+			fmt.Println(string(out))
+			return nil
+		}
+		if err := os.WriteFile(fixtureOutFile, out, 0644); err != nil {
+			return err
+		}
+		logger.Info("[Fixture] written", "name", name, "file", fixtureOutFile)
+		return nil
+	},
+}
+
+func init() {
+	withDataDir(cmdFixture)
+	withBlock(cmdFixture)
+	must(cmdFixture.MarkFlagRequired("block"))
+	cmdFixture.Flags().IntVar(&fixtureTxIndex, "txn", -1, "index of the transaction in the block to extract a state test of (default: block test of the whole block)")
+	cmdFixture.Flags().StringVar(&fixtureOutFile, "out", "", "write the fixture to this file instead of stdout")
+	rootCmd.AddCommand(cmdFixture)
+}
@@ -36,6 +36,14 @@ type NodeAddr struct {
 	IPv6 NodeAddr1
 }
 
+// NodeRecord is the signed node record received from the node, with what is known about the node.
+type NodeRecord struct {
+	ID       NodeID
+	ENR      string // in the "enr:" text form
+	ClientID *string
+	Updated  time.Time // when the record was received
+}
+
 type HandshakeError struct {
 	StringCode string
 	Time       time.Time
@@ -69,6 +77,10 @@ type DB interface {
 
 	UpdateForkCompatibility(ctx context.Context, id NodeID, isCompatFork bool) error
 
+	UpdateENR(ctx context.Context, id NodeID, enr string) error
+	// FindNodeRecords returns records of the live nodes received after the given time.
+	FindNodeRecords(ctx context.Context, maxPingTries uint, networkID uint, updatedAfter time.Time) ([]NodeRecord, error)
+
 	UpdateNeighborBucketKeys(ctx context.Context, id NodeID, keys []string) error
 	FindNeighborBucketKeys(ctx context.Context, id NodeID) ([]string, error)
 
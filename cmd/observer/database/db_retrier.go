@@ -106,6 +106,13 @@ func (db DBRetrier) UpdateClientID(ctx context.Context, id NodeID, clientID stri
 	return err
 }
 
+func (db DBRetrier) UpdateENR(ctx context.Context, id NodeID, enr string) error {
+	_, err := db.retry(ctx, "UpdateENR", func(ctx context.Context) (interface{}, error) {
+		return nil, db.db.UpdateENR(ctx, id, enr)
+	})
+	return err
+}
+
 func (db DBRetrier) FindClientID(ctx context.Context, id NodeID) (*string, error) {
 	resultAny, err := db.retry(ctx, "FindClientID", func(ctx context.Context) (interface{}, error) {
 		return db.db.FindClientID(ctx, id)
@@ -64,7 +64,10 @@ CREATE TABLE IF NOT EXISTS nodes (
     
     neighbor_keys TEXT,
     
-    crawl_retry_time INTEGER
+    crawl_retry_time INTEGER,
+
+    enr TEXT,
+    enr_updated INTEGER
 );
 
 CREATE TABLE IF NOT EXISTS handshake_errors (
@@ -131,6 +134,23 @@ UPDATE nodes SET ping_try = nodes.ping_try + 1 WHERE id = ?
 
 	sqlCountPingErrors = `
 SELECT ping_try FROM nodes WHERE id = ?
+`
+
+	sqlUpdateENR = `
+UPDATE nodes SET
+	enr = ?,
+	enr_updated = ?
+WHERE id = ?
+`
+
+	sqlFindNodeRecords = `
+SELECT id, enr, client_id, enr_updated FROM nodes
+WHERE (ping_try < ?)
+    AND (network_id = ?)
+    AND ((compat_fork == TRUE) OR (compat_fork IS NULL))
+    AND (enr IS NOT NULL)
+    AND (enr_updated > ?)
+ORDER BY id
 `
 
 	sqlUpdateClientID = `
@@ -304,6 +324,12 @@ WHERE (ping_try < ?)
 `
 )
 
+// columns added to the nodes table after its creation, added to the databases created before
+var sqlNodesMigrations = []string{
+	`ALTER TABLE nodes ADD COLUMN enr TEXT`,
+	`ALTER TABLE nodes ADD COLUMN enr_updated INTEGER`,
+}
+
 func NewDBSQLite(filePath string) (*DBSQLite, error) {
 	db, err := sql.Open("sqlite", filePath)
 	if err != nil {
@@ -314,6 +340,11 @@ func NewDBSQLite(filePath string) (*DBSQLite, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the DB schema: %w", err)
 	}
+	for _, migration := range sqlNodesMigrations {
+		if _, err = db.Exec(migration); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+			return nil, fmt.Errorf("failed to migrate the DB schema: %w", err)
+		}
+	}
 
 	instance := DBSQLite{db}
 	return &instance, nil
@@ -471,6 +502,52 @@ func (db *DBSQLite) UpdateClientID(ctx context.Context, id NodeID, clientID stri
 	return nil
 }
 
+func (db *DBSQLite) UpdateENR(ctx context.Context, id NodeID, enr string) error {
+	updated := time.Now().Unix()
+
+	_, err := db.db.ExecContext(ctx, sqlUpdateENR, enr, updated, id)
+	if err != nil {
+		return fmt.Errorf("UpdateENR failed to update a node: %w", err)
+	}
+	return nil
+}
+
+func (db *DBSQLite) FindNodeRecords(
+	ctx context.Context,
+	maxPingTries uint,
+	networkID uint,
+	updatedAfter time.Time,
+) ([]NodeRecord, error) {
+	cursor, err := db.db.QueryContext(ctx, sqlFindNodeRecords, maxPingTries, networkID, updatedAfter.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("FindNodeRecords failed to query: %w", err)
+	}
+	defer func() {
+		_ = cursor.Close()
+	}()
+
+	var records []NodeRecord
+	for cursor.Next() {
+		var record NodeRecord
+		var clientID sql.NullString
+		var updated int64
+		err := cursor.Scan(&record.ID, &record.ENR, &clientID, &updated)
+		if err != nil {
+			return nil, fmt.Errorf("FindNodeRecords failed to read data: %w", err)
+		}
+		if clientID.Valid {
+			record.ClientID = &clientID.String
+		}
+		record.Updated = time.Unix(updated, 0)
+		records = append(records, record)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("FindNodeRecords failed to iterate: %w", err)
+	}
+	return records, nil
+}
+
 func (db *DBSQLite) FindClientID(ctx context.Context, id NodeID) (*string, error) {
 	row := db.db.QueryRowContext(ctx, sqlFindClientID, id)
 	var clientID sql.NullString
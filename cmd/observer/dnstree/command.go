@@ -0,0 +1,208 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package dnstree
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/urfave/cli/v2"
+
+	"github.com/erigontech/erigon/cmd/utils"
+)
+
+type CommandFlags struct {
+	DataDir      string
+	Chain        string
+	MaxPingTries uint
+	MaxAge       time.Duration
+	Clients      []string
+	Limit        uint
+	NoForkCheck  bool
+
+	Domain  string
+	KeyFile string
+	Seq     uint
+	Links   []string
+	Format  string
+	TTL     uint
+	Output  string
+}
+
+type Command struct {
+	command cobra.Command
+	flags   CommandFlags
+}
+
+func NewCommand() *Command {
+	command := cobra.Command{
+		Use:   "dns-tree",
+		Short: "Build a signed EIP-1459 DNS node tree of the crawled nodes",
+	}
+
+	instance := Command{
+		command: command,
+	}
+	instance.withDatadir()
+	instance.withChain()
+	instance.withMaxPingTries()
+	instance.withMaxAge()
+	instance.withClients()
+	instance.withLimit()
+	instance.withNoForkCheck()
+	instance.withDomain()
+	instance.withKeyFile()
+	instance.withSeq()
+	instance.withLinks()
+	instance.withFormat()
+	instance.withTTL()
+	instance.withOutput()
+
+	return &instance
+}
+
+func (command *Command) withDatadir() {
+	flag := utils.DataDirFlag
+	command.command.Flags().StringVar(&command.flags.DataDir, flag.Name, flag.Value.String(), flag.Usage)
+	must(command.command.MarkFlagDirname(utils.DataDirFlag.Name))
+}
+
+func (command *Command) withChain() {
+	flag := utils.ChainFlag
+	command.command.Flags().StringVar(&command.flags.Chain, flag.Name, flag.Value, flag.Usage)
+}
+
+func (command *Command) withMaxPingTries() {
+	flag := cli.UintFlag{
+		Name:  "max-ping-tries",
+		Usage: "A number of PING failures for a node to be considered dead",
+		Value: 3,
+	}
+	command.command.Flags().UintVar(&command.flags.MaxPingTries, flag.Name, flag.Value, flag.Usage)
+}
+
+func (command *Command) withMaxAge() {
+	flag := cli.DurationFlag{
+		Name:  "max-age",
+		Usage: "Publish only the nodes whose records were received within this time",
+		Value: 24 * time.Hour,
+	}
+	command.command.Flags().DurationVar(&command.flags.MaxAge, flag.Name, flag.Value, flag.Usage)
+}
+
+func (command *Command) withClients() {
+	flag := cli.StringSliceFlag{
+		Name:  "clients",
+		Usage: "Publish only the nodes with client IDs starting with one of these prefixes, e.g. 'erigon,geth'",
+	}
+	command.command.Flags().StringSliceVar(&command.flags.Clients, flag.Name, nil, flag.Usage)
+}
+
+func (command *Command) withLimit() {
+	flag := cli.UintFlag{
+		Name:  "limit",
+		Usage: "A maximum number of published nodes, 0 for no limit",
+		Value: 0,
+	}
+	command.command.Flags().UintVar(&command.flags.Limit, flag.Name, flag.Value, flag.Usage)
+}
+
+func (command *Command) withNoForkCheck() {
+	flag := cli.BoolFlag{
+		Name:  "no-fork-check",
+		Usage: "Publish the nodes without checking the fork ID of their records against the chain",
+	}
+	command.command.Flags().BoolVar(&command.flags.NoForkCheck, flag.Name, false, flag.Usage)
+}
+
+func (command *Command) withDomain() {
+	flag := cli.StringFlag{
+		Name:  "domain",
+		Usage: "Domain name of the tree, e.g. 'nodes.example.org'",
+	}
+	command.command.Flags().StringVar(&command.flags.Domain, flag.Name, flag.Value, flag.Usage)
+	must(command.command.MarkFlagRequired(flag.Name))
+}
+
+func (command *Command) withKeyFile() {
+	flag := cli.StringFlag{
+		Name:  "key",
+		Usage: "File with the hex encoded secp256k1 private key signing the tree",
+	}
+	command.command.Flags().StringVar(&command.flags.KeyFile, flag.Name, flag.Value, flag.Usage)
+	must(command.command.MarkFlagRequired(flag.Name))
+}
+
+func (command *Command) withSeq() {
+	flag := cli.UintFlag{
+		Name:  "seq",
+		Usage: "Sequence number of the tree, 0 to use the current unix time",
+		Value: 0,
+	}
+	command.command.Flags().UintVar(&command.flags.Seq, flag.Name, flag.Value, flag.Usage)
+}
+
+func (command *Command) withLinks() {
+	flag := cli.StringSliceFlag{
+		Name:  "links",
+		Usage: "enrtree:// URLs of other trees to link from this one",
+	}
+	command.command.Flags().StringSliceVar(&command.flags.Links, flag.Name, nil, flag.Usage)
+}
+
+func (command *Command) withFormat() {
+	flag := cli.StringFlag{
+		Name:  "format",
+		Usage: "Output format: 'json' (records by name) or 'zone' (zone file TXT records)",
+		Value: FormatJSON,
+	}
+	command.command.Flags().StringVar(&command.flags.Format, flag.Name, flag.Value, flag.Usage)
+}
+
+func (command *Command) withTTL() {
+	flag := cli.UintFlag{
+		Name:  "ttl",
+		Usage: "TTL of the zone file records in seconds",
+		Value: 1800,
+	}
+	command.command.Flags().UintVar(&command.flags.TTL, flag.Name, flag.Value, flag.Usage)
+}
+
+func (command *Command) withOutput() {
+	flag := cli.StringFlag{
+		Name:  "output",
+		Usage: "Output file path, stdout if empty",
+	}
+	command.command.Flags().StringVar(&command.flags.Output, flag.Name, flag.Value, flag.Usage)
+}
+
+func (command *Command) RawCommand() *cobra.Command {
+	return &command.command
+}
+
+func (command *Command) OnRun(runFunc func(ctx context.Context, flags CommandFlags) error) {
+	command.command.RunE = func(cmd *cobra.Command, args []string) error {
+		return runFunc(cmd.Context(), command.flags)
+	}
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package dnstree
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/observer/database"
+	"github.com/erigontech/erigon/core/forkid"
+	"github.com/erigontech/erigon/eth/protocols/eth"
+	"github.com/erigontech/erigon/p2p/dnsdisc"
+	"github.com/erigontech/erigon/p2p/enode"
+)
+
+// Filter selects the node records published in the tree.
+type Filter struct {
+	// ForkFilter accepts the fork IDs of the published chain, records without fork ID are skipped if set
+	ForkFilter forkid.Filter
+	// ClientIDPrefixes of the accepted clients (case insensitive), all clients if empty
+	ClientIDPrefixes []string
+	// Limit is the maximum number of published nodes, the most recently seen ones are preferred
+	Limit int
+}
+
+// Published is the signed tree with its DNS records.
+type Published struct {
+	Domain  string            `json:"domain"`
+	URL     string            `json:"url"` // enrtree:// link of the tree
+	Seq     uint              `json:"seq"`
+	Nodes   int               `json:"nodes"`
+	Records map[string]string `json:"records"` // TXT records by fully qualified name
+}
+
+// SelectNodes decodes the records and returns the nodes passing the filter.
+func SelectNodes(records []database.NodeRecord, filter Filter, logger log.Logger) []*enode.Node {
+	// the most recently seen first, so the limit drops the stale ones
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Updated.After(records[j].Updated) })
+
+	nodes := make([]*enode.Node, 0, len(records))
+	for _, record := range records {
+		if (filter.Limit > 0) && (len(nodes) >= filter.Limit) {
+			break
+		}
+		if !matchClientID(record.ClientID, filter.ClientIDPrefixes) {
+			continue
+		}
+		node, err := enode.Parse(enode.ValidSchemes, record.ENR)
+		if err != nil {
+			logger.Debug("Skipping invalid node record", "id", record.ID, "err", err)
+			continue
+		}
+		if node.IP() == nil || node.TCP() == 0 {
+			// not reachable over RLPx
+			continue
+		}
+		if filter.ForkFilter != nil {
+			forkID, err := eth.LoadENRForkID(node.Record())
+			if (err != nil) || (forkID == nil) || (filter.ForkFilter(*forkID) != nil) {
+				continue
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func matchClientID(clientID *string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	if clientID == nil {
+		return false
+	}
+	id := strings.ToLower(*clientID)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(id, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish builds the tree of nodes and links to other trees and signs it with the key.
+// The sequence number defaults to the current unix time, so every publication supersedes the previous one.
+func Publish(nodes []*enode.Node, links []string, domain string, seq uint, key *ecdsa.PrivateKey) (*Published, error) {
+	if seq == 0 {
+		seq = uint(time.Now().Unix())
+	}
+	for _, link := range links {
+		if _, _, err := dnsdisc.ParseURL(link); err != nil {
+			return nil, fmt.Errorf("invalid tree link %s: %w", link, err)
+		}
+	}
+	tree, err := dnsdisc.MakeTree(seq, nodes, links)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make the tree: %w", err)
+	}
+	url, err := tree.Sign(key, domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the tree: %w", err)
+	}
+	return &Published{
+		Domain:  domain,
+		URL:     url,
+		Seq:     seq,
+		Nodes:   len(nodes),
+		Records: tree.ToTXT(domain),
+	}, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package dnstree
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/observer/database"
+	"github.com/erigontech/erigon/core/forkid"
+	"github.com/erigontech/erigon/eth/protocols/eth"
+	"github.com/erigontech/erigon/p2p/dnsdisc"
+	"github.com/erigontech/erigon/p2p/enode"
+	"github.com/erigontech/erigon/p2p/enr"
+)
+
+var (
+	chainGenesis = libcommon.Hash{1}
+	otherGenesis = libcommon.Hash{2}
+)
+
+func testRecord(t *testing.T, i int, genesis *libcommon.Hash, clientID string, updated time.Time) database.NodeRecord {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	var r enr.Record
+	r.Set(enr.IPv4(net.IPv4(10, 0, 0, byte(i))))
+	r.Set(enr.TCP(30303))
+	r.Set(enr.UDP(30303))
+	if genesis != nil {
+		r.Set(eth.CurrentENREntryFromForks(nil, nil, *genesis, 0, 0))
+	}
+	require.NoError(t, enode.SignV4(&r, key))
+	node, err := enode.New(enode.ValidSchemes, &r)
+	require.NoError(t, err)
+	return database.NodeRecord{
+		ID:       database.NodeID(fmt.Sprintf("%x", crypto.MarshalPubkey(&key.PublicKey))),
+		ENR:      node.String(),
+		ClientID: &clientID,
+		Updated:  updated,
+	}
+}
+
+func forkFilter(id forkid.ID) error {
+	if id == forkid.NewIDFromForks(nil, nil, chainGenesis, 0, 0) {
+		return nil
+	}
+	return forkid.ErrLocalIncompatibleOrStale
+}
+
+func TestSelectNodes(t *testing.T) {
+	now := time.Now()
+	records := []database.NodeRecord{
+		testRecord(t, 1, &chainGenesis, "erigon/v3.0.0/linux-amd64/go1.23", now.Add(-3*time.Hour)),
+		testRecord(t, 2, &chainGenesis, "Geth/v1.15.0-stable/linux-amd64/go1.23", now.Add(-time.Hour)),
+		testRecord(t, 3, &otherGenesis, "erigon/v3.0.0/linux-amd64/go1.23", now),
+		testRecord(t, 4, nil, "erigon/v3.0.0/linux-amd64/go1.23", now),
+		testRecord(t, 5, &chainGenesis, "Nethermind/v1.30.0", now.Add(-2*time.Hour)),
+		{ID: "broken", ENR: "enr:broken", Updated: now},
+	}
+	ip := func(nodes []*enode.Node) []string {
+		var res []string
+		for _, n := range nodes {
+			res = append(res, n.IP().String())
+		}
+		return res
+	}
+
+	nodes := SelectNodes(records, Filter{ForkFilter: forkFilter}, log.New())
+	require.Equal(t, []string{"10.0.0.2", "10.0.0.5", "10.0.0.1"}, ip(nodes))
+
+	nodes = SelectNodes(records, Filter{ForkFilter: forkFilter, ClientIDPrefixes: []string{"geth", "Erigon"}}, log.New())
+	require.Equal(t, []string{"10.0.0.2", "10.0.0.1"}, ip(nodes))
+
+	nodes = SelectNodes(records, Filter{ForkFilter: forkFilter, Limit: 2}, log.New())
+	require.Equal(t, []string{"10.0.0.2", "10.0.0.5"}, ip(nodes))
+
+	nodes = SelectNodes(records, Filter{}, log.New())
+	require.Len(t, nodes, 5)
+}
+
+type mapResolver map[string]string
+
+func (r mapResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if record, ok := r[name]; ok {
+		return []string{record}, nil
+	}
+	return nil, fmt.Errorf("%s not found", name)
+}
+
+func TestPublish(t *testing.T) {
+	var records []database.NodeRecord
+	for i := 1; i <= 20; i++ {
+		records = append(records, testRecord(t, i, &chainGenesis, "erigon", time.Now()))
+	}
+	nodes := SelectNodes(records, Filter{}, log.New())
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	linkKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	linkTree, err := dnsdisc.MakeTree(1, nil, nil)
+	require.NoError(t, err)
+	link, err := linkTree.Sign(linkKey, "other.example.org")
+	require.NoError(t, err)
+
+	published, err := Publish(nodes, []string{link}, "nodes.example.org", 7, key)
+	require.NoError(t, err)
+	require.Equal(t, uint(7), published.Seq)
+	require.Equal(t, 20, published.Nodes)
+
+	// the records resolve to the tree of the published nodes
+	client := dnsdisc.NewClient(dnsdisc.Config{Resolver: mapResolver(published.Records), RateLimit: 1000})
+	tree, err := client.SyncTree(published.URL)
+	require.NoError(t, err)
+	require.Equal(t, uint(7), tree.Seq())
+	require.Len(t, tree.Nodes(), 20)
+	require.Equal(t, []string{link}, tree.Links())
+
+	_, err = Publish(nodes, []string{"enrtree://broken"}, "nodes.example.org", 7, key)
+	require.Error(t, err)
+
+	var zone bytes.Buffer
+	require.NoError(t, Write(&zone, published, FormatZone, 300))
+	lines := strings.Split(strings.TrimSpace(zone.String()), "\n")
+	require.Len(t, lines, len(published.Records)+1)
+	require.True(t, strings.HasPrefix(lines[1], `nodes.example.org. 300 IN TXT "enrtree-root:v1 `))
+	for _, line := range lines[1:] {
+		for _, s := range strings.Split(line[strings.Index(line, `"`):], `" "`) {
+			require.LessOrEqual(t, len(strings.Trim(s, `"`)), maxTXTStringLength)
+		}
+	}
+
+	require.Error(t, Write(&zone, published, "bind", 300))
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package dnstree
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatJSON = "json" // Published as JSON, records to be uploaded by the provider specific tooling
+	FormatZone = "zone" // RFC 1035 zone file lines, accepted by most of the DNS servers and providers
+)
+
+// maxTXTStringLength is the limit of a single character string in a TXT record,
+// longer values are split into several strings which resolvers concatenate.
+const maxTXTStringLength = 255
+
+func Write(w io.Writer, published *Published, format string, ttl uint) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(published)
+	case FormatZone:
+		return writeZone(w, published, ttl)
+	default:
+		return fmt.Errorf("unknown output format %s, expected %s or %s", format, FormatJSON, FormatZone)
+	}
+}
+
+// writeZone writes the TXT records, the tree root goes first.
+func writeZone(w io.Writer, published *Published, ttl uint) error {
+	names := make([]string, 0, len(published.Records))
+	for name := range published.Records {
+		if name != published.Domain {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{published.Domain}, names...)
+
+	if _, err := fmt.Fprintf(w, "; %s seq=%d nodes=%d\n", published.URL, published.Seq, published.Nodes); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%s. %d IN TXT %s\n", name, ttl, txtStrings(published.Records[name])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// txtStrings splits the value into quoted character strings of the allowed length.
+// Values of the tree entries are base64/base32 encoded and don't need escaping.
+func txtStrings(value string) string {
+	var parts []string
+	for len(value) > maxTXTStringLength {
+		parts = append(parts, `"`+value[:maxTXTStringLength]+`"`)
+		value = value[maxTXTStringLength:]
+	}
+	parts = append(parts, `"`+value+`"`)
+	return strings.Join(parts, " ")
+}
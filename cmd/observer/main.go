@@ -20,14 +20,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/cmd/observer/database"
+	"github.com/erigontech/erigon/cmd/observer/dnstree"
 	"github.com/erigontech/erigon/cmd/observer/observer"
 	"github.com/erigontech/erigon/cmd/observer/reports"
 	"github.com/erigontech/erigon/cmd/utils"
+	"github.com/erigontech/erigon/core/forkid"
 	"github.com/erigontech/erigon/params"
 )
 
@@ -119,6 +124,57 @@ func reportWithFlags(ctx context.Context, flags reports.CommandFlags) error {
 	return nil
 }
 
+func dnsTreeWithFlags(ctx context.Context, flags dnstree.CommandFlags) error {
+	db, err := database.NewDBSQLite(filepath.Join(flags.DataDir, "observer.sqlite"))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	key, err := crypto.LoadECDSA(flags.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the tree signing key: %w", err)
+	}
+
+	networkID := uint(params.NetworkIDByChainName(flags.Chain))
+	filter := dnstree.Filter{
+		ClientIDPrefixes: flags.Clients,
+		Limit:            int(flags.Limit),
+	}
+	if !flags.NoForkCheck {
+		chainConfig := params.ChainConfigByChainName(flags.Chain)
+		genesisHash := params.GenesisHashByChainName(flags.Chain)
+		if (chainConfig == nil) || (genesisHash == nil) {
+			return fmt.Errorf("unknown chain %s", flags.Chain)
+		}
+		filter.ForkFilter = forkid.NewStaticFilter(chainConfig, *genesisHash, 0)
+	}
+
+	records, err := db.FindNodeRecords(ctx, flags.MaxPingTries, networkID, time.Now().Add(-flags.MaxAge))
+	if err != nil {
+		return err
+	}
+	nodes := dnstree.SelectNodes(records, filter, log.Root())
+	if len(nodes) == 0 {
+		return fmt.Errorf("no nodes to publish out of %d records", len(records))
+	}
+
+	published, err := dnstree.Publish(nodes, flags.Links, flags.Domain, flags.Seq, key)
+	if err != nil {
+		return err
+	}
+	log.Info("Built DNS tree", "url", published.URL, "seq", published.Seq, "nodes", published.Nodes, "records", len(published.Records))
+
+	out := os.Stdout
+	if flags.Output != "" {
+		if out, err = os.Create(flags.Output); err != nil {
+			return err
+		}
+		defer func() { _ = out.Close() }()
+	}
+	return dnstree.Write(out, published, flags.Format, flags.TTL)
+}
+
 func main() {
 	ctx, cancel := common.RootContext()
 	defer cancel()
@@ -129,6 +185,10 @@ func main() {
 	reportCommand.OnRun(reportWithFlags)
 	command.AddSubCommand(reportCommand.RawCommand())
 
+	dnsTreeCommand := dnstree.NewCommand()
+	dnsTreeCommand.OnRun(dnsTreeWithFlags)
+	command.AddSubCommand(dnsTreeCommand.RawCommand())
+
 	err := command.ExecuteContext(ctx, mainWithFlags)
 	if (err != nil) && !errors.Is(err, context.Canceled) {
 		utils.Fatalf("%v", err)
@@ -479,6 +479,13 @@ func (crawler *Crawler) saveInterrogationResult(
 		}
 	}
 
+	if (result != nil) && (result.ENR != nil) {
+		dbErr := crawler.db.UpdateENR(ctx, id, result.ENR.String())
+		if dbErr != nil {
+			return dbErr
+		}
+	}
+
 	if isCompatFork != nil {
 		dbErr := crawler.db.UpdateForkCompatibility(ctx, id, *isCompatFork)
 		if dbErr != nil {
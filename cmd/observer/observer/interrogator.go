@@ -59,6 +59,7 @@ type Interrogator struct {
 
 type InterrogationResult struct {
 	Node               *enode.Node
+	ENR                *enode.Node // signed record received from the node, nil if not requested or failed
 	IsCompatFork       *bool
 	HandshakeResult    *DiplomatResult
 	HandshakeRetryTime *time.Time
@@ -186,6 +187,7 @@ func (interrogator *Interrogator) Run(ctx context.Context) (*InterrogationResult
 
 	result := InterrogationResult{
 		interrogator.node,
+		enr,
 		isCompatFork,
 		handshakeResult,
 		handshakeRetryTime,
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/c2h5oh/datasize"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/datadir"
 	"github.com/spf13/cobra"
@@ -27,6 +28,7 @@ import (
 	"github.com/erigontech/erigon-lib/common/paths"
 	"github.com/erigontech/erigon/cmd/utils"
 	"github.com/erigontech/erigon/p2p/sentry"
+	"github.com/erigontech/erigon/p2p/sentry/capture"
 	"github.com/erigontech/erigon/turbo/debug"
 	"github.com/erigontech/erigon/turbo/logging"
 	node2 "github.com/erigontech/erigon/turbo/node"
@@ -46,11 +48,16 @@ var (
 	nodiscover   bool // disable sentry's discovery mechanism
 	protocol     uint
 	allowedPorts []uint
+	quicPort     uint
 	netRestrict  string // CIDR to restrict peering to
 	maxPeers     int
 	maxPendPeers int
 	healthCheck  bool
 	metrics      bool
+
+	captureDir      string // record the peer messages into the directory
+	captureFileSize string
+	captureFiles    int
 )
 
 func init() {
@@ -66,11 +73,15 @@ func init() {
 	rootCmd.Flags().BoolVar(&nodiscover, utils.NoDiscoverFlag.Name, false, utils.NoDiscoverFlag.Usage)
 	rootCmd.Flags().UintVar(&protocol, utils.P2pProtocolVersionFlag.Name, utils.P2pProtocolVersionFlag.Value.Value()[0], utils.P2pProtocolVersionFlag.Usage)
 	rootCmd.Flags().UintSliceVar(&allowedPorts, utils.P2pProtocolAllowedPorts.Name, utils.P2pProtocolAllowedPorts.Value.Value(), utils.P2pProtocolAllowedPorts.Usage)
+	rootCmd.Flags().UintVar(&quicPort, utils.P2pQUICPortFlag.Name, utils.P2pQUICPortFlag.Value, utils.P2pQUICPortFlag.Usage)
 	rootCmd.Flags().StringVar(&netRestrict, utils.NetrestrictFlag.Name, utils.NetrestrictFlag.Value, utils.NetrestrictFlag.Usage)
 	rootCmd.Flags().IntVar(&maxPeers, utils.MaxPeersFlag.Name, utils.MaxPeersFlag.Value, utils.MaxPeersFlag.Usage)
 	rootCmd.Flags().IntVar(&maxPendPeers, utils.MaxPendingPeersFlag.Name, utils.MaxPendingPeersFlag.Value, utils.MaxPendingPeersFlag.Usage)
 	rootCmd.Flags().BoolVar(&healthCheck, utils.HealthCheckFlag.Name, false, utils.HealthCheckFlag.Usage)
 	rootCmd.Flags().BoolVar(&metrics, utils.MetricsEnabledFlag.Name, false, utils.MetricsEnabledFlag.Usage)
+	rootCmd.Flags().StringVar(&captureDir, utils.SentryCaptureDirFlag.Name, "", utils.SentryCaptureDirFlag.Usage)
+	rootCmd.Flags().StringVar(&captureFileSize, utils.SentryCaptureFileSizeFlag.Name, utils.SentryCaptureFileSizeFlag.Value, utils.SentryCaptureFileSizeFlag.Usage)
+	rootCmd.Flags().IntVar(&captureFiles, utils.SentryCaptureFilesFlag.Name, utils.SentryCaptureFilesFlag.Value, utils.SentryCaptureFilesFlag.Usage)
 
 	if err := rootCmd.MarkFlagDirname(utils.DataDirFlag.Name); err != nil {
 		panic(err)
@@ -108,9 +119,23 @@ var rootCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if quicPort != 0 {
+			p2pConfig.QUICListenAddr = fmt.Sprintf(":%d", quicPort)
+		}
+
+		var captureCfg capture.Config
+		if captureDir != "" {
+			captureCfg = capture.DefaultConfig(captureDir)
+			fileSize, err := datasize.ParseString(captureFileSize)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", utils.SentryCaptureFileSizeFlag.Name, err)
+			}
+			captureCfg.MaxFileSize = int64(fileSize.Bytes())
+			captureCfg.MaxFiles = captureFiles
+		}
 
 		logger := debug.SetupCobra(cmd, "sentry")
-		return sentry.Sentry(cmd.Context(), dirs, sentryAddr, discoveryDNS, p2pConfig, protocol, healthCheck, logger)
+		return sentry.Sentry(cmd.Context(), dirs, sentryAddr, discoveryDNS, p2pConfig, protocol, healthCheck, captureCfg, logger)
 	},
 }
 
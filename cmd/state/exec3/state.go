@@ -58,9 +58,10 @@ type Worker struct {
 	blockReader services.FullBlockReader
 	in          *state.QueueWithRetry
 	rs          *state.StateV3
-	stateWriter *state.StateWriterV3
+	stateWriter state.ResettableStateWriter
 	stateReader state.ResettableStateReader
 	historyMode bool // if true - stateReader is HistoryReaderV3, otherwise it's state reader
+	observer    state.ReadObserver
 	chainConfig *chain.Config
 
 	ctx      context.Context
@@ -124,6 +125,14 @@ func (rw *Worker) ResetState(rs *state.StateV3, accumulator *shards.Accumulator)
 	rw.stateWriter = state.NewStateWriterV3(rs, accumulator)
 }
 
+// ResetVersionedState - switches worker to speculative execution of txns (see Block-STM in stagedsync):
+// worker doesn't touch StateV3 and does read/write state only by given reader/writer
+func (rw *Worker) ResetVersionedState(reader state.ResettableStateReader, writer state.ResettableStateWriter) {
+	rw.rs = nil
+	rw.SetReader(reader)
+	rw.stateWriter = writer
+}
+
 func (rw *Worker) Tx() kv.TemporalTx { return rw.chainTx }
 func (rw *Worker) DiscardReadList()  { rw.stateReader.DiscardReadList() }
 func (rw *Worker) ResetTx(chainTx kv.Tx) {
@@ -169,17 +178,26 @@ func (rw *Worker) SetReader(reader state.ResettableStateReader) {
 	rw.ibs.Reset()
 	rw.ibs = state.New(rw.stateReader)
 
-	switch reader.(type) {
+	switch r := reader.(type) {
 	case *state.HistoryReaderV3:
 		rw.historyMode = true
 	case *state.ReaderV3:
 		rw.historyMode = false
+		r.SetReadObserver(rw.observer)
 	default:
 		rw.historyMode = false
 		//fmt.Printf("[worker] unknown reader %T: historyMode is set to disabled\n", reader)
 	}
 }
 
+// SetReadObserver - observer gets keys of all state reads of worker (nil - disable). Supported only by ReaderV3.
+func (rw *Worker) SetReadObserver(observer state.ReadObserver) {
+	rw.observer = observer
+	if r, ok := rw.stateReader.(*state.ReaderV3); ok {
+		r.SetReadObserver(observer)
+	}
+}
+
 type validationResult struct {
 	PaymasterContext []byte
 	GasUsed          uint64
@@ -209,7 +227,9 @@ func (rw *Worker) RunTxTaskNoLock(txTask *state.TxTask, isMining, skipPostEvalua
 	txTask.Error = nil
 
 	rw.stateReader.SetTxNum(txTask.TxNum)
-	rw.rs.Domains().SetTxNum(txTask.TxNum)
+	if rw.rs != nil {
+		rw.rs.Domains().SetTxNum(txTask.TxNum)
+	}
 	rw.stateReader.ResetReadSet()
 	rw.stateWriter.ResetWriteSet()
 
@@ -59,6 +59,7 @@ import (
 	"github.com/erigontech/erigon/p2p/enode"
 	"github.com/erigontech/erigon/p2p/nat"
 	"github.com/erigontech/erigon/p2p/netutil"
+	"github.com/erigontech/erigon/p2p/sentry/capture"
 	"github.com/erigontech/erigon/params"
 	"github.com/erigontech/erigon/polygon/heimdall"
 	"github.com/erigontech/erigon/rpc/rpccfg"
@@ -143,6 +144,36 @@ var (
 		Name:  "externalcl",
 		Usage: "Enables the external consensus layer",
 	}
+	EngineRecordDirFlag = cli.StringFlag{
+		Name:  "engine.record.dir",
+		Usage: "Record Engine API newPayload, forkchoiceUpdated and getPayload calls into rotating files in this directory (for `integration engine-replay`)",
+		Value: "",
+	}
+	EngineRecordMaxFileSizeFlag = cli.UintFlag{
+		Name:  "engine.record.maxsize",
+		Usage: "Max Engine API recording file size in MB",
+		Value: 64,
+	}
+	EngineRecordMaxFilesFlag = cli.UintFlag{
+		Name:  "engine.record.maxfiles",
+		Usage: "Max Engine API recording files to keep",
+		Value: 10,
+	}
+	LightProvidersFlag = cli.StringFlag{
+		Name:  "light.providers",
+		Usage: "Comma separated JSON-RPC urls of untrusted eth_getProof providers. If set, eth_getBalance, eth_getTransactionCount, eth_getCode, eth_getStorageAt and eth_call are answered from proofs verified against the finalized header tracked by Caplin",
+		Value: "",
+	}
+	LightBeaconUrlFlag = cli.StringFlag{
+		Name:  "light.beacon.url",
+		Usage: "Beacon API serving light client updates for --light.providers, defaults to the Caplin beacon API (requires --beacon.api=beacon)",
+		Value: "",
+	}
+	LightCheckpointFlag = cli.StringFlag{
+		Name:  "light.checkpoint",
+		Usage: "Trusted beacon block root the light client bootstraps from, required by --light.providers. Use a recent finalized block root from a source you trust",
+		Value: "",
+	}
 	// Transaction pool settings
 	TxPoolDisableFlag = cli.BoolFlag{
 		Name:  "txpool.disable",
@@ -523,7 +554,7 @@ var (
 	}
 	P2pProtocolVersionFlag = cli.UintSliceFlag{
 		Name:  "p2p.protocol",
-		Usage: "Version of eth p2p protocol",
+		Usage: "Version of eth p2p protocol. eth/69 is opt-in, e.g. --p2p.protocol=68,67,69",
 		Value: cli.NewUintSlice(nodecfg.DefaultConfig.P2P.ProtocolVersion...),
 	}
 	P2pProtocolAllowedPorts = cli.UintSliceFlag{
@@ -531,6 +562,11 @@ var (
 		Usage: "Allowed ports to pick for different eth p2p protocol versions as follows <porta>,<portb>,..,<porti>",
 		Value: cli.NewUintSlice(uint(ListenPortFlag.Value), 30304, 30305, 30306, 30307),
 	}
+	P2pQUICPortFlag = cli.UintFlag{
+		Name:  "p2p.quic.port",
+		Usage: "UDP port of the experimental QUIC transport for the peers advertising it, 0 disables it. The sentries of further protocol versions use the next ports",
+		Value: 0,
+	}
 	SentryAddrFlag = cli.StringFlag{
 		Name:  "sentry.api.addr",
 		Usage: "Comma separated sentry addresses '<host>:<port>,<host>:<port>'",
@@ -539,6 +575,24 @@ var (
 		Name:  "sentry.log-peer-info",
 		Usage: "Log detailed peer info when a peer connects or disconnects. Enable to integrate with observer.",
 	}
+	SnapServeFlag = cli.BoolFlag{
+		Name:  "p2p.snap",
+		Usage: "Serve state of the recent blocks to the peers syncing with the snap/1 protocol (in-process sentries only)",
+	}
+	SentryCaptureDirFlag = cli.StringFlag{
+		Name:  "sentry.capture.dir",
+		Usage: "Record the messages of the peers into rotating files of the directory, for debugging (in-process sentries only)",
+	}
+	SentryCaptureFileSizeFlag = cli.StringFlag{
+		Name:  "sentry.capture.file-size",
+		Usage: "Size of a single --sentry.capture.dir file",
+		Value: "128mb",
+	}
+	SentryCaptureFilesFlag = cli.IntFlag{
+		Name:  "sentry.capture.files",
+		Usage: "Number of the --sentry.capture.dir files to keep, the oldest ones are deleted",
+		Value: 16,
+	}
 	DownloaderAddrFlag = cli.StringFlag{
 		Name:  "downloader.api.addr",
 		Usage: "downloader address '<host>:<port>'",
@@ -568,13 +622,15 @@ var (
 	}
 	NATFlag = cli.StringFlag{
 		Name: "nat",
-		Usage: `NAT port mapping mechanism (any|none|upnp|pmp|stun|extip:<IP>)
+		Usage: `NAT port mapping mechanism (any|none|upnp|pmp|pcp|stun|extip:<IP>)
 			 "" or "none"         Default - do not nat
 			 "extip:77.12.33.4"   Will assume the local machine is reachable on the given IP
 			 "any"                Uses the first auto-detected mechanism
 			 "upnp"               Uses the Universal Plug and Play protocol
 			 "pmp"                Uses NAT-PMP with an auto-detected gateway address
 			 "pmp:192.168.0.1"    Uses NAT-PMP with the given gateway address
+			 "pcp"                Uses the Port Control Protocol with an auto-detected gateway address
+			 "pcp:192.168.0.1"    Uses the Port Control Protocol with the given gateway address
 			 "stun"               Uses STUN to detect an external IP using a default server
 			 "stun:<server>"      Uses STUN to detect an external IP using the given server (host:port)
 `,
@@ -588,6 +644,10 @@ var (
 		Name:  "v5disc",
 		Usage: "Enables the experimental RLPx V5 (Topic Discovery) mechanism",
 	}
+	DiscoveryV5TopicsFlag = cli.StringFlag{
+		Name:  "v5disc.topics",
+		Usage: "Comma separated V5 discovery topics to advertise the node under and to find peers by (requires --v5disc)",
+	}
 	NetrestrictFlag = cli.StringFlag{
 		Name:  "netrestrict",
 		Usage: "Restricts network communication to the given IP networks (CIDR masks)",
@@ -1275,6 +1335,8 @@ func NewP2PConfig(
 		enodeDBPath = filepath.Join(dirs.Nodes, "eth67")
 	case direct.ETH68:
 		enodeDBPath = filepath.Join(dirs.Nodes, "eth68")
+	case direct.ETH69:
+		enodeDBPath = filepath.Join(dirs.Nodes, "eth69")
 	default:
 		return nil, fmt.Errorf("unknown protocol: %v", protocol)
 	}
@@ -1344,6 +1406,9 @@ func setListenAddress(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.IsSet(SentryAddrFlag.Name) {
 		cfg.SentryAddr = libcommon.CliString2Array(ctx.String(SentryAddrFlag.Name))
 	}
+	if port := ctx.Uint(P2pQUICPortFlag.Name); port != 0 {
+		cfg.QUICListenAddr = fmt.Sprintf(":%d", port)
+	}
 	// TODO cli lib doesn't store defaults for UintSlice properly so we have to get value directly
 	cfg.AllowedPorts = P2pProtocolAllowedPorts.Value.Value()
 	if ctx.IsSet(P2pProtocolAllowedPorts.Name) {
@@ -1401,7 +1466,7 @@ func setEtherbase(ctx *cli.Context, cfg *ethconfig.Config) {
 		}
 	}
 
-	if chainName := ctx.String(ChainFlag.Name); chainName == networkname.Dev || chainName == networkname.BorDevnet {
+	if chainName := ctx.String(ChainFlag.Name); chainName == networkname.Dev || chainName == networkname.BorDevnet || chainName == networkname.QBFTDevnet || chainName == networkname.CliqueDevnet {
 		if etherbase == "" {
 			cfg.Miner.Etherbase = core.DevnetEtherbase
 		}
@@ -1447,6 +1512,9 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config, nodeName, datadir string, l
 	if ctx.IsSet(DiscoveryV5Flag.Name) {
 		cfg.DiscoveryV5 = ctx.Bool(DiscoveryV5Flag.Name)
 	}
+	if ctx.IsSet(DiscoveryV5TopicsFlag.Name) {
+		cfg.DiscoveryV5Topics = libcommon.CliString2Array(ctx.String(DiscoveryV5TopicsFlag.Name))
+	}
 
 	if ctx.IsSet(MetricsEnabledFlag.Name) {
 		cfg.MetricsEnabled = ctx.Bool(MetricsEnabledFlag.Name)
@@ -1462,7 +1530,7 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config, nodeName, datadir string, l
 		cfg.NetRestrict = list
 	}
 
-	if ctx.String(ChainFlag.Name) == networkname.Dev {
+	if chainName := ctx.String(ChainFlag.Name); chainName == networkname.Dev || chainName == networkname.QBFTDevnet || chainName == networkname.CliqueDevnet {
 		// --dev mode can't use p2p networking.
 		//cfg.MaxPeers = 0 // It can have peers otherwise local sync is not possible
 		if !ctx.IsSet(ListenPortFlag.Name) {
@@ -1982,6 +2050,16 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 			cfg.EthDiscoveryURLs = libcommon.CliString2Array(urls)
 		}
 	}
+	cfg.SnapServe = ctx.Bool(SnapServeFlag.Name)
+	if dir := ctx.String(SentryCaptureDirFlag.Name); dir != "" {
+		cfg.SentryCapture = capture.DefaultConfig(dir)
+		fileSize, err := datasize.ParseString(ctx.String(SentryCaptureFileSizeFlag.Name))
+		if err != nil {
+			Fatalf("Option %s: %v", SentryCaptureFileSizeFlag.Name, err)
+		}
+		cfg.SentryCapture.MaxFileSize = int64(fileSize.Bytes())
+		cfg.SentryCapture.MaxFiles = ctx.Int(SentryCaptureFilesFlag.Name)
+	}
 
 	// Override any default configs for hard coded networks.
 	switch chain {
@@ -2012,6 +2090,38 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 		if !ctx.IsSet(MinerGasPriceFlag.Name) {
 			cfg.Miner.GasPrice = big.NewInt(1)
 		}
+	case networkname.CliqueDevnet:
+		signers := core.CliqueDevnetSigners
+		if len(signers) == 0 {
+			if cfg.Miner.Etherbase == (libcommon.Address{}) {
+				Fatalf("Please specify the signer address using --miner.etherbase")
+			}
+			signers = []libcommon.Address{cfg.Miner.Etherbase}
+		}
+		logger.Info("Using clique devnet signers", "signers", signers)
+
+		var period uint64
+		if ctx.IsSet(DeveloperPeriodFlag.Name) {
+			period = uint64(ctx.Int(DeveloperPeriodFlag.Name))
+		}
+		cfg.Genesis = core.CliqueDevnetGenesisBlock(period, signers)
+		if !ctx.IsSet(MinerGasPriceFlag.Name) {
+			cfg.Miner.GasPrice = big.NewInt(1)
+		}
+	case networkname.QBFTDevnet:
+		validators := core.QBFTDevnetValidators
+		if len(validators) == 0 {
+			if cfg.Miner.Etherbase == (libcommon.Address{}) {
+				Fatalf("Please specify the validator address using --miner.etherbase")
+			}
+			validators = []libcommon.Address{cfg.Miner.Etherbase}
+		}
+		logger.Info("Using qbft devnet validators", "validators", validators)
+
+		cfg.Genesis = core.QBFTDevnetGenesisBlock(validators)
+		if !ctx.IsSet(MinerGasPriceFlag.Name) {
+			cfg.Miner.GasPrice = big.NewInt(1)
+		}
 	}
 
 	if ctx.IsSet(OverridePragueFlag.Name) {
@@ -2022,6 +2132,16 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 	if clparams.EmbeddedSupported(cfg.NetworkID) || cfg.CaplinConfig.IsDevnet() {
 		cfg.InternalCL = !ctx.Bool(ExternalConsensusFlag.Name)
 	}
+	cfg.EngineRecordDirPath = ctx.String(EngineRecordDirFlag.Name)
+	cfg.EngineRecordMaxFileSize = uint16(ctx.Uint(EngineRecordMaxFileSizeFlag.Name))
+	cfg.EngineRecordMaxFiles = uint16(ctx.Uint(EngineRecordMaxFilesFlag.Name))
+	if providers := ctx.String(LightProvidersFlag.Name); providers != "" {
+		cfg.LightProviders = libcommon.CliString2Array(providers)
+	}
+	cfg.LightBeaconUrl = ctx.String(LightBeaconUrlFlag.Name)
+	if checkpoint := ctx.String(LightCheckpointFlag.Name); checkpoint != "" {
+		cfg.LightCheckpoint = libcommon.HexToHash(checkpoint)
+	}
 
 	if ctx.IsSet(TrustedSetupFile.Name) {
 		libkzg.SetTrustedSetupFilePath(ctx.String(TrustedSetupFile.Name))
@@ -21,6 +21,7 @@ package clique
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/erigontech/erigon/eth/consensuschain"
@@ -33,6 +34,9 @@ import (
 	"github.com/erigontech/erigon/turbo/services"
 )
 
+// errNotClique is returned by the API of a node which doesn't run the clique engine.
+var errNotClique = errors.New("consensus engine is not clique")
+
 // API is a user facing RPC API to allow controlling the signer and voting
 // mechanisms of the proof-of-authority scheme.
 type API struct {
@@ -45,6 +49,9 @@ type API struct {
 
 // GetSnapshot retrieves the state snapshot at a given block.
 func (api *API) GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*Snapshot, error) {
+	if api.clique == nil {
+		return nil, errNotClique
+	}
 	tx, err := api.db.BeginRo(ctx)
 	if err != nil {
 		return nil, err
@@ -53,11 +60,9 @@ func (api *API) GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*Snap
 	chain := consensuschain.NewReader(api.clique.ChainConfig, tx, api.blockReader, api.logger)
 
 	// Retrieve the requested block number (or current if none requested)
-	var header *types.Header
-	if number == nil || *number == rpc.LatestBlockNumber {
-		header = chain.CurrentHeader()
-	} else {
-		header = chain.GetHeaderByNumber(uint64(number.Int64()))
+	header, err := headerByNumber(chain, number)
+	if err != nil {
+		return nil, err
 	}
 	// Ensure we have an actually valid block and return its snapshot
 	if header == nil {
@@ -74,6 +79,9 @@ func (api *API) GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*Snap
 
 // GetSnapshotAtHash retrieves the state snapshot at a given block.
 func (api *API) GetSnapshotAtHash(ctx context.Context, hash libcommon.Hash) (*Snapshot, error) {
+	if api.clique == nil {
+		return nil, errNotClique
+	}
 	tx, err := api.db.BeginRo(ctx)
 	if err != nil {
 		return nil, err
@@ -96,6 +104,9 @@ func (api *API) GetSnapshotAtHash(ctx context.Context, hash libcommon.Hash) (*Sn
 
 // GetSigners retrieves the list of authorized signers at the specified block.
 func (api *API) GetSigners(ctx context.Context, number *rpc.BlockNumber) ([]libcommon.Address, error) {
+	if api.clique == nil {
+		return nil, errNotClique
+	}
 	tx, err := api.db.BeginRo(ctx)
 	if err != nil {
 		return nil, err
@@ -104,11 +115,9 @@ func (api *API) GetSigners(ctx context.Context, number *rpc.BlockNumber) ([]libc
 	chain := consensuschain.NewReader(api.clique.ChainConfig, tx, api.blockReader, api.logger)
 
 	// Retrieve the requested block number (or current if none requested)
-	var header *types.Header
-	if number == nil || *number == rpc.LatestBlockNumber {
-		header = chain.CurrentHeader()
-	} else {
-		header = chain.GetHeaderByNumber(uint64(number.Int64()))
+	header, err := headerByNumber(chain, number)
+	if err != nil {
+		return nil, err
 	}
 	// Ensure we have an actually valid block and return the signers from its snapshot
 	if header == nil {
@@ -124,6 +133,9 @@ func (api *API) GetSigners(ctx context.Context, number *rpc.BlockNumber) ([]libc
 
 // GetSignersAtHash retrieves the list of authorized signers at the specified block.
 func (api *API) GetSignersAtHash(ctx context.Context, hash libcommon.Hash) ([]libcommon.Address, error) {
+	if api.clique == nil {
+		return nil, errNotClique
+	}
 	tx, err := api.db.BeginRo(ctx)
 	if err != nil {
 		return nil, err
@@ -142,8 +154,51 @@ func (api *API) GetSignersAtHash(ctx context.Context, hash libcommon.Hash) ([]li
 	return snap.GetSigners(), nil
 }
 
+// GetSigner returns the signer of the specified block.
+func (api *API) GetSigner(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (libcommon.Address, error) {
+	if api.clique == nil {
+		return libcommon.Address{}, errNotClique
+	}
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return libcommon.Address{}, err
+	}
+	defer tx.Rollback()
+	chain := consensuschain.NewReader(api.clique.ChainConfig, tx, api.blockReader, api.logger)
+
+	var header *types.Header
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		header = chain.GetHeaderByHash(hash)
+	} else if header, err = headerByNumber(chain, blockNrOrHash.BlockNumber); err != nil {
+		return libcommon.Address{}, err
+	}
+	if header == nil {
+		return libcommon.Address{}, errUnknownBlock
+	}
+	return api.clique.Author(header)
+}
+
+// headerByNumber returns the header of the block, the current one if no block is requested.
+// Pending is served as latest, clique has no notion of safe and finalized blocks.
+func headerByNumber(chain *consensuschain.Reader, number *rpc.BlockNumber) (*types.Header, error) {
+	if number == nil {
+		return chain.CurrentHeader(), nil
+	}
+	switch *number {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		return chain.CurrentHeader(), nil
+	}
+	if *number < 0 {
+		return nil, fmt.Errorf("%s block is not supported by clique", number)
+	}
+	return chain.GetHeaderByNumber(number.Uint64()), nil
+}
+
 // Proposals returns the current proposals the node tries to uphold and vote on.
-func (api *API) Proposals() map[libcommon.Address]bool {
+func (api *API) Proposals() (map[libcommon.Address]bool, error) {
+	if api.clique == nil {
+		return nil, errNotClique
+	}
 	api.clique.lock.RLock()
 	defer api.clique.lock.RUnlock()
 
@@ -151,25 +206,33 @@ func (api *API) Proposals() map[libcommon.Address]bool {
 	for address, auth := range api.clique.proposals {
 		proposals[address] = auth
 	}
-	return proposals
+	return proposals, nil
 }
 
 // Propose injects a new authorization proposal that the signer will attempt to
 // push through.
-func (api *API) Propose(address libcommon.Address, auth bool) {
+func (api *API) Propose(address libcommon.Address, auth bool) error {
+	if api.clique == nil {
+		return errNotClique
+	}
 	api.clique.lock.Lock()
 	defer api.clique.lock.Unlock()
 
 	api.clique.proposals[address] = auth
+	return nil
 }
 
 // Discard drops a currently running proposal, stopping the signer from casting
 // further votes (either for or against).
-func (api *API) Discard(address libcommon.Address) {
+func (api *API) Discard(address libcommon.Address) error {
+	if api.clique == nil {
+		return errNotClique
+	}
 	api.clique.lock.Lock()
 	defer api.clique.lock.Unlock()
 
 	delete(api.clique.proposals, address)
+	return nil
 }
 
 type status struct {
@@ -183,6 +246,9 @@ type status struct {
 // - the number of signers,
 // - the percentage of in-turn blocks
 func (api *API) Status(ctx context.Context) (*status, error) {
+	if api.clique == nil {
+		return nil, errNotClique
+	}
 	tx, err := api.db.BeginRo(ctx)
 	if err != nil {
 		return nil, err
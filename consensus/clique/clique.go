@@ -541,7 +541,7 @@ func NewCliqueAPI(db kv.RoDB, engine consensus.EngineReader, blockReader service
 	return rpc.API{
 		Namespace: "clique",
 		Version:   "1.0",
-		Service:   &API{db: db, clique: c, blockReader: blockReader},
+		Service:   &API{db: db, clique: c, blockReader: blockReader, logger: log.Root()},
 		Public:    false,
 	}
 }
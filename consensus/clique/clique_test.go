@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/length"
@@ -36,6 +37,7 @@ import (
 	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/turbo/stages/mock"
 )
 
@@ -142,3 +144,38 @@ func TestReimportMirroredState(t *testing.T) {
 	}
 
 }
+
+func TestAPIBlockNumbers(t *testing.T) {
+	var (
+		cliqueDB = memdb.NewTestDB(t, kv.ConsensusDB)
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		engine   = clique.New(params.AllCliqueProtocolChanges, params.CliqueSnapshot, cliqueDB, log.New())
+	)
+	genspec := &types.Genesis{
+		ExtraData: make([]byte, clique.ExtraVanity+length.Addr+clique.ExtraSeal),
+		Config:    params.AllCliqueProtocolChanges,
+	}
+	copy(genspec.ExtraData[clique.ExtraVanity:], addr[:])
+	m := mock.MockWithGenesisEngine(t, genspec, engine, false, true)
+	api := clique.NewCliqueAPI(m.DB, engine, m.BlockReader).Service.(*clique.API)
+
+	for _, number := range []rpc.BlockNumber{rpc.EarliestBlockNumber, rpc.LatestBlockNumber, rpc.PendingBlockNumber} {
+		signers, err := api.GetSigners(m.Ctx, &number)
+		require.NoError(t, err, number)
+		require.Equal(t, []libcommon.Address{addr}, signers, number)
+		_, err = api.GetSnapshot(m.Ctx, &number)
+		require.NoError(t, err, number)
+	}
+	for _, number := range []rpc.BlockNumber{rpc.SafeBlockNumber, rpc.FinalizedBlockNumber} {
+		_, err := api.GetSigners(m.Ctx, &number)
+		require.ErrorContains(t, err, "not supported", number)
+		_, err = api.GetSnapshot(m.Ctx, &number)
+		require.ErrorContains(t, err, "not supported", number)
+		_, err = api.GetSigner(m.Ctx, rpc.BlockNumberOrHashWithNumber(number))
+		require.ErrorContains(t, err, "not supported", number)
+	}
+	unknown := rpc.BlockNumber(1)
+	_, err := api.GetSigners(m.Ctx, &unknown)
+	require.Error(t, err)
+}
@@ -28,6 +28,7 @@ import (
 	"math/big"
 	"math/rand"
 	"net/http"
+	"runtime"
 	"sync"
 	"time"
 
@@ -37,6 +38,7 @@ import (
 	"github.com/erigontech/erigon-lib/common/hexutil"
 
 	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/consensus/ethash/ethashcfg"
 	"github.com/erigontech/erigon/core/types"
 )
 
@@ -72,6 +74,40 @@ func (ethash *Ethash) Seal(chain consensus.ChainHeaderReader, block *types.Block
 	return nil
 }
 
+// Mine searches for the nonce satisfying the header difficulty on the calling goroutine and returns
+// the sealed copy of the header. Unlike Seal it doesn't need a remote miner, it's used by the tools
+// sealing single blocks (e.g. evm b11r).
+func (ethash *Ethash) Mine(header *types.Header) (*types.Header, error) {
+	if ethash.shared != nil {
+		return ethash.shared.Mine(header)
+	}
+	header = types.CopyHeader(header)
+	if ethash.config.PowMode == ethashcfg.ModeFake || ethash.config.PowMode == ethashcfg.ModeFullFake {
+		header.Nonce, header.MixDigest = types.BlockNonce{}, libcommon.Hash{}
+		return header, nil
+	}
+	if header.Difficulty == nil || header.Difficulty.Sign() <= 0 {
+		return nil, errInvalidDifficulty
+	}
+	dataset := ethash.dataset(header.Number.Uint64(), false)
+	// Datasets are unmapped in a finalizer, keep it alive while searching
+	defer runtime.KeepAlive(dataset)
+
+	target := new(big.Int).Div(two256, header.Difficulty)
+	hash := ethash.SealHash(header).Bytes()
+	for nonce := uint64(0); ; nonce++ {
+		digest, result := hashimotoFull(dataset.dataset, hash, nonce)
+		if new(big.Int).SetBytes(result).Cmp(target) <= 0 {
+			header.Nonce = types.EncodeNonce(nonce)
+			header.MixDigest = libcommon.BytesToHash(digest)
+			return header, nil
+		}
+		if nonce == math.MaxUint64 {
+			return nil, errInvalidPoW
+		}
+	}
+}
+
 // This is the timeout for HTTP requests to notify external miners.
 const remoteSealerTimeout = 1 * time.Second
 
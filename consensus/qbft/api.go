@@ -0,0 +1,150 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package qbft
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/consensuschain"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/services"
+)
+
+var errNotQBFT = errors.New("consensus engine is not qbft")
+
+// API is a user facing RPC API to inspect the validator set and the consensus
+// instance, and to vote on validators.
+type API struct {
+	db          kv.RoDB
+	qbft        *QBFT
+	logger      log.Logger
+	blockReader services.FullBlockReader
+}
+
+func NewQBFTAPI(db kv.RoDB, engine consensus.EngineReader, blockReader services.FullBlockReader) rpc.API {
+	var q *QBFT
+	if casted, ok := engine.(*QBFT); ok {
+		q = casted
+	}
+
+	return rpc.API{
+		Namespace: "qbft",
+		Version:   "1.0",
+		Service:   &API{db: db, qbft: q, blockReader: blockReader, logger: log.Root()},
+		Public:    false,
+	}
+}
+
+func (api *API) snapshot(ctx context.Context, header func(chain consensus.ChainHeaderReader) *types.Header) (*Snapshot, error) {
+	if api.qbft == nil {
+		return nil, errNotQBFT
+	}
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	chain := consensuschain.NewReader(api.qbft.ChainConfig, tx, api.blockReader, api.logger)
+
+	h := header(chain)
+	if h == nil {
+		return nil, errUnknownBlock
+	}
+	return api.qbft.Snapshot(chain, h.Number.Uint64(), h.Hash(), nil)
+}
+
+// GetSnapshot retrieves the validator set and the voting state at a given block.
+func (api *API) GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*Snapshot, error) {
+	return api.snapshot(ctx, func(chain consensus.ChainHeaderReader) *types.Header {
+		if number == nil || *number == rpc.LatestBlockNumber {
+			return chain.CurrentHeader()
+		}
+		return chain.GetHeaderByNumber(uint64(number.Int64()))
+	})
+}
+
+// GetValidatorsByBlockNumber retrieves the validators of the block after the given one.
+func (api *API) GetValidatorsByBlockNumber(ctx context.Context, number *rpc.BlockNumber) ([]libcommon.Address, error) {
+	snap, err := api.GetSnapshot(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return snap.Validators, nil
+}
+
+// GetValidatorsByBlockHash retrieves the validators of the block after the given one.
+func (api *API) GetValidatorsByBlockHash(ctx context.Context, hash libcommon.Hash) ([]libcommon.Address, error) {
+	snap, err := api.snapshot(ctx, func(chain consensus.ChainHeaderReader) *types.Header {
+		return chain.GetHeaderByHash(hash)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap.Validators, nil
+}
+
+// ProposeValidatorVote injects a vote to add or remove a validator which this validator
+// casts in the blocks it proposes.
+func (api *API) ProposeValidatorVote(address libcommon.Address, authorize bool) error {
+	if api.qbft == nil {
+		return errNotQBFT
+	}
+	api.qbft.lock.Lock()
+	defer api.qbft.lock.Unlock()
+
+	api.qbft.proposals[address] = authorize
+	return nil
+}
+
+// DiscardValidatorVote drops a vote, stopping this validator from casting it further.
+func (api *API) DiscardValidatorVote(address libcommon.Address) error {
+	if api.qbft == nil {
+		return errNotQBFT
+	}
+	api.qbft.lock.Lock()
+	defer api.qbft.lock.Unlock()
+
+	delete(api.qbft.proposals, address)
+	return nil
+}
+
+// GetPendingVotes returns the votes this validator casts.
+func (api *API) GetPendingVotes() (map[libcommon.Address]bool, error) {
+	if api.qbft == nil {
+		return nil, errNotQBFT
+	}
+	api.qbft.lock.RLock()
+	defer api.qbft.lock.RUnlock()
+
+	return maps.Clone(api.qbft.proposals), nil
+}
+
+// Status returns the height and round of the consensus instance of this validator.
+func (api *API) Status() (*Status, error) {
+	if api.qbft == nil {
+		return nil, errNotQBFT
+	}
+	return api.qbft.Status(), nil
+}
@@ -0,0 +1,673 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package qbft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/debug"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types/accounts"
+
+	"github.com/erigontech/erigon/core/types"
+)
+
+const (
+	maxClockDrift       = 5 * time.Second // How far in the future a proposed block may be
+	maxFutureHeights    = 16              // Heights ahead of the current one to buffer messages for
+	maxFutureMessages   = 16              // Messages buffered per validator and height
+	maxFutureTotal      = 4096            // Messages buffered in total, authors are unknown before the first height
+	maxRoundTimeoutExp  = 10              // Round timeouts stop doubling after this round
+	inboundMessageQueue = 1024
+)
+
+// sealRequest is a candidate block of a height, see QBFT.Seal.
+type sealRequest struct {
+	block   *types.BlockWithReceipts
+	parent  *types.Header
+	snap    *Snapshot // Validators of the height
+	signer  libcommon.Address
+	signFn  SignerFn
+	results chan<- *types.BlockWithReceipts
+}
+
+// Status is the state of the consensus instance of this validator.
+type Status struct {
+	Height     uint64            `json:"height"`
+	Round      uint64            `json:"round"`
+	Active     bool              `json:"active"`     // Whether the height is still being agreed on
+	Proposer   libcommon.Address `json:"proposer"`   // Proposer of the round
+	Validators int               `json:"validators"` // Size of the validator set of the height
+	Prepared   bool              `json:"prepared"`   // Whether this validator is locked on a block
+}
+
+// core runs the consensus instance of the current height. All its fields except status
+// are only touched by the run goroutine.
+//
+// An instance goes through rounds. In every round the proposer of the round broadcasts
+// a proposal; validators accept it and broadcast prepare messages. When a quorum of
+// prepares is seen the validator is prepared (locked) on the block and broadcasts its
+// commit message with the committed seal. A quorum of commits makes the block final.
+// If the round timer expires first, validators broadcast round change messages with
+// the block they are locked on. The proposer of the next round starts it when it sees
+// a quorum of round changes and must re-propose the highest locked block among them,
+// which keeps a block committed in any round the only one which can be committed.
+type core struct {
+	engine *QBFT
+	logger log.Logger
+
+	msgCh    chan *message
+	sealCh   chan *sealRequest
+	quit     chan struct{}
+	quitOnce sync.Once
+
+	req           *sealRequest
+	active        bool
+	height        uint64
+	round         uint64
+	proposal      *message // Accepted proposal of the round
+	sentCommit    bool
+	prepared      *message // Proposal the validator is locked on
+	preparedRound uint64
+	preparedCert  [][]byte // Quorum of prepare messages of the locked proposal
+	prepares      map[uint64]map[libcommon.Address]*message
+	commits       map[uint64]map[libcommon.Address]*message
+	roundChanges  map[uint64]map[libcommon.Address]*message
+	future        map[uint64]map[libcommon.Address][]*message
+	futureCount   int
+	verified      map[libcommon.Hash]error // Execution results of the proposed blocks of the height
+	committed     *types.BlockWithReceipts // Block of the height with the committed seals seen by this validator
+
+	roundTimer   *time.Timer
+	proposeTimer *time.Timer
+
+	status atomic.Pointer[Status]
+}
+
+func newCore(engine *QBFT, logger log.Logger) *core {
+	c := &core{
+		engine:       engine,
+		logger:       logger,
+		msgCh:        make(chan *message, inboundMessageQueue),
+		sealCh:       make(chan *sealRequest),
+		quit:         make(chan struct{}),
+		future:       make(map[uint64]map[libcommon.Address][]*message),
+		roundTimer:   time.NewTimer(time.Hour),
+		proposeTimer: time.NewTimer(time.Hour),
+	}
+	c.roundTimer.Stop()
+	c.proposeTimer.Stop()
+	c.status.Store(&Status{})
+	return c
+}
+
+func (c *core) run() {
+	defer debug.LogPanic()
+	for {
+		select {
+		case <-c.quit:
+			c.roundTimer.Stop()
+			c.proposeTimer.Stop()
+			return
+		case req := <-c.sealCh:
+			c.handleSeal(req)
+		case m := <-c.msgCh:
+			c.handleMessage(m)
+		case <-c.roundTimer.C:
+			c.handleTimeout()
+		case <-c.proposeTimer.C:
+			if c.active && c.round == 0 && c.proposal == nil && c.isProposer() {
+				c.propose(c.req.block.Block, nil)
+			}
+		}
+	}
+}
+
+func (c *core) stop() {
+	c.quitOnce.Do(func() { close(c.quit) })
+}
+
+// seal hands a candidate block to the instance of its height.
+func (c *core) seal(req *sealRequest) {
+	select {
+	case c.sealCh <- req:
+	case <-c.quit:
+	}
+}
+
+// deliver queues a message received from the network, dropping it if the instance is overloaded.
+func (c *core) deliver(m *message) {
+	select {
+	case c.msgCh <- m:
+	default:
+		c.logger.Debug("[qbft] Dropping message, queue is full", "msg", m)
+	}
+}
+
+func (c *core) handleSeal(req *sealRequest) {
+	height := req.block.Block.NumberU64()
+	switch {
+	case height < c.height:
+		c.sendResult(req, nil)
+	case height == c.height && !c.active:
+		// The miner seals the height again when the block of the proposer didn't arrive,
+		// publish the block with the seals this validator saw instead
+		c.sendResult(req, c.committed)
+	case height == c.height:
+		// A fresher candidate block of the current height, used if this validator proposes later
+		c.req = req
+	default:
+		c.startHeight(req)
+	}
+}
+
+func (c *core) startHeight(req *sealRequest) {
+	c.req, c.active, c.committed = req, true, nil
+	c.height = req.block.Block.NumberU64()
+	c.prepared, c.preparedRound, c.preparedCert = nil, 0, nil
+	c.prepares = make(map[uint64]map[libcommon.Address]*message)
+	c.commits = make(map[uint64]map[libcommon.Address]*message)
+	c.roundChanges = make(map[uint64]map[libcommon.Address]*message)
+	c.verified = make(map[libcommon.Hash]error)
+	c.engine.observe(req.snap)
+	c.startRound(0)
+
+	// Replay the messages which arrived before this validator got to the height
+	buffered := c.future[c.height]
+	for height, byAuthor := range c.future {
+		if height <= c.height {
+			for _, msgs := range byAuthor {
+				c.futureCount -= len(msgs)
+			}
+			delete(c.future, height)
+		}
+	}
+	for _, msgs := range buffered {
+		for _, m := range msgs {
+			c.handleMessage(m)
+		}
+	}
+}
+
+func (c *core) startRound(round uint64) {
+	c.round, c.proposal, c.sentCommit = round, nil, false
+	for r := range c.prepares {
+		if r < round {
+			delete(c.prepares, r)
+			delete(c.commits, r)
+		}
+	}
+	for r := range c.roundChanges {
+		if r < round {
+			delete(c.roundChanges, r)
+		}
+	}
+
+	timeout := time.Duration(c.engine.config.RequestTimeout) * time.Second << min(round, maxRoundTimeoutExp)
+	proposeAt := time.Until(time.Unix(int64(c.req.block.Block.Time()), 0))
+	if round == 0 && proposeAt > 0 {
+		timeout += proposeAt
+	}
+	resetTimer(c.roundTimer, timeout)
+	c.proposeTimer.Stop()
+	if round == 0 && c.isProposer() {
+		// Proposals of later rounds are sent when a quorum of round changes is seen
+		resetTimer(c.proposeTimer, max(proposeAt, 0))
+	}
+	c.updateStatus()
+	c.logger.Debug("[qbft] Starting round", "height", c.height, "round", round, "proposer", c.proposer(round))
+}
+
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
+
+func (c *core) proposer(round uint64) libcommon.Address {
+	return c.req.snap.ProposerForRound(round)
+}
+
+func (c *core) isProposer() bool {
+	return c.proposer(c.round) == c.req.signer
+}
+
+func (c *core) quorum() int {
+	return c.req.snap.Quorum()
+}
+
+func (c *core) updateStatus() {
+	status := &Status{Height: c.height, Round: c.round, Active: c.active, Prepared: c.prepared != nil}
+	if c.req != nil {
+		status.Proposer, status.Validators = c.proposer(c.round), len(c.req.snap.Validators)
+	}
+	c.status.Store(status)
+}
+
+// broadcast signs the message, sends it to the peers and handles it as if it was received.
+func (c *core) broadcast(m *message) {
+	m.Height = c.height
+	if err := m.sign(c.req.signer, c.req.signFn); err != nil {
+		c.logger.Warn("[qbft] Can't sign message", "msg", m, "err", err)
+		return
+	}
+	c.engine.broadcast(m.Code, m.encode())
+	c.handleMessage(m)
+}
+
+func (c *core) propose(block *types.Block, justification [][]byte) {
+	c.logger.Debug("[qbft] Proposing block", "height", c.height, "round", c.round, "hash", SealHash(block.Header()))
+	c.broadcast(&message{
+		Code:          msgProposal,
+		Round:         c.round,
+		Digest:        SealHash(block.Header()),
+		Block:         encodeBlock(block),
+		Justification: justification,
+		block:         block,
+	})
+}
+
+func (c *core) handleMessage(m *message) {
+	switch {
+	case m.Height > c.height:
+		c.bufferFuture(m)
+		return
+	case m.Height < c.height || !c.active:
+		return
+	case !c.req.snap.IsValidator(m.author):
+		c.logger.Debug("[qbft] Message from non-validator", "msg", m)
+		return
+	}
+
+	var err error
+	switch m.Code {
+	case msgProposal:
+		err = c.handleProposal(m)
+	case msgPrepare:
+		c.handlePrepare(m)
+	case msgCommit:
+		err = c.handleCommit(m)
+	case msgRoundChange:
+		err = c.handleRoundChange(m)
+	}
+	if err != nil {
+		c.logger.Debug("[qbft] Invalid message", "msg", m, "err", err)
+	}
+}
+
+// bufferFuture keeps a message of a later height. Only validators of the current height are
+// buffered for, and maxFutureTotal bounds the buffer before the first height. If more validators
+// than the set tolerates to be faulty are at later heights, the network moved on: the instance
+// is abandoned and the node catches up by syncing the committed blocks.
+func (c *core) bufferFuture(m *message) {
+	if m.Height > c.height+maxFutureHeights || c.futureCount >= maxFutureTotal {
+		return
+	}
+	if c.req != nil && !c.req.snap.IsValidator(m.author) {
+		return
+	}
+	byAuthor, ok := c.future[m.Height]
+	if !ok {
+		byAuthor = make(map[libcommon.Address][]*message)
+		c.future[m.Height] = byAuthor
+	}
+	if len(byAuthor[m.author]) < maxFutureMessages {
+		byAuthor[m.author] = append(byAuthor[m.author], m)
+		c.futureCount++
+	}
+	if !c.active {
+		return
+	}
+	ahead := make(map[libcommon.Address]struct{})
+	for _, byAuthor := range c.future {
+		for author := range byAuthor {
+			if c.req.snap.IsValidator(author) {
+				ahead[author] = struct{}{}
+			}
+		}
+	}
+	if len(ahead) > c.req.snap.faulty() {
+		c.logger.Info("[qbft] Validators moved to a later height, catching up", "height", c.height, "round", c.round)
+		c.finish(nil)
+	}
+}
+
+func (c *core) handleProposal(m *message) error {
+	if m.Round < c.round || (m.Round == c.round && c.proposal != nil) {
+		return nil
+	}
+	if m.author != c.proposer(m.Round) {
+		return fmt.Errorf("proposal from %x, proposer of the round is %x", m.author, c.proposer(m.Round))
+	}
+	if err := c.validateProposal(m); err != nil {
+		return err
+	}
+	if m.Round > c.round {
+		// The round change quorum in the justification moves this validator to the round too
+		c.startRound(m.Round)
+	}
+	c.proposal = m
+	c.broadcast(&message{Code: msgPrepare, Round: m.Round, Digest: m.Digest})
+	c.checkPrepared()
+	c.checkCommitted()
+	return nil
+}
+
+func (c *core) validateProposal(m *message) error {
+	if m.block == nil {
+		return errors.New("proposal without block")
+	}
+	header := m.block.Header()
+	if header.Number.Uint64() != c.height || header.ParentHash != c.req.parent.Hash() {
+		return fmt.Errorf("proposed block %d is not a child of %x", header.Number.Uint64(), c.req.parent.Hash())
+	}
+	if SealHash(header) != m.Digest {
+		return errors.New("proposal digest mismatch")
+	}
+	if time.Unix(int64(header.Time), 0).After(time.Now().Add(maxClockDrift)) {
+		return errInvalidTimestamp
+	}
+	extra, err := c.engine.verifyStandaloneFields(header)
+	if err != nil {
+		return err
+	}
+	if err := c.engine.verifyCascadingFields(c.req.parent, c.req.snap, header, extra); err != nil {
+		return err
+	}
+	if m.Round == 0 {
+		return c.executeProposal(m)
+	}
+
+	// Later rounds must be started by a quorum of round changes and re-propose the
+	// block with the highest prepared round among them
+	var highest *message
+	authors := make(map[libcommon.Address]struct{})
+	for _, data := range m.Justification {
+		rc, err := decodeMessage(data)
+		if err != nil {
+			return err
+		}
+		if rc.Code != msgRoundChange || rc.Height != c.height || rc.Round != m.Round || !c.req.snap.IsValidator(rc.author) {
+			return errors.New("invalid round change in justification")
+		}
+		if err := c.validatePreparedCert(rc); err != nil {
+			return err
+		}
+		authors[rc.author] = struct{}{}
+		if rc.block != nil && (highest == nil || rc.PreparedRound > highest.PreparedRound) {
+			highest = rc
+		}
+	}
+	if len(authors) < c.quorum() {
+		return errors.New("round change justification without quorum")
+	}
+	if highest != nil && highest.Digest != m.Digest {
+		return errors.New("proposal doesn't re-propose the highest prepared block")
+	}
+	return c.executeProposal(m)
+}
+
+// executeProposal executes the proposed block before this validator prepares it, unless it
+// is the block this validator built itself. Re-proposals in later rounds are not executed again.
+func (c *core) executeProposal(m *message) error {
+	if m.Digest == SealHash(c.req.block.Block.Header()) {
+		return nil
+	}
+	err, ok := c.verified[m.Digest]
+	if !ok {
+		err = c.engine.verifyBlock(m.block)
+		c.verified[m.Digest] = err
+	}
+	if err != nil {
+		return fmt.Errorf("proposed block execution: %w", err)
+	}
+	return nil
+}
+
+func (c *core) handlePrepare(m *message) {
+	if m.Round < c.round {
+		return
+	}
+	store(c.prepares, m)
+	c.checkPrepared()
+}
+
+func (c *core) handleCommit(m *message) error {
+	if m.Round < c.round {
+		return nil
+	}
+	validator, err := recoverCommitSeal(m.Digest, m.CommitSeal)
+	if err != nil {
+		return err
+	}
+	if validator != m.author {
+		return errors.New("committed seal is not signed by the message author")
+	}
+	store(c.commits, m)
+	c.checkCommitted()
+	return nil
+}
+
+func store(byRound map[uint64]map[libcommon.Address]*message, m *message) {
+	msgs, ok := byRound[m.Round]
+	if !ok {
+		msgs = make(map[libcommon.Address]*message)
+		byRound[m.Round] = msgs
+	}
+	msgs[m.author] = m
+}
+
+// matching returns the messages of the round for the digest of the accepted proposal
+func (c *core) matching(byRound map[uint64]map[libcommon.Address]*message) []*message {
+	var res []*message
+	for _, m := range byRound[c.round] {
+		if m.Digest == c.proposal.Digest {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+func (c *core) checkPrepared() {
+	if !c.active || c.proposal == nil || c.sentCommit {
+		return
+	}
+	prepares := c.matching(c.prepares)
+	if len(prepares) < c.quorum() {
+		return
+	}
+	c.sentCommit = true
+	c.prepared, c.preparedRound = c.proposal, c.round
+	c.preparedCert = make([][]byte, len(prepares))
+	for i, p := range prepares {
+		c.preparedCert[i] = p.encode()
+	}
+	c.updateStatus()
+
+	seal, err := c.req.signFn(c.req.signer, accounts.MimetypeQBFT, commitSealData(c.proposal.Digest))
+	if err != nil {
+		c.logger.Warn("[qbft] Can't sign committed seal", "err", err)
+		return
+	}
+	c.broadcast(&message{Code: msgCommit, Round: c.round, Digest: c.proposal.Digest, CommitSeal: seal})
+}
+
+func (c *core) checkCommitted() {
+	if !c.active || c.proposal == nil {
+		return
+	}
+	commits := c.matching(c.commits)
+	if len(commits) < c.quorum() {
+		return
+	}
+	slices.SortFunc(commits, func(a, b *message) int { return bytes.Compare(a.author[:], b.author[:]) })
+	seals := make([][]byte, len(commits))
+	for i, m := range commits {
+		seals[i] = m.CommitSeal
+	}
+
+	proposed := c.proposal.block
+	extra, err := DecodeExtra(proposed.HeaderNoCopy())
+	if err != nil {
+		c.logger.Warn("[qbft] Can't decode committed block", "err", err)
+		return
+	}
+	extra.Round, extra.CommittedSeals = c.round, seals
+	header := withExtra(proposed.HeaderNoCopy(), extra.Encode())
+
+	committed := &types.BlockWithReceipts{Block: proposed.WithSeal(header)}
+	if SealHash(c.req.block.Block.Header()) == c.proposal.Digest {
+		committed.Receipts, committed.Requests = c.req.block.Receipts, c.req.block.Requests
+	}
+	c.logger.Info("[qbft] Committed block", "number", c.height, "hash", header.Hash(), "round", c.round,
+		"proposer", header.Coinbase, "seals", len(seals))
+
+	// Validators see different quorums of commits and the seals are part of the block hash,
+	// so only the proposer of the round publishes the block to keep a single block per height
+	c.committed = committed
+	if c.proposal.author != c.req.signer {
+		committed = nil
+	}
+	c.finish(committed)
+}
+
+// finish ends the instance of the height and hands the result to the miner.
+func (c *core) finish(block *types.BlockWithReceipts) {
+	c.active = false
+	c.roundTimer.Stop()
+	c.proposeTimer.Stop()
+	c.updateStatus()
+	c.sendResult(c.req, block)
+}
+
+func (c *core) sendResult(req *sealRequest, block *types.BlockWithReceipts) {
+	select {
+	case req.results <- block:
+	default:
+		c.logger.Warn("[qbft] Sealing result is not read by miner", "height", req.block.Block.NumberU64())
+	}
+}
+
+func (c *core) handleTimeout() {
+	if !c.active {
+		return
+	}
+	c.logger.Info("[qbft] Round timed out", "height", c.height, "round", c.round, "proposer", c.proposer(c.round))
+	c.startRound(c.round + 1)
+	c.sendRoundChange()
+}
+
+func (c *core) sendRoundChange() {
+	m := &message{Code: msgRoundChange, Round: c.round}
+	if c.prepared != nil {
+		m.Digest, m.Block, m.block = c.prepared.Digest, c.prepared.Block, c.prepared.block
+		m.PreparedRound, m.Justification = c.preparedRound, c.preparedCert
+	}
+	c.broadcast(m)
+}
+
+func (c *core) handleRoundChange(m *message) error {
+	if m.Round < c.round {
+		return nil
+	}
+	if err := c.validatePreparedCert(m); err != nil {
+		return err
+	}
+	store(c.roundChanges, m)
+
+	if m.Round > c.round {
+		// More validators than can be faulty want a later round: move to the lowest of them
+		lowest, authors := m.Round, make(map[libcommon.Address]struct{})
+		for r, msgs := range c.roundChanges {
+			if r <= c.round {
+				continue
+			}
+			for author := range msgs {
+				authors[author] = struct{}{}
+			}
+			lowest = min(lowest, r)
+		}
+		if len(authors) <= c.req.snap.faulty() {
+			return nil
+		}
+		c.startRound(lowest)
+		c.sendRoundChange()
+		return nil
+	}
+	c.tryProposeForRound()
+	return nil
+}
+
+// validatePreparedCert checks the block a round change message is locked on.
+func (c *core) validatePreparedCert(rc *message) error {
+	if rc.block == nil {
+		return nil
+	}
+	if rc.PreparedRound >= rc.Round || SealHash(rc.block.Header()) != rc.Digest {
+		return errors.New("invalid prepared block in round change")
+	}
+	authors := make(map[libcommon.Address]struct{})
+	for _, data := range rc.Justification {
+		p, err := decodeMessage(data)
+		if err != nil {
+			return err
+		}
+		if p.Code != msgPrepare || p.Height != c.height || p.Round != rc.PreparedRound || p.Digest != rc.Digest || !c.req.snap.IsValidator(p.author) {
+			return errors.New("invalid prepare in round change")
+		}
+		authors[p.author] = struct{}{}
+	}
+	if len(authors) < c.quorum() {
+		return errors.New("prepared block without quorum")
+	}
+	return nil
+}
+
+// tryProposeForRound proposes in a round after round 0 once a quorum of round changes is seen.
+func (c *core) tryProposeForRound() {
+	if c.round == 0 || c.proposal != nil || !c.isProposer() {
+		return
+	}
+	rcs := c.roundChanges[c.round]
+	if len(rcs) < c.quorum() {
+		return
+	}
+	block := c.req.block.Block
+	var highest *message
+	justification := make([][]byte, 0, len(rcs))
+	for _, rc := range rcs {
+		justification = append(justification, rc.encode())
+		if rc.block != nil && (highest == nil || rc.PreparedRound > highest.PreparedRound) {
+			highest = rc
+		}
+	}
+	if highest != nil {
+		block = highest.block
+	}
+	c.propose(block, justification)
+}
@@ -0,0 +1,323 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package qbft
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/consensus/misc"
+	"github.com/erigontech/erigon/core/types"
+)
+
+// network connects engines directly, every broadcast reaches all online engines
+type network struct {
+	engines []*QBFT
+	offline map[int]bool
+}
+
+type transport struct {
+	net  *network
+	from int
+}
+
+func (t *transport) Broadcast(code uint64, data []byte) int {
+	sent := 0
+	for i, q := range t.net.engines {
+		if i == t.from || t.net.offline[i] || t.net.offline[t.from] {
+			continue
+		}
+		if err := q.HandleMessage(code, data); err != nil {
+			panic(err)
+		}
+		sent++
+	}
+	return sent
+}
+
+type validator struct {
+	key    *ecdsa.PrivateKey
+	addr   libcommon.Address
+	engine *QBFT
+}
+
+func newTestNetwork(t *testing.T, n int) (*network, []*validator, *types.Header) {
+	config := &chain.Config{
+		ChainID:     big.NewInt(1338),
+		Consensus:   chain.QBFTConsensus,
+		LondonBlock: big.NewInt(0),
+		QBFT:        &chain.QBFTConfig{BlockPeriod: 1, Epoch: 30000, RequestTimeout: 1},
+	}
+	net := &network{offline: make(map[int]bool)}
+	validators := make([]*validator, n)
+	addrs := make([]libcommon.Address, n)
+	for i := range validators {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		v := &validator{key: key, addr: crypto.PubkeyToAddress(key.PublicKey), engine: New(config, log.New())}
+		v.engine.Authorize(v.addr, func(_ libcommon.Address, _ string, msg []byte) ([]byte, error) {
+			return crypto.Sign(crypto.Keccak256(msg), key)
+		})
+		v.engine.AddTransport(&transport{net: net, from: i})
+		t.Cleanup(func() { v.engine.Close() })
+		validators[i], addrs[i] = v, v.addr
+		net.engines = append(net.engines, v.engine)
+	}
+	genesis := &types.Header{
+		Number:     big.NewInt(0),
+		Time:       uint64(time.Now().Unix()) - 10,
+		GasLimit:   30_000_000,
+		BaseFee:    big.NewInt(1_000_000_000),
+		Difficulty: big.NewInt(1),
+		UncleHash:  types.EmptyUncleHash,
+		Extra:      GenesisExtra(addrs),
+	}
+	return net, validators, genesis
+}
+
+func (v *validator) seal(t *testing.T, parent *types.Header, results chan *types.BlockWithReceipts) *Snapshot {
+	extra, err := DecodeExtra(parent)
+	require.NoError(t, err)
+	snap := newSnapshot(v.engine.config.Epoch, parent, extra.Validators)
+
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     big.NewInt(1),
+		Time:       parent.Time + v.engine.config.BlockPeriod,
+		Coinbase:   v.addr,
+		GasLimit:   parent.GasLimit,
+		BaseFee:    misc.CalcBaseFee(v.engine.ChainConfig, parent),
+		Difficulty: big.NewInt(1),
+		MixDigest:  Digest,
+		UncleHash:  types.EmptyUncleHash,
+		Extra:      (&Extra{Vanity: make([]byte, ExtraVanity), Validators: snap.Validators}).Encode(),
+	}
+	v.engine.core.seal(&sealRequest{
+		block:   &types.BlockWithReceipts{Block: types.NewBlockWithHeader(header)},
+		parent:  parent,
+		snap:    snap,
+		signer:  v.addr,
+		signFn:  v.engine.signFn,
+		results: results,
+	})
+	return snap
+}
+
+// collect waits for the sealing results, nil for validators which didn't propose the block
+func collect(t *testing.T, results []chan *types.BlockWithReceipts, timeout time.Duration) []*types.Block {
+	blocks := make([]*types.Block, len(results))
+	for i, ch := range results {
+		select {
+		case res := <-ch:
+			if res != nil {
+				blocks[i] = res.Block
+			}
+		case <-time.After(timeout):
+			t.Fatal("block not committed")
+		}
+	}
+	return blocks
+}
+
+// proposed returns the only block published by the validators
+func proposed(t *testing.T, blocks []*types.Block) *types.Block {
+	var res *types.Block
+	for _, block := range blocks {
+		if block != nil {
+			require.Nil(t, res, "more than one validator published the block")
+			res = block
+		}
+	}
+	require.NotNil(t, res)
+	return res
+}
+
+func TestCommit(t *testing.T) {
+	_, validators, genesis := newTestNetwork(t, 4)
+
+	var snap *Snapshot
+	results := make([]chan *types.BlockWithReceipts, len(validators))
+	for i, v := range validators {
+		results[i] = make(chan *types.BlockWithReceipts, 1)
+		snap = v.seal(t, genesis, results[i])
+	}
+	block := proposed(t, collect(t, results, 5*time.Second))
+	require.Equal(t, snap.ProposerForRound(0), block.Coinbase())
+
+	extra, err := DecodeExtra(block.Header())
+	require.NoError(t, err)
+	require.Zero(t, extra.Round)
+	require.NoError(t, verifyCommittedSeals(snap, block.Header(), extra))
+
+	// Seals of a different block don't verify
+	header := block.Header()
+	header.Time++
+	require.ErrorIs(t, verifyCommittedSeals(snap, header, extra), errInvalidCommittedSeals)
+
+	// Sealing the height again publishes the block with the seals the validator saw
+	for i, v := range validators {
+		if v.addr == block.Coinbase() {
+			continue
+		}
+		v.seal(t, genesis, results[i])
+		res := <-results[i]
+		require.NotNil(t, res)
+		require.Equal(t, SealHash(block.Header()), SealHash(res.Block.Header()))
+		extra, err := DecodeExtra(res.Block.Header())
+		require.NoError(t, err)
+		require.NoError(t, verifyCommittedSeals(snap, res.Block.Header(), extra))
+	}
+}
+
+func TestRoundChange(t *testing.T) {
+	net, validators, genesis := newTestNetwork(t, 4)
+
+	// The proposer of round 0 is down, the others must agree on the block of the round 1 proposer
+	extra, err := DecodeExtra(genesis)
+	require.NoError(t, err)
+	snap := newSnapshot(30000, genesis, extra.Validators)
+	for i, v := range validators {
+		if v.addr == snap.ProposerForRound(0) {
+			net.offline[i] = true
+		}
+	}
+	var results []chan *types.BlockWithReceipts
+	for i, v := range validators {
+		if net.offline[i] {
+			continue
+		}
+		ch := make(chan *types.BlockWithReceipts, 1)
+		v.seal(t, genesis, ch)
+		results = append(results, ch)
+	}
+	block := proposed(t, collect(t, results, 10*time.Second))
+	require.Equal(t, snap.ProposerForRound(1), block.Coinbase())
+
+	extra, err = DecodeExtra(block.Header())
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), extra.Round)
+	require.Len(t, extra.CommittedSeals, snap.Quorum())
+	require.NoError(t, verifyCommittedSeals(snap, block.Header(), extra))
+}
+
+type testVerifier struct {
+	err   error
+	calls atomic.Int32
+}
+
+func (v *testVerifier) VerifyBlock(context.Context, *types.Block) error {
+	v.calls.Add(1)
+	return v.err
+}
+
+func TestProposalExecution(t *testing.T) {
+	_, validators, genesis := newTestNetwork(t, 4)
+	verifier := &testVerifier{}
+	for _, v := range validators {
+		v.engine.SetBlockVerifier(verifier)
+	}
+	results := make([]chan *types.BlockWithReceipts, len(validators))
+	for i, v := range validators {
+		results[i] = make(chan *types.BlockWithReceipts, 1)
+		v.seal(t, genesis, results[i])
+	}
+	proposed(t, collect(t, results, 5*time.Second))
+	// Every validator but the proposer executed the block once
+	require.EqualValues(t, len(validators)-1, verifier.calls.Load())
+
+	// Validators don't prepare blocks which fail execution
+	_, validators, genesis = newTestNetwork(t, 4)
+	verifier = &testVerifier{err: errors.New("state root mismatch")}
+	for i, v := range validators {
+		v.engine.SetBlockVerifier(verifier)
+		results[i] = make(chan *types.BlockWithReceipts, 1)
+		v.seal(t, genesis, results[i])
+	}
+	for _, ch := range results {
+		select {
+		case <-ch:
+			t.Fatal("block failing execution was committed")
+		case <-time.After(1500 * time.Millisecond):
+		}
+	}
+}
+
+type countingTransport struct{ sent int }
+
+func (t *countingTransport) Broadcast(uint64, []byte) int {
+	t.sent++
+	return 1
+}
+
+func TestNonValidatorMessages(t *testing.T) {
+	_, validators, genesis := newTestNetwork(t, 4)
+	extra, err := DecodeExtra(genesis)
+	require.NoError(t, err)
+	snap := newSnapshot(30000, genesis, extra.Validators)
+
+	outsiderKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	outsider := crypto.PubkeyToAddress(outsiderKey.PublicKey)
+	signed := func(key *ecdsa.PrivateKey, height uint64) []byte {
+		m := &message{Code: msgPrepare, Height: height}
+		require.NoError(t, m.sign(crypto.PubkeyToAddress(key.PublicKey), func(_ libcommon.Address, _ string, msg []byte) ([]byte, error) {
+			return crypto.Sign(crypto.Keccak256(msg), key)
+		}))
+		return m.encode()
+	}
+
+	// Only messages of validators are gossiped
+	q := New(validators[0].engine.ChainConfig, log.New())
+	t.Cleanup(func() { q.Close() })
+	transport := &countingTransport{}
+	q.AddTransport(transport)
+	q.observe(snap)
+	require.NoError(t, q.HandleMessage(msgPrepare, signed(outsiderKey, 5)))
+	require.Zero(t, transport.sent)
+	require.NoError(t, q.HandleMessage(msgPrepare, signed(validators[1].key, 5)))
+	require.Equal(t, 1, transport.sent)
+
+	// Future messages are only buffered for validators
+	c := newCore(q, log.New())
+	c.req, c.height = &sealRequest{snap: snap}, 1
+	c.handleMessage(&message{Code: msgPrepare, Height: 2, author: outsider})
+	require.Zero(t, c.futureCount)
+	c.handleMessage(&message{Code: msgPrepare, Height: 2, author: validators[1].addr})
+	require.Equal(t, 1, c.futureCount)
+
+	// Before the first height the buffer is bounded
+	c = newCore(q, log.New())
+	for i := 0; i < 300; i++ {
+		author := libcommon.BigToAddress(big.NewInt(int64(i)))
+		for height := uint64(1); height <= maxFutureHeights; height++ {
+			c.handleMessage(&message{Code: msgPrepare, Height: height, author: author})
+		}
+	}
+	require.Equal(t, maxFutureTotal, c.futureCount)
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package qbft
+
+import (
+	"errors"
+	"fmt"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/rlp"
+
+	"github.com/erigontech/erigon/core/types"
+)
+
+const ExtraVanity = 32 // Fixed number of extra-data prefix bytes reserved for proposer vanity
+
+// Digest is the mix digest of all QBFT blocks, as in other IBFT family clients
+var Digest = libcommon.HexToHash("0x63746963616c2062797a616e74696e65206661756c7420746f6c6572616e6365")
+
+var (
+	// errInvalidExtra is returned if the extra-data of a header can't be decoded.
+	errInvalidExtra = errors.New("invalid qbft extra-data")
+
+	// errInvalidVanity is returned if the vanity of the extra-data is not 32 bytes.
+	errInvalidVanity = errors.New("extra-data vanity is not 32 bytes")
+)
+
+// ValidatorVote is a vote of the block proposer to add or remove a validator
+type ValidatorVote struct {
+	Recipient libcommon.Address
+	Authorize bool
+}
+
+// Extra is the QBFT part of the header extra-data. The whole extra-data is its RLP encoding.
+//
+// Validators is the validator set after the vote of the block is counted, so it's the set which
+// proposes and commits the next block. Round and CommittedSeals are excluded from the seal hash:
+// they are only known after the validators agreed on the block.
+type Extra struct {
+	Vanity         []byte
+	Validators     []libcommon.Address
+	Vote           []ValidatorVote // At most one vote
+	Round          uint64
+	CommittedSeals [][]byte
+}
+
+// DecodeExtra decodes the QBFT extra-data of the header.
+func DecodeExtra(header *types.Header) (*Extra, error) {
+	extra := new(Extra)
+	if err := rlp.DecodeBytes(header.Extra, extra); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidExtra, err)
+	}
+	if len(extra.Vanity) != ExtraVanity {
+		return nil, errInvalidVanity
+	}
+	if len(extra.Vote) > 1 {
+		return nil, fmt.Errorf("%w: more than one vote", errInvalidExtra)
+	}
+	return extra, nil
+}
+
+// Encode returns the RLP encoding of the extra-data.
+func (e *Extra) Encode() []byte {
+	enc, err := rlp.EncodeToBytes(e)
+	if err != nil {
+		panic("can't encode: " + err.Error())
+	}
+	return enc
+}
+
+// GenesisExtra returns the extra-data of a genesis block with the initial validator set.
+func GenesisExtra(validators []libcommon.Address) []byte {
+	validators = append([]libcommon.Address{}, validators...)
+	sortAddresses(validators)
+	extra := &Extra{Vanity: make([]byte, ExtraVanity), Validators: validators}
+	return extra.Encode()
+}
+
+// SealHash returns the hash of a block before it's agreed on: the hash of the header
+// without the round and the committed seals. It's the digest validators vote on.
+func SealHash(header *types.Header) libcommon.Hash {
+	extra, err := DecodeExtra(header)
+	if err != nil {
+		return libcommon.Hash{}
+	}
+	extra.Round, extra.CommittedSeals = 0, nil
+	return withExtra(header, extra.Encode()).Hash()
+}
+
+// withExtra returns a copy of the header with the extra-data replaced. The copy is made
+// through RLP: types.CopyHeader keeps the cached hash of the original header.
+func withExtra(header *types.Header, extra []byte) *types.Header {
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		panic("can't encode: " + err.Error())
+	}
+	cpy := new(types.Header)
+	if err := rlp.DecodeBytes(enc, cpy); err != nil {
+		panic("can't decode: " + err.Error())
+	}
+	cpy.Extra = extra
+	return cpy
+}
+
+// commitSealData is the data a validator signs in its committed seal of the block with the given digest.
+func commitSealData(digest libcommon.Hash) []byte {
+	return append(digest.Bytes(), byte(msgCommit))
+}
+
+// recoverCommitSeal returns the validator which signed the committed seal.
+func recoverCommitSeal(digest libcommon.Hash, seal []byte) (libcommon.Address, error) {
+	return recoverSigner(commitSealData(digest), seal)
+}
+
+func recoverSigner(data []byte, signature []byte) (libcommon.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return libcommon.Address{}, errInvalidSignature
+	}
+	pubkey, err := crypto.Ecrecover(crypto.Keccak256(data), signature)
+	if err != nil {
+		return libcommon.Address{}, err
+	}
+	var signer libcommon.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	return signer, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package qbft
+
+import (
+	"errors"
+	"fmt"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types/accounts"
+
+	"github.com/erigontech/erigon/core/types"
+)
+
+// Message codes of the qbft sentry subprotocol
+const (
+	msgProposal uint64 = iota
+	msgPrepare
+	msgCommit
+	msgRoundChange
+
+	ProtocolName    = "qbft"
+	ProtocolVersion = 1
+	ProtocolLength  = msgRoundChange + 1
+
+	ProtocolMaxMsgSize = 10 * 1024 * 1024 // Proposals carry whole blocks
+)
+
+var errInvalidSignature = errors.New("invalid signature")
+
+// message is a signed consensus message. Depending on the code:
+//   - proposal: Block is the proposed block, Justification holds the round change messages
+//     which started the round (for round > 0).
+//   - prepare: Digest is the seal hash of the proposed block.
+//   - commit: Digest as in prepare, CommitSeal is the committed seal of the block.
+//   - round change: Block is the block the sender prepared in PreparedRound, if any, and
+//     Justification holds the prepare messages which prove it.
+type message struct {
+	Code          uint64
+	Height        uint64
+	Round         uint64
+	Digest        libcommon.Hash
+	Block         []byte
+	CommitSeal    []byte
+	PreparedRound uint64
+	Justification [][]byte
+	Signature     []byte
+
+	author libcommon.Address
+	block  *types.Block
+}
+
+// signingData is the RLP encoding of the message without the signature
+func (m *message) signingData() []byte {
+	unsigned := *m
+	unsigned.Signature = nil
+	enc, err := rlp.EncodeToBytes(&unsigned)
+	if err != nil {
+		panic("can't encode: " + err.Error())
+	}
+	return enc
+}
+
+func (m *message) sign(signer libcommon.Address, signFn SignerFn) error {
+	sig, err := signFn(signer, accounts.MimetypeQBFT, m.signingData())
+	if err != nil {
+		return err
+	}
+	m.Signature, m.author = sig, signer
+	return nil
+}
+
+func (m *message) encode() []byte {
+	enc, err := rlp.EncodeToBytes(m)
+	if err != nil {
+		panic("can't encode: " + err.Error())
+	}
+	return enc
+}
+
+// decodeMessage decodes the message, recovers its author and decodes the carried block.
+func decodeMessage(data []byte) (*message, error) {
+	m := new(message)
+	if err := rlp.DecodeBytes(data, m); err != nil {
+		return nil, err
+	}
+	if m.Code >= ProtocolLength {
+		return nil, fmt.Errorf("unknown qbft message code %d", m.Code)
+	}
+	author, err := recoverSigner(m.signingData(), m.Signature)
+	if err != nil {
+		return nil, err
+	}
+	m.author = author
+	if len(m.Block) > 0 {
+		block := new(types.Block)
+		if err := rlp.DecodeBytes(m.Block, block); err != nil {
+			return nil, fmt.Errorf("invalid block in qbft message: %w", err)
+		}
+		m.block = block
+	}
+	return m, nil
+}
+
+func encodeBlock(block *types.Block) []byte {
+	enc, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		panic("can't encode: " + err.Error())
+	}
+	return enc
+}
+
+func (m *message) String() string {
+	return fmt.Sprintf("%s height=%d round=%d author=%x", codeName(m.Code), m.Height, m.Round, m.author)
+}
+
+func codeName(code uint64) string {
+	switch code {
+	case msgProposal:
+		return "proposal"
+	case msgPrepare:
+		return "prepare"
+	case msgCommit:
+		return "commit"
+	case msgRoundChange:
+		return "round-change"
+	default:
+		return "unknown"
+	}
+}
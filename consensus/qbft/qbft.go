@@ -0,0 +1,432 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package qbft implements a byzantine fault tolerant proof-of-authority consensus
+// engine with immediate finality for permissioned networks, following the QBFT
+// (IBFT 2.0 family) design.
+//
+// A block is final as soon as it's imported: its extra-data carries committed seals
+// of a quorum (2/3) of the validators. Validators agree on every block in rounds of
+// proposal, prepare and commit messages which they exchange through the "qbft" sentry
+// subprotocol. If a round doesn't commit a block in time, validators move to the next
+// round with the next proposer. Validators are added and removed by votes which block
+// proposers put into the extra-data.
+package qbft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/arc/v2"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/tracing"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm/evmtypes"
+	"github.com/erigontech/erigon/rpc"
+)
+
+const (
+	defaultEpochLength    = uint64(30000) // Default number of blocks after which pending votes are reset
+	defaultRequestTimeout = uint64(10)    // Default timeout of round 0 in seconds
+	inmemorySnapshots     = 128           // Number of recent snapshots to keep in memory
+	inmemoryMessages      = 4096          // Number of recent message hashes to keep to stop gossip loops
+)
+
+// Various error messages to mark blocks invalid.
+var (
+	// errUnknownBlock is returned when the list of validators is requested for a block
+	// that is not part of the local blockchain.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errInvalidNonce is returned if a block's nonce is non-zero.
+	errInvalidNonce = errors.New("non-zero nonce")
+
+	// errInvalidMixDigest is returned if a block's mix digest is not the QBFT digest.
+	errInvalidMixDigest = errors.New("invalid mix digest")
+
+	// errInvalidUncleHash is returned if a block contains an non-empty uncle list.
+	errInvalidUncleHash = errors.New("non empty uncle hash")
+
+	// errInvalidDifficulty is returned if the difficulty of a block is not 1.
+	errInvalidDifficulty = errors.New("invalid difficulty")
+
+	// errInvalidTimestamp is returned if the timestamp of a block is lower than
+	// the previous block's timestamp + the minimum block period.
+	errInvalidTimestamp = errors.New("invalid timestamp")
+
+	// errInvalidVote is returned if an epoch block contains a vote or a vote has no recipient.
+	errInvalidVote = errors.New("invalid validator vote")
+
+	// errInvalidValidators is returned if the validator set in the extra-data doesn't
+	// match the one calculated from the votes.
+	errInvalidValidators = errors.New("mismatching validator set")
+
+	// errInvalidVotingChain is returned if the validator set is attempted to be
+	// modified via out-of-range or non-contiguous headers.
+	errInvalidVotingChain = errors.New("invalid voting chain")
+
+	// errInvalidCommittedSeals is returned if a committed seal is not signed by a validator
+	// or a validator signed the block twice.
+	errInvalidCommittedSeals = errors.New("invalid committed seals")
+
+	// errInsufficientCommittedSeals is returned if less than a quorum of validators committed the block.
+	errInsufficientCommittedSeals = errors.New("not enough committed seals")
+
+	// ErrUnauthorizedProposer is returned if a block is proposed by an account which is not a validator.
+	ErrUnauthorizedProposer = errors.New("unauthorized proposer")
+)
+
+// SignerFn hashes and signs the data to be signed by a backing account.
+type SignerFn func(signer libcommon.Address, mimeType string, message []byte) ([]byte, error)
+
+// Transport sends consensus messages to the peers of the node.
+type Transport interface {
+	Broadcast(code uint64, data []byte) int
+}
+
+// BlockVerifier executes a proposed block on the state of its parent and checks the
+// results its header commits to: state root, receipts root, logs bloom and gas used.
+type BlockVerifier interface {
+	VerifyBlock(ctx context.Context, block *types.Block) error
+}
+
+// QBFT is the byzantine fault tolerant proof-of-authority consensus engine.
+type QBFT struct {
+	ChainConfig *chain.Config
+	config      *chain.QBFTConfig // Consensus engine configuration parameters
+
+	recents  *lru.ARCCache[libcommon.Hash, *Snapshot] // Snapshots for recent blocks to speed up reorgs
+	messages *lru.ARCCache[libcommon.Hash, struct{}]  // Recently seen messages to stop gossip loops
+
+	proposals map[libcommon.Address]bool // Current list of proposals we are pushing
+
+	signer libcommon.Address // Ethereum address of the signing key
+	signFn SignerFn          // Signer function to authorize hashes with
+	lock   sync.RWMutex      // Protects the signer and proposals fields
+
+	transports     []Transport
+	transportsLock sync.RWMutex
+
+	verifier atomic.Pointer[BlockVerifier]
+	head     atomic.Pointer[Snapshot] // Snapshot of the highest block seen, to filter gossip
+
+	core   *core
+	logger log.Logger
+}
+
+// New creates a QBFT consensus engine. The initial validators are taken from the genesis extra-data.
+func New(cfg *chain.Config, logger log.Logger) *QBFT {
+	conf := *cfg.QBFT
+	if conf.Epoch == 0 {
+		conf.Epoch = defaultEpochLength
+	}
+	if conf.RequestTimeout == 0 {
+		conf.RequestTimeout = defaultRequestTimeout
+	}
+	recents, _ := lru.NewARC[libcommon.Hash, *Snapshot](inmemorySnapshots)
+	messages, _ := lru.NewARC[libcommon.Hash, struct{}](inmemoryMessages)
+
+	q := &QBFT{
+		ChainConfig: cfg,
+		config:      &conf,
+		recents:     recents,
+		messages:    messages,
+		proposals:   make(map[libcommon.Address]bool),
+		logger:      logger,
+	}
+	q.core = newCore(q, logger)
+	go q.core.run()
+	return q
+}
+
+// Type returns underlying consensus engine
+func (q *QBFT) Type() chain.ConsensusName {
+	return chain.QBFTConsensus
+}
+
+// Author implements consensus.Engine, returning the proposer of the block.
+// The committed seals of the validators make the coinbase authentic.
+func (q *QBFT) Author(header *types.Header) (libcommon.Address, error) {
+	return header.Coinbase, nil
+}
+
+// VerifyHeader checks whether a header conforms to the consensus rules.
+func (q *QBFT) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, _ bool) error {
+	return q.verifyHeader(chain, header, nil)
+}
+
+// VerifyUncles implements consensus.Engine, always returning an error for any
+// uncles as this consensus mechanism doesn't permit uncles.
+func (q *QBFT) VerifyUncles(chain consensus.ChainReader, header *types.Header, uncles []*types.Header) error {
+	if len(uncles) > 0 {
+		return errors.New("uncles not allowed")
+	}
+	return nil
+}
+
+// Prepare implements consensus.Engine, preparing all the consensus fields of the
+// header for running the transactions on top.
+func (q *QBFT) Prepare(chain consensus.ChainHeaderReader, header *types.Header, state *state.IntraBlockState) error {
+	number := header.Number.Uint64()
+	snap, err := q.Snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+
+	q.lock.RLock()
+	signer := q.signer
+	var votes []ValidatorVote
+	if number%q.config.Epoch != 0 {
+		// Gather all the proposals that make sense voting on and cast a random one
+		addresses := make([]libcommon.Address, 0, len(q.proposals))
+		for address, authorize := range q.proposals {
+			if snap.validVote(address, authorize) {
+				addresses = append(addresses, address)
+			}
+		}
+		if len(addresses) > 0 {
+			address := addresses[rand.Intn(len(addresses))] // nolint: gosec
+			votes = append(votes, ValidatorVote{Recipient: address, Authorize: q.proposals[address]})
+		}
+	}
+	q.lock.RUnlock()
+
+	header.Coinbase = signer
+	header.Nonce = types.BlockNonce{}
+	header.MixDigest = Digest
+	header.Difficulty = big.NewInt(1)
+
+	vanity := make([]byte, ExtraVanity)
+	copy(vanity, header.Extra)
+	extra := &Extra{Vanity: vanity, Validators: snap.nextValidators(signer, votes), Vote: votes}
+	header.Extra = extra.Encode()
+
+	// Ensure the timestamp has the correct delay
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	header.Time = parent.Time + q.config.BlockPeriod
+	if now := uint64(time.Now().Unix()); header.Time < now {
+		header.Time = now
+	}
+	return nil
+}
+
+func (q *QBFT) Initialize(config *chain.Config, chain consensus.ChainHeaderReader, header *types.Header,
+	state *state.IntraBlockState, syscall consensus.SysCallCustom, logger log.Logger, tracer *tracing.Hooks) {
+}
+
+func (q *QBFT) CalculateRewards(config *chain.Config, header *types.Header, uncles []*types.Header, syscall consensus.SystemCall,
+) ([]consensus.Reward, error) {
+	return []consensus.Reward{}, nil
+}
+
+// Finalize implements consensus.Engine, ensuring no uncles are set, nor block
+// rewards given.
+func (q *QBFT) Finalize(config *chain.Config, header *types.Header, state *state.IntraBlockState,
+	txs types.Transactions, uncles []*types.Header, r types.Receipts, withdrawals []*types.Withdrawal,
+	chain consensus.ChainReader, syscall consensus.SystemCall, skipReceiptsEval bool, logger log.Logger,
+) (types.Transactions, types.Receipts, types.FlatRequests, error) {
+	return txs, r, nil, nil
+}
+
+// FinalizeAndAssemble implements consensus.Engine, ensuring no uncles are set,
+// nor block rewards given, and returns the final block.
+func (q *QBFT) FinalizeAndAssemble(chainConfig *chain.Config, header *types.Header, state *state.IntraBlockState,
+	txs types.Transactions, uncles []*types.Header, receipts types.Receipts, withdrawals []*types.Withdrawal, chain consensus.ChainReader, syscall consensus.SystemCall, call consensus.Call, logger log.Logger,
+) (*types.Block, types.Transactions, types.Receipts, types.FlatRequests, error) {
+	return types.NewBlockForAsembling(header, txs, nil, receipts, withdrawals), txs, receipts, nil, nil
+}
+
+// Authorize injects a private key into the consensus engine to propose blocks and
+// sign consensus messages with.
+func (q *QBFT) Authorize(signer libcommon.Address, signFn SignerFn) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.signer = signer
+	q.signFn = signFn
+}
+
+// AddTransport registers a transport to broadcast consensus messages through.
+func (q *QBFT) AddTransport(t Transport) {
+	q.transportsLock.Lock()
+	defer q.transportsLock.Unlock()
+	q.transports = append(q.transports, t)
+}
+
+// SetBlockVerifier sets the executor of proposed blocks. Without it the proposals are only
+// checked against the header rules, which is only suitable for tests.
+func (q *QBFT) SetBlockVerifier(v BlockVerifier) {
+	q.verifier.Store(&v)
+}
+
+func (q *QBFT) verifyBlock(block *types.Block) error {
+	v := q.verifier.Load()
+	if v == nil {
+		return nil
+	}
+	return (*v).VerifyBlock(context.Background(), block)
+}
+
+// observe records snap if it is the snapshot of the highest block seen so far.
+func (q *QBFT) observe(snap *Snapshot) {
+	for {
+		head := q.head.Load()
+		if head != nil && head.Number > snap.Number {
+			return
+		}
+		if q.head.CompareAndSwap(head, snap) {
+			return
+		}
+	}
+}
+
+func (q *QBFT) broadcast(code uint64, data []byte) {
+	q.messages.Add(crypto.Keccak256Hash(data), struct{}{})
+
+	q.transportsLock.RLock()
+	defer q.transportsLock.RUnlock()
+	for _, t := range q.transports {
+		t.Broadcast(code, data)
+	}
+}
+
+// HandleMessage handles a consensus message received from a peer. New messages of the
+// validators of the highest block seen are gossiped to the other peers, so validators
+// which aren't connected directly still reach each other. Messages of other authors are
+// dropped, until the first block is seen they are only handed to the consensus instance,
+// which bounds what it buffers. The error is returned only for malformed messages.
+func (q *QBFT) HandleMessage(code uint64, data []byte) error {
+	hash := crypto.Keccak256Hash(data)
+	if q.messages.Contains(hash) {
+		return nil
+	}
+	m, err := decodeMessage(data)
+	if err != nil {
+		return err
+	}
+	if m.Code != code {
+		return fmt.Errorf("qbft %s message sent with code %d", codeName(m.Code), code)
+	}
+	head := q.head.Load()
+	switch {
+	case head == nil:
+		q.messages.Add(hash, struct{}{})
+	case head.IsValidator(m.author):
+		q.broadcast(code, data)
+	default:
+		q.messages.Add(hash, struct{}{})
+		q.logger.Trace("[qbft] Dropping message from non-validator", "msg", m)
+		return nil
+	}
+	q.core.deliver(m)
+	return nil
+}
+
+// Seal implements consensus.Engine. The block is handed to the consensus instance of
+// its height and proposed if this validator is the proposer of the round. The proposer
+// sends the committed block with the seals of a quorum of validators to results, the
+// other validators send nil and import the block of the proposer. If the height is
+// sealed again because that block didn't arrive, the block with the seals this validator
+// saw is sent instead. The block is also nil if the network moved on to the next
+// height without this validator. The instance is not
+// interrupted by stop: the height is agreed on by all validators together.
+func (q *QBFT) Seal(chain consensus.ChainHeaderReader, blockWithReceipts *types.BlockWithReceipts, results chan<- *types.BlockWithReceipts, stop <-chan struct{}) error {
+	header := blockWithReceipts.Block.Header()
+
+	// Sealing the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	q.lock.RLock()
+	signer, signFn := q.signer, q.signFn
+	q.lock.RUnlock()
+
+	snap, err := q.Snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	if !snap.IsValidator(signer) {
+		return fmt.Errorf("QBFT.Seal: %w", ErrUnauthorizedProposer)
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+
+	q.core.seal(&sealRequest{
+		block:   blockWithReceipts,
+		parent:  parent,
+		snap:    snap,
+		signer:  signer,
+		signFn:  signFn,
+		results: results,
+	})
+	return nil
+}
+
+// SealHash returns the hash of a block prior to it being sealed.
+func (q *QBFT) SealHash(header *types.Header) libcommon.Hash {
+	return SealHash(header)
+}
+
+// CalcDifficulty is the difficulty adjustment algorithm. All QBFT blocks have difficulty 1.
+func (q *QBFT) CalcDifficulty(chain consensus.ChainHeaderReader, _, _ uint64, _ *big.Int, _ uint64, _, _ libcommon.Hash, _ uint64) *big.Int {
+	return big.NewInt(1)
+}
+
+func (q *QBFT) IsServiceTransaction(sender libcommon.Address, syscall consensus.SystemCall) bool {
+	return false
+}
+
+// Close implements consensus.Engine, stopping the consensus instance.
+func (q *QBFT) Close() error {
+	q.core.stop()
+	return nil
+}
+
+// APIs implements consensus.Engine. The qbft namespace is registered by the rpc daemon, see NewQBFTAPI.
+func (q *QBFT) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return []rpc.API{}
+}
+
+func (q *QBFT) GetTransferFunc() evmtypes.TransferFunc {
+	return consensus.Transfer
+}
+
+func (q *QBFT) GetPostApplyMessageFunc() evmtypes.PostApplyMessageFunc {
+	return nil
+}
+
+// Status returns the state of the consensus instance.
+func (q *QBFT) Status() *Status {
+	return q.core.status.Load()
+}
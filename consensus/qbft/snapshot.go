@@ -0,0 +1,231 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package qbft
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/core/types"
+)
+
+// Vote represents a single vote that a validator made, when proposing a block,
+// to modify the validator set.
+type Vote struct {
+	Validator libcommon.Address `json:"validator"` // Validator which proposed the block with the vote
+	Block     uint64            `json:"block"`     // Block number the vote was cast in
+	Address   libcommon.Address `json:"address"`   // Account being voted on
+	Authorize bool              `json:"authorize"` // Whether to add or remove the account
+}
+
+// Tally is a simple vote tally to keep the current score of votes.
+type Tally struct {
+	Authorize bool `json:"authorize"` // Whether the vote is about adding or removing someone
+	Votes     int  `json:"votes"`     // Number of votes until now wanting to pass the proposal
+}
+
+// Snapshot is the validator set and the state of the voting at a given block.
+//
+// Unlike clique, every header carries the whole validator set in its extra-data, so
+// a snapshot can always be rebuilt from the headers since the last epoch block and
+// isn't persisted.
+type Snapshot struct {
+	epoch uint64
+
+	Number     uint64                      `json:"number"`     // Block number where the snapshot was created
+	Hash       libcommon.Hash              `json:"hash"`       // Block hash where the snapshot was created
+	Proposer   libcommon.Address           `json:"proposer"`   // Proposer of the block
+	Validators []libcommon.Address         `json:"validators"` // Ascending validator set for the next block
+	Votes      []*Vote                     `json:"votes"`      // List of votes cast in chronological order
+	Tally      map[libcommon.Address]Tally `json:"tally"`      // Current vote tally to avoid recalculating
+	index      map[libcommon.Address]int   // Position of a validator in Validators
+}
+
+func sortAddresses(addrs []libcommon.Address) {
+	slices.SortFunc(addrs, func(a, b libcommon.Address) int { return bytes.Compare(a[:], b[:]) })
+}
+
+// newSnapshot creates the snapshot of a block without pending votes: the genesis or an epoch block.
+func newSnapshot(epoch uint64, header *types.Header, validators []libcommon.Address) *Snapshot {
+	snap := &Snapshot{
+		epoch:      epoch,
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		Proposer:   header.Coinbase,
+		Validators: append([]libcommon.Address{}, validators...),
+		Tally:      make(map[libcommon.Address]Tally),
+	}
+	sortAddresses(snap.Validators)
+	snap.reindex()
+	return snap
+}
+
+func (s *Snapshot) reindex() {
+	s.index = make(map[libcommon.Address]int, len(s.Validators))
+	for i, v := range s.Validators {
+		s.index[v] = i
+	}
+}
+
+func (s *Snapshot) copy() *Snapshot {
+	cpy := &Snapshot{
+		epoch:      s.epoch,
+		Number:     s.Number,
+		Hash:       s.Hash,
+		Proposer:   s.Proposer,
+		Validators: slices.Clone(s.Validators),
+		Votes:      make([]*Vote, len(s.Votes)),
+		Tally:      maps.Clone(s.Tally),
+		index:      maps.Clone(s.index),
+	}
+	for i, vote := range s.Votes {
+		v := *vote
+		cpy.Votes[i] = &v
+	}
+	return cpy
+}
+
+// IsValidator returns whether the address is in the validator set for the next block.
+func (s *Snapshot) IsValidator(address libcommon.Address) bool {
+	_, ok := s.index[address]
+	return ok
+}
+
+// Quorum is the number of validators which must agree on the next block: ceil(2n/3).
+func (s *Snapshot) Quorum() int {
+	return (2*len(s.Validators) + 2) / 3
+}
+
+// faulty is the number of faulty validators the set tolerates.
+func (s *Snapshot) faulty() int {
+	return (len(s.Validators) - 1) / 3
+}
+
+// ProposerForRound returns the proposer of the next block in the given round. Proposers
+// rotate: the validator after the proposer of this block proposes in round 0, the next one
+// in round 1 and so on.
+func (s *Snapshot) ProposerForRound(round uint64) libcommon.Address {
+	start := 0
+	if i, ok := s.index[s.Proposer]; ok {
+		start = i + 1
+	}
+	n := uint64(len(s.Validators))
+	return s.Validators[(uint64(start)+round%n)%n]
+}
+
+// validVote returns whether it makes sense to cast the specified vote.
+func (s *Snapshot) validVote(address libcommon.Address, authorize bool) bool {
+	return s.IsValidator(address) != authorize
+}
+
+// nextValidators returns the validator set after the vote of the next block is counted.
+func (s *Snapshot) nextValidators(proposer libcommon.Address, votes []ValidatorVote) []libcommon.Address {
+	next := s.copy()
+	next.count(s.Number+1, proposer, votes)
+	return next.Validators
+}
+
+// apply creates a new snapshot by applying the header on top of the snapshot. The header
+// must be the child of the snapshot block.
+func (s *Snapshot) apply(header *types.Header, extra *Extra) (*Snapshot, error) {
+	number := header.Number.Uint64()
+	if number != s.Number+1 || header.ParentHash != s.Hash {
+		return nil, errInvalidVotingChain
+	}
+	snap := s.copy()
+	if number%s.epoch == 0 {
+		snap.Votes = nil
+		snap.Tally = make(map[libcommon.Address]Tally)
+	}
+	snap.count(number, header.Coinbase, extra.Vote)
+	snap.Number, snap.Hash, snap.Proposer = number, header.Hash(), header.Coinbase
+	return snap, nil
+}
+
+// count counts the votes the proposer cast in the block and updates the validator set
+// if a vote reached the majority.
+func (s *Snapshot) count(number uint64, proposer libcommon.Address, votes []ValidatorVote) {
+	for _, v := range votes {
+		// Discard any previous votes from the proposer on the same account
+		for i, vote := range s.Votes {
+			if vote.Validator == proposer && vote.Address == v.Recipient {
+				s.uncast(vote.Address, vote.Authorize)
+				s.Votes = append(s.Votes[:i], s.Votes[i+1:]...)
+				break
+			}
+		}
+		if !s.cast(v.Recipient, v.Authorize) {
+			continue
+		}
+		s.Votes = append(s.Votes, &Vote{Validator: proposer, Block: number, Address: v.Recipient, Authorize: v.Authorize})
+
+		tally := s.Tally[v.Recipient]
+		if tally.Votes <= len(s.Validators)/2 {
+			continue
+		}
+		if tally.Authorize {
+			s.Validators = append(s.Validators, v.Recipient)
+			sortAddresses(s.Validators)
+		} else {
+			s.Validators = slices.DeleteFunc(s.Validators, func(a libcommon.Address) bool { return a == v.Recipient })
+			// Discard any previous votes the removed validator cast
+			for i := 0; i < len(s.Votes); i++ {
+				if s.Votes[i].Validator == v.Recipient {
+					s.uncast(s.Votes[i].Address, s.Votes[i].Authorize)
+					s.Votes = append(s.Votes[:i], s.Votes[i+1:]...)
+					i--
+				}
+			}
+		}
+		s.reindex()
+		// Discard any previous votes around the just changed account
+		s.Votes = slices.DeleteFunc(s.Votes, func(vote *Vote) bool { return vote.Address == v.Recipient })
+		delete(s.Tally, v.Recipient)
+	}
+}
+
+// cast adds a new vote into the tally.
+func (s *Snapshot) cast(address libcommon.Address, authorize bool) bool {
+	if !s.validVote(address, authorize) {
+		return false
+	}
+	if old, ok := s.Tally[address]; ok {
+		old.Votes++
+		s.Tally[address] = old
+	} else {
+		s.Tally[address] = Tally{Authorize: authorize, Votes: 1}
+	}
+	return true
+}
+
+// uncast removes a previously cast vote from the tally.
+func (s *Snapshot) uncast(address libcommon.Address, authorize bool) bool {
+	tally, ok := s.Tally[address]
+	if !ok || tally.Authorize != authorize {
+		return false
+	}
+	if tally.Votes > 1 {
+		tally.Votes--
+		s.Tally[address] = tally
+	} else {
+		delete(s.Tally, address)
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package qbft
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/core/types"
+)
+
+func TestExtraRoundTrip(t *testing.T) {
+	validators := []libcommon.Address{{3}, {1}, {2}}
+	header := &types.Header{Number: big.NewInt(0), Extra: GenesisExtra(validators)}
+
+	extra, err := DecodeExtra(header)
+	require.NoError(t, err)
+	require.Equal(t, []libcommon.Address{{1}, {2}, {3}}, extra.Validators)
+
+	// Round and committed seals don't change the seal hash
+	sealHash := SealHash(header)
+	extra.Round, extra.CommittedSeals = 3, [][]byte{{1}, {2}}
+	header.Extra = extra.Encode()
+	require.Equal(t, sealHash, SealHash(header))
+
+	decoded, err := DecodeExtra(header)
+	require.NoError(t, err)
+	require.Equal(t, extra, decoded)
+
+	header.Extra = []byte{0xc0}
+	_, err = DecodeExtra(header)
+	require.ErrorIs(t, err, errInvalidExtra)
+}
+
+func TestSnapshotProposersAndQuorum(t *testing.T) {
+	validators := []libcommon.Address{{1}, {2}, {3}, {4}}
+	snap := newSnapshot(30000, &types.Header{Number: big.NewInt(0)}, validators)
+	require.Equal(t, 3, snap.Quorum())
+	require.Equal(t, 1, snap.faulty())
+
+	// The proposer of the genesis is not a validator, rotation starts from the first one
+	require.Equal(t, libcommon.Address{1}, snap.ProposerForRound(0))
+	require.Equal(t, libcommon.Address{2}, snap.ProposerForRound(1))
+	require.Equal(t, libcommon.Address{1}, snap.ProposerForRound(4))
+
+	snap.Proposer = libcommon.Address{4}
+	require.Equal(t, libcommon.Address{1}, snap.ProposerForRound(0))
+	require.Equal(t, libcommon.Address{3}, snap.ProposerForRound(2))
+}
+
+func TestSnapshotVoting(t *testing.T) {
+	validators := []libcommon.Address{{1}, {2}, {3}}
+	parent := &types.Header{Number: big.NewInt(0)}
+	snap := newSnapshot(30000, parent, validators)
+
+	// Two of three validators are needed to add a validator
+	add := []ValidatorVote{{Recipient: libcommon.Address{4}, Authorize: true}}
+	for i, proposer := range []libcommon.Address{{1}, {2}} {
+		header := &types.Header{Number: big.NewInt(int64(i + 1)), ParentHash: parent.Hash(), Coinbase: proposer}
+		next := snap.nextValidators(proposer, add)
+		header.Extra = (&Extra{Vanity: make([]byte, ExtraVanity), Validators: next, Vote: add}).Encode()
+		extra, err := DecodeExtra(header)
+		require.NoError(t, err)
+
+		snap, err = snap.apply(header, extra)
+		require.NoError(t, err)
+		require.Equal(t, next, snap.Validators)
+		parent = header
+	}
+	require.True(t, snap.IsValidator(libcommon.Address{4}))
+	require.Empty(t, snap.Votes)
+	require.Equal(t, 3, snap.Quorum())
+
+	// Headers must extend the snapshot
+	_, err := snap.apply(&types.Header{Number: big.NewInt(5), ParentHash: parent.Hash()}, &Extra{})
+	require.ErrorIs(t, err, errInvalidVotingChain)
+}
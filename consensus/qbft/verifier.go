@@ -0,0 +1,209 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package qbft
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/consensus/misc"
+	"github.com/erigontech/erigon/core/types"
+)
+
+// verifyHeader checks whether a header conforms to the consensus rules. The caller
+// may optionally pass in a batch of parents (ascending order) to avoid looking those
+// up from the database.
+func (q *QBFT) verifyHeader(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	extra, err := q.verifyStandaloneFields(header)
+	if err != nil {
+		return err
+	}
+	// The genesis block is the always valid dead-end
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	snap, err := q.Snapshot(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	if err := q.verifyCascadingFields(parent, snap, header, extra); err != nil {
+		return err
+	}
+	return verifyCommittedSeals(snap, header, extra)
+}
+
+// verifyStandaloneFields checks the header fields which don't depend on other headers.
+func (q *QBFT) verifyStandaloneFields(header *types.Header) (*Extra, error) {
+	if header.Number == nil {
+		return nil, errUnknownBlock
+	}
+	number := header.Number.Uint64()
+
+	// Don't waste time checking blocks from the future
+	if header.Time > uint64(time.Now().Unix()) {
+		return nil, consensus.ErrFutureBlock
+	}
+	extra, err := DecodeExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	if header.Nonce != (types.BlockNonce{}) {
+		return nil, errInvalidNonce
+	}
+	if number > 0 && header.MixDigest != Digest {
+		return nil, errInvalidMixDigest
+	}
+	// Ensure that the block doesn't contain any uncles which are meaningless in BFT
+	if header.UncleHash != types.EmptyUncleHash {
+		return nil, errInvalidUncleHash
+	}
+	if number > 0 && (header.Difficulty == nil || header.Difficulty.Cmp(libcommon.Big1) != 0) {
+		return nil, errInvalidDifficulty
+	}
+	// Votes reset on epoch blocks, so they can't vote
+	for _, vote := range extra.Vote {
+		if number%q.config.Epoch == 0 || vote.Recipient == (libcommon.Address{}) {
+			return nil, errInvalidVote
+		}
+	}
+	if header.WithdrawalsHash != nil {
+		return nil, consensus.ErrUnexpectedWithdrawals
+	}
+	if header.RequestsHash != nil {
+		return nil, consensus.ErrUnexpectedRequests
+	}
+	return extra, nil
+}
+
+// verifyCascadingFields checks the header fields which depend on the parent and on the
+// validator set of the parent. Committed seals are not checked: proposals don't have them yet.
+func (q *QBFT) verifyCascadingFields(parent *types.Header, snap *Snapshot, header *types.Header, extra *Extra) error {
+	if parent.Time+q.config.BlockPeriod > header.Time {
+		return errInvalidTimestamp
+	}
+	if !q.ChainConfig.IsLondon(header.Number.Uint64()) {
+		// Verify BaseFee not present before EIP-1559 fork.
+		if header.BaseFee != nil {
+			return fmt.Errorf("invalid baseFee before fork: have %d, want <nil>", header.BaseFee)
+		}
+		if err := misc.VerifyGaslimit(parent.GasLimit, header.GasLimit); err != nil {
+			return err
+		}
+	} else if err := misc.VerifyEip1559Header(q.ChainConfig, parent, header, false /*skipGasLimit*/); err != nil {
+		// Verify the header's EIP-1559 attributes.
+		return err
+	}
+	if err := misc.VerifyAbsenceOfCancunHeaderFields(header); err != nil {
+		return err
+	}
+	if !snap.IsValidator(header.Coinbase) {
+		return ErrUnauthorizedProposer
+	}
+	if !slices.Equal(snap.nextValidators(header.Coinbase, extra.Vote), extra.Validators) {
+		return errInvalidValidators
+	}
+	return nil
+}
+
+// verifyCommittedSeals checks that a quorum of the validators committed the block.
+func verifyCommittedSeals(snap *Snapshot, header *types.Header, extra *Extra) error {
+	digest := SealHash(header)
+	committed := make(map[libcommon.Address]struct{}, len(extra.CommittedSeals))
+	for _, seal := range extra.CommittedSeals {
+		validator, err := recoverCommitSeal(digest, seal)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errInvalidCommittedSeals, err)
+		}
+		if _, ok := committed[validator]; ok || !snap.IsValidator(validator) {
+			return errInvalidCommittedSeals
+		}
+		committed[validator] = struct{}{}
+	}
+	if len(committed) < snap.Quorum() {
+		return errInsufficientCommittedSeals
+	}
+	return nil
+}
+
+// Snapshot retrieves the validator set and the voting state at the given block.
+func (q *QBFT) Snapshot(chain consensus.ChainHeaderReader, number uint64, hash libcommon.Hash, parents []*types.Header) (*Snapshot, error) {
+	var (
+		headers []*types.Header
+		snap    *Snapshot
+	)
+	for snap == nil {
+		// If an in-memory snapshot was found, use that
+		if s, ok := q.recents.Get(hash); ok {
+			snap = s
+			break
+		}
+		var header *types.Header
+		if len(parents) > 0 {
+			// If we have explicit parents, pick from there (enforced)
+			header = parents[len(parents)-1]
+			if header.Hash() != hash || header.Number.Uint64() != number {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			parents = parents[:len(parents)-1]
+		} else {
+			// No explicit parents (or no more left), reach out to the database
+			header = chain.GetHeader(hash, number)
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+		}
+		// The genesis and epoch blocks have no pending votes: their validators are the snapshot
+		if number%q.config.Epoch == 0 {
+			extra, err := DecodeExtra(header)
+			if err != nil {
+				return nil, err
+			}
+			snap = newSnapshot(q.config.Epoch, header, extra.Validators)
+			break
+		}
+		headers = append(headers, header)
+		number, hash = number-1, header.ParentHash
+	}
+	// Previous snapshot found, apply any pending headers on top of it
+	for i := len(headers) - 1; i >= 0; i-- {
+		extra, err := DecodeExtra(headers[i])
+		if err != nil {
+			return nil, err
+		}
+		if snap, err = snap.apply(headers[i], extra); err != nil {
+			return nil, err
+		}
+	}
+	q.recents.Add(snap.Hash, snap)
+	q.observe(snap)
+	return snap, nil
+}
@@ -39,6 +39,7 @@ import (
 	"github.com/erigontech/erigon/consensus"
 	"github.com/erigontech/erigon/consensus/merge"
 	"github.com/erigontech/erigon/consensus/misc"
+	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/core/vm"
@@ -319,6 +320,17 @@ func (cp *ChainPack) NumberOfPoWBlocks() int {
 // values. Inserting them into BlockChain requires use of FakePow or
 // a similar non-validating proof of work implementation.
 func GenerateChain(config *chain.Config, parent *types.Block, engine consensus.Engine, db kv.RwDB, n int, gen func(int, *BlockGen)) (*ChainPack, error) {
+	return generateChain(config, parent, engine, db, n, gen, false)
+}
+
+// GenerateChainWithTd - like GenerateChain, but the chain reader given to the engine knows the total difficulty
+// of the parent (read from db) and of the generated blocks. It lets engines switching at the terminal total
+// difficulty (merge) produce proof-of-stake blocks and run their block initialization (e.g. EIP-4788, EIP-2935).
+func GenerateChainWithTd(config *chain.Config, parent *types.Block, engine consensus.Engine, db kv.RwDB, n int, gen func(int, *BlockGen)) (*ChainPack, error) {
+	return generateChain(config, parent, engine, db, n, gen, true)
+}
+
+func generateChain(config *chain.Config, parent *types.Block, engine consensus.Engine, db kv.RwDB, n int, gen func(int, *BlockGen), withTd bool) (*ChainPack, error) {
 	if config == nil {
 		config = params.TestChainConfig
 	}
@@ -331,6 +343,15 @@ func GenerateChain(config *chain.Config, parent *types.Block, engine consensus.E
 	}
 	defer tx.Rollback()
 	logger := log.New("generate-chain", config.ChainName)
+	// the engine reads block initialization inputs (chain config, total difficulty) from the chain reader
+	var initChain consensus.ChainHeaderReader
+	if withTd {
+		td, err := rawdb.ReadTd(tx, parent.Hash(), parent.NumberU64())
+		if err != nil {
+			return nil, err
+		}
+		chainreader.td, initChain = td, chainreader
+	}
 
 	domains, err := libstate.NewSharedDomains(tx, logger)
 	if err != nil {
@@ -366,7 +387,7 @@ func GenerateChain(config *chain.Config, parent *types.Block, engine consensus.E
 			}
 		}
 		if b.engine != nil {
-			err := InitializeBlockExecution(b.engine, nil, b.header, config, ibs, nil, logger, nil)
+			err := InitializeBlockExecution(b.engine, initChain, b.header, config, ibs, nil, logger, nil)
 			if err != nil {
 				return nil, nil, fmt.Errorf("call to InitializeBlockExecution: %w", err)
 			}
@@ -419,6 +440,12 @@ func GenerateChain(config *chain.Config, parent *types.Block, engine consensus.E
 		blocks[i] = block
 		receipts[i] = receipt
 		parent = block
+		if withTd {
+			if chainreader.td != nil {
+				chainreader.td = new(big.Int).Add(chainreader.td, block.Difficulty())
+			}
+			chainreader.current = block
+		}
 	}
 	tx.Rollback()
 
@@ -629,6 +656,7 @@ func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.I
 type FakeChainReader struct {
 	Cfg     *chain.Config
 	current *types.Block
+	td      *big.Int // total difficulty of current, if known
 }
 
 // Config returns the chain configuration.
@@ -648,9 +676,14 @@ func (cr *FakeChainReader) GetHeaderByHash(hash libcommon.Hash) *types.Header
 func (cr *FakeChainReader) GetHeader(hash libcommon.Hash, number uint64) *types.Header { return nil }
 func (cr *FakeChainReader) GetBlock(hash libcommon.Hash, number uint64) *types.Block   { return nil }
 func (cr *FakeChainReader) HasBlock(hash libcommon.Hash, number uint64) bool           { return false }
-func (cr *FakeChainReader) GetTd(hash libcommon.Hash, number uint64) *big.Int          { return nil }
-func (cr *FakeChainReader) FrozenBlocks() uint64                                       { return 0 }
-func (cr *FakeChainReader) FrozenBorBlocks() uint64                                    { return 0 }
+func (cr *FakeChainReader) GetTd(hash libcommon.Hash, number uint64) *big.Int {
+	if cr.td != nil && cr.current != nil && cr.current.Hash() == hash {
+		return cr.td
+	}
+	return nil
+}
+func (cr *FakeChainReader) FrozenBlocks() uint64    { return 0 }
+func (cr *FakeChainReader) FrozenBorBlocks() uint64 { return 0 }
 func (cr *FakeChainReader) BorEventsByBlock(hash libcommon.Hash, number uint64) []rlp.RawValue {
 	return nil
 }
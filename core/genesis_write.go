@@ -38,6 +38,7 @@ import (
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/datadir"
 	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/length"
 	"github.com/erigontech/erigon-lib/config3"
 	"github.com/erigontech/erigon-lib/crypto"
 	"github.com/erigontech/erigon-lib/kv"
@@ -46,10 +47,12 @@ import (
 	"github.com/erigontech/erigon-lib/kv/temporal"
 	"github.com/erigontech/erigon-lib/log/v3"
 	state2 "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/consensus/qbft"
 	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/tracing"
 	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/params"
 )
 
@@ -154,6 +157,9 @@ func WriteGenesisBlock(tx kv.RwTx, genesis *types.Genesis, overridePragueTime *b
 	if err := newCfg.CheckConfigForkOrder(); err != nil {
 		return newCfg, nil, err
 	}
+	if err := vm.ValidateCustomPrecompiles(newCfg); err != nil {
+		return newCfg, nil, err
+	}
 	storedCfg, storedErr := rawdb.ReadChainConfig(tx, storedHash)
 	if storedErr != nil && newCfg.Bor == nil {
 		return newCfg, nil, storedErr
@@ -238,6 +244,9 @@ func write(tx kv.RwTx, g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (
 	if err := config.CheckConfigForkOrder(); err != nil {
 		return nil, nil, err
 	}
+	if err := vm.ValidateCustomPrecompiles(config); err != nil {
+		return nil, nil, err
+	}
 
 	if err := rawdb.WriteBlock(tx, block); err != nil {
 		return nil, nil, err
@@ -433,6 +442,51 @@ func DeveloperGenesisBlock(period uint64, faucet libcommon.Address) *types.Genes
 	}
 }
 
+// CliqueDevnetSigners are the signers of the clique devnet genesis. The devnet process
+// appends its block producers before they start, a standalone node uses its etherbase.
+var CliqueDevnetSigners []libcommon.Address
+
+// CliqueDevnetGenesisBlock returns the genesis block of a local network of clique signers.
+func CliqueDevnetGenesisBlock(period uint64, signers []libcommon.Address) *types.Genesis {
+	config := *params.CliqueDevnetChainConfig
+	if period > 0 {
+		clique := *config.Clique
+		clique.Period = period
+		config.Clique = &clique
+	}
+
+	signers = slices.Clone(signers)
+	slices.SortFunc(signers, func(a, b libcommon.Address) int { return a.Cmp(b) })
+	extra := make([]byte, 32, 32+len(signers)*length.Addr+crypto.SignatureLength)
+	for _, signer := range signers {
+		extra = append(extra, signer[:]...)
+	}
+
+	return &types.Genesis{
+		Config:     &config,
+		ExtraData:  append(extra, make([]byte, crypto.SignatureLength)...),
+		GasLimit:   11500000,
+		Difficulty: big.NewInt(1),
+		Alloc:      readPrealloc("allocs/dev.json"),
+	}
+}
+
+// QBFTDevnetValidators are the validators of the qbft devnet genesis. The devnet process
+// appends its block producers before they start, a standalone node uses its etherbase.
+var QBFTDevnetValidators []libcommon.Address
+
+// QBFTDevnetGenesisBlock returns the genesis block of a local network of QBFT validators.
+func QBFTDevnetGenesisBlock(validators []libcommon.Address) *types.Genesis {
+	return &types.Genesis{
+		Config:     params.QBFTDevnetChainConfig,
+		ExtraData:  qbft.GenesisExtra(validators),
+		GasLimit:   11500000,
+		Difficulty: big.NewInt(1),
+		Mixhash:    qbft.Digest,
+		Alloc:      readPrealloc("allocs/dev.json"),
+	}
+}
+
 // ToBlock creates the genesis block and writes state of a genesis specification
 // to the given database (or discards it if nil).
 func GenesisToBlock(g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (*types.Block, *state.IntraBlockState, error) {
@@ -1208,6 +1208,23 @@ func WriteDBSchemaVersion(tx kv.RwTx) error {
 	}
 	return nil
 }
+
+// ReadHistoryExpiredTo - first block whose body was not deleted by history expiry, 0 if nothing expired
+func ReadHistoryExpiredTo(tx kv.Getter) (uint64, error) {
+	v, err := tx.GetOne(kv.DatabaseInfo, kv.HistoryExpiredTo)
+	if err != nil {
+		return 0, err
+	}
+	if len(v) != 8 {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+func WriteHistoryExpiredTo(tx kv.Putter, blockNum uint64) error {
+	return tx.Put(kv.DatabaseInfo, kv.HistoryExpiredTo, hexutil.EncodeTs(blockNum))
+}
+
 func ReadDBSchemaVersion(tx kv.Tx) (major, minor, patch uint32, ok bool, err error) {
 	existingVersion, err := tx.GetOne(kv.DatabaseInfo, kv.DBSchemaVersionKey)
 	if err != nil {
@@ -0,0 +1,102 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"sync"
+
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+var (
+	mxPrefetchKeys   = metrics.NewCounter(`prefetch_keys`)   // keys warmed by prefetcher
+	mxPrefetchReads  = metrics.NewCounter(`prefetch_reads`)  // keys read by execution of prefetched blocks
+	mxPrefetchHits   = metrics.NewCounter(`prefetch_hits`)   // keys read by execution which were warmed
+	mxPrefetchUnused = metrics.NewCounter(`prefetch_unused`) // keys warmed, but not read by execution
+)
+
+// ReadObserver - gets Domain and key of state read
+type ReadObserver func(domain kv.Domain, k []byte)
+
+// PrefetchTracker - keys of one block warmed by prefetcher and keys read by its execution.
+// Execution reports hit-rate when block is done, keys which were warmed but not read are counted
+// when both prefetch and execution are done.
+type PrefetchTracker struct {
+	mu         sync.Mutex
+	prefetched map[string]struct{}
+	read       map[string]struct{}
+	finished   bool // prefetch is done
+	reported   bool // execution is done
+	hits       int
+}
+
+func NewPrefetchTracker() *PrefetchTracker {
+	return &PrefetchTracker{prefetched: map[string]struct{}{}, read: map[string]struct{}{}}
+}
+
+// Prefetched - ReadObserver of prefetcher
+func (t *PrefetchTracker) Prefetched(domain kv.Domain, k []byte) {
+	key := VersionedKey(domain, k)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.prefetched[key]; !ok {
+		t.prefetched[key] = struct{}{}
+		mxPrefetchKeys.Inc()
+	}
+}
+
+// Read - ReadObserver of execution
+func (t *PrefetchTracker) Read(domain kv.Domain, k []byte) {
+	key := VersionedKey(domain, k)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.read[key] = struct{}{}
+}
+
+// Report - updates hit-rate metrics by reads of executed block. Returns amount of keys read by execution
+// and how many of them were prefetched.
+func (t *PrefetchTracker) Report() (reads, hits int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.read {
+		if _, ok := t.prefetched[key]; ok {
+			hits++
+		}
+	}
+	reads = len(t.read)
+	mxPrefetchReads.AddInt(reads)
+	mxPrefetchHits.AddInt(hits)
+	t.reported, t.hits = true, hits
+	t.reportUnused()
+	return reads, hits
+}
+
+// Finish - marks prefetch of block as done, no more keys are warmed after it
+func (t *PrefetchTracker) Finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.finished = true
+	t.reportUnused()
+}
+
+// reportUnused - prefetch may still run when block is executed, so unused keys are known only when both are done
+func (t *PrefetchTracker) reportUnused() {
+	if t.finished && t.reported {
+		mxPrefetchUnused.AddInt(len(t.prefetched) - t.hits)
+	}
+}
@@ -446,6 +446,14 @@ func (w *StateWriterBufferedV3) CreateContract(address common.Address) error {
 	return nil
 }
 
+// ResettableStateWriter - writer of exec3 workers: collects write set of one txn
+type ResettableStateWriter interface {
+	StateWriter
+	ResetWriteSet()
+	WriteSet() map[string]*libstate.KvList
+	PrevAndDels() (map[string][]byte, map[string]*accounts.Account, map[string][]byte, map[string]uint64)
+}
+
 // StateWriterV3 - used by parallel workers to accumulate updates and then send them to conflict-resolution.
 type StateWriterV3 struct {
 	rs          *StateV3
@@ -556,6 +564,7 @@ type ReaderV3 struct {
 	trace     bool
 	tx        kv.TemporalGetter
 	composite []byte
+	observer  ReadObserver
 }
 
 func NewReaderV3(tx kv.TemporalGetter) *ReaderV3 {
@@ -573,7 +582,13 @@ func (r *ReaderV3) ReadSet() map[string]*libstate.KvList { return nil }
 func (r *ReaderV3) SetTrace(trace bool)                  { r.trace = trace }
 func (r *ReaderV3) ResetReadSet()                        {}
 
+// SetReadObserver - observer gets every key read by this reader (nil - disable)
+func (r *ReaderV3) SetReadObserver(observer ReadObserver) { r.observer = observer }
+
 func (r *ReaderV3) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	if r.observer != nil {
+		r.observer(kv.AccountsDomain, address[:])
+	}
 	enc, _, err := r.tx.GetLatest(kv.AccountsDomain, address[:])
 	if err != nil {
 		return nil, err
@@ -601,6 +616,9 @@ func (r *ReaderV3) ReadAccountDataForDebug(address common.Address) (*accounts.Ac
 
 func (r *ReaderV3) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
 	r.composite = append(append(r.composite[:0], address[:]...), key.Bytes()...)
+	if r.observer != nil {
+		r.observer(kv.StorageDomain, r.composite)
+	}
 	enc, _, err := r.tx.GetLatest(kv.StorageDomain, r.composite)
 	if err != nil {
 		return nil, err
@@ -616,6 +634,9 @@ func (r *ReaderV3) ReadAccountStorage(address common.Address, incarnation uint64
 }
 
 func (r *ReaderV3) ReadAccountCode(address common.Address, incarnation uint64) ([]byte, error) {
+	if r.observer != nil {
+		r.observer(kv.CodeDomain, address[:])
+	}
 	enc, _, err := r.tx.GetLatest(kv.CodeDomain, address[:])
 	if err != nil {
 		return nil, err
@@ -627,6 +648,9 @@ func (r *ReaderV3) ReadAccountCode(address common.Address, incarnation uint64) (
 }
 
 func (r *ReaderV3) ReadAccountCodeSize(address common.Address, incarnation uint64) (int, error) {
+	if r.observer != nil {
+		r.observer(kv.CodeDomain, address[:])
+	}
 	enc, _, err := r.tx.GetLatest(kv.CodeDomain, address[:])
 	if err != nil {
 		return 0, err
@@ -0,0 +1,454 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/holiman/uint256"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	libstate "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/turbo/shards"
+)
+
+// ErrDependency - returned by VersionedStateReader when txn read a value of lower txn which is being re-executed.
+// Such execution is discarded and txn is re-executed after the lower txn is done.
+var ErrDependency = errors.New("versioned read: dependency on txn being re-executed")
+
+// ErrVersionedUnsupported - txn did a state change which can't be expressed in VersionMap (for example,
+// deletion of account with storage). Such block must be executed serially.
+var ErrVersionedUnsupported = errors.New("versioned write: unsupported state change")
+
+// VersionMap - multi-version memory of Block-STM. For every account/storage slot/code it keeps values written
+// by txns of one block, so txn `i` reads value written by the highest txn `j < i` (or base state).
+// Accounts also support "blind" balance increases (see IntraBlockState.BalanceIncreaseSet) - they are
+// folded on read, so txns which only pay to coinbase do not conflict.
+type VersionMap struct {
+	emptyRemoval bool
+
+	mu    sync.RWMutex
+	cells map[string]*versionedCell
+
+	written [][]string // keys written by latest incarnation of each txn
+}
+
+type versionedEntry struct {
+	val      []byte       // nil - deleted
+	increase *uint256.Int // not nil - balance increase of account without reading it
+	estimate bool         // writer is being re-executed, readers must wait for it
+}
+
+type versionedCell struct {
+	mu      sync.RWMutex
+	txs     []int // sorted
+	entries map[int]versionedEntry
+}
+
+func NewVersionMap(txCount int, emptyRemoval bool) *VersionMap {
+	return &VersionMap{
+		emptyRemoval: emptyRemoval,
+		cells:        map[string]*versionedCell{},
+		written:      make([][]string, txCount),
+	}
+}
+
+func VersionedKey(domain kv.Domain, k []byte) string {
+	return string(append([]byte{byte(domain)}, k...))
+}
+
+// SplitVersionedKey - reverse of VersionedKey
+func SplitVersionedKey(key string) (kv.Domain, []byte) {
+	return kv.Domain(key[0]), []byte(key[1:])
+}
+
+func (vm *VersionMap) cell(key string, create bool) *versionedCell {
+	vm.mu.RLock()
+	c, ok := vm.cells[key]
+	vm.mu.RUnlock()
+	if ok || !create {
+		return c
+	}
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	if c, ok = vm.cells[key]; !ok {
+		c = &versionedCell{entries: map[int]versionedEntry{}}
+		vm.cells[key] = c
+	}
+	return c
+}
+
+// Read - returns value of `key` visible for txn `txIdx`. `base` is called if none of lower txns wrote the key.
+// blockedBy >= 0 means lower txn `blockedBy` is being re-executed and txIdx must wait for it.
+func (vm *VersionMap) Read(key string, txIdx int, base func() ([]byte, error)) (v []byte, blockedBy int, err error) {
+	var (
+		found       bool
+		increase    uint256.Int
+		hasIncrease bool
+	)
+	if c := vm.cell(key, false); c != nil {
+		c.mu.RLock()
+		for i := sortedIndexBelow(c.txs, txIdx); i >= 0; i-- {
+			e := c.entries[c.txs[i]]
+			if e.estimate {
+				c.mu.RUnlock()
+				return nil, c.txs[i], nil
+			}
+			if e.increase != nil {
+				increase.Add(&increase, e.increase)
+				hasIncrease = true
+				continue
+			}
+			v, found = e.val, true
+			break
+		}
+		c.mu.RUnlock()
+	}
+	if !found {
+		if v, err = base(); err != nil {
+			return nil, -1, err
+		}
+	}
+	if !hasIncrease {
+		return v, -1, nil
+	}
+
+	// same as StateV3.applyState does for BalanceIncreaseSet
+	var acc accounts.Account
+	if len(v) > 0 {
+		if err := accounts.DeserialiseV3(&acc, v); err != nil {
+			return nil, -1, err
+		}
+	}
+	acc.Balance.Add(&acc.Balance, &increase)
+	if vm.emptyRemoval && acc.Nonce == 0 && acc.Balance.IsZero() && acc.IsEmptyCodeHash() {
+		return nil, -1, nil
+	}
+	return accounts.SerialiseV3(&acc), -1, nil
+}
+
+// sortedIndexBelow - position of the highest element of `txs` which is `< txIdx`, or -1
+func sortedIndexBelow(txs []int, txIdx int) int {
+	i, _ := slices.BinarySearch(txs, txIdx)
+	return i - 1
+}
+
+// Record - replaces writes of previous incarnation of txn `txIdx` by `writes`. Returns true if
+// txn wrote a key which previous incarnation didn't write (then higher txns must be re-validated).
+func (vm *VersionMap) Record(txIdx int, writes *VersionedWriteSet) (wroteNewKey bool) {
+	prev := vm.written[txIdx]
+	keys := make([]string, 0, len(writes.keys))
+	for i, key := range writes.keys {
+		keys = append(keys, key)
+		c := vm.cell(key, true)
+		c.mu.Lock()
+		if _, ok := c.entries[txIdx]; !ok {
+			pos, _ := slices.BinarySearch(c.txs, txIdx)
+			c.txs = slices.Insert(c.txs, pos, txIdx)
+		}
+		c.entries[txIdx] = writes.entries[i]
+		c.mu.Unlock()
+		if !slices.Contains(prev, key) {
+			wroteNewKey = true
+		}
+	}
+	for _, key := range prev {
+		if slices.Contains(keys, key) {
+			continue
+		}
+		c := vm.cell(key, false)
+		c.mu.Lock()
+		if _, ok := c.entries[txIdx]; ok {
+			delete(c.entries, txIdx)
+			pos, _ := slices.BinarySearch(c.txs, txIdx)
+			c.txs = slices.Delete(c.txs, pos, pos+1)
+		}
+		c.mu.Unlock()
+	}
+	vm.written[txIdx] = keys
+	return wroteNewKey
+}
+
+// MarkEstimate - marks all writes of txn `txIdx` as estimates: txn is going to be re-executed
+func (vm *VersionMap) MarkEstimate(txIdx int) {
+	for _, key := range vm.written[txIdx] {
+		c := vm.cell(key, false)
+		c.mu.Lock()
+		e := c.entries[txIdx]
+		e.estimate = true
+		c.entries[txIdx] = e
+		c.mu.Unlock()
+	}
+}
+
+// ValidateReads - checks that values read by txn `txIdx` are still the ones visible for it
+func (vm *VersionMap) ValidateReads(txIdx int, reads *VersionedReadSet, base func(key string) ([]byte, error)) (bool, error) {
+	for i, key := range reads.keys {
+		v, blockedBy, err := vm.Read(key, txIdx, func() ([]byte, error) { return base(key) })
+		if err != nil {
+			return false, err
+		}
+		if blockedBy >= 0 || !bytes.Equal(v, reads.vals[i]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// VersionedReadSet - first value of each key read by txn
+type VersionedReadSet struct {
+	keys []string
+	vals [][]byte
+	seen map[string]struct{}
+}
+
+func (rs *VersionedReadSet) add(key string, v []byte) {
+	if rs.seen == nil {
+		rs.seen = map[string]struct{}{}
+	}
+	if _, ok := rs.seen[key]; ok {
+		return
+	}
+	rs.seen[key] = struct{}{}
+	rs.keys = append(rs.keys, key)
+	rs.vals = append(rs.vals, v)
+}
+
+func (rs *VersionedReadSet) Len() int { return len(rs.keys) }
+
+// VersionedWriteSet - writes of one txn incarnation, in order they must be applied
+type VersionedWriteSet struct {
+	keys    []string
+	entries []versionedEntry
+}
+
+func (ws *VersionedWriteSet) put(key string, v []byte) {
+	ws.keys = append(ws.keys, key)
+	ws.entries = append(ws.entries, versionedEntry{val: v})
+}
+
+func (ws *VersionedWriteSet) increase(key string, amount uint256.Int) {
+	ws.keys = append(ws.keys, key)
+	ws.entries = append(ws.entries, versionedEntry{increase: &amount})
+}
+
+func (ws *VersionedWriteSet) Len() int { return len(ws.keys) }
+
+// VersionedStateReader - reader of Block-STM workers: reads values visible for given txn from VersionMap,
+// falls back to `base` and records read set for validation.
+type VersionedStateReader struct {
+	vm        *VersionMap
+	base      func(key string) ([]byte, error)
+	txIdx     int
+	reads     VersionedReadSet
+	blockedBy int
+}
+
+func NewVersionedStateReader(vm *VersionMap, base func(key string) ([]byte, error)) *VersionedStateReader {
+	return &VersionedStateReader{vm: vm, base: base, blockedBy: -1}
+}
+
+// ResetVersioned - prepares reader for execution of txn `txIdx`
+func (r *VersionedStateReader) ResetVersioned(vm *VersionMap, txIdx int) {
+	r.vm, r.txIdx, r.blockedBy = vm, txIdx, -1
+	r.reads = VersionedReadSet{}
+}
+
+// BlockedBy - lower txn which must finish before this txn can be executed, or -1
+func (r *VersionedStateReader) BlockedBy() int           { return r.blockedBy }
+func (r *VersionedStateReader) Reads() *VersionedReadSet { return &r.reads }
+
+func (r *VersionedStateReader) SetTx(tx kv.TemporalTx)               {}
+func (r *VersionedStateReader) SetTxNum(txNum uint64)                {}
+func (r *VersionedStateReader) DiscardReadList()                     {}
+func (r *VersionedStateReader) ReadSet() map[string]*libstate.KvList { return nil }
+func (r *VersionedStateReader) ResetReadSet()                        { r.reads = VersionedReadSet{} }
+
+func (r *VersionedStateReader) read(domain kv.Domain, k []byte, record bool) ([]byte, error) {
+	if r.blockedBy >= 0 {
+		return nil, ErrDependency
+	}
+	key := VersionedKey(domain, k)
+	v, blockedBy, err := r.vm.Read(key, r.txIdx, func() ([]byte, error) { return r.base(key) })
+	if err != nil {
+		return nil, err
+	}
+	if blockedBy >= 0 {
+		r.blockedBy = blockedBy
+		return nil, ErrDependency
+	}
+	if record {
+		r.reads.add(key, v)
+	}
+	return v, nil
+}
+
+func (r *VersionedStateReader) readAccount(address common.Address, record bool) (*accounts.Account, error) {
+	enc, err := r.read(kv.AccountsDomain, address[:], record)
+	if err != nil || len(enc) == 0 {
+		return nil, err
+	}
+	var acc accounts.Account
+	if err := accounts.DeserialiseV3(&acc, enc); err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
+
+func (r *VersionedStateReader) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	return r.readAccount(address, true)
+}
+
+func (r *VersionedStateReader) ReadAccountDataForDebug(address common.Address) (*accounts.Account, error) {
+	return r.readAccount(address, false)
+}
+
+func (r *VersionedStateReader) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	return r.read(kv.StorageDomain, append(address.Bytes(), key.Bytes()...), true)
+}
+
+func (r *VersionedStateReader) ReadAccountCode(address common.Address, incarnation uint64) ([]byte, error) {
+	return r.read(kv.CodeDomain, address[:], true)
+}
+
+func (r *VersionedStateReader) ReadAccountCodeSize(address common.Address, incarnation uint64) (int, error) {
+	code, err := r.read(kv.CodeDomain, address[:], true)
+	return len(code), err
+}
+
+func (r *VersionedStateReader) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	return 0, nil
+}
+
+// VersionedStateWriter - writer of Block-STM workers. Mirrors StateWriterV3, but instead of writing to
+// SharedDomains it buffers updates: as VersionedWriteSet for VersionMap and as TxTask.WriteLists for
+// StateV3.ApplyState4. Accumulator notifications are replayed when txn is applied.
+type VersionedStateWriter struct {
+	writes      VersionedWriteSet
+	writeLists  map[string]*libstate.KvList
+	notify      []func(accumulator *shards.Accumulator)
+	unsupported error
+}
+
+func NewVersionedStateWriter() *VersionedStateWriter {
+	w := &VersionedStateWriter{}
+	w.ResetWriteSet()
+	return w
+}
+
+func (w *VersionedStateWriter) ResetWriteSet() {
+	w.writes = VersionedWriteSet{}
+	w.writeLists = newWriteList()
+	w.notify = nil
+	w.unsupported = nil
+}
+
+func (w *VersionedStateWriter) WriteSet() map[string]*libstate.KvList { return w.writeLists }
+func (w *VersionedStateWriter) PrevAndDels() (map[string][]byte, map[string]*accounts.Account, map[string][]byte, map[string]uint64) {
+	return nil, nil, nil, nil
+}
+
+// Writes - writes of executed txn, including balance increases which IntraBlockState didn't pass to writer
+func (w *VersionedStateWriter) Writes(balanceIncreases map[common.Address]uint256.Int) *VersionedWriteSet {
+	addrs := make([]common.Address, 0, len(balanceIncreases))
+	for addr := range balanceIncreases {
+		addrs = append(addrs, addr)
+	}
+	slices.SortFunc(addrs, func(a, b common.Address) int { return bytes.Compare(a[:], b[:]) })
+	for _, addr := range addrs {
+		w.writes.increase(VersionedKey(kv.AccountsDomain, addr[:]), balanceIncreases[addr])
+	}
+	return &w.writes
+}
+
+// Unsupported - not nil if txn did state change which VersionMap can't represent
+func (w *VersionedStateWriter) Unsupported() error { return w.unsupported }
+
+// Notifications - accumulator updates done by txn, must be replayed in txn order
+func (w *VersionedStateWriter) Notifications() []func(accumulator *shards.Accumulator) {
+	return w.notify
+}
+
+func (w *VersionedStateWriter) push(domain kv.Domain, k, v []byte) {
+	w.writeLists[domain.String()].Push(string(k), v)
+	w.writes.put(VersionedKey(domain, k), v)
+}
+
+func (w *VersionedStateWriter) UpdateAccountData(address common.Address, original, account *accounts.Account) error {
+	if original.Incarnation > account.Incarnation {
+		// re-creation of account does delete its storage - not representable without iteration over storage
+		w.unsupported = fmt.Errorf("%w: re-creation of %x", ErrVersionedUnsupported, address)
+		return nil
+	}
+	value := accounts.SerialiseV3(account)
+	incarnation := account.Incarnation
+	w.notify = append(w.notify, func(accumulator *shards.Accumulator) {
+		accumulator.ChangeAccount(address, incarnation, value)
+	})
+	w.push(kv.AccountsDomain, address[:], value)
+	return nil
+}
+
+func (w *VersionedStateWriter) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	if code == nil {
+		w.unsupported = fmt.Errorf("%w: nil code of %x", ErrVersionedUnsupported, address)
+		return nil
+	}
+	w.push(kv.CodeDomain, address[:], code)
+	w.notify = append(w.notify, func(accumulator *shards.Accumulator) {
+		accumulator.ChangeCode(address, incarnation, code)
+	})
+	return nil
+}
+
+func (w *VersionedStateWriter) DeleteAccount(address common.Address, original *accounts.Account) error {
+	if original != nil && (original.Incarnation > 0 || !original.IsEmptyCodeHash()) {
+		// deletion of contract does delete its code and storage
+		w.unsupported = fmt.Errorf("%w: deletion of contract %x", ErrVersionedUnsupported, address)
+		return nil
+	}
+	w.push(kv.AccountsDomain, address[:], nil)
+	return nil
+}
+
+func (w *VersionedStateWriter) WriteAccountStorage(address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	if *original == *value {
+		return nil
+	}
+	composite := append(address.Bytes(), key.Bytes()...)
+	v := value.Bytes()
+	if len(v) == 0 {
+		w.push(kv.StorageDomain, composite, nil)
+		return nil
+	}
+	k := *key
+	w.notify = append(w.notify, func(accumulator *shards.Accumulator) {
+		accumulator.ChangeStorage(address, incarnation, k, v)
+	})
+	w.push(kv.StorageDomain, composite, v)
+	return nil
+}
+
+func (w *VersionedStateWriter) CreateContract(address common.Address) error {
+	return nil
+}
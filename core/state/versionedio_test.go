@@ -0,0 +1,119 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/types/accounts"
+)
+
+func TestVersionMap(t *testing.T) {
+	addr := common.HexToAddress("0x01")
+	slot := common.HexToHash("0x02")
+	base := map[string][]byte{
+		VersionedKey(kv.AccountsDomain, addr[:]): accounts.SerialiseV3(&accounts.Account{Nonce: 1, Balance: *uint256.NewInt(100)}),
+	}
+	baseRead := func(key string) ([]byte, error) { return base[key], nil }
+
+	vm := NewVersionMap(4, true)
+	write := func(txIdx int, value uint64, increase uint64) bool {
+		w := NewVersionedStateWriter()
+		require.NoError(t, w.WriteAccountStorage(addr, 1, &slot, uint256.NewInt(0), uint256.NewInt(value)))
+		var increases map[common.Address]uint256.Int
+		if increase > 0 {
+			increases = map[common.Address]uint256.Int{addr: *uint256.NewInt(increase)}
+		}
+		return vm.Record(txIdx, w.Writes(increases))
+	}
+
+	require.True(t, write(0, 10, 0))
+	require.True(t, write(2, 30, 5))
+
+	r := NewVersionedStateReader(vm, baseRead)
+	read := func(txIdx int) (uint64, uint64) {
+		r.ResetVersioned(vm, txIdx)
+		v, err := r.ReadAccountStorage(addr, 1, &slot)
+		require.NoError(t, err)
+		acc, err := r.ReadAccountData(addr)
+		require.NoError(t, err)
+		return new(uint256.Int).SetBytes(v).Uint64(), acc.Balance.Uint64()
+	}
+
+	// txn reads values of the highest lower txn, balance increases are folded
+	v, balance := read(0)
+	require.Equal(t, uint64(0), v)
+	require.Equal(t, uint64(100), balance)
+	v, balance = read(1)
+	require.Equal(t, uint64(10), v)
+	require.Equal(t, uint64(100), balance)
+	v, balance = read(3)
+	require.Equal(t, uint64(30), v)
+	require.Equal(t, uint64(105), balance)
+	reads := *r.Reads()
+	require.Equal(t, 2, reads.Len())
+
+	// re-execution of txn 2 marks its writes as estimates: txn 3 must wait
+	vm.MarkEstimate(2)
+	r.ResetVersioned(vm, 3)
+	_, err := r.ReadAccountStorage(addr, 1, &slot)
+	require.ErrorIs(t, err, ErrDependency)
+	require.Equal(t, 2, r.BlockedBy())
+	valid, err := vm.ValidateReads(3, &reads, baseRead)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// same writes - reads of txn 3 stay valid
+	require.False(t, write(2, 30, 5))
+	valid, err = vm.ValidateReads(3, &reads, baseRead)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	// different value - reads of txn 3 are invalid, but txn 1 is not affected
+	require.False(t, write(2, 31, 5))
+	valid, err = vm.ValidateReads(3, &reads, baseRead)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// txn 0 doesn't write slot anymore - txn 1 reads base state
+	vm.Record(0, &VersionedWriteSet{})
+	v, _ = read(1)
+	require.Equal(t, uint64(0), v)
+}
+
+func TestVersionedStateWriterUnsupported(t *testing.T) {
+	addr := common.HexToAddress("0x01")
+
+	w := NewVersionedStateWriter()
+	require.NoError(t, w.DeleteAccount(addr, &accounts.Account{Balance: *uint256.NewInt(1)}))
+	require.NoError(t, w.Unsupported())
+	require.Equal(t, 1, w.Writes(nil).Len())
+
+	w.ResetWriteSet()
+	require.NoError(t, w.DeleteAccount(addr, &accounts.Account{Incarnation: 1}))
+	require.ErrorIs(t, w.Unsupported(), ErrVersionedUnsupported)
+
+	w.ResetWriteSet()
+	require.NoError(t, w.Unsupported())
+	require.NoError(t, w.UpdateAccountData(addr, &accounts.Account{Incarnation: 2}, &accounts.Account{Incarnation: 1}))
+	require.ErrorIs(t, w.Unsupported(), ErrVersionedUnsupported)
+}
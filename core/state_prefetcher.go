@@ -0,0 +1,212 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/core/vm/evmtypes"
+)
+
+// prefetchTimeout - prefetching of block which takes longer than slot is useless
+const prefetchTimeout = 12 * time.Second
+
+// maxPrefetchTrackers - amount of recent blocks for which prefetched keys are kept until block execution
+const maxPrefetchTrackers = 16
+
+// StatePrefetcher - warms Domain reads (accounts, storage, code) of a block before and during its execution.
+// Reads are done by concurrent read-only transactions, so files and DB pages are already in page-cache when
+// execution needs them. Keys are taken from coinbase, senders, recipients and access lists of txns, then
+// every txn is simulated on top of latest state (ignoring other txns of block) to discover the rest.
+// Warmed keys are registered in state.PrefetchTracker of block, execution takes it and reports hit-rate metrics.
+type StatePrefetcher struct {
+	config  *chain.Config
+	db      kv.TemporalRoDB
+	workers int
+	logger  log.Logger
+
+	trackersLock sync.Mutex
+	trackers     map[libcommon.Hash]*state.PrefetchTracker
+	order        []libcommon.Hash
+}
+
+func NewStatePrefetcher(config *chain.Config, db kv.TemporalRoDB, workers int, logger log.Logger) *StatePrefetcher {
+	return &StatePrefetcher{config: config, db: db, workers: max(workers, 1), logger: logger, trackers: map[libcommon.Hash]*state.PrefetchTracker{}}
+}
+
+// Track - creates tracker of block and registers it for execution.
+// Must be called before block is sent to execution, to let execution report hit-rate.
+func (p *StatePrefetcher) Track(blockHash libcommon.Hash) *state.PrefetchTracker {
+	t := state.NewPrefetchTracker()
+
+	p.trackersLock.Lock()
+	defer p.trackersLock.Unlock()
+	if _, ok := p.trackers[blockHash]; !ok {
+		p.order = append(p.order, blockHash)
+	}
+	p.trackers[blockHash] = t
+	for len(p.order) > maxPrefetchTrackers {
+		delete(p.trackers, p.order[0])
+		p.order = p.order[1:]
+	}
+	return t
+}
+
+// TakeTracker - returns tracker of block (nil if block wasn't prefetched) and unregisters it
+func (p *StatePrefetcher) TakeTracker(blockHash libcommon.Hash) *state.PrefetchTracker {
+	p.trackersLock.Lock()
+	defer p.trackersLock.Unlock()
+	t, ok := p.trackers[blockHash]
+	if !ok {
+		return nil
+	}
+	delete(p.trackers, blockHash)
+	for i, h := range p.order {
+		if h == blockHash {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+	return t
+}
+
+// Prefetch - warms state of block into tracker (see Track), returns when all keys are read or ctx is done.
+func (p *StatePrefetcher) Prefetch(ctx context.Context, block *types.Block, tracker *state.PrefetchTracker) error {
+	defer tracker.Finish()
+	ctx, cancel := context.WithTimeout(ctx, prefetchTimeout)
+	defer cancel()
+	start := time.Now()
+	if err := p.prefetch(ctx, block, tracker); err != nil {
+		return err
+	}
+	p.logger.Debug("[prefetch] done", "block", block.NumberU64(), "txs", block.Transactions().Len(), "took", time.Since(start))
+	return nil
+}
+
+func (p *StatePrefetcher) prefetch(ctx context.Context, block *types.Block, tracker *state.PrefetchTracker) error {
+	header := block.HeaderNoCopy()
+	txs := block.Transactions()
+	signer := types.MakeSigner(p.config, header.Number.Uint64(), header.Time)
+	rules := p.config.Rules(header.Number.Uint64(), header.Time)
+	coinbase := header.Coinbase
+	// block hashes are not known without chain reader - simulation is best-effort anyway
+	blockContext := NewEVMBlockContext(header, func(uint64) libcommon.Hash { return libcommon.Hash{} }, nil, &coinbase, p.config)
+
+	// cheap keys of all txns go first, then simulations
+	jobs := make(chan func(reader *state.ReaderV3), p.workers)
+	var wg sync.WaitGroup
+	var firstErr error
+	var errOnce sync.Once
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tx, err := p.db.BeginTemporalRo(ctx)
+			if err != nil {
+				errOnce.Do(func() { firstErr = err })
+				for range jobs { // don't block producer
+				}
+				return
+			}
+			defer tx.Rollback()
+			reader := state.NewReaderV3(tx)
+			reader.SetReadObserver(tracker.Prefetched)
+			for job := range jobs {
+				if ctx.Err() == nil {
+					job(reader)
+				}
+			}
+		}()
+	}
+
+	send := func(job func(reader *state.ReaderV3)) bool {
+		select {
+		case jobs <- job:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	send(func(reader *state.ReaderV3) { _, _ = reader.ReadAccountData(coinbase) })
+	for _, w := range block.Withdrawals() {
+		addr := w.Address
+		send(func(reader *state.ReaderV3) { _, _ = reader.ReadAccountData(addr) })
+	}
+	for _, txn := range txs {
+		txn := txn
+		if !send(func(reader *state.ReaderV3) { prefetchTxnKeys(reader, txn, signer) }) {
+			break
+		}
+	}
+	for _, txn := range txs {
+		txn := txn
+		if !send(func(reader *state.ReaderV3) { p.simulate(reader, txn, signer, header, rules, blockContext) }) {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// prefetchTxnKeys - reads keys known without execution: sender, recipient with its code, access list
+func prefetchTxnKeys(reader *state.ReaderV3, txn types.Transaction, signer *types.Signer) {
+	if sender, err := txn.Sender(*signer); err == nil {
+		_, _ = reader.ReadAccountData(sender)
+	}
+	if to := txn.GetTo(); to != nil {
+		if acc, _ := reader.ReadAccountData(*to); acc != nil && !acc.IsEmptyCodeHash() {
+			_, _ = reader.ReadAccountCode(*to, acc.Incarnation)
+		}
+	}
+	for _, tuple := range txn.GetAccessList() {
+		_, _ = reader.ReadAccountData(tuple.Address)
+		for i := range tuple.StorageKeys {
+			_, _ = reader.ReadAccountStorage(tuple.Address, 0, &tuple.StorageKeys[i])
+		}
+	}
+}
+
+// simulate - executes txn on top of latest state, result is discarded: only reads matter
+func (p *StatePrefetcher) simulate(reader *state.ReaderV3, txn types.Transaction, signer *types.Signer, header *types.Header, rules *chain.Rules, blockContext evmtypes.BlockContext) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			p.logger.Trace("[prefetch] simulation panic", "txn", txn.Hash(), "err", rec)
+		}
+	}()
+	msg, err := txn.AsMessage(*signer, header.BaseFee, rules)
+	if err != nil {
+		return
+	}
+	msg.SetCheckNonce(false) // previous txns of same sender are not applied
+	ibs := state.New(reader)
+	evm := vm.NewEVM(blockContext, NewEVMTxContext(msg), ibs, p.config, vm.Config{})
+	gp := new(GasPool).AddGas(msg.Gas()).AddBlobGas(msg.BlobGas())
+	_, _ = ApplyMessage(evm, msg, gp, true /* refunds */, false /* gasBailout */, nil)
+}
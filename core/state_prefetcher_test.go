@@ -0,0 +1,120 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+	state3 "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/tracing"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/params"
+)
+
+func TestStatePrefetcher(t *testing.T) {
+	t.Parallel()
+	logger := log.New()
+	dirs := datadir.New(t.TempDir())
+	db, _ := temporaltest.NewTestDB(t, dirs)
+
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	aa := libcommon.HexToAddress("0x000000000000000000000000000000000000aaaa")
+	bb := libcommon.HexToAddress("0x000000000000000000000000000000000000bbbb")
+	genesis := &types.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			sender: {Balance: big.NewInt(1_000_000_000)},
+			// sloads 0x01 - known only by simulation
+			aa: {Balance: big.NewInt(0), Code: []byte{byte(vm.PUSH1), 0x01, byte(vm.SLOAD), byte(vm.STOP)}, Storage: map[libcommon.Hash]libcommon.Hash{{31: 1}: {31: 5}}},
+		},
+	}
+	_, genesisBlock, err := core.CommitGenesisBlock(db, genesis, dirs, logger)
+	require.NoError(t, err)
+
+	// genesis state is written to Domains by execution stage - do it here
+	tx, err := db.BeginTemporalRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	sd, err := state3.NewSharedDomains(tx, logger)
+	require.NoError(t, err)
+	defer sd.Close()
+	ibs := state.New(state.NewReaderV3(sd))
+	for addr, account := range genesis.Alloc {
+		require.NoError(t, ibs.CreateAccount(addr, true))
+		require.NoError(t, ibs.AddBalance(addr, uint256.MustFromBig(account.Balance), tracing.BalanceIncreaseGenesisBalance))
+		require.NoError(t, ibs.SetCode(addr, account.Code))
+		for k, v := range account.Storage {
+			require.NoError(t, ibs.SetState(addr, &k, *uint256.NewInt(0).SetBytes(v.Bytes())))
+		}
+	}
+	require.NoError(t, ibs.CommitBlock(&chain.Rules{}, state.NewWriterV4(sd)))
+	require.NoError(t, sd.Flush(context.Background(), tx))
+	require.NoError(t, tx.Commit())
+
+	chainID, _ := uint256.FromBig(genesis.Config.ChainID)
+	txn, err := types.SignNewTx(key, *types.LatestSigner(genesis.Config), &types.AccessListTx{
+		ChainID: chainID,
+		LegacyTx: types.LegacyTx{
+			CommonTx: types.CommonTx{To: &aa, GasLimit: 50_000},
+			GasPrice: uint256.NewInt(1),
+		},
+		AccessList: types.AccessList{{Address: bb, StorageKeys: []libcommon.Hash{{31: 7}}}},
+	})
+	require.NoError(t, err)
+	header := &types.Header{
+		ParentHash: genesisBlock.Hash(),
+		Number:     big.NewInt(1),
+		GasLimit:   genesisBlock.GasLimit(),
+		Difficulty: big.NewInt(1),
+		Coinbase:   libcommon.Address{1},
+		Time:       genesisBlock.Time() + 1,
+	}
+	block := types.NewBlock(header, types.Transactions{txn}, nil, nil, nil)
+
+	prefetcher := core.NewStatePrefetcher(genesis.Config, db, 2, logger)
+	tracker := prefetcher.Track(block.Hash())
+	require.NoError(t, prefetcher.Prefetch(context.Background(), block, tracker))
+
+	require.Equal(t, tracker, prefetcher.TakeTracker(block.Hash()))
+	require.Nil(t, prefetcher.TakeTracker(block.Hash()))
+
+	tracker.Read(kv.AccountsDomain, sender[:])
+	tracker.Read(kv.AccountsDomain, header.Coinbase[:])
+	tracker.Read(kv.CodeDomain, aa[:])
+	tracker.Read(kv.StorageDomain, append(aa.Bytes(), libcommon.Hash{31: 1}.Bytes()...))
+	tracker.Read(kv.StorageDomain, append(bb.Bytes(), libcommon.Hash{31: 7}.Bytes()...))
+	tracker.Read(kv.StorageDomain, append(bb.Bytes(), libcommon.Hash{31: 8}.Bytes()...)) // not prefetched
+	reads, hits := tracker.Report()
+	require.Equal(t, 6, reads)
+	require.Equal(t, 5, hits)
+}
@@ -102,6 +102,15 @@ type storedReceiptRLP struct {
 	FirstLogIndex     uint32 // Logs have their own incremental Index within block. To allow calc it without re-executing whole block - can store it in Receipt
 }
 
+// receiptRLP69 is the network encoding of a receipt since eth/69: the type is a field
+// instead of the typed envelope and the bloom is omitted, it's derived from the logs.
+type receiptRLP69 struct {
+	Type              uint8
+	PostStateOrStatus []byte
+	CumulativeGasUsed uint64
+	Logs              []*Log
+}
+
 // NewReceipt creates a barebone transaction receipt, copying the init fields.
 // Deprecated: create receipts using a struct literal instead.
 func NewReceipt(failed bool, cumulativeGasUsed uint64) *Receipt {
@@ -150,6 +159,27 @@ func (r *Receipt) MarshalBinary() ([]byte, error) {
 	return buf.Bytes(), err
 }
 
+// EncodeRLP69 writes the eth/69 network encoding of the receipt.
+func (r *Receipt) EncodeRLP69(w io.Writer) error {
+	return rlp.Encode(w, &receiptRLP69{r.Type, r.statusEncoding(), r.CumulativeGasUsed, r.Logs})
+}
+
+// DecodeRLP69 reads a receipt in the eth/69 network encoding and derives its bloom.
+func (r *Receipt) DecodeRLP69(s *rlp.Stream) error {
+	var data receiptRLP69
+	if err := s.Decode(&data); err != nil {
+		return err
+	}
+	switch data.Type {
+	case LegacyTxType, AccessListTxType, DynamicFeeTxType, BlobTxType, SetCodeTxType:
+	default:
+		return ErrTxTypeNotSupported
+	}
+	r.Type, r.CumulativeGasUsed, r.Logs = data.Type, data.CumulativeGasUsed, data.Logs
+	r.Bloom = LogsBloom(data.Logs)
+	return r.setStatus(data.PostStateOrStatus)
+}
+
 // UnmarshalBinary decodes the consensus encoding of receipts.
 // It supports legacy RLP receipts and EIP-2718 typed receipts.
 func (r *Receipt) UnmarshalBinary(b []byte) error {
@@ -0,0 +1,298 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/holiman/uint256"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/core/vm/evmtypes"
+)
+
+// PrecompileFactory creates a custom precompile from its chain config entry
+type PrecompileFactory func(cfg *chain.PrecompileConfig) (PrecompiledContract, error)
+
+// precompileFactories - kinds of custom precompiles chain configs may enable
+var precompileFactories = map[string]PrecompileFactory{
+	"systemStorage": newSystemStorage,
+	"ed25519Verify": newEd25519Verify,
+}
+
+// RegisterPrecompile makes a kind of custom precompiles available to chain configs.
+// Not thread safe: must be called from init.
+func RegisterPrecompile(kind string, factory PrecompileFactory) {
+	if _, ok := precompileFactories[kind]; ok {
+		panic(fmt.Sprintf("precompile kind %q is already registered", kind))
+	}
+	precompileFactories[kind] = factory
+}
+
+// StatefulPrecompiledContract - custom precompile with access to the state, Run is not called by the EVM
+type StatefulPrecompiledContract interface {
+	PrecompiledContract
+	RunWithEnv(env *PrecompileEnv, input []byte) ([]byte, error)
+}
+
+// PrecompileEnv - context of a call of a stateful precompile
+type PrecompileEnv struct {
+	State    evmtypes.IntraBlockState
+	Caller   libcommon.Address
+	Address  libcommon.Address // account of the precompile
+	ReadOnly bool              // static call, state must not be modified
+}
+
+type boundPrecompile struct {
+	StatefulPrecompiledContract
+	env *PrecompileEnv
+}
+
+func (p *boundPrecompile) Run(input []byte) ([]byte, error) {
+	return p.RunWithEnv(p.env, input)
+}
+
+// instances are created once per distinct chain config entry, factories may be expensive
+var customPrecompileCache sync.Map // customPrecompileKey -> PrecompiledContract
+
+// customPrecompileKey - value of a chain config entry, entries of re-decoded configs share the instance
+type customPrecompileKey struct {
+	kind             string
+	address          libcommon.Address
+	block            string
+	baseGas, wordGas uint64
+	params           string
+}
+
+func newCustomPrecompileKey(cfg *chain.PrecompileConfig) customPrecompileKey {
+	key := customPrecompileKey{kind: cfg.Kind, address: cfg.Address, baseGas: cfg.BaseGas, wordGas: cfg.WordGas, params: string(cfg.Params)}
+	if cfg.Block != nil {
+		key.block = cfg.Block.String()
+	}
+	return key
+}
+
+func customPrecompile(cfg *chain.PrecompileConfig) (PrecompiledContract, error) {
+	key := newCustomPrecompileKey(cfg)
+	if p, ok := customPrecompileCache.Load(key); ok {
+		return p.(PrecompiledContract), nil
+	}
+	factory, ok := precompileFactories[cfg.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown precompile kind %q", cfg.Kind)
+	}
+	p, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("precompile %s at %x: %w", cfg.Kind, cfg.Address, err)
+	}
+	customPrecompileCache.Store(key, p)
+	return p, nil
+}
+
+// standardPrecompiles - precompiles of every fork, custom precompiles may not take their addresses
+var standardPrecompiles = []map[libcommon.Address]PrecompiledContract{
+	PrecompiledContractsHomestead,
+	PrecompiledContractsByzantium,
+	PrecompiledContractsIstanbul,
+	PrecompiledContractsBerlin,
+	PrecompiledContractsCancun,
+	PrecompiledContractsNapoli,
+	PrecompiledContractsPrague,
+}
+
+// ValidateCustomPrecompiles checks that the custom precompiles of the chain config are of known kinds,
+// have valid parameters and do not shadow each other or the precompiles of any fork.
+func ValidateCustomPrecompiles(config *chain.Config) error {
+	seen := make(map[libcommon.Address]struct{}, len(config.Precompiles))
+	for _, cfg := range config.Precompiles {
+		for _, standard := range standardPrecompiles {
+			if _, ok := standard[cfg.Address]; ok {
+				return fmt.Errorf("precompile %s at %x: address of a standard precompile", cfg.Kind, cfg.Address)
+			}
+		}
+		if _, ok := seen[cfg.Address]; ok {
+			return fmt.Errorf("precompile %s at %x: address is used by another precompile", cfg.Kind, cfg.Address)
+		}
+		seen[cfg.Address] = struct{}{}
+		if _, err := customPrecompile(cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activeCustomPrecompiles returns the custom precompiles enabled at the block, nil if there are none
+func activeCustomPrecompiles(config *chain.Config, blockNum uint64) map[libcommon.Address]PrecompiledContract {
+	var active map[libcommon.Address]PrecompiledContract
+	for _, cfg := range config.Precompiles {
+		if !cfg.IsActive(blockNum) {
+			continue
+		}
+		// invalid entries are rejected when the genesis is written
+		p, err := customPrecompile(cfg)
+		if err != nil {
+			continue
+		}
+		if active == nil {
+			active = make(map[libcommon.Address]PrecompiledContract, len(config.Precompiles))
+		}
+		active[cfg.Address] = p
+	}
+	return active
+}
+
+// CustomPrecompileAddresses returns addresses of the custom precompiles enabled at the block.
+func CustomPrecompileAddresses(config *chain.Config, blockNum uint64) []libcommon.Address {
+	var addrs []libcommon.Address
+	for _, cfg := range config.Precompiles {
+		if cfg.IsActive(blockNum) {
+			addrs = append(addrs, cfg.Address)
+		}
+	}
+	return addrs
+}
+
+// ActivePrecompilesAt returns addresses of the precompiles of the fork active at the block and of the custom
+// precompiles enabled by the chain config, same as EVM.ActivePrecompiles of an EVM running that block.
+func ActivePrecompilesAt(config *chain.Config, blockNum, blockTime uint64) []libcommon.Address {
+	active := ActivePrecompiles(config.Rules(blockNum, blockTime))
+	custom := CustomPrecompileAddresses(config, blockNum)
+	if len(custom) == 0 {
+		return active
+	}
+	slices.SortFunc(custom, libcommon.Address.Cmp)
+	return append(slices.Clone(active), custom...)
+}
+
+func customPrecompileGas(cfg *chain.PrecompileConfig, input []byte) uint64 {
+	return cfg.BaseGas + cfg.WordGas*ToWordSize(uint64(len(input)))
+}
+
+var (
+	errSystemStorageInput  = errors.New("input must be a 32 byte key, or a key and a 32 byte value")
+	errSystemStorageWriter = errors.New("caller may not write system storage")
+	errStatefulPrecompile  = errors.New("precompile requires access to the state")
+)
+
+// systemStorage - key-value storage in the account of the precompile. 32 bytes of input read the value of the key,
+// 64 bytes write the value of the key, allowed only to the configured writers.
+type systemStorage struct {
+	cfg      *chain.PrecompileConfig
+	writeGas uint64
+	writers  map[libcommon.Address]struct{}
+}
+
+type systemStorageParams struct {
+	Writers  []libcommon.Address `json:"writers"`
+	WriteGas uint64              `json:"writeGas"` // charged in addition to the base gas for writes
+}
+
+func newSystemStorage(cfg *chain.PrecompileConfig) (PrecompiledContract, error) {
+	var params systemStorageParams
+	if len(cfg.Params) > 0 {
+		if err := json.Unmarshal(cfg.Params, &params); err != nil {
+			return nil, err
+		}
+	}
+	writers := make(map[libcommon.Address]struct{}, len(params.Writers))
+	for _, w := range params.Writers {
+		writers[w] = struct{}{}
+	}
+	return &systemStorage{cfg: cfg, writeGas: params.WriteGas, writers: writers}, nil
+}
+
+func (c *systemStorage) RequiredGas(input []byte) uint64 {
+	if len(input) == 64 {
+		return customPrecompileGas(c.cfg, input) + c.writeGas
+	}
+	return customPrecompileGas(c.cfg, input)
+}
+
+func (c *systemStorage) Run(input []byte) ([]byte, error) {
+	return nil, errStatefulPrecompile
+}
+
+func (c *systemStorage) RunWithEnv(env *PrecompileEnv, input []byte) ([]byte, error) {
+	switch len(input) {
+	case 32:
+		key := libcommon.BytesToHash(input)
+		var value uint256.Int
+		if err := env.State.GetState(env.Address, &key, &value); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrIntraBlockStateFailed, err)
+		}
+		b := value.Bytes32()
+		return b[:], nil
+	case 64:
+		if env.ReadOnly {
+			return nil, ErrWriteProtection
+		}
+		if _, ok := c.writers[env.Caller]; !ok {
+			return nil, errSystemStorageWriter
+		}
+		// account without nonce, balance and code would be cleared as empty (EIP-161) along with its storage
+		nonce, err := env.State.GetNonce(env.Address)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrIntraBlockStateFailed, err)
+		}
+		if nonce == 0 {
+			if err := env.State.SetNonce(env.Address, 1); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrIntraBlockStateFailed, err)
+			}
+		}
+		key := libcommon.BytesToHash(input[:32])
+		var value uint256.Int
+		value.SetBytes(input[32:])
+		if err := env.State.SetState(env.Address, &key, value); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrIntraBlockStateFailed, err)
+		}
+		return nil, nil
+	default:
+		return nil, errSystemStorageInput
+	}
+}
+
+// ed25519Verify - verifies an Ed25519 signature, input is the 32 byte public key, the 64 byte signature
+// and the message. Returns 1 as a 32 byte word if the signature is valid, nothing otherwise.
+type ed25519Verify struct {
+	cfg *chain.PrecompileConfig
+}
+
+func newEd25519Verify(cfg *chain.PrecompileConfig) (PrecompiledContract, error) {
+	return &ed25519Verify{cfg: cfg}, nil
+}
+
+func (c *ed25519Verify) RequiredGas(input []byte) uint64 {
+	return customPrecompileGas(c.cfg, input)
+}
+
+func (c *ed25519Verify) Run(input []byte) ([]byte, error) {
+	const prefixLength = ed25519.PublicKeySize + ed25519.SignatureSize
+	if len(input) < prefixLength {
+		return nil, nil
+	}
+	publicKey := ed25519.PublicKey(input[:ed25519.PublicKeySize])
+	if !ed25519.Verify(publicKey, input[prefixLength:], input[ed25519.PublicKeySize:prefixLength]) {
+		return nil, nil
+	}
+	return libcommon.LeftPadBytes(big1.Bytes(), 32), nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm_test
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/core/vm/evmtypes"
+	"github.com/erigontech/erigon/params"
+)
+
+func TestCustomPrecompiles(t *testing.T) {
+	t.Parallel()
+	var (
+		writer       = libcommon.HexToAddress("0xaa")
+		storageAddr  = libcommon.HexToAddress("0x0b00")
+		verifierAddr = libcommon.HexToAddress("0x0b01")
+	)
+	storageParams, err := json.Marshal(map[string]any{"writers": []libcommon.Address{writer}, "writeGas": 20000})
+	require.NoError(t, err)
+	config := *params.AllProtocolChanges
+	config.Precompiles = []*chain.PrecompileConfig{
+		{Kind: "systemStorage", Address: storageAddr, BaseGas: 800, Params: storageParams},
+		{Kind: "ed25519Verify", Address: verifierAddr, Block: big.NewInt(10), BaseGas: 2000, WordGas: 10},
+	}
+	require.NoError(t, vm.ValidateCustomPrecompiles(&config))
+
+	invalid := config
+	invalid.Precompiles = []*chain.PrecompileConfig{{Kind: "ed25519Verify", Address: libcommon.BytesToAddress([]byte{1})}}
+	require.ErrorContains(t, vm.ValidateCustomPrecompiles(&invalid), "standard precompile")
+	invalid.Precompiles = []*chain.PrecompileConfig{config.Precompiles[0], {Kind: "ed25519Verify", Address: storageAddr}}
+	require.ErrorContains(t, vm.ValidateCustomPrecompiles(&invalid), "another precompile")
+	invalid.Precompiles = []*chain.PrecompileConfig{{Kind: "unknown", Address: storageAddr}}
+	require.ErrorContains(t, vm.ValidateCustomPrecompiles(&invalid), "unknown precompile kind")
+
+	tx, sd := testTemporalTxSD(t, testTemporalDB(t))
+	defer tx.Rollback()
+	s := state.New(state.NewReaderV3(sd))
+	newEVM := func(blockNum uint64) *vm.EVM {
+		vmctx := evmtypes.BlockContext{
+			CanTransfer: func(evmtypes.IntraBlockState, libcommon.Address, *uint256.Int) (bool, error) { return true, nil },
+			Transfer: func(evmtypes.IntraBlockState, libcommon.Address, libcommon.Address, *uint256.Int, bool) error {
+				return nil
+			},
+			BlockNumber: blockNum,
+		}
+		return vm.NewEVM(vmctx, evmtypes.TxContext{}, s, &config, vm.Config{})
+	}
+
+	t.Run("system storage", func(t *testing.T) {
+		evm := newEVM(0)
+		require.Contains(t, evm.ActivePrecompiles(), storageAddr)
+		require.NotContains(t, evm.ActivePrecompiles(), verifierAddr)
+		require.Equal(t, evm.ActivePrecompiles(), vm.ActivePrecompilesAt(&config, 0, 0))
+		// custom precompiles follow the standard ones ordered by address
+		active := newEVM(10).ActivePrecompiles()
+		require.Equal(t, []libcommon.Address{storageAddr, verifierAddr}, active[len(active)-2:])
+		require.Equal(t, active, vm.ActivePrecompilesAt(&config, 10, 0))
+
+		key, value := libcommon.HexToHash("0x01"), libcommon.HexToHash("0x2a")
+		write := append(key.Bytes(), value.Bytes()...)
+		_, gas, err := evm.Call(vm.AccountRef(writer), storageAddr, write, 100_000, new(uint256.Int), false)
+		require.NoError(t, err)
+		require.Equal(t, uint64(100_000-800-20000), gas)
+
+		ret, _, err := evm.StaticCall(vm.AccountRef(libcommon.Address{}), storageAddr, key.Bytes(), 100_000)
+		require.NoError(t, err)
+		require.Equal(t, value.Bytes(), ret)
+		nonce, err := s.GetNonce(storageAddr)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), nonce)
+
+		_, _, err = evm.StaticCall(vm.AccountRef(writer), storageAddr, write, 100_000)
+		require.ErrorIs(t, err, vm.ErrWriteProtection)
+		_, _, err = evm.Call(vm.AccountRef(libcommon.Address{}), storageAddr, write, 100_000, new(uint256.Int), false)
+		require.Error(t, err)
+		_, _, err = evm.Call(vm.AccountRef(writer), storageAddr, []byte{1}, 100_000, new(uint256.Int), false)
+		require.Error(t, err)
+	})
+
+	t.Run("ed25519", func(t *testing.T) {
+		publicKey, privateKey, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		msg := []byte("custom precompile")
+		input := append(append([]byte(publicKey), ed25519.Sign(privateKey, msg)...), msg...)
+
+		// not active before its activation block, so the call reaches an empty account
+		ret, gas, err := newEVM(9).StaticCall(vm.AccountRef(libcommon.Address{}), verifierAddr, input, 100_000)
+		require.NoError(t, err)
+		require.Empty(t, ret)
+		require.Equal(t, uint64(100_000), gas)
+
+		evm := newEVM(10)
+		ret, gas, err = evm.StaticCall(vm.AccountRef(libcommon.Address{}), verifierAddr, input, 100_000)
+		require.NoError(t, err)
+		require.Equal(t, libcommon.LeftPadBytes([]byte{1}, 32), ret)
+		require.Equal(t, uint64(100_000-2000-10*4), gas)
+
+		input[len(input)-1] ^= 1
+		ret, _, err = evm.StaticCall(vm.AccountRef(libcommon.Address{}), verifierAddr, input, 100_000)
+		require.NoError(t, err)
+		require.Empty(t, ret)
+	})
+}
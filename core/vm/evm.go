@@ -22,6 +22,7 @@ package vm
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sync/atomic"
 
 	"github.com/holiman/uint256"
@@ -56,10 +57,28 @@ func (evm *EVM) precompile(addr libcommon.Address) (PrecompiledContract, bool) {
 	default:
 		precompiles = PrecompiledContractsHomestead
 	}
-	p, ok := precompiles[addr]
+	if p, ok := precompiles[addr]; ok {
+		return p, true
+	}
+	p, ok := evm.customPrecompiles[addr]
 	return p, ok
 }
 
+// ActivePrecompiles returns addresses of the precompiles of the current fork and of the custom precompiles
+// enabled by the chain config.
+func (evm *EVM) ActivePrecompiles() []libcommon.Address {
+	active := ActivePrecompiles(evm.chainRules)
+	if len(evm.customPrecompiles) == 0 {
+		return active
+	}
+	custom := make([]libcommon.Address, 0, len(evm.customPrecompiles))
+	for addr := range evm.customPrecompiles {
+		custom = append(custom, addr)
+	}
+	slices.SortFunc(custom, libcommon.Address.Cmp)
+	return append(slices.Clone(active), custom...)
+}
+
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
 func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, error) {
 	return evm.interpreter.Run(contract, input, readOnly)
@@ -97,6 +116,8 @@ type EVM struct {
 	// available gas is calculated in gasCall* according to the 63/64 rule and later
 	// applied in opCall*.
 	callGasTemp uint64
+	// precompiles enabled by the chain config in addition to the ones of the fork
+	customPrecompiles map[libcommon.Address]PrecompiledContract
 
 	JumpDestCache *JumpDestCache
 }
@@ -118,6 +139,9 @@ func NewEVM(blockCtx evmtypes.BlockContext, txCtx evmtypes.TxContext, state evmt
 		chainRules:      chainConfig.Rules(blockCtx.BlockNumber, blockCtx.Time),
 		JumpDestCache:   NewJumpDestCache(),
 	}
+	if len(chainConfig.Precompiles) > 0 {
+		evm.customPrecompiles = activeCustomPrecompiles(chainConfig, blockCtx.BlockNumber)
+	}
 
 	evm.interpreter = NewEVMInterpreter(evm, vmConfig)
 
@@ -145,6 +169,9 @@ func (evm *EVM) ResetBetweenBlocks(blockCtx evmtypes.BlockContext, txCtx evmtype
 	evm.intraBlockState = ibs
 	evm.config = vmConfig
 	evm.chainRules = chainRules
+	if len(evm.chainConfig.Precompiles) > 0 {
+		evm.customPrecompiles = activeCustomPrecompiles(evm.chainConfig, blockCtx.BlockNumber)
+	}
 
 	evm.interpreter = NewEVMInterpreter(evm, vmConfig)
 
@@ -159,6 +186,12 @@ func (evm *EVM) Cancel() { evm.abort.Store(true) }
 // Cancelled returns true if Cancel has been called
 func (evm *EVM) Cancelled() bool { return evm.abort.Load() }
 
+// readOnly returns whether the current call frame runs in a static context
+func (evm *EVM) readOnly() bool {
+	ro, ok := evm.interpreter.(interface{ getReadonly() bool })
+	return ok && ro.getReadonly()
+}
+
 // CallGasTemp returns the callGasTemp for the EVM
 func (evm *EVM) CallGasTemp() uint64 {
 	return evm.callGasTemp
@@ -248,6 +281,14 @@ func (evm *EVM) call(typ OpCode, caller ContractRef, addr libcommon.Address, inp
 
 	// It is allowed to call precompiles, even via delegatecall
 	if isPrecompile {
+		if sp, ok := p.(StatefulPrecompiledContract); ok {
+			p = &boundPrecompile{sp, &PrecompileEnv{
+				State:    evm.intraBlockState,
+				Caller:   caller.Address(),
+				Address:  addr,
+				ReadOnly: typ == STATICCALL || evm.readOnly(),
+			}}
+		}
 		ret, gas, err = RunPrecompiledContract(p, input, gas, evm.Config().Tracer)
 	} else if len(code) == 0 {
 		// If the account has no code, we can abort here
@@ -48,6 +48,8 @@ type Config struct {
 	StatelessExec bool // true is certain conditions (like state trie root hash matching) need to be relaxed for stateless EVM execution
 	RestoreState  bool // Revert all changes made to the state (useful for constant system calls)
 
+	SuperInstructions bool // Run contracts by cached pre-decoded instruction stream with fused instructions (not traced ones)
+
 	ExtraEips []int // Additional EIPS that are to be enabled
 
 }
@@ -201,6 +203,9 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 	default:
 		jt = &frontierInstructionSet
 	}
+	if superInstructionsEnv {
+		cfg.SuperInstructions = true
+	}
 	if len(cfg.ExtraEips) > 0 {
 		jt = copyJumpTable(jt)
 		for i, eip := range cfg.ExtraEips {
@@ -290,6 +295,16 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		in.depth--
 	}()
 
+	if code := in.decodedCode(contract); code != nil {
+		if res, err = in.runDecoded(code, callContext, pc); err != errDecodedFallback {
+			if err == errStopToken {
+				err = nil // clear stop token error
+			}
+			return append(ret, res...), err
+		}
+		err = nil // continue by classic loop from _pc
+	}
+
 	// The Interpreter main run loop (contextual). This loop runs until either an
 	// explicit STOP, RETURN or SELFDESTRUCT is executed, an error occurred during
 	// the execution of one of the operations or until the done flag is set by the
@@ -0,0 +1,242 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package runtime
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	stateLib "github.com/erigontech/erigon-lib/state"
+
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+)
+
+type superInstructionsOutcome struct {
+	ret     []byte
+	gasLeft uint64
+	err     string
+	storage [8]uint256.Int
+	logs    types.Logs
+}
+
+func runSuperInstructions(t *testing.T, domains *stateLib.SharedDomains, chainConfig *chain.Config, code []byte, gas uint64, vmConfig vm.Config) superInstructionsOutcome {
+	t.Helper()
+	ibs := state.New(state.NewReaderV3(domains))
+	address := libcommon.HexToAddress("0xc0de")
+	require.NoError(t, ibs.SetCode(address, code))
+	cfg := &Config{State: ibs, ChainConfig: chainConfig, GasLimit: gas, EVMConfig: vmConfig, Time: big.NewInt(1)}
+	ret, gasLeft, err := Call(address, []byte{1, 2, 3}, cfg)
+	res := superInstructionsOutcome{ret: ret, gasLeft: gasLeft, logs: ibs.Logs()}
+	if err != nil {
+		res.err = err.Error()
+	}
+	for i := range res.storage {
+		key := libcommon.BigToHash(big.NewInt(int64(i)))
+		require.NoError(t, ibs.GetState(address, &key, &res.storage[i]))
+	}
+	return res
+}
+
+// requireSameExecution - superinstruction mode gives the same result, gas, storage and logs as classic loop
+func requireSameExecution(t *testing.T, domains *stateLib.SharedDomains, chainConfig *chain.Config, code []byte, gas uint64, skipAnalysis bool) {
+	t.Helper()
+	classic := runSuperInstructions(t, domains, chainConfig, code, gas, vm.Config{SkipAnalysis: skipAnalysis})
+	super := runSuperInstructions(t, domains, chainConfig, code, gas, vm.Config{SkipAnalysis: skipAnalysis, SuperInstructions: true})
+	require.Equal(t, classic, super, "code %x, gas %d, skipAnalysis %t", code, gas, skipAnalysis)
+}
+
+// superInstructionsProgram - random code biased to loops, branches, calls and fused sequences
+func superInstructionsProgram(r *rand.Rand, n int) []byte {
+	var (
+		code   []byte
+		labels []int // positions of PUSH2 immediates which are jump destinations
+		dests  []int
+	)
+	for i := 0; i < 4+r.Intn(8); i++ {
+		code = append(code, byte(vm.PUSH1), byte(r.Intn(8)))
+	}
+	for i := 0; i < n; i++ {
+		switch k := r.Intn(24); {
+		case k < 4:
+			code = append(code, byte(vm.PUSH1), byte(r.Intn(8)))
+		case k < 5:
+			size := 1 + r.Intn(32)
+			code = append(code, byte(vm.PUSH1)+byte(size-1))
+			for j := 0; j < size; j++ {
+				code = append(code, byte(r.Intn(256)))
+			}
+		case k < 7:
+			code = append(code, byte(vm.DUP1)+byte(r.Intn(4)))
+			if r.Intn(2) == 0 {
+				code = append(code, byte(vm.SWAP1)+byte(r.Intn(4)))
+			}
+		case k < 9:
+			code = append(code, byte(vm.SWAP1)+byte(r.Intn(4)), byte(vm.POP))
+		case k < 11:
+			dests = append(dests, len(code))
+			code = append(code, byte(vm.JUMPDEST))
+		case k < 13:
+			labels = append(labels, len(code)+1)
+			code = append(code, byte(vm.PUSH2), 0, 0, []byte{byte(vm.JUMP), byte(vm.JUMPI), byte(vm.JUMPI)}[r.Intn(3)])
+		case k < 14:
+			code = append(code, []byte{byte(vm.JUMP), byte(vm.JUMPI)}[r.Intn(2)])
+		case k < 16:
+			code = append(code, byte(vm.PUSH1), byte(r.Intn(8)), []byte{byte(vm.MSTORE), byte(vm.MLOAD), byte(vm.SSTORE), byte(vm.SLOAD), byte(vm.LOG1), byte(vm.CALLDATALOAD)}[r.Intn(6)])
+		case k < 17:
+			code = append(code, []byte{byte(vm.GAS), byte(vm.PC), byte(vm.MSIZE), byte(vm.PUSH0), byte(vm.RETURNDATASIZE)}[r.Intn(5)])
+		case k < 18:
+			// call itself with a part of gas
+			code = append(code, byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1), byte(vm.ADDRESS), byte(vm.PUSH2), byte(r.Intn(64)), 0, byte(vm.CALL))
+		case k < 19:
+			code = append(code, byte(vm.PUSH1), byte(r.Intn(64)), byte(vm.PUSH1), byte(r.Intn(8)), []byte{byte(vm.RETURN), byte(vm.REVERT)}[r.Intn(2)])
+		case k < 20:
+			op := byte(r.Intn(256))
+			if op >= byte(vm.BLOCKHASH) && op <= byte(vm.BLOBBASEFEE) {
+				op = byte(vm.INVALID) // block context of runtime is not complete
+			}
+			code = append(code, op)
+		default:
+			code = append(code, []byte{byte(vm.ADD), byte(vm.SUB), byte(vm.MUL), byte(vm.LT), byte(vm.ISZERO), byte(vm.AND), byte(vm.SHL), byte(vm.EQ), byte(vm.NOT), byte(vm.POP)}[r.Intn(10)])
+		}
+	}
+	for _, l := range labels {
+		dest := r.Intn(len(code) + 2) // mostly invalid destinations
+		if len(dests) > 0 && r.Intn(4) > 0 {
+			dest = dests[r.Intn(len(dests))]
+		}
+		code[l], code[l+1] = byte(dest>>8), byte(dest)
+	}
+	return code
+}
+
+func TestSuperInstructionsDifferential(t *testing.T) {
+	t.Parallel()
+	_, tx, _ := NewTestTemporalDb(t)
+	domains, err := stateLib.NewSharedDomains(tx, log.New())
+	require.NoError(t, err)
+	defer domains.Close()
+
+	istanbul := &chain.Config{
+		ChainID:               big.NewInt(1),
+		HomesteadBlock:        new(big.Int),
+		TangerineWhistleBlock: new(big.Int),
+		SpuriousDragonBlock:   new(big.Int),
+		ByzantiumBlock:        new(big.Int),
+		ConstantinopleBlock:   new(big.Int),
+		PetersburgBlock:       new(big.Int),
+		IstanbulBlock:         new(big.Int),
+	}
+	forks := map[string]*chain.Config{"Istanbul": istanbul, "Latest": nil}
+
+	t.Run("handcrafted", func(t *testing.T) {
+		programs := [][]byte{
+			// counting loop: PUSH JUMPI and DUP SWAP fusions, runs out of gas at every possible op
+			{byte(vm.PUSH1), 20, byte(vm.JUMPDEST), byte(vm.PUSH1), 1, byte(vm.SWAP1), byte(vm.SUB), byte(vm.DUP1), byte(vm.SWAP1),
+				byte(vm.DUP1), byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.DUP1), byte(vm.PUSH1), 2, byte(vm.JUMPI), byte(vm.STOP)},
+			// stack underflow in the middle of a segment
+			{byte(vm.PUSH1), 1, byte(vm.PUSH1), 2, byte(vm.ADD), byte(vm.ADD), byte(vm.PUSH1), 0, byte(vm.SSTORE)},
+			// constant jump into push data and to not jumpdest
+			{byte(vm.PUSH1), byte(vm.JUMPDEST), byte(vm.PUSH1), 1, byte(vm.JUMP), byte(vm.PUSH1), 7, byte(vm.PUSH1), 0, byte(vm.SSTORE)},
+			{byte(vm.PUSH1), 1, byte(vm.PUSH1), 3, byte(vm.JUMPI), byte(vm.STOP)},
+			// computed jump into push data
+			{byte(vm.PUSH1), byte(vm.JUMPDEST), byte(vm.PC), byte(vm.PUSH1), 1, byte(vm.SWAP1), byte(vm.SUB), byte(vm.JUMP), byte(vm.STOP),
+				byte(vm.PUSH1), 9, byte(vm.PUSH1), 1, byte(vm.SSTORE)},
+			// GAS is observed exactly
+			{byte(vm.PUSH1), 1, byte(vm.PUSH1), 2, byte(vm.GAS), byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.GAS), byte(vm.PUSH1), 1, byte(vm.SSTORE)},
+			// undefined opcode with non-empty stack and stack overflow
+			{byte(vm.PUSH1), 1, 0x0c},
+			{byte(vm.JUMPDEST), byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.JUMP)},
+			// revert keeps remaining gas
+			{byte(vm.PUSH1), 7, byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.REVERT)},
+			// truncated push at the end
+			{byte(vm.PUSH1), 5, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.PUSH32), 1, 2},
+		}
+		for name, chainConfig := range forks {
+			t.Run(name, func(t *testing.T) {
+				for _, code := range programs {
+					for gas := uint64(0); gas < 120; gas++ {
+						requireSameExecution(t, domains, chainConfig, code, gas, false)
+					}
+					for _, gas := range []uint64{1_000, 25_000, 100_000, 1_000_000} {
+						requireSameExecution(t, domains, chainConfig, code, gas, false)
+						requireSameExecution(t, domains, chainConfig, code, gas, true)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		programs := 300
+		if testing.Short() {
+			programs = 50
+		}
+		for name, chainConfig := range forks {
+			t.Run(name, func(t *testing.T) {
+				r := rand.New(rand.NewSource(1))
+				for i := 0; i < programs; i++ {
+					code := superInstructionsProgram(r, 1+r.Intn(64))
+					gas := []uint64{uint64(r.Intn(200)), uint64(r.Intn(5_000)), uint64(r.Intn(100_000))}[r.Intn(3)]
+					requireSameExecution(t, domains, chainConfig, code, gas, r.Intn(4) == 0)
+				}
+			})
+		}
+	})
+}
+
+func BenchmarkSuperInstructions(b *testing.B) {
+	_, tx, _ := NewTestTemporalDb(b)
+	domains, err := stateLib.NewSharedDomains(tx, log.New())
+	require.NoError(b, err)
+	defer domains.Close()
+
+	// loop of 1000 iterations: arithmetic, memory and stack shuffling
+	code := []byte{
+		byte(vm.PUSH2), 0x03, 0xe8, // counter
+		byte(vm.JUMPDEST), // 3
+		byte(vm.DUP1), byte(vm.DUP1), byte(vm.MUL), byte(vm.PUSH1), 7, byte(vm.ADD),
+		byte(vm.DUP2), byte(vm.SWAP1), byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.POP),
+		byte(vm.PUSH1), 1, byte(vm.SWAP1), byte(vm.SUB),
+		byte(vm.DUP1), byte(vm.PUSH1), 3, byte(vm.JUMPI),
+		byte(vm.STOP),
+	}
+	for _, super := range []bool{false, true} {
+		b.Run(fmt.Sprintf("super=%t", super), func(b *testing.B) {
+			ibs := state.New(state.NewReaderV3(domains))
+			address := libcommon.HexToAddress("0xc0de")
+			require.NoError(b, ibs.SetCode(address, code))
+			cfg := &Config{State: ibs, GasLimit: 10_000_000, EVMConfig: vm.Config{SuperInstructions: super}}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := Call(address, nil, cfg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+	stdmath "math"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/dbg"
+	"github.com/erigontech/erigon-lib/common/math"
+
+	"github.com/erigontech/erigon/core/tracing"
+)
+
+// Superinstruction interpreter mode (Config.SuperInstructions or EVM_SUPERINSTRUCTIONS=true env).
+//
+// Contract code is decoded once into a stream of instructions, cached by code hash: PUSH immediates are
+// decoded ahead of time, constant jump destinations are resolved to instruction indices and frequent
+// sequences (PUSHn JUMP, PUSHn JUMPI, DUPn SWAPm, SWAPn POP) are fused into one instruction.
+//
+// The stream is split into segments - straight-line code which doesn't depend on remaining gas. A segment
+// starts at JUMPDEST and ends after a jump, a halting op, GAS or an op with dynamic gas. Constant gas and
+// stack bounds of the whole segment are checked and charged once when the segment is entered. If the check
+// fails, the frame continues in the classic loop from the start of the segment, so errors and remaining gas
+// are exactly the same as in the classic loop. Tracers need per-opcode gas, so traced execution always
+// uses the classic loop.
+
+var (
+	superInstructionsEnv  = dbg.EnvBool("EVM_SUPERINSTRUCTIONS", false)
+	decodedCodeCacheLimit = dbg.EnvInt("EVM_DECODED_LRU", 1024)
+
+	decodedCodeCache = newDecodedCodeCache()
+
+	// errDecodedFallback - decoded code can't continue, classic loop continues from pc
+	errDecodedFallback = errors.New("continue in classic interpreter loop")
+)
+
+type decodedKey struct {
+	jt       *JumpTable // gas and stack bounds of segments depend on instruction set
+	codeHash libcommon.Hash
+}
+
+func newDecodedCodeCache() *lru.Cache[decodedKey, *decodedCode] {
+	c, err := lru.New[decodedKey, *decodedCode](decodedCodeCacheLimit)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+type insKind uint8
+
+const (
+	insOp        insKind = iota // executed by jump table
+	insPush                     // PUSH1..PUSH32 with decoded immediate
+	insJump                     // JUMP, resolved from decoded jumpdests
+	insJumpi                    // JUMPI, resolved from decoded jumpdests
+	insPushJump                 // PUSHn JUMP
+	insPushJumpi                // PUSHn JUMPI
+	insDupSwap                  // DUPn SWAPm
+	insSwapPop                  // SWAPn POP
+)
+
+type instruction struct {
+	pc     uint32 // pc of first op
+	pc2    uint32 // pc of second op of fused instruction, pc otherwise
+	op     OpCode
+	op2    OpCode // second op of fused instruction, op otherwise
+	kind   insKind
+	a, b   uint8 // Dup/Swap arguments of insDupSwap, insSwapPop
+	imm    int32 // index of pushed value in decodedCode.imms
+	target int32 // instruction of constant jump destination, -1 if it's not a valid jumpdest
+	seg    int32 // index in decodedCode.segs if instruction starts segment, -1 otherwise
+}
+
+type segment struct {
+	gas      uint64 // sum of constant gas
+	minStack int    // stack bounds on entry which pass stack validation of all ops
+	maxStack int
+}
+
+type decodedCode struct {
+	ins       []instruction
+	imms      []uint256.Int
+	segs      []segment
+	jumpdests []int32 // pc -> instruction of JUMPDEST at pc, -1 if pc is not a valid jumpdest
+}
+
+// decodeCode - builds instruction stream of code. Last instruction is STOP after the end of code.
+func decodeCode(code []byte, jt *JumpTable) *decodedCode {
+	d := &decodedCode{jumpdests: make([]int32, len(code))}
+	for i := range d.jumpdests {
+		d.jumpdests[i] = -1
+	}
+	for pc := 0; pc < len(code); {
+		op := OpCode(code[pc])
+		ins := instruction{pc: uint32(pc), pc2: uint32(pc), op: op, op2: op, target: -1, seg: -1}
+		next := pc + 1
+		switch {
+		case op >= PUSH1 && op <= PUSH32:
+			size := int(op-PUSH1) + 1
+			ins.kind, ins.imm = insPush, int32(len(d.imms))
+			d.imms = append(d.imms, pushValue(code, pc, size))
+			next = pc + 1 + size
+			if next < len(code) && (OpCode(code[next]) == JUMP || OpCode(code[next]) == JUMPI) {
+				ins.kind = insPushJump
+				if OpCode(code[next]) == JUMPI {
+					ins.kind = insPushJumpi
+				}
+				ins.pc2, ins.op2 = uint32(next), OpCode(code[next])
+				next++
+			}
+		case op == JUMP:
+			ins.kind = insJump
+		case op == JUMPI:
+			ins.kind = insJumpi
+		case op == JUMPDEST:
+			d.jumpdests[pc] = int32(len(d.ins))
+		case op >= DUP1 && op <= DUP16 && next < len(code) && OpCode(code[next]) >= SWAP1 && OpCode(code[next]) <= SWAP16:
+			ins.kind, ins.a, ins.b = insDupSwap, uint8(op-DUP1)+1, uint8(code[next]-byte(SWAP1))+2
+			ins.pc2, ins.op2 = uint32(next), OpCode(code[next])
+			next++
+		case op >= SWAP1 && op <= SWAP16 && next < len(code) && OpCode(code[next]) == POP:
+			ins.kind, ins.a = insSwapPop, uint8(op-SWAP1)+2
+			ins.pc2, ins.op2 = uint32(next), POP
+			next++
+		}
+		d.ins = append(d.ins, ins)
+		pc = next
+	}
+	d.ins = append(d.ins, instruction{pc: uint32(len(code)), pc2: uint32(len(code)), op: STOP, op2: STOP, target: -1, seg: -1})
+
+	height, start := 0, true
+	for i := range d.ins {
+		ins := &d.ins[i]
+		if ins.kind == insPushJump || ins.kind == insPushJumpi {
+			ins.target = int32(d.jumpdest(&d.imms[ins.imm]))
+		}
+		if ins.op == JUMPDEST {
+			start = true
+		}
+		if start {
+			ins.seg = int32(len(d.segs))
+			d.segs = append(d.segs, segment{maxStack: stdmath.MaxInt})
+			height, start = 0, false
+		}
+		seg := &d.segs[len(d.segs)-1]
+		ops := [2]OpCode{ins.op, ins.op2}
+		for _, op := range ops[:insLen(ins)] {
+			operation := jt[op]
+			seg.gas += operation.constantGas
+			seg.minStack = max(seg.minStack, operation.numPop-height)
+			seg.maxStack = min(seg.maxStack, operation.maxStack-height)
+			height += operation.numPush - operation.numPop
+			start = start || endsSegment(op, operation)
+		}
+	}
+	return d
+}
+
+func insLen(ins *instruction) int {
+	if ins.pc2 != ins.pc {
+		return 2
+	}
+	return 1
+}
+
+// endsSegment - op after which execution may depend on remaining gas or leave straight-line code
+func endsSegment(op OpCode, operation *operation) bool {
+	if operation.dynamicGas != nil {
+		return true
+	}
+	switch op {
+	case JUMP, JUMPI, STOP, RETURN, REVERT, SELFDESTRUCT, INVALID, GAS:
+		return true
+	}
+	return false
+}
+
+// pushValue - immediate of PUSH at pc, right-padded with zeroes if code ends before it, same as opPush
+func pushValue(code []byte, pc int, size int) uint256.Int {
+	start := min(pc+1, len(code))
+	end := min(start+size, len(code))
+	var v uint256.Int
+	v.SetBytes(libcommon.RightPadBytes(code[start:end], size))
+	return v
+}
+
+// jumpdest - instruction of JUMPDEST at dest, -1 if dest is not a valid jumpdest
+func (d *decodedCode) jumpdest(dest *uint256.Int) int {
+	if udest, overflow := dest.Uint64WithOverflow(); !overflow && udest < uint64(len(d.jumpdests)) {
+		return int(d.jumpdests[udest])
+	}
+	return -1
+}
+
+// decodedCode - instruction stream of contract, nil if contract must be run by classic loop
+func (in *EVMInterpreter) decodedCode(contract *Contract) *decodedCode {
+	// initcode (no code hash) runs once and isn't worth decoding
+	if !in.cfg.SuperInstructions || in.cfg.Tracer != nil || contract.CodeHash == (libcommon.Hash{}) || len(contract.Code) >= stdmath.MaxInt32 {
+		return nil
+	}
+	key := decodedKey{jt: in.jt, codeHash: contract.CodeHash}
+	if d, ok := decodedCodeCache.Get(key); ok {
+		return d
+	}
+	d := decodeCode(contract.Code, in.jt)
+	decodedCodeCache.Add(key, d)
+	return d
+}
+
+// runDecoded - runs instruction stream. Returns errDecodedFallback with pc where classic loop must continue.
+func (in *EVMInterpreter) runDecoded(d *decodedCode, scope *ScopeContext, pc *uint64) (res []byte, err error) {
+	var (
+		contract = scope.Contract
+		st       = scope.Stack
+		steps    = 0
+	)
+	for i := 0; ; {
+		steps++
+		if steps%1000 == 0 && in.evm.Cancelled() {
+			return nil, nil
+		}
+		ins := &d.ins[i]
+		if ins.seg >= 0 {
+			seg := &d.segs[ins.seg]
+			if sLen := st.Len(); sLen < seg.minStack || sLen > seg.maxStack || contract.Gas < seg.gas {
+				*pc = uint64(ins.pc)
+				return nil, errDecodedFallback
+			}
+			contract.Gas -= seg.gas
+		}
+		switch ins.kind {
+		case insPush:
+			st.Push(&d.imms[ins.imm])
+			i++
+			continue
+		case insDupSwap:
+			st.Dup(int(ins.a))
+			st.Swap(int(ins.b))
+			i++
+			continue
+		case insSwapPop:
+			st.Swap(int(ins.a))
+			st.Pop()
+			i++
+			continue
+		case insJump:
+			if target := d.jumpdest(st.Peek()); target >= 0 {
+				st.Pop()
+				i = target
+				continue
+			}
+		case insJumpi:
+			if st.Back(1).IsZero() {
+				st.Pop()
+				st.Pop()
+				i++
+				continue
+			}
+			if target := d.jumpdest(st.Peek()); target >= 0 {
+				st.Pop()
+				st.Pop()
+				i = target
+				continue
+			}
+		case insPushJump:
+			if ins.target >= 0 {
+				i = int(ins.target)
+				continue
+			}
+			st.Push(&d.imms[ins.imm])
+		case insPushJumpi:
+			if st.Peek().IsZero() {
+				st.Pop()
+				i++
+				continue
+			}
+			if ins.target >= 0 {
+				st.Pop()
+				i = int(ins.target)
+				continue
+			}
+			st.Push(&d.imms[ins.imm])
+		}
+
+		// Last op of instruction by jump table: not fused ops and jumps to not resolved destinations
+		// (invalid ones or push data jumpdests if analysis is skipped). Constant gas is already charged.
+		*pc = uint64(ins.pc2)
+		operation := in.jt[ins.op2]
+		if operation.dynamicGas != nil {
+			var memorySize uint64
+			if operation.memorySize != nil {
+				memSize, overflow := operation.memorySize(st)
+				if overflow {
+					return nil, ErrGasUintOverflow
+				}
+				if memorySize, overflow = math.SafeMul(ToWordSize(memSize), 32); overflow {
+					return nil, ErrGasUintOverflow
+				}
+			}
+			dynamicCost, err := operation.dynamicGas(in.evm, contract, st, scope.Memory, memorySize)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrOutOfGas, err)
+			}
+			if !contract.UseGas(dynamicCost, nil, tracing.GasChangeIgnored) {
+				return nil, ErrOutOfGas
+			}
+			if memorySize > 0 {
+				scope.Memory.Resize(memorySize)
+			}
+		}
+		if res, err = operation.execute(pc, in, scope); err != nil {
+			return res, err
+		}
+		if *pc == uint64(ins.pc2) {
+			i++
+			continue
+		}
+		// jump, execute sets pc before destination
+		next := *pc + 1
+		if next < uint64(len(d.jumpdests)) && d.jumpdests[next] >= 0 {
+			i = int(d.jumpdests[next])
+			continue
+		}
+		*pc = next
+		return nil, errDecodedFallback
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCode(t *testing.T) {
+	t.Parallel()
+	code := []byte{
+		byte(PUSH1), 1, // 0
+		byte(PUSH1), 7, byte(JUMPI), // 2: fused, jumps to JUMPDEST at 7
+		byte(DUP2), byte(SWAP1), // 5: fused
+		byte(JUMPDEST),         // 7
+		byte(SWAP2), byte(POP), // 8: fused
+		byte(PUSH2), 0, 5, byte(JUMP), // 10: fused, 5 is not a jumpdest
+		byte(PUSH1), // 14: immediate is cut by the end of code
+	}
+	d := decodeCode(code, &frontierInstructionSet)
+
+	kinds := make([]insKind, len(d.ins))
+	for i := range d.ins {
+		kinds[i] = d.ins[i].kind
+	}
+	require.Equal(t, []insKind{insPush, insPushJumpi, insDupSwap, insOp, insSwapPop, insPushJump, insPush, insOp}, kinds)
+	require.Equal(t, []uint256.Int{*uint256.NewInt(1), *uint256.NewInt(7), *uint256.NewInt(5), {}}, d.imms)
+
+	require.Equal(t, uint32(4), d.ins[1].pc2)
+	require.Equal(t, int32(3), d.ins[1].target)
+	require.Equal(t, int32(-1), d.ins[5].target)
+	require.Equal(t, int32(3), d.jumpdests[7])
+	require.Equal(t, int32(-1), d.jumpdests[3]) // push data
+	require.Equal(t, [2]uint8{2, 2}, [2]uint8{d.ins[2].a, d.ins[2].b})
+	require.Equal(t, uint8(3), d.ins[4].a)
+	require.Equal(t, STOP, d.ins[7].op)
+	require.Equal(t, uint32(len(code)), d.ins[7].pc)
+
+	segStarts := make([]int32, len(d.ins))
+	for i := range d.ins {
+		segStarts[i] = d.ins[i].seg
+	}
+	require.Equal(t, []int32{0, -1, 1, 2, -1, -1, 3, -1}, segStarts)
+	require.Equal(t, []segment{
+		{gas: 3 + 3 + 10, minStack: 0, maxStack: 1022},        // PUSH1 PUSH1 JUMPI
+		{gas: 3 + 3, minStack: 2, maxStack: 1023},             // DUP2 SWAP1
+		{gas: 1 + 3 + 2 + 3 + 8, minStack: 3, maxStack: 1024}, // JUMPDEST SWAP2 POP PUSH2 JUMP
+		{gas: 3, minStack: 0, maxStack: 1023},                 // PUSH1 STOP
+	}, d.segs)
+}
@@ -0,0 +1,288 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bundle collects the diagnostics of an Erigon node into a single tarball
+// (support bundle) and reads it back.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Version of the bundle layout.
+	Version = 1
+
+	ManifestName      = "manifest.json"
+	StageProgressName = "stages.json"
+	DatadirName       = "datadir.json"
+)
+
+// Manifest describes the bundle content, it is the last entry of the tarball.
+type Manifest struct {
+	Version       int       `json:"version"`
+	ErigonVersion string    `json:"erigonVersion"` // of the collecting binary
+	Created       time.Time `json:"created"`
+	WindowStart   time.Time `json:"windowStart"`
+	WindowEnd     time.Time `json:"windowEnd"`
+	Node          string    `json:"node"` // diagnostics URL
+	Entries       []Entry   `json:"entries"`
+	Redactions    int       `json:"redactions"` // number of secrets removed from the entries
+}
+
+// Entry is a collected file, or a source that failed to be collected.
+type Entry struct {
+	Name  string    `json:"name"`
+	Size  int       `json:"size"`
+	Time  time.Time `json:"time"`
+	Error string    `json:"error,omitempty"`
+}
+
+// Failed returns the entries which could not be collected.
+func (m *Manifest) Failed() []Entry {
+	var failed []Entry
+	for _, e := range m.Entries {
+		if e.Error != "" {
+			failed = append(failed, e)
+		}
+	}
+	return failed
+}
+
+// writer adds the entries to the tarball, it is safe for concurrent use.
+type writer struct {
+	mu       sync.Mutex
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	manifest Manifest
+}
+
+func newWriter(w io.Writer) *writer {
+	gz := gzip.NewWriter(w)
+	return &writer{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+func (w *writer) add(name string, data []byte, collected time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.writeFile(name, data, collected); err != nil {
+		return err
+	}
+	w.manifest.Entries = append(w.manifest.Entries, Entry{Name: name, Size: len(data), Time: collected})
+	return nil
+}
+
+// fail records the source which could not be collected.
+func (w *writer) fail(name string, err error, collected time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.manifest.Entries = append(w.manifest.Entries, Entry{Name: name, Time: collected, Error: err.Error()})
+}
+
+func (w *writer) writeFile(name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(data)
+	return err
+}
+
+func (w *writer) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data, err := json.MarshalIndent(&w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := w.writeFile(ManifestName, data, w.manifest.Created); err != nil {
+		return err
+	}
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+// Bundle is a support bundle read back into memory.
+type Bundle struct {
+	Manifest Manifest
+	files    map[string][]byte
+}
+
+// Open reads the bundle tarball.
+func Open(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a support bundle: %w", err)
+	}
+	defer gz.Close()
+
+	b := &Bundle{files: map[string][]byte{}}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading support bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", hdr.Name, err)
+		}
+		b.files[hdr.Name] = data
+	}
+
+	manifest, ok := b.files[ManifestName]
+	if !ok {
+		return nil, fmt.Errorf("support bundle has no %s", ManifestName)
+	}
+	if err := json.Unmarshal(manifest, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", ManifestName, err)
+	}
+	if b.Manifest.Version > Version {
+		return nil, fmt.Errorf("support bundle version %d is not supported, max %d", b.Manifest.Version, Version)
+	}
+	return b, nil
+}
+
+// Names returns the names of the files in the bundle, sorted.
+func (b *Bundle) Names() []string {
+	names := make([]string, 0, len(b.files))
+	for name := range b.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// File returns the content of the named file.
+func (b *Bundle) File(name string) ([]byte, bool) {
+	data, ok := b.files[name]
+	return data, ok
+}
+
+// Samples returns the names of the samples of a diagnostics endpoint or metrics, the oldest first.
+func (b *Bundle) Samples(dir string) []string {
+	var names []string
+	for _, name := range b.Names() {
+		if path.Dir(name) == dir {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Summary writes a human readable overview of the bundle.
+func (b *Bundle) Summary(w io.Writer) error {
+	m := &b.Manifest
+	fmt.Fprintf(w, "Support bundle v%d collected by Erigon %s at %s\n", m.Version, m.ErigonVersion, m.Created.Format(time.RFC3339))
+	fmt.Fprintf(w, "Node:        %s\n", m.Node)
+	fmt.Fprintf(w, "Window:      %s - %s (%s)\n", m.WindowStart.Format(time.RFC3339), m.WindowEnd.Format(time.RFC3339), m.WindowEnd.Sub(m.WindowStart).Round(time.Second))
+	fmt.Fprintf(w, "Redactions:  %d\n", m.Redactions)
+
+	if version, ok := b.files[path.Join(diagnosticsDir, "version.json")]; ok {
+		fmt.Fprintf(w, "Node version: %s\n", strings.TrimSpace(string(version)))
+	}
+
+	if data, ok := b.files[StageProgressName]; ok {
+		var stages []StageProgress
+		if err := json.Unmarshal(data, &stages); err == nil {
+			fmt.Fprintln(w, "\nStage progress:")
+			for _, s := range stages {
+				fmt.Fprintf(w, "  %-24s %12d  (pruned %d)\n", s.Stage, s.Progress, s.PruneProgress)
+			}
+		}
+	}
+
+	if data, ok := b.files[DatadirName]; ok {
+		var inventory DatadirInventory
+		if err := json.Unmarshal(data, &inventory); err == nil {
+			fmt.Fprintf(w, "\nDatadir %s: %d files\n", inventory.Root, len(inventory.Files))
+			dirs := make([]string, 0, len(inventory.DirSizes))
+			for dir := range inventory.DirSizes {
+				dirs = append(dirs, dir)
+			}
+			sort.Strings(dirs)
+			for _, dir := range dirs {
+				fmt.Fprintf(w, "  %-24s %s\n", dir, formatSize(inventory.DirSizes[dir]))
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "\nContent:")
+	groups := map[string][2]int{}
+	for _, e := range m.Entries {
+		if e.Error != "" {
+			continue
+		}
+		group := e.Name
+		if dir := path.Dir(e.Name); dir != "." {
+			group = dir + "/"
+		}
+		g := groups[group]
+		groups[group] = [2]int{g[0] + 1, g[1] + e.Size}
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-40s %4d files %10s\n", name, groups[name][0], formatSize(int64(groups[name][1])))
+	}
+
+	if failed := m.Failed(); len(failed) > 0 {
+		fmt.Fprintln(w, "\nNot collected:")
+		for _, e := range failed {
+			fmt.Fprintf(w, "  %-40s %s\n", e.Name, e.Error)
+		}
+	}
+	return nil
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
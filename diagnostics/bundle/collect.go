@@ -0,0 +1,392 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+const (
+	diagnosticsDir = "diagnostics"
+	metricsDir     = "metrics"
+	profilesDir    = "profiles"
+	logsDir        = "logs"
+
+	sampleTimeFormat = "20060102T150405.000Z"
+)
+
+// diagnostics endpoints collected once
+var diagnosticsEndpoints = []string{
+	"version",
+	"cmdline",
+	"flags",
+	"nodeinfo",
+	"hardware-info",
+	"processes-info",
+	"bootnodes",
+	"nat",
+	"snapshot-files-list",
+}
+
+// diagnostics endpoints sampled during the window
+var diagnosticsSampledEndpoints = []string{
+	"sync-stages",
+	"sync-eta",
+	"snapshot-sync",
+	"peers",
+	"headers",
+	"bodies",
+	"resources-usage",
+	"mem",
+	"cpu-usage",
+	"memory-info",
+}
+
+// profiles of the diagnostics /pprof/ endpoint collected at the end of the window
+var diagnosticsProfiles = []string{"heap", "goroutine", "allocs"}
+
+// Config of the bundle collection.
+type Config struct {
+	DiagnosticsURL string // diagnostics endpoint, e.g. http://127.0.0.1:6062/debug/diag
+	MetricsURL     string // prometheus metrics, e.g. http://127.0.0.1:6061/debug/metrics/prometheus
+	PprofURL       string // net/http/pprof endpoint for the CPU profile, e.g. http://127.0.0.1:6060/debug/pprof
+
+	DataDir string // datadir to list the files of
+	LogDir  string // directory of the node log files
+
+	Window    time.Duration // how long the diagnostics and metrics are sampled, and the CPU profiled
+	Interval  time.Duration // time between the samples
+	LogsSince time.Duration // log files modified within this time are collected
+	LogsLimit int           // max bytes of a single log file, the older lines are cut
+
+	ErigonVersion string
+	Sources       []Source // additional entries, e.g. read from the databases
+}
+
+// Source produces a bundle entry.
+type Source struct {
+	Name    string
+	Collect func(ctx context.Context) ([]byte, error)
+}
+
+// StageProgress is an entry of the StageProgressName file.
+type StageProgress struct {
+	Stage         string `json:"stage"`
+	Progress      uint64 `json:"progress"`
+	PruneProgress uint64 `json:"pruneProgress"`
+}
+
+// DatadirInventory is the content of the DatadirName file.
+type DatadirInventory struct {
+	Root     string           `json:"root"`
+	Files    []DatadirFile    `json:"files"`
+	DirSizes map[string]int64 `json:"dirSizes"` // total size of the top level directories
+}
+
+type DatadirFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+type collector struct {
+	cfg      Config
+	client   *http.Client
+	w        *writer
+	redactor *Redactor
+	logger   log.Logger
+}
+
+// Collect writes the support bundle to w. Sources which can't be collected, e.g. because the node
+// is not running, are listed in the manifest with the error instead of failing the collection.
+func Collect(ctx context.Context, cfg Config, w io.Writer, logger log.Logger) (*Manifest, error) {
+	c := &collector{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Window + time.Minute},
+		w:        newWriter(w),
+		redactor: NewRedactor(),
+		logger:   logger,
+	}
+	now := time.Now().UTC()
+	c.w.manifest = Manifest{
+		Version:       Version,
+		ErigonVersion: cfg.ErigonVersion,
+		Created:       now,
+		WindowStart:   now,
+		Node:          cfg.DiagnosticsURL,
+	}
+
+	var wg sync.WaitGroup
+	var cpuErr error
+	if cfg.PprofURL != "" && cfg.Window >= time.Second {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			url := fmt.Sprintf("%s/profile?seconds=%d", strings.TrimSuffix(cfg.PprofURL, "/"), int(cfg.Window.Seconds()))
+			cpuErr = c.collectBinary(ctx, path.Join(profilesDir, "cpu.pprof"), url)
+		}()
+	}
+
+	err := c.collectAll(ctx)
+	wg.Wait()
+	if err == nil {
+		err = cpuErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.w.manifest.WindowEnd = time.Now().UTC()
+	c.w.manifest.Redactions = c.redactor.Count()
+	if err := c.w.close(); err != nil {
+		return nil, err
+	}
+	return &c.w.manifest, nil
+}
+
+func (c *collector) collectAll(ctx context.Context) error {
+	if c.cfg.DiagnosticsURL != "" {
+		for _, endpoint := range diagnosticsEndpoints {
+			if err := c.collectText(ctx, path.Join(diagnosticsDir, endpoint+".json"), c.diagnosticsURL(endpoint)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		if err := c.sample(ctx); err != nil {
+			return err
+		}
+		if time.Since(c.w.manifest.WindowStart)+c.cfg.Interval > c.cfg.Window || c.cfg.Interval <= 0 {
+			break
+		}
+		select {
+		case <-time.After(c.cfg.Interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if c.cfg.DiagnosticsURL != "" {
+		for _, profile := range diagnosticsProfiles {
+			if err := c.collectBinary(ctx, path.Join(profilesDir, profile+".pprof"), c.diagnosticsURL("pprof/"+profile)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.collectLogs(); err != nil {
+		return err
+	}
+	if c.cfg.DataDir != "" {
+		if err := c.collectSource(ctx, Source{Name: DatadirName, Collect: c.datadirInventory}); err != nil {
+			return err
+		}
+	}
+	for _, source := range c.cfg.Sources {
+		if err := c.collectSource(ctx, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sample collects the diagnostics and metrics which change over time.
+func (c *collector) sample(ctx context.Context) error {
+	ts := time.Now().UTC().Format(sampleTimeFormat)
+	if c.cfg.DiagnosticsURL != "" {
+		for _, endpoint := range diagnosticsSampledEndpoints {
+			if err := c.collectText(ctx, path.Join(diagnosticsDir, endpoint, ts+".json"), c.diagnosticsURL(endpoint)); err != nil {
+				return err
+			}
+		}
+	}
+	if c.cfg.MetricsURL != "" {
+		if err := c.collectText(ctx, path.Join(metricsDir, ts+".prom"), c.cfg.MetricsURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *collector) diagnosticsURL(endpoint string) string {
+	return strings.TrimSuffix(c.cfg.DiagnosticsURL, "/") + "/" + endpoint
+}
+
+// collectText fetches the url and adds the redacted response.
+// Only the write errors are returned, failed requests are recorded in the manifest.
+func (c *collector) collectText(ctx context.Context, name, url string) error {
+	return c.collectSource(ctx, Source{Name: name, Collect: func(ctx context.Context) ([]byte, error) {
+		data, err := c.get(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(name, ".json") {
+			return c.redactor.RedactJSON(data), nil
+		}
+		return c.redactor.Redact(data), nil
+	}})
+}
+
+// collectBinary fetches the url and adds the response as is.
+func (c *collector) collectBinary(ctx context.Context, name, url string) error {
+	return c.collectSource(ctx, Source{Name: name, Collect: func(ctx context.Context) ([]byte, error) {
+		return c.get(ctx, url)
+	}})
+}
+
+func (c *collector) collectSource(ctx context.Context, source Source) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	data, err := source.Collect(ctx)
+	collected := time.Now().UTC()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.logger.Debug("[support-bundle] not collected", "entry", source.Name, "err", err)
+		c.w.fail(source.Name, err, collected)
+		return nil
+	}
+	return c.w.add(source.Name, data, collected)
+}
+
+func (c *collector) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// collectLogs adds the tails of the recently modified log files.
+func (c *collector) collectLogs() error {
+	if c.cfg.LogDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(c.cfg.LogDir)
+	if err != nil {
+		c.w.fail(logsDir, err, time.Now().UTC())
+		return nil
+	}
+	since := time.Now().Add(-c.cfg.LogsSince)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || (c.cfg.LogsSince > 0 && info.ModTime().Before(since)) {
+			continue
+		}
+		data, err := readTail(filepath.Join(c.cfg.LogDir, entry.Name()), c.cfg.LogsLimit)
+		name := path.Join(logsDir, entry.Name())
+		if err != nil {
+			c.w.fail(name, err, time.Now().UTC())
+			continue
+		}
+		if err := c.w.add(name, c.redactor.Redact(data), info.ModTime().UTC()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readTail reads up to limit last bytes of the file, starting from a whole line.
+func readTail(filePath string, limit int) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || info.Size() <= int64(limit) {
+		return io.ReadAll(f)
+	}
+	data := make([]byte, limit)
+	if _, err := f.ReadAt(data, info.Size()-int64(limit)); err != nil {
+		return nil, err
+	}
+	if i := strings.IndexByte(string(data), '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return data, nil
+}
+
+// datadirInventory lists the datadir files with their sizes, the content is not read.
+func (c *collector) datadirInventory(ctx context.Context) ([]byte, error) {
+	inventory := DatadirInventory{Root: c.cfg.DataDir, DirSizes: map[string]int64{}}
+	err := filepath.WalkDir(c.cfg.DataDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// unreadable directories are skipped, the rest is still useful
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() || d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(c.cfg.DataDir, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		inventory.Files = append(inventory.Files, DatadirFile{Path: rel, Size: info.Size(), ModTime: info.ModTime().UTC()})
+		top := "."
+		if i := strings.IndexByte(rel, '/'); i >= 0 {
+			top = rel[:i]
+		}
+		inventory.DirSizes[top] += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(&inventory, "", "  ")
+}
@@ -61,6 +61,11 @@ func SetupPeersAccess(ctxclient *cli.Context, metricsMux *http.ServeMux, node *n
 		w.Header().Set("Content-Type", "application/json")
 		writePeers(w, ctxclient, node, diag)
 	})
+
+	metricsMux.HandleFunc("/nat", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		diag.NATStatusJson(w)
+	})
 }
 
 func writePeers(w http.ResponseWriter, ctx *cli.Context, node *node.ErigonNode, diag *diaglib.DiagnosticClient) {
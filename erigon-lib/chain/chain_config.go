@@ -84,10 +84,14 @@ type Config struct {
 	// See also EIP-6110: Supply validator deposits on chain
 	DepositContract common.Address `json:"depositContractAddress,omitempty"`
 
+	// (Optional) precompiles of private networks, enabled in addition to the ones of the active fork
+	Precompiles []*PrecompileConfig `json:"precompiles,omitempty"`
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
 	Aura   *AuRaConfig   `json:"aura,omitempty"`
+	QBFT   *QBFTConfig   `json:"qbft,omitempty"`
 
 	Bor     BorConfig       `json:"-"`
 	BorJSON json.RawMessage `json:"bor,omitempty"`
@@ -96,6 +100,22 @@ type Config struct {
 	AllowAA bool
 }
 
+// PrecompileConfig - custom precompile enabled at the address from the activation block,
+// kinds of precompiles are registered in core/vm
+type PrecompileConfig struct {
+	Kind    string          `json:"kind"`
+	Address common.Address  `json:"address"`
+	Block   *big.Int        `json:"block,omitempty"`   // activation block, genesis if not set
+	BaseGas uint64          `json:"baseGas"`           // gas charged per call
+	WordGas uint64          `json:"wordGas,omitempty"` // gas charged per 32-byte word of input
+	Params  json.RawMessage `json:"params,omitempty"`  // parameters specific to the kind
+}
+
+// IsActive returns whether the precompile is enabled at the given block.
+func (p *PrecompileConfig) IsActive(num uint64) bool {
+	return p.Block == nil || isForked(p.Block, num)
+}
+
 type BlobConfig struct {
 	Target                *uint64 `json:"target,omitempty"`
 	Max                   *uint64 `json:"max,omitempty"`
@@ -202,6 +222,8 @@ func (c *Config) getEngine() string {
 		return c.Bor.String()
 	case c.Aura != nil:
 		return c.Aura.String()
+	case c.QBFT != nil:
+		return c.QBFT.String()
 	default:
 		return "unknown"
 	}
@@ -553,6 +575,18 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// QBFTConfig is the consensus engine configs for byzantine fault tolerant sealing with immediate finality.
+type QBFTConfig struct {
+	BlockPeriod    uint64 `json:"blockperiodseconds"`    // Minimum number of seconds between blocks
+	Epoch          uint64 `json:"epochlength"`           // Number of blocks after which pending validator votes are reset
+	RequestTimeout uint64 `json:"requesttimeoutseconds"` // Timeout of round 0, doubled on every round change
+}
+
+// String implements the stringer interface, returning the consensus engine details.
+func (c *QBFTConfig) String() string {
+	return "qbft"
+}
+
 // Looks up a config value as of a given block number (or time).
 // The assumption here is that config is a càdlàg map of starting_from_block -> value.
 // For example, config of {"0": "0xA", "10": "0xB", "20": "0xC"}
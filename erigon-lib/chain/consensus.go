@@ -23,4 +23,5 @@ const (
 	EtHashConsensus ConsensusName = "ethash"
 	CliqueConsensus ConsensusName = "clique"
 	BorConsensus    ConsensusName = "bor"
+	QBFTConsensus   ConsensusName = "qbft"
 )
@@ -29,6 +29,8 @@ const (
 	BorE2ETestChain2Val = "bor-e2e-test-2Val"
 	Chiado              = "chiado"
 	Test                = "test"
+	QBFTDevnet          = "qbft-devnet"
+	CliqueDevnet        = "clique-devnet"
 )
 
 var All = []string{
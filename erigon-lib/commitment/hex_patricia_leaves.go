@@ -0,0 +1,262 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commitment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+)
+
+// LeafFunc is called by WalkAccounts and WalkStorage for every visited leaf with its hashed key (32 bytes,
+// for storage leaves it is the hash of the slot only) and plain key. For account leaves storageRoot holds
+// the root hash of the account storage trie, for storage leaves it is nil. Returning false stops the walk.
+type LeafFunc func(hashedKey, plainKey, storageRoot []byte) (bool, error)
+
+// WalkAccounts visits account leaves in ascending order of their hashed keys, starting from the first leaf
+// with hashed key >= from (nil means from the very first leaf). Leaves are discovered by reading branch nodes
+// through the trie context, so trie state has to be restored (or processed) before the call.
+func (hph *HexPatriciaHashed) WalkAccounts(from []byte, fn LeafFunc) error {
+	return hph.newLeafWalker(nil, from, false, fn).walk()
+}
+
+// WalkAccountsReverse visits account leaves with hashed keys < before in descending order.
+func (hph *HexPatriciaHashed) WalkAccountsReverse(before []byte, fn LeafFunc) error {
+	return hph.newLeafWalker(nil, before, true, fn).walk()
+}
+
+// WalkStorage visits storage leaves of the account with given hashed key in ascending order of their hashed
+// slots, starting from the first slot with hash >= from (nil means from the very first slot).
+func (hph *HexPatriciaHashed) WalkStorage(hashedAccount, from []byte, fn LeafFunc) error {
+	if len(hashedAccount) != length.Hash {
+		return fmt.Errorf("WalkStorage: hashed account of length %d, expected %d", len(hashedAccount), length.Hash)
+	}
+	return hph.newLeafWalker(hashedAccount, from, false, fn).walk()
+}
+
+// WalkStorageReverse visits storage leaves of the account with hashed slots < before in descending order.
+func (hph *HexPatriciaHashed) WalkStorageReverse(hashedAccount, before []byte, fn LeafFunc) error {
+	if len(hashedAccount) != length.Hash {
+		return fmt.Errorf("WalkStorageReverse: hashed account of length %d, expected %d", len(hashedAccount), length.Hash)
+	}
+	return hph.newLeafWalker(hashedAccount, before, true, fn).walk()
+}
+
+type leafWalker struct {
+	hph     *HexPatriciaHashed
+	fn      LeafFunc
+	bound   []byte // nibbles of the walk bound; for storage walks first 64 nibbles are the account
+	storage bool
+	reverse bool
+	stopped bool
+}
+
+func (hph *HexPatriciaHashed) newLeafWalker(hashedAccount, bound []byte, reverse bool, fn LeafFunc) *leafWalker {
+	w := &leafWalker{hph: hph, fn: fn, reverse: reverse, storage: hashedAccount != nil}
+	if w.storage {
+		w.bound = append(make([]byte, 0, 128), splitOntoHexNibbles(hashedAccount)...)
+	}
+	keyBound := make([]byte, 64)
+	switch {
+	case len(bound) > 0:
+		copy(keyBound, splitOntoHexNibbles(bound[:min(len(bound), length.Hash)]))
+	case reverse:
+		// walk everything: bound is above any key
+		for i := range keyBound {
+			keyBound[i] = terminatorHexByte
+		}
+	}
+	w.bound = append(w.bound, keyBound...)
+	return w
+}
+
+func (w *leafWalker) walk() error {
+	root := &w.hph.root
+	if root.hashedExtLen == 0 {
+		return w.walkBranch(nil)
+	}
+	return w.walkCell(root, root.hashedExtension[:root.hashedExtLen])
+}
+
+// skip returns true if none of the keys starting with path could be visited.
+func (w *leafWalker) skip(path []byte) bool {
+	n := min(len(path), len(w.bound))
+	if w.storage {
+		// subtree has to belong to the walked account
+		a := min(n, 64)
+		if !bytes.Equal(path[:a], w.bound[:a]) {
+			return true
+		}
+	}
+	if w.reverse {
+		return bytes.Compare(path[:n], w.bound[:n]) > 0
+	}
+	return bytes.Compare(path[:n], w.bound[:n]) < 0
+}
+
+func (w *leafWalker) walkBranch(prefix []byte) error {
+	data, _, err := w.hph.ctx.Branch(hexNibblesToCompactBytes(prefix))
+	if err != nil {
+		return err
+	}
+	if len(data) < 4 {
+		return nil
+	}
+	// cells are stored in the order of nibbles, so all of them are decoded before walking in either direction
+	var (
+		cells   [16]cell
+		nibbles = make([]int, 0, 16)
+		pos     = 4
+	)
+	for bitset := binary.BigEndian.Uint16(data[2:]); bitset != 0; {
+		bit := bitset & -bitset
+		nibble := bits.TrailingZeros16(bit)
+		bitset ^= bit
+
+		if pos >= len(data) {
+			return fmt.Errorf("branch %x: cell %x is missing", prefix, nibble)
+		}
+		fieldBits := cellFields(data[pos])
+		pos++
+		if pos, err = cells[nibble].fillFromFields(data, pos, fieldBits); err != nil {
+			return fmt.Errorf("branch %x: %w", prefix, err)
+		}
+		nibbles = append(nibbles, nibble)
+	}
+	depth := len(prefix) + 1
+	for i := range nibbles {
+		if w.stopped {
+			break
+		}
+		nibble := nibbles[i]
+		if w.reverse {
+			nibble = nibbles[len(nibbles)-1-i]
+		}
+		path := append(append(make([]byte, 0, 128), prefix...), byte(nibble))
+		if w.skip(path) {
+			continue
+		}
+		c := &cells[nibble]
+		if err = c.deriveHashedKeys(depth, w.hph.keccak, w.hph.accountKeyLen); err != nil {
+			return err
+		}
+		if err = w.walkCell(c, append(path, c.hashedExtension[:c.hashedExtLen]...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkCell visits the leaf held by the cell or descends into the branch node below it. Path is the full
+// nibble path of the cell, including its extension.
+func (w *leafWalker) walkCell(c *cell, path []byte) error {
+	if w.skip(path) {
+		return nil
+	}
+	if !w.storage {
+		if c.accountAddrLen > 0 {
+			if len(path) < 64 {
+				return fmt.Errorf("account leaf %x at path of length %d", c.accountAddr[:c.accountAddrLen], len(path))
+			}
+			if !w.inBounds(path[:64]) {
+				return nil
+			}
+			storageRoot, err := w.storageRoot(c, path)
+			if err != nil {
+				return err
+			}
+			return w.emit(path[:64], c.accountAddr[:c.accountAddrLen], storageRoot)
+		}
+		if len(path) >= 64 {
+			return nil
+		}
+		return w.walkBranch(path)
+	}
+	if c.storageAddrLen > 0 {
+		if len(path) < 128 {
+			return fmt.Errorf("storage leaf %x at path of length %d", c.storageAddr[:c.storageAddrLen], len(path))
+		}
+		if !w.inBounds(path[64:128]) {
+			return nil
+		}
+		return w.emit(path[64:128], c.storageAddr[:c.storageAddrLen], nil)
+	}
+	if len(path) >= 128 {
+		return nil
+	}
+	// either an account leaf with storage trie hanging below or a branch on the way to it
+	return w.walkBranch(path)
+}
+
+func (w *leafWalker) inBounds(hashedKey []byte) bool {
+	cmp := bytes.Compare(hashedKey, w.bound[len(w.bound)-64:])
+	if w.reverse {
+		return cmp < 0
+	}
+	return cmp >= 0
+}
+
+// storageRoot returns root hash of the storage trie of the account held by the cell.
+func (w *leafWalker) storageRoot(c *cell, path []byte) ([]byte, error) {
+	switch {
+	case c.storageAddrLen > 0:
+		// the only storage slot is folded into the account leaf
+		if len(path) < 128 {
+			return nil, fmt.Errorf("account leaf %x with storage at path of length %d", c.accountAddr[:c.accountAddrLen], len(path))
+		}
+		update, err := w.hph.ctx.Storage(c.storageAddr[:c.storageAddrLen])
+		if err != nil {
+			return nil, err
+		}
+		key := append(append(make([]byte, 0, 65), path[64:128]...), terminatorHexByte)
+		leaf, err := w.hph.leafHashWithKeyVal(make([]byte, 0, 33), key, update.Storage[:update.StorageLen], true)
+		if err != nil {
+			return nil, err
+		}
+		return leaf[1:], nil
+	case c.extLen > 0:
+		if c.hashLen == 0 {
+			return nil, fmt.Errorf("account leaf %x: extension without hash", c.accountAddr[:c.accountAddrLen])
+		}
+		root, err := w.hph.extensionHash(c.extension[:c.extLen], c.hash[:c.hashLen])
+		if err != nil {
+			return nil, err
+		}
+		return root[:], nil
+	case c.hashLen > 0:
+		return common.Copy(c.hash[:c.hashLen]), nil
+	default:
+		return EmptyRootHash, nil
+	}
+}
+
+func (w *leafWalker) emit(hashedKey, plainKey, storageRoot []byte) error {
+	key, err := compactKey(hashedKey)
+	if err != nil {
+		return err
+	}
+	cont, err := w.fn(key, plainKey, storageRoot)
+	if err != nil {
+		return err
+	}
+	w.stopped = !cont
+	return nil
+}
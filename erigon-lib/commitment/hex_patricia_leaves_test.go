@@ -0,0 +1,209 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commitment
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/trie"
+)
+
+type walkedLeaf struct {
+	hashed, plain string
+}
+
+func reversed(leaves []walkedLeaf) []walkedLeaf {
+	if len(leaves) == 0 {
+		return nil
+	}
+	res := make([]walkedLeaf, len(leaves))
+	for i, l := range leaves {
+		res[len(leaves)-1-i] = l
+	}
+	return res
+}
+
+func collectLeaves(t *testing.T, walk func(LeafFunc) error, limit int) []walkedLeaf {
+	t.Helper()
+	var leaves []walkedLeaf
+	err := walk(func(hashedKey, plainKey, _ []byte) (bool, error) {
+		leaves = append(leaves, walkedLeaf{hashed: string(hashedKey), plain: string(plainKey)})
+		return limit <= 0 || len(leaves) < limit, nil
+	})
+	require.NoError(t, err)
+	return leaves
+}
+
+func sortedLeaves(plainKeys [][]byte, hash func([]byte) []byte) []walkedLeaf {
+	leaves := make([]walkedLeaf, 0, len(plainKeys))
+	for _, pk := range plainKeys {
+		leaves = append(leaves, walkedLeaf{hashed: string(hash(pk)), plain: string(pk)})
+	}
+	sort.Slice(leaves, func(i, j int) bool { return leaves[i].hashed < leaves[j].hashed })
+	return leaves
+}
+
+func hashAccount(pk []byte) []byte { return crypto.Keccak256(pk) }
+
+func Test_HexPatriciaHashed_WalkLeaves(t *testing.T) {
+	t.Parallel()
+
+	ms := NewMockState(t)
+	rnd := rand.New(rand.NewSource(42))
+	ub := NewUpdateBuilder()
+
+	var accounts [][]byte
+	for i := 0; i < 300; i++ {
+		addr := make([]byte, length.Addr)
+		rnd.Read(addr)
+		accounts = append(accounts, addr)
+		ub.Balance(hex.EncodeToString(addr), uint64(i+1))
+	}
+	// first account has plenty of storage, second holds a single slot folded into the account leaf
+	slots := make(map[int][][]byte)
+	for i := 0; i < 64; i++ {
+		slot := make([]byte, length.Hash)
+		rnd.Read(slot)
+		slots[0] = append(slots[0], append(common.Copy(accounts[0]), slot...))
+		ub.Storage(hex.EncodeToString(accounts[0]), hex.EncodeToString(slot), fmt.Sprintf("%02x", i+1))
+	}
+	slots[1] = [][]byte{append(common.Copy(accounts[1]), make([]byte, length.Hash)...)}
+	ub.Storage(hex.EncodeToString(accounts[1]), hex.EncodeToString(make([]byte, length.Hash)), "01")
+
+	plainKeys, updates := ub.Build()
+	require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+
+	hph := NewHexPatriciaHashed(length.Addr, ms, ms.TempDir())
+	upds := WrapKeyUpdates(t, ModeDirect, KeyToHexNibbleHash, plainKeys, updates)
+	defer upds.Close()
+	_, err := hph.Process(context.Background(), upds, "")
+	require.NoError(t, err)
+
+	// walk the trie restored from the encoded state, as it is done by the readers
+	state, err := hph.EncodeCurrentState(nil)
+	require.NoError(t, err)
+	restored := NewHexPatriciaHashed(length.Addr, ms, ms.TempDir())
+	require.NoError(t, restored.SetState(state))
+
+	expected := sortedLeaves(accounts, hashAccount)
+	t.Run("accounts", func(t *testing.T) {
+		got := collectLeaves(t, func(fn LeafFunc) error { return restored.WalkAccounts(nil, fn) }, 0)
+		require.Equal(t, expected, got)
+	})
+	t.Run("accounts from", func(t *testing.T) {
+		from := []byte(expected[100].hashed)
+		got := collectLeaves(t, func(fn LeafFunc) error { return restored.WalkAccounts(from, fn) }, 10)
+		require.Equal(t, expected[100:110], got)
+
+		// origin in between of two leaves
+		from = common.Copy(from)
+		from[length.Hash-1]++
+		got = collectLeaves(t, func(fn LeafFunc) error { return restored.WalkAccounts(from, fn) }, 0)
+		require.Equal(t, expected[101:], got)
+
+		got = collectLeaves(t, func(fn LeafFunc) error { return restored.WalkAccounts(bytes.Repeat([]byte{0xff}, 32), fn) }, 0)
+		require.Empty(t, got)
+	})
+	t.Run("accounts reverse", func(t *testing.T) {
+		got := collectLeaves(t, func(fn LeafFunc) error { return restored.WalkAccountsReverse(nil, fn) }, 0)
+		require.Equal(t, reversed(expected), got)
+
+		got = collectLeaves(t, func(fn LeafFunc) error { return restored.WalkAccountsReverse([]byte(expected[100].hashed), fn) }, 1)
+		require.Equal(t, expected[99:100], got)
+
+		got = collectLeaves(t, func(fn LeafFunc) error { return restored.WalkAccountsReverse([]byte(expected[0].hashed), fn) }, 0)
+		require.Empty(t, got)
+	})
+	t.Run("storage roots", func(t *testing.T) {
+		roots := make(map[string][]byte)
+		err := restored.WalkAccounts(nil, func(_, plainKey, storageRoot []byte) (bool, error) {
+			roots[string(plainKey)] = storageRoot
+			return true, nil
+		})
+		require.NoError(t, err)
+		require.Len(t, roots, len(accounts))
+
+		for i, addr := range accounts {
+			keys, ok := slots[i]
+			if !ok {
+				require.Equal(t, EmptyRootHash, roots[string(addr)])
+				continue
+			}
+			tr := trie.New(common.Hash{})
+			for _, key := range keys {
+				value, err := ms.Storage(key)
+				require.NoError(t, err)
+				enc, err := rlp.EncodeToBytes(value.Storage[:value.StorageLen])
+				require.NoError(t, err)
+				tr.Update(crypto.Keccak256(key[length.Addr:]), enc)
+			}
+			require.Equal(t, tr.Hash().Bytes(), roots[string(addr)], "account %x", addr)
+		}
+	})
+	t.Run("storage", func(t *testing.T) {
+		for i, keys := range slots {
+			hashedAccount := crypto.Keccak256(accounts[i])
+			want := sortedLeaves(keys, func(pk []byte) []byte { return crypto.Keccak256(pk[length.Addr:]) })
+			got := collectLeaves(t, func(fn LeafFunc) error { return restored.WalkStorage(hashedAccount, nil, fn) }, 0)
+			require.Equal(t, want, got)
+
+			from := []byte(want[len(want)/2].hashed)
+			got = collectLeaves(t, func(fn LeafFunc) error { return restored.WalkStorage(hashedAccount, from, fn) }, 0)
+			require.Equal(t, want[len(want)/2:], got)
+
+			got = collectLeaves(t, func(fn LeafFunc) error { return restored.WalkStorageReverse(hashedAccount, from, fn) }, 0)
+			require.Equal(t, reversed(want[:len(want)/2]), got)
+		}
+		got := collectLeaves(t, func(fn LeafFunc) error { return restored.WalkStorage(crypto.Keccak256(accounts[2]), nil, fn) }, 0)
+		require.Empty(t, got)
+	})
+}
+
+func Test_HexPatriciaHashed_WalkLeavesSingleAccount(t *testing.T) {
+	t.Parallel()
+
+	ms := NewMockState(t)
+	plainKeys, updates := NewUpdateBuilder().
+		Balance("00000000000000000000000000000000000000f5", 4).
+		Build()
+	require.NoError(t, ms.applyPlainUpdates(plainKeys, updates))
+
+	hph := NewHexPatriciaHashed(length.Addr, ms, ms.TempDir())
+	upds := WrapKeyUpdates(t, ModeDirect, KeyToHexNibbleHash, plainKeys, updates)
+	defer upds.Close()
+	_, err := hph.Process(context.Background(), upds, "")
+	require.NoError(t, err)
+
+	got := collectLeaves(t, func(fn LeafFunc) error { return hph.WalkAccounts(nil, fn) }, 0)
+	require.Equal(t, sortedLeaves(plainKeys, hashAccount), got)
+
+	empty := NewHexPatriciaHashed(length.Addr, NewMockState(t), ms.TempDir())
+	got = collectLeaves(t, func(fn LeafFunc) error { return empty.WalkAccounts(nil, fn) }, 0)
+	require.Empty(t, got)
+}
@@ -96,7 +96,7 @@ func DataDirForNetwork(datadir string, network string) string {
 	}
 
 	switch network {
-	case networkname.Dev:
+	case networkname.Dev, networkname.QBFTDevnet, networkname.CliqueDevnet:
 		return "" // unless explicitly requested, use memory databases
 	case networkname.Holesky:
 		return networkDataDirCheckingLegacy(datadir, "holesky")
@@ -25,12 +25,16 @@ import (
 	"os"
 	"sync"
 
+	goethkzg "github.com/crate-crypto/go-eth-kzg"
 	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
 )
 
 const (
 	BlobCommitmentVersionKZG uint8 = 0x01
 	PrecompileInputLength    int   = 192
+
+	// CellsPerExtBlob is the number of cells (and thus of cell KZG proofs) of an extended blob, EIP-7594
+	CellsPerExtBlob = goethkzg.CellsPerExtBlob
 )
 
 type VersionedHash [32]byte
@@ -45,6 +49,9 @@ var (
 
 	gokzgCtx      *gokzg4844.Context
 	initCryptoCtx sync.Once
+
+	goethkzgCtx       *goethkzg.Context
+	initCellCryptoCtx sync.Once
 )
 
 func init() {
@@ -94,6 +101,89 @@ func Ctx() *gokzg4844.Context {
 	return gokzgCtx
 }
 
+// InitCellKZGCtx initializes the global EIP-7594 (PeerDAS) context object returned via CellCtx
+func InitCellKZGCtx() {
+	initCellCryptoCtx.Do(func() {
+		if trustedSetupFile != "" {
+			file, err := os.ReadFile(trustedSetupFile)
+			if err != nil {
+				panic(fmt.Sprintf("could not read file, err: %v", err))
+			}
+
+			setup := new(goethkzg.JSONTrustedSetup)
+			if err = json.Unmarshal(file, setup); err != nil {
+				panic(fmt.Sprintf("could not unmarshal, err: %v", err))
+			}
+
+			goethkzgCtx, err = goethkzg.NewContext4096(setup)
+			if err != nil {
+				panic(fmt.Sprintf("could not create cell KZG context, err: %v", err))
+			}
+		} else {
+			var err error
+			goethkzgCtx, err = goethkzg.NewContext4096Secure()
+			if err != nil {
+				panic(fmt.Sprintf("could not create cell KZG context, err : %v", err))
+			}
+		}
+	})
+}
+
+// CellCtx returns the context used to compute and verify EIP-7594 cell proofs. Like Ctx, it is
+// expensive to initialize, so production services should pre-initialize by calling InitCellKZGCtx.
+func CellCtx() *goethkzg.Context {
+	InitCellKZGCtx()
+	return goethkzgCtx
+}
+
+// ComputeCellProofs implements compute_cells_and_kzg_proofs from EIP-7594, returning only the
+// CellsPerExtBlob proofs: the cells themselves can always be recomputed from the blob.
+func ComputeCellProofs(blob []byte) ([]gokzg4844.KZGProof, error) {
+	if len(blob) != len(goethkzg.Blob{}) {
+		return nil, errInvalidInputLength
+	}
+	_, proofs, err := CellCtx().ComputeCellsAndKZGProofs((*goethkzg.Blob)(blob), 0)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]gokzg4844.KZGProof, len(proofs))
+	for i := range proofs {
+		res[i] = gokzg4844.KZGProof(proofs[i])
+	}
+	return res, nil
+}
+
+// VerifyCellProofs checks the CellsPerExtBlob cell proofs of every blob against its commitment,
+// see verify_cell_kzg_proof_batch from EIP-7594. cellProofs holds the proofs of all blobs in order.
+func VerifyCellProofs(blobs [][]byte, commitments []gokzg4844.KZGCommitment, cellProofs []gokzg4844.KZGProof) error {
+	if len(blobs) != len(commitments) || len(cellProofs) != len(blobs)*CellsPerExtBlob {
+		return errInvalidInputLength
+	}
+	ctx := CellCtx()
+	cellCommitments := make([]goethkzg.KZGCommitment, 0, len(cellProofs))
+	cellIndices := make([]uint64, 0, len(cellProofs))
+	cells := make([]*goethkzg.Cell, 0, len(cellProofs))
+	proofs := make([]goethkzg.KZGProof, len(cellProofs))
+	for i, blob := range blobs {
+		if len(blob) != len(goethkzg.Blob{}) {
+			return errInvalidInputLength
+		}
+		blobCells, err := ctx.ComputeCells((*goethkzg.Blob)(blob), 0)
+		if err != nil {
+			return err
+		}
+		for j := range blobCells {
+			cellCommitments = append(cellCommitments, goethkzg.KZGCommitment(commitments[i]))
+			cellIndices = append(cellIndices, uint64(j))
+			cells = append(cells, blobCells[j])
+		}
+	}
+	for i := range cellProofs {
+		proofs[i] = goethkzg.KZGProof(cellProofs[i])
+	}
+	return ctx.VerifyCellKZGProofBatch(cellCommitments, cellIndices, cells, proofs)
+}
+
 // KZGToVersionedHash implements kzg_to_versioned_hash from EIP-4844
 func KZGToVersionedHash(kzg gokzg4844.KZGCommitment) VersionedHash {
 	h := sha256.Sum256(kzg[:])
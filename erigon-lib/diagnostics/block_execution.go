@@ -73,6 +73,7 @@ func (d *DiagnosticClient) runBlockExecutionListener(rootCtx context.Context) {
 				return
 			case info := <-ch:
 				d.BlockExecution.SetData(info)
+				d.observeStage(StageObservation{Stage: executionStageID, Unit: UnitBlocks, Current: info.BlockNumber, Target: info.To, Rate: info.BlkPerSec, TxRate: info.TxPerSec})
 				if d.syncStats.SyncFinished {
 					return
 				}
@@ -45,6 +45,7 @@ func (d *DiagnosticClient) runBodiesBlockDownloadListener(rootCtx context.Contex
 				d.bodiesMutex.Lock()
 				d.bodies.BlockDownload = info
 				d.bodiesMutex.Unlock()
+				d.observeStage(StageObservation{Stage: bodiesStageID, Unit: UnitBlocks, Current: info.BlockNumber, Target: info.BlockNumber + info.Remaining})
 			}
 		}
 
@@ -55,6 +55,9 @@ type DiagnosticClient struct {
 	resourcesUsageMutex sync.Mutex
 	networkSpeed        NetworkSpeedTestResult
 	networkSpeedMutex   sync.Mutex
+	syncETA             SyncETAEstimator
+	natStatus           map[string]NATStatusUpdate // by the p2p listening address
+	natMutex            sync.Mutex
 	webseedsList        []string
 	conn                *websocket.Conn
 }
@@ -137,6 +140,8 @@ func (d *DiagnosticClient) Setup() {
 	d.setupBodiesDiagnostics(rootCtx)
 	d.setupResourcesUsageDiagnostics(rootCtx)
 	d.setupSpeedtestDiagnostics(rootCtx)
+	d.setupNATDiagnostics(rootCtx)
+	d.setupSyncETADiagnostics(rootCtx)
 
 	d.setupTxPoolDiagnostics(rootCtx)
 
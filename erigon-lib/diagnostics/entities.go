@@ -198,6 +198,7 @@ type CPUInfo struct {
 type BlockHeadersUpdate struct {
 	CurrentBlockNumber  uint64  `json:"blockNumber"`
 	PreviousBlockNumber uint64  `json:"previousBlockNumber"`
+	HighestSeenBlock    uint64  `json:"highestSeenBlock"` // highest header received from the peers
 	Speed               float64 `json:"speed"`
 	Alloc               uint64  `json:"alloc"`
 	Sys                 uint64  `json:"sys"`
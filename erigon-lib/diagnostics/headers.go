@@ -74,8 +74,7 @@ func (d *DiagnosticClient) runWriteHeadersListener(rootCtx context.Context) {
 				d.headerMutex.Lock()
 				d.headers.WriteHeaders = info
 				d.headerMutex.Unlock()
-
-				return
+				d.observeStage(StageObservation{Stage: headersStageID, Unit: UnitBlocks, Current: info.CurrentBlockNumber, Target: info.HighestSeenBlock, Rate: info.Speed})
 			}
 		}
 	}()
@@ -0,0 +1,112 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+type Reachability string
+
+const (
+	ReachabilityUnknown     Reachability = "unknown"     // not enough data yet
+	ReachabilityReachable   Reachability = "reachable"   // peers can connect to the node
+	ReachabilityUnreachable Reachability = "unreachable" // no peer connected from outside and the node couldn't connect to its external address
+)
+
+// NATMappingStatus is a port mapping kept on the router.
+type NATMappingStatus struct {
+	Protocol     string    `json:"protocol"`
+	Name         string    `json:"name"`
+	InternalPort int       `json:"internalPort"`
+	ExternalPort int       `json:"externalPort"` // 0 if not mapped
+	Renewed      time.Time `json:"renewed"`
+	Expires      time.Time `json:"expires"`
+	Failures     int       `json:"failures"` // consecutive failed renewals
+	Error        string    `json:"error,omitempty"`
+}
+
+// ReachabilityCheck is a result of an inbound reachability test.
+type ReachabilityCheck struct {
+	Method    string    `json:"method"` // "dial" - connect to own external address, "inbound" - peers connected from outside
+	Time      time.Time `json:"time"`
+	Reachable bool      `json:"reachable"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// NATStatusUpdate is the NAT traversal state of a p2p server.
+type NATStatusUpdate struct {
+	ListenAddr   string              `json:"listenAddr"`
+	Mechanism    string              `json:"mechanism"`
+	ExternalIP   string              `json:"externalIP"`
+	ENRSeq       uint64              `json:"enrSeq"`
+	Mappings     []NATMappingStatus  `json:"mappings"`
+	Reachability Reachability        `json:"reachability"`
+	Checks       []ReachabilityCheck `json:"checks"`
+	LastInbound  time.Time           `json:"lastInbound"` // last peer connected from a public address
+	Updated      time.Time           `json:"updated"`
+}
+
+func (ti NATStatusUpdate) Type() Type {
+	return TypeOf(ti)
+}
+
+func (d *DiagnosticClient) setupNATDiagnostics(rootCtx context.Context) {
+	go func() {
+		ctx, ch, closeChannel := Context[NATStatusUpdate](rootCtx, 1)
+		defer closeChannel()
+
+		StartProviders(ctx, TypeOf(NATStatusUpdate{}), log.Root())
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case info := <-ch:
+				d.natMutex.Lock()
+				if d.natStatus == nil {
+					d.natStatus = map[string]NATStatusUpdate{}
+				}
+				d.natStatus[info.ListenAddr] = info
+				d.natMutex.Unlock()
+			}
+		}
+	}()
+}
+
+// NATStatus returns the NAT traversal state of the p2p servers, one per listening address.
+func (d *DiagnosticClient) NATStatus() []NATStatusUpdate {
+	d.natMutex.Lock()
+	defer d.natMutex.Unlock()
+	status := make([]NATStatusUpdate, 0, len(d.natStatus))
+	for _, s := range d.natStatus {
+		status = append(status, s)
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].ListenAddr < status[j].ListenAddr })
+	return status
+}
+
+func (d *DiagnosticClient) NATStatusJson(w io.Writer) {
+	if err := json.NewEncoder(w).Encode(d.NATStatus()); err != nil {
+		log.Debug("[diagnostics] NATStatusJson", "err", err)
+	}
+}
@@ -34,6 +34,7 @@ func (d *DiagnosticClient) runSnapshotListener(rootCtx context.Context) {
 				return
 			case info := <-ch:
 				d.SetSnapshotDownloadInfo(info)
+				d.observeStage(StageObservation{Stage: snapshotsStageID, Unit: UnitBytes, Current: info.Downloaded, Target: info.Total, Rate: float64(info.DownloadRate)})
 				d.UpdateSnapshotStageStats(CalculateSyncStageStats(info), "Downloading snapshots")
 
 				if info.DownloadFinished {
@@ -0,0 +1,428 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+// ids of the sync stages fed by the diagnostics updates, see eth/stagedsync/stages
+const (
+	snapshotsStageID = "OtterSync"
+	headersStageID   = "Headers"
+	bodiesStageID    = "Bodies"
+	executionStageID = "Execution"
+)
+
+// units of the stage progress
+const (
+	UnitBlocks = "blocks"
+	UnitBytes  = "bytes"
+)
+
+const (
+	etaRateSmoothing       = 0.3 // weight of the latest rate in the moving average
+	resourcesSampleEvery   = 10 * time.Second
+	resourcesSamplesWindow = 6 // samples averaged to classify the bottleneck
+
+	bottleneckDiskBusyPercent = 80.0
+	bottleneckIOWaitPercent   = 20.0
+	bottleneckCPUPercent      = 85.0
+	bottleneckMinActivePeers  = 3
+)
+
+// stages waiting for the data from the network
+var networkStages = map[string]bool{
+	snapshotsStageID: true,
+	"Snapshots":      true,
+	headersStageID:   true,
+	bodiesStageID:    true,
+	"BorHeimdall":    true,
+	"PolygonSync":    true,
+	"BeaconBlocks":   true,
+}
+
+type Bottleneck string
+
+const (
+	BottleneckUnknown Bottleneck = "unknown"
+	BottleneckDisk    Bottleneck = "disk"
+	BottleneckCPU     Bottleneck = "cpu"
+	BottleneckNetwork Bottleneck = "network"
+	BottleneckPeers   Bottleneck = "peers"
+)
+
+// StageObservation is the progress of a stage at a point in time.
+type StageObservation struct {
+	Stage   string
+	Unit    string
+	Time    time.Time
+	Current uint64
+	Target  uint64  // 0 if not known
+	Rate    float64 // units per second reported by the stage, 0 to learn it from the progress
+	TxRate  float64 // transactions per second, if the stage processes them
+}
+
+// ResourcesSample is the node resources usage since the previous sample.
+type ResourcesSample struct {
+	Time            time.Time `json:"time"`
+	CPUPercent      float64   `json:"cpuPercent"`
+	IOWaitPercent   float64   `json:"ioWaitPercent"`
+	DiskBusyPercent float64   `json:"diskBusyPercent"` // of the datadir disk
+	NetInRate       uint64    `json:"netInRate"`       // bytes per second received from the peers and downloaded
+	ActivePeers     int       `json:"activePeers"`     // peers which sent data, including the torrent peers
+}
+
+type StageETA struct {
+	Stage     string  `json:"stage"`
+	State     string  `json:"state"`
+	Unit      string  `json:"unit,omitempty"`
+	Current   uint64  `json:"current"`
+	Target    uint64  `json:"target"`
+	Rate      float64 `json:"rate"` // units per second
+	TxsPerSec float64 `json:"txsPerSec,omitempty"`
+	ETA       uint64  `json:"eta"` // seconds
+	Estimated bool    `json:"estimated"`
+}
+
+type SyncETA struct {
+	Stages           []StageETA      `json:"stages"`
+	ETA              uint64          `json:"eta"`       // seconds until all the stages are completed
+	Estimated        bool            `json:"estimated"` // false if some of the remaining observed stages have no estimate
+	Bottleneck       Bottleneck      `json:"bottleneck"`
+	BottleneckReason string          `json:"bottleneckReason"`
+	Resources        ResourcesSample `json:"resources"` // averaged over the recent samples
+}
+
+type stageThroughput struct {
+	unit    string
+	current uint64
+	target  uint64
+	rate    float64
+	txRate  float64
+	updated time.Time
+}
+
+// SyncETAEstimator learns the throughput of the sync stages and estimates the time left.
+// The zero value is ready to use.
+type SyncETAEstimator struct {
+	mu      sync.Mutex
+	stages  map[string]*stageThroughput
+	samples []ResourcesSample
+}
+
+// Observe records the stage progress, the throughput is a moving average of the progress rate.
+func (e *SyncETAEstimator) Observe(o StageObservation) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stages == nil {
+		e.stages = map[string]*stageThroughput{}
+	}
+
+	t, ok := e.stages[o.Stage]
+	if !ok || t.unit != o.Unit {
+		t = &stageThroughput{unit: o.Unit, current: o.Current}
+		e.stages[o.Stage] = t
+	}
+
+	rate := o.Rate
+	if rate == 0 && ok && o.Current > t.current && o.Time.After(t.updated) {
+		rate = float64(o.Current-t.current) / o.Time.Sub(t.updated).Seconds()
+	}
+	if rate > 0 {
+		if t.rate == 0 {
+			t.rate = rate
+		} else {
+			t.rate = etaRateSmoothing*rate + (1-etaRateSmoothing)*t.rate
+		}
+	}
+	if o.TxRate > 0 {
+		if t.txRate == 0 {
+			t.txRate = o.TxRate
+		} else {
+			t.txRate = etaRateSmoothing*o.TxRate + (1-etaRateSmoothing)*t.txRate
+		}
+	}
+
+	t.current = o.Current
+	if o.Target != 0 || t.target < o.Current {
+		t.target = o.Target
+	}
+	t.updated = o.Time
+}
+
+// AddResourcesSample records the resources usage, the recent samples are used to find the bottleneck.
+func (e *SyncETAEstimator) AddResourcesSample(s ResourcesSample) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.samples = append(e.samples, s)
+	if len(e.samples) > resourcesSamplesWindow {
+		e.samples = e.samples[len(e.samples)-resourcesSamplesWindow:]
+	}
+}
+
+// Estimate returns the time left per stage and overall. Stages which are not in the list,
+// e.g. when the stage list is not known yet, are estimated in the name order. Only some of
+// the stages report their progress, the others are left out of the overall estimate.
+func (e *SyncETAEstimator) Estimate(syncStages []SyncStage) SyncETA {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(syncStages) == 0 {
+		ids := make([]string, 0, len(e.stages))
+		for id := range e.stages {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			syncStages = append(syncStages, SyncStage{ID: id, State: Running})
+		}
+	}
+
+	res := SyncETA{Stages: make([]StageETA, 0, len(syncStages)), Estimated: true}
+	running := ""
+	remaining, observed := false, false
+	for _, stage := range syncStages {
+		eta := StageETA{Stage: stage.ID, State: stage.State.String()}
+		t, ok := e.stages[stage.ID]
+		if ok {
+			eta.Unit = t.unit
+			eta.Current = t.current
+			eta.Target = t.target
+			eta.Rate = t.rate
+			eta.TxsPerSec = t.txRate
+		}
+
+		switch {
+		case stage.State == Completed:
+			eta.Estimated = true
+		case stage.State == Running && eta.Target != 0 && eta.Current >= eta.Target:
+			eta.Estimated = true
+		case eta.Rate > 0 && eta.Target > eta.Current:
+			eta.ETA = uint64(float64(eta.Target-eta.Current) / eta.Rate)
+			eta.Estimated = true
+		}
+		if stage.State == Running && running == "" {
+			running = stage.ID
+		}
+
+		res.ETA += eta.ETA
+		if stage.State != Completed {
+			remaining = true
+			if ok {
+				observed = true
+				res.Estimated = res.Estimated && eta.Estimated
+			}
+		}
+		res.Stages = append(res.Stages, eta)
+	}
+	// nothing to estimate from, unless the sync is done
+	if remaining && !observed {
+		res.Estimated = false
+	}
+
+	res.Resources = averageResources(e.samples)
+	res.Bottleneck, res.BottleneckReason = ClassifyBottleneck(running, res.Resources)
+	return res
+}
+
+func averageResources(samples []ResourcesSample) ResourcesSample {
+	if len(samples) == 0 {
+		return ResourcesSample{}
+	}
+	var avg ResourcesSample
+	var netIn uint64
+	var peers int
+	for _, s := range samples {
+		avg.CPUPercent += s.CPUPercent
+		avg.IOWaitPercent += s.IOWaitPercent
+		avg.DiskBusyPercent += s.DiskBusyPercent
+		netIn += s.NetInRate
+		peers += s.ActivePeers
+	}
+	n := len(samples)
+	avg.Time = samples[n-1].Time
+	avg.CPUPercent /= float64(n)
+	avg.IOWaitPercent /= float64(n)
+	avg.DiskBusyPercent /= float64(n)
+	avg.NetInRate = netIn / uint64(n)
+	avg.ActivePeers = peers / n
+	return avg
+}
+
+// ClassifyBottleneck finds the resource limiting the stage. Stages waiting for the network are limited
+// by the peers if there are too few of them, then the saturated disk or CPU is the bottleneck.
+func ClassifyBottleneck(stage string, s ResourcesSample) (Bottleneck, string) {
+	if s.Time.IsZero() {
+		return BottleneckUnknown, "no resources usage samples yet"
+	}
+	network := networkStages[stage]
+	switch {
+	case network && s.ActivePeers < bottleneckMinActivePeers:
+		return BottleneckPeers, fmt.Sprintf("%s stage has %d active peers", stage, s.ActivePeers)
+	case s.DiskBusyPercent >= bottleneckDiskBusyPercent || s.IOWaitPercent >= bottleneckIOWaitPercent:
+		return BottleneckDisk, fmt.Sprintf("disk busy %.0f%%, iowait %.0f%%", s.DiskBusyPercent, s.IOWaitPercent)
+	case s.CPUPercent >= bottleneckCPUPercent:
+		return BottleneckCPU, fmt.Sprintf("cpu %.0f%%", s.CPUPercent)
+	case network:
+		return BottleneckNetwork, fmt.Sprintf("%s stage downloads %s/s from %d peers, disk and cpu are not saturated", stage, common.ByteCount(s.NetInRate), s.ActivePeers)
+	case stage == "":
+		return BottleneckUnknown, "no running stage"
+	default:
+		return BottleneckUnknown, fmt.Sprintf("%s stage doesn't saturate disk, cpu or network", stage)
+	}
+}
+
+func (d *DiagnosticClient) setupSyncETADiagnostics(rootCtx context.Context) {
+	d.runResourcesSampler(rootCtx)
+}
+
+func (d *DiagnosticClient) observeStage(o StageObservation) {
+	if o.Time.IsZero() {
+		o.Time = time.Now()
+	}
+	d.syncETA.Observe(o)
+}
+
+// SyncETA returns the time left for the sync and its current bottleneck.
+func (d *DiagnosticClient) SyncETA() SyncETA {
+	d.mu.Lock()
+	syncStages := make([]SyncStage, len(d.syncStages))
+	copy(syncStages, d.syncStages)
+	d.mu.Unlock()
+	return d.syncETA.Estimate(syncStages)
+}
+
+func (d *DiagnosticClient) SyncETAJson(w io.Writer) {
+	if err := json.NewEncoder(w).Encode(d.SyncETA()); err != nil {
+		log.Debug("[diagnostics] SyncETAJson", "err", err)
+	}
+}
+
+func (d *DiagnosticClient) runResourcesSampler(rootCtx context.Context) {
+	go func() {
+		ticker := time.NewTicker(resourcesSampleEvery)
+		defer ticker.Stop()
+
+		var sampler resourcesSampler
+		sampler.sample(d) // baseline of the counters
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case <-ticker.C:
+				if s, ok := sampler.sample(d); ok {
+					d.syncETA.AddResourcesSample(s)
+				}
+			}
+		}
+	}()
+}
+
+// resourcesSampler turns the cumulative counters into the usage since the previous sample.
+type resourcesSampler struct {
+	prevTime    time.Time
+	prevCPU     cpu.TimesStat
+	prevDisks   map[string]disk.IOCountersStat
+	prevPeersIn map[string]uint64
+}
+
+func (r *resourcesSampler) sample(d *DiagnosticClient) (ResourcesSample, bool) {
+	now := time.Now()
+	s := ResourcesSample{Time: now}
+	elapsed := now.Sub(r.prevTime)
+	hasPrev := !r.prevTime.IsZero() && elapsed > 0
+	r.prevTime = now
+
+	if times, err := cpu.Times(false); err == nil && len(times) > 0 {
+		cur := times[0]
+		if hasPrev {
+			total := cpuTotal(cur) - cpuTotal(r.prevCPU)
+			if total > 0 {
+				s.IOWaitPercent = 100 * (cur.Iowait - r.prevCPU.Iowait) / total
+				s.CPUPercent = 100 * (total - (cur.Idle - r.prevCPU.Idle) - (cur.Iowait - r.prevCPU.Iowait)) / total
+			}
+		}
+		r.prevCPU = cur
+	}
+
+	if counters, err := disk.IOCounters(); err == nil {
+		if hasPrev {
+			d.mu.Lock()
+			device := filepath.Base(d.hardwareInfo.Disk.Device)
+			d.mu.Unlock()
+			busy := func(name string) float64 {
+				prev, ok := r.prevDisks[name]
+				if !ok {
+					return 0
+				}
+				return min(100, 100*float64(counters[name].IoTime-prev.IoTime)/float64(elapsed.Milliseconds()))
+			}
+			if _, ok := counters[device]; ok {
+				s.DiskBusyPercent = busy(device)
+			} else {
+				// the datadir disk is not known, the busiest one is the best guess
+				for name := range counters {
+					s.DiskBusyPercent = max(s.DiskBusyPercent, busy(name))
+				}
+			}
+		}
+		r.prevDisks = counters
+	}
+
+	var netIn uint64
+	peersIn := map[string]uint64{}
+	if d.peersStats != nil {
+		for id, peer := range d.peersStats.GetPeers() {
+			peersIn[id] = peer.BytesIn
+			if prev, ok := r.prevPeersIn[id]; ok && peer.BytesIn > prev {
+				netIn += peer.BytesIn - prev
+				s.ActivePeers++
+			}
+		}
+	}
+	r.prevPeersIn = peersIn
+	if hasPrev {
+		s.NetInRate = uint64(float64(netIn) / elapsed.Seconds())
+	}
+
+	d.mu.Lock()
+	if download := d.syncStats.SnapshotDownload; !download.DownloadFinished {
+		s.NetInRate += download.DownloadRate
+		s.ActivePeers += int(download.Peers)
+	}
+	d.mu.Unlock()
+
+	return s, hasPrev
+}
+
+func cpuTotal(t cpu.TimesStat) float64 {
+	return t.User + t.System + t.Idle + t.Nice + t.Iowait + t.Irq + t.Softirq + t.Steal
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/diagnostics"
+)
+
+func TestSyncETAEstimate(t *testing.T) {
+	var e diagnostics.SyncETAEstimator
+	start := time.Now()
+
+	// rate is learned from the progress
+	e.Observe(diagnostics.StageObservation{Stage: "Bodies", Unit: diagnostics.UnitBlocks, Time: start, Current: 1000, Target: 3000})
+	e.Observe(diagnostics.StageObservation{Stage: "Bodies", Unit: diagnostics.UnitBlocks, Time: start.Add(10 * time.Second), Current: 2000, Target: 3000})
+	// rate is reported by the stage
+	e.Observe(diagnostics.StageObservation{Stage: "Execution", Unit: diagnostics.UnitBlocks, Time: start, Current: 500, Target: 3000, Rate: 50, TxRate: 1000})
+
+	stages := diagnostics.InitStagesFromList([]string{"OtterSync", "Bodies", "Senders", "Execution"})
+	stages[0].State = diagnostics.Completed
+	stages[1].State = diagnostics.Running
+
+	eta := e.Estimate(stages)
+	require.Len(t, eta.Stages, 4)
+
+	require.True(t, eta.Stages[0].Estimated)
+	require.Zero(t, eta.Stages[0].ETA)
+
+	require.Equal(t, 100.0, eta.Stages[1].Rate)
+	require.Equal(t, uint64(10), eta.Stages[1].ETA)
+	require.True(t, eta.Stages[1].Estimated)
+
+	// no progress updates for the stage
+	require.False(t, eta.Stages[2].Estimated)
+
+	require.Equal(t, uint64(50), eta.Stages[3].ETA)
+	require.Equal(t, 1000.0, eta.Stages[3].TxsPerSec)
+
+	// stages without progress updates are left out of the overall estimate
+	require.Equal(t, uint64(60), eta.ETA)
+	require.True(t, eta.Estimated)
+	require.Equal(t, diagnostics.BottleneckUnknown, eta.Bottleneck)
+
+	// moving average of the rate
+	e.Observe(diagnostics.StageObservation{Stage: "Bodies", Unit: diagnostics.UnitBlocks, Time: start.Add(20 * time.Second), Current: 2200, Target: 3000})
+	eta = e.Estimate(stages)
+	require.InDelta(t, 76.0, eta.Stages[1].Rate, 0.001)
+
+	// unwind doesn't produce a rate
+	e.Observe(diagnostics.StageObservation{Stage: "Bodies", Unit: diagnostics.UnitBlocks, Time: start.Add(30 * time.Second), Current: 2100, Target: 3000})
+	eta = e.Estimate(stages)
+	require.InDelta(t, 76.0, eta.Stages[1].Rate, 0.001)
+	require.Equal(t, uint64(2100), eta.Stages[1].Current)
+
+	// observed stage without a rate yet
+	e.Observe(diagnostics.StageObservation{Stage: "Senders", Unit: diagnostics.UnitBlocks, Time: start, Current: 100, Target: 3000})
+	eta = e.Estimate(stages)
+	require.False(t, eta.Stages[2].Estimated)
+	require.False(t, eta.Estimated)
+
+	// nothing observed
+	eta = (&diagnostics.SyncETAEstimator{}).Estimate(stages)
+	require.False(t, eta.Estimated)
+}
+
+func TestSyncETAHeadersTarget(t *testing.T) {
+	var e diagnostics.SyncETAEstimator
+	start := time.Now()
+	e.Observe(diagnostics.StageObservation{Stage: "Headers", Unit: diagnostics.UnitBlocks, Time: start, Current: 1000, Target: 5000, Rate: 200})
+
+	stages := diagnostics.InitStagesFromList([]string{"Headers"})
+	stages[0].State = diagnostics.Running
+	eta := e.Estimate(stages)
+	require.Equal(t, uint64(5000), eta.Stages[0].Target)
+	require.Equal(t, uint64(20), eta.ETA)
+	require.True(t, eta.Estimated)
+}
+
+func TestSyncETABottleneck(t *testing.T) {
+	var e diagnostics.SyncETAEstimator
+	stages := diagnostics.InitStagesFromList([]string{"Headers", "Execution"})
+	stages[0].State = diagnostics.Running
+
+	now := time.Now()
+	e.AddResourcesSample(diagnostics.ResourcesSample{Time: now, CPUPercent: 20, ActivePeers: 1})
+	e.AddResourcesSample(diagnostics.ResourcesSample{Time: now, CPUPercent: 30, ActivePeers: 2})
+	eta := e.Estimate(stages)
+	require.Equal(t, diagnostics.BottleneckPeers, eta.Bottleneck)
+	require.Equal(t, 25.0, eta.Resources.CPUPercent)
+	require.Equal(t, 1, eta.Resources.ActivePeers)
+
+	tests := []struct {
+		stage  string
+		sample diagnostics.ResourcesSample
+		want   diagnostics.Bottleneck
+	}{
+		{"Headers", diagnostics.ResourcesSample{ActivePeers: 10, NetInRate: 1 << 20}, diagnostics.BottleneckNetwork},
+		{"Headers", diagnostics.ResourcesSample{ActivePeers: 10, DiskBusyPercent: 95}, diagnostics.BottleneckDisk},
+		{"Execution", diagnostics.ResourcesSample{IOWaitPercent: 30}, diagnostics.BottleneckDisk},
+		{"Execution", diagnostics.ResourcesSample{CPUPercent: 90, DiskBusyPercent: 40}, diagnostics.BottleneckCPU},
+		{"Execution", diagnostics.ResourcesSample{CPUPercent: 10}, diagnostics.BottleneckUnknown},
+	}
+	for _, tt := range tests {
+		tt.sample.Time = now
+		got, reason := diagnostics.ClassifyBottleneck(tt.stage, tt.sample)
+		require.Equal(t, tt.want, got, reason)
+		require.NotEmpty(t, reason)
+	}
+}
@@ -111,10 +111,12 @@ func (s *GrpcServer) Delete(ctx context.Context, request *proto_downloader.Delet
 			return nil, errors.New("field 'path' is required")
 		}
 		for _, t := range torrents {
-			select {
-			case <-t.GotInfo():
-				continue
-			default:
+			if !request.IncludeSeeded {
+				select {
+				case <-t.GotInfo():
+					continue
+				default:
+				}
 			}
 			if t.Name() == name {
 				t.Drop()
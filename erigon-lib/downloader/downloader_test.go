@@ -18,11 +18,13 @@ package downloader
 
 import (
 	"context"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
 
 	lg "github.com/anacrolix/log"
+	"github.com/anacrolix/torrent/metainfo"
 	"github.com/stretchr/testify/require"
 
 	"github.com/erigontech/erigon-lib/common/datadir"
@@ -88,6 +90,40 @@ func TestNoEscape(t *testing.T) {
 	require.Error(err)
 }
 
+func TestFileInfoHash(t *testing.T) {
+	require := require.New(t)
+	dirs := datadir.New(t.TempDir())
+	ctx := context.Background()
+
+	fName := filepath.Join("domain", "v1-accounts.0-1.kv")
+	fPath := filepath.Join(dirs.Snap, fName)
+	require.NoError(os.MkdirAll(filepath.Dir(fPath), 0755))
+	require.NoError(os.WriteFile(fPath, []byte("some data"), 0644))
+
+	tf := NewAtomicTorrentFS(dirs.Snap)
+	created, err := BuildTorrentIfNeed(ctx, fName, dirs.Snap, tf)
+	require.NoError(err)
+	require.True(created)
+	mi, err := metainfo.LoadFromFile(fPath + ".torrent")
+	require.NoError(err)
+
+	// same hash as the .torrent created by BuildTorrentIfNeed, for relative and absolute paths
+	h, err := FileInfoHash(fName, dirs.Snap)
+	require.NoError(err)
+	require.Equal(mi.HashInfoBytes(), h)
+	h, err = FileInfoHash(fPath, dirs.Snap)
+	require.NoError(err)
+	require.Equal(mi.HashInfoBytes(), h)
+
+	require.NoError(os.WriteFile(fPath, []byte("some dada"), 0644))
+	h, err = FileInfoHash(fName, dirs.Snap)
+	require.NoError(err)
+	require.NotEqual(mi.HashInfoBytes(), h)
+
+	_, err = FileInfoHash("./../a.seg", dirs.Snap)
+	require.Error(err)
+}
+
 func TestVerifyData(t *testing.T) {
 	require := require.New(t)
 	dirs := datadir.New(t.TempDir())
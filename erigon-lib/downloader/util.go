@@ -181,6 +181,25 @@ func BuildTorrentIfNeed(ctx context.Context, fName, root string, torrentFiles *A
 	return torrentFiles.CreateWithMetaInfo(info, nil)
 }
 
+// FileInfoHash - computes infohash of file in snapshots dir the same way BuildTorrentIfNeed does.
+// Reads whole file (big IO). Result can be compared with preverified hashes or with existing .torrent file
+func FileInfoHash(fName, root string) (metainfo.Hash, error) {
+	fName, err := ensureCantLeaveDir(fName, root)
+	if err != nil {
+		return metainfo.Hash{}, err
+	}
+	info := &metainfo.Info{PieceLength: downloadercfg.DefaultPieceSize, Name: fName}
+	if err := info.BuildFromFilePath(filepath.Join(root, fName)); err != nil {
+		return metainfo.Hash{}, fmt.Errorf("FileInfoHash: %w", err)
+	}
+	info.Name = fName
+	infoBytes, err := bencode.Marshal(info)
+	if err != nil {
+		return metainfo.Hash{}, err
+	}
+	return metainfo.HashBytes(infoBytes), nil
+}
+
 // BuildTorrentFilesIfNeed - create .torrent files from .seg files (big IO) - if .seg files were added manually
 func BuildTorrentFilesIfNeed(ctx context.Context, dirs datadir.Dirs, torrentFiles *AtomicTorrentFS, chain string, ignore snapcfg.Preverified, all bool) (int, error) {
 	logEvery := time.NewTicker(20 * time.Second)
@@ -753,4 +753,32 @@ func (m *MemoryMutation) HistoryStartFrom(name kv.Domain) uint64 {
 func (m *MemoryMutation) FreezeInfo() kv.FreezeInfo {
 	panic("not supported")
 }
-func (m *MemoryMutation) Debug() kv.TemporalDebugTx { return m.db.(kv.TemporalTx).Debug() }
+func (m *MemoryMutation) Debug() kv.TemporalDebugTx {
+	if aggTx, ok := m.AggTx().(aggDebugTx); ok {
+		return &memoryMutationDebugTx{m: m, aggTx: aggTx}
+	}
+	return m.db.(kv.TemporalTx).Debug()
+}
+
+type aggDebugTx interface {
+	DebugRangeLatest(tx kv.Tx, domain kv.Domain, from, to []byte, limit int) (stream.KV, error)
+	DebugGetLatestFromDB(domain kv.Domain, key []byte, tx kv.Tx) ([]byte, uint64, bool, error)
+	DebugGetLatestFromFiles(domain kv.Domain, k []byte, maxTxNum uint64) (v []byte, found bool, fileStartTxNum uint64, fileEndTxNum uint64, err error)
+}
+
+// memoryMutationDebugTx reads the domain values in db through the batch, so the
+// values written or unwound in the batch are seen.
+type memoryMutationDebugTx struct {
+	m     *MemoryMutation
+	aggTx aggDebugTx
+}
+
+func (d *memoryMutationDebugTx) RangeLatest(domain kv.Domain, from, to []byte, limit int) (stream.KV, error) {
+	return d.aggTx.DebugRangeLatest(d.m, domain, from, to, limit)
+}
+func (d *memoryMutationDebugTx) GetLatestFromDB(domain kv.Domain, k []byte) (v []byte, step uint64, found bool, err error) {
+	return d.aggTx.DebugGetLatestFromDB(domain, k, d.m)
+}
+func (d *memoryMutationDebugTx) GetLatestFromFiles(domain kv.Domain, k []byte, maxTxNum uint64) (v []byte, found bool, fileStartTxNum uint64, fileEndTxNum uint64, err error) {
+	return d.aggTx.DebugGetLatestFromFiles(domain, k, maxTxNum)
+}
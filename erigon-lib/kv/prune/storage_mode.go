@@ -32,6 +32,7 @@ var DefaultMode = Mode{
 	Initialised: true,
 	History:     Distance(math.MaxUint64),
 	Blocks:      Distance(math.MaxUint64),
+	Expiry:      Distance(math.MaxUint64),
 	Experiments: Experiments{}, // all off
 }
 
@@ -75,6 +76,14 @@ func Get(db kv.Getter) (Mode, error) {
 		prune.Blocks = blockAmount
 	}
 
+	blockAmount, err = get(db, kv.PruneExpiry)
+	if err != nil {
+		return prune, err
+	}
+	if blockAmount != nil {
+		prune.Expiry = blockAmount
+	}
+
 	return prune, nil
 }
 
@@ -82,6 +91,9 @@ type Mode struct {
 	Initialised bool // Set when the values are initialised (not default)
 	History     BlockAmount
 	Blocks      BlockAmount
+	// Expiry - EIP-4444 style history expiry: block bodies and transactions segments older than
+	// this distance are deleted and no longer downloaded or seeded, only headers are kept
+	Expiry      BlockAmount
 	Experiments Experiments
 }
 
@@ -135,6 +147,9 @@ func (m Mode) String() string {
 			long += fmt.Sprintf(" --prune.b.%s=%d", m.Blocks.dbType(), m.Blocks.toValue())
 		}
 	}
+	if m.Expiry != nil && m.Expiry.Enabled() {
+		long += fmt.Sprintf(" --prune.expiry=%d", m.Expiry.toValue())
+	}
 
 	return strings.TrimLeft(short+long, " ")
 }
@@ -154,6 +169,13 @@ func Override(db kv.RwTx, sm Mode) error {
 		return err
 	}
 
+	if sm.Expiry != nil {
+		err = set(db, kv.PruneExpiry, sm.Expiry)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -187,9 +209,14 @@ func setIfNotExist(db kv.GetPut, pm Mode) error {
 		pm = DefaultMode
 	}
 
+	expiry := pm.Expiry
+	if expiry == nil {
+		expiry = DefaultMode.Expiry
+	}
 	pruneDBData := map[string]BlockAmount{
 		string(kv.PruneHistory): pm.History,
 		string(kv.PruneBlocks):  pm.Blocks,
+		string(kv.PruneExpiry):  expiry,
 	}
 
 	for key, value := range pruneDBData {
@@ -31,14 +31,15 @@ func TestSetStorageModeIfNotExist(t *testing.T) {
 	_, tx := memdb.NewTestTx(t)
 	prune, err := Get(tx)
 	require.NoError(t, err)
-	assert.Equal(t, Mode{true, Distance(math.MaxUint64), Distance(math.MaxUint64), Experiments{}}, prune)
+	assert.Equal(t, Mode{true, Distance(math.MaxUint64), Distance(math.MaxUint64), Distance(math.MaxUint64), Experiments{}}, prune)
 
-	err = setIfNotExist(tx, Mode{true, Distance(1), Distance(2), Experiments{}})
+	err = setIfNotExist(tx, Mode{true, Distance(1), Distance(2), Distance(3), Experiments{}})
 	require.NoError(t, err)
 
 	prune, err = Get(tx)
 	require.NoError(t, err)
-	assert.Equal(t, Mode{true, Distance(1), Distance(2), Experiments{}}, prune)
+	assert.Equal(t, Mode{true, Distance(1), Distance(2), Distance(3), Experiments{}}, prune)
+	assert.Equal(t, "--prune.h.older=1 --prune.b.older=2 --prune.expiry=3", prune.String())
 }
 
 var distanceTests = []struct {
@@ -318,6 +318,10 @@ var (
 	PruneTypeOlder = []byte("older")
 	PruneHistory   = []byte("pruneHistory")
 	PruneBlocks    = []byte("pruneBlocks")
+	PruneExpiry    = []byte("pruneExpiry")
+
+	// HistoryExpiredTo - first block whose body is still available after history expiry
+	HistoryExpiredTo = []byte("historyExpiredTo")
 
 	DBSchemaVersionKey = []byte("dbVersion")
 	GenesisKey         = []byte("genesis")
@@ -478,12 +482,14 @@ const (
 	RecentLocalTransaction = "RecentLocalTransaction" // sequence_u64 -> tx_hash
 	PoolTransaction        = "PoolTransaction"        // txHash -> sender+tx_rlp
 	PoolInfo               = "PoolInfo"               // option_key -> option_value
+	PoolBlobCellProofs     = "PoolBlobCellProofs"     // txHash -> EIP-7594 cell proofs computed at admission (48 bytes each)
 )
 
 var TxPoolTables = []string{
 	RecentLocalTransaction,
 	PoolTransaction,
 	PoolInfo,
+	PoolBlobCellProofs,
 }
 var SentryTables = []string{
 	Inodes,
@@ -1542,6 +1542,55 @@ func TestDomain_GetAfterAggregation(t *testing.T) {
 	}
 }
 
+func TestDomain_IntegrityAccessors(t *testing.T) {
+	db, d := testDbAndDomainOfStep(t, 25, log.New())
+	require := require.New(t)
+	ctx := context.Background()
+
+	tx, err := db.BeginRw(ctx)
+	require.NoError(err)
+	defer tx.Rollback()
+
+	dc := d.BeginFilesRo()
+	defer d.Close()
+	writer := dc.NewWriter()
+	defer writer.Close()
+
+	totalTx := uint64(1000)
+	data := generateTestData(t, length.Addr, length.Addr+length.Hash, totalTx, 50, 100)
+	for key, updates := range data {
+		for i := range updates {
+			writer.SetTxNum(updates[i].txNum)
+			writer.PutWithPrev([]byte(key), nil, updates[i].value, nil, 0)
+		}
+	}
+	writer.SetTxNum(totalTx)
+	require.NoError(writer.Flush(ctx, tx))
+	collateAndMerge(t, db, tx, d, totalTx)
+	require.NoError(tx.Commit())
+	dc.Close()
+
+	dc = d.BeginFilesRo()
+	defer dc.Close()
+	require.NotEmpty(dc.files)
+
+	broken, err := dc.IntegrityAccessors(ctx, 1)
+	require.NoError(err)
+	require.Empty(broken)
+	broken, err = dc.IntegrityAccessors(ctx, 7)
+	require.NoError(err)
+	require.Empty(broken)
+
+	// accessor which can't find keys of its .kv file
+	item := dc.files[0].src
+	bindex := item.bindex
+	item.bindex = nil
+	broken, err = dc.IntegrityAccessors(ctx, 1)
+	item.bindex = bindex
+	require.NoError(err)
+	require.Contains(broken, d.kvBtFilePath(item.startTxNum/d.aggregationStep, item.endTxNum/d.aggregationStep))
+}
+
 func TestDomainRange(t *testing.T) {
 	db, d := testDbAndDomainOfStep(t, 25, log.New())
 	require, ctx := require.New(t), context.Background()
@@ -14,6 +14,7 @@ import (
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/recsplit"
 	"github.com/erigontech/erigon-lib/recsplit/eliasfano32"
+	"github.com/erigontech/erigon-lib/seg"
 )
 
 // search key in all files of all domains and print file names
@@ -192,3 +193,93 @@ func (iit *InvertedIndexRoTx) IntegrityInvertedIndexAllValuesAreInRange(ctx cont
 	}
 	return nil
 }
+
+// IntegrityDomainAccessors - checks that keys of domain's .kv files are findable by their accessors.
+// Returns paths of accessor files which are broken (and must be re-built or re-downloaded)
+func (at *AggregatorRoTx) IntegrityDomainAccessors(ctx context.Context, domain kv.Domain, sampleEvery uint64) ([]string, error) {
+	return at.d[domain].IntegrityAccessors(ctx, sampleEvery)
+}
+
+// IntegrityAccessors - reads every `sampleEvery`-th key of each visible .kv file and checks that
+// existence filter contains it and btree/hashmap index returns same value
+func (dt *DomainRoTx) IntegrityAccessors(ctx context.Context, sampleEvery uint64) (broken []string, err error) {
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	if sampleEvery == 0 {
+		sampleEvery = 1
+	}
+
+	for i := range dt.files {
+		item := dt.files[i].src
+		if item.decompressor == nil {
+			continue
+		}
+		ok, err := dt.integrityAccessorsOfFile(ctx, i, sampleEvery, logEvery)
+		if err != nil {
+			return broken, err
+		}
+		if ok {
+			continue
+		}
+		fromStep, toStep := item.startTxNum/dt.d.aggregationStep, item.endTxNum/dt.d.aggregationStep
+		if dt.d.AccessorList&AccessorBTree != 0 {
+			broken = append(broken, dt.d.kvBtFilePath(fromStep, toStep))
+		}
+		if dt.d.AccessorList&AccessorHashMap != 0 {
+			broken = append(broken, dt.d.kvAccessorFilePath(fromStep, toStep))
+		}
+		if dt.d.AccessorList&AccessorExistence != 0 {
+			broken = append(broken, dt.d.kvExistenceIdxFilePath(fromStep, toStep))
+		}
+	}
+	return broken, nil
+}
+
+func (dt *DomainRoTx) integrityAccessorsOfFile(ctx context.Context, i int, sampleEvery uint64, logEvery *time.Ticker) (ok bool, err error) {
+	item := dt.files[i].src
+	if dt.d.AccessorList&AccessorBTree != 0 && item.bindex == nil {
+		return false, nil
+	}
+	if dt.d.AccessorList&AccessorHashMap != 0 && item.index == nil {
+		return false, nil
+	}
+	useExistenceFilter := dt.d.AccessorList&AccessorExistence != 0 && item.existence != nil
+
+	defer item.decompressor.EnableReadAhead().DisableReadAhead()
+	r := seg.NewReader(item.decompressor.MakeGetter(), dt.d.Compression)
+	r.Reset(0)
+	var k, v []byte
+	for n := uint64(0); r.HasNext(); n++ {
+		k, _ = r.Next(k[:0])
+		if n%sampleEvery != 0 {
+			r.Skip()
+			continue
+		}
+		v, _ = r.Next(v[:0])
+
+		if useExistenceFilter {
+			hi, _ := dt.ht.iit.hashKey(k)
+			if !item.existence.ContainsHash(hi) {
+				dt.d.logger.Warn("[integrity] key not in existence filter", "f", item.decompressor.FileName(), "k", fmt.Sprintf("%x", common.Shorten(k, 8)))
+				return false, nil
+			}
+		}
+		fv, found, _, err := dt.getLatestFromFile(i, k)
+		if err != nil {
+			return false, err
+		}
+		if !found || !bytes.Equal(fv, v) {
+			dt.d.logger.Warn("[integrity] key not found by accessor", "f", item.decompressor.FileName(), "k", fmt.Sprintf("%x", common.Shorten(k, 8)), "found", found)
+			return false, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-logEvery.C:
+			dt.d.logger.Info("[integrity] domain accessors", "f", item.decompressor.FileName(), "k", fmt.Sprintf("%x", common.Shorten(k, 8)))
+		default:
+		}
+	}
+	return true, nil
+}
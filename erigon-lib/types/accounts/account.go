@@ -47,6 +47,7 @@ const (
 	MimetypeTypedData         = "data/typed"
 	MimetypeClique            = "application/x-clique-header"
 	MimetypeBor               = "application/x-bor-header"
+	MimetypeQBFT              = "application/x-qbft-message"
 	MimetypeTextPlain         = "text/plain"
 )
 
@@ -85,10 +85,12 @@ import (
 	executionclient "github.com/erigontech/erigon/cl/phase1/execution_client"
 	"github.com/erigontech/erigon/cmd/caplin/caplin1"
 	rpcdaemoncli "github.com/erigontech/erigon/cmd/rpcdaemon/cli"
+	"github.com/erigontech/erigon/cmd/rpcdaemon/cli/httpcfg"
 	"github.com/erigontech/erigon/consensus"
 	"github.com/erigontech/erigon/consensus/clique"
 	"github.com/erigontech/erigon/consensus/ethash"
 	"github.com/erigontech/erigon/consensus/merge"
+	"github.com/erigontech/erigon/consensus/qbft"
 	"github.com/erigontech/erigon/contracts"
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/rawdb"
@@ -100,6 +102,7 @@ import (
 	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/eth/ethconsensusconfig"
 	"github.com/erigontech/erigon/eth/protocols/eth"
+	"github.com/erigontech/erigon/eth/protocols/snap"
 	"github.com/erigontech/erigon/eth/stagedsync"
 	"github.com/erigontech/erigon/eth/stagedsync/stages"
 	"github.com/erigontech/erigon/eth/tracers"
@@ -109,6 +112,8 @@ import (
 	"github.com/erigontech/erigon/p2p"
 	"github.com/erigontech/erigon/p2p/enode"
 	"github.com/erigontech/erigon/p2p/sentry"
+	"github.com/erigontech/erigon/p2p/sentry/capture"
+	"github.com/erigontech/erigon/p2p/sentry/peerscore"
 	"github.com/erigontech/erigon/p2p/sentry/sentry_multi_client"
 	"github.com/erigontech/erigon/params"
 	"github.com/erigontech/erigon/polygon/bor"
@@ -123,9 +128,11 @@ import (
 	"github.com/erigontech/erigon/turbo/engineapi"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_block_downloader"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_helpers"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_recorder"
 	"github.com/erigontech/erigon/turbo/execution/eth1"
 	"github.com/erigontech/erigon/turbo/execution/eth1/eth1_chain_reader"
 	"github.com/erigontech/erigon/turbo/jsonrpc"
+	"github.com/erigontech/erigon/turbo/lightclient"
 	privateapi2 "github.com/erigontech/erigon/turbo/privateapi"
 	"github.com/erigontech/erigon/turbo/rpchelper"
 	"github.com/erigontech/erigon/turbo/services"
@@ -169,6 +176,7 @@ type Ethereum struct {
 	ethBackendRPC       *privateapi2.EthBackendServer
 	ethRpcClient        rpchelper.ApiBackend
 	engineBackendRPC    *engineapi.EngineServer
+	engineRecorder      *engine_recorder.Recorder
 	miningRPC           *privateapi2.MiningServer
 	miningRpcClient     txpoolproto.MiningClient
 	stateDiffClient     *direct.StateDiffClientDirect
@@ -184,6 +192,7 @@ type Ethereum struct {
 	sentryCancel   context.CancelFunc
 	sentriesClient *sentry_multi_client.MultiClient
 	sentryServers  []*sentry.GrpcServer
+	snapHandler    *snap.Handler
 
 	stagedSync         *stagedsync.Sync
 	pipelineStagedSync *stagedsync.Sync
@@ -444,8 +453,33 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			return nil, err
 		}
 
+		// peer scores and the banlist are shared by the sentries of all protocol versions
+		peerScorer := peerscore.New(peerscore.DefaultConfig(filepath.Join(stack.Config().Dirs.Nodes, "banlist.json")), logger)
+
+		var captureWriter *capture.Writer
+		if config.SentryCapture.Dir != "" {
+			captureWriter, err = capture.NewWriter(config.SentryCapture, logger)
+			if err != nil {
+				return nil, err
+			}
+			go func() {
+				<-backend.sentryCtx.Done()
+				captureWriter.Close()
+			}()
+			logger.Info("[sentry] capturing peer messages", "dir", config.SentryCapture.Dir)
+		}
+
+		var quicHost string
+		var quicPort int
+		if p2pConfig.QUICListenAddr != "" {
+			quicHost, quicPort, err = splitAddrIntoHostAndPort(p2pConfig.QUICListenAddr)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		var pi int // points to next port to be picked from refCfg.AllowedPorts
-		for _, protocol := range p2pConfig.ProtocolVersion {
+		for i, protocol := range p2pConfig.ProtocolVersion {
 			cfg := p2pConfig
 			cfg.NodeDatabase = filepath.Join(stack.Config().Dirs.Nodes, eth.ProtocolToString[protocol])
 
@@ -475,7 +509,14 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			}
 
 			cfg.ListenAddr = fmt.Sprintf("%s:%d", listenHost, listenPort)
+			if p2pConfig.QUICListenAddr != "" {
+				cfg.QUICListenAddr = fmt.Sprintf("%s:%d", quicHost, quicPort+i)
+			}
 			server := sentry.NewGrpcServer(backend.sentryCtx, nil, readNodeInfo, &cfg, protocol, logger)
+			server.SetPeerScorer(peerScorer)
+			if captureWriter != nil {
+				server.SetCapture(captureWriter)
+			}
 			backend.sentryServers = append(backend.sentryServers, server)
 			sentries = append(sentries, direct.NewSentryClientDirect(protocol, server))
 		}
@@ -539,6 +580,8 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		consensusConfig = &config.Aura
 	} else if chainConfig.Bor != nil {
 		consensusConfig = chainConfig.Bor
+	} else if chainConfig.QBFT != nil {
+		consensusConfig = chainConfig.QBFT
 	} else {
 		consensusConfig = &config.Ethash
 	}
@@ -583,6 +626,39 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 
 	backend.engine = ethconsensusconfig.CreateConsensusEngine(ctx, stack.Config(), chainConfig, consensusConfig, config.Miner.Notify, config.Miner.Noverify, heimdallClient, config.WithoutHeimdall, blockReader, false /* readonly */, logger, polygonBridge, heimdallService)
 
+	if qbftEngine, ok := backend.engine.(*qbft.QBFT); ok {
+		// Consensus messages are gossiped over the qbft subprotocol of the in-process sentries
+		if len(backend.sentryServers) == 0 {
+			logger.Warn("[qbft] Consensus messages need in-process sentries, validators won't reach each other")
+		}
+		for _, srv := range backend.sentryServers {
+			qbftEngine.AddTransport(srv.AddSubprotocol(qbft.ProtocolName, qbft.ProtocolVersion, qbft.ProtocolLength, qbft.ProtocolMaxMsgSize,
+				func(_ [64]byte, code uint64, data []byte) error { return qbftEngine.HandleMessage(code, data) }))
+		}
+		// Proposals are executed before this validator votes for them
+		qbftEngine.SetBlockVerifier(builder.NewBlockVerifier(chainConfig, backend.chainDB, backend.engine, blockReader, logger))
+	}
+
+	if config.SnapServe {
+		// State is served over the snap subprotocol of the in-process sentries
+		if len(backend.sentryServers) == 0 {
+			logger.Warn("[snap] Serving state needs in-process sentries, snap requests won't be answered")
+		}
+		snapHandler := snap.NewHandler(backend.chainDB, blockReader, dirs.Tmp, logger)
+		backend.snapHandler = snapHandler
+		for _, srv := range backend.sentryServers {
+			var sp *sentry.Subprotocol
+			sp = srv.AddSubprotocol(snap.ProtocolName, snap.ProtocolVersion, snap.ProtocolLength, snap.ProtocolMaxMsgSize,
+				func(peerID [64]byte, code uint64, data []byte) error {
+					respCode, resp, err := snapHandler.HandleMessage(backend.sentryCtx, code, data)
+					if err != nil || resp == nil {
+						return err
+					}
+					return sp.Send(peerID, respCode, resp)
+				})
+		}
+	}
+
 	inMemoryExecution := func(txc wrap.TxContainer, header *types.Header, body *types.RawBody, unwindPoint uint64, headersChain []*types.Header, bodiesChain []*types.RawBody,
 		notifications *shards.Notifications) error {
 		terseLogger := log.New()
@@ -822,6 +898,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 				config.Genesis,
 				config.Sync,
 				stages2.SilkwormForExecutionStage(backend.silkworm, config),
+				nil,
 			),
 			stagedsync.StageSendersCfg(backend.chainDB, chainConfig, config.Sync, false, dirs.Tmp, config.Prune, blockReader, backend.sentriesClient.Hd),
 			stagedsync.StageMiningExecCfg(backend.chainDB, miner, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, nil, 0, txnProvider, blockReader),
@@ -831,9 +908,10 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		logger, stages.ModeBlockProduction)
 
 	// proof-of-stake mining
-	assembleBlockPOS := func(param *core.BlockBuilderParameters, interrupt *int32) (*types.BlockWithReceipts, error) {
+	assembleBlockPOS := func(param *core.BlockBuilderParameters, interrupt *int32, report *builder.PayloadReport) (*types.BlockWithReceipts, error) {
 		miningStatePos := stagedsync.NewMiningState(&config.Miner)
 		miningStatePos.MiningConfig.Etherbase = param.SuggestedFeeRecipient
+		miningStatePos.Report = report
 		proposingSync := stagedsync.New(
 			config.Sync,
 			stagedsync.MiningStages(backend.sentryCtx,
@@ -869,6 +947,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 					config.Genesis,
 					config.Sync,
 					stages2.SilkwormForExecutionStage(backend.silkworm, config),
+					nil,
 				),
 				stagedsync.StageSendersCfg(backend.chainDB, chainConfig, config.Sync, false, dirs.Tmp, config.Prune, blockReader, backend.sentriesClient.Hd),
 				stagedsync.StageMiningExecCfg(backend.chainDB, miningStatePos, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, interrupt, param.PayloadId, txnProvider, blockReader),
@@ -1023,7 +1102,11 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 	}
 
 	checkStateRoot := true
-	pipelineStages := stages2.NewPipelineStages(ctx, backend.chainDB, config, p2pConfig, backend.sentriesClient, backend.notifications, backend.downloaderClient, blockReader, blockRetire, backend.silkworm, backend.forkValidator, logger, tracer, checkStateRoot)
+	var prefetcher *core.StatePrefetcher
+	if config.Sync.Prefetch {
+		prefetcher = core.NewStatePrefetcher(chainConfig, backend.chainDB, config.Sync.PrefetchWorkers, logger)
+	}
+	pipelineStages := stages2.NewPipelineStages(ctx, backend.chainDB, config, p2pConfig, backend.sentriesClient, backend.notifications, backend.downloaderClient, blockReader, blockRetire, backend.silkworm, backend.forkValidator, logger, tracer, checkStateRoot, prefetcher)
 	backend.pipelineStagedSync = stagedsync.New(config.Sync, pipelineStages, stagedsync.PipelineUnwindOrder, stagedsync.PipelinePruneOrder, logger, stages.ModeApplyingBlocks)
 	backend.eth1ExecutionServer = eth1.NewEthereumExecutionModule(blockReader, backend.chainDB, backend.pipelineStagedSync, backend.forkValidator, chainConfig, assembleBlockPOS, hook, backend.notifications.Accumulator, backend.notifications.RecentLogs, backend.notifications.StateChangesConsumer, logger, backend.engine, config.Sync, ctx)
 	executionRpc := direct.NewExecutionClientDirect(backend.eth1ExecutionServer)
@@ -1049,6 +1132,19 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		config.Miner.EnabledPOS,
 		!config.PolygonPosSingleSlotFinality,
 	)
+	if config.EngineRecordDirPath != "" {
+		backend.engineRecorder, err = engine_recorder.NewRecorder(config.EngineRecordDirPath, uint(config.EngineRecordMaxFileSize), uint(config.EngineRecordMaxFiles), logger)
+		if err != nil {
+			return nil, err
+		}
+		engineBackendRPC.SetRecorder(backend.engineRecorder)
+	}
+	if prefetcher != nil {
+		engineBackendRPC.SetPrefetcher(prefetcher)
+	}
+	if config.Miner.EnabledPOS {
+		engineBackendRPC.SetPayloadReportAPI(engineapi.NewPayloadReportAPI(backend.eth1ExecutionServer, builder.NewSimulator(chainConfig, backend.chainDB, backend.engine, blockReader, logger)))
+	}
 	backend.engineBackendRPC = engineBackendRPC
 	// If we choose not to run a consensus layer, run our embedded.
 	if config.InternalCL && (clparams.EmbeddedSupported(config.NetworkID) || config.CaplinConfig.IsDevnet()) {
@@ -1169,6 +1265,16 @@ func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig
 	}
 
 	s.apiList = jsonrpc.APIList(chainKv, s.ethRpcClient, s.txPoolRpcClient, s.miningRpcClient, s.rpcFilters, s.rpcDaemonStateCache, blockReader, &httpRpcCfg, s.engine, s.logger, s.polygonBridge, s.heimdallService)
+	if len(config.LightProviders) > 0 {
+		lightApis, err := s.lightClientAPIs(ctx, config, chainConfig, &httpRpcCfg)
+		if err != nil {
+			return err
+		}
+		s.apiList = append(s.apiList, lightApis...)
+	}
+	if len(s.sentryServers) > 0 && slices.Contains(httpRpcCfg.API, "admin") {
+		s.apiList = append(s.apiList, peerscore.NewAPI(s.sentryServers[0].PeerScorer()).APIs()...)
+	}
 
 	if config.SilkwormRpcDaemon && httpRpcCfg.Enabled {
 		interface_log_settings := silkworm.RpcInterfaceLogSettings{
@@ -1210,6 +1316,35 @@ func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig
 	return nil
 }
 
+// lightClientAPIs starts tracking finalized headers from the beacon API and returns the eth API
+// answering state queries from proofs of config.LightProviders. Registered last, it overrides
+// the methods of the same name served from the local database.
+func (s *Ethereum) lightClientAPIs(ctx context.Context, config *ethconfig.Config, chainConfig *chain.Config, httpRpcCfg *httpcfg.HttpCfg) ([]rpc.API, error) {
+	providers := make([]lightclient.ProofProvider, 0, len(config.LightProviders))
+	for _, url := range config.LightProviders {
+		provider, err := lightclient.DialProvider(ctx, url, s.logger)
+		if err != nil {
+			return nil, fmt.Errorf("light client provider %s: %w", url, err)
+		}
+		providers = append(providers, provider)
+	}
+	if config.LightCheckpoint == (libcommon.Hash{}) {
+		return nil, errors.New("light client requires a trusted checkpoint, see --light.checkpoint")
+	}
+	if !clparams.EmbeddedSupported(config.NetworkID) {
+		return nil, fmt.Errorf("light client: no beacon chain config for network %d", config.NetworkID)
+	}
+	_, beaconCfg := clparams.GetConfigsByNetwork(clparams.NetworkType(config.NetworkID))
+	beaconUrl := config.LightBeaconUrl
+	if beaconUrl == "" {
+		beaconUrl = "http://" + config.CaplinConfig.BeaconAPIRouter.Address
+	}
+	tracker := lightclient.NewHeaderTracker(lightclient.NewBeaconApiSource(beaconUrl, s.logger), beaconCfg, config.LightCheckpoint, s.logger)
+	go tracker.Run(ctx, 12*time.Second)
+	s.logger.Info("[lightclient] serving state from proofs", "providers", config.LightProviders, "beaconApi", beaconUrl, "checkpoint", config.LightCheckpoint)
+	return lightclient.NewEthAPI(tracker, providers, chainConfig, httpRpcCfg.Gascap, httpRpcCfg.EvmCallTimeout, s.logger).APIs(), nil
+}
+
 func (s *Ethereum) APIs() []rpc.API {
 	return s.apiList
 }
@@ -1282,6 +1417,10 @@ func (s *Ethereum) StartMining(ctx context.Context, db kv.RwDB, stateDiffClient
 			s.engine.(*clique.Clique).Authorize(eb, func(_ libcommon.Address, _ string, msg []byte) ([]byte, error) {
 				return crypto.Sign(crypto.Keccak256(msg), miner.MiningConfig.SigKey)
 			})
+		} else if s.chainConfig.Consensus == chain.QBFTConsensus {
+			s.engine.(*qbft.QBFT).Authorize(eb, func(_ libcommon.Address, _ string, msg []byte) ([]byte, error) {
+				return crypto.Sign(crypto.Keccak256(msg), miner.MiningConfig.SigKey)
+			})
 		} else {
 			s.logger.Error("mining is not supported after the Merge")
 			return errors.New("mining is not supported after the Merge")
@@ -1749,7 +1888,13 @@ func (s *Ethereum) Stop() error {
 	for _, sentryServer := range s.sentryServers {
 		sentryServer.Close()
 	}
+	if s.snapHandler != nil {
+		s.snapHandler.Close()
+	}
 	s.chainDB.Close()
+	if err := s.engineRecorder.Close(); err != nil {
+		s.logger.Error("engine recorder close error", "err", err)
+	}
 
 	if s.silkwormRPCDaemonService != nil {
 		if err := s.silkwormRPCDaemonService.Stop(); err != nil {
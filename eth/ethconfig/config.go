@@ -42,6 +42,7 @@ import (
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/eth/ethconfig/estimate"
 	"github.com/erigontech/erigon/eth/gasprice/gaspricecfg"
+	"github.com/erigontech/erigon/p2p/sentry/capture"
 	"github.com/erigontech/erigon/params"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/txnprovider/shutter"
@@ -81,6 +82,8 @@ var Defaults = Config{
 		//LoopBlockLimit:             100_000,
 		ParallelStateFlushing: true,
 		ChaosMonkey:           false,
+		BlockSTMMinTxs:        16,
+		PrefetchWorkers:       4,
 	},
 	Ethash: ethashcfg.Config{
 		CachesInMem:      2,
@@ -182,6 +185,12 @@ type Config struct {
 	// for nodes to connect to.
 	EthDiscoveryURLs []string
 
+	// Serve snap/1 state sync requests of the peers from the latest state
+	SnapServe bool
+
+	// Record the messages of the peers, disabled if the directory is empty
+	SentryCapture capture.Config
+
 	Prune     prune.Mode
 	BatchSize datasize.ByteSize // Batch size for execution stage
 
@@ -244,6 +253,16 @@ type Config struct {
 	// Consensus layer
 	InternalCL bool
 
+	// Engine API call recording, disabled if the dir is empty
+	EngineRecordDirPath     string
+	EngineRecordMaxFileSize uint16
+	EngineRecordMaxFiles    uint16
+
+	// Light mode: state queries answered from proofs of these providers, disabled if empty
+	LightProviders  []string
+	LightBeaconUrl  string
+	LightCheckpoint common.Hash
+
 	OverridePragueTime *big.Int `toml:",omitempty"`
 
 	// Embedded Silkworm support
@@ -286,4 +305,13 @@ type Sync struct {
 
 	ChaosMonkey              bool
 	AlwaysGenerateChangesets bool
+
+	// BlockSTM - execute txns of blocks at chain tip speculatively in parallel by ExecWorkerCount workers.
+	// Blocks with less than BlockSTMMinTxs txns are executed serially.
+	BlockSTM       bool
+	BlockSTMMinTxs int
+
+	// Prefetch - warm state reads of payloads received by Engine API before and during their execution
+	Prefetch        bool
+	PrefetchWorkers int
 }
@@ -0,0 +1,419 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/anacrolix/torrent/metainfo"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/chain/snapcfg"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/common/dir"
+	"github.com/erigontech/erigon-lib/downloader"
+	"github.com/erigontech/erigon-lib/downloader/snaptype"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core/rawdb"
+	coresnaptype "github.com/erigontech/erigon/core/snaptype"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/stagedsync/stages"
+	"github.com/erigontech/erigon/turbo/jsonrpc/receipts"
+	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+type ScrubCheck string
+
+const (
+	Checksums        ScrubCheck = "checksums" // .seg/.idx/.kv/.kvi/.bt/.ef/.efi/... against preverified hashes or .torrent files
+	DomainAccessors  ScrubCheck = "accessors" // Domain .kv files against their .kvi/.bt/.kvei
+	TxNumsContinuity ScrubCheck = "txnums"    // TxNums continuity against bodies
+	ReceiptsRoot     ScrubCheck = "receipts"  // receipts root re-computation of sampled blocks
+)
+
+var AllScrubChecks = []ScrubCheck{Checksums, DomainAccessors, TxNumsContinuity, ReceiptsRoot}
+
+var scrubChecksumExts = []string{".seg", ".idx", ".kv", ".kvi", ".kvei", ".bt", ".v", ".vi", ".ef", ".efi"}
+
+type ScrubProblem struct {
+	Check   ScrubCheck `json:"check"`
+	File    string     `json:"file,omitempty"`
+	Block   *uint64    `json:"block,omitempty"`
+	Message string     `json:"message"`
+}
+
+// ScrubReport - machine-readable result of `erigon scrub`. File names are relative to `snapshots` dir.
+type ScrubReport struct {
+	Checks       []ScrubCheck   `json:"checks"`
+	FilesChecked int            `json:"filesChecked"`
+	Unverifiable []string       `json:"unverifiable"` // no preverified hash and no .torrent file
+	Problems     []ScrubProblem `json:"problems"`
+	// Delete - files to remove before next start. Accessors are re-built on startup, other files are re-downloaded
+	Delete []string `json:"delete"`
+	// Redownload - subset of Delete which downloader must fetch again
+	Redownload []string `json:"redownload"`
+
+	mu sync.Mutex
+}
+
+func (r *ScrubReport) OK() bool { return len(r.Problems) == 0 }
+
+func (r *ScrubReport) addProblem(p ScrubProblem) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Problems = append(r.Problems, p)
+	log.Warn("[scrub] "+p.Message, "check", p.Check, "file", p.File)
+}
+
+func (r *ScrubReport) addDelete(fName string, redownload bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Delete = append(r.Delete, fName)
+	if redownload {
+		r.Redownload = append(r.Redownload, fName)
+	}
+}
+
+// Finish - sorts and de-duplicates lists, so report of same datadir is always same
+func (r *ScrubReport) Finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, l := range []*[]string{&r.Delete, &r.Redownload, &r.Unverifiable} {
+		if *l == nil {
+			*l = []string{}
+		}
+		slices.Sort(*l)
+		*l = slices.Compact(*l)
+	}
+	if r.Problems == nil {
+		r.Problems = []ScrubProblem{}
+	}
+	slices.SortStableFunc(r.Problems, func(a, b ScrubProblem) int {
+		return slices.Index(AllScrubChecks, a.Check) - slices.Index(AllScrubChecks, b.Check)
+	})
+}
+
+// ScrubChecksums - re-hashes every data and accessor file in `snapshots` dir and compares infohash with
+// preverified one, or (for files produced locally) with .torrent file next to it. Big IO.
+func ScrubChecksums(ctx context.Context, dirs datadir.Dirs, preverified snapcfg.Preverified, workers int, report *ScrubReport) error {
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+
+	var files []string
+	if err := filepath.WalkDir(dirs.Snap, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !slices.Contains(scrubChecksumExts, filepath.Ext(path)) {
+			return nil
+		}
+		fName, err := filepath.Rel(dirs.Snap, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(fName))
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(-1)
+	}
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(workers)
+	var done atomic.Int32
+	for _, fName := range files {
+		g.Go(func() error {
+			defer done.Add(1)
+			return scrubChecksum(ctx, dirs, fName, preverified, report)
+		})
+	}
+
+	wait := make(chan error, 1)
+	go func() { wait <- g.Wait() }()
+	for {
+		select {
+		case err := <-wait:
+			report.mu.Lock()
+			report.FilesChecked += int(done.Load())
+			report.mu.Unlock()
+			return err
+		case <-logEvery.C:
+			log.Info("[scrub] checksums", "progress", fmt.Sprintf("%d/%d", done.Load(), len(files)))
+		}
+	}
+}
+
+func scrubChecksum(ctx context.Context, dirs datadir.Dirs, fName string, preverified snapcfg.Preverified, report *ScrubReport) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	var expected metainfo.Hash
+	item, isPreverified := preverified.Get(fName)
+	switch {
+	case isPreverified:
+		if err := expected.FromHexString(item.Hash); err != nil {
+			return fmt.Errorf("preverified hash of %s: %w", fName, err)
+		}
+	default:
+		torrentPath := filepath.Join(dirs.Snap, fName) + ".torrent"
+		exists, err := dir.FileExist(torrentPath)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			report.mu.Lock()
+			report.Unverifiable = append(report.Unverifiable, fName)
+			report.mu.Unlock()
+			return nil
+		}
+		mi, err := metainfo.LoadFromFile(torrentPath)
+		if err != nil {
+			report.addProblem(ScrubProblem{Check: Checksums, File: fName + ".torrent", Message: fmt.Sprintf("can't read .torrent: %s", err)})
+			report.addDelete(fName+".torrent", false)
+			return nil
+		}
+		expected = mi.HashInfoBytes()
+	}
+
+	got, err := downloader.FileInfoHash(fName, dirs.Snap)
+	if err != nil {
+		return err
+	}
+	if got == expected {
+		return nil
+	}
+	report.addProblem(ScrubProblem{Check: Checksums, File: fName, Message: fmt.Sprintf("infohash mismatch: expected %s, got %s", expected.HexString(), got.HexString())})
+	report.addDelete(fName, isPreverified)
+	report.addDelete(fName+".torrent", false)
+	return nil
+}
+
+// ScrubAccessors - checks that every `sampleEvery`-th key of Domain .kv files is findable by accessors.
+// Broken accessors are listed for deletion: they are re-built on startup.
+func ScrubAccessors(ctx context.Context, db kv.TemporalRoDB, dirs datadir.Dirs, sampleEvery uint64, report *ScrubReport) error {
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ac := tx.(state.HasAggTx).AggTx().(*state.AggregatorRoTx)
+	for domain := kv.Domain(0); domain < kv.DomainLen; domain++ {
+		broken, err := ac.IntegrityDomainAccessors(ctx, domain, sampleEvery)
+		if err != nil {
+			return err
+		}
+		for _, fPath := range broken {
+			fName, err := filepath.Rel(dirs.Snap, fPath)
+			if err != nil {
+				return err
+			}
+			fName = filepath.ToSlash(fName)
+			report.addProblem(ScrubProblem{Check: DomainAccessors, File: fName, Message: fmt.Sprintf("%s accessor doesn't match its .kv file", domain)})
+			report.addDelete(fName, false)
+			report.addDelete(fName+".torrent", false)
+		}
+		log.Info("[scrub] accessors", "domain", domain, "broken", len(broken))
+	}
+	return nil
+}
+
+// ScrubTxNums - checks that each block's first txNum follows previous block's last txNum, and that
+// TxNums stored in db match bodies. Bodies segments with gaps are listed for re-download.
+func ScrubTxNums(ctx context.Context, db kv.TemporalRoDB, blockReader services.FullBlockReader, report *ScrubReport) error {
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	fromBlock, err := rawdb.ReadHistoryExpiredTo(tx)
+	if err != nil {
+		return err
+	}
+	toBlock, err := stages.GetStageProgress(tx, stages.Bodies)
+	if err != nil {
+		return err
+	}
+	toBlock = max(toBlock, blockReader.FrozenBlocks())
+	c, err := tx.Cursor(kv.MaxTxNum)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var prevMaxTxNum uint64
+	for blockNum := fromBlock; blockNum <= toBlock; blockNum++ {
+		body, err := blockReader.CanonicalBodyForStorage(ctx, tx, blockNum)
+		if err != nil {
+			return err
+		}
+		if body == nil {
+			scrubBlockProblem(blockReader, report, TxNumsContinuity, coresnaptype.Enums.Bodies, blockNum, "canonical body not found")
+			prevMaxTxNum = 0
+			continue
+		}
+		maxTxNum := body.BaseTxnID.U64() + uint64(body.TxCount) - 1
+		if blockNum > fromBlock && prevMaxTxNum != 0 && body.BaseTxnID.U64() != prevMaxTxNum+1 {
+			scrubBlockProblem(blockReader, report, TxNumsContinuity, coresnaptype.Enums.Bodies, blockNum, fmt.Sprintf("txNums gap: first txNum %d, previous block's last %d", body.BaseTxnID.U64(), prevMaxTxNum))
+		}
+		dbMaxTxNum, ok, err := rawdbv3.DefaultReadTxNumFunc(tx, c, blockNum)
+		if err != nil {
+			return err
+		}
+		if ok && dbMaxTxNum != maxTxNum {
+			bn := blockNum
+			report.addProblem(ScrubProblem{Check: TxNumsContinuity, Block: &bn, Message: fmt.Sprintf("db MaxTxNum %d doesn't match body %d", dbMaxTxNum, maxTxNum)})
+		}
+		prevMaxTxNum = maxTxNum
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-logEvery.C:
+			log.Info("[scrub] txnums", "block", blockNum, "of", toBlock)
+		default:
+		}
+	}
+	return nil
+}
+
+// ScrubReceipts - re-executes `samples` random blocks with available state history and compares
+// root of produced receipts with header's ReceiptHash.
+func ScrubReceipts(ctx context.Context, db kv.TemporalRoDB, blockReader services.FullBlockReader, chainConfig *chain.Config, engine consensus.EngineReader, samples int, report *ScrubReport) error {
+	if chainConfig.Bor != nil {
+		log.Info("[scrub] receipts: skipping, bor state-sync receipts are not part of receipts root")
+		return nil
+	}
+
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, blockReader))
+	fromBlock, err := rawdb.ReadHistoryExpiredTo(tx)
+	if err != nil {
+		return err
+	}
+	if historyStart := tx.HistoryStartFrom(kv.AccountsDomain); historyStart > 0 {
+		ok, bn, err := txNumsReader.FindBlockNum(tx, historyStart)
+		if err != nil {
+			return err
+		}
+		if ok {
+			fromBlock = max(fromBlock, bn+1)
+		}
+	}
+	fromBlock = max(fromBlock, 1)
+	toBlock, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
+		return err
+	}
+	if toBlock < fromBlock {
+		log.Info("[scrub] receipts: no blocks with state history", "from", fromBlock, "to", toBlock)
+		return nil
+	}
+
+	blockNums := make([]uint64, 0, samples)
+	if span := toBlock - fromBlock + 1; span <= uint64(samples) {
+		for bn := fromBlock; bn <= toBlock; bn++ {
+			blockNums = append(blockNums, bn)
+		}
+	} else {
+		for range samples {
+			blockNums = append(blockNums, fromBlock+rand.Uint64N(span))
+		}
+		slices.Sort(blockNums)
+		blockNums = slices.Compact(blockNums)
+	}
+
+	generator := receipts.NewGenerator(blockReader, engine)
+	for _, blockNum := range blockNums {
+		block, err := blockReader.BlockByNumber(ctx, tx, blockNum)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			scrubBlockProblem(blockReader, report, ReceiptsRoot, coresnaptype.Enums.Transactions, blockNum, "block not found")
+			continue
+		}
+		rs, err := generator.GetReceipts(ctx, chainConfig, tx, block)
+		if err != nil {
+			bn := blockNum
+			report.addProblem(ScrubProblem{Check: ReceiptsRoot, Block: &bn, Message: fmt.Sprintf("can't re-execute block: %s", err)})
+			continue
+		}
+		if root := types.DeriveSha(rs); root != block.ReceiptHash() {
+			bn := blockNum
+			report.addProblem(ScrubProblem{Check: ReceiptsRoot, Block: &bn, Message: fmt.Sprintf("receipts root mismatch: header %x, computed %x", block.ReceiptHash(), root)})
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+	}
+	log.Info("[scrub] receipts", "sampled", len(blockNums), "from", fromBlock, "to", toBlock)
+	return nil
+}
+
+// scrubBlockProblem - records problem of given block. If the block is frozen - segment of given type
+// holding it is listed for re-download.
+func scrubBlockProblem(blockReader services.FullBlockReader, report *ScrubReport, check ScrubCheck, segType snaptype.Enum, blockNum uint64, msg string) {
+	bn := blockNum
+	p := ScrubProblem{Check: check, Block: &bn, Message: msg}
+	if blockNum < blockReader.FrozenBlocks() {
+		for _, fName := range blockReader.FrozenFiles() {
+			fi, _, ok := snaptype.ParseFileName("", fName)
+			if !ok || fi.Ext != ".seg" || blockNum < fi.From || blockNum >= fi.To {
+				continue
+			}
+			if fi.Type == nil || fi.Type.Enum() != segType {
+				continue
+			}
+			p.File = fName
+			report.addDelete(fName, true)
+			report.addDelete(fName+".torrent", false)
+		}
+	}
+	report.addProblem(p)
+}
@@ -0,0 +1,198 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package integrity
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain/snapcfg"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/downloader"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/stages/mock"
+)
+
+// scrubBlockReader - serves bodies, blocks and frozen files from memory, everything else from embedded reader
+type scrubBlockReader struct {
+	services.FullBlockReader
+	bodies      map[uint64]*types.BodyForStorage
+	blocks      map[uint64]*types.Block
+	frozen      uint64
+	frozenFiles []string
+}
+
+func (r *scrubBlockReader) CanonicalBodyForStorage(ctx context.Context, tx kv.Getter, blockNum uint64) (*types.BodyForStorage, error) {
+	return r.bodies[blockNum], nil
+}
+
+func (r *scrubBlockReader) BlockByNumber(ctx context.Context, tx kv.Tx, number uint64) (*types.Block, error) {
+	if b, ok := r.blocks[number]; ok {
+		return b, nil
+	}
+	return r.FullBlockReader.BlockByNumber(ctx, tx, number)
+}
+
+func (r *scrubBlockReader) FrozenBlocks() uint64  { return r.frozen }
+func (r *scrubBlockReader) FrozenFiles() []string { return r.frozenFiles }
+
+func TestScrubChecksums(t *testing.T) {
+	dirs := datadir.New(t.TempDir())
+	writeSeg := func(fName, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dirs.Snap, fName), []byte(content), 0o644))
+	}
+	const (
+		good      = "v1-000000-000010-headers.seg"
+		corrupted = "v1-000000-000010-bodies.seg"
+		local     = "v1-000000-000010-transactions.seg"
+	)
+	writeSeg(good, "headers")
+	writeSeg(corrupted, "bodies")
+	writeSeg(local, "transactions")
+
+	hashOf := func(fName string) string {
+		h, err := downloader.FileInfoHash(fName, dirs.Snap)
+		require.NoError(t, err)
+		return h.HexString()
+	}
+	preverified := snapcfg.Preverified{
+		{Name: corrupted, Hash: hashOf(corrupted)},
+		{Name: good, Hash: hashOf(good)},
+	}
+	writeSeg(corrupted, "bodies, flipped")
+
+	report := &ScrubReport{}
+	require.NoError(t, ScrubChecksums(context.Background(), dirs, preverified, 2, report))
+	report.Finish()
+
+	require.Equal(t, 3, report.FilesChecked)
+	require.Equal(t, []string{local}, report.Unverifiable)
+	require.Equal(t, []string{corrupted, corrupted + ".torrent"}, report.Delete)
+	require.Equal(t, []string{corrupted}, report.Redownload)
+	require.Len(t, report.Problems, 1)
+	require.Equal(t, Checksums, report.Problems[0].Check)
+	require.Equal(t, corrupted, report.Problems[0].File)
+}
+
+func TestScrubTxNums(t *testing.T) {
+	m := mock.Mock(t)
+	const bodiesSeg = "v1-000000-000010-bodies.seg"
+	blockReader := &scrubBlockReader{
+		FullBlockReader: m.BlockReader,
+		bodies: map[uint64]*types.BodyForStorage{
+			0: {BaseTxnID: 0, TxCount: 2},
+			1: {BaseTxnID: 2, TxCount: 2},
+			2: {BaseTxnID: 10, TxCount: 2}, // txNums 4..9 are missing
+			3: {BaseTxnID: 12, TxCount: 2},
+		},
+		frozen:      3,
+		frozenFiles: []string{"v1-000000-000010-headers.seg", bodiesSeg, "v1-000000-000010-transactions.seg"},
+	}
+
+	report := &ScrubReport{}
+	require.NoError(t, ScrubTxNums(context.Background(), m.DB, blockReader, report))
+	report.Finish()
+
+	require.Len(t, report.Problems, 1)
+	require.Equal(t, TxNumsContinuity, report.Problems[0].Check)
+	require.Equal(t, uint64(2), *report.Problems[0].Block)
+	require.Equal(t, bodiesSeg, report.Problems[0].File)
+	require.Equal(t, []string{bodiesSeg, bodiesSeg + ".torrent"}, report.Delete)
+	require.Equal(t, []string{bodiesSeg}, report.Redownload)
+}
+
+func TestScrubReceipts(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &types.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	m := mock.MockWithGenesis(t, gspec, key, false)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3, func(i int, b *core.BlockGen) {
+		txn, err := types.SignTx(types.NewTransaction(b.TxNonce(addr), libcommon.HexToAddress("deadbeef"), uint256.NewInt(100), params.TxGas, uint256.NewInt(params.GWei), nil), *signer, key)
+		require.NoError(t, err)
+		b.AddTx(txn)
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	// header of block 2 claims receipts its transactions don't produce
+	header := chain.Blocks[1].Header()
+	header.ReceiptHash = libcommon.Hash{0x01}
+	blockReader := &scrubBlockReader{
+		FullBlockReader: m.BlockReader,
+		blocks:          map[uint64]*types.Block{2: chain.Blocks[1].WithSeal(header)},
+	}
+
+	report := &ScrubReport{}
+	require.NoError(t, ScrubReceipts(context.Background(), m.DB, blockReader, m.ChainConfig, m.Engine, 10, report))
+	report.Finish()
+
+	require.Len(t, report.Problems, 1)
+	require.Equal(t, ReceiptsRoot, report.Problems[0].Check)
+	require.Equal(t, uint64(2), *report.Problems[0].Block)
+	require.Empty(t, report.Problems[0].File)
+	require.Empty(t, report.Delete)
+	require.Empty(t, report.Redownload)
+}
+
+func TestScrubReportFinish(t *testing.T) {
+	report := &ScrubReport{}
+	report.addProblem(ScrubProblem{Check: ReceiptsRoot, Message: "receipts"})
+	report.addProblem(ScrubProblem{Check: Checksums, File: "b.seg", Message: "b"})
+	report.addProblem(ScrubProblem{Check: TxNumsContinuity, Message: "txnums"})
+	report.addProblem(ScrubProblem{Check: Checksums, File: "a.seg", Message: "a"})
+	report.addDelete("b.seg", true)
+	report.addDelete("b.seg.torrent", false)
+	report.addDelete("a.seg", true)
+	report.addDelete("b.seg", true)
+	report.Finish()
+
+	require.Equal(t, []string{"a.seg", "b.seg", "b.seg.torrent"}, report.Delete)
+	require.Equal(t, []string{"a.seg", "b.seg"}, report.Redownload)
+	require.NotNil(t, report.Unverifiable)
+	require.Empty(t, report.Unverifiable)
+	// problems are grouped by check, keeping order of discovery inside a check
+	var messages []string
+	for _, p := range report.Problems {
+		messages = append(messages, p.Message)
+	}
+	require.Equal(t, []string{"b", "a", "txnums", "receipts"}, messages)
+
+	empty := &ScrubReport{}
+	empty.Finish()
+	require.True(t, empty.OK())
+	require.NotNil(t, empty.Problems)
+	require.NotNil(t, empty.Delete)
+	require.NotNil(t, empty.Redownload)
+}
@@ -27,6 +27,7 @@ import (
 	libcommon "github.com/erigontech/erigon-lib/common"
 
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/direct"
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon/core/types"
 )
@@ -232,3 +233,61 @@ func TestEth66Messages(t *testing.T) {
 		}
 	}
 }
+
+func TestReceipts69EncodeDecode(t *testing.T) {
+	receipts := []*types.Receipt{
+		{
+			Type:              types.LegacyTxType,
+			Status:            types.ReceiptStatusFailed,
+			CumulativeGasUsed: 1,
+			Logs: []*types.Log{
+				{
+					Address: libcommon.BytesToAddress([]byte{0x11}),
+					Topics:  []libcommon.Hash{libcommon.HexToHash("dead"), libcommon.HexToHash("beef")},
+					Data:    []byte{0x01, 0x00, 0xff},
+				},
+			},
+		},
+		{
+			Type:              types.DynamicFeeTxType,
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: 21001,
+		},
+	}
+	for _, r := range receipts {
+		r.Bloom = types.CreateBloom(types.Receipts{r})
+	}
+
+	have, err := rlp.EncodeToBytes(ReceiptsPacket69{1111, []BlockReceipts69{receipts}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// receipts are [type, status, cumulative gas, logs] without the bloom
+	want := common.FromHex("f874820457f86ff86df864808001f85ff85d940000000000000000000000000000000000000011f842a0000000000000000000000000000000000000000000000000000000000000deada0000000000000000000000000000000000000000000000000000000000000beef830100ffc60201825209c0")
+	if !bytes.Equal(have, want) {
+		t.Fatalf("have\n\t%x\nwant\n\t%x", have, want)
+	}
+	encoded, err := EncodeReceipts(receipts, direct.ETH69)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, have[7:]) {
+		t.Fatalf("EncodeReceipts: have\n\t%x\nwant\n\t%x", encoded, have[7:])
+	}
+
+	var decoded ReceiptsPacket69
+	if err := rlp.DecodeBytes(have, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.RequestId != 1111 || len(decoded.Receipts) != 1 || len(decoded.Receipts[0]) != len(receipts) {
+		t.Fatalf("unexpected packet %+v", decoded)
+	}
+	for i, r := range decoded.Receipts[0] {
+		if r.Type != receipts[i].Type || r.Status != receipts[i].Status || r.CumulativeGasUsed != receipts[i].CumulativeGasUsed {
+			t.Errorf("receipt %d: have %v, want %v", i, r, receipts[i])
+		}
+		if r.Bloom != receipts[i].Bloom {
+			t.Errorf("receipt %d: bloom is not derived from logs", i)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/order"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/kv/stream"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/eth/stagedsync/stages"
+)
+
+// codeIndexInterval is how often the code index picks up newly deployed code.
+const codeIndexInterval = time.Minute
+
+// codeIndex maps code hashes to an account holding the code. Flat state keeps code by
+// address, so the index is built by scanning the code domain once and then updated
+// from the code domain history.
+type codeIndex struct {
+	db       kv.TemporalRoDB
+	txNums   rawdbv3.TxNumsReader
+	logger   log.Logger
+	updating sync.Mutex
+
+	mu    sync.RWMutex
+	codes map[libcommon.Hash]libcommon.Address
+	txNum uint64 // code changes before the tx are indexed
+}
+
+func newCodeIndex(db kv.TemporalRoDB, txNums rawdbv3.TxNumsReader, logger log.Logger) *codeIndex {
+	return &codeIndex{db: db, txNums: txNums, logger: logger, codes: make(map[libcommon.Hash]libcommon.Address)}
+}
+
+func (c *codeIndex) get(hash libcommon.Hash) (libcommon.Address, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	addr, ok := c.codes[hash]
+	return addr, ok
+}
+
+// remove drops the hash whose account got a different code.
+func (c *codeIndex) remove(hash libcommon.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.codes, hash)
+}
+
+func (c *codeIndex) run(ctx context.Context) {
+	ticker := time.NewTicker(codeIndexInterval)
+	defer ticker.Stop()
+	for {
+		if err := c.update(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Warn("[snap] Failed to index contract code", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// update indexes the code deployed since the previous update.
+func (c *codeIndex) update(ctx context.Context) error {
+	c.updating.Lock()
+	defer c.updating.Unlock()
+
+	tx, err := c.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	head, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
+		return err
+	}
+	toTxNum, err := c.txNums.Max(tx, head)
+	if err != nil {
+		return err
+	}
+	toTxNum++
+
+	c.mu.RLock()
+	fromTxNum := c.txNum
+	c.mu.RUnlock()
+	if fromTxNum >= toTxNum {
+		return nil
+	}
+	start := time.Now()
+	var it stream.KV
+	if fromTxNum == 0 {
+		it, err = tx.RangeAsOf(kv.CodeDomain, nil, nil, toTxNum, order.Asc, kv.Unlim)
+	} else {
+		it, err = tx.HistoryRange(kv.CodeDomain, int(fromTxNum), int(toTxNum), order.Asc, kv.Unlim)
+	}
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	codes := make(map[libcommon.Hash]libcommon.Address)
+	for it.HasNext() {
+		addr, code, err := it.Next()
+		if err != nil {
+			return err
+		}
+		if fromTxNum > 0 {
+			// history holds the code before the change
+			if code, _, err = tx.GetLatest(kv.CodeDomain, addr); err != nil {
+				return err
+			}
+		}
+		if len(code) == 0 {
+			continue
+		}
+		codes[crypto.Keccak256Hash(code)] = libcommon.BytesToAddress(addr)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+	}
+
+	c.mu.Lock()
+	for hash, addr := range codes {
+		c.codes[hash] = addr
+	}
+	c.txNum = toTxNum
+	total := len(c.codes)
+	c.mu.Unlock()
+	if fromTxNum == 0 {
+		c.logger.Info("[snap] Indexed contract code", "codes", total, "took", time.Since(start))
+	}
+	return nil
+}
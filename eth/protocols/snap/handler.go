@@ -0,0 +1,600 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/erigontech/erigon-lib/commitment"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/trie"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+var maxHash = libcommon.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+
+// Handler serves snap requests from the flat state and the commitment trie. Leaves are
+// enumerated in hashed key order by walking the commitment branches and range proofs
+// are built from the commitment witness.
+//
+// The states of the last stateHistory blocks can be served, older ones are rebuilt
+// by unwinding the state changesets in memory. Opened states are kept for subsequent
+// requests of the same root. Requests for any other root get empty responses, which
+// syncing peers treat as the state being unavailable.
+type Handler struct {
+	db          kv.TemporalRoDB
+	blockReader services.FullBlockReader
+	tmpdir      string
+	codes       *codeIndex
+	logger      log.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	views map[libcommon.Hash]*stateWorker
+
+	opening sync.Mutex // guards recent
+	recent  struct {
+		head  uint64
+		roots map[libcommon.Hash]uint64 // state root -> block number
+	}
+}
+
+// NewHandler creates the handler and starts indexing the contract code in the
+// background. Close stops it.
+func NewHandler(db kv.TemporalRoDB, blockReader services.FullBlockReader, tmpdir string, logger log.Logger) *Handler {
+	ctx, cancel := context.WithCancel(context.Background())
+	txNums := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, blockReader))
+	h := &Handler{
+		db:          db,
+		blockReader: blockReader,
+		tmpdir:      tmpdir,
+		codes:       newCodeIndex(db, txNums, logger),
+		logger:      logger,
+		ctx:         ctx,
+		cancel:      cancel,
+		views:       make(map[libcommon.Hash]*stateWorker),
+	}
+	go h.codes.run(ctx)
+	return h
+}
+
+// HandleMessage serves a request of a peer and returns the response message. The
+// node doesn't snap sync itself, so responses sent by peers are ignored and nil is
+// returned for them. Errors are returned only for malformed requests.
+func (h *Handler) HandleMessage(ctx context.Context, code uint64, data []byte) (uint64, []byte, error) {
+	var (
+		reply any
+		err   error
+	)
+	switch code {
+	case GetAccountRangeMsg:
+		var req GetAccountRangePacket
+		if err := rlp.DecodeBytes(data, &req); err != nil {
+			return 0, nil, fmt.Errorf("decoding GetAccountRange: %w", err)
+		}
+		code = AccountRangeMsg
+		if reply, err = h.serveAccountRange(ctx, &req); err != nil {
+			reply = &AccountRangePacket{ID: req.ID}
+		}
+	case GetStorageRangesMsg:
+		var req GetStorageRangesPacket
+		if err := rlp.DecodeBytes(data, &req); err != nil {
+			return 0, nil, fmt.Errorf("decoding GetStorageRanges: %w", err)
+		}
+		code = StorageRangesMsg
+		if reply, err = h.serveStorageRanges(ctx, &req); err != nil {
+			reply = &StorageRangesPacket{ID: req.ID}
+		}
+	case GetByteCodesMsg:
+		var req GetByteCodesPacket
+		if err := rlp.DecodeBytes(data, &req); err != nil {
+			return 0, nil, fmt.Errorf("decoding GetByteCodes: %w", err)
+		}
+		code = ByteCodesMsg
+		if reply, err = h.serveByteCodes(ctx, &req); err != nil {
+			reply = &ByteCodesPacket{ID: req.ID}
+		}
+	case GetTrieNodesMsg:
+		var req GetTrieNodesPacket
+		if err := rlp.DecodeBytes(data, &req); err != nil {
+			return 0, nil, fmt.Errorf("decoding GetTrieNodes: %w", err)
+		}
+		code = TrieNodesMsg
+		if reply, err = h.serveTrieNodes(ctx, &req); err != nil {
+			reply = &TrieNodesPacket{ID: req.ID}
+		}
+	case AccountRangeMsg, StorageRangesMsg, ByteCodesMsg, TrieNodesMsg:
+		return 0, nil, nil
+	default:
+		return 0, nil, fmt.Errorf("unknown snap message code %d", code)
+	}
+	if err != nil && !errors.Is(err, errUnavailableRoot) {
+		h.logger.Debug("[snap] failed to serve request", "code", code, "err", err)
+	}
+	resp, err := rlp.EncodeToBytes(reply)
+	if err != nil {
+		return 0, nil, err
+	}
+	return code, resp, nil
+}
+
+// prove returns the deduplicated trie nodes proving the hashed keys. The witness is
+// built for the touched plain keys, which have to cover the paths to the keys.
+func (v *stateView) prove(ctx context.Context, touched [][]byte, keys [][]byte, storage bool) ([][]byte, error) {
+	if len(touched) == 0 {
+		return nil, nil
+	}
+	proofTrie, err := v.witness(ctx, touched)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		proof [][]byte
+		seen  = make(map[string]struct{})
+	)
+	for _, key := range keys {
+		fromLevel := 0
+		if storage {
+			accountProof, err := proofTrie.Prove(key[:length.Hash], 0, false)
+			if err != nil {
+				return nil, err
+			}
+			fromLevel = len(accountProof)
+		}
+		nodes, err := proofTrie.Prove(key, fromLevel, storage)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			if _, ok := seen[string(node)]; !ok {
+				seen[string(node)] = struct{}{}
+				proof = append(proof, node)
+			}
+		}
+	}
+	return proof, nil
+}
+
+func (h *Handler) serveAccountRange(ctx context.Context, req *GetAccountRangePacket) (*AccountRangePacket, error) {
+	resp := &AccountRangePacket{ID: req.ID}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	err := h.withState(ctx, req.Root, func(v *stateView) error {
+		return v.accountRange(ctx, req, resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (v *stateView) accountRange(ctx context.Context, req *GetAccountRangePacket, resp *AccountRangePacket) error {
+	var err error
+
+	var (
+		size    uint64
+		touched [][]byte
+		last    []byte
+	)
+	if req.Origin != (libcommon.Hash{}) {
+		// the predecessor of the origin covers the origin path if no served account does
+		err = v.trie.WalkAccountsReverse(req.Origin[:], func(_, plainKey, _ []byte) (bool, error) {
+			touched = append(touched, libcommon.Copy(plainKey))
+			return false, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	err = v.trie.WalkAccounts(req.Origin[:], func(hashedKey, plainKey, storageRoot []byte) (bool, error) {
+		body, err := v.slimAccount(plainKey, storageRoot)
+		if err != nil {
+			return false, err
+		}
+		size += uint64(length.Hash + len(body))
+		resp.Accounts = append(resp.Accounts, &AccountData{Hash: libcommon.BytesToHash(hashedKey), Body: body})
+		if last == nil {
+			touched = append(touched, libcommon.Copy(plainKey))
+		}
+		last = libcommon.Copy(plainKey)
+		return bytes.Compare(hashedKey, req.Limit[:]) < 0 && size <= req.Bytes, nil
+	})
+	if err != nil {
+		return err
+	}
+	keys := [][]byte{req.Origin[:]}
+	if len(resp.Accounts) > 0 {
+		touched = append(touched, last)
+		keys = append(keys, resp.Accounts[len(resp.Accounts)-1].Hash[:])
+	}
+	if resp.Proof, err = v.prove(ctx, touched, keys, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+// slimAccount returns the account body in the slim format.
+func (v *stateView) slimAccount(plainKey, storageRoot []byte) (rlp.RawValue, error) {
+	enc, _, err := v.domains.GetLatest(kv.AccountsDomain, plainKey)
+	if err != nil {
+		return nil, err
+	}
+	var acc accounts.Account
+	if err := accounts.DeserialiseV3(&acc, enc); err != nil {
+		return nil, fmt.Errorf("account %x: %w", plainKey, err)
+	}
+	slim := slimAccount{Nonce: acc.Nonce, Balance: &acc.Balance}
+	if !bytes.Equal(storageRoot, trie.EmptyRoot[:]) {
+		slim.Root = storageRoot
+	}
+	if !acc.IsEmptyCodeHash() {
+		slim.CodeHash = acc.CodeHash[:]
+	}
+	return rlp.EncodeToBytes(&slim)
+}
+
+func (h *Handler) serveStorageRanges(ctx context.Context, req *GetStorageRangesPacket) (*StorageRangesPacket, error) {
+	resp := &StorageRangesPacket{ID: req.ID}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	err := h.withState(ctx, req.Root, func(v *stateView) error {
+		return v.storageRanges(ctx, req, resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (v *stateView) storageRanges(ctx context.Context, req *GetStorageRangesPacket, resp *StorageRangesPacket) error {
+	var err error
+
+	// Storage of the whole account is served even if the limit is exceeded a bit, to
+	// avoid breaking up contracts into multiple responses which need proofs
+	hardLimit := uint64(float64(req.Bytes) * (1 + stateLookupSlack))
+	var size uint64
+	for i, account := range req.Accounts {
+		if size >= req.Bytes {
+			break
+		}
+		// origin and limit apply to the first account only
+		var origin libcommon.Hash
+		limit := maxHash
+		if i == 0 {
+			if len(req.Origin) > 0 {
+				origin = libcommon.BytesToHash(req.Origin)
+			}
+			if len(req.Limit) > 0 {
+				limit = libcommon.BytesToHash(req.Limit)
+			}
+		}
+		var (
+			slots   []*StorageData
+			touched [][]byte
+			last    []byte
+			abort   bool
+		)
+		err = v.trie.WalkStorage(account[:], origin[:], func(hashedKey, plainKey, _ []byte) (bool, error) {
+			if size >= hardLimit {
+				abort = true
+				return false, nil
+			}
+			value, _, err := v.domains.GetLatest(kv.StorageDomain, plainKey)
+			if err != nil {
+				return false, err
+			}
+			body, err := rlp.EncodeToBytes(value)
+			if err != nil {
+				return false, err
+			}
+			size += uint64(length.Hash + len(body))
+			slots = append(slots, &StorageData{Hash: libcommon.BytesToHash(hashedKey), Body: body})
+			if last == nil {
+				touched = append(touched, libcommon.Copy(plainKey))
+			}
+			last = libcommon.Copy(plainKey)
+			return bytes.Compare(hashedKey, limit[:]) < 0, nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(slots) > 0 {
+			resp.Slots = append(resp.Slots, slots)
+		}
+		// Proofs are needed only if the storage of the account isn't served entirely
+		if origin == (libcommon.Hash{}) && !(abort && len(slots) > 0) {
+			continue
+		}
+		if origin != (libcommon.Hash{}) {
+			err = v.trie.WalkStorageReverse(account[:], origin[:], func(_, plainKey, _ []byte) (bool, error) {
+				touched = append(touched, libcommon.Copy(plainKey))
+				return false, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		keys := [][]byte{append(libcommon.Copy(account[:]), origin[:]...)}
+		if len(slots) > 0 {
+			touched = append(touched, last)
+			keys = append(keys, append(libcommon.Copy(account[:]), slots[len(slots)-1].Hash[:]...))
+		}
+		if len(touched) > 0 {
+			touched = append(touched, touched[0][:length.Addr])
+		}
+		if resp.Proof, err = v.prove(ctx, touched, keys, true); err != nil {
+			return err
+		}
+		break
+	}
+	return nil
+}
+
+func (h *Handler) serveByteCodes(ctx context.Context, req *GetByteCodesPacket) (*ByteCodesPacket, error) {
+	resp := &ByteCodesPacket{ID: req.ID}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	if len(req.Hashes) > maxCodeLookups {
+		req.Hashes = req.Hashes[:maxCodeLookups]
+	}
+	tx, err := h.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var size uint64
+	for _, hash := range req.Hashes {
+		if accounts.IsEmptyCodeHash(hash) {
+			// Peers should not request the empty code, but if they do, at least
+			// send them back a correct response without db lookups
+			resp.Codes = append(resp.Codes, []byte{})
+			continue
+		}
+		addr, ok := h.codes.get(hash)
+		if !ok {
+			continue
+		}
+		code, _, err := tx.GetLatest(kv.CodeDomain, addr[:])
+		if err != nil {
+			return nil, err
+		}
+		if crypto.Keccak256Hash(code) != hash {
+			// the account code has changed since it was indexed
+			h.codes.remove(hash)
+			continue
+		}
+		resp.Codes = append(resp.Codes, code)
+		if size += uint64(len(code)); size > req.Bytes {
+			break
+		}
+	}
+	return resp, nil
+}
+
+func (h *Handler) serveTrieNodes(ctx context.Context, req *GetTrieNodesPacket) (*TrieNodesPacket, error) {
+	resp := &TrieNodesPacket{ID: req.ID}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	err := h.withState(ctx, req.Root, func(v *stateView) error {
+		return v.trieNodes(ctx, req, resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (v *stateView) trieNodes(ctx context.Context, req *GetTrieNodesPacket, resp *TrieNodesPacket) error {
+	var err error
+
+	// Every requested node is looked up on the proof of a leaf below it, so first the
+	// leaves are found and touched, then the nodes are taken from a single witness
+	type lookup struct {
+		path    []byte // nibbles of the node path
+		key     []byte // hashed key of the leaf below the node
+		storage bool
+	}
+	var (
+		lookups []lookup
+		touched [][]byte
+	)
+	firstLeaf := func(walk func(from []byte, fn commitment.LeafFunc) error, path []byte) (hashedKey, plainKey []byte, err error) {
+		err = walk(nibblesToKey(path), func(k, pk, _ []byte) (bool, error) {
+			if bytes.HasPrefix(keyToNibbles(k), path) {
+				hashedKey, plainKey = libcommon.Copy(k), libcommon.Copy(pk)
+			}
+			return false, nil
+		})
+		return hashedKey, plainKey, err
+	}
+loop:
+	for _, pathSet := range req.Paths {
+		if len(pathSet) == 0 {
+			break
+		}
+		if len(pathSet) == 1 {
+			if len(lookups) >= maxTrieNodeLookups {
+				break
+			}
+			path := compactToNibbles(pathSet[0])
+			hashedKey, plainKey, err := firstLeaf(v.trie.WalkAccounts, path)
+			if err != nil {
+				return err
+			}
+			if hashedKey == nil {
+				break
+			}
+			lookups = append(lookups, lookup{path: path, key: hashedKey})
+			touched = append(touched, plainKey)
+			continue
+		}
+		if len(pathSet[0]) != length.Hash {
+			break
+		}
+		account := pathSet[0]
+		_, accountKey, err := firstLeaf(v.trie.WalkAccounts, keyToNibbles(account))
+		if err != nil {
+			return err
+		}
+		if accountKey == nil {
+			break
+		}
+		touched = append(touched, accountKey)
+		walkStorage := func(from []byte, fn commitment.LeafFunc) error { return v.trie.WalkStorage(account, from, fn) }
+		for _, compact := range pathSet[1:] {
+			if len(lookups) >= maxTrieNodeLookups {
+				break loop
+			}
+			path := compactToNibbles(compact)
+			hashedKey, plainKey, err := firstLeaf(walkStorage, path)
+			if err != nil {
+				return err
+			}
+			if hashedKey == nil {
+				break loop
+			}
+			lookups = append(lookups, lookup{path: path, key: append(libcommon.Copy(account), hashedKey...), storage: true})
+			touched = append(touched, plainKey)
+		}
+	}
+	if len(lookups) == 0 {
+		return nil
+	}
+	proofTrie, err := v.witness(ctx, touched)
+	if err != nil {
+		return err
+	}
+	var size uint64
+	for _, l := range lookups {
+		fromLevel := 0
+		if l.storage {
+			accountProof, err := proofTrie.Prove(l.key[:length.Hash], 0, false)
+			if err != nil {
+				return err
+			}
+			fromLevel = len(accountProof)
+		}
+		proof, err := proofTrie.Prove(l.key, fromLevel, l.storage)
+		if err != nil {
+			return err
+		}
+		node := nodeAtPath(proof, l.path)
+		if node == nil {
+			break
+		}
+		resp.Nodes = append(resp.Nodes, node)
+		if size += uint64(len(node)); size > req.Bytes {
+			break
+		}
+	}
+	return nil
+}
+
+// nodeAtPath returns the node of the proof which is located at the path, nil if the
+// path points into a node.
+func nodeAtPath(proof [][]byte, path []byte) []byte {
+	depth := 0
+	for _, node := range proof {
+		if depth == len(path) {
+			return node
+		}
+		n, err := nodeNibbles(node)
+		if err != nil {
+			return nil
+		}
+		if depth += n; depth > len(path) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// nodeNibbles returns the number of key nibbles consumed by the encoded trie node.
+func nodeNibbles(node []byte) (int, error) {
+	content, _, err := rlp.SplitList(node)
+	if err != nil {
+		return 0, err
+	}
+	items, err := rlp.CountValues(content)
+	if err != nil {
+		return 0, err
+	}
+	switch items {
+	case 17:
+		return 1, nil
+	case 2:
+		key, _, err := rlp.SplitString(content)
+		if err != nil {
+			return 0, err
+		}
+		return len(compactToNibbles(key)), nil
+	default:
+		return 0, fmt.Errorf("invalid trie node with %d items", items)
+	}
+}
+
+// compactToNibbles decodes the hex prefix encoded path, the terminator flag is dropped.
+func compactToNibbles(compact []byte) []byte {
+	if len(compact) == 0 {
+		return nil
+	}
+	nibbles := keyToNibbles(compact)
+	if nibbles[0]&1 == 1 { // odd length, the first nibble holds only the flags
+		return nibbles[1:]
+	}
+	return nibbles[2:]
+}
+
+func keyToNibbles(key []byte) []byte {
+	nibbles := make([]byte, 0, len(key)*2)
+	for _, b := range key {
+		nibbles = append(nibbles, b>>4, b&0x0f)
+	}
+	return nibbles
+}
+
+// nibblesToKey returns the lowest hashed key with given nibbles prefix.
+func nibblesToKey(nibbles []byte) []byte {
+	key := make([]byte, length.Hash)
+	for i, n := range nibbles[:min(len(nibbles), 2*length.Hash)] {
+		if i%2 == 0 {
+			key[i/2] = n << 4
+		} else {
+			key[i/2] |= n
+		}
+	}
+	return key
+}
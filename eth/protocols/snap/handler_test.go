@@ -0,0 +1,398 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/trie"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/stages/mock"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+
+	contractAddr = libcommon.HexToAddress("0x00000000000000000000000000000000000c0de1")
+	contractCode = []byte{0x60, 0x01, 0x60, 0x00, 0x55, 0x00}
+
+	// deployedCode is created in the second block by deployedInit
+	deployedAddr = crypto.CreateAddress(testAddr, 1)
+	deployedCode = []byte{0x60, 0x02, 0x60, 0x00, 0x55, 0x00}
+	deployedInit = append(append([]byte{0x65}, deployedCode...), 0x60, 0x00, 0x52, 0x60, 0x06, 0x60, 0x1a, 0xf3)
+)
+
+type snapBackend struct {
+	*mock.MockSentry
+	handler *Handler
+	root    libcommon.Hash
+	chain   *core.ChainPack
+}
+
+func newSnapBackend(t *testing.T) *snapBackend {
+	alloc := types.GenesisAlloc{testAddr: {Balance: big.NewInt(1_000_000_000)}}
+	for i := 1; i <= 200; i++ {
+		alloc[libcommon.BigToAddress(big.NewInt(int64(i)))] = types.GenesisAccount{Balance: big.NewInt(int64(i)), Nonce: uint64(i % 3)}
+	}
+	storage := make(map[libcommon.Hash]libcommon.Hash)
+	for i := 1; i <= 100; i++ {
+		storage[libcommon.BigToHash(big.NewInt(int64(i)))] = libcommon.BigToHash(big.NewInt(int64(1000 + i)))
+	}
+	alloc[contractAddr] = types.GenesisAccount{Balance: big.NewInt(1), Code: contractCode, Storage: storage}
+
+	m := mock.MockWithGenesis(t, &types.Genesis{Config: params.TestChainConfig, Alloc: alloc}, testKey, false)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, func(i int, b *core.BlockGen) {
+		var txn types.Transaction = types.NewTransaction(b.TxNonce(testAddr), libcommon.Address{0xaa}, uint256.NewInt(1000), 21000, uint256.NewInt(1), nil)
+		if i == 1 {
+			txn = types.NewContractCreation(b.TxNonce(testAddr), uint256.NewInt(0), 100_000, uint256.NewInt(1), deployedInit)
+		}
+		signed, err := types.SignTx(txn, *types.LatestSignerForChainID(m.ChainConfig.ChainID), testKey)
+		require.NoError(t, err)
+		b.AddTx(signed)
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	handler := NewHandler(m.DB, m.BlockReader, m.Dirs.Tmp, log.New())
+	t.Cleanup(handler.Close)
+	return &snapBackend{
+		MockSentry: m,
+		handler:    handler,
+		root:       chain.TopBlock.Root(),
+		chain:      chain,
+	}
+}
+
+func (b *snapBackend) request(t *testing.T, code uint64, req any, resp any) {
+	t.Helper()
+	data, err := rlp.EncodeToBytes(req)
+	require.NoError(t, err)
+	_, out, err := b.handler.HandleMessage(context.Background(), code, data)
+	require.NoError(t, err)
+	require.NoError(t, rlp.DecodeBytes(out, resp))
+}
+
+// verifyProof returns the value stored under the key in the trie with given root,
+// nil if the proof shows there is no such key.
+func verifyProof(root libcommon.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	nodes := make(map[libcommon.Hash][]byte, len(proof))
+	for _, node := range proof {
+		nodes[crypto.Keccak256Hash(node)] = node
+	}
+	node, ok := nodes[root]
+	if !ok {
+		return nil, errors.New("root node is missing")
+	}
+	path := keyToNibbles(key)
+	for {
+		content, _, err := rlp.SplitList(node)
+		if err != nil {
+			return nil, err
+		}
+		items, err := rlp.CountValues(content)
+		if err != nil {
+			return nil, err
+		}
+		var child []byte
+		switch items {
+		case 17:
+			for i := byte(0); i <= path[0]; i++ {
+				_, val, rest, err := rlp.Split(content)
+				if err != nil {
+					return nil, err
+				}
+				if i == path[0] {
+					child = content[:len(content)-len(rest)]
+				}
+				_ = val
+				content = rest
+			}
+			path = path[1:]
+		case 2:
+			compact, rest, err := rlp.SplitString(content)
+			if err != nil {
+				return nil, err
+			}
+			nodeKey := compactToNibbles(compact)
+			if !bytes.HasPrefix(path, nodeKey) {
+				return nil, nil
+			}
+			path = path[len(nodeKey):]
+			if compact[0]&0x20 != 0 { // leaf
+				val, _, err := rlp.SplitString(rest)
+				return val, err
+			}
+			child = rest
+		default:
+			return nil, fmt.Errorf("invalid node with %d items", items)
+		}
+		kind, val, _, err := rlp.Split(child)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case kind == rlp.List:
+			node = child
+		case len(val) == 0:
+			return nil, nil
+		default:
+			if node, ok = nodes[libcommon.BytesToHash(val)]; !ok {
+				return nil, fmt.Errorf("node %x is missing", val)
+			}
+		}
+	}
+}
+
+// fullAccount converts the slim account body to the one stored in the trie.
+func fullAccount(t *testing.T, body []byte) (libcommon.Hash, []byte) {
+	var slim slimAccount
+	require.NoError(t, rlp.DecodeBytes(body, &slim))
+	root, codeHash := trie.EmptyRoot, crypto.Keccak256Hash(nil)
+	if len(slim.Root) > 0 {
+		root = libcommon.BytesToHash(slim.Root)
+	}
+	if len(slim.CodeHash) > 0 {
+		codeHash = libcommon.BytesToHash(slim.CodeHash)
+	}
+	enc, err := rlp.EncodeToBytes([]any{slim.Nonce, slim.Balance, root, codeHash})
+	require.NoError(t, err)
+	return root, enc
+}
+
+func TestAccountRange(t *testing.T) {
+	b := newSnapBackend(t)
+
+	var (
+		origin   libcommon.Hash
+		accounts []*AccountData
+	)
+	for i := 0; ; i++ {
+		var resp AccountRangePacket
+		b.request(t, GetAccountRangeMsg, &GetAccountRangePacket{ID: uint64(i), Root: b.root, Origin: origin, Limit: maxHash, Bytes: 1000}, &resp)
+		require.Equal(t, uint64(i), resp.ID)
+		require.NotEmpty(t, resp.Proof)
+
+		// origin is proven absent or present and the last account is proven to hold the body
+		_, err := verifyProof(b.root, origin[:], resp.Proof)
+		require.NoError(t, err)
+		if len(resp.Accounts) == 0 {
+			break
+		}
+		last := resp.Accounts[len(resp.Accounts)-1]
+		value, err := verifyProof(b.root, last.Hash[:], resp.Proof)
+		require.NoError(t, err)
+		_, expected := fullAccount(t, last.Body)
+		require.Equal(t, expected, value)
+
+		accounts = append(accounts, resp.Accounts...)
+		next := new(big.Int).Add(last.Hash.Big(), big.NewInt(1))
+		if next.BitLen() > 256 {
+			break
+		}
+		origin = libcommon.BigToHash(next)
+	}
+
+	var want []libcommon.Hash
+	for _, addr := range []libcommon.Address{testAddr, contractAddr, deployedAddr, {0xaa}, b.Genesis.Coinbase()} {
+		want = append(want, crypto.Keccak256Hash(addr[:]))
+	}
+	for i := 1; i <= 200; i++ {
+		want = append(want, crypto.Keccak256Hash(libcommon.BigToAddress(big.NewInt(int64(i))).Bytes()))
+	}
+	sort.Slice(want, func(i, j int) bool { return bytes.Compare(want[i][:], want[j][:]) < 0 })
+	var got []libcommon.Hash
+	for _, acc := range accounts {
+		got = append(got, acc.Hash)
+	}
+	require.Equal(t, want, got)
+
+	// unknown root is not served
+	var resp AccountRangePacket
+	b.request(t, GetAccountRangeMsg, &GetAccountRangePacket{ID: 1, Root: libcommon.Hash{1}, Limit: maxHash, Bytes: 1000}, &resp)
+	require.Empty(t, resp.Accounts)
+	require.Empty(t, resp.Proof)
+}
+
+func contractAccount(t *testing.T, b *snapBackend) (libcommon.Hash, libcommon.Hash) {
+	hash := crypto.Keccak256Hash(contractAddr[:])
+	var resp AccountRangePacket
+	b.request(t, GetAccountRangeMsg, &GetAccountRangePacket{Root: b.root, Origin: hash, Limit: hash, Bytes: 1}, &resp)
+	require.Len(t, resp.Accounts, 1)
+	require.Equal(t, hash, resp.Accounts[0].Hash)
+	root, _ := fullAccount(t, resp.Accounts[0].Body)
+	return hash, root
+}
+
+func TestStorageRanges(t *testing.T) {
+	b := newSnapBackend(t)
+	account, storageRoot := contractAccount(t, b)
+
+	// whole storage fits into the response and needs no proof
+	var resp StorageRangesPacket
+	b.request(t, GetStorageRangesMsg, &GetStorageRangesPacket{ID: 7, Root: b.root, Accounts: []libcommon.Hash{account, crypto.Keccak256Hash(testAddr[:])}, Bytes: softResponseLimit}, &resp)
+	require.Equal(t, uint64(7), resp.ID)
+	require.Len(t, resp.Slots, 1)
+	require.Len(t, resp.Slots[0], 100)
+	require.Empty(t, resp.Proof)
+	for i := 1; i < len(resp.Slots[0]); i++ {
+		require.Negative(t, bytes.Compare(resp.Slots[0][i-1].Hash[:], resp.Slots[0][i].Hash[:]))
+	}
+	all := resp.Slots[0]
+
+	// range from the middle is capped by the size and proven
+	origin := all[40].Hash
+	resp = StorageRangesPacket{}
+	b.request(t, GetStorageRangesMsg, &GetStorageRangesPacket{Root: b.root, Accounts: []libcommon.Hash{account}, Origin: origin[:], Bytes: 500}, &resp)
+	require.Len(t, resp.Slots, 1)
+	require.Less(t, len(resp.Slots[0]), 60)
+	require.Equal(t, all[40:40+len(resp.Slots[0])], resp.Slots[0])
+	require.NotEmpty(t, resp.Proof)
+
+	last := resp.Slots[0][len(resp.Slots[0])-1]
+	value, err := verifyProof(storageRoot, last.Hash[:], resp.Proof)
+	require.NoError(t, err)
+	require.Equal(t, last.Body, value)
+
+	// origin in between of the slots is proven absent
+	between := libcommon.BigToHash(new(big.Int).Add(all[40].Hash.Big(), big.NewInt(1)))
+	resp = StorageRangesPacket{}
+	b.request(t, GetStorageRangesMsg, &GetStorageRangesPacket{Root: b.root, Accounts: []libcommon.Hash{account}, Origin: between[:], Bytes: 500}, &resp)
+	require.Equal(t, all[41], resp.Slots[0][0])
+	value, err = verifyProof(storageRoot, between[:], resp.Proof)
+	require.NoError(t, err)
+	require.Nil(t, value)
+}
+
+func TestByteCodes(t *testing.T) {
+	b := newSnapBackend(t)
+	require.NoError(t, b.handler.codes.update(context.Background()))
+
+	// code is served without the accounts holding it being served first
+	var resp ByteCodesPacket
+	hashes := []libcommon.Hash{{1}, crypto.Keccak256Hash(contractCode), crypto.Keccak256Hash(deployedCode)}
+	b.request(t, GetByteCodesMsg, &GetByteCodesPacket{ID: 3, Hashes: hashes, Bytes: softResponseLimit}, &resp)
+	require.Equal(t, uint64(3), resp.ID)
+	require.Equal(t, [][]byte{contractCode, deployedCode}, resp.Codes)
+}
+
+func TestCodeIndex(t *testing.T) {
+	b := newSnapBackend(t)
+	ctx := context.Background()
+	tx, err := b.DB.BeginTemporalRo(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	afterFirst, err := rawdbv3.TxNums.Max(tx, 1)
+	require.NoError(t, err)
+	tx.Rollback()
+
+	// code deployed after the initial scan is picked up from the history
+	c := newCodeIndex(b.DB, rawdbv3.TxNums, log.New())
+	c.txNum = afterFirst + 1
+	require.NoError(t, c.update(ctx))
+	addr, ok := c.get(crypto.Keccak256Hash(deployedCode))
+	require.True(t, ok)
+	require.Equal(t, deployedAddr, addr)
+	_, ok = c.get(crypto.Keccak256Hash(contractCode))
+	require.False(t, ok)
+
+	// the initial scan indexes all the code
+	c = newCodeIndex(b.DB, rawdbv3.TxNums, log.New())
+	require.NoError(t, c.update(ctx))
+	for _, code := range [][]byte{contractCode, deployedCode} {
+		_, ok = c.get(crypto.Keccak256Hash(code))
+		require.True(t, ok)
+	}
+}
+
+func TestHistoricalRoots(t *testing.T) {
+	b := newSnapBackend(t)
+	deployedHash := crypto.Keccak256Hash(deployedAddr[:])
+	roots := []libcommon.Hash{b.Genesis.Root(), b.chain.Blocks[0].Root(), b.root}
+
+	for i, root := range roots {
+		var resp AccountRangePacket
+		b.request(t, GetAccountRangeMsg, &GetAccountRangePacket{Root: root, Origin: deployedHash, Limit: deployedHash, Bytes: 1}, &resp)
+		require.NotEmpty(t, resp.Proof)
+		value, err := verifyProof(root, deployedHash[:], resp.Proof)
+		require.NoError(t, err)
+		if i < 2 {
+			// the contract is deployed in the last block
+			require.Nil(t, value)
+			require.NotEqual(t, deployedHash, resp.Accounts[0].Hash)
+			continue
+		}
+		require.Equal(t, deployedHash, resp.Accounts[0].Hash)
+		_, expected := fullAccount(t, resp.Accounts[0].Body)
+		require.Equal(t, expected, value)
+	}
+
+	// opened states are reused
+	b.handler.mu.Lock()
+	require.Len(t, b.handler.views, len(roots))
+	b.handler.mu.Unlock()
+	var resp AccountRangePacket
+	b.request(t, GetAccountRangeMsg, &GetAccountRangePacket{Root: roots[0], Limit: maxHash, Bytes: 1}, &resp)
+	require.NotEmpty(t, resp.Accounts)
+	b.handler.mu.Lock()
+	require.Len(t, b.handler.views, len(roots))
+	b.handler.mu.Unlock()
+}
+
+func TestTrieNodes(t *testing.T) {
+	b := newSnapBackend(t)
+	account, storageRoot := contractAccount(t, b)
+
+	var resp TrieNodesPacket
+	b.request(t, GetTrieNodesMsg, &GetTrieNodesPacket{
+		ID:   5,
+		Root: b.root,
+		Paths: []TrieNodePathSet{
+			{{0x00}},                   // account trie root
+			{{0x11}},                   // account trie node at nibble 1
+			{account[:], {0x00}},       // storage trie root of the contract
+			{account[:], {0x00, 0x00}}, // absent storage node stops the response
+			{{0x12}},
+		},
+		Bytes: softResponseLimit,
+	}, &resp)
+	require.Equal(t, uint64(5), resp.ID)
+	require.Len(t, resp.Nodes, 3)
+	require.Equal(t, b.root, crypto.Keccak256Hash(resp.Nodes[0]))
+	require.Equal(t, storageRoot, crypto.Keccak256Hash(resp.Nodes[2]))
+
+	// the node at nibble 1 is referenced by the root
+	_, _, err := rlp.SplitList(resp.Nodes[1])
+	require.NoError(t, err)
+	require.Contains(t, string(resp.Nodes[0]), string(crypto.Keccak256(resp.Nodes[1])))
+}
@@ -0,0 +1,346 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erigontech/erigon-lib/commitment"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/membatchwithdb"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	libstate "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon-lib/trie"
+	"github.com/erigontech/erigon/eth/stagedsync/stages"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+const (
+	// stateHistory is the number of recent blocks whose state roots are served.
+	stateHistory = 128
+
+	// maxStateViews is the number of states kept open for serving, the least
+	// recently opened one is closed when another state is requested.
+	maxStateViews = 4
+
+	// stateViewLifetime bounds how long a state view holds its read transaction.
+	stateViewLifetime = time.Minute
+)
+
+var errUnavailableRoot = errors.New("state root is not available")
+
+// stateView is a state the requests are served from, either the latest one or a
+// recent one rebuilt by unwinding the state changesets in memory.
+type stateView struct {
+	tx      kv.TemporalTx
+	batch   *membatchwithdb.MemoryMutation // unwound state, nil for the latest state
+	domains *libstate.SharedDomains
+	trie    *commitment.HexPatriciaHashed
+	root    libcommon.Hash
+	tmpdir  string
+}
+
+// openState opens the state with the given root if it belongs to one of the recent
+// canonical blocks.
+func (h *Handler) openState(ctx context.Context, root libcommon.Hash) (*stateView, error) {
+	tx, err := h.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v := &stateView{tx: tx, tmpdir: h.tmpdir}
+	blockNum, ok, err := h.findRoot(ctx, tx, root)
+	if err != nil {
+		v.Close()
+		return nil, err
+	}
+	if !ok {
+		v.Close()
+		return nil, errUnavailableRoot
+	}
+	var stateTx kv.Tx = tx
+	if blockNum < h.recent.head {
+		v.batch = membatchwithdb.NewMemoryBatch(tx, h.tmpdir, h.logger)
+		if err := h.unwind(ctx, v.batch, blockNum, h.recent.head); err != nil {
+			v.Close()
+			return nil, err
+		}
+		stateTx = v.batch
+	}
+	if v.domains, err = libstate.NewSharedDomains(stateTx, h.logger); err != nil {
+		v.Close()
+		return nil, err
+	}
+	hph, ok := v.domains.GetCommitmentContext().Trie().(*commitment.HexPatriciaHashed)
+	if !ok {
+		v.Close()
+		return nil, errors.New("commitment is not a hex patricia trie")
+	}
+	v.trie = hph
+	rootHash, err := hph.RootHash()
+	if err != nil {
+		v.Close()
+		return nil, err
+	}
+	if v.root = libcommon.BytesToHash(rootHash); v.root != root {
+		// the state isn't at the head block yet, e.g. the execution is in progress
+		v.Close()
+		return nil, errUnavailableRoot
+	}
+	return v, nil
+}
+
+// findRoot returns the number of the most recent canonical block with the given state
+// root, looking back stateHistory blocks from the executed head.
+func (h *Handler) findRoot(ctx context.Context, tx kv.Tx, root libcommon.Hash) (uint64, bool, error) {
+	head, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
+		return 0, false, err
+	}
+	if h.recent.roots == nil || h.recent.head != head {
+		roots := make(map[libcommon.Hash]uint64, stateHistory)
+		for i := uint64(0); i < stateHistory && i <= head; i++ {
+			header, err := h.blockReader.HeaderByNumber(ctx, tx, head-i)
+			if err != nil {
+				return 0, false, err
+			}
+			if header == nil {
+				break
+			}
+			if _, ok := roots[header.Root]; !ok {
+				roots[header.Root] = head - i
+			}
+		}
+		h.recent.head, h.recent.roots = head, roots
+	}
+	blockNum, ok := h.recent.roots[root]
+	return blockNum, ok, nil
+}
+
+// unwind reverts the state in the batch from the head to the given block using the
+// changesets of the blocks in between.
+func (h *Handler) unwind(ctx context.Context, batch *membatchwithdb.MemoryMutation, blockNum, head uint64) error {
+	var changeset [kv.DomainLen][]libstate.DomainEntryDiff
+	for n := head; n > blockNum; n-- {
+		hash, ok, err := h.blockReader.CanonicalHash(ctx, batch, n)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("canonical hash not found %d", n)
+		}
+		diffs, ok, err := libstate.ReadDiffSet(batch, n, hash)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// changesets are pruned, the state can't be rebuilt
+			return errUnavailableRoot
+		}
+		for i := range diffs {
+			changeset[i] = libstate.MergeDiffSets(changeset[i], diffs[i])
+		}
+	}
+	txNum, err := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, h.blockReader)).Min(batch, blockNum+1)
+	if err != nil {
+		return err
+	}
+	domains, err := libstate.NewSharedDomains(batch, h.logger)
+	if err != nil {
+		return err
+	}
+	defer domains.Close()
+	return domains.Unwind(ctx, batch, blockNum, txNum, &changeset)
+}
+
+func (v *stateView) Close() {
+	if v.domains != nil {
+		v.domains.Close()
+	}
+	if v.batch != nil {
+		v.batch.Rollback()
+	}
+	v.tx.Rollback()
+}
+
+// witness returns the trie of the state with the paths to the touched plain keys.
+func (v *stateView) witness(ctx context.Context, touched [][]byte) (*trie.Trie, error) {
+	updates := commitment.NewUpdates(commitment.ModeDirect, v.tmpdir, commitment.KeyToHexNibbleHash)
+	defer updates.Close()
+	for _, plainKey := range touched {
+		if len(plainKey) == length.Addr {
+			updates.TouchPlainKey(string(plainKey), nil, updates.TouchAccount)
+		} else {
+			updates.TouchPlainKey(string(plainKey), nil, updates.TouchStorage)
+		}
+	}
+	proofTrie, _, err := v.trie.GenerateWitness(ctx, updates, nil, v.root[:], "snap")
+	return proofTrie, err
+}
+
+// stateWorker owns a state view and runs the requests against it. The view is used
+// from a single goroutine because the in-memory batch of an unwound state holds a
+// write transaction, which is bound to the thread it was started on.
+type stateWorker struct {
+	root   libcommon.Hash
+	opened time.Time
+	jobs   chan stateJob
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+type stateJob struct {
+	fn     func(v *stateView) error
+	result chan error
+}
+
+// withState runs fn against the state with the given root, opening the state if it
+// isn't open yet.
+func (h *Handler) withState(ctx context.Context, root libcommon.Hash, fn func(v *stateView) error) error {
+	for {
+		w, err := h.stateWorker(ctx, root)
+		if err != nil {
+			return err
+		}
+		job := stateJob{fn: fn, result: make(chan error, 1)}
+		select {
+		case w.jobs <- job:
+		case <-w.done:
+			// the view has expired meanwhile
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		select {
+		case err := <-job.result:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (h *Handler) stateWorker(ctx context.Context, root libcommon.Hash) (*stateWorker, error) {
+	h.mu.Lock()
+	w, ok := h.views[root]
+	h.mu.Unlock()
+	if ok {
+		return w, nil
+	}
+
+	// Opening an unwound state is expensive, so states are opened one at a time
+	h.opening.Lock()
+	defer h.opening.Unlock()
+	h.mu.Lock()
+	w, ok = h.views[root]
+	h.mu.Unlock()
+	if ok {
+		return w, nil
+	}
+	w = &stateWorker{
+		root:   root,
+		opened: time.Now(),
+		jobs:   make(chan stateJob),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	opened := make(chan error, 1)
+	go h.runState(w, opened)
+	select {
+	case err := <-opened:
+		if err != nil {
+			return nil, err
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.views) >= maxStateViews {
+		var oldest *stateWorker
+		for _, view := range h.views {
+			if oldest == nil || view.opened.Before(oldest.opened) {
+				oldest = view
+			}
+		}
+		delete(h.views, oldest.root)
+		close(oldest.quit)
+	}
+	h.views[root] = w
+	return w, nil
+}
+
+func (h *Handler) runState(w *stateWorker, opened chan<- error) {
+	defer close(w.done)
+	v, err := h.openState(h.ctx, w.root)
+	opened <- err
+	if err != nil {
+		return
+	}
+	defer v.Close()
+
+	expiry := time.NewTimer(stateViewLifetime)
+	defer expiry.Stop()
+	for {
+		select {
+		case job := <-w.jobs:
+			err := job.fn(v)
+			// ResetBranchCache drops the branches read while serving the request
+			v.domains.GetCommitmentContext().ResetBranchCache()
+			job.result <- err
+			if err != nil && !errors.Is(err, context.Canceled) {
+				// the trie may be left unfolded, the view is opened again on the next request
+				h.dropState(w)
+				return
+			}
+		case <-expiry.C:
+			h.dropState(w)
+			return
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+func (h *Handler) dropState(w *stateWorker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.views[w.root] == w {
+		delete(h.views, w.root)
+	}
+}
+
+// Close closes the open states.
+func (h *Handler) Close() {
+	h.cancel()
+	h.mu.Lock()
+	workers := make([]*stateWorker, 0, len(h.views))
+	for root, w := range h.views {
+		delete(h.views, root)
+		close(w.quit)
+		workers = append(workers, w)
+	}
+	h.mu.Unlock()
+	for _, w := range workers {
+		<-w.done
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/erigontech/erigon-lib/common/cmp"
 	"github.com/erigontech/erigon-lib/common/dbg"
 	"github.com/erigontech/erigon-lib/config3"
+	"github.com/erigontech/erigon-lib/diagnostics"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/log/v3"
@@ -81,11 +82,22 @@ type Progress struct {
 	prevRepeatCount    uint64
 	commitThreshold    uint64
 
+	// execution statistics sent to diagnostics, by one Progress of the stage only
+	sendStatistics bool
+	startBlockNum  uint64
+	maxBlockNum    uint64
+
 	workersCount int
 	logPrefix    string
 	logger       log.Logger
 }
 
+// SendStatistics makes Log send the execution statistics of the stage run, which executes the blocks
+// up to maxBlockNum, to diagnostics.
+func (p *Progress) SendStatistics(maxBlockNum uint64) {
+	p.sendStatistics, p.startBlockNum, p.maxBlockNum = true, p.prevOutputBlockNum, maxBlockNum
+}
+
 func (p *Progress) Log(suffix string, rs *state.StateV3, in *state.QueueWithRetry, rws *state.ResultsQueue, txCount uint64, gas uint64, inputBlockNum uint64, outputBlockNum uint64, outTxNum uint64, repeatCount uint64, idxStepsAmountInDB float64, shouldGenerateChangesets bool, inMemExec bool) {
 	mxExecStepsInDB.Set(idxStepsAmountInDB * 100)
 	var m runtime.MemStats
@@ -127,6 +139,20 @@ func (p *Progress) Log(suffix string, rs *state.StateV3, in *state.QueueWithRetr
 		"alloc", common.ByteCount(m.Alloc), "sys", common.ByteCount(m.Sys),
 	)
 
+	if p.sendStatistics {
+		diagnostics.Send(diagnostics.BlockExecutionStatistics{
+			From:        p.startBlockNum,
+			To:          p.maxBlockNum,
+			BlockNumber: outputBlockNum,
+			BlkPerSec:   float64(diffBlocks) / interval.Seconds(),
+			TxPerSec:    float64(txSec),
+			MgasPerSec:  float64(gasSec) / 1e6,
+			Alloc:       m.Alloc,
+			Sys:         m.Sys,
+			TimeElapsed: interval.Seconds(),
+		})
+	}
+
 	p.prevTime = currentTime
 	p.prevTxCount = txCount
 	p.prevGasUsed = gas
@@ -347,6 +373,8 @@ func ExecV3(ctx context.Context,
 	// TODO are these dups ?
 	processed := NewProgress(blockNum, commitThreshold, workerCount, true, execStage.LogPrefix(), logger)
 	progress := NewProgress(blockNum, commitThreshold, workerCount, false, execStage.LogPrefix(), logger)
+	// the periodic one, so the sync ETA gets a single series of rates
+	progress.SendStatistics(maxBlockNum)
 
 	logEvery := time.NewTicker(20 * time.Second)
 	defer logEvery.Stop()
@@ -405,11 +433,15 @@ func ExecV3(ctx context.Context,
 				inMemExec:      inMemExec,
 				applyTx:        applyTx,
 				applyWorker:    applyWorker,
+				accumulator:    accumulator,
 				outputTxNum:    &outputTxNum,
 				outputBlockNum: stages.SyncMetrics[stages.Execution],
 				logger:         logger,
 			},
 		}
+		if !initialCycle && !isMining && hooks == nil {
+			se.blockSTM = cfg.blockSTM
+		}
 
 		defer func() {
 			processed.Log("Done", executor.readState(), nil, nil, se.txCount, logGas, inputBlockNum.Load(), outputBlockNum.GetValueUint64(), outputTxNum.Load(), mxExecRepeats.GetValueUint64(), stepsInDB, shouldGenerateChangesets, inMemExec)
@@ -655,7 +687,19 @@ Loop:
 
 			se.skipPostEvaluation = skipPostEvaluation
 
+			var prefetched *state.PrefetchTracker
+			if cfg.prefetcher != nil {
+				prefetched = cfg.prefetcher.TakeTracker(b.Hash())
+			}
+			if prefetched != nil {
+				se.setReadObserver(prefetched.Read)
+			}
 			continueLoop, err := se.execute(ctx, txTasks)
+			if prefetched != nil {
+				se.setReadObserver(nil)
+				reads, hits := prefetched.Report()
+				logger.Debug(fmt.Sprintf("[%s] prefetch", execStage.LogPrefix()), "block", blockNum, "reads", reads, "hits", hits)
+			}
 			if b.NumberU64() > 0 && hooks != nil && hooks.OnBlockEnd != nil {
 				hooks.OnBlockEnd(err)
 			}
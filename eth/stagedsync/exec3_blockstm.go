@@ -0,0 +1,490 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/common/dbg"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+	state2 "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/cmd/state/exec3"
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/shards"
+)
+
+/*
+blockSTM - optimistic parallel execution of txns of one block (Block-STM, https://arxiv.org/abs/2203.06871).
+
+- Workers execute txns speculatively: reads go to state.VersionMap (values written by lower txns of the block)
+and fall back to state of the beginning of the block. Writes are not applied, but recorded in VersionMap.
+- After execution txn is validated: all values it read are read again - if any of them changed, txn is
+aborted, its writes are marked as "estimates" and txn is re-executed. Txn which reads an estimate waits
+until writer is re-executed.
+- When all txns are executed and validated - results are applied by serialExecutor in txn order, the same
+way as results of serial execution. So state, receipts and changesets are exactly the same.
+
+RwTx and SharedDomains are not thread-safe: workers don't touch them, reads of base state (and block hashes)
+are served by the goroutine which called `execute`.
+
+If any txn does a state change which VersionMap can't represent (see state.ErrVersionedUnsupported) - block
+is executed serially.
+*/
+type blockSTM struct {
+	workers []*blockSTMWorker
+	minTxs  int
+	logger  log.Logger
+
+	run          *blockSTMRun       // current block
+	readObserver state.ReadObserver // gets reads of base state
+}
+
+type blockSTMWorker struct {
+	*exec3.Worker
+	reader *state.VersionedStateReader
+	writer *state.VersionedStateWriter
+	dirty  bool // IntraBlockState may keep error of aborted execution - must be re-created
+}
+
+func newBlockSTM(workerCount, minTxs int, db kv.RoDB, blockReader services.FullBlockReader, chainConfig *chain.Config, genesis *types.Genesis, engine consensus.Engine, dirs datadir.Dirs, logger log.Logger) *blockSTM {
+	bs := &blockSTM{minTxs: max(minTxs, 2), logger: logger}
+	for i := 0; i < max(workerCount, 2); i++ {
+		w := &blockSTMWorker{
+			Worker: exec3.NewWorker(nil, logger, nil, context.Background(), false, db, nil, blockReader, chainConfig, genesis, nil, engine, dirs, false),
+			reader: state.NewVersionedStateReader(nil, func(key string) ([]byte, error) { return bs.run.base(key) }),
+			writer: state.NewVersionedStateWriter(),
+		}
+		w.ResetVersionedState(w.reader, w.writer)
+		bs.workers = append(bs.workers, w)
+	}
+	return bs
+}
+
+// eligible - Block-STM is used only for plain txns of a block which is executed from beginning
+func (bs *blockSTM) eligible(tasks []*state.TxTask) bool {
+	if len(tasks) < bs.minTxs {
+		return false
+	}
+	for i, t := range tasks {
+		if t.TxIndex != i || t.Final || t.Tx == nil || t.HistoryExecution || t.Error != nil {
+			return false
+		}
+		if t.Tx.Type() == types.AccountAbstractionTxType {
+			return false
+		}
+	}
+	return true
+}
+
+// execute - executes txns of one block (`tasks` starting from first txn, final task is not executed) and fills their results.
+// Returns amount of executed tasks (0 - block must be executed serially) and accumulator notifications of each task.
+func (bs *blockSTM) execute(ctx context.Context, tasks []*state.TxTask, doms *state2.SharedDomains) (executed int, notify [][]func(*shards.Accumulator), err error) {
+	if len(tasks) > 0 && tasks[len(tasks)-1].Final {
+		tasks = tasks[:len(tasks)-1]
+	}
+	if !bs.eligible(tasks) {
+		return 0, nil, nil
+	}
+
+	run := newBlockSTMRun(tasks)
+	bs.run = run
+
+	var wg sync.WaitGroup
+	for _, w := range bs.workers {
+		wg.Add(1)
+		go func(w *blockSTMWorker) {
+			defer wg.Done()
+			run.work(w)
+		}(w)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// serve reads of workers until all of them are done
+	getHash := tasks[0].EvmBlockContext.GetHash
+	for finished := false; !finished; {
+		select {
+		case req := <-run.requests:
+			req.resp <- run.serve(req, doms, getHash, bs.readObserver)
+		case <-ctx.Done():
+			run.fail(ctx.Err())
+			ctx = context.Background() // keep serving reads until workers see the failure
+		case <-done:
+			finished = true
+		}
+	}
+	bs.run = nil
+
+	if run.err != nil {
+		if errors.Is(run.err, state.ErrVersionedUnsupported) || errors.Is(run.err, errBlockSTMPanic) {
+			bs.logger.Debug("[blockstm] fallback to serial execution", "block", tasks[0].BlockNum, "err", run.err)
+			return 0, nil, nil
+		}
+		return 0, nil, run.err
+	}
+
+	notify = make([][]func(*shards.Accumulator), len(tasks))
+	for i, t := range tasks {
+		res := run.results[i].Load()
+		t.Error, t.Failed, t.UsedGas = res.task.Error, res.task.Failed, res.task.UsedGas
+		t.Logs, t.TraceFroms, t.TraceTos = res.task.Logs, res.task.TraceFroms, res.task.TraceTos
+		t.BalanceIncreaseSet, t.WriteLists = res.task.BalanceIncreaseSet, res.task.WriteLists
+		notify[i] = res.notify
+	}
+	mxExecRepeats.AddInt(int(run.repeats.Load()))
+	mxBlockSTMBlocks.Inc()
+	return len(tasks), notify, nil
+}
+
+var (
+	errBlockSTMPanic = errors.New("blockstm worker panic")
+
+	mxBlockSTMBlocks = metrics.NewCounter(`exec_blockstm_blocks`) // blocks executed in parallel, without fallback to serial execution
+)
+
+type blockSTMResult struct {
+	task   state.TxTask
+	reads  state.VersionedReadSet
+	notify []func(*shards.Accumulator)
+}
+
+type blockSTMRequest struct {
+	key    string // read of base state, if empty - read of block hash
+	number uint64
+	resp   chan blockSTMResponse
+}
+
+type blockSTMResponse struct {
+	v    []byte
+	hash common.Hash
+	err  error
+}
+
+type blockSTMRun struct {
+	tasks   []*state.TxTask
+	vm      *state.VersionMap
+	sched   *blockSTMScheduler
+	results []atomic.Pointer[blockSTMResult]
+	repeats atomic.Int64
+
+	requests  chan blockSTMRequest
+	baseLock  sync.RWMutex
+	baseCache map[string][]byte
+
+	errOnce sync.Once
+	err     error
+}
+
+func newBlockSTMRun(tasks []*state.TxTask) *blockSTMRun {
+	return &blockSTMRun{
+		tasks:     tasks,
+		vm:        state.NewVersionMap(len(tasks), tasks[0].Rules.IsSpuriousDragon),
+		sched:     newBlockSTMScheduler(len(tasks)),
+		results:   make([]atomic.Pointer[blockSTMResult], len(tasks)),
+		requests:  make(chan blockSTMRequest),
+		baseCache: map[string][]byte{},
+	}
+}
+
+func (run *blockSTMRun) fail(err error) {
+	run.errOnce.Do(func() { run.err = err })
+	run.sched.abort()
+}
+
+// base - value of key at the beginning of the block
+func (run *blockSTMRun) base(key string) ([]byte, error) {
+	run.baseLock.RLock()
+	v, ok := run.baseCache[key]
+	run.baseLock.RUnlock()
+	if ok {
+		return v, nil
+	}
+	resp := make(chan blockSTMResponse, 1)
+	run.requests <- blockSTMRequest{key: key, resp: resp}
+	r := <-resp
+	return r.v, r.err
+}
+
+func (run *blockSTMRun) getHash(n uint64) common.Hash {
+	resp := make(chan blockSTMResponse, 1)
+	run.requests <- blockSTMRequest{number: n, resp: resp}
+	return (<-resp).hash
+}
+
+// serve - executed by goroutine which owns RwTx
+func (run *blockSTMRun) serve(req blockSTMRequest, doms *state2.SharedDomains, getHash func(uint64) common.Hash, observer state.ReadObserver) blockSTMResponse {
+	if req.key == "" {
+		return blockSTMResponse{hash: getHash(req.number)}
+	}
+	domain, k := state.SplitVersionedKey(req.key)
+	if observer != nil {
+		observer(domain, k)
+	}
+	v, _, err := doms.GetLatest(domain, k)
+	if err != nil {
+		return blockSTMResponse{err: err}
+	}
+	v = common.Copy(v)
+	run.baseLock.Lock()
+	run.baseCache[req.key] = v
+	run.baseLock.Unlock()
+	return blockSTMResponse{v: v}
+}
+
+func (run *blockSTMRun) work(w *blockSTMWorker) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			run.fail(fmt.Errorf("%w: %s, %s", errBlockSTMPanic, rec, dbg.Stack()))
+		}
+	}()
+	for task := run.sched.nextTask(); task.kind != blockSTMDone; {
+		switch task.kind {
+		case blockSTMExecute:
+			task = run.executeTx(w, task.txIdx)
+		case blockSTMValidate:
+			run.validateTx(task.txIdx, task.incarnation)
+			task = blockSTMTask{kind: blockSTMNone}
+		default:
+			task = run.sched.nextTask()
+		}
+	}
+}
+
+// executeTx - executes txn until it's not blocked by lower txn, returns next task for worker
+func (run *blockSTMRun) executeTx(w *blockSTMWorker, txIdx int) blockSTMTask {
+	for {
+		res := &blockSTMResult{task: *run.tasks[txIdx]}
+		res.task.EvmBlockContext.GetHash = run.getHash
+		if w.dirty {
+			w.SetReader(w.reader)
+			w.dirty = false
+		}
+		w.reader.ResetVersioned(run.vm, txIdx)
+		w.RunTxTaskNoLock(&res.task, false, false)
+
+		if blockedBy := w.reader.BlockedBy(); blockedBy >= 0 {
+			w.dirty = true
+			run.repeats.Add(1)
+			if run.sched.addDependency(txIdx, blockedBy) {
+				return blockSTMTask{kind: blockSTMNone}
+			}
+			continue // blocking txn is already executed
+		}
+
+		writes := &state.VersionedWriteSet{}
+		if res.task.Error != nil {
+			w.dirty = true
+		} else {
+			if err := w.writer.Unsupported(); err != nil {
+				run.fail(err)
+				return blockSTMTask{kind: blockSTMDone}
+			}
+			writes = w.writer.Writes(res.task.BalanceIncreaseSet)
+			res.notify = w.writer.Notifications()
+		}
+		res.reads = *w.reader.Reads()
+		run.results[txIdx].Store(res)
+		wroteNewKey := run.vm.Record(txIdx, writes)
+		return run.sched.finishExecution(txIdx, wroteNewKey)
+	}
+}
+
+func (run *blockSTMRun) validateTx(txIdx, incarnation int) {
+	res := run.results[txIdx].Load()
+	valid, err := run.vm.ValidateReads(txIdx, &res.reads, run.base)
+	if err != nil {
+		run.fail(err)
+		return
+	}
+	aborted := !valid && run.sched.tryValidationAbort(txIdx, incarnation, run.vm)
+	if aborted {
+		run.repeats.Add(1)
+	}
+	run.sched.finishValidation(txIdx, aborted)
+}
+
+type blockSTMStatus uint8
+
+const (
+	blockSTMReady blockSTMStatus = iota
+	blockSTMExecuting
+	blockSTMExecuted
+	blockSTMAborting // waits for re-execution
+)
+
+type blockSTMTaskKind uint8
+
+const (
+	blockSTMNone blockSTMTaskKind = iota // worker must ask scheduler for next task
+	blockSTMDone
+	blockSTMExecute
+	blockSTMValidate
+)
+
+type blockSTMTask struct {
+	kind        blockSTMTaskKind
+	txIdx       int
+	incarnation int
+}
+
+// blockSTMScheduler - collaborative scheduler of Block-STM: lowest txn which needs execution or validation goes first.
+type blockSTMScheduler struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	n             int
+	executionIdx  int
+	validationIdx int
+	active        int // tasks given to workers and not finished yet
+	aborted       bool
+
+	status       []blockSTMStatus
+	incarnations []int
+	dependants   [][]int // txns which wait for re-execution of txn
+}
+
+func newBlockSTMScheduler(n int) *blockSTMScheduler {
+	s := &blockSTMScheduler{
+		n:            n,
+		status:       make([]blockSTMStatus, n),
+		incarnations: make([]int, n),
+		dependants:   make([][]int, n),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *blockSTMScheduler) abort() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.aborted = true
+	s.cond.Broadcast()
+}
+
+func (s *blockSTMScheduler) nextTask() blockSTMTask {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		if s.aborted {
+			return blockSTMTask{kind: blockSTMDone}
+		}
+		if s.validationIdx < s.executionIdx {
+			i := s.validationIdx
+			s.validationIdx++
+			if s.status[i] == blockSTMExecuted {
+				s.active++
+				return blockSTMTask{kind: blockSTMValidate, txIdx: i, incarnation: s.incarnations[i]}
+			}
+			continue
+		}
+		if s.executionIdx < s.n {
+			i := s.executionIdx
+			s.executionIdx++
+			if s.status[i] == blockSTMReady {
+				s.status[i] = blockSTMExecuting
+				s.active++
+				return blockSTMTask{kind: blockSTMExecute, txIdx: i, incarnation: s.incarnations[i]}
+			}
+			continue
+		}
+		if s.active == 0 {
+			s.cond.Broadcast()
+			return blockSTMTask{kind: blockSTMDone}
+		}
+		s.cond.Wait()
+	}
+}
+
+// addDependency - returns false if txn `blockedBy` is already executed and txn can be re-executed right away
+func (s *blockSTMScheduler) addDependency(txIdx, blockedBy int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status[blockedBy] == blockSTMExecuted || s.aborted {
+		return s.aborted
+	}
+	s.status[txIdx] = blockSTMAborting
+	s.dependants[blockedBy] = append(s.dependants[blockedBy], txIdx)
+	s.active--
+	s.cond.Broadcast()
+	return true
+}
+
+// finishExecution - returns validation of executed txn if it can be done right away
+func (s *blockSTMScheduler) finishExecution(txIdx int, wroteNewKey bool) blockSTMTask {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.cond.Broadcast()
+	s.status[txIdx] = blockSTMExecuted
+	for _, dep := range s.dependants[txIdx] {
+		s.setReady(dep)
+	}
+	s.dependants[txIdx] = nil
+
+	if s.validationIdx > txIdx && !s.aborted {
+		if !wroteNewKey {
+			// higher txns didn't read anything new - only this txn needs validation
+			return blockSTMTask{kind: blockSTMValidate, txIdx: txIdx, incarnation: s.incarnations[txIdx]}
+		}
+		s.validationIdx = txIdx
+	}
+	s.active--
+	return blockSTMTask{kind: blockSTMNone}
+}
+
+func (s *blockSTMScheduler) setReady(txIdx int) {
+	s.status[txIdx] = blockSTMReady
+	s.incarnations[txIdx]++
+	s.executionIdx = min(s.executionIdx, txIdx)
+}
+
+// tryValidationAbort - aborts given incarnation of txn, if it's not aborted yet
+func (s *blockSTMScheduler) tryValidationAbort(txIdx, incarnation int, vm *state.VersionMap) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status[txIdx] != blockSTMExecuted || s.incarnations[txIdx] != incarnation {
+		return false
+	}
+	s.status[txIdx] = blockSTMAborting
+	vm.MarkEstimate(txIdx)
+	return true
+}
+
+func (s *blockSTMScheduler) finishValidation(txIdx int, aborted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if aborted {
+		s.setReady(txIdx)
+		s.validationIdx = min(s.validationIdx, txIdx+1)
+	}
+	s.active--
+	s.cond.Broadcast()
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync_test
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/metrics"
+	libstate "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/rawdb/rawtemporaldb"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/stages/mock"
+)
+
+// TestBlockSTMMatchesSerial - same chain is executed serially and by Block-STM. Every txn of a block conflicts
+// with others: all of them increment one storage slot (and emit a log), pay to one address and senders send
+// few txns per block. State root, receipts and changesets of both executions must be the same.
+func TestBlockSTMMatchesSerial(t *testing.T) {
+	const blocks, senders = 3, 6
+	var (
+		// sstore(0, sload(0)+1) log0(0, 0)
+		counterCode = libcommon.FromHex("0x6000546001016000556000600Aa000")
+		counter     = libcommon.HexToAddress("0xc0")
+		sink        = libcommon.HexToAddress("0x51")
+	)
+	keys := make([]*ecdsa.PrivateKey, senders)
+	alloc := types.GenesisAlloc{counter: {Code: counterCode, Balance: new(big.Int)}}
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = types.GenesisAccount{Balance: big.NewInt(params.Ether)}
+	}
+	gspec := &types.Genesis{Config: params.TestChainConfig, Alloc: alloc}
+	signer := types.LatestSigner(gspec.Config)
+
+	serial := mock.MockWithGenesis(t, gspec, keys[0], false)
+	syncCfg := ethconfig.Defaults.Sync
+	syncCfg.BlockSTM, syncCfg.BlockSTMMinTxs, syncCfg.ExecWorkerCount = true, 2, 4
+	parallel := mock.MockWithGenesisSyncConfig(t, gspec, keys[0], syncCfg)
+
+	chain, err := core.GenerateChain(serial.ChainConfig, serial.Genesis, serial.Engine, serial.DB, blocks, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(libcommon.Address{1})
+		for _, key := range keys {
+			from := crypto.PubkeyToAddress(key.PublicKey)
+			for _, txn := range []types.Transaction{
+				types.NewTransaction(b.TxNonce(from), counter, uint256.NewInt(0), 100_000, uint256.NewInt(params.GWei), nil),
+				types.NewTransaction(b.TxNonce(from)+1, sink, uint256.NewInt(uint64(i+1)), params.TxGas, uint256.NewInt(params.GWei), nil),
+			} {
+				signed, err := types.SignTx(txn, *signer, key)
+				require.NoError(t, err)
+				b.AddTx(signed)
+			}
+		}
+	})
+	require.NoError(t, err)
+
+	stmBlocks := metrics.GetOrCreateCounter(`exec_blockstm_blocks`)
+	stmBefore := stmBlocks.GetValueUint64()
+	// execution checks state root and receipts root of every block
+	require.NoError(t, serial.InsertChain(chain))
+	require.NoError(t, parallel.InsertChain(chain))
+	require.Equal(t, uint64(blocks), stmBlocks.GetValueUint64()-stmBefore, "blocks must be executed by Block-STM")
+
+	serialTx, err := serial.DB.BeginTemporalRo(serial.Ctx)
+	require.NoError(t, err)
+	defer serialTx.Rollback()
+	parallelTx, err := parallel.DB.BeginTemporalRo(parallel.Ctx)
+	require.NoError(t, err)
+	defer parallelTx.Rollback()
+
+	for _, tx := range []kv.TemporalTx{serialTx, parallelTx} {
+		doms, err := libstate.NewSharedDomains(tx, serial.Log)
+		require.NoError(t, err)
+		root, err := doms.ComputeCommitment(serial.Ctx, false, chain.TopBlock.NumberU64(), "")
+		doms.Close()
+		require.NoError(t, err)
+		require.Equal(t, chain.TopBlock.Root(), libcommon.BytesToHash(root))
+
+		v, _, err := tx.GetLatest(kv.StorageDomain, append(counter.Bytes(), libcommon.Hash{}.Bytes()...))
+		require.NoError(t, err)
+		require.Equal(t, uint64(blocks*senders), new(uint256.Int).SetBytes(v).Uint64())
+	}
+
+	for i, block := range chain.Blocks {
+		serialDiff, ok, err := libstate.ReadDiffSet(serialTx, block.NumberU64(), block.Hash())
+		require.NoError(t, err)
+		require.True(t, ok)
+		parallelDiff, ok, err := libstate.ReadDiffSet(parallelTx, block.NumberU64(), block.Hash())
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, serialDiff, parallelDiff, "changeset of block %d", block.NumberU64())
+
+		fromTxNum, err := rawdbv3.TxNums.Min(parallelTx, block.NumberU64())
+		require.NoError(t, err)
+		var logIndex uint32
+		for j, receipt := range chain.Receipts[i] {
+			// receipt of txn is readable as of next txn, first txNum of block is system txn
+			txNum := fromTxNum + uint64(j) + 2
+			cumGasUsed, _, firstLogIndex, err := rawtemporaldb.ReceiptAsOf(parallelTx, txNum)
+			require.NoError(t, err)
+			require.Equal(t, receipt.CumulativeGasUsed, cumGasUsed, "receipt %d of block %d", j, block.NumberU64())
+			require.Equal(t, logIndex, firstLogIndex, "receipt %d of block %d", j, block.NumberU64())
+			serialGasUsed, _, serialFirstLogIndex, err := rawtemporaldb.ReceiptAsOf(serialTx, txNum)
+			require.NoError(t, err)
+			require.Equal(t, serialGasUsed, cumGasUsed)
+			require.Equal(t, serialFirstLogIndex, firstLogIndex)
+			logIndex += uint32(len(receipt.Logs))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/core/state"
+)
+
+// TestBlockSTMScheduler - txns increment one of few counters: every txn conflicts with lower txns
+// of same counter, result must be the same as of serial execution.
+func TestBlockSTMScheduler(t *testing.T) {
+	const txCount, counters, workers = 200, 3, 8
+	addr := common.HexToAddress("0x01")
+	base := func(key string) ([]byte, error) { return nil, nil }
+
+	vm := state.NewVersionMap(txCount, true)
+	sched := newBlockSTMScheduler(txCount)
+	results := make([]atomic.Pointer[state.VersionedReadSet], txCount)
+	observed := make([]atomic.Uint64, txCount)
+
+	execute := func(txIdx int) (blockedBy int, wroteNewKey bool) {
+		slot := common.Hash{31: byte(txIdx % counters)}
+		r := state.NewVersionedStateReader(vm, base)
+		r.ResetVersioned(vm, txIdx)
+		v, err := r.ReadAccountStorage(addr, 1, &slot)
+		if err != nil {
+			require.ErrorIs(t, err, state.ErrDependency)
+			return r.BlockedBy(), false
+		}
+		counter := new(uint256.Int).SetBytes(v)
+		observed[txIdx].Store(counter.Uint64())
+
+		w := state.NewVersionedStateWriter()
+		require.NoError(t, w.WriteAccountStorage(addr, 1, &slot, counter, new(uint256.Int).AddUint64(counter, 1)))
+		reads := *r.Reads()
+		results[txIdx].Store(&reads)
+		return -1, vm.Record(txIdx, w.Writes(nil))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := sched.nextTask(); task.kind != blockSTMDone; {
+				switch task.kind {
+				case blockSTMExecute:
+					blockedBy, wroteNewKey := execute(task.txIdx)
+					if blockedBy >= 0 {
+						if !sched.addDependency(task.txIdx, blockedBy) {
+							continue
+						}
+						task = blockSTMTask{kind: blockSTMNone}
+						continue
+					}
+					task = sched.finishExecution(task.txIdx, wroteNewKey)
+				case blockSTMValidate:
+					valid, err := vm.ValidateReads(task.txIdx, results[task.txIdx].Load(), base)
+					require.NoError(t, err)
+					sched.finishValidation(task.txIdx, !valid && sched.tryValidationAbort(task.txIdx, task.incarnation, vm))
+					task = blockSTMTask{kind: blockSTMNone}
+				default:
+					task = sched.nextTask()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < txCount; i++ {
+		require.Equal(t, uint64(i/counters), observed[i].Load(), "txn %d", i)
+	}
+}
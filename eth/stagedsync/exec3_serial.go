@@ -15,17 +15,27 @@ import (
 	"github.com/erigontech/erigon/core/rawdb/rawtemporaldb"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/turbo/shards"
 )
 
 type serialExecutor struct {
 	txExecutor
 	skipPostEvaluation bool
+	blockSTM           *blockSTM // if not nil - txns of block are executed in parallel, but applied serially
 	// outputs
 	txCount     uint64
 	usedGas     uint64
 	blobGasUsed uint64
 }
 
+// setReadObserver - observer gets keys of all state reads of execution (nil - disable)
+func (se *serialExecutor) setReadObserver(observer state.ReadObserver) {
+	se.applyWorker.SetReadObserver(observer)
+	if se.blockSTM != nil {
+		se.blockSTM.readObserver = observer
+	}
+}
+
 func (se *serialExecutor) wait() error {
 	return nil
 }
@@ -35,12 +45,32 @@ func (se *serialExecutor) status(ctx context.Context, commitThreshold uint64) er
 }
 
 func (se *serialExecutor) execute(ctx context.Context, tasks []*state.TxTask) (cont bool, err error) {
-	for _, txTask := range tasks {
-		if txTask.Error != nil {
-			return false, nil
+	var (
+		stmExecuted int // amount of tasks after stmFrom which are already executed by blockSTM
+		stmFrom     int
+		stmNotify   [][]func(*shards.Accumulator)
+	)
+	for i, txTask := range tasks {
+		if se.blockSTM != nil && txTask.TxIndex == 0 && !txTask.Final {
+			stmFrom = i
+			if stmExecuted, stmNotify, err = se.blockSTM.execute(ctx, tasks[i:], se.doms); err != nil {
+				return false, err
+			}
 		}
 
-		se.applyWorker.RunTxTaskNoLock(txTask, se.isMining, se.skipPostEvaluation)
+		if i-stmFrom < stmExecuted {
+			se.doms.SetTxNum(txTask.TxNum)
+			if se.accumulator != nil {
+				for _, notify := range stmNotify[i-stmFrom] {
+					notify(se.accumulator)
+				}
+			}
+		} else {
+			if txTask.Error != nil {
+				return false, nil
+			}
+			se.applyWorker.RunTxTaskNoLock(txTask, se.isMining, se.skipPostEvaluation)
+		}
 		if err := func() error {
 			if errors.Is(txTask.Error, context.Canceled) {
 				return txTask.Error
@@ -39,6 +39,7 @@ import (
 	"github.com/erigontech/erigon-lib/wrap"
 	"github.com/erigontech/erigon/cmd/state/exec3"
 	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/rawdb/rawdbhelpers"
 	"github.com/erigontech/erigon/core/state"
@@ -88,6 +89,9 @@ type ExecuteBlockCfg struct {
 	blockProduction bool
 
 	applyWorker, applyWorkerMining *exec3.Worker
+	blockSTM                       *blockSTM // nil if disabled
+
+	prefetcher *core.StatePrefetcher // nil if disabled, gives keys warmed for block to report hit-rate
 }
 
 func StageExecuteBlocksCfg(
@@ -107,11 +111,17 @@ func StageExecuteBlocksCfg(
 	genesis *types.Genesis,
 	syncCfg ethconfig.Sync,
 	silkworm *silkworm.Silkworm,
+	prefetcher *core.StatePrefetcher,
 ) ExecuteBlockCfg {
 	if dirs.SnapDomain == "" {
 		panic("empty `dirs` variable")
 	}
 
+	var bs *blockSTM
+	if syncCfg.BlockSTM {
+		bs = newBlockSTM(syncCfg.ExecWorkerCount, syncCfg.BlockSTMMinTxs, db, blockReader, chainConfig, genesis, engine, dirs, log.Root())
+	}
+
 	return ExecuteBlockCfg{
 		db:                db,
 		prune:             pm,
@@ -131,6 +141,8 @@ func StageExecuteBlocksCfg(
 		silkworm:          silkworm,
 		applyWorker:       exec3.NewWorker(nil, log.Root(), vmConfig.Tracer, context.Background(), false, db, nil, blockReader, chainConfig, genesis, nil, engine, dirs, false),
 		applyWorkerMining: exec3.NewWorker(nil, log.Root(), vmConfig.Tracer, context.Background(), false, db, nil, blockReader, chainConfig, genesis, nil, engine, dirs, true),
+		blockSTM:          bs,
+		prefetcher:        prefetcher,
 	}
 }
 
@@ -186,6 +186,7 @@ func HeadersPOW(s *StageState, u Unwinder, ctx context.Context, tx kv.RwTx, cfg
 	stopped := false
 	var noProgressCounter uint = 0
 	prevProgress := startProgress
+	highestSeen := startProgress // highest header received from the peers
 	var wasProgress bool
 	var lastSkeletonTime time.Time
 	var peer [64]byte
@@ -284,7 +285,8 @@ Loop:
 		case <-logEvery.C:
 			progress := cfg.hd.Progress()
 			stats := cfg.hd.ExtractStats()
-			logProgressHeaders(logPrefix, prevProgress, progress, stats, logger)
+			highestSeen = max(highestSeen, stats.RespMaxBlock)
+			logProgressHeaders(logPrefix, prevProgress, progress, highestSeen, stats, logger)
 			if prevProgress == progress {
 				noProgressCounter++
 			} else {
@@ -531,6 +533,7 @@ func logProgressHeaders(
 	logPrefix string,
 	prev uint64,
 	now uint64,
+	highestSeen uint64,
 	stats headerdownload.Stats,
 	logger log.Logger,
 ) uint64 {
@@ -557,6 +560,7 @@ func logProgressHeaders(
 	diagnostics.Send(diagnostics.BlockHeadersUpdate{
 		CurrentBlockNumber:  now,
 		PreviousBlockNumber: prev,
+		HighestSeenBlock:    max(highestSeen, now),
 		Speed:               speed,
 		Alloc:               m.Alloc,
 		Sys:                 m.Sys,
@@ -41,6 +41,7 @@ import (
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/eth/ethutils"
 	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/builder"
 	"github.com/erigontech/erigon/turbo/services"
 )
 
@@ -60,6 +61,8 @@ type MiningState struct {
 	PendingResultCh chan *types.Block
 	MiningResultCh  chan *types.BlockWithReceipts
 	MiningBlock     *MiningBlock
+	// optional, collects choices made while building the payload
+	Report *builder.PayloadReport
 }
 
 func NewMiningState(cfg *params.MiningConfig) MiningState {
@@ -105,6 +108,7 @@ func StageMiningCreateBlockCfg(
 // TODO:
 // - resubmitAdjustCh - variable is not implemented
 func SpawnMiningCreateBlockStage(s *StageState, txc wrap.TxContainer, cfg MiningCreateBlockCfg, quit <-chan struct{}, logger log.Logger) (err error) {
+	defer cfg.miner.Report.Phase("create-block", time.Now())
 	current := cfg.miner.MiningBlock
 	var txPoolLocals []libcommon.Address //txPoolV2 has no concept of local addresses (yet?)
 	coinbase := cfg.miner.MiningConfig.Etherbase
@@ -43,6 +43,7 @@ import (
 	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/eth/stagedsync/stages"
 	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/builder"
 	"github.com/erigontech/erigon/turbo/services"
 	"github.com/erigontech/erigon/txnprovider"
 )
@@ -98,6 +99,7 @@ func SpawnMiningExecStage(s *StageState, txc wrap.TxContainer, cfg MiningExecCfg
 	logPrefix := s.LogPrefix()
 	current := cfg.miningState.MiningBlock
 	preparedTxns := current.PreparedTxns
+	report := cfg.miningState.Report
 
 	var (
 		stateReader state.StateReader
@@ -121,8 +123,19 @@ func SpawnMiningExecStage(s *StageState, txc wrap.TxContainer, cfg MiningExecCfg
 		return header
 	}
 
+	var (
+		coinbaseBalance *uint256.Int
+		err             error
+	)
+	if report != nil {
+		if coinbaseBalance, err = ibs.GetBalance(cfg.miningState.MiningConfig.Etherbase); err != nil {
+			return err
+		}
+		coinbaseBalance = coinbaseBalance.Clone()
+	}
+
 	if len(preparedTxns) > 0 {
-		logs, _, err := addTransactionsToMiningBlock(ctx, logPrefix, current, cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, preparedTxns, cfg.miningState.MiningConfig.Etherbase, ibs, cfg.interrupt, cfg.payloadId, logger)
+		logs, _, err := addTransactionsToMiningBlock(ctx, logPrefix, current, cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, preparedTxns, cfg.miningState.MiningConfig.Etherbase, ibs, cfg.interrupt, cfg.payloadId, report, logger)
 		if err != nil {
 			return err
 		}
@@ -156,7 +169,7 @@ func SpawnMiningExecStage(s *StageState, txc wrap.TxContainer, cfg MiningExecCfg
 			}
 
 			if len(txns) > 0 {
-				logs, stop, err := addTransactionsToMiningBlock(ctx, logPrefix, current, cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, txns, cfg.miningState.MiningConfig.Etherbase, ibs, cfg.interrupt, cfg.payloadId, logger)
+				logs, stop, err := addTransactionsToMiningBlock(ctx, logPrefix, current, cfg.chainConfig, cfg.vmConfig, getHeader, cfg.engine, txns, cfg.miningState.MiningConfig.Etherbase, ibs, cfg.interrupt, cfg.payloadId, report, logger)
 				if err != nil {
 					return err
 				}
@@ -177,6 +190,17 @@ func SpawnMiningExecStage(s *StageState, txc wrap.TxContainer, cfg MiningExecCfg
 		metrics.UpdateBlockProducerProductionDelay(current.ParentHeaderTime, current.Header.Number.Uint64(), logger)
 	}
 
+	if report != nil {
+		// value of transactions for coinbase, before withdrawals are credited by finalization
+		balance, err := ibs.GetBalance(cfg.miningState.MiningConfig.Etherbase)
+		if err != nil {
+			return err
+		}
+		if balance.Gt(coinbaseBalance) {
+			report.AddValue(new(uint256.Int).Sub(balance, coinbaseBalance))
+		}
+	}
+
 	logger.Debug("SpawnMiningExecStage", "block", current.Header.Number, "txn", current.Txns.Len(), "payload", cfg.payloadId)
 	if current.Uncles == nil {
 		current.Uncles = []*types.Header{}
@@ -193,12 +217,14 @@ func SpawnMiningExecStage(s *StageState, txc wrap.TxContainer, cfg MiningExecCfg
 		return err
 	}
 
-	var err error
+	finalizeStart := time.Now()
 	var block *types.Block
 	block, current.Txns, current.Receipts, current.Requests, err = core.FinalizeBlockExecution(cfg.engine, stateReader, current.Header, current.Txns, current.Uncles, &state.NoopWriter{}, &cfg.chainConfig, ibs, current.Receipts, current.Withdrawals, chainReader, true, logger, nil)
 	if err != nil {
 		return fmt.Errorf("cannot finalize block execution: %s", err)
 	}
+	report.Phase("finalize", finalizeStart)
+	defer report.Phase("state-root", time.Now())
 
 	// Simulate the block execution to get the final state root
 	if err = rawdb.WriteHeader(txc.Tx, block.Header()); err != nil {
@@ -275,21 +301,26 @@ func getNextTransactions(
 		txnprovider.WithTxnIdsFilter(alreadyYielded),
 	}
 
+	report := cfg.miningState.Report
+	start := time.Now()
 	txns, err := cfg.txnProvider.ProvideTxns(ctx, provideOpts...)
 	if err != nil {
 		return nil, err
 	}
+	report.Phase("txpool", start)
 
+	start = time.Now()
 	blockNum := executionAt + 1
-	txns, err = filterBadTransactions(txns, chainID, cfg.chainConfig, blockNum, header, simStateReader, simStateWriter, logger)
+	txns, err = filterBadTransactions(txns, chainID, cfg.chainConfig, blockNum, header, simStateReader, simStateWriter, report, logger)
 	if err != nil {
 		return nil, err
 	}
+	report.Phase("filter", start)
 
 	return txns, nil
 }
 
-func filterBadTransactions(transactions []types.Transaction, chainID *uint256.Int, config chain.Config, blockNumber uint64, header *types.Header, simStateReader state.StateReader, simStateWriter state.StateWriter, logger log.Logger) ([]types.Transaction, error) {
+func filterBadTransactions(transactions []types.Transaction, chainID *uint256.Int, config chain.Config, blockNumber uint64, header *types.Header, simStateReader state.StateReader, simStateWriter state.StateWriter, report *builder.PayloadReport, logger log.Logger) ([]types.Transaction, error) {
 	initialCnt := len(transactions)
 	var filtered []types.Transaction
 	gasBailout := false
@@ -306,15 +337,17 @@ func filterBadTransactions(transactions []types.Transaction, chainID *uint256.In
 	for len(transactions) > 0 && missedTxs != len(transactions) {
 		transaction := transactions[0]
 		transactionChainId := transaction.GetChainID()
+		sender, ok := transaction.GetSender()
 		if !transactionChainId.IsZero() && transactionChainId.Cmp(chainID) != 0 {
 			transactions = transactions[1:]
 			badChainId++
+			report.Skip(transaction, sender, builder.SkipBadChainId, nil)
 			continue
 		}
-		sender, ok := transaction.GetSender()
 		if !ok {
 			transactions = transactions[1:]
 			noSenderCnt++
+			report.Skip(transaction, sender, builder.SkipNoSender, nil)
 			continue
 		}
 		account, err := simStateReader.ReadAccountData(sender)
@@ -324,12 +357,14 @@ func filterBadTransactions(transactions []types.Transaction, chainID *uint256.In
 		if account == nil {
 			transactions = transactions[1:]
 			noAccountCnt++
+			report.Skip(transaction, sender, builder.SkipNoAccount, nil)
 			continue
 		}
 		// Check transaction nonce
 		if account.Nonce > transaction.GetNonce() {
 			transactions = transactions[1:]
 			nonceTooLowCnt++
+			report.Skip(transaction, sender, builder.SkipNonceTooLow, nil)
 			continue
 		}
 		if account.Nonce < transaction.GetNonce() {
@@ -355,6 +390,7 @@ func filterBadTransactions(transactions []types.Transaction, chainID *uint256.In
 			if !isEoaCodeAllowed {
 				transactions = transactions[1:]
 				notEOACnt++
+				report.Skip(transaction, sender, builder.SkipNotEOA, nil)
 				continue
 			}
 		}
@@ -369,6 +405,7 @@ func filterBadTransactions(transactions []types.Transaction, chainID *uint256.In
 				if err := core.CheckEip1559TxGasFeeCap(sender, transaction.GetFeeCap(), transaction.GetTipCap(), baseFee256, false /* isFree */); err != nil {
 					transactions = transactions[1:]
 					feeTooLowCnt++
+					report.Skip(transaction, sender, builder.SkipFeeTooLow, err)
 					continue
 				}
 			}
@@ -382,12 +419,14 @@ func filterBadTransactions(transactions []types.Transaction, chainID *uint256.In
 		if overflow {
 			transactions = transactions[1:]
 			overflowCnt++
+			report.Skip(transaction, sender, builder.SkipOverflow, nil)
 			continue
 		}
 		want, overflow = want.AddOverflow(want, value)
 		if overflow {
 			transactions = transactions[1:]
 			overflowCnt++
+			report.Skip(transaction, sender, builder.SkipOverflow, nil)
 			continue
 		}
 
@@ -395,6 +434,7 @@ func filterBadTransactions(transactions []types.Transaction, chainID *uint256.In
 			if !gasBailout {
 				transactions = transactions[1:]
 				balanceTooLowCnt++
+				report.Skip(transaction, sender, builder.SkipBalanceTooLow, nil)
 				continue
 			}
 		}
@@ -411,6 +451,11 @@ func filterBadTransactions(transactions []types.Transaction, chainID *uint256.In
 		filtered = append(filtered, transaction)
 		transactions = transactions[1:]
 	}
+	// what is left can't be executed, previous nonces of senders are missing
+	for _, transaction := range transactions {
+		sender, _ := transaction.GetSender()
+		report.Skip(transaction, sender, builder.SkipNonceGap, nil)
+	}
 	logger.Info("Filtration", "initial", initialCnt, "no sender", noSenderCnt, "no account", noAccountCnt, "nonce too low", nonceTooLowCnt, "nonceTooHigh", missedTxs, "sender not EOA", notEOACnt, "fee too low", feeTooLowCnt, "overflow", overflowCnt, "balance too low", balanceTooLowCnt, "bad chain id", badChainId, "filtered", len(filtered))
 	return filtered, nil
 }
@@ -428,8 +473,10 @@ func addTransactionsToMiningBlock(
 	ibs *state.IntraBlockState,
 	interrupt *int32,
 	payloadId uint64,
+	report *builder.PayloadReport,
 	logger log.Logger,
 ) (types.Logs, bool, error) {
+	defer report.Phase("execute", time.Now())
 	header := current.Header
 	txnIdx := ibs.TxnIndex() + 1
 	gasPool := new(core.GasPool).AddGas(header.GasLimit - header.GasUsed)
@@ -466,14 +513,22 @@ func addTransactionsToMiningBlock(
 	}()
 
 	done := false
+	// candidates which weren't tried because building stopped
+	skipRest := func(rest types.Transactions, reason builder.SkipReason) {
+		for _, txn := range rest {
+			sender, _ := txn.GetSender()
+			report.Skip(txn, sender, reason, nil)
+		}
+	}
 
 LOOP:
-	for _, txn := range txns {
+	for i, txn := range txns {
 		// see if we need to stop now
 		if stopped != nil {
 			select {
 			case <-stopped.C:
 				done = true
+				skipRest(txns[i:], builder.SkipInterrupted)
 				break LOOP
 			default:
 			}
@@ -492,6 +547,7 @@ LOOP:
 		if gasPool.Gas() < params.TxGas {
 			logger.Debug(fmt.Sprintf("[%s] Not enough gas for further transactions", logPrefix), "have", gasPool, "want", params.TxGas)
 			done = true
+			skipRest(txns[i:], builder.SkipGasLimit)
 			break
 		}
 
@@ -499,6 +555,7 @@ LOOP:
 		from, err := txn.Sender(*signer)
 		if err != nil {
 			logger.Warn(fmt.Sprintf("[%s] Could not recover transaction sender", logPrefix), "hash", txn.Hash(), "err", err)
+			report.Skip(txn, from, builder.SkipNoSender, err)
 			continue
 		}
 
@@ -506,11 +563,15 @@ LOOP:
 		// phase, start ignoring the sender until we do.
 		if txn.Protected() && !chainConfig.IsSpuriousDragon(header.Number.Uint64()) {
 			logger.Debug(fmt.Sprintf("[%s] Ignoring replay protected transaction", logPrefix), "hash", txn.Hash(), "eip155", chainConfig.SpuriousDragonBlock)
+			report.Skip(txn, from, builder.SkipReplayProtected, nil)
 			continue
 		}
 
 		// Start executing the transaction
 		logs, err := miningCommitTx(txn, coinbase, vmConfig, chainConfig, ibs, current)
+		if err != nil {
+			report.Skip(txn, from, builder.SkipReasonOf(err), err)
+		}
 		if errors.Is(err, core.ErrGasLimitReached) {
 			// Skip the env out-of-gas transaction
 			logger.Debug(fmt.Sprintf("[%s] Gas limit exceeded for env block", logPrefix), "hash", txn.Hash(), "sender", from)
@@ -525,6 +586,7 @@ LOOP:
 			logger.Trace(fmt.Sprintf("[%s] Added transaction", logPrefix), "hash", txn.Hash(), "sender", from, "nonce", txn.GetNonce(), "payload", payloadId)
 			coalescedLogs = append(coalescedLogs, logs...)
 			txnIdx++
+			report.Include(txn, from, current.Receipts[len(current.Receipts)-1], header.BaseFee)
 		} else {
 			// Strange error, discard the transaction and get the next in line (note, the
 			// nonce-too-high clause will prevent us from executing in vain).
@@ -18,6 +18,7 @@ package stagedsync
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/erigontech/erigon-lib/log/v3"
 
@@ -60,6 +61,7 @@ func StageMiningFinishCfg(
 }
 
 func SpawnMiningFinishStage(s *StageState, tx kv.RwTx, cfg MiningFinishCfg, quit <-chan struct{}, logger log.Logger) error {
+	defer cfg.miningState.Report.Phase("finish", time.Now())
 	logPrefix := s.LogPrefix()
 	current := cfg.miningState.MiningBlock
 
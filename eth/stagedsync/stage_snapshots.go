@@ -635,6 +635,13 @@ func SnapshotsPrune(s *PruneState, cfg SnapshotsCfg, ctx context.Context, tx kv.
 	if err := pruneCanonicalMarkers(ctx, tx, cfg.blockReader); err != nil {
 		return err
 	}
+	filesExpired, err := expireBlockSnapshots(ctx, s.LogPrefix(), tx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	if filesExpired && cfg.notifier != nil {
+		cfg.notifier.Events.OnNewSnapshot()
+	}
 
 	if cfg.snapshotUploader != nil {
 		// if we're uploading make sure that the DB does not get too far
@@ -723,6 +730,65 @@ func pruneBlockSnapshots(ctx context.Context, cfg SnapshotsCfg, logger log.Logge
 	return filesDeleted, nil
 }
 
+// expireBlockSnapshots - EIP-4444 style history expiry: deletes bodies and transactions segments
+// older than prune.Mode.Expiry and records the new horizon. Headers segments are kept, so the
+// header chain stays verifiable while RPC refuses the expired bodies.
+func expireBlockSnapshots(ctx context.Context, logPrefix string, tx kv.RwTx, cfg SnapshotsCfg, logger log.Logger) (bool, error) {
+	if cfg.prune.Expiry == nil || !cfg.prune.Expiry.Enabled() {
+		return false, nil
+	}
+	headNumber := cfg.blockReader.FrozenBlocks()
+	executionProgress, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
+		return false, err
+	}
+	// do not expire blocks which are not executed yet
+	if headNumber == 0 || headNumber > executionProgress {
+		return false, nil
+	}
+	expireTo := cfg.prune.Expiry.PruneTo(headNumber)
+	expiredTo, err := rawdb.ReadHistoryExpiredTo(tx)
+	if err != nil {
+		return false, err
+	}
+
+	var expired []string
+	for _, file := range cfg.blockReader.FrozenFiles() {
+		info, _, ok := snaptype.ParseFileName(cfg.dirs.Snap, file)
+		if !ok || info.To > expireTo {
+			continue
+		}
+		if e := info.Type.Enum(); e != coresnaptype.Enums.Bodies && e != coresnaptype.Enums.Transactions {
+			continue
+		}
+		expired = append(expired, file)
+		expiredTo = max(expiredTo, info.To)
+	}
+	if len(expired) == 0 {
+		return false, nil
+	}
+	if err := rawdb.WriteHistoryExpiredTo(tx, expiredTo); err != nil {
+		return false, err
+	}
+	// stop seeding and remove the .torrent files, so they are not downloaded again either
+	if cfg.snapshotDownloader != nil && !reflect.ValueOf(cfg.snapshotDownloader).IsNil() {
+		if _, err := cfg.snapshotDownloader.Delete(ctx, &protodownloader.DeleteRequest{Paths: expired, IncludeSeeded: true}); err != nil {
+			return false, err
+		}
+	}
+	paths := make([]string, 0, len(expired))
+	for _, file := range expired {
+		if err := cfg.blockReader.Snapshots().Delete(file); err != nil {
+			return false, err
+		}
+		paths = append(paths, filepath.Join(cfg.dirs.Snap, file))
+	}
+	// frozen segments are not removed from disk by Delete, readers keep their mmap-ed files open
+	cfg.blockReader.Snapshots().RemoveOldFiles(paths)
+	logger.Info(fmt.Sprintf("[%s] Expired block history", logPrefix), "to", expiredTo, "files", len(expired))
+	return true, nil
+}
+
 type uploadState struct {
 	sync.Mutex
 	file             string
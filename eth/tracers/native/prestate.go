@@ -179,6 +179,18 @@ func (t *prestateTracer) OnTxStart(env *tracing.VMContext, tx types.Transaction,
 	t.lookupAccount(t.to)
 	t.lookupAccount(env.Coinbase)
 
+	// Authorities are modified by set code transactions without being accessed by any opcode
+	if stx, ok := tx.(*types.SetCodeTransaction); ok {
+		var b [33]byte
+		data := bytes.NewBuffer(nil)
+		for _, auth := range stx.GetAuthorizations() {
+			data.Reset()
+			if authority, err := auth.RecoverSigner(data, b[:]); err == nil {
+				t.lookupAccount(*authority)
+			}
+		}
+	}
+
 	if t.create && t.config.DiffMode {
 		t.created[t.to] = true
 	}
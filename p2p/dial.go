@@ -82,6 +82,7 @@ var (
 	errAlreadyConnected = errors.New("already connected")
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNotWhitelisted   = errors.New("not contained in netrestrict whitelist")
+	errBanned           = errors.New("banned")
 	errNoPort           = errors.New("node does not provide TCP port")
 )
 
@@ -140,8 +141,10 @@ type dialConfig struct {
 	maxDialPeers   int              // maximum number of dialed peers
 	maxActiveDials int              // maximum number of active dials
 	netRestrict    *netutil.Netlist // IP whitelist, disabled if nil
+	isBanned       func(enode.ID) bool
 	resolver       nodeResolver
 	dialer         NodeDialer
+	quicDialer     NodeDialer // dials the nodes advertising QUIC, disabled if nil
 	log            log.Logger
 	clock          mclock.Clock
 	rand           *mrand.Rand
@@ -420,6 +423,9 @@ func (d *dialScheduler) checkDial(n *enode.Node) error {
 	if d.netRestrict != nil && !d.netRestrict.Contains(n.IP()) {
 		return errNotWhitelisted
 	}
+	if d.isBanned != nil && d.isBanned(n.ID()) {
+		return errBanned
+	}
 	if d.history.contains(string(n.ID().Bytes())) {
 		return errRecentlyDialed
 	}
@@ -558,6 +564,13 @@ func (t *dialTask) resolve(d *dialScheduler) bool {
 
 // dial performs the actual connection attempt.
 func (t *dialTask) dial(d *dialScheduler, dest *enode.Node) error {
+	if d.quicDialer != nil && nodeQUIC(dest) != nil {
+		fd, err := d.quicDialer.Dial(d.ctx, dest)
+		if err == nil {
+			return d.setupFunc(fd, t.flags, dest)
+		}
+		d.log.Trace("QUIC dial error, falling back to TCP", "id", dest.ID(), "addr", nodeQUIC(dest), "conn", t.flags, "err", err)
+	}
 	fd, err := d.dialer.Dial(d.ctx, dest)
 	if err != nil {
 		cleanErr := cleanupDialErr(err)
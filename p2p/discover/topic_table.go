@@ -0,0 +1,270 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/common/mclock"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon/p2p/enode"
+	"github.com/erigontech/erigon/p2p/netutil"
+)
+
+const (
+	topicAdLifetime    = 15 * time.Minute // how long an ad stays in the table
+	topicQueueCapacity = 100              // max ads of a single topic
+	topicTableCapacity = 10000            // max ads of all topics
+	topicTicketWindow  = 10 * time.Second // how late a ticket can be used after its waiting time
+
+	topicQueueIPLimit, topicQueueSubnet = 10, 24 // max ads of a single subnet in a topic queue
+)
+
+var (
+	errTicketInvalid  = errors.New("invalid ticket")
+	errTicketNodeID   = errors.New("ticket issued to another node")
+	errTicketTooEarly = errors.New("ticket used before its waiting time")
+	errTicketExpired  = errors.New("ticket expired")
+)
+
+// Topic identifies a service advertised in discv5.
+// It is a hash, and the ads are placed on the nodes close to it in the DHT.
+type Topic [32]byte
+
+// NewTopic returns the topic of a service name.
+func NewTopic(name string) Topic {
+	return Topic(crypto.Keccak256Hash([]byte(name)))
+}
+
+func (t Topic) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// topicAd is a node advertised under a topic.
+type topicAd struct {
+	node    *enode.Node
+	expires mclock.AbsTime
+}
+
+// topicTicket is the content of a ticket issued by the registrar.
+// It is opaque for the registrant and is signed by the registrar to be checked on REGTOPIC.
+type topicTicket struct {
+	Topic    Topic
+	NodeID   enode.ID
+	Issued   uint64 // mclock.AbsTime of the registrar
+	WaitTime uint64 // milliseconds
+}
+
+// topicTable holds the ads of the nodes registered on this node.
+//
+// Every topic has a queue of ads limited by topicQueueCapacity, and all the queues together
+// are limited by topicTableCapacity. Ads of a single subnet take at most topicQueueIPLimit
+// slots of a queue, so one operator can't fill the queue of a topic. A node wanting to register requests a ticket first.
+// The ticket tells how long to wait for a free slot: the time until the oldest ad expires
+// if the queue or the table is full. After the waiting time the node registers with the ticket,
+// and if the slot is taken by then, it gets a new ticket.
+type topicTable struct {
+	mu        sync.Mutex
+	clock     mclock.Clock
+	queues    map[Topic][]*topicAd // the oldest ads first
+	count     int
+	ticketKey []byte
+}
+
+func newTopicTable(clock mclock.Clock) *topicTable {
+	key := make([]byte, 32)
+	crand.Read(key)
+	return &topicTable{
+		clock:     clock,
+		queues:    make(map[Topic][]*topicAd),
+		ticketKey: key,
+	}
+}
+
+// expire removes the expired ads.
+func (tt *topicTable) expire(now mclock.AbsTime) {
+	for topic, queue := range tt.queues {
+		i := 0
+		for (i < len(queue)) && (queue[i].expires <= now) {
+			i++
+		}
+		tt.count -= i
+		if i == len(queue) {
+			delete(tt.queues, topic)
+		} else if i > 0 {
+			tt.queues[topic] = queue[i:]
+		}
+	}
+}
+
+func (tt *topicTable) find(topic Topic, id enode.ID) int {
+	for i, ad := range tt.queues[topic] {
+		if ad.node.ID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// subnetFreeAt returns when the subnet of ip gets a free slot in the topic queue, 0 if it has one.
+func (tt *topicTable) subnetFreeAt(topic Topic, ip net.IP) mclock.AbsTime {
+	if len(ip) == 0 || netutil.IsLAN(ip) {
+		return 0
+	}
+	subnet := netutil.DistinctNetSet{Subnet: topicQueueSubnet, Limit: 1}
+	subnet.Add(ip)
+	var same []mclock.AbsTime
+	for _, ad := range tt.queues[topic] {
+		if adIP := ad.node.IP(); len(adIP) > 0 && subnet.Contains(adIP) {
+			same = append(same, ad.expires)
+		}
+	}
+	if len(same) < topicQueueIPLimit {
+		return 0
+	}
+	return same[len(same)-topicQueueIPLimit]
+}
+
+// waitTime returns how long the node at ip has to wait before it can register under the topic.
+func (tt *topicTable) waitTime(topic Topic, id enode.ID, ip net.IP) time.Duration {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	now := tt.clock.Now()
+	tt.expire(now)
+	if tt.find(topic, id) >= 0 {
+		// re-registration replaces the existing ad
+		return 0
+	}
+
+	var freeAt mclock.AbsTime
+	if queue := tt.queues[topic]; len(queue) >= topicQueueCapacity {
+		freeAt = queue[0].expires
+	}
+	if tt.count >= topicTableCapacity {
+		for _, queue := range tt.queues {
+			if (freeAt == 0) || (queue[0].expires < freeAt) {
+				freeAt = queue[0].expires
+			}
+		}
+	}
+	freeAt = max(freeAt, tt.subnetFreeAt(topic, ip))
+	if freeAt <= now {
+		return 0
+	}
+	return freeAt.Sub(now)
+}
+
+// register adds the node to the topic queue if there is a free slot.
+func (tt *topicTable) register(topic Topic, node *enode.Node) bool {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	now := tt.clock.Now()
+	tt.expire(now)
+	ad := &topicAd{node: node, expires: now.Add(topicAdLifetime)}
+	queue := tt.queues[topic]
+	if i := tt.find(topic, node.ID()); i >= 0 {
+		// move the renewed ad to the end to keep the queue ordered by expiration
+		queue = append(queue[:i:i], queue[i+1:]...)
+		tt.queues[topic] = append(queue, ad)
+		return true
+	}
+	if (len(queue) >= topicQueueCapacity) || (tt.count >= topicTableCapacity) {
+		return false
+	}
+	if tt.subnetFreeAt(topic, node.IP()) != 0 {
+		return false
+	}
+	tt.queues[topic] = append(queue, ad)
+	tt.count++
+	return true
+}
+
+// query returns up to limit random nodes advertised under the topic.
+func (tt *topicTable) query(topic Topic, limit int) []*enode.Node {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	tt.expire(tt.clock.Now())
+	queue := tt.queues[topic]
+	nodes := make([]*enode.Node, 0, min(limit, len(queue)))
+	for _, i := range rand.Perm(len(queue)) {
+		if len(nodes) >= limit {
+			break
+		}
+		nodes = append(nodes, queue[i].node)
+	}
+	return nodes
+}
+
+// issueTicket creates a signed ticket for the node with the current waiting time.
+func (tt *topicTable) issueTicket(topic Topic, id enode.ID, ip net.IP) ([]byte, time.Duration) {
+	wait := tt.waitTime(topic, id, ip)
+	ticket := topicTicket{
+		Topic:    topic,
+		NodeID:   id,
+		Issued:   uint64(tt.clock.Now()),
+		WaitTime: uint64(wait.Milliseconds()),
+	}
+	enc, _ := rlp.EncodeToBytes(&ticket)
+	return append(enc, tt.ticketMAC(enc)...), wait
+}
+
+// checkTicket verifies that the ticket was issued to the node by this table
+// and its waiting time has passed.
+func (tt *topicTable) checkTicket(data []byte, id enode.ID) (Topic, error) {
+	if len(data) <= sha256.Size {
+		return Topic{}, errTicketInvalid
+	}
+	enc, mac := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if !hmac.Equal(mac, tt.ticketMAC(enc)) {
+		return Topic{}, errTicketInvalid
+	}
+	var ticket topicTicket
+	if err := rlp.DecodeBytes(enc, &ticket); err != nil {
+		return Topic{}, errTicketInvalid
+	}
+	if ticket.NodeID != id {
+		return Topic{}, errTicketNodeID
+	}
+
+	now := tt.clock.Now()
+	usableAt := mclock.AbsTime(ticket.Issued).Add(time.Duration(ticket.WaitTime) * time.Millisecond)
+	if now < usableAt {
+		return Topic{}, errTicketTooEarly
+	}
+	if now > usableAt.Add(topicTicketWindow) {
+		return Topic{}, errTicketExpired
+	}
+	return ticket.Topic, nil
+}
+
+func (tt *topicTable) ticketMAC(data []byte) []byte {
+	mac := hmac.New(sha256.New, tt.ticketKey)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common/mclock"
+	"github.com/erigontech/erigon/p2p/enode"
+	"github.com/erigontech/erigon/p2p/enr"
+)
+
+func topicTestNodes(n int) []*enode.Node {
+	nodes := make([]*enode.Node, n)
+	for i := range nodes {
+		var id enode.ID
+		id[0], id[1] = byte(i>>8), byte(i)
+		nodes[i] = enode.SignNull(new(enr.Record), id)
+	}
+	return nodes
+}
+
+func TestTopicTable_queueCapacity(t *testing.T) {
+	clock := new(mclock.Simulated)
+	tt := newTopicTable(clock)
+	topic := NewTopic("test")
+	nodes := topicTestNodes(topicQueueCapacity + 1)
+
+	for i, n := range nodes[:topicQueueCapacity] {
+		require.Zero(t, tt.waitTime(topic, n.ID(), n.IP()))
+		require.True(t, tt.register(topic, n))
+		if i == 0 {
+			clock.Run(time.Minute)
+		}
+	}
+
+	// the queue is full until the first ad expires
+	late := nodes[topicQueueCapacity]
+	require.Equal(t, topicAdLifetime-time.Minute, tt.waitTime(topic, late.ID(), late.IP()))
+	require.False(t, tt.register(topic, late))
+	// other topics are not affected
+	require.Zero(t, tt.waitTime(NewTopic("other"), late.ID(), late.IP()))
+	// registered nodes can renew without waiting
+	clock.Run(2 * time.Minute)
+	require.Zero(t, tt.waitTime(topic, nodes[1].ID(), nodes[1].IP()))
+	require.True(t, tt.register(topic, nodes[1]))
+
+	clock.Run(topicAdLifetime - 3*time.Minute)
+	require.Zero(t, tt.waitTime(topic, late.ID(), late.IP()))
+	require.True(t, tt.register(topic, late))
+	require.Len(t, tt.query(topic, 1000), topicQueueCapacity)
+	require.Len(t, tt.query(topic, 10), 10)
+
+	// the renewed ad outlives the others
+	clock.Run(2 * time.Minute)
+	got := tt.query(topic, 1000)
+	require.Len(t, got, 2)
+	require.ElementsMatch(t, []enode.ID{nodes[1].ID(), late.ID()}, []enode.ID{got[0].ID(), got[1].ID()})
+	require.Equal(t, 2, tt.count)
+}
+
+func TestTopicTable_subnetLimit(t *testing.T) {
+	clock := new(mclock.Simulated)
+	tt := newTopicTable(clock)
+	topic := NewTopic("test")
+	newNode := func(i int, ip net.IP) *enode.Node {
+		var r enr.Record
+		r.Set(enr.IP(ip))
+		var id enode.ID
+		id[0], id[1] = 0xff, byte(i)
+		return enode.SignNull(&r, id)
+	}
+
+	for i := 0; i < topicQueueIPLimit; i++ {
+		n := newNode(i, net.IP{1, 2, 3, byte(i + 1)})
+		require.Zero(t, tt.waitTime(topic, n.ID(), n.IP()))
+		require.True(t, tt.register(topic, n))
+		if i == 0 {
+			clock.Run(time.Minute)
+		}
+	}
+
+	// the subnet is full until its first ad expires
+	late := newNode(100, net.IP{1, 2, 3, 100})
+	require.Equal(t, topicAdLifetime-time.Minute, tt.waitTime(topic, late.ID(), late.IP()))
+	require.False(t, tt.register(topic, late))
+	// other subnets, LAN addresses and other topics are not affected
+	other := newNode(101, net.IP{1, 2, 4, 1})
+	require.Zero(t, tt.waitTime(topic, other.ID(), other.IP()))
+	require.True(t, tt.register(topic, other))
+	lan := newNode(102, net.IP{192, 168, 0, 1})
+	require.True(t, tt.register(topic, lan))
+	require.Zero(t, tt.waitTime(NewTopic("other"), late.ID(), late.IP()))
+
+	clock.Run(topicAdLifetime - time.Minute)
+	require.Zero(t, tt.waitTime(topic, late.ID(), late.IP()))
+	require.True(t, tt.register(topic, late))
+}
+
+func TestTopicTable_tickets(t *testing.T) {
+	clock := new(mclock.Simulated)
+	tt := newTopicTable(clock)
+	topic := NewTopic("test")
+	nodes := topicTestNodes(topicQueueCapacity + 1)
+	for _, n := range nodes[:topicQueueCapacity] {
+		require.True(t, tt.register(topic, n))
+	}
+	id := nodes[topicQueueCapacity].ID()
+
+	ticket, wait := tt.issueTicket(topic, id, nil)
+	require.Equal(t, topicAdLifetime, wait)
+
+	_, err := tt.checkTicket(ticket, id)
+	require.ErrorIs(t, err, errTicketTooEarly)
+	_, err = tt.checkTicket(ticket, nodes[0].ID())
+	require.ErrorIs(t, err, errTicketNodeID)
+	tampered := append([]byte{}, ticket...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = tt.checkTicket(tampered, id)
+	require.ErrorIs(t, err, errTicketInvalid)
+	_, err = newTopicTable(clock).checkTicket(ticket, id)
+	require.ErrorIs(t, err, errTicketInvalid)
+
+	clock.Run(wait)
+	got, err := tt.checkTicket(ticket, id)
+	require.NoError(t, err)
+	require.Equal(t, topic, got)
+
+	clock.Run(topicTicketWindow + time.Second)
+	_, err = tt.checkTicket(ticket, id)
+	require.ErrorIs(t, err, errTicketExpired)
+}
@@ -0,0 +1,309 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erigontech/erigon-lib/common/debug"
+	"github.com/erigontech/erigon-lib/common/mclock"
+	"github.com/erigontech/erigon/p2p/discover/v5wire"
+	"github.com/erigontech/erigon/p2p/enode"
+	"github.com/erigontech/erigon/p2p/netutil"
+)
+
+const (
+	topicRegistrarCount   = 8                // registrars an ad is placed on
+	topicRegisterAttempts = 5                // tickets used against a single registrar per round
+	topicMaxWaitTime      = topicAdLifetime  // longer waiting times are not worth it
+	topicRenewMargin      = time.Minute      // ads are renewed this long before they expire
+	topicRetryInterval    = 30 * time.Second // next registration round after a failed one
+	topicSearchInterval   = 30 * time.Second // min time between searches of the topic iterator
+)
+
+// RegisterTopic advertises the local node under the topic until ctx is done or the transport is closed.
+// The ads are placed on the nodes closest to the topic hash and are renewed before they expire.
+func (t *UDPv5) RegisterTopic(ctx context.Context, topic Topic) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(t.closeCtx, cancel)
+	go func() {
+		defer debug.LogPanic()
+		defer stop()
+		defer cancel()
+		t.topicRegisterLoop(ctx, topic)
+	}()
+}
+
+func (t *UDPv5) topicRegisterLoop(ctx context.Context, topic Topic) {
+	for {
+		registrars := t.newLookup(ctx, enode.ID(topic)).run()
+		if len(registrars) > topicRegistrarCount {
+			registrars = registrars[:topicRegistrarCount]
+		}
+
+		var registered atomic.Int32
+		var wg sync.WaitGroup
+		for _, n := range registrars {
+			wg.Add(1)
+			go func(n *enode.Node) {
+				defer debug.LogPanic()
+				defer wg.Done()
+				if t.registerTopicAt(ctx, n, topic) {
+					registered.Add(1)
+				}
+			}(n)
+		}
+		wg.Wait()
+		t.log.Trace("Registered topic", "topic", topic, "registrars", registered.Load(), "candidates", len(registrars))
+
+		next := topicRetryInterval
+		if registered.Load() > 0 {
+			next = topicAdLifetime - topicRenewMargin
+		}
+		select {
+		case <-t.clock.After(next):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// registerTopicAt obtains a ticket from the registrar, waits and registers with it.
+func (t *UDPv5) registerTopicAt(ctx context.Context, n *enode.Node, topic Topic) bool {
+	ticket, wait, err := t.requestTicket(n, topic)
+	for attempt := 0; (err == nil) && (attempt < topicRegisterAttempts); attempt++ {
+		if wait > topicMaxWaitTime {
+			return false
+		}
+		select {
+		case <-t.clock.After(wait):
+		case <-ctx.Done():
+			return false
+		}
+
+		var registered bool
+		registered, ticket, wait, err = t.regtopic(n, ticket)
+		if registered {
+			return true
+		}
+		if ticket == nil {
+			return false
+		}
+	}
+	if err != nil {
+		t.log.Trace("Topic registration failed", "id", n.ID(), "topic", topic, "err", err)
+	}
+	return false
+}
+
+// requestTicket calls REQUESTTICKET on a node and waits for a TICKET response.
+func (t *UDPv5) requestTicket(n *enode.Node, topic Topic) ([]byte, time.Duration, error) {
+	resp := t.call(n, v5wire.TicketMsg, &v5wire.RequestTicket{Topic: topic[:]})
+	defer t.callDone(resp)
+
+	select {
+	case respMsg := <-resp.ch:
+		ticket := respMsg.(*v5wire.Ticket)
+		return ticket.Ticket, time.Duration(ticket.WaitTime) * time.Millisecond, nil
+	case err := <-resp.err:
+		return nil, 0, err
+	}
+}
+
+// regtopic calls REGTOPIC on a node and waits for a REGCONFIRMATION response.
+// If the registration fails, it returns the new ticket if the registrar issued one.
+func (t *UDPv5) regtopic(n *enode.Node, ticket []byte) (bool, []byte, time.Duration, error) {
+	resp := t.call(n, v5wire.RegconfirmationMsg, &v5wire.Regtopic{Ticket: ticket, ENR: t.Self().Record()})
+	defer t.callDone(resp)
+
+	select {
+	case respMsg := <-resp.ch:
+		conf := respMsg.(*v5wire.Regconfirmation)
+		if conf.Registered || (len(conf.Ticket) == 0) {
+			return conf.Registered, nil, 0, nil
+		}
+		return false, conf.Ticket, time.Duration(conf.WaitTime) * time.Millisecond, nil
+	case err := <-resp.err:
+		return false, nil, 0, err
+	}
+}
+
+// topicQuery calls TOPICQUERY on a node and waits for NODES responses.
+func (t *UDPv5) topicQuery(n *enode.Node, topic Topic) ([]*enode.Node, error) {
+	resp := t.call(n, v5wire.NodesMsg, &v5wire.TopicQuery{Topic: topic[:]})
+	return t.waitForNodes(resp, nil)
+}
+
+// TopicSearch queries the nodes closest to the topic hash and returns the nodes advertised under the topic.
+func (t *UDPv5) TopicSearch(topic Topic) []*enode.Node {
+	return t.topicSearch(t.closeCtx, topic)
+}
+
+func (t *UDPv5) topicSearch(ctx context.Context, topic Topic) []*enode.Node {
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		seen  = map[enode.ID]struct{}{t.Self().ID(): {}}
+		nodes []*enode.Node
+	)
+	add := func(found []*enode.Node) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, n := range found {
+			if _, ok := seen[n.ID()]; !ok {
+				seen[n.ID()] = struct{}{}
+				nodes = append(nodes, n)
+			}
+		}
+	}
+
+	add(t.topics.query(topic, findnodeResultLimit))
+	for _, registrar := range t.newLookup(ctx, enode.ID(topic)).run() {
+		wg.Add(1)
+		go func(n *enode.Node) {
+			defer debug.LogPanic()
+			defer wg.Done()
+			found, err := t.topicQuery(n, topic)
+			if err != nil && !errors.Is(err, errClosed) {
+				t.log.Trace("Topic query failed", "id", n.ID(), "topic", topic, "err", err)
+			}
+			add(found)
+		}(registrar)
+	}
+	wg.Wait()
+	return nodes
+}
+
+// TopicNodes returns an iterator that repeatedly searches for the nodes advertised under the topic.
+func (t *UDPv5) TopicNodes(topic Topic) enode.Iterator {
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	return &topicIterator{
+		ctx:    ctx,
+		cancel: cancel,
+		search: func(ctx context.Context) []*enode.Node { return t.topicSearch(ctx, topic) },
+		after:  t.clock.After,
+	}
+}
+
+// topicIterator iterates over the results of topic searches.
+// When the results are consumed, a new search runs after topicSearchInterval.
+type topicIterator struct {
+	ctx      context.Context
+	cancel   func()
+	search   func(ctx context.Context) []*enode.Node
+	after    func(time.Duration) <-chan mclock.AbsTime
+	buffer   []*enode.Node
+	searched bool
+}
+
+// Node returns the current node.
+func (it *topicIterator) Node() *enode.Node {
+	if len(it.buffer) == 0 {
+		return nil
+	}
+	return it.buffer[0]
+}
+
+// Next moves to the next node.
+func (it *topicIterator) Next() bool {
+	if len(it.buffer) > 0 {
+		it.buffer = it.buffer[1:]
+	}
+	for len(it.buffer) == 0 {
+		if it.searched {
+			select {
+			case <-it.after(topicSearchInterval):
+			case <-it.ctx.Done():
+			}
+		}
+		if it.ctx.Err() != nil {
+			it.buffer = nil
+			return false
+		}
+		it.buffer = it.search(it.ctx)
+		it.searched = true
+	}
+	return true
+}
+
+// Close ends the iterator.
+func (it *topicIterator) Close() {
+	it.cancel()
+}
+
+// handleRequestTicket issues a ticket for the requested topic.
+func (t *UDPv5) handleRequestTicket(p *v5wire.RequestTicket, fromID enode.ID, fromAddr *net.UDPAddr) {
+	if len(p.Topic) != len(Topic{}) {
+		t.log.Trace("Invalid topic in "+p.Name(), "id", fromID, "addr", fromAddr)
+		return
+	}
+	ticket, wait := t.topics.issueTicket(Topic(p.Topic), fromID, fromAddr.IP)
+	resp := &v5wire.Ticket{ReqID: p.ReqID, Ticket: ticket, WaitTime: uint64(wait.Milliseconds())}
+	t.sendResponse(fromID, fromAddr, resp) //nolint:errcheck
+}
+
+// handleRegtopic registers the sender under the ticket topic.
+// If there is no free slot, the sender gets a new ticket.
+func (t *UDPv5) handleRegtopic(p *v5wire.Regtopic, fromID enode.ID, fromAddr *net.UDPAddr) {
+	resp := &v5wire.Regconfirmation{ReqID: p.ReqID}
+	defer t.sendResponse(fromID, fromAddr, resp) //nolint:errcheck
+
+	topic, err := t.topics.checkTicket(p.Ticket, fromID)
+	if err != nil {
+		t.log.Trace("Invalid ticket in "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+		return
+	}
+	if p.ENR == nil {
+		return
+	}
+	node, err := enode.New(t.validSchemes, p.ENR)
+	if err != nil || node.ID() != fromID || node.IP() == nil || node.UDP() == 0 {
+		t.log.Trace("Invalid record in "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+		return
+	}
+	if t.netrestrict != nil && !t.netrestrict.Contains(node.IP()) {
+		return
+	}
+
+	if t.topics.register(topic, node) {
+		resp.Registered = true
+		return
+	}
+	ticket, wait := t.topics.issueTicket(topic, fromID, node.IP())
+	resp.Ticket = ticket
+	resp.WaitTime = uint64(wait.Milliseconds())
+}
+
+// handleTopicQuery returns the nodes advertised under the topic.
+func (t *UDPv5) handleTopicQuery(p *v5wire.TopicQuery, fromID enode.ID, fromAddr *net.UDPAddr) {
+	var nodes []*enode.Node
+	if len(p.Topic) == len(Topic{}) {
+		for _, n := range t.topics.query(Topic(p.Topic), findnodeResultLimit) {
+			if netutil.CheckRelayIP(fromAddr.IP, n.IP()) == nil {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	for _, resp := range packNodes(p.ReqID, nodes) {
+		t.sendResponse(fromID, fromAddr, resp) //nolint:errcheck
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build integration_skip
+
+package discover
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/p2p/enode"
+)
+
+// This test checks that a node advertised under a topic is found by the other nodes.
+func TestUDPv5_topicE2E(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fix me on win please")
+	}
+	t.Parallel()
+	logger := log.New()
+
+	registrar := startLocalhostV5(t, Config{}, logger)
+	advertiser := startLocalhostV5(t, Config{Bootnodes: []*enode.Node{registrar.Self()}}, logger)
+	searcher := startLocalhostV5(t, Config{Bootnodes: []*enode.Node{registrar.Self()}}, logger)
+	defer func() {
+		for _, node := range []*UDPv5{registrar, advertiser, searcher} {
+			node.Close()
+		}
+	}()
+
+	topic := NewTopic("test")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	advertiser.RegisterTopic(ctx, topic)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, n := range searcher.TopicSearch(topic) {
+			if n.ID() == advertiser.Self().ID() {
+				return
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatal("advertised node not found")
+}
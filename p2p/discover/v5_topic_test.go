@@ -0,0 +1,138 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common/mclock"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/p2p/discover/v5wire"
+	"github.com/erigontech/erigon/p2p/enode"
+	"github.com/erigontech/erigon/p2p/enr"
+)
+
+// topicTestCodec keeps the packets sent by the handlers instead of encoding them.
+type topicTestCodec struct {
+	sent []v5wire.Packet
+}
+
+func (c *topicTestCodec) Encode(_ enode.ID, _ string, p v5wire.Packet, _ *v5wire.Whoareyou) ([]byte, v5wire.Nonce, error) {
+	c.sent = append(c.sent, p)
+	return nil, v5wire.Nonce{}, nil
+}
+
+func (c *topicTestCodec) Decode([]byte, string) (enode.ID, *enode.Node, v5wire.Packet, error) {
+	return enode.ID{}, nil, nil, net.ErrClosed
+}
+
+func (c *topicTestCodec) last(t *testing.T) v5wire.Packet {
+	t.Helper()
+	require.NotEmpty(t, c.sent)
+	p := c.sent[len(c.sent)-1]
+	c.sent = c.sent[:0]
+	return p
+}
+
+type topicTestConn struct{}
+
+func (topicTestConn) ReadFromUDP([]byte) (int, *net.UDPAddr, error)    { return 0, nil, net.ErrClosed }
+func (topicTestConn) WriteToUDP(b []byte, _ *net.UDPAddr) (int, error) { return len(b), nil }
+func (topicTestConn) Close() error                                     { return nil }
+func (topicTestConn) LocalAddr() net.Addr                              { return &net.UDPAddr{} }
+
+func topicTestNode(i int, ip net.IP) *enode.Node {
+	var id enode.ID
+	id[0], id[1] = byte(i>>8), byte(i)
+	var r enr.Record
+	r.Set(enr.IP(ip))
+	r.Set(enr.UDP(30303))
+	return enode.SignNull(&r, id)
+}
+
+// This test checks that the topic registration requests are handled correctly.
+func TestUDPv5_topicHandling(t *testing.T) {
+	t.Parallel()
+	codec := &topicTestCodec{}
+	udp := &UDPv5{
+		conn:         topicTestConn{},
+		codec:        codec,
+		log:          log.New(),
+		validSchemes: enode.ValidSchemesForTesting,
+		topics:       newTopicTable(new(mclock.Simulated)),
+	}
+	topic := NewTopic("test")
+	remote := topicTestNode(0, net.IP{1, 2, 3, 1})
+	remoteAddr := &net.UDPAddr{IP: remote.IP(), Port: remote.UDP()}
+
+	requestTicket := func(n *enode.Node) *v5wire.Ticket {
+		udp.handleRequestTicket(&v5wire.RequestTicket{ReqID: []byte{1}, Topic: topic[:]}, n.ID(), &net.UDPAddr{IP: n.IP(), Port: n.UDP()})
+		return codec.last(t).(*v5wire.Ticket)
+	}
+	regtopic := func(n *enode.Node, ticket []byte) *v5wire.Regconfirmation {
+		udp.handleRegtopic(&v5wire.Regtopic{ReqID: []byte{2}, Ticket: ticket, ENR: n.Record()}, n.ID(), &net.UDPAddr{IP: n.IP(), Port: n.UDP()})
+		return codec.last(t).(*v5wire.Regconfirmation)
+	}
+	query := func(topic Topic) []*enr.Record {
+		udp.handleTopicQuery(&v5wire.TopicQuery{ReqID: []byte{3}, Topic: topic[:]}, remote.ID(), remoteAddr)
+		var records []*enr.Record
+		for _, p := range codec.sent {
+			records = append(records, p.(*v5wire.Nodes).Nodes...)
+		}
+		codec.sent = codec.sent[:0]
+		return records
+	}
+
+	// Nothing is advertised yet.
+	require.Empty(t, query(topic))
+
+	// Registration with an invalid ticket fails without a new ticket.
+	resp := regtopic(remote, []byte("ticket"))
+	require.False(t, resp.Registered)
+	require.Empty(t, resp.Ticket)
+
+	// The table is empty, so the ticket can be used right away.
+	ticket := requestTicket(remote)
+	require.Zero(t, ticket.WaitTime)
+	require.True(t, regtopic(remote, ticket.Ticket).Registered)
+	require.Equal(t, []*enr.Record{remote.Record()}, query(topic))
+
+	// Other topics are empty.
+	require.Empty(t, query(NewTopic("other")))
+
+	// A subnet takes a limited number of slots of the topic queue.
+	late := topicTestNode(topicQueueIPLimit, net.IP{1, 2, 3, 200})
+	lateTicket := requestTicket(late)
+	require.Zero(t, lateTicket.WaitTime)
+	for i := 1; i < topicQueueIPLimit; i++ {
+		n := topicTestNode(i, net.IP{1, 2, 3, byte(1 + i)})
+		require.True(t, regtopic(n, requestTicket(n).Ticket).Registered)
+	}
+	resp = regtopic(late, lateTicket.Ticket)
+	require.False(t, resp.Registered)
+	require.Equal(t, uint64(topicAdLifetime.Milliseconds()), resp.WaitTime)
+	require.Equal(t, uint64(topicAdLifetime.Milliseconds()), requestTicket(late).WaitTime)
+	require.Len(t, query(topic), topicQueueIPLimit)
+
+	// Other subnets are not affected.
+	other := topicTestNode(topicQueueIPLimit+1, net.IP{1, 2, 4, 1})
+	require.Zero(t, requestTicket(other).WaitTime)
+	require.True(t, regtopic(other, requestTicket(other).Ticket).Registered)
+}
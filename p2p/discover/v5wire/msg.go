@@ -124,8 +124,9 @@ type (
 
 	// TICKET is the response to REQUESTTICKET.
 	Ticket struct {
-		ReqID  []byte
-		Ticket []byte
+		ReqID    []byte
+		Ticket   []byte
+		WaitTime uint64 // milliseconds to wait before using the ticket in REGTOPIC
 	}
 
 	// REGTOPIC registers the sender in a topic queue using a ticket.
@@ -136,9 +137,12 @@ type (
 	}
 
 	// REGCONFIRMATION is the reply to REGTOPIC.
+	// When the registration fails, it may contain a new ticket to retry with.
 	Regconfirmation struct {
 		ReqID      []byte
 		Registered bool
+		Ticket     []byte `rlp:"optional"`
+		WaitTime   uint64 `rlp:"optional"`
 	}
 
 	// TOPICQUERY asks for nodes with the given topic.
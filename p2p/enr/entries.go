@@ -78,6 +78,12 @@ type UDP6 uint16
 
 func (v UDP6) ENRKey() string { return "udp6" }
 
+// QUIC is the "devp2p-quic" key, which holds the UDP port of the experimental devp2p QUIC transport.
+// It is not "quic": consensus layer clients already use that key for their libp2p QUIC port.
+type QUIC uint16
+
+func (v QUIC) ENRKey() string { return "devp2p-quic" }
+
 // ID is the "id" key, which holds the name of the identity scheme.
 type ID string
 
@@ -41,8 +41,9 @@ type Interface interface {
 	//
 	// protocol is "UDP" or "TCP". Some implementations allow setting
 	// a display name for the mapping. The mapping may be removed by
-	// the gateway when its lifetime ends.
-	AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) error
+	// the gateway when its lifetime ends. The returned port is the
+	// external port assigned by the gateway, it may differ from extport.
+	AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (uint16, error)
 	DeleteMapping(protocol string, extport, intport int) error
 	SupportsMapping() bool
 
@@ -64,6 +65,8 @@ type Interface interface {
 //	"upnp"               uses the Universal Plug and Play protocol
 //	"pmp"                uses NAT-PMP with an auto-detected gateway address
 //	"pmp:192.168.0.1"    uses NAT-PMP with the given gateway address
+//	"pcp"                uses the Port Control Protocol with an auto-detected gateway address
+//	"pcp:192.168.0.1"    uses the Port Control Protocol with the given gateway address
 //	"stun"               uses STUN to detect an external IP using a default server
 //	"stun:<server>"      uses STUN to detect an external IP using the given server (host:port)
 func Parse(spec string) (Interface, error) {
@@ -96,6 +99,15 @@ func Parse(spec string) (Interface, error) {
 			}
 		}
 		return PMP(ip), nil
+	case "pcp":
+		var ip net.IP
+		if len(parts) > 1 {
+			ip = net.ParseIP(parts[1])
+			if ip == nil {
+				return nil, errors.New("invalid IP address")
+			}
+		}
+		return PCP(ip), nil
 	case "stun":
 		var addr string
 		if len(parts) > 1 {
@@ -125,10 +137,10 @@ func Map(m Interface, c <-chan struct{}, protocol string, extport, intport int,
 		logger1.Trace("Deleting port mapping")
 		m.DeleteMapping(protocol, extport, intport)
 	}()
-	if err := m.AddMapping(protocol, extport, intport, name, mapTimeout); err != nil {
+	if port, err := m.AddMapping(protocol, extport, intport, name, mapTimeout); err != nil {
 		logger1.Debug("Couldn't add port mapping", "err", err)
 	} else {
-		logger1.Info("Mapped network port")
+		logger1.Info("Mapped network port", "mapped", port)
 	}
 	for {
 		select {
@@ -138,7 +150,7 @@ func Map(m Interface, c <-chan struct{}, protocol string, extport, intport int,
 			}
 		case <-refresh.C:
 			logger1.Trace("Refreshing port mapping")
-			if err := m.AddMapping(protocol, extport, intport, name, mapTimeout); err != nil {
+			if _, err := m.AddMapping(protocol, extport, intport, name, mapTimeout); err != nil {
 				logger1.Debug("Couldn't add port mapping", "err", err)
 			}
 			refresh.Reset(mapTimeout)
@@ -156,19 +168,20 @@ func (n ExtIP) String() string              { return fmt.Sprintf("ExtIP(%v)", ne
 
 // These do nothing.
 
-func (ExtIP) AddMapping(string, int, int, string, time.Duration) error { return nil }
-func (ExtIP) DeleteMapping(string, int, int) error                     { return nil }
-func (ExtIP) SupportsMapping() bool                                    { return false }
+func (ExtIP) AddMapping(string, int, int, string, time.Duration) (uint16, error) { return 0, nil }
+func (ExtIP) DeleteMapping(string, int, int) error                               { return nil }
+func (ExtIP) SupportsMapping() bool                                              { return false }
 
 // Any returns a port mapper that tries to discover any supported
 // mechanism on the local network.
 func Any() Interface {
 	// TODO: attempt to discover whether the local machine has an
 	// Internet-class address. Return ExtIP in this case.
-	return startautodisc("UPnP or NAT-PMP", func() Interface {
-		found := make(chan Interface, 2)
+	return startautodisc("UPnP, NAT-PMP or PCP", func() Interface {
+		found := make(chan Interface, 3)
 		go func() { found <- discoverUPnP() }()
 		go func() { found <- discoverPMP() }()
+		go func() { found <- discoverPCP() }()
 		for i := 0; i < cap(found); i++ {
 			if c := <-found; c != nil {
 				return c
@@ -194,6 +207,25 @@ func PMP(gateway net.IP) Interface {
 	return startautodisc("NAT-PMP", discoverPMP)
 }
 
+// PCP returns a port mapper that uses the Port Control Protocol (RFC 6887).
+// If the given gateway address is nil, PCP will attempt to auto-discover the router.
+func PCP(gateway net.IP) Interface {
+	if gateway != nil {
+		return newPCP(gateway)
+	}
+	return startautodisc("PCP", discoverPCP)
+}
+
+// Rediscover makes an auto-discovered mechanism look for the router again on the next call,
+// e.g. when the router was replaced or stopped responding. It returns false for the other mechanisms.
+func Rediscover(m Interface) bool {
+	if ad, ok := m.(*autodisc); ok {
+		ad.rediscover()
+		return true
+	}
+	return false
+}
+
 // autodisc represents a port mapping mechanism that is still being
 // auto-discovered. Calls to the Interface methods on this type will
 // wait until the discovery is done and then call the method on the
@@ -203,30 +235,33 @@ func PMP(gateway net.IP) Interface {
 // want return an Interface value from UPnP, PMP and Auto immediately.
 type autodisc struct {
 	what string // type of interface being autodiscovered
-	once sync.Once
 	doit func() Interface
 
+	discMu sync.Mutex // serializes the discovery
+	done   bool       // guarded by discMu
+
 	mu    sync.Mutex
 	found Interface
 }
 
 func startautodisc(what string, doit func() Interface) Interface {
-	// TODO: monitor network configuration and rerun doit when it changes.
 	return &autodisc{what: what, doit: doit}
 }
 
-func (n *autodisc) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) error {
-	if err := n.wait(); err != nil {
-		return err
+func (n *autodisc) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (uint16, error) {
+	found, err := n.wait()
+	if err != nil {
+		return 0, err
 	}
-	return n.found.AddMapping(protocol, extport, intport, name, lifetime)
+	return found.AddMapping(protocol, extport, intport, name, lifetime)
 }
 
 func (n *autodisc) DeleteMapping(protocol string, extport, intport int) error {
-	if err := n.wait(); err != nil {
+	found, err := n.wait()
+	if err != nil {
 		return err
 	}
-	return n.found.DeleteMapping(protocol, extport, intport)
+	return found.DeleteMapping(protocol, extport, intport)
 }
 
 func (n *autodisc) SupportsMapping() bool {
@@ -234,10 +269,11 @@ func (n *autodisc) SupportsMapping() bool {
 }
 
 func (n *autodisc) ExternalIP() (net.IP, error) {
-	if err := n.wait(); err != nil {
+	found, err := n.wait()
+	if err != nil {
 		return nil, err
 	}
-	return n.found.ExternalIP()
+	return found.ExternalIP()
 }
 
 func (n *autodisc) String() string {
@@ -250,14 +286,28 @@ func (n *autodisc) String() string {
 }
 
 // wait blocks until auto-discovery has been performed.
-func (n *autodisc) wait() error {
-	n.once.Do(func() {
+func (n *autodisc) wait() (Interface, error) {
+	n.discMu.Lock()
+	if !n.done {
+		found := n.doit()
 		n.mu.Lock()
-		n.found = n.doit()
+		n.found = found
 		n.mu.Unlock()
-	})
+		n.done = true
+	}
+	n.discMu.Unlock()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	if n.found == nil {
-		return fmt.Errorf("no %s router discovered", n.what)
+		return nil, fmt.Errorf("no %s router discovered", n.what)
 	}
-	return nil
+	return n.found, nil
+}
+
+// rediscover makes the next call run the discovery again.
+func (n *autodisc) rediscover() {
+	n.discMu.Lock()
+	defer n.discMu.Unlock()
+	n.done = false
 }
@@ -64,3 +64,29 @@ func TestAutoDiscRace(t *testing.T) {
 		}
 	}
 }
+
+func TestAutoDiscRediscover(t *testing.T) {
+	var runs int
+	ad := startautodisc("thing", func() Interface {
+		runs++
+		return ExtIP{33, 44, 55, byte(runs)}
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := ad.ExternalIP(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !Rediscover(ad) {
+		t.Fatal("autodisc is not rediscovered")
+	}
+	ip, err := ad.ExternalIP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 || !ip.Equal(net.IP{33, 44, 55, 2}) {
+		t.Errorf("got %d discoveries and IP %v, want 2 and 33.44.55.2", runs, ip)
+	}
+	if Rediscover(ExtIP{1, 2, 3, 4}) {
+		t.Error("ExtIP can't be rediscovered")
+	}
+}
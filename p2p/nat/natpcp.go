@@ -0,0 +1,261 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package nat
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	natpmp "github.com/jackpal/go-nat-pmp"
+
+	"github.com/erigontech/erigon-lib/common/debug"
+)
+
+// Port Control Protocol, RFC 6887
+const (
+	pcpPort    = 5351
+	pcpVersion = 2
+
+	pcpOpAnnounce = 0
+	pcpOpMap      = 1
+	pcpResponse   = 0x80
+
+	pcpHeaderSize = 24
+	pcpMapSize    = 36
+	pcpMaxSize    = 1100
+
+	pcpProtoTCP = 6
+	pcpProtoUDP = 17
+)
+
+var (
+	// request retransmission timeouts, the spec retries longer but the mappings are refreshed anyway
+	pcpTimeouts         = []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, time.Second, 2 * time.Second}
+	pcpDiscoverTimeouts = []time.Duration{time.Second}
+)
+
+var pcpResultCodes = map[byte]string{
+	1:  "UNSUPP_VERSION",
+	2:  "NOT_AUTHORIZED",
+	3:  "MALFORMED_REQUEST",
+	4:  "UNSUPP_OPCODE",
+	5:  "UNSUPP_OPTION",
+	6:  "MALFORMED_OPTION",
+	7:  "NETWORK_FAILURE",
+	8:  "NO_RESOURCES",
+	9:  "UNSUPP_PROTOCOL",
+	10: "USER_EX_QUOTA",
+	11: "CANNOT_PROVIDE_EXTERNAL",
+	12: "ADDRESS_MISMATCH",
+	13: "EXCESSIVE_REMOTE_PEERS",
+}
+
+// PCPError is a failure result code of the PCP server.
+type PCPError byte
+
+func (e PCPError) Error() string {
+	if name, ok := pcpResultCodes[byte(e)]; ok {
+		return "PCP " + name
+	}
+	return fmt.Sprintf("PCP result code %d", byte(e))
+}
+
+type pcpMappingKey struct {
+	protocol byte
+	port     uint16
+}
+
+type pcp struct {
+	gw   net.IP
+	port int
+
+	mu     sync.Mutex
+	nonces map[pcpMappingKey][12]byte // renewals and deletions must use the nonce of the mapping
+	extIP  net.IP                     // assigned by the last mapping
+}
+
+func newPCP(gw net.IP) *pcp {
+	return &pcp{gw: gw, port: pcpPort, nonces: map[pcpMappingKey][12]byte{}}
+}
+
+func (n *pcp) String() string {
+	return fmt.Sprintf("PCP(%v)", n.gw)
+}
+
+func (n *pcp) SupportsMapping() bool {
+	return true
+}
+
+// ExternalIP returns the address assigned by the last mapping. PCP has no request for the
+// external address, before the first mapping it is asked with NAT-PMP which the PCP servers usually support too.
+func (n *pcp) ExternalIP() (net.IP, error) {
+	n.mu.Lock()
+	ip := n.extIP
+	n.mu.Unlock()
+	if ip != nil {
+		return ip, nil
+	}
+	response, err := natpmp.NewClientWithTimeout(n.gw, time.Second).GetExternalAddress()
+	if err != nil {
+		return nil, fmt.Errorf("no PCP mapping yet and NAT-PMP failed: %w", err)
+	}
+	return response.ExternalIPAddress[:], nil
+}
+
+func (n *pcp) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (uint16, error) {
+	if lifetime <= 0 {
+		return 0, errors.New("lifetime must not be <= 0")
+	}
+	return n.mapPort(protocol, extport, intport, uint32(lifetime/time.Second))
+}
+
+func (n *pcp) DeleteMapping(protocol string, extport, intport int) error {
+	_, err := n.mapPort(protocol, extport, intport, 0)
+	return err
+}
+
+func (n *pcp) mapPort(protocol string, extport, intport int, lifetime uint32) (uint16, error) {
+	var proto byte
+	switch strings.ToUpper(protocol) {
+	case "TCP":
+		proto = pcpProtoTCP
+	case "UDP":
+		proto = pcpProtoUDP
+	default:
+		return 0, fmt.Errorf("unsupported protocol %s", protocol)
+	}
+	key := pcpMappingKey{protocol: proto, port: uint16(intport)}
+
+	n.mu.Lock()
+	nonce, ok := n.nonces[key]
+	n.mu.Unlock()
+	if !ok {
+		if _, err := rand.Read(nonce[:]); err != nil {
+			return 0, err
+		}
+	}
+
+	payload := make([]byte, pcpMapSize)
+	copy(payload[0:12], nonce[:])
+	payload[12] = proto
+	binary.BigEndian.PutUint16(payload[16:18], uint16(intport))
+	binary.BigEndian.PutUint16(payload[18:20], uint16(extport))
+	// any external IPv4 address
+	copy(payload[20:36], net.IPv4zero.To16())
+
+	resp, err := n.request(pcpOpMap, lifetime, payload, pcpTimeouts)
+	if err != nil {
+		return 0, err
+	}
+	if len(resp) < pcpHeaderSize+pcpMapSize {
+		return 0, errors.New("PCP MAP response is too short")
+	}
+	m := resp[pcpHeaderSize:]
+	if string(m[0:12]) != string(nonce[:]) {
+		return 0, errors.New("PCP MAP response nonce mismatch")
+	}
+	port := binary.BigEndian.Uint16(m[18:20])
+	ip := net.IP(append([]byte(nil), m[20:36]...))
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if lifetime == 0 {
+		delete(n.nonces, key)
+		return port, nil
+	}
+	n.nonces[key] = nonce
+	if !ip.IsUnspecified() {
+		n.extIP = ip
+	}
+	return port, nil
+}
+
+// request sends the request and waits for the successful response, the request is retransmitted after each timeout.
+func (n *pcp) request(op byte, lifetime uint32, payload []byte, timeouts []time.Duration) ([]byte, error) {
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: n.gw, Port: n.port})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	req := make([]byte, pcpHeaderSize+len(payload))
+	req[0] = pcpVersion
+	req[1] = op
+	binary.BigEndian.PutUint32(req[4:8], lifetime)
+	// the client address must be the one the server sees the request from
+	copy(req[8:24], conn.LocalAddr().(*net.UDPAddr).IP.To16())
+	copy(req[pcpHeaderSize:], payload)
+
+	buf := make([]byte, pcpMaxSize)
+	for _, timeout := range timeouts {
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+		deadline := time.Now().Add(timeout)
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+		for {
+			size, err := conn.Read(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return nil, err
+			}
+			resp := buf[:size]
+			if size < pcpHeaderSize || resp[0] != pcpVersion || resp[1] != op|pcpResponse {
+				continue
+			}
+			if code := resp[3]; code != 0 {
+				return nil, PCPError(code)
+			}
+			return append([]byte(nil), resp...), nil
+		}
+	}
+	return nil, fmt.Errorf("no PCP response from %v", n.gw)
+}
+
+func discoverPCP() Interface {
+	gws := potentialGateways()
+	found := make(chan *pcp, len(gws))
+	for i := range gws {
+		gw := gws[i]
+		go func() {
+			defer debug.LogPanic()
+			c := newPCP(gw)
+			if _, err := c.request(pcpOpAnnounce, 0, nil, pcpDiscoverTimeouts); err != nil {
+				found <- nil
+			} else {
+				found <- c
+			}
+		}()
+	}
+	for range gws {
+		if c := <-found; c != nil {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package nat
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakePCP answers the MAP requests assigning the next external port.
+func fakePCP(t *testing.T) (*net.UDPConn, chan []byte) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := make(chan []byte, 10)
+	go func() {
+		buf := make([]byte, pcpMaxSize)
+		for {
+			size, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			req := append([]byte(nil), buf[:size]...)
+			requests <- req
+
+			resp := make([]byte, pcpHeaderSize+pcpMapSize)
+			resp[0] = pcpVersion
+			resp[1] = req[1] | pcpResponse
+			copy(resp[4:8], req[4:8])
+			binary.BigEndian.PutUint32(resp[8:12], 1000) // epoch
+			copy(resp[pcpHeaderSize:], req[pcpHeaderSize:])
+			m := resp[pcpHeaderSize:]
+			intport := binary.BigEndian.Uint16(m[16:18])
+			if intport == 1 {
+				resp[3] = 2 // NOT_AUTHORIZED
+			}
+			binary.BigEndian.PutUint16(m[18:20], binary.BigEndian.Uint16(m[18:20])+1)
+			copy(m[20:36], net.IPv4(203, 0, 113, 7).To16())
+			if _, err := conn.WriteToUDP(resp, addr); err != nil {
+				return
+			}
+		}
+	}()
+	return conn, requests
+}
+
+func TestPCPMapping(t *testing.T) {
+	server, requests := fakePCP(t)
+	defer server.Close()
+
+	n := newPCP(net.IPv4(127, 0, 0, 1))
+	n.port = server.LocalAddr().(*net.UDPAddr).Port
+
+	port, err := n.AddMapping("tcp", 30303, 30303, "test", 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port != 30304 {
+		t.Errorf("got external port %d, want 30304", port)
+	}
+	req := <-requests
+	if req[1] != pcpOpMap || binary.BigEndian.Uint32(req[4:8]) != 600 || req[pcpHeaderSize+12] != pcpProtoTCP {
+		t.Errorf("bad MAP request %x", req)
+	}
+	if !net.IP(req[8:24]).Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("bad client address %v", net.IP(req[8:24]))
+	}
+	nonce := string(req[pcpHeaderSize : pcpHeaderSize+12])
+
+	ip, err := n.ExternalIP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ip.Equal(net.IPv4(203, 0, 113, 7)) {
+		t.Errorf("got external IP %v", ip)
+	}
+
+	// the renewal and the deletion use the nonce of the mapping
+	if _, err := n.AddMapping("tcp", 30303, 30303, "test", 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if req := <-requests; string(req[pcpHeaderSize:pcpHeaderSize+12]) != nonce {
+		t.Error("renewal nonce differs")
+	}
+	if err := n.DeleteMapping("tcp", 30303, 30303); err != nil {
+		t.Fatal(err)
+	}
+	req = <-requests
+	if string(req[pcpHeaderSize:pcpHeaderSize+12]) != nonce || binary.BigEndian.Uint32(req[4:8]) != 0 {
+		t.Errorf("bad delete request %x", req)
+	}
+
+	_, err = n.AddMapping("udp", 1, 1, "test", time.Minute)
+	var pcpErr PCPError
+	if !errors.As(err, &pcpErr) || pcpErr != 2 {
+		t.Errorf("got error %v, want NOT_AUTHORIZED", err)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/huin/goupnp"
 	"github.com/huin/goupnp/dcps/internetgateway1"
 	"github.com/huin/goupnp/dcps/internetgateway2"
+	"github.com/huin/goupnp/soap"
 
 	"github.com/erigontech/erigon-lib/common/debug"
 )
@@ -84,18 +85,37 @@ func (n *upnp) ExternalIP() (addr net.IP, err error) {
 	return ip, nil
 }
 
-func (n *upnp) AddMapping(protocol string, extport, intport int, desc string, lifetime time.Duration) error {
+func (n *upnp) AddMapping(protocol string, extport, intport int, desc string, lifetime time.Duration) (uint16, error) {
 	ip, err := n.internalAddress()
 	if err != nil {
-		return nil
+		return 0, err
 	}
 	protocol = strings.ToUpper(protocol)
 	lifetimeS := uint32(lifetime / time.Second)
 	n.DeleteMapping(protocol, extport, intport)
 
-	return n.withRateLimit(func() error {
+	err = n.withRateLimit(func() error {
 		return n.client.AddPortMapping("", uint16(extport), protocol, uint16(intport), ip.String(), true, desc, lifetimeS)
 	})
+	if err != nil && isOnlyPermanentLeasesError(err) {
+		// some routers support only the leases without expiration
+		err = n.withRateLimit(func() error {
+			return n.client.AddPortMapping("", uint16(extport), protocol, uint16(intport), ip.String(), true, desc, 0)
+		})
+	}
+	if err != nil {
+		return 0, err
+	}
+	return uint16(extport), nil
+}
+
+// isOnlyPermanentLeasesError checks for the OnlyPermanentLeasesSupported (725) UPnP error.
+func isOnlyPermanentLeasesError(err error) bool {
+	var soapErr *soap.SOAPFaultError
+	if !errors.As(err, &soapErr) {
+		return false
+	}
+	return soapErr.Detail.UPnPError.Errorcode == 725
 }
 
 func (n *upnp) internalAddress() (net.IP, error) {
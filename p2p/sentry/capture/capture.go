@@ -0,0 +1,397 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package capture records the devp2p messages exchanged by the sentry with its peers into rotating
+// files, so that malformed or surprising messages can be inspected and replayed offline.
+//
+// A capture file starts with the header and continues with the RLP encoded records, one per message.
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon/p2p"
+)
+
+const (
+	Magic   = "erigon-p2p-capture"
+	Version = 1
+
+	filePrefix     = "capture-"
+	fileExt        = ".rlp"
+	fileTimeFormat = "20060102T150405.000000000Z"
+
+	defaultMaxPayload = 10 * 1024 * 1024 // eth.ProtocolMaxMsgSize, larger messages are rejected by the sentry
+
+	bufferSize    = 1024 * 1024
+	flushInterval = time.Second
+)
+
+var ErrBadHeader = errors.New("not a capture file")
+
+type header struct {
+	Magic   string
+	Version uint64
+}
+
+// Record is a message sent to or received from a peer.
+type Record struct {
+	Time      uint64 // unix nanoseconds when the message was received or sent
+	PeerID    [64]byte
+	PeerName  string
+	Inbound   bool
+	Protocol  string // e.g. eth/68
+	Code      uint64
+	Size      uint32 // size of the message payload
+	Truncated bool   // the payload is larger than Config.MaxPayload and not recorded
+	Payload   []byte
+}
+
+func (r *Record) Timestamp() time.Time {
+	return time.Unix(0, int64(r.Time))
+}
+
+type Config struct {
+	Dir         string // capture is disabled if empty
+	MaxFileSize int64  // a new file is started when the current one reaches the size
+	MaxFiles    int    // the oldest files are deleted above the number, 0 - keep all
+	MaxPayload  int    // larger payloads are not recorded, only their size
+}
+
+func DefaultConfig(dir string) Config {
+	return Config{
+		Dir:         dir,
+		MaxFileSize: 128 * 1024 * 1024,
+		MaxFiles:    16,
+		MaxPayload:  defaultMaxPayload,
+	}
+}
+
+// Writer writes the records into the rotating files of the capture directory. It is safe for concurrent use.
+// The records are buffered in memory and flushed to the file every flushInterval, so the peer goroutines
+// don't wait for the disk. A write error disables the capture instead of breaking the peer connections.
+type Writer struct {
+	cfg    Config
+	logger log.Logger
+
+	lock   sync.Mutex
+	file   *os.File
+	buf    *bufio.Writer
+	size   int64
+	closed bool
+
+	stop sync.Once
+	quit chan struct{}
+	done chan struct{}
+}
+
+func NewWriter(cfg Config, logger log.Logger) (*Writer, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("capture directory is not set")
+	}
+	if cfg.MaxPayload <= 0 {
+		cfg.MaxPayload = defaultMaxPayload
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, err
+	}
+	w := &Writer{cfg: cfg, logger: logger, quit: make(chan struct{}), done: make(chan struct{})}
+	go w.flushLoop()
+	return w, nil
+}
+
+func (w *Writer) flushLoop() {
+	defer close(w.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.lock.Lock()
+			if w.buf != nil && w.buf.Buffered() > 0 {
+				if err := w.buf.Flush(); err != nil {
+					w.logger.Warn("[capture] write failed, capture is disabled", "dir", w.cfg.Dir, "err", err)
+					w.close()
+				}
+			}
+			w.lock.Unlock()
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// Write appends the record to the current file.
+func (w *Writer) Write(rec *Record) {
+	data, err := rlp.EncodeToBytes(rec)
+	if err != nil {
+		w.logger.Warn("[capture] can't encode record", "peer", rec.PeerName, "code", rec.Code, "err", err)
+		return
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.closed {
+		return
+	}
+	if err := w.write(data); err != nil {
+		w.logger.Warn("[capture] write failed, capture is disabled", "dir", w.cfg.Dir, "err", err)
+		w.close()
+	}
+}
+
+func (w *Writer) write(data []byte) error {
+	if w.file != nil && w.cfg.MaxFileSize > 0 && w.size+int64(len(data)) > w.cfg.MaxFileSize {
+		if err := w.closeFile(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := w.buf.Write(data)
+	w.size += int64(n)
+	return err
+}
+
+// rotate starts a new file and deletes the oldest ones.
+func (w *Writer) rotate() error {
+	name := filepath.Join(w.cfg.Dir, filePrefix+time.Now().UTC().Format(fileTimeFormat)+fileExt)
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	data, err := rlp.EncodeToBytes(&header{Magic: Magic, Version: Version})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	w.file, w.buf, w.size = f, bufio.NewWriterSize(f, bufferSize), int64(len(data))
+
+	if w.cfg.MaxFiles <= 0 {
+		return nil
+	}
+	files, err := Files(w.cfg.Dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > w.cfg.MaxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// Close flushes and closes the current file, records written after it are dropped.
+func (w *Writer) Close() error {
+	w.lock.Lock()
+	err := w.close()
+	w.lock.Unlock()
+	w.stop.Do(func() {
+		close(w.quit)
+		<-w.done
+	})
+	return err
+}
+
+func (w *Writer) close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.closeFile()
+}
+
+func (w *Writer) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.buf.Flush()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.file, w.buf = nil, nil
+	return err
+}
+
+// Wrap returns rw recording the messages of the peer.
+func (w *Writer) Wrap(rw p2p.MsgReadWriter, peerID [64]byte, peerName, protocol string) p2p.MsgReadWriter {
+	return &msgReadWriter{rw: rw, w: w, peerID: peerID, peerName: peerName, protocol: protocol}
+}
+
+type msgReadWriter struct {
+	rw       p2p.MsgReadWriter
+	w        *Writer
+	peerID   [64]byte
+	peerName string
+	protocol string
+}
+
+func (c *msgReadWriter) ReadMsg() (p2p.Msg, error) {
+	msg, err := c.rw.ReadMsg()
+	if err != nil {
+		return msg, err
+	}
+	receivedAt := msg.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+	return c.record(msg, true, receivedAt)
+}
+
+func (c *msgReadWriter) WriteMsg(msg p2p.Msg) error {
+	msg, err := c.record(msg, false, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.rw.WriteMsg(msg)
+}
+
+// record writes the message and returns it with the payload replaced by the buffered one.
+func (c *msgReadWriter) record(msg p2p.Msg, inbound bool, at time.Time) (p2p.Msg, error) {
+	rec := &Record{
+		Time:     uint64(at.UnixNano()),
+		PeerID:   c.peerID,
+		PeerName: c.peerName,
+		Inbound:  inbound,
+		Protocol: c.protocol,
+		Code:     msg.Code,
+		Size:     msg.Size,
+	}
+	if int(msg.Size) > c.w.cfg.MaxPayload {
+		rec.Truncated = true
+		c.w.Write(rec)
+		return msg, nil
+	}
+	payload := make([]byte, msg.Size)
+	if _, err := io.ReadFull(msg.Payload, payload); err != nil {
+		return msg, err
+	}
+	msg.Payload = bytes.NewReader(payload)
+	rec.Payload = payload
+	c.w.Write(rec)
+	return msg, nil
+}
+
+// Files returns the capture files of the directory, the oldest first.
+func Files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), filePrefix) && strings.HasSuffix(e.Name(), fileExt) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Reader reads the records of a capture file.
+type Reader struct {
+	stream *rlp.Stream
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	stream := rlp.NewStream(r, 0)
+	var h header
+	if err := stream.Decode(&h); err != nil || h.Magic != Magic {
+		return nil, ErrBadHeader
+	}
+	if h.Version != Version {
+		return nil, fmt.Errorf("unsupported capture version %d", h.Version)
+	}
+	return &Reader{stream: stream}, nil
+}
+
+// Next returns the next record or io.EOF at the end of the file.
+func (r *Reader) Next() (*Record, error) {
+	var rec Record
+	if err := r.stream.Decode(&rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// ReadFile reads all the records of the file. The last record cut by the node crash is ignored.
+func ReadFile(path string) ([]*Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var records []*Record
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		records = append(records, rec)
+	}
+}
+
+// Read reads the records of a capture file or of all the files of a capture directory.
+func Read(path string) ([]*Record, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return ReadFile(path)
+	}
+	files, err := Files(path)
+	if err != nil {
+		return nil, err
+	}
+	var records []*Record
+	for _, file := range files {
+		fileRecords, err := ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, fileRecords...)
+	}
+	return records, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package capture
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/p2p"
+)
+
+func TestCaptureRoundTrip(t *testing.T) {
+	cfg := DefaultConfig(t.TempDir())
+	cfg.MaxPayload = 16
+	w, err := NewWriter(cfg, log.New())
+	require.NoError(t, err)
+
+	local, remote := p2p.MsgPipe()
+	defer local.Close()
+	peerID := [64]byte{1, 2, 3}
+	rw := w.Wrap(local, peerID, "peer-1", "eth/68")
+
+	go func() {
+		_ = p2p.Send(remote, 0x03, []uint64{1, 2, 3})
+		_ = p2p.Send(remote, 0x05, make([]byte, 100))
+	}()
+	msg, err := rw.ReadMsg()
+	require.NoError(t, err)
+	var got []uint64
+	require.NoError(t, msg.Decode(&got)) // the payload is still readable
+	require.Equal(t, []uint64{1, 2, 3}, got)
+	msg, err = rw.ReadMsg()
+	require.NoError(t, err)
+	msg.Discard()
+
+	go func() {
+		for i := 0; i < 2; i++ {
+			msg, _ := remote.ReadMsg()
+			msg.Discard()
+		}
+	}()
+	require.NoError(t, p2p.Send(rw, 0x04, "hi"))
+	require.NoError(t, w.Close())
+	require.NoError(t, p2p.Send(rw, 0x04, "dropped"))
+
+	records, err := Read(cfg.Dir)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	require.True(t, records[0].Inbound)
+	require.Equal(t, peerID, records[0].PeerID)
+	require.Equal(t, "peer-1", records[0].PeerName)
+	require.Equal(t, "eth/68", records[0].Protocol)
+	require.Equal(t, uint64(0x03), records[0].Code)
+	require.Equal(t, []byte{0xc3, 1, 2, 3}, records[0].Payload)
+	require.NotZero(t, records[0].Time)
+
+	require.True(t, records[1].Truncated)
+	require.Equal(t, uint32(102), records[1].Size)
+	require.Empty(t, records[1].Payload)
+
+	require.False(t, records[2].Inbound)
+	require.Equal(t, uint64(0x04), records[2].Code)
+	require.Equal(t, []byte{0x82, 'h', 'i'}, records[2].Payload)
+}
+
+func TestCaptureRotation(t *testing.T) {
+	cfg := DefaultConfig(t.TempDir())
+	cfg.MaxFileSize = 300
+	cfg.MaxFiles = 2
+	w, err := NewWriter(cfg, log.New())
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		w.Write(&Record{Time: uint64(i), Code: uint64(i), Payload: make([]byte, 100)})
+	}
+	require.NoError(t, w.Close())
+
+	files, err := Files(cfg.Dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	// only the newest records are kept
+	records, err := Read(cfg.Dir)
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+	require.Less(t, len(records), 20)
+	for i, rec := range records {
+		require.Equal(t, uint64(20-len(records)+i), rec.Code)
+	}
+}
+
+func TestCaptureFlush(t *testing.T) {
+	cfg := DefaultConfig(t.TempDir())
+	w, err := NewWriter(cfg, log.New())
+	require.NoError(t, err)
+	defer w.Close()
+
+	w.Write(&Record{Time: 1, Code: 1, Payload: []byte{0xc0}})
+	// the buffered record reaches the file without closing the writer
+	require.Eventually(t, func() bool {
+		records, err := Read(cfg.Dir)
+		return err == nil && len(records) == 1
+	}, 5*flushInterval, flushInterval/10)
+}
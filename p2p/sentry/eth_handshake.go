@@ -19,6 +19,7 @@ package sentry
 import (
 	"fmt"
 
+	"github.com/erigontech/erigon-lib/direct"
 	"github.com/erigontech/erigon-lib/gointerfaces"
 	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
 	"github.com/erigontech/erigon/core/forkid"
@@ -26,24 +27,56 @@ import (
 	"github.com/erigontech/erigon/p2p"
 )
 
+// peerStatus - status of the peer received in the handshake
+type peerStatus struct {
+	*eth.StatusPacket
+	// BlockRange - blocks the peer serves, announced since eth/69, nil before
+	BlockRange *eth.BlockRangeUpdatePacket
+}
+
+// makeStatusPacket - own status message for the protocol version
+func makeStatusPacket(status *proto_sentry.StatusData, version uint) eth.Packet {
+	genesisHash := gointerfaces.ConvertH256ToHash(status.ForkData.Genesis)
+	forkID := forkid.NewIDFromForks(status.ForkData.HeightForks, status.ForkData.TimeForks, genesisHash, status.MaxBlockHeight, status.MaxBlockTime)
+	if version >= direct.ETH69 {
+		return &eth.StatusPacket69{
+			ProtocolVersion: uint32(version),
+			NetworkID:       status.NetworkId,
+			Genesis:         genesisHash,
+			ForkID:          forkID,
+			EarliestBlock:   status.MinBlockHeight,
+			LatestBlock:     status.MaxBlockHeight,
+			LatestBlockHash: gointerfaces.ConvertH256ToHash(status.BestHash),
+		}
+	}
+	return &eth.StatusPacket{
+		ProtocolVersion: uint32(version),
+		NetworkID:       status.NetworkId,
+		TD:              gointerfaces.ConvertH256ToUint256Int(status.TotalDifficulty).ToBig(),
+		Head:            gointerfaces.ConvertH256ToHash(status.BestHash),
+		Genesis:         genesisHash,
+		ForkID:          forkID,
+	}
+}
+
 func readAndValidatePeerStatusMessage(
 	rw p2p.MsgReadWriter,
 	status *proto_sentry.StatusData,
 	version uint,
 	minVersion uint,
-) (*eth.StatusPacket, *p2p.PeerError) {
+) (*peerStatus, *p2p.PeerError) {
 	msg, err := rw.ReadMsg()
 	if err != nil {
 		return nil, p2p.NewPeerError(p2p.PeerErrorStatusReceive, p2p.DiscNetworkError, err, "readAndValidatePeerStatusMessage rw.ReadMsg error")
 	}
 
-	reply, err := tryDecodeStatusMessage(&msg)
+	reply, err := tryDecodeStatusMessage(&msg, version)
 	msg.Discard()
 	if err != nil {
 		return nil, p2p.NewPeerError(p2p.PeerErrorStatusDecode, p2p.DiscProtocolError, err, "readAndValidatePeerStatusMessage tryDecodeStatusMessage error")
 	}
 
-	err = checkPeerStatusCompatibility(reply, status, version, minVersion)
+	err = checkPeerStatusCompatibility(reply.StatusPacket, status, version, minVersion)
 	if err != nil {
 		return nil, p2p.NewPeerError(p2p.PeerErrorStatusIncompatible, p2p.DiscUselessPeer, err, "readAndValidatePeerStatusMessage checkPeerStatusCompatibility error")
 	}
@@ -51,7 +84,7 @@ func readAndValidatePeerStatusMessage(
 	return reply, nil
 }
 
-func tryDecodeStatusMessage(msg *p2p.Msg) (*eth.StatusPacket, error) {
+func tryDecodeStatusMessage(msg *p2p.Msg, version uint) (*peerStatus, error) {
 	if msg.Code != eth.StatusMsg {
 		return nil, fmt.Errorf("first msg has code %x (!= %x)", msg.Code, eth.StatusMsg)
 	}
@@ -60,12 +93,35 @@ func tryDecodeStatusMessage(msg *p2p.Msg) (*eth.StatusPacket, error) {
 		return nil, fmt.Errorf("message is too large %d, limit %d", msg.Size, eth.ProtocolMaxMsgSize)
 	}
 
-	var reply eth.StatusPacket
+	if version < direct.ETH69 {
+		var reply eth.StatusPacket
+		if err := msg.Decode(&reply); err != nil {
+			return nil, fmt.Errorf("decode message %v: %w", msg, err)
+		}
+		return &peerStatus{StatusPacket: &reply}, nil
+	}
+
+	var reply eth.StatusPacket69
 	if err := msg.Decode(&reply); err != nil {
 		return nil, fmt.Errorf("decode message %v: %w", msg, err)
 	}
-
-	return &reply, nil
+	if reply.EarliestBlock > reply.LatestBlock {
+		return nil, fmt.Errorf("invalid block range: earliest %d, latest %d", reply.EarliestBlock, reply.LatestBlock)
+	}
+	return &peerStatus{
+		StatusPacket: &eth.StatusPacket{
+			ProtocolVersion: reply.ProtocolVersion,
+			NetworkID:       reply.NetworkID,
+			Head:            reply.LatestBlockHash,
+			Genesis:         reply.Genesis,
+			ForkID:          reply.ForkID,
+		},
+		BlockRange: &eth.BlockRangeUpdatePacket{
+			EarliestBlock:   reply.EarliestBlock,
+			LatestBlock:     reply.LatestBlock,
+			LatestBlockHash: reply.LatestBlockHash,
+		},
+	}, nil
 }
 
 func checkPeerStatusCompatibility(
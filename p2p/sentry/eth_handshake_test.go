@@ -22,14 +22,17 @@ import (
 
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/direct"
 	"github.com/erigontech/erigon-lib/gointerfaces"
 	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/rlp"
 
 	"github.com/erigontech/erigon/core/forkid"
 	"github.com/erigontech/erigon/eth/protocols/eth"
+	"github.com/erigontech/erigon/p2p"
 	"github.com/erigontech/erigon/params"
 )
 
@@ -97,3 +100,42 @@ func TestCheckPeerStatusCompatibility(t *testing.T) {
 		assert.ErrorIs(t, err, forkid.ErrLocalIncompatibleOrStale)
 	})
 }
+
+func TestDecodeStatusMessage69(t *testing.T) {
+	heightForks, timeForks := forkid.GatherForks(params.MainnetChainConfig, 0 /* genesisTime */)
+	status := &proto_sentry.StatusData{
+		NetworkId:       1,
+		TotalDifficulty: gointerfaces.ConvertUint256IntToH256(uint256.NewInt(1)),
+		BestHash:        gointerfaces.ConvertHashToH256(libcommon.HexToHash("0x0100")),
+		MinBlockHeight:  100,
+		MaxBlockHeight:  200,
+		ForkData: &proto_sentry.Forks{
+			Genesis:     gointerfaces.ConvertHashToH256(params.MainnetGenesisHash),
+			HeightForks: heightForks,
+			TimeForks:   timeForks,
+		},
+	}
+	encode := func(packet eth.Packet) *p2p.Msg {
+		size, r, err := rlp.EncodeToReader(packet)
+		require.NoError(t, err)
+		return &p2p.Msg{Code: eth.StatusMsg, Size: uint32(size), Payload: r}
+	}
+
+	packet := makeStatusPacket(status, direct.ETH69)
+	require.IsType(t, &eth.StatusPacket69{}, packet)
+	reply, err := tryDecodeStatusMessage(encode(packet), direct.ETH69)
+	require.NoError(t, err)
+	assert.Equal(t, &eth.BlockRangeUpdatePacket{EarliestBlock: 100, LatestBlock: 200, LatestBlockHash: libcommon.HexToHash("0x0100")}, reply.BlockRange)
+	assert.Equal(t, libcommon.HexToHash("0x0100"), reply.Head)
+	assert.NoError(t, checkPeerStatusCompatibility(reply.StatusPacket, status, direct.ETH69, direct.ETH69))
+
+	// status of earlier versions has no block range
+	reply, err = tryDecodeStatusMessage(encode(makeStatusPacket(status, direct.ETH68)), direct.ETH68)
+	require.NoError(t, err)
+	assert.Nil(t, reply.BlockRange)
+
+	invalid := packet.(*eth.StatusPacket69)
+	invalid.EarliestBlock = invalid.LatestBlock + 1
+	_, err = tryDecodeStatusMessage(encode(invalid), direct.ETH69)
+	assert.ErrorContains(t, err, "invalid block range")
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package peerscore
+
+import (
+	"github.com/erigontech/erigon/p2p/enode"
+	"github.com/erigontech/erigon/rpc"
+)
+
+// API exposes the peer scores in the admin namespace.
+type API struct {
+	scorer *Scorer
+}
+
+func NewAPI(scorer *Scorer) *API {
+	return &API{scorer: scorer}
+}
+
+func (api *API) APIs() []rpc.API {
+	return []rpc.API{{
+		Namespace: "admin",
+		Public:    false,
+		Service:   api,
+		Version:   "1.0",
+	}}
+}
+
+// PeerScores returns scores of the tracked peers, the worst ones first.
+func (api *API) PeerScores() []*PeerScore {
+	return api.scorer.Scores()
+}
+
+// BannedPeers returns the banlist.
+func (api *API) BannedPeers() []BannedPeer {
+	return api.scorer.BannedPeers()
+}
+
+// UnbanPeer removes the node with given enode ID or URL from the banlist.
+func (api *API) UnbanPeer(node string) (bool, error) {
+	id, err := enode.ParseID(node)
+	if err != nil {
+		n, err := enode.Parse(enode.ValidSchemes, node)
+		if err != nil {
+			return false, err
+		}
+		id = n.ID()
+	}
+	return api.scorer.Unban(id)
+}
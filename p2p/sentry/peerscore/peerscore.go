@@ -0,0 +1,454 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package peerscore keeps reputation of the sentry peers. Scores are built from the
+// events observed per peer and protocol (served responses, timeouts, invalid data) and
+// decay towards zero over time, so old misbehaviour is eventually forgiven. Peers whose
+// score drops below the thresholds are disconnected and banned, bans are persisted and
+// keep the peers from being dialed or accepted until they expire.
+package peerscore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/common/dir"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/p2p/enode"
+)
+
+// Event is an observation about the behaviour of a peer.
+type Event int
+
+const (
+	Useful            Event = iota // served a non-empty response
+	Empty                          // served a response without data
+	Timeout                        // didn't respond to a request in time
+	InvalidData                    // served data which failed validation
+	ProtocolViolation              // broke the rules of the protocol
+)
+
+var eventNames = [...]string{"useful", "empty", "timeout", "invalid", "violation"}
+
+func (e Event) String() string {
+	if int(e) < len(eventNames) {
+		return eventNames[e]
+	}
+	return fmt.Sprintf("event(%d)", int(e))
+}
+
+// Action is the decision taken for a peer after its score was updated.
+type Action int
+
+const (
+	None       Action = iota
+	Disconnect        // score is below Config.DisconnectScore
+	Ban               // score is below Config.BanScore
+)
+
+func (a Action) String() string {
+	switch a {
+	case Disconnect:
+		return "disconnect"
+	case Ban:
+		return "ban"
+	default:
+		return "none"
+	}
+}
+
+type Config struct {
+	// Weights are the score changes caused by the events
+	Weights map[Event]float64
+	// HalfLife is the time in which a score decays to the half of its value
+	HalfLife time.Duration
+	// MaxScore caps the score of a peer in a protocol, so that the rewards collected by a
+	// busy peer can't outweigh its misbehaviour. No cap if 0
+	MaxScore float64
+	// Scores at which peers are disconnected and banned
+	DisconnectScore float64
+	BanScore        float64
+	// BanDuration is how long banned peers are refused
+	BanDuration time.Duration
+	// BanListFile persists the bans, kept in memory only if empty
+	BanListFile string
+	// MaxTracked is the number of tracked peers above which the decayed scores are forgotten,
+	// followed by the least recently seen peers which aren't banned
+	MaxTracked int
+}
+
+func DefaultConfig(banListFile string) Config {
+	return Config{
+		Weights: map[Event]float64{
+			Useful:            1,
+			Empty:             -0.5,
+			Timeout:           -5,
+			InvalidData:       -25,
+			ProtocolViolation: -50,
+		},
+		HalfLife:        10 * time.Minute,
+		MaxScore:        10,
+		DisconnectScore: -40,
+		BanScore:        -100,
+		BanDuration:     24 * time.Hour,
+		BanListFile:     banListFile,
+		MaxTracked:      4096,
+	}
+}
+
+// ProtocolScore is the score of a peer in one of the protocols it runs.
+type ProtocolScore struct {
+	Score     float64       `json:"score"`
+	Useful    uint64        `json:"useful"`
+	Empty     uint64        `json:"empty"`
+	Timeouts  uint64        `json:"timeouts"`
+	Invalid   uint64        `json:"invalid"`
+	Violation uint64        `json:"violations"`
+	Latency   time.Duration `json:"latency"` // moving average of the response latency
+}
+
+// PeerScore is the reputation of a peer, its score is the sum of the protocol scores.
+type PeerScore struct {
+	ID        string                    `json:"id"`     // enode ID
+	PubKey    string                    `json:"pubkey"` // sentry peer ID
+	Score     float64                   `json:"score"`
+	Protocols map[string]*ProtocolScore `json:"protocols"`
+	Updated   time.Time                 `json:"updated"`
+}
+
+// BannedPeer is an entry of the banlist.
+type BannedPeer struct {
+	ID     enode.ID  `json:"id"`
+	Until  time.Time `json:"until"`
+	Reason string    `json:"reason"`
+}
+
+type peer struct {
+	protocols map[string]*ProtocolScore
+	updated   time.Time // last decay of the scores
+	seen      time.Time // last event of the peer
+}
+
+// Scorer tracks scores of the peers, it's shared by the sentries of the node.
+type Scorer struct {
+	cfg    Config
+	lock   sync.Mutex
+	peers  map[[64]byte]*peer
+	banned map[enode.ID]BannedPeer
+	now    func() time.Time
+	logger log.Logger
+}
+
+func New(cfg Config, logger log.Logger) *Scorer {
+	s := &Scorer{
+		cfg:    cfg,
+		peers:  make(map[[64]byte]*peer),
+		banned: make(map[enode.ID]BannedPeer),
+		now:    time.Now,
+		logger: logger,
+	}
+	if err := s.loadBanList(); err != nil {
+		logger.Warn("[p2p] Can't read peer banlist", "file", cfg.BanListFile, "err", err)
+	}
+	return s
+}
+
+// Record accounts the event observed for the peer in the protocol and returns the action
+// to take for the peer. Banning is left to the caller, see Ban.
+func (s *Scorer) Record(peerID [64]byte, protocol string, event Event) Action {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ps := s.protocolScore(peerID, protocol)
+	ps.Score += s.cfg.Weights[event]
+	if s.cfg.MaxScore > 0 && ps.Score > s.cfg.MaxScore {
+		ps.Score = s.cfg.MaxScore
+	}
+	switch event {
+	case Useful:
+		ps.Useful++
+	case Empty:
+		ps.Empty++
+	case Timeout:
+		ps.Timeouts++
+	case InvalidData:
+		ps.Invalid++
+	case ProtocolViolation:
+		ps.Violation++
+	}
+	return s.action(peerID)
+}
+
+// RecordResponse accounts the response to a request of ours which took given time.
+func (s *Scorer) RecordResponse(peerID [64]byte, protocol string, latency time.Duration, empty bool) Action {
+	event := Useful
+	if empty {
+		event = Empty
+	}
+	action := s.Record(peerID, protocol, event)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ps := s.protocolScore(peerID, protocol)
+	if ps.Latency == 0 {
+		ps.Latency = latency
+	} else {
+		ps.Latency = (ps.Latency*7 + latency) / 8
+	}
+	return action
+}
+
+// Score returns current score of the peer, 0 for unknown peers.
+func (s *Scorer) Score(peerID [64]byte) float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.score(peerID)
+}
+
+// Ban refuses the peer for the configured ban duration.
+func (s *Scorer) Ban(peerID [64]byte, reason string) {
+	id := PeerIDToNodeID(peerID)
+	s.lock.Lock()
+	s.banned[id] = BannedPeer{ID: id, Until: s.now().Add(s.cfg.BanDuration), Reason: reason}
+	err := s.saveBanList()
+	s.lock.Unlock()
+
+	s.logger.Debug("[p2p] Peer banned", "id", id, "reason", reason, "duration", s.cfg.BanDuration)
+	if err != nil {
+		s.logger.Warn("[p2p] Can't write peer banlist", "file", s.cfg.BanListFile, "err", err)
+	}
+}
+
+// Unban removes the peer from the banlist, returns false if it wasn't banned.
+func (s *Scorer) Unban(id enode.ID) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.banned[id]; !ok {
+		return false, nil
+	}
+	delete(s.banned, id)
+	return true, s.saveBanList()
+}
+
+// Banned reports if the node can't be dialed or accepted. Used by the p2p server.
+func (s *Scorer) Banned(id enode.ID) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ban, ok := s.banned[id]
+	if !ok {
+		return false
+	}
+	if s.now().After(ban.Until) {
+		delete(s.banned, id)
+		return false
+	}
+	return true
+}
+
+// Scores returns the scores of the tracked peers, the worst ones first.
+func (s *Scorer) Scores() []*PeerScore {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	res := make([]*PeerScore, 0, len(s.peers))
+	for peerID, p := range s.peers {
+		s.decay(p, now)
+		ps := &PeerScore{
+			ID:        PeerIDToNodeID(peerID).String(),
+			PubKey:    hex.EncodeToString(peerID[:]),
+			Protocols: make(map[string]*ProtocolScore, len(p.protocols)),
+			Updated:   p.seen,
+		}
+		for name, protocol := range p.protocols {
+			protocolCopy := *protocol
+			ps.Protocols[name] = &protocolCopy
+			ps.Score += protocol.Score
+		}
+		res = append(res, ps)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Score < res[j].Score })
+	return res
+}
+
+// BannedPeers returns the entries of the banlist which haven't expired yet.
+func (s *Scorer) BannedPeers() []BannedPeer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	res := make([]BannedPeer, 0, len(s.banned))
+	for _, ban := range s.banned {
+		if now.Before(ban.Until) {
+			res = append(res, ban)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Until.Before(res[j].Until) })
+	return res
+}
+
+// protocolScore returns decayed score of the peer in the protocol, creating the missing entries.
+func (s *Scorer) protocolScore(peerID [64]byte, protocol string) *ProtocolScore {
+	now := s.now()
+	p, ok := s.peers[peerID]
+	if !ok {
+		if len(s.peers) >= s.cfg.MaxTracked {
+			s.prune(now)
+		}
+		p = &peer{protocols: make(map[string]*ProtocolScore), updated: now, seen: now}
+		s.peers[peerID] = p
+	}
+	s.decay(p, now)
+	p.seen = now
+	ps, ok := p.protocols[protocol]
+	if !ok {
+		ps = &ProtocolScore{}
+		p.protocols[protocol] = ps
+	}
+	return ps
+}
+
+func (s *Scorer) decay(p *peer, now time.Time) {
+	elapsed := now.Sub(p.updated)
+	if elapsed <= 0 || s.cfg.HalfLife <= 0 {
+		return
+	}
+	factor := math.Pow(0.5, float64(elapsed)/float64(s.cfg.HalfLife))
+	for _, ps := range p.protocols {
+		ps.Score *= factor
+	}
+	p.updated = now
+}
+
+// prune forgets the peers whose scores decayed to almost nothing. If there are still too
+// many of them, the least recently seen peers are forgotten, except for the banned ones.
+func (s *Scorer) prune(now time.Time) {
+	for peerID, p := range s.peers {
+		s.decay(p, now)
+		var score float64
+		for _, ps := range p.protocols {
+			score += math.Abs(ps.Score)
+		}
+		if score < 1 {
+			delete(s.peers, peerID)
+		}
+	}
+	if len(s.peers) < s.cfg.MaxTracked {
+		return
+	}
+
+	evictable := make([][64]byte, 0, len(s.peers))
+	for peerID := range s.peers {
+		if ban, ok := s.banned[PeerIDToNodeID(peerID)]; ok && now.Before(ban.Until) {
+			continue
+		}
+		evictable = append(evictable, peerID)
+	}
+	sort.Slice(evictable, func(i, j int) bool {
+		return s.peers[evictable[i]].seen.Before(s.peers[evictable[j]].seen)
+	})
+	for _, peerID := range evictable {
+		if len(s.peers) < s.cfg.MaxTracked {
+			break
+		}
+		delete(s.peers, peerID)
+	}
+}
+
+func (s *Scorer) score(peerID [64]byte) float64 {
+	p, ok := s.peers[peerID]
+	if !ok {
+		return 0
+	}
+	s.decay(p, s.now())
+	var score float64
+	for _, ps := range p.protocols {
+		score += ps.Score
+	}
+	return score
+}
+
+func (s *Scorer) action(peerID [64]byte) Action {
+	score := s.score(peerID)
+	switch {
+	case score <= s.cfg.BanScore:
+		return Ban
+	case score <= s.cfg.DisconnectScore:
+		return Disconnect
+	default:
+		return None
+	}
+}
+
+func (s *Scorer) loadBanList() error {
+	if s.cfg.BanListFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.cfg.BanListFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var bans []BannedPeer
+	if err = json.Unmarshal(data, &bans); err != nil {
+		return err
+	}
+	now := s.now()
+	for _, ban := range bans {
+		if now.Before(ban.Until) {
+			s.banned[ban.ID] = ban
+		}
+	}
+	return nil
+}
+
+// saveBanList writes the unexpired bans, must be called with the lock held.
+func (s *Scorer) saveBanList() error {
+	if s.cfg.BanListFile == "" {
+		return nil
+	}
+	now := s.now()
+	bans := make([]BannedPeer, 0, len(s.banned))
+	for id, ban := range s.banned {
+		if now.After(ban.Until) {
+			delete(s.banned, id)
+			continue
+		}
+		bans = append(bans, ban)
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Until.Before(bans[j].Until) })
+	data, err := json.MarshalIndent(bans, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.cfg.BanListFile + ".tmp"
+	if err = dir.WriteFileWithFsync(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.cfg.BanListFile)
+}
+
+// PeerIDToNodeID converts the sentry peer ID (public key) to the enode ID.
+func PeerIDToNodeID(peerID [64]byte) enode.ID {
+	return enode.ID(crypto.Keccak256Hash(peerID[:]))
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package peerscore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time          { return c.now }
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestScorer(t *testing.T, banListFile string) (*Scorer, *testClock) {
+	clock := &testClock{now: time.Unix(1_700_000_000, 0)}
+	s := New(DefaultConfig(banListFile), log.New())
+	s.now = clock.Now
+	require.NoError(t, s.loadBanList())
+	return s, clock
+}
+
+func TestScoreDecay(t *testing.T) {
+	s, clock := newTestScorer(t, "")
+	peer := [64]byte{1}
+
+	for i := 0; i < 4; i++ {
+		require.Equal(t, None, s.Record(peer, "eth/68", Timeout))
+	}
+	require.Equal(t, -20.0, s.Score(peer))
+
+	clock.Advance(s.cfg.HalfLife)
+	require.InDelta(t, -10.0, s.Score(peer), 1e-9)
+	clock.Advance(2 * s.cfg.HalfLife)
+	require.InDelta(t, -2.5, s.Score(peer), 1e-9)
+
+	require.Equal(t, 0.0, s.Score([64]byte{2}))
+}
+
+func TestScoreActions(t *testing.T) {
+	s, _ := newTestScorer(t, "")
+	peer := [64]byte{1}
+
+	require.Equal(t, None, s.RecordResponse(peer, "eth/68", 100*time.Millisecond, false))
+	require.Equal(t, None, s.Record(peer, "eth/68", InvalidData))
+	require.Equal(t, Disconnect, s.Record(peer, "snap/1", InvalidData))
+	require.Equal(t, Disconnect, s.Record(peer, "eth/68", ProtocolViolation))
+	require.Equal(t, Ban, s.Record(peer, "eth/68", Timeout))
+
+	scores := s.Scores()
+	require.Len(t, scores, 1)
+	require.Equal(t, -104.0, scores[0].Score)
+	require.Equal(t, PeerIDToNodeID(peer).String(), scores[0].ID)
+	eth := scores[0].Protocols["eth/68"]
+	require.Equal(t, uint64(1), eth.Useful)
+	require.Equal(t, uint64(1), eth.Invalid)
+	require.Equal(t, uint64(1), eth.Violation)
+	require.Equal(t, 100*time.Millisecond, eth.Latency)
+	require.Equal(t, uint64(1), scores[0].Protocols["snap/1"].Invalid)
+
+	// useful peers come last
+	s.RecordResponse([64]byte{2}, "eth/68", time.Second, false)
+	s.RecordResponse([64]byte{3}, "eth/68", time.Second, true)
+	scores = s.Scores()
+	require.Len(t, scores, 3)
+	require.Equal(t, PeerIDToNodeID([64]byte{3}).String(), scores[1].ID)
+	require.Equal(t, PeerIDToNodeID([64]byte{2}).String(), scores[2].ID)
+}
+
+func TestScoreCap(t *testing.T) {
+	s, clock := newTestScorer(t, "")
+	peer := [64]byte{1}
+
+	// a busy peer can't collect more than the cap
+	for i := 0; i < 10_000; i++ {
+		require.Equal(t, None, s.RecordResponse(peer, "eth/68", 10*time.Millisecond, false))
+		clock.Advance(10 * time.Millisecond)
+	}
+	require.InDelta(t, s.cfg.MaxScore, s.Score(peer), 1e-3)
+
+	// so its misbehaviour still counts
+	require.Equal(t, None, s.Record(peer, "eth/68", InvalidData))
+	require.Equal(t, Disconnect, s.Record(peer, "eth/68", InvalidData))
+	require.Equal(t, Disconnect, s.Record(peer, "eth/68", ProtocolViolation))
+	require.Equal(t, Ban, s.Record(peer, "eth/68", ProtocolViolation))
+}
+
+func TestPruneTracked(t *testing.T) {
+	s, clock := newTestScorer(t, "")
+	s.cfg.MaxTracked = 4
+	banned := [64]byte{0}
+	s.Record(banned, "eth/68", ProtocolViolation)
+	s.Ban(banned, "test")
+	for i := byte(1); i < 4; i++ {
+		clock.Advance(time.Second)
+		s.Record([64]byte{i}, "eth/68", Timeout)
+	}
+	// peer 1 is seen again, so peer 2 is the least recently seen
+	clock.Advance(time.Second)
+	s.Record([64]byte{1}, "eth/68", Timeout)
+
+	// none of the scores decayed away, yet the number of tracked peers stays bounded
+	for i := byte(4); i < 6; i++ {
+		clock.Advance(time.Second)
+		s.Record([64]byte{i}, "eth/68", Timeout)
+		require.Len(t, s.peers, s.cfg.MaxTracked)
+	}
+	require.Contains(t, s.peers, banned)
+	require.Contains(t, s.peers, [64]byte{1})
+	require.NotContains(t, s.peers, [64]byte{2})
+	require.NotContains(t, s.peers, [64]byte{3})
+	require.Contains(t, s.peers, [64]byte{4})
+	require.Contains(t, s.peers, [64]byte{5})
+}
+
+func TestBanList(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "banlist.json")
+	s, clock := newTestScorer(t, file)
+	peer, other := [64]byte{1}, [64]byte{2}
+
+	s.Ban(peer, "test")
+	require.True(t, s.Banned(PeerIDToNodeID(peer)))
+	require.False(t, s.Banned(PeerIDToNodeID(other)))
+
+	clock.Advance(time.Hour)
+	s.Ban(other, "test")
+
+	// bans survive the restart
+	restarted, restartedClock := newTestScorer(t, file)
+	restartedClock.now = clock.now
+	require.True(t, restarted.Banned(PeerIDToNodeID(peer)))
+	require.True(t, restarted.Banned(PeerIDToNodeID(other)))
+	require.Len(t, restarted.BannedPeers(), 2)
+
+	ok, err := restarted.Unban(PeerIDToNodeID(other))
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = restarted.Unban(PeerIDToNodeID(other))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// and expire
+	restartedClock.Advance(s.cfg.BanDuration)
+	require.False(t, restarted.Banned(PeerIDToNodeID(peer)))
+	require.Empty(t, restarted.BannedPeers())
+
+	reloaded, reloadedClock := newTestScorer(t, file)
+	reloadedClock.now = clock.now
+	require.True(t, reloaded.Banned(PeerIDToNodeID(peer)))
+	require.False(t, reloaded.Banned(PeerIDToNodeID(other)))
+}
@@ -28,6 +28,7 @@ import (
 	"math"
 	"math/rand"
 	"net"
+	"path/filepath"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -53,11 +54,12 @@ import (
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon/cmd/utils"
-	"github.com/erigontech/erigon/core/forkid"
 	"github.com/erigontech/erigon/eth/protocols/eth"
 	"github.com/erigontech/erigon/p2p"
 	"github.com/erigontech/erigon/p2p/dnsdisc"
 	"github.com/erigontech/erigon/p2p/enode"
+	"github.com/erigontech/erigon/p2p/sentry/capture"
+	"github.com/erigontech/erigon/p2p/sentry/peerscore"
 	"github.com/erigontech/erigon/params"
 )
 
@@ -66,6 +68,8 @@ const (
 	// complete before dropping the connection.= as malicious.
 	handshakeTimeout  = 5 * time.Second
 	maxPermitsPerPeer = 4 // How many outstanding requests per peer we may have
+	// blockRangeUpdateInterval - how often the served block range is announced to eth/69 peers, in blocks
+	blockRangeUpdateInterval = 32
 )
 
 // PeerInfo collects various extra bits of information about the peer,
@@ -74,10 +78,13 @@ type PeerInfo struct {
 	peer          *p2p.Peer
 	lock          sync.RWMutex
 	deadlines     []time.Time // Request deadlines
+	sent          []time.Time // Times the requests were sent at, parallel to deadlines
 	latestDealine time.Time
 	height        uint64
+	earliest      uint64 // first block the peer serves bodies and receipts of, announced since eth/69
 	rw            p2p.MsgReadWriter
 	protocol      uint
+	scorer        *peerscore.Scorer // nil if the peer isn't scored
 
 	ctx       context.Context
 	ctxCancel context.CancelFunc
@@ -179,6 +186,7 @@ func (pi *PeerInfo) AddDeadline(deadline time.Time) {
 	pi.lock.Lock()
 	defer pi.lock.Unlock()
 	pi.deadlines = append(pi.deadlines, deadline)
+	pi.sent = append(pi.sent, time.Now())
 	pi.latestDealine = deadline
 }
 
@@ -196,23 +204,76 @@ func (pi *PeerInfo) SetIncreasedHeight(newHeight uint64) {
 	}
 }
 
+// EarliestBlock - first block the peer serves bodies and receipts of, 0 for peers before eth/69
+func (pi *PeerInfo) EarliestBlock() uint64 {
+	return atomic.LoadUint64(&pi.earliest)
+}
+
+// SetBlockRange updates the range of blocks the peer serves
+func (pi *PeerInfo) SetBlockRange(earliest, latest uint64) {
+	atomic.StoreUint64(&pi.earliest, earliest)
+	pi.SetIncreasedHeight(latest)
+}
+
 // ClearDeadlines goes through the deadlines of
 // given peers and removes the ones that have passed
 // Optionally, it also clears one extra deadline - this is used when response is received
 // It returns the number of deadlines left
 func (pi *PeerInfo) ClearDeadlines(now time.Time, givePermit bool) int {
+	return pi.clearDeadlines(now, givePermit, false)
+}
+
+// clearDeadlines scores the passed deadlines as timeouts and the cleared extra deadline as a response
+func (pi *PeerInfo) clearDeadlines(now time.Time, givePermit bool, emptyResponse bool) int {
 	pi.lock.Lock()
-	defer pi.lock.Unlock()
 	// Look for the first deadline which is not passed yet
 	firstNotPassed := sort.Search(len(pi.deadlines), func(i int) bool {
 		return pi.deadlines[i].After(now)
 	})
 	cutOff := firstNotPassed
-	if cutOff < len(pi.deadlines) && givePermit {
+	var latency time.Duration
+	responded := cutOff < len(pi.deadlines) && givePermit
+	if responded {
+		latency = now.Sub(pi.sent[cutOff])
 		cutOff++
 	}
 	pi.deadlines = pi.deadlines[cutOff:]
-	return len(pi.deadlines)
+	pi.sent = pi.sent[cutOff:]
+	left := len(pi.deadlines)
+	pi.lock.Unlock()
+
+	for i := 0; i < firstNotPassed; i++ {
+		pi.score(peerscore.Timeout)
+	}
+	if responded && pi.scorer != nil {
+		pi.applyScore(pi.scorer.RecordResponse(pi.ID(), pi.protocolName(), latency, emptyResponse))
+	}
+	return left
+}
+
+func (pi *PeerInfo) protocolName() string {
+	return fmt.Sprintf("%s/%d", eth.ProtocolName, pi.protocol)
+}
+
+// score records the event in the eth protocol of the peer
+func (pi *PeerInfo) score(event peerscore.Event) {
+	if pi.scorer != nil {
+		pi.applyScore(pi.scorer.Record(pi.ID(), pi.protocolName(), event))
+	}
+}
+
+// applyScore disconnects and bans the peer if its score got too low, static and trusted peers are kept
+func (pi *PeerInfo) applyScore(action peerscore.Action) {
+	if action == peerscore.None || pi.RemoveReason() != nil {
+		return
+	}
+	if info := pi.peer.Info(); info.Network.Static || info.Network.Trusted {
+		return
+	}
+	if action == peerscore.Ban {
+		pi.scorer.Ban(pi.ID(), "low score")
+	}
+	pi.Remove(p2p.NewPeerError(p2p.PeerErrorDiscReason, p2p.DiscUselessPeer, nil, fmt.Sprintf("peer score too low, %s", action)))
 }
 
 func (pi *PeerInfo) LatestDeadline() time.Time {
@@ -301,26 +362,17 @@ func handShake(
 	rw p2p.MsgReadWriter,
 	version uint,
 	minVersion uint,
-) (*libcommon.Hash, *p2p.PeerError) {
+) (*peerStatus, *p2p.PeerError) {
 	// Send out own handshake in a new thread
 	errChan := make(chan *p2p.PeerError, 2)
-	resultChan := make(chan *eth.StatusPacket, 1)
+	resultChan := make(chan *peerStatus, 1)
 
-	ourTD := gointerfaces.ConvertH256ToUint256Int(status.TotalDifficulty)
 	// Convert proto status data into the one required by devp2p
-	genesisHash := gointerfaces.ConvertH256ToHash(status.ForkData.Genesis)
+	ourStatus := makeStatusPacket(status, version)
 
 	go func() {
 		defer debug.LogPanic()
-		status := &eth.StatusPacket{
-			ProtocolVersion: uint32(version),
-			NetworkID:       status.NetworkId,
-			TD:              ourTD.ToBig(),
-			Head:            gointerfaces.ConvertH256ToHash(status.BestHash),
-			Genesis:         genesisHash,
-			ForkID:          forkid.NewIDFromForks(status.ForkData.HeightForks, status.ForkData.TimeForks, genesisHash, status.MaxBlockHeight, status.MaxBlockTime),
-		}
-		err := p2p.Send(rw, eth.StatusMsg, status)
+		err := p2p.Send(rw, eth.StatusMsg, ourStatus)
 
 		if err == nil {
 			errChan <- nil
@@ -356,8 +408,7 @@ func handShake(
 		}
 	}
 
-	peerStatus := <-resultChan
-	return &peerStatus.Head, nil
+	return <-resultChan, nil
 }
 
 func runPeer(
@@ -408,7 +459,7 @@ func runPeer(
 			return p2p.NewPeerError(p2p.PeerErrorMessageSizeLimit, p2p.DiscSubprotocolError, nil, fmt.Sprintf("sentry.runPeer: message is too large %d, limit %d", msg.Size, eth.ProtocolMaxMsgSize))
 		}
 
-		givePermit := false
+		givePermit, emptyResponse := false, false
 		switch msg.Code {
 		case eth.StatusMsg:
 			msg.Discard()
@@ -433,6 +484,7 @@ func runPeer(
 			if _, err := io.ReadFull(msg.Payload, b); err != nil {
 				logger.Error(fmt.Sprintf("%s: reading msg into bytes: %v", peerID, err))
 			}
+			emptyResponse = isEmptyResponse(b)
 			send(eth.ToProto[protocol][msg.Code], peerID, b)
 		case eth.GetBlockBodiesMsg:
 			if !hasSubscribers(eth.ToProto[protocol][msg.Code]) {
@@ -452,6 +504,7 @@ func runPeer(
 			if _, err := io.ReadFull(msg.Payload, b); err != nil {
 				logger.Error(fmt.Sprintf("%s: reading msg into bytes: %v", peerID, err))
 			}
+			emptyResponse = isEmptyResponse(b)
 			send(eth.ToProto[protocol][msg.Code], peerID, b)
 		case eth.GetReceiptsMsg:
 			if !hasSubscribers(eth.ToProto[protocol][msg.Code]) {
@@ -533,6 +586,15 @@ func runPeer(
 				logger.Error(fmt.Sprintf("%s: reading msg into bytes: %v", peerID, err))
 			}
 			send(eth.ToProto[protocol][msg.Code], peerID, b)
+		case eth.BlockRangeUpdateMsg:
+			var update eth.BlockRangeUpdatePacket
+			if err := msg.Decode(&update); err != nil {
+				return p2p.NewPeerError(p2p.PeerErrorInvalidMessage, p2p.DiscProtocolError, err, "sentry.runPeer: invalid block range update")
+			}
+			if update.EarliestBlock > update.LatestBlock {
+				return p2p.NewPeerError(p2p.PeerErrorInvalidMessage, p2p.DiscProtocolError, nil, fmt.Sprintf("sentry.runPeer: invalid block range %d-%d", update.EarliestBlock, update.LatestBlock))
+			}
+			peerInfo.SetBlockRange(update.EarliestBlock, update.LatestBlock)
 		case 11:
 			// Ignore
 			// TODO: Investigate why BSC peers for eth/67 send these messages
@@ -546,7 +608,34 @@ func runPeer(
 		trackPeerStatistics(peerInfo.peer.Fullname(), peerInfo.peer.ID().String(), true, msgType.String(), msgCap, int(msg.Size))
 
 		msg.Discard()
-		peerInfo.ClearDeadlines(time.Now(), givePermit)
+		peerInfo.clearDeadlines(time.Now(), givePermit, emptyResponse)
+	}
+}
+
+// isEmptyResponse reports if the eth/66+ response packet, [request id, [items...]], has no items
+func isEmptyResponse(packet []byte) bool {
+	content, _, err := rlp.SplitList(packet)
+	if err != nil {
+		return false
+	}
+	_, _, rest, err := rlp.Split(content)
+	if err != nil {
+		return false
+	}
+	items, _, err := rlp.SplitList(rest)
+	return err == nil && len(items) == 0
+}
+
+// isProtocolViolation reports if the peer was dropped for breaking the rules of the protocol
+func isProtocolViolation(err *p2p.PeerError) bool {
+	if err == nil {
+		return false
+	}
+	switch err.Code {
+	case p2p.PeerErrorInvalidMessage, p2p.PeerErrorMessageSizeLimit, p2p.PeerErrorStatusUnexpected:
+		return true
+	default:
+		return false
 	}
 }
 
@@ -604,6 +693,7 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 		ctx:          ctx,
 		p2p:          cfg,
 		peersStreams: NewPeersStreams(),
+		scorer:       peerscore.New(peerscore.DefaultConfig(""), logger),
 		logger:       logger,
 	}
 
@@ -617,7 +707,7 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 	ss.Protocols = append(ss.Protocols, p2p.Protocol{
 		Name:           eth.ProtocolName,
 		Version:        protocol,
-		Length:         17,
+		Length:         eth.ProtocolLengths[protocol],
 		DialCandidates: disc,
 		Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) *p2p.PeerError {
 			peerID := peer.Pubkey()
@@ -627,8 +717,12 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 			}
 			logger.Trace("[p2p] start with peer", "peerId", printablePeerID)
 
+			if ss.capture != nil {
+				rw = ss.capture.Wrap(rw, peerID, peer.Fullname(), fmt.Sprintf("%s/%d", eth.ProtocolName, protocol))
+			}
 			peerInfo := NewPeerInfo(peer, rw)
 			peerInfo.protocol = protocol
+			peerInfo.scorer = ss.scorer
 			defer peerInfo.Close()
 
 			defer ss.GoodPeers.Delete(peerID)
@@ -639,10 +733,13 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 				return p2p.NewPeerError(p2p.PeerErrorLocalStatusNeeded, p2p.DiscProtocolError, nil, "could not get status message from core")
 			}
 
-			peerBestHash, err := handShake(ctx, status, rw, protocol, protocol)
+			peerStatus, err := handShake(ctx, status, rw, protocol, protocol)
 			if err != nil {
 				return err
 			}
+			if peerStatus.BlockRange != nil {
+				peerInfo.SetBlockRange(peerStatus.BlockRange.EarliestBlock, peerStatus.BlockRange.LatestBlock)
+			}
 
 			// handshake is successful
 			logger.Trace("[p2p] Received status message OK", "peerId", printablePeerID, "name", peer.Name())
@@ -650,14 +747,14 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 			ss.GoodPeers.Store(peerID, peerInfo)
 			ss.sendNewPeerToClients(gointerfaces.ConvertHashToH512(peerID))
 			defer ss.sendGonePeerToClients(gointerfaces.ConvertHashToH512(peerID))
-			getBlockHeadersErr := ss.getBlockHeaders(ctx, *peerBestHash, peerID)
+			getBlockHeadersErr := ss.getBlockHeaders(ctx, peerStatus.Head, peerID)
 			if getBlockHeadersErr != nil {
 				return p2p.NewPeerError(p2p.PeerErrorFirstMessageSend, p2p.DiscNetworkError, getBlockHeadersErr, "p2p.Protocol.Run getBlockHeaders failure")
 			}
 
 			cap := p2p.Cap{Name: eth.ProtocolName, Version: protocol}
 
-			return runPeer(
+			err = runPeer(
 				ctx,
 				peerID,
 				cap,
@@ -667,6 +764,10 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 				ss.hasSubscribers,
 				logger,
 			)
+			if isProtocolViolation(err) {
+				peerInfo.score(peerscore.ProtocolViolation)
+			}
+			return err
 		},
 		NodeInfo: func() interface{} {
 			return readNodeInfo()
@@ -682,7 +783,7 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 }
 
 // Sentry creates and runs standalone sentry
-func Sentry(ctx context.Context, dirs datadir.Dirs, sentryAddr string, discoveryDNS []string, cfg *p2p.Config, protocolVersion uint, healthCheck bool, logger log.Logger) error {
+func Sentry(ctx context.Context, dirs datadir.Dirs, sentryAddr string, discoveryDNS []string, cfg *p2p.Config, protocolVersion uint, healthCheck bool, captureCfg capture.Config, logger log.Logger) error {
 	dir.MustExist(dirs.DataDir)
 
 	discovery := func() enode.Iterator {
@@ -694,6 +795,15 @@ func Sentry(ctx context.Context, dirs datadir.Dirs, sentryAddr string, discovery
 	}
 	cfg.DiscoveryDNS = discoveryDNS
 	sentryServer := NewGrpcServer(ctx, discovery, func() *eth.NodeInfo { return nil }, cfg, protocolVersion, logger)
+	sentryServer.SetPeerScorer(peerscore.New(peerscore.DefaultConfig(filepath.Join(dirs.Nodes, "banlist.json")), logger))
+	if captureCfg.Dir != "" {
+		w, err := capture.NewWriter(captureCfg, logger)
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+		sentryServer.SetCapture(w)
+	}
 
 	grpcServer, err := grpcSentryServer(ctx, sentryAddr, sentryServer, healthCheck)
 	if err != nil {
@@ -716,14 +826,33 @@ type GrpcServer struct {
 	p2pServerLock        sync.RWMutex
 	statusData           *proto_sentry.StatusData
 	statusDataLock       sync.RWMutex
+	blockRangeAnnounced  uint64 // head height of the last block range update, guarded by statusDataLock
 	messageStreams       map[proto_sentry.MessageId]map[uint64]chan *proto_sentry.InboundMessage
 	messagesSubscriberID uint64
 	messageStreamsLock   sync.RWMutex
 	peersStreams         *PeersStreams
+	scorer               *peerscore.Scorer
+	capture              *capture.Writer
 	p2p                  *p2p.Config
 	logger               log.Logger
 }
 
+// SetPeerScorer replaces the scorer of the peers, used to share one between the sentries of the node.
+// Must be called before the p2p server is started.
+func (ss *GrpcServer) SetPeerScorer(scorer *peerscore.Scorer) {
+	ss.scorer = scorer
+}
+
+func (ss *GrpcServer) PeerScorer() *peerscore.Scorer {
+	return ss.scorer
+}
+
+// SetCapture enables recording of the peer messages, the writer can be shared between the sentries of the node.
+// Must be called before the p2p server is started.
+func (ss *GrpcServer) SetCapture(w *capture.Writer) {
+	ss.capture = w
+}
+
 func (ss *GrpcServer) rangePeers(f func(peerInfo *PeerInfo) bool) {
 	ss.GoodPeers.Range(func(key, value interface{}) bool {
 		peerInfo, _ := value.(*PeerInfo)
@@ -800,6 +929,9 @@ func (ss *GrpcServer) PenalizePeer(_ context.Context, req *proto_sentry.Penalize
 	//log.Warn("Received penalty", "kind", req.GetPenalty().Descriptor().FullName, "from", fmt.Sprintf("%s", req.GetPeerId()))
 	peerID := ConvertH512ToPeerID(req.PeerId)
 	peerInfo := ss.getPeer(peerID)
+	if peerInfo != nil {
+		peerInfo.score(peerscore.InvalidData)
+	}
 	if ss.statusData != nil && peerInfo != nil && !peerInfo.peer.Info().Network.Static && !peerInfo.peer.Info().Network.Trusted {
 		ss.removePeer(peerID, p2p.NewPeerError(p2p.PeerErrorDiscReason, p2p.DiscRequested, nil, "penalized peer"))
 	}
@@ -814,13 +946,19 @@ func (ss *GrpcServer) PeerMinBlock(_ context.Context, req *proto_sentry.PeerMinB
 	return &emptypb.Empty{}, nil
 }
 
-func (ss *GrpcServer) findBestPeersWithPermit(peerCount int) []*PeerInfo {
+// findBestPeersWithPermit picks the peers with the most permits. If the request needs the
+// history of minBlock (bodies, receipts), peers which pruned it are skipped.
+func (ss *GrpcServer) findBestPeersWithPermit(peerCount int, minBlock uint64, needsHistory bool) []*PeerInfo {
 	// Choose peer(s) that we can send this request to, with maximum number of permits
 	now := time.Now()
 	byMinBlock := make(PeersByMinBlock, 0, peerCount)
 	var pokePeer *PeerInfo // Peer with the earliest dealine, to be "poked" by the request
 	var pokeDeadline time.Time
 	ss.rangePeers(func(peerInfo *PeerInfo) bool {
+		if needsHistory && peerInfo.EarliestBlock() > minBlock {
+			// history the request needs is not served by the peer
+			return true
+		}
 		deadlines := peerInfo.ClearDeadlines(now, false /* givePermit */)
 		height := peerInfo.Height()
 		//fmt.Printf("%d deadlines for peer %s\n", deadlines, peerID)
@@ -851,13 +989,13 @@ func (ss *GrpcServer) findBestPeersWithPermit(peerCount int) []*PeerInfo {
 	return foundPeers
 }
 
-func (ss *GrpcServer) findPeerByMinBlock(minBlock uint64) (*PeerInfo, bool) {
+func (ss *GrpcServer) findPeerByMinBlock(minBlock uint64, needsHistory bool) (*PeerInfo, bool) {
 	// Choose a peer that we can send this request to, with maximum number of permits
 	var foundPeerInfo *PeerInfo
 	var maxPermits int
 	now := time.Now()
 	ss.rangePeers(func(peerInfo *PeerInfo) bool {
-		if peerInfo.Height() >= minBlock {
+		if peerInfo.Height() >= minBlock && (!needsHistory || peerInfo.EarliestBlock() <= minBlock) {
 			deadlines := peerInfo.ClearDeadlines(now, false /* givePermit */)
 			//fmt.Printf("%d deadlines for peer %s\n", deadlines, peerID)
 			if deadlines < maxPermitsPerPeer {
@@ -878,18 +1016,20 @@ func (ss *GrpcServer) SendMessageByMinBlock(_ context.Context, inreq *proto_sent
 	msgcode := eth.FromProto[ss.Protocols[0].Version][inreq.Data.Id]
 	if msgcode != eth.GetBlockHeadersMsg &&
 		msgcode != eth.GetBlockBodiesMsg &&
+		msgcode != eth.GetReceiptsMsg &&
 		msgcode != eth.GetPooledTransactionsMsg {
 		return reply, fmt.Errorf("sendMessageByMinBlock not implemented for message Id: %s", inreq.Data.Id)
 	}
+	needsHistory := msgcode == eth.GetBlockBodiesMsg || msgcode == eth.GetReceiptsMsg
 	if inreq.MaxPeers == 1 {
-		peerInfo, found := ss.findPeerByMinBlock(inreq.MinBlock)
+		peerInfo, found := ss.findPeerByMinBlock(inreq.MinBlock, needsHistory)
 		if found {
 			ss.writePeer("[sentry] sendMessageByMinBlock", peerInfo, msgcode, inreq.Data.Data, 30*time.Second)
 			reply.Peers = []*proto_types.H512{gointerfaces.ConvertHashToH512(peerInfo.ID())}
 			return reply, nil
 		}
 	}
-	peerInfos := ss.findBestPeersWithPermit(int(inreq.MaxPeers))
+	peerInfos := ss.findBestPeersWithPermit(int(inreq.MaxPeers), inreq.MinBlock, needsHistory)
 	reply.Peers = make([]*proto_types.H512, len(peerInfos))
 	for i, peerInfo := range peerInfos {
 		ss.writePeer("[sentry] sendMessageByMinBlock", peerInfo, msgcode, inreq.Data.Data, 15*time.Second)
@@ -999,6 +1139,8 @@ func (ss *GrpcServer) HandShake(context.Context, *emptypb.Empty) (*proto_sentry.
 		reply.Protocol = proto_sentry.Protocol_ETH67
 	case direct.ETH68:
 		reply.Protocol = proto_sentry.Protocol_ETH68
+	case direct.ETH69:
+		reply.Protocol = proto_sentry.Protocol_ETH69
 	}
 	return reply, nil
 }
@@ -1024,6 +1166,9 @@ func (ss *GrpcServer) startP2PServer(genesisHash libcommon.Hash) (*p2p.Server, e
 	if err != nil {
 		return nil, err
 	}
+	if ss.scorer != nil {
+		srv.IsBanned = ss.scorer.Banned
+	}
 
 	if err = srv.Start(ss.ctx, ss.logger); err != nil {
 		srv.Stop()
@@ -1061,10 +1206,32 @@ func (ss *GrpcServer) SetStatus(ctx context.Context, statusData *proto_sentry.St
 	if ss.statusData == nil || statusData.MaxBlockHeight != 0 {
 		// Not overwrite statusData if the message contains zero MaxBlock (comes from standalone transaction pool)
 		ss.statusData = statusData
+		ss.announceBlockRange(statusData)
 	}
 	return reply, nil
 }
 
+// announceBlockRange sends the served block range to eth/69 peers, once per blockRangeUpdateInterval blocks
+func (ss *GrpcServer) announceBlockRange(statusData *proto_sentry.StatusData) {
+	if ss.Protocols[0].Version < direct.ETH69 || statusData.MaxBlockHeight < ss.blockRangeAnnounced+blockRangeUpdateInterval {
+		return
+	}
+	ss.blockRangeAnnounced = statusData.MaxBlockHeight
+	data, err := rlp.EncodeToBytes(&eth.BlockRangeUpdatePacket{
+		EarliestBlock:   statusData.MinBlockHeight,
+		LatestBlock:     statusData.MaxBlockHeight,
+		LatestBlockHash: gointerfaces.ConvertH256ToHash(statusData.BestHash),
+	})
+	if err != nil {
+		ss.logger.Error("[sentry] encode block range update", "err", err)
+		return
+	}
+	ss.rangePeers(func(peerInfo *PeerInfo) bool {
+		ss.writePeer("[sentry] announceBlockRange", peerInfo, eth.BlockRangeUpdateMsg, data, 0)
+		return true
+	})
+}
+
 func (ss *GrpcServer) Peers(_ context.Context, _ *emptypb.Empty) (*proto_sentry.PeersReply, error) {
 	p2pServer := ss.getP2PServer()
 	if p2pServer == nil {
@@ -34,10 +34,12 @@ import (
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/forkid"
 	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/protocols/eth"
 	"github.com/erigontech/erigon/p2p"
 )
 
@@ -87,6 +89,7 @@ func startHandshake(
 // Tests that peers are correctly accepted (or rejected) based on the advertised
 // fork IDs in the protocol handshake.
 func TestForkIDSplit67(t *testing.T) { testForkIDSplit(t, direct.ETH67) }
+func TestForkIDSplit69(t *testing.T) { testForkIDSplit(t, direct.ETH69) }
 
 func testForkIDSplit(t *testing.T, protocol uint) {
 	var (
@@ -212,3 +215,37 @@ func TestSentryServerImpl_SetStatusInitPanic(t *testing.T) {
 		t.Fatalf("error expected")
 	}
 }
+
+func TestIsEmptyResponse(t *testing.T) {
+	empty, err := rlp.EncodeToBytes(&eth.BlockHeadersPacket66{RequestId: 7})
+	require.NoError(t, err)
+	require.True(t, isEmptyResponse(empty))
+
+	full, err := rlp.EncodeToBytes(&eth.BlockHeadersPacket66{RequestId: 7, BlockHeadersPacket: eth.BlockHeadersPacket{{Number: big.NewInt(1)}}})
+	require.NoError(t, err)
+	require.False(t, isEmptyResponse(full))
+	require.False(t, isEmptyResponse([]byte{0x01}))
+}
+
+func TestFindPeersByBlockRange(t *testing.T) {
+	ss := &GrpcServer{}
+	pruned, archive := NewPeerInfo(nil, nil), NewPeerInfo(nil, nil)
+	defer pruned.Close()
+	defer archive.Close()
+	pruned.SetBlockRange(1000, 2000)
+	archive.SetBlockRange(0, 1500)
+	ss.GoodPeers.Store([64]byte{1}, pruned)
+	ss.GoodPeers.Store([64]byte{2}, archive)
+
+	// headers are served by peers which pruned the history
+	require.Len(t, ss.findBestPeersWithPermit(2, 500, false), 2)
+	peer, found := ss.findPeerByMinBlock(1800, false)
+	require.True(t, found)
+	require.Equal(t, pruned, peer)
+
+	// bodies and receipts aren't
+	require.Equal(t, []*PeerInfo{archive}, ss.findBestPeersWithPermit(2, 500, true))
+	peer, found = ss.findPeerByMinBlock(500, true)
+	require.True(t, found)
+	require.Equal(t, archive, peer)
+}
@@ -0,0 +1,244 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	isentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
+	"github.com/erigontech/erigon/eth/protocols/eth"
+	"github.com/erigontech/erigon/p2p"
+	"github.com/erigontech/erigon/p2p/enode"
+	"github.com/erigontech/erigon/p2p/sentry"
+	"github.com/erigontech/erigon/p2p/sentry/capture"
+)
+
+var ErrTruncatedPayload = errors.New("payload is not captured")
+
+// InboundHandler processes a message received from a peer, e.g. sentry_multi_client.MultiClient.HandleInboundMessage.
+type InboundHandler func(ctx context.Context, inreq *isentry.InboundMessage, sentry isentry.SentryClient) error
+
+// ReplayResult is the outcome of a replayed inbound message.
+type ReplayResult struct {
+	Record  *capture.Record
+	Message *isentry.InboundMessage // nil if the message can't be replayed
+	Err     error
+}
+
+// SentMessage is a message sent by the handlers during the replay.
+type SentMessage struct {
+	PeerID [64]byte // zero if the message is sent to all or random peers
+	Data   *isentry.OutboundMessageData
+}
+
+// ReplaySentry is a sentry serving the peers of a capture (see package capture). It feeds the captured
+// inbound messages to the handlers and records what the handlers send back, instead of talking to the network.
+type ReplaySentry struct {
+	isentry.UnimplementedSentryServer
+	records []*capture.Record
+	peers   map[[64]byte]*p2p.Peer
+	clients map[uint]isentry.SentryClient
+
+	lock      sync.Mutex
+	sent      []SentMessage
+	penalties []*isentry.PenalizePeerRequest
+}
+
+// NewReplaySentry creates the sentry for the records, see capture.Read.
+func NewReplaySentry(records []*capture.Record) *ReplaySentry {
+	s := &ReplaySentry{
+		records: records,
+		peers:   map[[64]byte]*p2p.Peer{},
+		clients: map[uint]isentry.SentryClient{},
+	}
+	for _, rec := range records {
+		if _, ok := s.peers[rec.PeerID]; ok {
+			continue
+		}
+		var caps []p2p.Cap
+		if version, err := protocolVersion(rec.Protocol); err == nil {
+			caps = append(caps, p2p.Cap{Name: eth.ProtocolName, Version: version})
+		}
+		id := enode.ID(crypto.Keccak256Hash(rec.PeerID[:]))
+		s.peers[rec.PeerID] = p2p.NewPeer(id, rec.PeerID, rec.PeerName, caps, false)
+	}
+	return s
+}
+
+func protocolVersion(protocol string) (uint, error) {
+	name, version, ok := strings.Cut(protocol, "/")
+	if !ok || name != eth.ProtocolName {
+		return 0, fmt.Errorf("unsupported protocol %q", protocol)
+	}
+	v, err := strconv.ParseUint(version, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unsupported protocol %q: %w", protocol, err)
+	}
+	return uint(v), nil
+}
+
+// Replay passes the inbound messages of the capture, except the handshake, to handle in the captured order.
+// The original time between the messages is divided by speed, the messages are replayed without delays if speed is 0.
+func (s *ReplaySentry) Replay(ctx context.Context, handle InboundHandler, speed float64) ([]ReplayResult, error) {
+	var results []ReplayResult
+	var prevTime uint64
+	for _, rec := range s.records {
+		if !rec.Inbound || rec.Code == eth.StatusMsg {
+			continue
+		}
+		if speed > 0 && prevTime != 0 && rec.Time > prevTime {
+			select {
+			case <-time.After(time.Duration(float64(rec.Time-prevTime) / speed)):
+			case <-ctx.Done():
+				return results, ctx.Err()
+			}
+		}
+		prevTime = rec.Time
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		results = append(results, s.replay(ctx, rec, handle))
+	}
+	return results, nil
+}
+
+func (s *ReplaySentry) replay(ctx context.Context, rec *capture.Record, handle InboundHandler) ReplayResult {
+	result := ReplayResult{Record: rec}
+	if rec.Truncated {
+		result.Err = ErrTruncatedPayload
+		return result
+	}
+	version, err := protocolVersion(rec.Protocol)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	id, ok := eth.ToProto[version][rec.Code]
+	if !ok {
+		result.Err = fmt.Errorf("unknown message code 0x%x of %s", rec.Code, rec.Protocol)
+		return result
+	}
+	client, ok := s.clients[version]
+	if !ok {
+		client = direct.NewSentryClientDirect(version, s)
+		s.clients[version] = client
+	}
+	result.Message = &isentry.InboundMessage{
+		Id:     id,
+		Data:   rec.Payload,
+		PeerId: gointerfaces.ConvertHashToH512(rec.PeerID),
+	}
+	result.Err = handle(ctx, result.Message, client)
+	return result
+}
+
+// Sent returns the messages sent by the handlers.
+func (s *ReplaySentry) Sent() []SentMessage {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]SentMessage(nil), s.sent...)
+}
+
+// Penalties returns the peer penalties given by the handlers.
+func (s *ReplaySentry) Penalties() []*isentry.PenalizePeerRequest {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]*isentry.PenalizePeerRequest(nil), s.penalties...)
+}
+
+func (s *ReplaySentry) send(peerID [64]byte, data *isentry.OutboundMessageData) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sent = append(s.sent, SentMessage{PeerID: peerID, Data: data})
+}
+
+func (s *ReplaySentry) peerInfo(peer *p2p.Peer) *types.PeerInfo {
+	info := peer.Info()
+	return &types.PeerInfo{
+		Id:    info.ID,
+		Name:  info.Name,
+		Enode: info.Enode,
+		Caps:  info.Caps,
+	}
+}
+
+func (s *ReplaySentry) PenalizePeer(ctx context.Context, req *isentry.PenalizePeerRequest) (*emptypb.Empty, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.penalties = append(s.penalties, req)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ReplaySentry) PeerMinBlock(context.Context, *isentry.PeerMinBlockRequest) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ReplaySentry) PeerById(ctx context.Context, in *isentry.PeerByIdRequest) (*isentry.PeerByIdReply, error) {
+	peer, ok := s.peers[sentry.ConvertH512ToPeerID(in.PeerId)]
+	if !ok {
+		return &isentry.PeerByIdReply{}, nil
+	}
+	return &isentry.PeerByIdReply{Peer: s.peerInfo(peer)}, nil
+}
+
+func (s *ReplaySentry) PeerCount(context.Context, *isentry.PeerCountRequest) (*isentry.PeerCountReply, error) {
+	return &isentry.PeerCountReply{Count: uint64(len(s.peers))}, nil
+}
+
+func (s *ReplaySentry) Peers(context.Context, *emptypb.Empty) (*isentry.PeersReply, error) {
+	reply := &isentry.PeersReply{}
+	for _, peer := range s.peers {
+		reply.Peers = append(reply.Peers, s.peerInfo(peer))
+	}
+	return reply, nil
+}
+
+func (s *ReplaySentry) SendMessageById(ctx context.Context, in *isentry.SendMessageByIdRequest) (*isentry.SentPeers, error) {
+	peerID := sentry.ConvertH512ToPeerID(in.PeerId)
+	if _, ok := s.peers[peerID]; !ok {
+		return &isentry.SentPeers{}, nil
+	}
+	s.send(peerID, in.Data)
+	return &isentry.SentPeers{Peers: []*types.H512{in.PeerId}}, nil
+}
+
+func (s *ReplaySentry) SendMessageByMinBlock(ctx context.Context, in *isentry.SendMessageByMinBlockRequest) (*isentry.SentPeers, error) {
+	s.send([64]byte{}, in.Data)
+	return &isentry.SentPeers{}, nil
+}
+
+func (s *ReplaySentry) SendMessageToRandomPeers(ctx context.Context, in *isentry.SendMessageToRandomPeersRequest) (*isentry.SentPeers, error) {
+	s.send([64]byte{}, in.Data)
+	return &isentry.SentPeers{}, nil
+}
+
+func (s *ReplaySentry) SendMessageToAll(ctx context.Context, in *isentry.OutboundMessageData) (*isentry.SentPeers, error) {
+	s.send([64]byte{}, in)
+	return &isentry.SentPeers{}, nil
+}
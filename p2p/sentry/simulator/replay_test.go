@@ -0,0 +1,157 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package simulator_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	isentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/eth/protocols/eth"
+	"github.com/erigontech/erigon/p2p/sentry"
+	"github.com/erigontech/erigon/p2p/sentry/capture"
+	"github.com/erigontech/erigon/p2p/sentry/sentry_multi_client"
+	"github.com/erigontech/erigon/p2p/sentry/simulator"
+	"github.com/erigontech/erigon/turbo/stages/mock"
+)
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	w, err := capture.NewWriter(capture.DefaultConfig(dir), log.New())
+	require.NoError(t, err)
+
+	good, bad := [64]byte{1}, [64]byte{2}
+	request, err := rlp.EncodeToBytes(&eth.GetBlockHeadersPacket66{
+		RequestId:             7,
+		GetBlockHeadersPacket: &eth.GetBlockHeadersPacket{Origin: eth.HashOrNumber{Number: 10}, Amount: 1},
+	})
+	require.NoError(t, err)
+	for _, rec := range []*capture.Record{
+		{Time: 1, PeerID: good, PeerName: "good", Inbound: true, Protocol: "eth/68", Code: eth.StatusMsg, Payload: []byte{0xc0}},
+		{Time: 2, PeerID: good, PeerName: "good", Inbound: false, Protocol: "eth/68", Code: eth.GetBlockHeadersMsg, Payload: request},
+		{Time: 3, PeerID: good, PeerName: "good", Inbound: true, Protocol: "eth/68", Code: eth.GetBlockHeadersMsg, Payload: request},
+		{Time: 4, PeerID: bad, PeerName: "bad", Inbound: true, Protocol: "eth/68", Code: eth.GetBlockHeadersMsg, Payload: []byte{0x01, 0x02}},
+		{Time: 5, PeerID: bad, PeerName: "bad", Inbound: true, Protocol: "eth/68", Code: eth.BlockBodiesMsg, Size: 1 << 30, Truncated: true},
+	} {
+		w.Write(rec)
+	}
+	require.NoError(t, w.Close())
+
+	records, err := capture.Read(dir)
+	require.NoError(t, err)
+	s := simulator.NewReplaySentry(records)
+
+	peers, err := s.PeerCount(context.Background(), &isentry.PeerCountRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), peers.Count)
+
+	handle := func(ctx context.Context, inreq *isentry.InboundMessage, sentryClient isentry.SentryClient) error {
+		require.Equal(t, isentry.MessageId_GET_BLOCK_HEADERS_66, inreq.Id)
+		var packet eth.GetBlockHeadersPacket66
+		if err := rlp.DecodeBytes(inreq.Data, &packet); err != nil {
+			_, _ = sentryClient.PenalizePeer(ctx, &isentry.PenalizePeerRequest{PeerId: inreq.PeerId, Penalty: isentry.PenaltyKind_Kick})
+			return err
+		}
+		reply, err := rlp.EncodeToBytes(&eth.BlockHeadersPacket66{RequestId: packet.RequestId})
+		if err != nil {
+			return err
+		}
+		_, err = sentryClient.SendMessageById(ctx, &isentry.SendMessageByIdRequest{
+			PeerId: inreq.PeerId,
+			Data:   &isentry.OutboundMessageData{Id: isentry.MessageId_BLOCK_HEADERS_66, Data: reply},
+		})
+		return err
+	}
+
+	results, err := s.Replay(context.Background(), handle, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.NoError(t, results[0].Err)
+	require.Error(t, results[1].Err)
+	require.ErrorIs(t, results[2].Err, simulator.ErrTruncatedPayload)
+	require.Nil(t, results[2].Message)
+
+	sent := s.Sent()
+	require.Len(t, sent, 1)
+	require.Equal(t, good, sent[0].PeerID)
+	require.Equal(t, isentry.MessageId_BLOCK_HEADERS_66, sent[0].Data.Id)
+
+	penalties := s.Penalties()
+	require.Len(t, penalties, 1)
+	require.Equal(t, bad, sentry.ConvertH512ToPeerID(penalties[0].PeerId))
+}
+
+// TestReplayMultiClient replays a capture into the handlers the node runs, backed by a mock chain.
+func TestReplayMultiClient(t *testing.T) {
+	m := mock.Mock(t)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3, func(int, *core.BlockGen) {})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	logger := log.New()
+	statusDataProvider := sentry.NewStatusDataProvider(m.DB, m.ChainConfig, m.Genesis, m.ChainConfig.ChainID.Uint64(), logger)
+	multiClient, err := sentry_multi_client.NewMultiClient(m.DB, m.ChainConfig, m.Engine, nil, ethconfig.Defaults.Sync,
+		m.BlockReader, 128, statusDataProvider, false, func(*types.Header) uint { return 0 }, false, logger)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	w, err := capture.NewWriter(capture.DefaultConfig(dir), logger)
+	require.NoError(t, err)
+	good, bad := [64]byte{1}, [64]byte{2}
+	request, err := rlp.EncodeToBytes(&eth.GetBlockHeadersPacket66{
+		RequestId:             7,
+		GetBlockHeadersPacket: &eth.GetBlockHeadersPacket{Origin: eth.HashOrNumber{Number: 2}, Amount: 2},
+	})
+	require.NoError(t, err)
+	w.Write(&capture.Record{Time: 1, PeerID: good, PeerName: "good", Inbound: true, Protocol: "eth/68", Code: eth.GetBlockHeadersMsg, Payload: request})
+	w.Write(&capture.Record{Time: 2, PeerID: bad, PeerName: "bad", Inbound: true, Protocol: "eth/68", Code: eth.GetBlockHeadersMsg, Payload: []byte{0xc2, 0x01}})
+	require.NoError(t, w.Close())
+
+	records, err := capture.Read(dir)
+	require.NoError(t, err)
+	s := simulator.NewReplaySentry(records)
+	results, err := s.Replay(m.Ctx, multiClient.HandleInboundMessage, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.NoError(t, results[0].Err)
+	require.Error(t, results[1].Err)
+
+	// the good peer is answered with the headers of the chain
+	sent := s.Sent()
+	require.Len(t, sent, 1)
+	require.Equal(t, good, sent[0].PeerID)
+	require.Equal(t, isentry.MessageId_BLOCK_HEADERS_66, sent[0].Data.Id)
+	var reply eth.BlockHeadersPacket66
+	require.NoError(t, rlp.DecodeBytes(sent[0].Data.Data, &reply))
+	require.Equal(t, uint64(7), reply.RequestId)
+	require.Len(t, reply.BlockHeadersPacket, 2)
+	require.Equal(t, chain.Headers[1].Hash(), reply.BlockHeadersPacket[0].Hash())
+	require.Equal(t, chain.Headers[2].Hash(), reply.BlockHeadersPacket[1].Hash())
+
+	// the peer sending invalid rlp is kicked
+	penalties := s.Penalties()
+	require.Len(t, penalties, 1)
+	require.Equal(t, bad, sentry.ConvertH512ToPeerID(penalties[0].PeerId))
+	require.Equal(t, isentry.PenaltyKind_Kick, penalties[0].Penalty)
+}
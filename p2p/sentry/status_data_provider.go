@@ -31,6 +31,7 @@ import (
 	"github.com/erigontech/erigon-lib/gointerfaces"
 	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
 	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/prune"
 	"github.com/erigontech/erigon/core/forkid"
 	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/types"
@@ -43,6 +44,8 @@ type ChainHead struct {
 	HeadTime   uint64
 	HeadHash   libcommon.Hash
 	HeadTd     *uint256.Int
+	// HistoryStart - lowest block which bodies and receipts are served, older ones are pruned or expired
+	HistoryStart uint64
 }
 
 type StatusDataProvider struct {
@@ -96,10 +99,11 @@ func makeGenesisChainHead(genesis *types.Block) ChainHead {
 	}
 
 	return ChainHead{
-		HeadHeight: genesis.NumberU64(),
-		HeadTime:   genesis.Time(),
-		HeadHash:   genesis.Hash(),
-		HeadTd:     genesisDifficulty,
+		HeadHeight:   genesis.NumberU64(),
+		HeadTime:     genesis.Time(),
+		HeadHash:     genesis.Hash(),
+		HeadTd:       genesisDifficulty,
+		HistoryStart: genesis.NumberU64(),
 	}
 }
 
@@ -110,6 +114,7 @@ func (s *StatusDataProvider) makeStatusData(head ChainHead) *proto_sentry.Status
 		BestHash:        gointerfaces.ConvertHashToH256(head.HeadHash),
 		MaxBlockHeight:  head.HeadHeight,
 		MaxBlockTime:    head.HeadTime,
+		MinBlockHeight:  head.HistoryStart,
 		ForkData: &proto_sentry.Forks{
 			Genesis:     gointerfaces.ConvertHashToH256(s.genesisHash),
 			HeightForks: s.heightForks,
@@ -149,7 +154,27 @@ func ReadChainHeadWithTx(tx kv.Tx) (ChainHead, error) {
 		return ChainHead{}, fmt.Errorf("ReadChainHead: total difficulty conversion error: %w", err)
 	}
 
-	return ChainHead{height, time, hash, td256}, nil
+	historyStart, err := readHistoryStart(tx, height)
+	if err != nil {
+		return ChainHead{}, err
+	}
+
+	return ChainHead{height, time, hash, td256, historyStart}, nil
+}
+
+// readHistoryStart - first block which is not removed by blocks pruning or history expiry
+func readHistoryStart(tx kv.Tx, head uint64) (uint64, error) {
+	mode, err := prune.Get(tx)
+	if err != nil {
+		return 0, fmt.Errorf("ReadChainHead: prune mode read error: %w", err)
+	}
+	var start uint64
+	for _, amount := range []prune.BlockAmount{mode.Blocks, mode.Expiry} {
+		if amount != nil && amount.Enabled() {
+			start = max(start, amount.PruneTo(head))
+		}
+	}
+	return start, nil
 }
 
 func ReadChainHead(ctx context.Context, db kv.RoDB) (ChainHead, error) {
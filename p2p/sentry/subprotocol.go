@@ -0,0 +1,127 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/erigontech/erigon/p2p"
+	"github.com/erigontech/erigon/p2p/sentry/peerscore"
+)
+
+// SubprotocolHandler handles a message of a subprotocol received from a peer.
+// Returning an error disconnects the peer.
+type SubprotocolHandler func(peerID [64]byte, code uint64, data []byte) error
+
+// Subprotocol is a devp2p protocol run next to eth by the sentry, with the messages
+// handled in-process instead of being forwarded to the sentry clients. It is used
+// by consensus engines which gossip their own messages, like qbft.
+type Subprotocol struct {
+	name       string
+	length     uint64
+	maxMsgSize uint32
+	handler    SubprotocolHandler
+	peers      sync.Map // [64]byte -> p2p.MsgReadWriter
+}
+
+// AddSubprotocol registers a subprotocol on the server. It must be called before the
+// p2p server is started, the eth protocol stays the first one of the server.
+func (ss *GrpcServer) AddSubprotocol(name string, version uint, length uint64, maxMsgSize uint32, handler SubprotocolHandler) *Subprotocol {
+	sp := &Subprotocol{name: name, length: length, maxMsgSize: maxMsgSize, handler: handler}
+	ss.Protocols = append(ss.Protocols, p2p.Protocol{
+		Name:    name,
+		Version: version,
+		Length:  length,
+		Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) *p2p.PeerError {
+			peerID := peer.Pubkey()
+			sp.peers.Store(peerID, rw)
+			defer sp.peers.Delete(peerID)
+			ss.logger.Trace("[p2p] start subprotocol with peer", "protocol", name, "peerId", hex.EncodeToString(peerID[:])[:20])
+			err := sp.run(peerID, rw)
+			if isProtocolViolation(err) {
+				ss.scoreSubprotocolPeer(peer, fmt.Sprintf("%s/%d", name, version), peerscore.ProtocolViolation)
+			}
+			return err
+		},
+		NodeInfo: func() interface{} { return nil },
+		PeerInfo: func(peerID [64]byte) interface{} { return nil },
+	})
+	return sp
+}
+
+// scoreSubprotocolPeer records the event of a subprotocol, the peer is dropped by the subprotocol
+// error already, so only the ban is left to apply
+func (ss *GrpcServer) scoreSubprotocolPeer(peer *p2p.Peer, protocol string, event peerscore.Event) {
+	if ss.scorer == nil {
+		return
+	}
+	peerID := peer.Pubkey()
+	info := peer.Info()
+	if ss.scorer.Record(peerID, protocol, event) == peerscore.Ban && !info.Network.Static && !info.Network.Trusted {
+		ss.scorer.Ban(peerID, protocol+" "+event.String())
+	}
+}
+
+func (sp *Subprotocol) run(peerID [64]byte, rw p2p.MsgReadWriter) *p2p.PeerError {
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return p2p.NewPeerError(p2p.PeerErrorMessageReceive, p2p.DiscNetworkError, err, sp.name+" subprotocol read")
+		}
+		if msg.Code >= sp.length || msg.Size > sp.maxMsgSize {
+			msg.Discard()
+			return p2p.NewPeerError(p2p.PeerErrorInvalidMessage, p2p.DiscSubprotocolError,
+				fmt.Errorf("code %d, size %d", msg.Code, msg.Size), sp.name+" subprotocol message")
+		}
+		data, err := io.ReadAll(msg.Payload)
+		msg.Discard()
+		if err != nil {
+			return p2p.NewPeerError(p2p.PeerErrorMessageReceive, p2p.DiscNetworkError, err, sp.name+" subprotocol read")
+		}
+		if err := sp.handler(peerID, msg.Code, data); err != nil {
+			return p2p.NewPeerError(p2p.PeerErrorInvalidMessage, p2p.DiscSubprotocolError, err, sp.name+" subprotocol message")
+		}
+	}
+}
+
+// Broadcast sends the message to all peers running the subprotocol and returns the
+// number of peers it was sent to.
+func (sp *Subprotocol) Broadcast(code uint64, data []byte) int {
+	sent := 0
+	sp.peers.Range(func(_, value any) bool {
+		rw := value.(p2p.MsgReadWriter)
+		if err := rw.WriteMsg(p2p.Msg{Code: code, Size: uint32(len(data)), Payload: bytes.NewReader(data)}); err == nil {
+			sent++
+		}
+		return true
+	})
+	return sent
+}
+
+// Send sends the message to the given peer if it runs the subprotocol.
+func (sp *Subprotocol) Send(peerID [64]byte, code uint64, data []byte) error {
+	value, ok := sp.peers.Load(peerID)
+	if !ok {
+		return fmt.Errorf("%s subprotocol is not running with peer %x", sp.name, peerID[:8])
+	}
+	rw := value.(p2p.MsgReadWriter)
+	return rw.WriteMsg(p2p.Msg{Code: code, Size: uint32(len(data)), Payload: bytes.NewReader(data)})
+}
@@ -24,6 +24,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/ecdsa"
+	"crypto/tls"
 	"encoding/hex"
 	"errors"
 	"net"
@@ -34,6 +35,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/quic-go/quic-go"
 	"golang.org/x/sync/semaphore"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
@@ -73,7 +75,10 @@ const (
 	serverStatsLogInterval = 60 * time.Second
 )
 
-var errServerStopped = errors.New("server stopped")
+var (
+	errServerStopped = errors.New("server stopped")
+	errNoDiscoveryV5 = errors.New("V5 discovery is not running")
+)
 
 // Config holds Server options.
 type Config struct {
@@ -102,6 +107,10 @@ type Config struct {
 	// protocol should be started or not.
 	DiscoveryV5 bool `toml:",omitempty"`
 
+	// DiscoveryV5Topics are the V5 discovery topics the node is advertised under.
+	// The nodes found under these topics are dialed as well.
+	DiscoveryV5Topics []string `toml:",omitempty"`
+
 	// Name sets the node name of this server.
 	// Use common.MakeName to create a name that follows existing conventions.
 	Name string `toml:"-"`
@@ -128,6 +137,10 @@ type Config struct {
 	// IP networks contained in the list are considered.
 	NetRestrict *netutil.Netlist `toml:",omitempty"`
 
+	// IsBanned, if set, reports the nodes which are neither dialed nor accepted,
+	// unless they are trusted.
+	IsBanned func(id enode.ID) bool `toml:"-"`
+
 	// NodeDatabase is the path to the database containing the previously seen
 	// live nodes in the network.
 	NodeDatabase string `toml:",omitempty"`
@@ -162,6 +175,11 @@ type Config struct {
 	// NAT interface description (see NAT.Parse()).
 	NATSpec string
 
+	// If QUICListenAddr is set, the server also accepts connections over the experimental
+	// QUIC transport on this UDP address and advertises its port in the node record.
+	// Nodes advertising QUIC are dialed over QUIC, others and failed dials use TCP.
+	QUICListenAddr string `toml:",omitempty"`
+
 	// If Dialer is set to a non-nil value, the given Dialer
 	// is used to dial outbound peer connections.
 	Dialer NodeDialer `toml:"-"`
@@ -210,6 +228,9 @@ type Server struct {
 	running atomic.Bool
 
 	listener     net.Listener
+	quicTr       *quic.Transport
+	quicListener *quic.Listener
+	quicTLS      *tls.Config
 	ourHandshake *protoHandshake
 	loopWG       sync.WaitGroup // loop, listenLoop
 	peerFeed     event.Feed
@@ -236,7 +257,11 @@ type Server struct {
 	checkpointAddPeer       chan *conn
 
 	// State of run loop and listenLoop.
+	inboundMu      sync.Mutex // protects inboundHistory, shared by the TCP and QUIC listeners
 	inboundHistory expHeap
+	lastInbound    atomic.Int64 // unix nanoseconds when the last peer connected from a public address
+
+	portMappingRegister chan *portMapping
 
 	errorsMu sync.Mutex
 	errors   map[string]uint
@@ -447,11 +472,19 @@ func (srv *Server) Stop() {
 		// this unblocks listener Accept
 		_ = srv.listener.Close()
 	}
+	if srv.quicListener != nil {
+		_ = srv.quicListener.Close()
+	}
 	if srv.nodedb != nil {
 		srv.nodedb.Close()
 	}
 	srv.lock.Unlock()
 	srv.loopWG.Wait()
+	// The QUIC connections of the peers share the socket, close it once they are gone.
+	if srv.quicTr != nil {
+		_ = srv.quicTr.Close()
+		_ = srv.quicTr.Conn.Close()
+	}
 }
 
 // sharedUDPConn implements a shared connection. Write sends messages to the underlying connection while read returns
@@ -523,11 +556,17 @@ func (srv *Server) Start(ctx context.Context, logger log.Logger) error {
 	if err := srv.setupLocalNode(); err != nil {
 		return err
 	}
+	srv.setupPortMapping()
 	if srv.ListenAddr != "" {
 		if err := srv.setupListening(srv.quitCtx); err != nil {
 			return err
 		}
 	}
+	if srv.QUICListenAddr != "" {
+		if err := srv.setupQUIC(srv.quitCtx); err != nil {
+			return err
+		}
+	}
 	if err := srv.setupDiscovery(srv.quitCtx); err != nil {
 		return err
 	}
@@ -535,6 +574,8 @@ func (srv *Server) Start(ctx context.Context, logger log.Logger) error {
 
 	srv.running.Store(true)
 	srv.loopWG.Add(1)
+	go srv.natLoop()
+	srv.loopWG.Add(1)
 	go srv.run()
 	return nil
 }
@@ -570,31 +611,6 @@ func (srv *Server) setupLocalNode() error {
 	}
 
 	srv.updateLocalNodeStaticAddrCache()
-
-	switch srv.NAT.(type) {
-	case nil:
-		// No NAT interface, do nothing.
-	case nat.ExtIP:
-		// ExtIP doesn't block, set the IP right away.
-		ip, _ := srv.NAT.ExternalIP()
-		srv.localnode.SetStaticIP(ip)
-		srv.updateLocalNodeStaticAddrCache()
-	default:
-		// Ask the router about the IP. This takes a while and blocks startup,
-		// do it in the background.
-		srv.loopWG.Add(1)
-		go func() {
-			defer debug.LogPanic()
-			defer srv.loopWG.Done()
-			if ip, err := srv.NAT.ExternalIP(); err == nil {
-				srv.logger.Info("NAT ExternalIP resolved", "ip", ip)
-				srv.localnode.SetStaticIP(ip)
-				srv.updateLocalNodeStaticAddrCache()
-			} else {
-				srv.logger.Warn("NAT ExternalIP resolution has failed, try to pass a different --nat option", "err", err)
-			}
-		}()
-	}
 	return nil
 }
 
@@ -627,12 +643,7 @@ func (srv *Server) setupDiscovery(ctx context.Context) error {
 	srv.logger.Trace("UDP listener up", "addr", realaddr)
 	if srv.NAT != nil {
 		if !realaddr.IP.IsLoopback() && srv.NAT.SupportsMapping() {
-			srv.loopWG.Add(1)
-			go func() {
-				defer debug.LogPanic()
-				defer srv.loopWG.Done()
-				nat.Map(srv.NAT, srv.quit, "udp", realaddr.Port, realaddr.Port, "ethereum discovery", srv.logger)
-			}()
+			srv.portMappingRegister <- &portMapping{protocol: "UDP", name: "ethereum discovery", port: realaddr.Port}
 		}
 	}
 	srv.localnode.SetFallbackUDP(realaddr.Port)
@@ -679,10 +690,32 @@ func (srv *Server) setupDiscovery(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		for _, topic := range srv.DiscoveryV5Topics {
+			srv.DiscV5.RegisterTopic(ctx, discover.NewTopic(topic))
+			srv.discmix.AddSource(srv.DiscV5.TopicNodes(discover.NewTopic(topic)))
+		}
+	}
+	return nil
+}
+
+// RegisterTopic advertises the node under the V5 discovery topic until ctx is done or the server stops.
+func (srv *Server) RegisterTopic(ctx context.Context, topic string) error {
+	if srv.DiscV5 == nil {
+		return errNoDiscoveryV5
 	}
+	srv.DiscV5.RegisterTopic(ctx, discover.NewTopic(topic))
 	return nil
 }
 
+// TopicNodes returns an iterator of the nodes advertised under the V5 discovery topic.
+// The iterator must be closed by the caller.
+func (srv *Server) TopicNodes(topic string) (enode.Iterator, error) {
+	if srv.DiscV5 == nil {
+		return nil, errNoDiscoveryV5
+	}
+	return srv.DiscV5.TopicNodes(discover.NewTopic(topic)), nil
+}
+
 func (srv *Server) setupDialScheduler() {
 	config := dialConfig{
 		self:           srv.localnode.ID(),
@@ -690,6 +723,7 @@ func (srv *Server) setupDialScheduler() {
 		maxActiveDials: srv.MaxPendingPeers,
 		log:            srv.logger,
 		netRestrict:    srv.NetRestrict,
+		isBanned:       srv.IsBanned,
 		dialer:         srv.Dialer,
 		clock:          srv.clock,
 	}
@@ -699,6 +733,9 @@ func (srv *Server) setupDialScheduler() {
 	if config.dialer == nil {
 		config.dialer = tcpDialer{&net.Dialer{Timeout: defaultDialTimeout}}
 	}
+	if srv.quicTr != nil {
+		config.quicDialer = &quicDialer{tr: srv.quicTr, tls: srv.quicTLS}
+	}
 	var subProtocolVersion uint
 	if len(srv.Protocols) > 0 {
 		subProtocolVersion = srv.Protocols[0].Version
@@ -743,12 +780,7 @@ func (srv *Server) setupListening(ctx context.Context) error {
 		srv.updateLocalNodeStaticAddrCache()
 
 		if !tcp.IP.IsLoopback() && (srv.NAT != nil) && srv.NAT.SupportsMapping() {
-			srv.loopWG.Add(1)
-			go func() {
-				defer debug.LogPanic()
-				defer srv.loopWG.Done()
-				nat.Map(srv.NAT, srv.quit, "tcp", tcp.Port, tcp.Port, "ethereum p2p", srv.logger)
-			}()
+			srv.portMappingRegister <- &portMapping{protocol: "TCP", name: "ethereum p2p", port: tcp.Port}
 		}
 	}
 
@@ -845,6 +877,9 @@ running:
 				srv.dialsched.peerAdded(c)
 				if p.Inbound() {
 					inboundCount++
+					if ip := netutil.AddrIP(c.fd.RemoteAddr()); ip != nil && !netutil.IsLAN(ip) {
+						srv.lastInbound.Store(time.Now().UnixNano())
+					}
 				}
 			}
 			c.cont <- err
@@ -899,6 +934,8 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 		return DiscAlreadyConnected
 	case c.node.ID() == srv.localnode.ID():
 		return DiscSelf
+	case !c.is(trustedConn) && srv.IsBanned != nil && srv.IsBanned(c.node.ID()):
+		return DiscUselessPeer
 	case (len(srv.Protocols) > 0) && (countMatchingProtocols(srv.Protocols, c.caps) == 0):
 		return DiscUselessPeer
 	default:
@@ -959,7 +996,7 @@ func (srv *Server) listenLoop(ctx context.Context) {
 		}
 
 		remoteIP := netutil.AddrIP(fd.RemoteAddr())
-		if err := srv.checkInboundConn(fd, remoteIP); err != nil {
+		if err := srv.checkInboundConn(remoteIP); err != nil {
 			srv.logger.Trace("Rejected inbound connection", "addr", fd.RemoteAddr(), "err", err)
 			_ = fd.Close()
 			slots.Release(1)
@@ -982,7 +1019,7 @@ func (srv *Server) listenLoop(ctx context.Context) {
 	}
 }
 
-func (srv *Server) checkInboundConn(fd net.Conn, remoteIP net.IP) error {
+func (srv *Server) checkInboundConn(remoteIP net.IP) error {
 	if remoteIP == nil {
 		return nil
 	}
@@ -991,6 +1028,8 @@ func (srv *Server) checkInboundConn(fd net.Conn, remoteIP net.IP) error {
 		return errors.New("not whitelisted in NetRestrict")
 	}
 	// Reject Internet peers that try too often.
+	srv.inboundMu.Lock()
+	defer srv.inboundMu.Unlock()
 	now := srv.clock.Now()
 	srv.inboundHistory.expire(now, nil)
 	if !netutil.IsLAN(remoteIP) && srv.inboundHistory.contains(remoteIP.String()) {
@@ -1005,10 +1044,14 @@ func (srv *Server) checkInboundConn(fd net.Conn, remoteIP net.IP) error {
 // or the handshakes have failed.
 func (srv *Server) SetupConn(fd net.Conn, flags connFlag, dialDest *enode.Node) error {
 	c := &conn{fd: fd, flags: flags, cont: make(chan error)}
-	if dialDest == nil {
-		c.transport = srv.newTransport(fd, nil)
+	var dialPubkey *ecdsa.PublicKey
+	if dialDest != nil {
+		dialPubkey = dialDest.Pubkey()
+	}
+	if qc, ok := fd.(*quicConn); ok {
+		c.transport = newQUICTransport(qc, dialPubkey)
 	} else {
-		c.transport = srv.newTransport(fd, dialDest.Pubkey())
+		c.transport = srv.newTransport(fd, dialPubkey)
 	}
 
 	err := srv.setupConn(c, flags, dialDest)
@@ -1079,9 +1122,13 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 func nodeFromConn(pubkey *ecdsa.PublicKey, conn net.Conn) *enode.Node {
 	var ip net.IP
 	var port int
-	if tcp, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
-		ip = tcp.IP
-		port = tcp.Port
+	switch addr := conn.RemoteAddr().(type) {
+	case *net.TCPAddr:
+		ip = addr.IP
+		port = addr.Port
+	case *net.UDPAddr:
+		// The QUIC port of the peer is not a TCP port, only the IP is known.
+		ip = addr.IP
 	}
 	return enode.NewV4(pubkey, ip, port, port)
 }
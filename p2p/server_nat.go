@@ -0,0 +1,324 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"github.com/erigontech/erigon-lib/common/debug"
+	"github.com/erigontech/erigon-lib/diagnostics"
+	"github.com/erigontech/erigon/p2p/enr"
+	"github.com/erigontech/erigon/p2p/nat"
+	"github.com/erigontech/erigon/p2p/netutil"
+)
+
+const (
+	portMapDuration        = 10 * time.Minute
+	portMapRefreshInterval = 5 * time.Minute
+	portMapRetryInterval   = 30 * time.Second // after the first failure, doubled up to portMapRefreshInterval
+	portMapRediscoverAfter = 3                // failed renewals before looking for the router again
+
+	extipCheckInterval = 5 * time.Minute
+
+	reachabilityCheckDelay    = 2 * time.Minute
+	reachabilityCheckInterval = 15 * time.Minute
+	reachabilityDialTimeout   = 5 * time.Second
+	inboundReachableWindow    = 30 * time.Minute
+
+	natStatusInterval = time.Minute
+)
+
+// portMapping is a port mapping kept alive by natLoop.
+type portMapping struct {
+	protocol string // TCP or UDP
+	name     string
+	port     int // internal port
+	extPort  int // assigned external port, 0 if not mapped
+	nextTime time.Time
+	renewed  time.Time
+	failures int // consecutive failed renewals
+	err      error
+}
+
+// key identifies the mapping in natLoop. The QUIC transport listens on its own UDP port,
+// so its mapping can't be keyed by the protocol like the others.
+func (m *portMapping) key() string {
+	if m.name == quicMappingName {
+		return "QUIC"
+	}
+	return m.protocol
+}
+
+func (srv *Server) setupPortMapping() {
+	// portMappingRegister receives up to three values: the TCP port if listening is enabled,
+	// the UDP port if discovery is enabled and the QUIC port if the QUIC transport is enabled.
+	// It is buffered to not block the setup while the router is being discovered.
+	srv.portMappingRegister = make(chan *portMapping, 3)
+
+	if ip, ok := srv.NAT.(nat.ExtIP); ok {
+		// ExtIP doesn't block, set the IP right away.
+		srv.localnode.SetStaticIP(net.IP(ip))
+		srv.updateLocalNodeStaticAddrCache()
+	}
+}
+
+// resolvesExternalIP reports whether the external IP has to be asked from the NAT interface.
+func (srv *Server) resolvesExternalIP() bool {
+	switch srv.NAT.(type) {
+	case nil, nat.ExtIP:
+		return false
+	default:
+		return true
+	}
+}
+
+// natLoop keeps the port mappings alive, follows the changes of the external address
+// and checks whether the node is reachable by the peers.
+func (srv *Server) natLoop() {
+	defer debug.LogPanic()
+	defer srv.loopWG.Done()
+
+	var (
+		mappings    = make(map[string]*portMapping, 3)
+		extip       net.IP
+		extipFailed bool
+		checks      []diagnostics.ReachabilityCheck
+		started     = time.Now()
+
+		refresh      = time.NewTimer(math.MaxInt64)
+		extipCheck   = time.NewTimer(0)
+		reachability = time.NewTimer(reachabilityCheckDelay)
+		status       = time.NewTicker(natStatusInterval)
+	)
+	defer refresh.Stop()
+	defer extipCheck.Stop()
+	defer reachability.Stop()
+	defer status.Stop()
+	if !srv.resolvesExternalIP() {
+		extipCheck.Stop()
+	}
+
+	defer func() {
+		for _, m := range mappings {
+			if m.extPort != 0 {
+				srv.logger.Trace("Deleting port mapping", "proto", m.protocol, "extport", m.extPort, "intport", m.port, "interface", srv.NAT)
+				_ = srv.NAT.DeleteMapping(m.protocol, m.extPort, m.port)
+			}
+		}
+	}()
+
+	for {
+		next := time.Duration(math.MaxInt64)
+		for _, m := range mappings {
+			if d := time.Until(m.nextTime); d < next {
+				next = max(d, 0)
+			}
+		}
+		refresh.Reset(next)
+
+		select {
+		case <-srv.quit:
+			return
+
+		case m := <-srv.portMappingRegister:
+			m.nextTime = time.Now()
+			mappings[m.key()] = m
+
+		case <-refresh.C:
+			for _, m := range mappings {
+				if !time.Now().Before(m.nextTime) {
+					srv.renewPortMapping(m)
+				}
+			}
+
+		case <-extipCheck.C:
+			ip, err := srv.NAT.ExternalIP()
+			switch {
+			case err != nil && extip == nil && !extipFailed:
+				extipFailed = true
+				srv.logger.Warn("NAT ExternalIP resolution has failed, try to pass a different --nat option", "err", err)
+			case err != nil:
+				srv.logger.Debug("NAT ExternalIP resolution has failed", "err", err)
+			case !ip.Equal(extip):
+				if extip == nil {
+					srv.logger.Info("NAT ExternalIP resolved", "ip", ip)
+				} else {
+					srv.logger.Info("NAT ExternalIP changed", "old", extip, "new", ip, "interface", srv.NAT)
+				}
+				extip = ip
+				srv.localnode.SetStaticIP(ip)
+				srv.updateLocalNodeStaticAddrCache()
+			}
+			extipCheck.Reset(extipCheckInterval)
+
+		case <-reachability.C:
+			checks = srv.checkReachability()
+			reachability.Reset(reachabilityCheckInterval)
+
+		case <-status.C:
+		}
+
+		diagnostics.Send(srv.natStatus(mappings, checks, started))
+	}
+}
+
+// renewPortMapping adds or refreshes the mapping. The node record follows the external port
+// assigned by the router, repeated failures make an auto-discovered router to be looked for again.
+func (srv *Server) renewPortMapping(m *portMapping) {
+	now := time.Now()
+	logger := srv.logger.New("proto", m.protocol, "intport", m.port, "interface", srv.NAT)
+
+	extPort := m.extPort
+	if extPort == 0 {
+		extPort = m.port
+	}
+	p, err := srv.NAT.AddMapping(m.protocol, extPort, m.port, m.name, portMapDuration)
+	if err != nil {
+		m.failures++
+		m.err = err
+		retry := min(portMapRetryInterval<<min(m.failures-1, 8), portMapRefreshInterval)
+		m.nextTime = now.Add(retry)
+		logger.Debug("Couldn't add port mapping", "err", err, "failures", m.failures, "retry", retry)
+
+		if m.extPort != 0 && now.After(m.renewed.Add(portMapDuration)) {
+			logger.Warn("Port mapping has expired, peers may be unable to connect to the node", "extport", m.extPort, "err", err)
+			m.extPort = 0
+		}
+		if m.failures%portMapRediscoverAfter == 0 && nat.Rediscover(srv.NAT) {
+			logger.Info("Port mapping keeps failing, looking for the router again", "failures", m.failures)
+		}
+		return
+	}
+
+	m.failures = 0
+	m.err = nil
+	m.renewed = now
+	m.nextTime = now.Add(portMapRefreshInterval)
+
+	external := int(p)
+	if external == 0 {
+		external = extPort
+	}
+	if external == m.extPort {
+		logger.Trace("Refreshed port mapping", "extport", external)
+		return
+	}
+	if m.extPort == 0 {
+		logger.Info("Mapped network port", "extport", external)
+	} else {
+		logger.Info("External port of the mapping changed", "old", m.extPort, "new", external)
+	}
+	m.extPort = external
+	switch m.key() {
+	case "TCP":
+		srv.localnode.Set(enr.TCP(external))
+	case "UDP":
+		srv.localnode.SetFallbackUDP(external)
+	case "QUIC":
+		srv.localnode.Set(enr.QUIC(external))
+	}
+	srv.updateLocalNodeStaticAddrCache()
+}
+
+// checkReachability tests whether the peers can connect to the node: it looks for the peers
+// connected from public addresses and tries to connect to the external address of the node.
+// Connecting to itself needs NAT loopback support of the router, so only the success is conclusive.
+func (srv *Server) checkReachability() []diagnostics.ReachabilityCheck {
+	if srv.listener == nil {
+		return nil
+	}
+	now := time.Now()
+
+	inbound := diagnostics.ReachabilityCheck{Method: "inbound", Time: now}
+	if last := srv.lastInbound.Load(); last != 0 && now.Sub(time.Unix(0, last)) < inboundReachableWindow {
+		inbound.Reachable = true
+	} else {
+		inbound.Error = fmt.Sprintf("no peers connected from public addresses in the last %v", inboundReachableWindow)
+	}
+
+	dial := diagnostics.ReachabilityCheck{Method: "dial", Time: now}
+	self := srv.localnode.Node()
+	if ip := self.IP(); ip == nil || ip.IsUnspecified() || netutil.IsLAN(ip) || self.TCP() == 0 {
+		dial.Error = "no public external address, see the --nat option"
+	} else {
+		addr := net.JoinHostPort(ip.String(), fmt.Sprint(self.TCP()))
+		conn, err := net.DialTimeout("tcp", addr, reachabilityDialTimeout)
+		if err != nil {
+			dial.Error = err.Error()
+		} else {
+			_ = conn.Close()
+			dial.Reachable = true
+		}
+	}
+	return []diagnostics.ReachabilityCheck{inbound, dial}
+}
+
+func (srv *Server) natStatus(mappings map[string]*portMapping, checks []diagnostics.ReachabilityCheck, started time.Time) diagnostics.NATStatusUpdate {
+	self := srv.localnode.Node()
+	status := diagnostics.NATStatusUpdate{
+		ListenAddr:   srv.ListenAddr,
+		Mechanism:    "none",
+		ENRSeq:       self.Seq(),
+		Reachability: diagnostics.ReachabilityUnknown,
+		Checks:       checks,
+		Updated:      time.Now(),
+	}
+	if srv.NAT != nil {
+		status.Mechanism = srv.NAT.String()
+	}
+	if ip := self.IP(); ip != nil {
+		status.ExternalIP = ip.String()
+	}
+	if last := srv.lastInbound.Load(); last != 0 {
+		status.LastInbound = time.Unix(0, last)
+	}
+	for _, key := range []string{"TCP", "UDP", "QUIC"} {
+		m, ok := mappings[key]
+		if !ok {
+			continue
+		}
+		ms := diagnostics.NATMappingStatus{
+			Protocol:     m.protocol,
+			Name:         m.name,
+			InternalPort: m.port,
+			ExternalPort: m.extPort,
+			Renewed:      m.renewed,
+			Failures:     m.failures,
+		}
+		if !m.renewed.IsZero() {
+			ms.Expires = m.renewed.Add(portMapDuration)
+		}
+		if m.err != nil {
+			ms.Error = m.err.Error()
+		}
+		status.Mappings = append(status.Mappings, ms)
+	}
+
+	for _, check := range checks {
+		if check.Reachable {
+			status.Reachability = diagnostics.ReachabilityReachable
+			return status
+		}
+	}
+	if len(checks) > 0 && time.Since(started) >= inboundReachableWindow {
+		status.Reachability = diagnostics.ReachabilityUnreachable
+	}
+	return status
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erigontech/erigon-lib/diagnostics"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/p2p/enode"
+)
+
+// fakeNAT maps the ports to extPort and reports ip as the external address.
+type fakeNAT struct {
+	mu      sync.Mutex
+	ip      net.IP
+	extPort uint16
+	err     error
+	deleted []int
+}
+
+func (n *fakeNAT) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (uint16, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.extPort, n.err
+}
+
+func (n *fakeNAT) DeleteMapping(protocol string, extport, intport int) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.deleted = append(n.deleted, extport)
+	return nil
+}
+
+func (n *fakeNAT) ExternalIP() (net.IP, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.ip, nil
+}
+
+func (n *fakeNAT) SupportsMapping() bool { return true }
+func (n *fakeNAT) String() string        { return "fake" }
+
+func TestServerPortMapping(t *testing.T) {
+	fake := &fakeNAT{ip: net.IPv4(203, 0, 113, 1), extPort: 40000}
+	srv := &Server{Config: Config{
+		PrivateKey:      newkey(),
+		MaxPeers:        1,
+		MaxPendingPeers: 1,
+		NoDiscovery:     true,
+		NoDial:          true,
+		ListenAddr:      "0.0.0.0:0",
+		NAT:             fake,
+	}}
+	if err := srv.TestStart(log.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		self := srv.Self()
+		if self.TCP() == 40000 && self.IP().Equal(fake.ip) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("node record is not updated, got %v:%d", self.IP(), self.TCP())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	srv.Stop()
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.deleted) != 1 || fake.deleted[0] != 40000 {
+		t.Errorf("got deleted mappings %v, want [40000]", fake.deleted)
+	}
+}
+
+func TestServerPortMappingRenewal(t *testing.T) {
+	logger := log.New()
+	db, err := enode.OpenDB(context.Background(), "", t.TempDir(), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	fake := &fakeNAT{extPort: 40000}
+	srv := &Server{Config: Config{PrivateKey: newkey(), NAT: fake}, logger: logger}
+	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey, logger)
+
+	m := &portMapping{protocol: "TCP", name: "test", port: 30303}
+	srv.renewPortMapping(m)
+	if m.extPort != 40000 || srv.localnode.Node().TCP() != 40000 {
+		t.Fatalf("got mapped port %d and record port %d, want 40000", m.extPort, srv.localnode.Node().TCP())
+	}
+	seq := srv.localnode.Node().Seq()
+
+	// the router assigned another port
+	fake.extPort = 40001
+	srv.renewPortMapping(m)
+	if srv.localnode.Node().TCP() != 40001 || srv.localnode.Node().Seq() <= seq {
+		t.Errorf("record is not updated, port %d seq %d", srv.localnode.Node().TCP(), srv.localnode.Node().Seq())
+	}
+
+	// renewals fail until the lease expires
+	fake.err = errors.New("router is gone")
+	srv.renewPortMapping(m)
+	srv.renewPortMapping(m)
+	if m.failures != 2 || m.extPort != 40001 || time.Until(m.nextTime) > 2*portMapRetryInterval {
+		t.Errorf("got %d failures, port %d, next renewal in %v", m.failures, m.extPort, time.Until(m.nextTime))
+	}
+	m.renewed = time.Now().Add(-portMapDuration - time.Second)
+	srv.renewPortMapping(m)
+	if m.extPort != 0 {
+		t.Error("expired mapping is kept")
+	}
+
+	status := srv.natStatus(map[string]*portMapping{"TCP": m}, nil, time.Now())
+	if len(status.Mappings) != 1 || status.Mappings[0].Failures != 3 || status.Mappings[0].Error == "" {
+		t.Errorf("bad mapping status %+v", status.Mappings)
+	}
+	if status.Reachability != diagnostics.ReachabilityUnknown {
+		t.Errorf("got reachability %s, want unknown", status.Reachability)
+	}
+
+	checks := []diagnostics.ReachabilityCheck{{Method: "inbound"}, {Method: "dial"}}
+	status = srv.natStatus(nil, checks, time.Now().Add(-inboundReachableWindow))
+	if status.Reachability != diagnostics.ReachabilityUnreachable {
+		t.Errorf("got reachability %s, want unreachable", status.Reachability)
+	}
+	checks[1].Reachable = true
+	status = srv.natStatus(nil, checks, time.Now().Add(-inboundReachableWindow))
+	if status.Reachability != diagnostics.ReachabilityReachable {
+		t.Errorf("got reachability %s, want reachable", status.Reachability)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/quic-go/quic-go"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/erigontech/erigon-lib/common/debug"
+	"github.com/erigontech/erigon/p2p/enr"
+	"github.com/erigontech/erigon/p2p/netutil"
+)
+
+const quicMappingName = "ethereum quic"
+
+// setupQUIC starts the QUIC listener. Its UDP socket is also used to dial
+// the nodes advertising QUIC.
+func (srv *Server) setupQUIC(ctx context.Context) error {
+	addr, err := net.ResolveUDPAddr("udp", srv.QUICListenAddr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return err
+	}
+	tlsConf, err := newQUICTLSConfig()
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	tr := &quic.Transport{Conn: conn}
+	listener, err := tr.Listen(tlsConf, quicConfig())
+	if err != nil {
+		_ = tr.Close()
+		_ = conn.Close()
+		return err
+	}
+	srv.quicTr = tr
+	srv.quicListener = listener
+	srv.quicTLS = tlsConf
+
+	realaddr := conn.LocalAddr().(*net.UDPAddr)
+	srv.QUICListenAddr = realaddr.String()
+	srv.localnode.Set(enr.QUIC(realaddr.Port))
+	srv.updateLocalNodeStaticAddrCache()
+	if !realaddr.IP.IsLoopback() && (srv.NAT != nil) && srv.NAT.SupportsMapping() {
+		srv.portMappingRegister <- &portMapping{protocol: "UDP", name: quicMappingName, port: realaddr.Port}
+	}
+
+	srv.loopWG.Add(1)
+	go func() {
+		defer debug.LogPanic()
+		defer srv.loopWG.Done()
+		srv.quicListenLoop(ctx)
+	}()
+	return nil
+}
+
+// quicListenLoop runs in its own goroutine and accepts inbound QUIC connections.
+func (srv *Server) quicListenLoop(ctx context.Context) {
+	srv.logger.Trace("QUIC listener up", "addr", srv.quicListener.Addr())
+
+	// The slots limit accepts of new connections, separately from the TCP listener.
+	slots := semaphore.NewWeighted(int64(srv.MaxPendingPeers))
+	defer func() {
+		_ = slots.Acquire(ctx, int64(srv.MaxPendingPeers))
+	}()
+
+	for {
+		if slotErr := slots.Acquire(ctx, 1); slotErr != nil {
+			if !errors.Is(slotErr, context.Canceled) {
+				srv.logger.Error("Failed to get a peer connection slot", "err", slotErr)
+			}
+			return
+		}
+
+		conn, err := srv.quicListener.Accept(ctx)
+		if err != nil {
+			// Log the error unless the server is shutting down.
+			select {
+			case <-srv.quit:
+			default:
+				srv.logger.Error("QUIC listener failed to accept a connection", "err", err)
+			}
+			slots.Release(1)
+			return
+		}
+
+		remoteIP := netutil.AddrIP(conn.RemoteAddr())
+		if err := srv.checkInboundConn(remoteIP); err != nil {
+			srv.logger.Trace("Rejected inbound QUIC connection", "addr", conn.RemoteAddr(), "err", err)
+			_ = conn.CloseWithError(quic.ApplicationErrorCode(DiscRequested), "")
+			slots.Release(1)
+			continue
+		}
+		go func() {
+			defer debug.LogPanic()
+			defer slots.Release(1)
+
+			// The dialer opens the control stream and starts the RLPx handshake on it.
+			sctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+			stream, err := conn.AcceptStream(sctx)
+			cancel()
+			if err != nil {
+				srv.logger.Trace("Failed to accept QUIC control stream", "addr", conn.RemoteAddr(), "err", err)
+				_ = conn.CloseWithError(quic.ApplicationErrorCode(DiscNetworkError), "")
+				return
+			}
+			srv.logger.Trace("Accepted QUIC connection", "addr", conn.RemoteAddr())
+			// The error is logged in Server.setupConn().
+			_ = srv.SetupConn(&quicConn{Stream: stream, conn: conn}, inboundConn, nil)
+		}()
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+type quicTestResult struct {
+	proto string
+	got   string
+	err   error
+}
+
+// quicTestProtocol sends a greeting and reports the greeting of the remote end.
+func quicTestProtocol(name string, results chan<- quicTestResult) Protocol {
+	return Protocol{
+		Name:    name,
+		Version: 1,
+		Length:  2,
+		Run: func(p *Peer, rw MsgReadWriter) *PeerError {
+			if err := SendItems(rw, 1, "hello from "+name); err != nil {
+				results <- quicTestResult{proto: name, err: err}
+				return NewPeerError(PeerErrorTest, DiscNetworkError, err, "send failed")
+			}
+			msg, err := rw.ReadMsg()
+			if err != nil {
+				results <- quicTestResult{proto: name, err: err}
+				return NewPeerError(PeerErrorTest, DiscNetworkError, err, "read failed")
+			}
+			var greeting []string
+			err = msg.Decode(&greeting)
+			if err == nil && (msg.Code != 1 || len(greeting) != 1) {
+				err = fmt.Errorf("unexpected message %d %v", msg.Code, greeting)
+			}
+			if err != nil {
+				results <- quicTestResult{proto: name, err: err}
+				return NewPeerError(PeerErrorTest, DiscProtocolError, err, "bad greeting")
+			}
+			results <- quicTestResult{proto: name, got: greeting[0]}
+			// Keep the peer until the remote end disconnects.
+			for {
+				if _, err := rw.ReadMsg(); err != nil {
+					return NewPeerError(PeerErrorTest, DiscNetworkError, err, "read failed")
+				}
+			}
+		},
+	}
+}
+
+func startQUICTestServer(t *testing.T, withQUIC bool, results chan<- quicTestResult) *Server {
+	config := Config{
+		Name:            "test",
+		MaxPeers:        10,
+		MaxPendingPeers: 10,
+		ListenAddr:      "127.0.0.1:0",
+		NoDiscovery:     true,
+		PrivateKey:      newkey(),
+		Protocols:       []Protocol{quicTestProtocol("a", results), quicTestProtocol("b", results)},
+	}
+	if withQUIC {
+		config.QUICListenAddr = "127.0.0.1:0"
+	}
+	srv := &Server{Config: config}
+	if err := srv.TestStart(log.New()); err != nil {
+		t.Fatalf("could not start server: %v", err)
+	}
+	t.Cleanup(srv.Stop)
+	return srv
+}
+
+func checkQUICTestResults(t *testing.T, results <-chan quicTestResult) {
+	t.Helper()
+	// Both protocols run on both ends.
+	for i := 0; i < 4; i++ {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				t.Fatalf("protocol %s failed: %v", r.proto, r.err)
+			}
+			if want := "hello from " + r.proto; r.got != want {
+				t.Errorf("protocol %s got %q, want %q", r.proto, r.got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the protocols to exchange messages")
+		}
+	}
+}
+
+func TestServerQUIC(t *testing.T) {
+	results := make(chan quicTestResult, 4)
+	srv1 := startQUICTestServer(t, true, results)
+	srv2 := startQUICTestServer(t, true, results)
+
+	if nodeQUIC(srv2.Self()) == nil {
+		t.Fatal("node record doesn't advertise QUIC")
+	}
+	if !syncAddPeer(srv1, srv2.Self()) {
+		t.Fatal("peer not added")
+	}
+	checkQUICTestResults(t, results)
+
+	for _, srv := range []*Server{srv1, srv2} {
+		peers := srv.Peers()
+		if len(peers) != 1 {
+			t.Fatalf("got %d peers, want 1", len(peers))
+		}
+		qt, ok := peers[0].rw.transport.(*quicTransport)
+		if !ok {
+			t.Fatalf("peer uses %T, want QUIC transport", peers[0].rw.transport)
+		}
+		qt.smu.Lock()
+		streams := len(qt.streams)
+		qt.smu.Unlock()
+		if streams != 2 {
+			t.Errorf("got %d subprotocol streams, want 2", streams)
+		}
+	}
+
+	// The disconnect reason reaches the remote end.
+	events := make(chan *PeerEvent, 10)
+	sub := srv2.SubscribeEvents(events)
+	defer sub.Unsubscribe()
+	srv1.RemovePeer(srv2.Self())
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type != PeerEventTypeDrop {
+				continue
+			}
+			if want := "reason=" + DiscRequested.String(); !strings.Contains(ev.Error, want) {
+				t.Errorf("got drop error %q, want %q", ev.Error, want)
+			}
+			return
+		case <-timeout:
+			t.Fatal("remote end didn't drop the peer")
+		}
+	}
+}
+
+func TestServerQUICFallback(t *testing.T) {
+	results := make(chan quicTestResult, 4)
+	srv1 := startQUICTestServer(t, true, results)
+	srv2 := startQUICTestServer(t, false, results)
+
+	if nodeQUIC(srv2.Self()) != nil {
+		t.Fatal("node record advertises QUIC")
+	}
+	if !syncAddPeer(srv1, srv2.Self()) {
+		t.Fatal("peer not added")
+	}
+	checkQUICTestResults(t, results)
+
+	peers := srv1.Peers()
+	if len(peers) != 1 {
+		t.Fatalf("got %d peers, want 1", len(peers))
+	}
+	if _, ok := peers[0].rw.transport.(*rlpxTransport); !ok {
+		t.Fatalf("peer uses %T, want RLPx transport", peers[0].rw.transport)
+	}
+}
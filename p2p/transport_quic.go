@@ -0,0 +1,454 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/quic-go/quic-go"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/erigontech/erigon/p2p/enode"
+	"github.com/erigontech/erigon/p2p/enr"
+)
+
+const (
+	// quicALPN is the TLS application protocol negotiated by devp2p QUIC connections.
+	quicALPN = "devp2p"
+
+	// quicBindingMsg carries the TLS channel binding over the RLPx control stream.
+	// It is sent right after the encryption handshake, before the protocol handshake,
+	// so it can't clash with the base protocol messages.
+	quicBindingMsg    = 0x0f
+	quicBindingLabel  = "EXPORTER-devp2p-quic"
+	quicBindingLength = 32
+
+	// quicMaxMsgSize is the limit of the message size, same as the one of RLPx frames.
+	quicMaxMsgSize = 1<<24 - 1
+
+	// quicMaxStreams limits the subprotocol streams a peer may open.
+	quicMaxStreams = 32
+
+	// quicMaxReadBuffer limits the memory held by messages read from the subprotocol
+	// streams of one connection and not yet passed to ReadMsg. It fits one message
+	// of the maximum size along with its snappy-compressed frame.
+	quicMaxReadBuffer = 2 * quicMaxMsgSize
+)
+
+var (
+	errQUICBinding    = errors.New("QUIC channel binding mismatch")
+	errQUICBaseMsg    = errors.New("base protocol message on a QUIC subprotocol stream")
+	errQUICMsgTooBig  = errors.New("message too big")
+	errQUICConnClosed = errors.New("QUIC connection closed")
+)
+
+// quicConn is the control stream of a devp2p QUIC connection. It runs the RLPx
+// handshakes and carries the base protocol messages, so it stands in for the
+// TCP connection everywhere the server needs a net.Conn.
+type quicConn struct {
+	quic.Stream
+	conn   quic.Connection
+	reason quic.ApplicationErrorCode // sent to the remote end when the connection is closed
+}
+
+func (c *quicConn) LocalAddr() net.Addr  { return c.conn.LocalAddr() }
+func (c *quicConn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+// Close closes the whole QUIC connection, not only the control stream.
+func (c *quicConn) Close() error {
+	return c.conn.CloseWithError(c.reason, "")
+}
+
+// quicTransport is the transport of QUIC connections. The handshakes and the base
+// protocol messages go over the RLPx control stream, while the messages of every
+// subprotocol are sent on a separate unidirectional stream, so a lost packet of
+// one protocol doesn't hold back the messages of the others.
+//
+// The subprotocol streams are encrypted by QUIC only. The TLS certificates are
+// self-signed, the identity of the peer is verified by the RLPx handshake, which
+// is bound to the TLS session by exchanging its exported keying material over
+// the encrypted control stream.
+type quicTransport struct {
+	*rlpxTransport
+	fd     *quicConn
+	snappy bool
+
+	smu     sync.Mutex
+	streams map[Cap]*quicSendStream
+
+	readBuffer *semaphore.Weighted // bytes of the frames being read from subprotocol streams
+
+	in        chan quicRead
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type quicRead struct {
+	msg Msg
+	err error
+}
+
+type quicSendStream struct {
+	mu  sync.Mutex
+	s   quic.SendStream
+	buf bytes.Buffer
+}
+
+func newQUICTransport(fd *quicConn, dialDest *ecdsa.PublicKey) transport {
+	return &quicTransport{
+		rlpxTransport: newRLPX(fd, dialDest).(*rlpxTransport),
+		fd:            fd,
+		streams:       make(map[Cap]*quicSendStream),
+		readBuffer:    semaphore.NewWeighted(quicMaxReadBuffer),
+		in:            make(chan quicRead),
+		closed:        make(chan struct{}),
+	}
+}
+
+func (t *quicTransport) doEncHandshake(prv *ecdsa.PrivateKey) (*ecdsa.PublicKey, error) {
+	pubkey, err := t.rlpxTransport.doEncHandshake(prv)
+	if err != nil {
+		return nil, err
+	}
+	tlsState := t.fd.conn.ConnectionState().TLS
+	binding, err := tlsState.ExportKeyingMaterial(quicBindingLabel, nil, quicBindingLength)
+	if err != nil {
+		return nil, err
+	}
+	werr := make(chan error, 1)
+	go func() {
+		_, err := t.conn.Write(quicBindingMsg, binding)
+		werr <- err
+	}()
+	code, data, _, err := t.conn.Read()
+	if err != nil {
+		<-werr
+		return nil, err
+	}
+	if err := <-werr; err != nil {
+		return nil, fmt.Errorf("write error: %w", err)
+	}
+	if code != quicBindingMsg || !hmac.Equal(data, binding) {
+		return nil, errQUICBinding
+	}
+	return pubkey, nil
+}
+
+func (t *quicTransport) doProtoHandshake(our *protoHandshake) (*protoHandshake, error) {
+	their, err := t.rlpxTransport.doProtoHandshake(our)
+	if err != nil {
+		return nil, err
+	}
+	t.snappy = their.Version >= snappyProtocolVersion
+
+	go t.readControl()
+	go t.acceptStreams()
+	return their, nil
+}
+
+func (t *quicTransport) ReadMsg() (Msg, error) {
+	select {
+	case r := <-t.in:
+		return r.msg, r.err
+	case <-t.closed:
+		return Msg{}, errQUICConnClosed
+	}
+}
+
+// WriteMsg sends the base protocol messages over the control stream and the
+// subprotocol messages over the stream of their protocol.
+func (t *quicTransport) WriteMsg(msg Msg) error {
+	if msg.meterCap.Name == "" {
+		return t.rlpxTransport.WriteMsg(msg)
+	}
+	s, err := t.sendStream(msg.meterCap)
+	if err != nil {
+		return err
+	}
+	return s.write(msg, t.snappy)
+}
+
+func (t *quicTransport) close(err error) {
+	t.closeOnce.Do(func() { close(t.closed) })
+	if r, ok := err.(DiscReason); ok {
+		t.fd.reason = quic.ApplicationErrorCode(r)
+	} else {
+		t.fd.reason = quic.ApplicationErrorCode(DiscNetworkError)
+	}
+	// The disconnect message is written to the control stream before closing the
+	// connection, the remote end also gets the reason as the QUIC error code.
+	t.rlpxTransport.close(err)
+}
+
+func (t *quicTransport) sendStream(c Cap) (*quicSendStream, error) {
+	t.smu.Lock()
+	defer t.smu.Unlock()
+
+	if s := t.streams[c]; s != nil {
+		return s, nil
+	}
+	ctx, cancel := context.WithTimeout(t.fd.conn.Context(), frameWriteTimeout)
+	defer cancel()
+	s, err := t.fd.conn.OpenUniStreamSync(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.streams[c] = &quicSendStream{s: s}
+	return t.streams[c], nil
+}
+
+// write sends a message as a frame of the code and the size as uvarints,
+// followed by the payload.
+func (s *quicSendStream) write(msg Msg, compress bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if msg.Size > quicMaxMsgSize {
+		return errQUICMsgTooBig
+	}
+	s.buf.Reset()
+	if _, err := io.CopyN(&s.buf, msg.Payload, int64(msg.Size)); err != nil {
+		return err
+	}
+	data := s.buf.Bytes()
+	if compress {
+		data = snappy.Encode(nil, data)
+	}
+	frame := binary.AppendUvarint(make([]byte, 0, 2*binary.MaxVarintLen64+len(data)), msg.Code)
+	frame = binary.AppendUvarint(frame, uint64(len(data)))
+	frame = append(frame, data...)
+
+	if err := s.s.SetWriteDeadline(time.Now().Add(frameWriteTimeout)); err != nil {
+		return err
+	}
+	_, err := s.s.Write(frame)
+	return err
+}
+
+// deliver passes a read message or error to ReadMsg. It reports false
+// if the transport was closed in the meantime.
+func (t *quicTransport) deliver(msg Msg, err error) bool {
+	select {
+	case t.in <- quicRead{msg, err}:
+		return true
+	case <-t.closed:
+		return false
+	}
+}
+
+func (t *quicTransport) readControl() {
+	for {
+		msg, err := t.rlpxTransport.ReadMsg()
+		if err != nil {
+			t.deliver(Msg{}, quicReadErr(err))
+			return
+		}
+		if !t.deliver(msg, nil) {
+			return
+		}
+	}
+}
+
+func (t *quicTransport) acceptStreams() {
+	for {
+		s, err := t.fd.conn.AcceptUniStream(t.fd.conn.Context())
+		if err != nil {
+			t.deliver(Msg{}, quicReadErr(err))
+			return
+		}
+		go t.readStream(s)
+	}
+}
+
+func (t *quicTransport) readStream(s quic.ReceiveStream) {
+	r := bufio.NewReader(s)
+	for {
+		msg, held, err := t.readFrame(r)
+		if err != nil {
+			s.CancelRead(0)
+			t.deliver(Msg{}, quicReadErr(err))
+			return
+		}
+		delivered := t.deliver(msg, nil)
+		t.readBuffer.Release(held)
+		if !delivered {
+			return
+		}
+	}
+}
+
+// readFrame reads a message from a subprotocol stream. The returned number of bytes
+// is taken from the connection's read buffer and must be released by the caller.
+func (t *quicTransport) readFrame(r *bufio.Reader) (Msg, int64, error) {
+	code, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Msg{}, 0, err
+	}
+	if code < baseProtocolLength {
+		return Msg{}, 0, errQUICBaseMsg
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Msg{}, 0, err
+	}
+	if size > quicMaxMsgSize {
+		return Msg{}, 0, errQUICMsgTooBig
+	}
+	// The whole frame is reserved at once, so streams never wait while holding
+	// a part of the buffer.
+	need := int64(size)
+	if t.snappy {
+		header, err := r.Peek(min(int(size), binary.MaxVarintLen32))
+		if err != nil {
+			return Msg{}, 0, err
+		}
+		actualSize, err := snappy.DecodedLen(header)
+		if err != nil {
+			return Msg{}, 0, err
+		}
+		if actualSize > quicMaxMsgSize {
+			return Msg{}, 0, errQUICMsgTooBig
+		}
+		need += int64(actualSize)
+	}
+	if err := t.readBuffer.Acquire(t.fd.conn.Context(), need); err != nil {
+		return Msg{}, 0, err
+	}
+	// The buffer grows with the data actually received, not with the declared size.
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(size)); err != nil {
+		t.readBuffer.Release(need)
+		return Msg{}, 0, err
+	}
+	data := buf.Bytes()
+	if t.snappy {
+		if data, err = snappy.Decode(nil, data); err != nil {
+			t.readBuffer.Release(need)
+			return Msg{}, 0, err
+		}
+		// only the decoded payload is kept
+		t.readBuffer.Release(int64(size))
+		need -= int64(size)
+	}
+	return Msg{
+		ReceivedAt: time.Now(),
+		Code:       code,
+		Size:       uint32(len(data)),
+		meterSize:  uint32(size),
+		Payload:    bytes.NewReader(data),
+	}, need, nil
+}
+
+// quicReadErr turns the connection close error of the remote end into its
+// disconnect reason.
+func quicReadErr(err error) error {
+	var appErr *quic.ApplicationError
+	if errors.As(err, &appErr) && appErr.Remote {
+		if appErr.ErrorCode > math.MaxUint8 {
+			return DiscProtocolError
+		}
+		return DiscReason(appErr.ErrorCode)
+	}
+	return err
+}
+
+func quicConfig() *quic.Config {
+	return &quic.Config{
+		HandshakeIdleTimeout:  handshakeTimeout,
+		MaxIdleTimeout:        frameReadTimeout,
+		KeepAlivePeriod:       pingInterval,
+		MaxIncomingStreams:    1, // the control stream
+		MaxIncomingUniStreams: quicMaxStreams,
+	}
+}
+
+// newQUICTLSConfig creates the TLS config of the QUIC transport with a fresh
+// self-signed certificate. The certificates are not verified, the peers are
+// authenticated by the RLPx handshake.
+func newQUICTLSConfig() (*tls.Config, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(10 * 365 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates:       []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		NextProtos:         []string{quicALPN},
+		MinVersion:         tls.VersionTLS13,
+		InsecureSkipVerify: true, //nolint:gosec
+	}, nil
+}
+
+// nodeQUIC returns the QUIC endpoint of the node, or nil if it doesn't advertise one.
+func nodeQUIC(n *enode.Node) *net.UDPAddr {
+	var port enr.QUIC
+	if n.IP() == nil || n.Load(&port) != nil || port == 0 {
+		return nil
+	}
+	return &net.UDPAddr{IP: n.IP(), Port: int(port)}
+}
+
+// quicDialer dials the nodes over QUIC, sharing the UDP socket of the QUIC listener.
+type quicDialer struct {
+	tr  *quic.Transport
+	tls *tls.Config
+}
+
+func (d *quicDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	addr := nodeQUIC(dest)
+	if addr == nil {
+		return nil, errors.New("node has no QUIC endpoint")
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultDialTimeout)
+	defer cancel()
+	conn, err := d.tr.Dial(ctx, addr, d.tls, quicConfig())
+	if err != nil {
+		return nil, err
+	}
+	s, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		_ = conn.CloseWithError(0, "")
+		return nil, err
+	}
+	return &quicConn{Stream: s, conn: conn}, nil
+}
@@ -139,6 +139,40 @@ var (
 		Clique:                &chain.CliqueConfig{Period: 0, Epoch: 30000},
 	}
 
+	// CliqueDevnetChainConfig contains the chain parameters of a local network of clique signers.
+	CliqueDevnetChainConfig = &chain.Config{
+		ChainID:               big.NewInt(1339),
+		Consensus:             chain.CliqueConsensus,
+		HomesteadBlock:        big.NewInt(0),
+		TangerineWhistleBlock: big.NewInt(0),
+		SpuriousDragonBlock:   big.NewInt(0),
+		ByzantiumBlock:        big.NewInt(0),
+		ConstantinopleBlock:   big.NewInt(0),
+		PetersburgBlock:       big.NewInt(0),
+		IstanbulBlock:         big.NewInt(0),
+		MuirGlacierBlock:      big.NewInt(0),
+		BerlinBlock:           big.NewInt(0),
+		LondonBlock:           big.NewInt(0),
+		Clique:                &chain.CliqueConfig{Period: 5, Epoch: 30000},
+	}
+
+	// QBFTDevnetChainConfig contains the chain parameters of a local network of QBFT validators.
+	QBFTDevnetChainConfig = &chain.Config{
+		ChainID:               big.NewInt(1338),
+		Consensus:             chain.QBFTConsensus,
+		HomesteadBlock:        big.NewInt(0),
+		TangerineWhistleBlock: big.NewInt(0),
+		SpuriousDragonBlock:   big.NewInt(0),
+		ByzantiumBlock:        big.NewInt(0),
+		ConstantinopleBlock:   big.NewInt(0),
+		PetersburgBlock:       big.NewInt(0),
+		IstanbulBlock:         big.NewInt(0),
+		MuirGlacierBlock:      big.NewInt(0),
+		BerlinBlock:           big.NewInt(0),
+		LondonBlock:           big.NewInt(0),
+		QBFT:                  &chain.QBFTConfig{BlockPeriod: 2, Epoch: 30000, RequestTimeout: 4},
+	}
+
 	AmoyChainConfig = readChainSpec("chainspecs/amoy.json")
 
 	BorMainnetChainConfig = readChainSpec("chainspecs/bor-mainnet.json")
@@ -215,6 +249,10 @@ func ChainConfigByChainName(chain string) *chain.Config {
 		return MainnetChainConfig
 	case networkname.Dev:
 		return AllCliqueProtocolChanges
+	case networkname.QBFTDevnet:
+		return QBFTDevnetChainConfig
+	case networkname.CliqueDevnet:
+		return CliqueDevnetChainConfig
 	case networkname.Holesky:
 		return HoleskyChainConfig
 	case networkname.Sepolia:
@@ -29,6 +29,7 @@ var (
 	_ Error = new(invalidMessageError)
 	_ Error = new(InvalidParamsError)
 	_ Error = new(CustomError)
+	_ Error = new(HistoryExpiredError)
 )
 
 const defaultErrorCode = -32000
@@ -92,3 +93,16 @@ type CustomError struct {
 func (e *CustomError) ErrorCode() int { return e.Code }
 
 func (e *CustomError) Error() string { return e.Message }
+
+// HistoryExpiredError - the requested block body is older than the history expiry horizon (EIP-4444).
+// The error code is the one used by other clients for pruned history.
+type HistoryExpiredError struct {
+	Block     uint64
+	ExpiredTo uint64
+}
+
+func (e *HistoryExpiredError) ErrorCode() int { return 4444 }
+
+func (e *HistoryExpiredError) Error() string {
+	return fmt.Sprintf("pruned history unavailable: block %d is older than the history expiry horizon %d", e.Block, e.ExpiredTo)
+}
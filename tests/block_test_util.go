@@ -45,6 +45,7 @@ import (
 	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/eth/ethconsensusconfig"
 	"github.com/erigontech/erigon/turbo/services"
 	"github.com/erigontech/erigon/turbo/stages/mock"
@@ -61,6 +62,11 @@ func (bt *BlockTest) UnmarshalJSON(in []byte) error {
 	return json.Unmarshal(in, &bt.json)
 }
 
+// MarshalJSON implements json.Marshaler interface.
+func (bt *BlockTest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&bt.json)
+}
+
 type btJSON struct {
 	Blocks     []btBlock                `json:"blocks"`
 	Genesis    btHeader                 `json:"genesisBlockHeader"`
@@ -72,10 +78,10 @@ type btJSON struct {
 }
 
 type btBlock struct {
-	BlockHeader     *btHeader
-	ExpectException string
-	Rlp             string
-	UncleHeaders    []*btHeader
+	BlockHeader     *btHeader   `json:"blockHeader,omitempty"`
+	ExpectException string      `json:"expectException,omitempty"`
+	Rlp             string      `json:"rlp"`
+	UncleHeaders    []*btHeader `json:"uncleHeaders,omitempty"`
 }
 
 //go:generate gencodec -type btHeader -field-override btHeaderMarshaling -out gen_btheader.go
@@ -117,14 +123,23 @@ type btHeaderMarshaling struct {
 	ExcessBlobGas *math.HexOrDecimal64
 }
 
-func (bt *BlockTest) Run(t *testing.T, checkStateRoot bool) error {
+// Network - fork rules the test runs with
+func (bt *BlockTest) Network() string { return bt.json.Network }
+
+func (bt *BlockTest) Run(tb testing.TB, checkStateRoot bool) error {
+	return bt.RunWithVMConfig(tb, checkStateRoot, &vm.Config{})
+}
+
+// RunWithVMConfig - like Run, but blocks are executed with given vm.Config (for example with tracer).
+// tb may be nil to run outside of `go test`, then setup failures panic.
+func (bt *BlockTest) RunWithVMConfig(tb testing.TB, checkStateRoot bool, vmConfig *vm.Config) error {
 	config, ok := Forks[bt.json.Network]
 	if !ok {
 		return UnsupportedForkError{bt.json.Network}
 	}
 
 	engine := ethconsensusconfig.CreateConsensusEngineBareBones(context.Background(), config, log.New())
-	m := mock.MockWithGenesisEngine(t, bt.genesis(config), engine, false, checkStateRoot)
+	m := mock.MockWithGenesisEngineVMConfig(tb, bt.genesis(config), engine, vmConfig, false, checkStateRoot)
 	defer m.Close()
 
 	bt.br = m.BlockReader
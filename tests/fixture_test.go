@@ -0,0 +1,127 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/ethconsensusconfig"
+	"github.com/erigontech/erigon/turbo/stages/mock"
+)
+
+func TestFixtureGenerator(t *testing.T) {
+	ctx := context.Background()
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		counter  = libcommon.HexToAddress("0xc0")
+		receiver = libcommon.HexToAddress("0xfe")
+		config   = Forks["Paris"]
+		gspec    = &types.Genesis{
+			Config: config,
+			Alloc: types.GenesisAlloc{
+				address: {Balance: big.NewInt(libcommon.Ether)},
+				// increments slot 0 and logs 32 bytes of memory
+				counter: {
+					Balance: new(big.Int),
+					Code:    libcommon.FromHex("0x60005460010160005560206000a000"),
+					Storage: map[libcommon.Hash]libcommon.Hash{{}: libcommon.BigToHash(big.NewInt(5))},
+				},
+			},
+		}
+		signer   = types.LatestSignerForChainID(config.ChainID)
+		gasPrice = uint256.NewInt(10 * libcommon.GWei)
+	)
+	engine := ethconsensusconfig.CreateConsensusEngineBareBones(ctx, config, log.New())
+	m := mock.MockWithGenesisEngine(t, gspec, engine, false, true)
+
+	chain, err := core.GenerateChainWithTd(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, func(i int, block *core.BlockGen) {
+		txns := []types.Transaction{
+			types.NewTransaction(block.TxNonce(address), counter, uint256.NewInt(0), 100_000, gasPrice, nil),
+		}
+		if i == 1 {
+			// reads the slot written by the first transaction of the block
+			txns = append(txns,
+				types.NewTransaction(block.TxNonce(address)+1, counter, uint256.NewInt(0), 100_000, gasPrice, nil),
+				types.NewTransaction(block.TxNonce(address)+2, receiver, uint256.NewInt(1000), 21_000, gasPrice, nil),
+			)
+		}
+		for _, txn := range txns {
+			signed, err := types.SignTx(txn, *signer, key)
+			require.NoError(t, err)
+			block.AddTx(signed)
+		}
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	tx, err := m.DB.BeginTemporalRo(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	generator := NewFixtureGenerator(m.ChainConfig, m.Engine, m.BlockReader, t.TempDir(), m.Log)
+
+	t.Run("state test", func(t *testing.T) {
+		st, err := generator.StateTest(ctx, tx, 2, 1)
+		require.NoError(t, err)
+		require.Equal(t, libcommon.BigToHash(big.NewInt(7)), st.json.Pre[counter].Storage[libcommon.Hash{}])
+
+		// fixtures are run from json files
+		enc, err := json.Marshal(st)
+		require.NoError(t, err)
+		var fixture StateTest
+		require.NoError(t, json.Unmarshal(enc, &fixture))
+
+		dirs := datadir.New(t.TempDir())
+		db, _ := temporaltest.NewTestDB(t, dirs)
+		rwTx, err := db.BeginRw(ctx)
+		require.NoError(t, err)
+		defer rwTx.Rollback()
+		for _, subtest := range fixture.Subtests() {
+			_, _, err := fixture.Run(rwTx, subtest, vm.Config{}, dirs)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("block test", func(t *testing.T) {
+		bt, err := generator.BlockTest(ctx, tx, 2)
+		require.NoError(t, err)
+		require.Equal(t, libcommon.BigToHash(big.NewInt(6)), bt.json.Pre[counter].Storage[libcommon.Hash{}])
+		require.Equal(t, libcommon.BigToHash(big.NewInt(8)), bt.json.Post[counter].Storage[libcommon.Hash{}])
+		require.Equal(t, big.NewInt(1000), bt.json.Post[receiver].Balance)
+
+		enc, err := json.Marshal(bt)
+		require.NoError(t, err)
+		var fixture BlockTest
+		require.NoError(t, json.Unmarshal(enc, &fixture))
+		require.NoError(t, fixture.Run(t, true))
+	})
+}
@@ -0,0 +1,673 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/holiman/uint256"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/math"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	state2 "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon-lib/wrap"
+
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/consensuschain"
+	"github.com/erigontech/erigon/eth/ethconsensusconfig"
+	"github.com/erigontech/erigon/eth/tracers"
+	_ "github.com/erigontech/erigon/eth/tracers/native"
+	"github.com/erigontech/erigon/turbo/rpchelper"
+	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+// ErrFixtureDiverged - execution of the extracted fixture doesn't match execution on the chain,
+// usually because it depends on the hashes or numbers of blocks, which fixtures don't preserve
+var ErrFixtureDiverged = errors.New("fixture doesn't reproduce the execution")
+
+// FixtureGenerator extracts state tests and block tests reproducing execution of blocks of a synced chain.
+// Pre state of a fixture holds only the accounts and storage accessed by the execution: it is collected
+// with the prestateTracer and, for block tests, by recording the state read outside of transactions
+// by system calls and withdrawals. Post state is computed the same way the fixture is run by the harness.
+type FixtureGenerator struct {
+	config      *chain.Config
+	engine      consensus.Engine
+	blockReader services.FullBlockReader
+	tmpdir      string
+	logger      log.Logger
+}
+
+func NewFixtureGenerator(config *chain.Config, engine consensus.Engine, blockReader services.FullBlockReader, tmpdir string, logger log.Logger) *FixtureGenerator {
+	return &FixtureGenerator{config: config, engine: engine, blockReader: blockReader, tmpdir: tmpdir, logger: logger}
+}
+
+// StateTest extracts a state test reproducing transaction txIndex of the block. State tests have
+// no chain, so the result of BLOCKHASH differs from the one seen on the chain.
+func (g *FixtureGenerator) StateTest(ctx context.Context, tx kv.TemporalTx, number uint64, txIndex int) (*StateTest, error) {
+	block, err := g.readBlock(ctx, tx, number)
+	if err != nil {
+		return nil, err
+	}
+	if txIndex < 0 || txIndex >= block.Transactions().Len() {
+		return nil, fmt.Errorf("block %d has %d transactions, no transaction %d", number, block.Transactions().Len(), txIndex)
+	}
+	header := block.HeaderNoCopy()
+	fork, err := fixtureFork(g.config, header)
+	if err != nil {
+		return nil, err
+	}
+	pre, receipts, err := g.prestate(ctx, tx, block, txIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	txn := block.Transactions()[txIndex]
+	var txBytes bytes.Buffer
+	if err := txn.MarshalBinary(&txBytes); err != nil {
+		return nil, err
+	}
+	t := &StateTest{json: stJSON{
+		Env: stEnv{
+			Coinbase:      header.Coinbase,
+			Difficulty:    header.Difficulty,
+			Random:        new(big.Int).SetBytes(header.MixDigest[:]),
+			GasLimit:      header.GasLimit,
+			Number:        number,
+			Timestamp:     header.Time,
+			BaseFee:       header.BaseFee,
+			ExcessBlobGas: header.ExcessBlobGas,
+		},
+		Pre:  pre,
+		Tx:   stTransactionOf(txn),
+		Post: map[string][]stPostState{fork: {{Tx: txBytes.Bytes()}}},
+	}}
+
+	err = g.withTestDB(ctx, func(rwTx kv.RwTx, dirs datadir.Dirs) error {
+		statedb, root, err := t.RunNoVerify(rwTx, StateSubtest{Fork: fork}, vm.Config{}, dirs)
+		if err != nil {
+			return err
+		}
+		logs := rlpHash(statedb.Logs())
+		if logs != rlpHash(receipts[txIndex].Logs) {
+			return fmt.Errorf("%w: logs of transaction %d differ", ErrFixtureDiverged, txIndex)
+		}
+		post := &t.json.Post[fork][0]
+		post.Root, post.Logs = libcommon.UnprefixedHash(root), libcommon.UnprefixedHash(logs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// BlockTest extracts a block test reproducing the block. The block is rebased on top of a genesis
+// holding the pre state and runs as block 1, so NUMBER and BLOCKHASH differ from the chain:
+// transactions depending on them make receipts differ and are reported as ErrFixtureDiverged.
+func (g *FixtureGenerator) BlockTest(ctx context.Context, tx kv.TemporalTx, number uint64) (*BlockTest, error) {
+	block, err := g.readBlock(ctx, tx, number)
+	if err != nil {
+		return nil, err
+	}
+	fork, err := fixtureFork(g.config, block.HeaderNoCopy())
+	if err != nil {
+		return nil, err
+	}
+	parent, err := g.blockReader.Header(ctx, tx, block.ParentHash(), number-1)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("parent %x of block %d not found", block.ParentHash(), number)
+	}
+	pre, _, err := g.prestate(ctx, tx, block, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	// genesis takes the place of the parent, so base fee and excess blob gas of the block are the same
+	config := Forks[fork]
+	genesis := &types.Genesis{
+		Config:        config,
+		Timestamp:     parent.Time,
+		GasLimit:      parent.GasLimit,
+		GasUsed:       parent.GasUsed,
+		Difficulty:    new(big.Int),
+		BaseFee:       parent.BaseFee,
+		BlobGasUsed:   parent.BlobGasUsed,
+		ExcessBlobGas: parent.ExcessBlobGas,
+		Alloc:         pre,
+	}
+
+	var bt *BlockTest
+	err = g.withTestDB(ctx, func(rwTx kv.RwTx, dirs datadir.Dirs) error {
+		genesisBlock, _, err := core.GenesisToBlock(genesis, dirs, g.logger)
+		if err != nil {
+			return err
+		}
+		rebased, post, err := g.executeRebased(ctx, rwTx, config, genesisBlock, block, pre)
+		if err != nil {
+			return err
+		}
+		enc, err := rlp.EncodeToBytes(rebased)
+		if err != nil {
+			return err
+		}
+		bt = &BlockTest{json: btJSON{
+			Blocks:     []btBlock{{BlockHeader: btHeaderOf(rebased.HeaderNoCopy()), Rlp: hexutil.Encode(enc)}},
+			Genesis:    *btHeaderOf(genesisBlock.HeaderNoCopy()),
+			Pre:        pre,
+			Post:       post,
+			BestBlock:  libcommon.UnprefixedHash(rebased.Hash()),
+			Network:    fork,
+			SealEngine: "NoProof",
+		}}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bt, nil
+}
+
+func (g *FixtureGenerator) readBlock(ctx context.Context, tx kv.TemporalTx, number uint64) (*types.Block, error) {
+	block, err := g.blockReader.BlockByNumber(ctx, tx, number)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	return block, nil
+}
+
+// prestate executes the block on the historical state up to transaction txIndex, or the whole block
+// if txIndex is negative, and returns the state accessed by the traced transactions before the block
+func (g *FixtureGenerator) prestate(ctx context.Context, tx kv.TemporalTx, block *types.Block, txIndex int) (types.GenesisAlloc, types.Receipts, error) {
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, g.blockReader))
+	// txIndex -1 is the system transaction at the beginning of the block
+	historyReader, err := rpchelper.CreateHistoryStateReader(tx, txNumsReader, block.NumberU64(), -1, g.config.ChainName)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := newPrestateRecorder(historyReader)
+	ibs := state.New(reader)
+	header := block.HeaderNoCopy()
+	chainReader := consensuschain.NewReader(g.config, tx, g.blockReader, g.logger)
+	if err := core.InitializeBlockExecution(g.engine, chainReader, header, g.config, ibs, nil, g.logger, nil); err != nil {
+		return nil, nil, err
+	}
+
+	getHeader := func(hash libcommon.Hash, number uint64) *types.Header {
+		h, _ := g.blockReader.Header(ctx, tx, hash, number)
+		return h
+	}
+	blockHashFunc := core.GetHashFn(header, getHeader)
+	gp := new(core.GasPool).AddGas(header.GasLimit).AddBlobGas(g.config.GetMaxBlobGasPerBlock(header.Time))
+	noop := state.NewNoopWriter()
+	var usedGas, usedBlobGas uint64
+
+	pre := types.GenesisAlloc{}
+	receipts := make(types.Receipts, 0, block.Transactions().Len())
+	for i, txn := range block.Transactions() {
+		if txIndex >= 0 && i > txIndex {
+			break
+		}
+		var vmConfig vm.Config
+		var tracer *tracers.Tracer
+		if txIndex < 0 || i == txIndex {
+			tracer, err = tracers.New("prestateTracer", &tracers.Context{BlockHash: block.Hash(), TxIndex: i, TxHash: txn.Hash()}, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+			vmConfig.Tracer = tracer.Hooks
+		}
+		ibs.SetTxContext(i)
+		reader.paused = true
+		receipt, _, err := core.ApplyTransaction(g.config, blockHashFunc, g.engine, nil, gp, ibs, noop, header, txn, &usedGas, &usedBlobGas, vmConfig)
+		reader.paused = false
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not apply txn %d from block %d [%x]: %w", i, block.NumberU64(), txn.Hash(), err)
+		}
+		receipts = append(receipts, receipt)
+		if tracer == nil {
+			continue
+		}
+		result, err := tracer.GetResult()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := mergePrestate(pre, result); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if txIndex < 0 {
+		if _, _, _, _, err := core.FinalizeBlockExecution(g.engine, reader, header, block.Transactions(), block.Uncles(), noop, g.config, ibs, receipts, block.Withdrawals(), chainReader, true, g.logger, nil); err != nil {
+			return nil, nil, err
+		}
+		reader.mergeInto(pre)
+	}
+	return minimalAlloc(pre), receipts, nil
+}
+
+// executeRebased executes the block as block 1 on top of the genesis holding the pre state,
+// returns the block with the resulting state root and the post state of the accessed accounts
+func (g *FixtureGenerator) executeRebased(ctx context.Context, tx kv.RwTx, config *chain.Config, genesisBlock, block *types.Block, pre types.GenesisAlloc) (*types.Block, types.GenesisAlloc, error) {
+	header, err := uncachedHeader(block.HeaderNoCopy())
+	if err != nil {
+		return nil, nil, err
+	}
+	header.Number = big.NewInt(1)
+	header.ParentHash = genesisBlock.Hash()
+
+	if _, err := MakePreState(config.Rules(0, genesisBlock.Time()), tx, pre, header.Number.Uint64()); err != nil {
+		return nil, nil, err
+	}
+	var txc wrap.TxContainer
+	txc.Tx = tx
+	domains, err := state2.NewSharedDomains(tx, g.logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer domains.Close()
+	txc.Doms = domains
+	reader := rpchelper.NewLatestStateReader(tx)
+	writer := rpchelper.NewLatestStateWriter(txc, nil, header.Number.Uint64())
+	ibs := state.New(reader)
+
+	engine := ethconsensusconfig.CreateConsensusEngineBareBones(ctx, config, g.logger)
+	chainReader := consensuschain.NewReader(config, tx, nil, g.logger)
+	if err := core.InitializeBlockExecution(engine, chainReader, header, config, ibs, writer, g.logger, nil); err != nil {
+		return nil, nil, err
+	}
+
+	genesisHash := genesisBlock.Hash()
+	blockHashFunc := func(n uint64) libcommon.Hash {
+		if n == 0 {
+			return genesisHash
+		}
+		return libcommon.Hash{}
+	}
+	gp := new(core.GasPool).AddGas(header.GasLimit).AddBlobGas(config.GetMaxBlobGasPerBlock(header.Time))
+	var usedGas, usedBlobGas uint64
+	receipts := make(types.Receipts, 0, block.Transactions().Len())
+	for i, txn := range block.Transactions() {
+		ibs.SetTxContext(i)
+		receipt, _, err := core.ApplyTransaction(config, blockHashFunc, engine, nil, gp, ibs, writer, header, txn, &usedGas, &usedBlobGas, vm.Config{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: transaction %d: %v", ErrFixtureDiverged, i, err)
+		}
+		receipts = append(receipts, receipt)
+	}
+	if usedGas != header.GasUsed {
+		return nil, nil, fmt.Errorf("%w: gas used %d, block %d", ErrFixtureDiverged, usedGas, header.GasUsed)
+	}
+	if receiptHash := types.DeriveSha(receipts); receiptHash != header.ReceiptHash {
+		return nil, nil, fmt.Errorf("%w: receipts root %x, block %x", ErrFixtureDiverged, receiptHash, header.ReceiptHash)
+	}
+
+	newBlock, _, _, _, err := core.FinalizeBlockExecution(engine, reader, header, block.Transactions(), block.Uncles(), writer, config, ibs, receipts, block.Withdrawals(), chainReader, true, g.logger, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	root, err := domains.ComputeCommitment(ctx, true, header.Number.Uint64(), "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("ComputeCommitment: %w", err)
+	}
+	// execution has hashed the header without the state root
+	if header, err = uncachedHeader(newBlock.HeaderNoCopy()); err != nil {
+		return nil, nil, err
+	}
+	header.Root = libcommon.BytesToHash(root)
+
+	// accounts created by the block are not in the pre state
+	touched := make(map[libcommon.Address]struct{}, len(pre))
+	for addr := range pre {
+		touched[addr] = struct{}{}
+	}
+	touched[header.Coinbase] = struct{}{}
+	for i, txn := range block.Transactions() {
+		if to := txn.GetTo(); to != nil {
+			touched[*to] = struct{}{}
+		} else {
+			touched[receipts[i].ContractAddress] = struct{}{}
+		}
+	}
+	for _, w := range block.Withdrawals() {
+		touched[w.Address] = struct{}{}
+	}
+
+	post := make(types.GenesisAlloc, len(touched))
+	for addr := range touched {
+		if exists, err := ibs.Exist(addr); err != nil {
+			return nil, nil, err
+		} else if !exists {
+			continue
+		}
+		account := pre[addr]
+		balance, err := ibs.GetBalance(addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		nonce, err := ibs.GetNonce(addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		code, err := ibs.GetCode(addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		// code may point to the database, which is closed once the fixture is built
+		postAccount := types.GenesisAccount{Balance: balance.ToBig(), Nonce: nonce, Code: libcommon.CopyBytes(code)}
+		if len(account.Storage) > 0 {
+			postAccount.Storage = make(map[libcommon.Hash]libcommon.Hash, len(account.Storage))
+		}
+		for key := range account.Storage {
+			var value uint256.Int
+			if err := ibs.GetState(addr, &key, &value); err != nil {
+				return nil, nil, err
+			}
+			postAccount.Storage[key] = value.Bytes32()
+		}
+		post[addr] = postAccount
+	}
+	return newBlock.WithSeal(header), post, nil
+}
+
+// withTestDB runs fn on an empty database in a temporary directory, as the harness runs fixtures
+func (g *FixtureGenerator) withTestDB(ctx context.Context, fn func(tx kv.RwTx, dirs datadir.Dirs) error) error {
+	tmp, err := os.MkdirTemp(g.tmpdir, "fixture-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	dirs := datadir.New(tmp)
+	db, agg := temporaltest.NewTestDB(nil, dirs)
+	defer db.Close()
+	defer agg.Close()
+
+	tx, err := db.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return fn(tx, dirs)
+}
+
+// fixtureFork - fork of Forks the fixture of the block runs with
+func fixtureFork(config *chain.Config, header *types.Header) (string, error) {
+	if config.ChainID == nil || config.ChainID.Cmp(big.NewInt(1)) != 0 {
+		return "", fmt.Errorf("fixtures run with chain id 1, transactions of chain %v can't be replayed", config.ChainID)
+	}
+	number := header.Number.Uint64()
+	if !config.IsLondon(number) || header.Difficulty.Sign() != 0 {
+		return "", fmt.Errorf("block %d is not post-merge, only post-merge blocks are supported", number)
+	}
+	rules := config.Rules(number, header.Time)
+	switch {
+	case rules.IsOsaka:
+		return "", UnsupportedForkError{"Osaka"}
+	case rules.IsPrague:
+		return "Prague", nil
+	case rules.IsCancun:
+		return "Cancun", nil
+	case rules.IsShanghai:
+		return "Shanghai", nil
+	default:
+		return "Paris", nil
+	}
+}
+
+// prestateAccount - account in the result of the prestateTracer
+type prestateAccount struct {
+	Balance *hexutil.Big                      `json:"balance"`
+	Code    hexutil.Bytes                     `json:"code"`
+	Nonce   uint64                            `json:"nonce"`
+	Storage map[libcommon.Hash]libcommon.Hash `json:"storage"`
+}
+
+// mergePrestate adds the prestateTracer result of a transaction. State accessed by earlier
+// transactions of the block is already there with the value it had before the block.
+func mergePrestate(pre types.GenesisAlloc, result json.RawMessage) error {
+	var traced map[libcommon.Address]*prestateAccount
+	if err := json.Unmarshal(result, &traced); err != nil {
+		return err
+	}
+	for addr, traced := range traced {
+		account, ok := pre[addr]
+		if !ok {
+			account = types.GenesisAccount{Balance: traced.Balance.ToInt(), Nonce: traced.Nonce, Code: traced.Code}
+		}
+		for key, value := range traced.Storage {
+			if account.Storage == nil {
+				account.Storage = map[libcommon.Hash]libcommon.Hash{}
+			}
+			if _, ok := account.Storage[key]; !ok {
+				account.Storage[key] = value
+			}
+		}
+		pre[addr] = account
+	}
+	return nil
+}
+
+// minimalAlloc drops empty storage slots and accounts which don't exist, empty state gives the same answers
+func minimalAlloc(pre types.GenesisAlloc) types.GenesisAlloc {
+	alloc := make(types.GenesisAlloc, len(pre))
+	for addr, account := range pre {
+		for key, value := range account.Storage {
+			if value == (libcommon.Hash{}) {
+				delete(account.Storage, key)
+			}
+		}
+		if len(account.Storage) == 0 {
+			account.Storage = nil
+		}
+		if account.Balance == nil {
+			account.Balance = new(big.Int)
+		}
+		if account.Balance.Sign() == 0 && account.Nonce == 0 && len(account.Code) == 0 && len(account.Storage) == 0 {
+			continue
+		}
+		alloc[addr] = account
+	}
+	return alloc
+}
+
+// prestateRecorder records the state read outside of transactions: system calls and withdrawals
+// are not seen by the prestateTracer. Only first reads of the block reach the reader, so recorded
+// values are the ones before the block.
+type prestateRecorder struct {
+	state.StateReader
+	paused   bool
+	accounts map[libcommon.Address]types.GenesisAccount
+	storage  map[libcommon.Address]map[libcommon.Hash]libcommon.Hash
+}
+
+func newPrestateRecorder(reader state.StateReader) *prestateRecorder {
+	return &prestateRecorder{
+		StateReader: reader,
+		accounts:    map[libcommon.Address]types.GenesisAccount{},
+		storage:     map[libcommon.Address]map[libcommon.Hash]libcommon.Hash{},
+	}
+}
+
+func (r *prestateRecorder) ReadAccountData(address libcommon.Address) (*accounts.Account, error) {
+	account, err := r.StateReader.ReadAccountData(address)
+	if err != nil || r.paused {
+		return account, err
+	}
+	if err := r.recordAccount(address, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (r *prestateRecorder) ReadAccountStorage(address libcommon.Address, incarnation uint64, key *libcommon.Hash) ([]byte, error) {
+	enc, err := r.StateReader.ReadAccountStorage(address, incarnation, key)
+	if err != nil || r.paused {
+		return enc, err
+	}
+	// the account may have been read by a transaction, recorded storage needs it too
+	if _, ok := r.accounts[address]; !ok {
+		account, err := r.StateReader.ReadAccountData(address)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.recordAccount(address, account); err != nil {
+			return nil, err
+		}
+	}
+	slots, ok := r.storage[address]
+	if !ok {
+		slots = map[libcommon.Hash]libcommon.Hash{}
+		r.storage[address] = slots
+	}
+	if _, ok := slots[*key]; !ok {
+		slots[*key] = libcommon.BytesToHash(enc)
+	}
+	return enc, nil
+}
+
+func (r *prestateRecorder) recordAccount(address libcommon.Address, account *accounts.Account) error {
+	if _, ok := r.accounts[address]; ok {
+		return nil
+	}
+	recorded := types.GenesisAccount{Balance: new(big.Int)}
+	if account != nil {
+		recorded.Balance, recorded.Nonce = account.Balance.ToBig(), account.Nonce
+		if !account.IsEmptyCodeHash() {
+			code, err := r.StateReader.ReadAccountCode(address, account.Incarnation)
+			if err != nil {
+				return err
+			}
+			recorded.Code = libcommon.CopyBytes(code)
+		}
+	}
+	r.accounts[address] = recorded
+	return nil
+}
+
+// mergeInto adds the recorded state to the traced one. Recorded values take precedence: the tracer
+// looks values up when a transaction accesses them, after system calls may have changed them.
+func (r *prestateRecorder) mergeInto(pre types.GenesisAlloc) {
+	for addr, recorded := range r.accounts {
+		account := pre[addr]
+		account.Balance, account.Nonce, account.Code = recorded.Balance, recorded.Nonce, recorded.Code
+		for key, value := range r.storage[addr] {
+			if account.Storage == nil {
+				account.Storage = map[libcommon.Hash]libcommon.Hash{}
+			}
+			account.Storage[key] = value
+		}
+		pre[addr] = account
+	}
+}
+
+// uncachedHeader - copy of the header to be modified, CopyHeader keeps the cached hash
+func uncachedHeader(h *types.Header) (*types.Header, error) {
+	enc, err := rlp.EncodeToBytes(h)
+	if err != nil {
+		return nil, err
+	}
+	cpy := new(types.Header)
+	if err := rlp.DecodeBytes(enc, cpy); err != nil {
+		return nil, err
+	}
+	return cpy, nil
+}
+
+func btHeaderOf(h *types.Header) *btHeader {
+	return &btHeader{
+		Bloom:                 h.Bloom,
+		Coinbase:              h.Coinbase,
+		MixHash:               h.MixDigest,
+		Nonce:                 h.Nonce,
+		Number:                h.Number,
+		Hash:                  h.Hash(),
+		ParentHash:            h.ParentHash,
+		ReceiptTrie:           h.ReceiptHash,
+		StateRoot:             h.Root,
+		TransactionsTrie:      h.TxHash,
+		UncleHash:             h.UncleHash,
+		ExtraData:             h.Extra,
+		Difficulty:            h.Difficulty,
+		GasLimit:              h.GasLimit,
+		GasUsed:               h.GasUsed,
+		Timestamp:             h.Time,
+		BaseFeePerGas:         h.BaseFee,
+		WithdrawalsRoot:       h.WithdrawalsHash,
+		BlobGasUsed:           h.BlobGasUsed,
+		ExcessBlobGas:         h.ExcessBlobGas,
+		ParentBeaconBlockRoot: h.ParentBeaconBlockRoot,
+		RequestsHash:          h.RequestsHash,
+	}
+}
+
+// stTransactionOf - transaction section of a state test, the transaction itself is taken from txbytes
+// of the post state, as there is no secret key to sign it with
+func stTransactionOf(txn types.Transaction) stTransaction {
+	st := stTransaction{
+		Nonce:    math.HexOrDecimal64(txn.GetNonce()),
+		GasLimit: []math.HexOrDecimal64{math.HexOrDecimal64(txn.GetGasLimit())},
+		Data:     []string{hexutil.Encode(txn.GetData())},
+		Value:    []string{hexutil.EncodeBig(txn.GetValue().ToBig())},
+	}
+	if to := txn.GetTo(); to != nil {
+		st.To = to.Hex()
+	}
+	switch txn.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		st.GasPrice = (*math.HexOrDecimal256)(txn.GetFeeCap().ToBig())
+	default:
+		st.MaxFeePerGas = (*math.HexOrDecimal256)(txn.GetFeeCap().ToBig())
+		st.MaxPriorityFeePerGas = (*math.HexOrDecimal256)(txn.GetTipCap().ToBig())
+	}
+	if accessList := txn.GetAccessList(); len(accessList) > 0 {
+		st.AccessLists = []*types.AccessList{&accessList}
+	}
+	if btx, ok := txn.(*types.BlobTx); ok {
+		st.BlobGasFeeCap = (*math.HexOrDecimal256)(btx.MaxFeePerBlobGas.ToBig())
+	}
+	if stx, ok := txn.(*types.SetCodeTransaction); ok {
+		for _, auth := range stx.GetAuthorizations() {
+			st.Authorizations = append(st.Authorizations, types.JsonAuthorization{}.FromAuthorization(auth))
+		}
+	}
+	return st
+}
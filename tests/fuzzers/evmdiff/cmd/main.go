@@ -0,0 +1,128 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// evmdiff - differential fuzzing of Erigon's EVM:
+//
+//	go run ./tests/fuzzers/evmdiff/cmd -reference "evm --json statetest" -n 1000 -out ./divergences
+//	go run ./tests/fuzzers/evmdiff/cmd -stored ./tests/fuzzers/evmdiff/testdata
+//	go run ./tests/fuzzers/evmdiff/cmd -record ./traces -reference "evm --json statetest" -n 100
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/erigontech/erigon/tests/fuzzers/evmdiff"
+)
+
+func main() {
+	var (
+		reference = flag.String("reference", "", "command of reference EVM, state-test file is appended as last argument, e.g. \"evm --json statetest\"")
+		stored    = flag.String("stored", "", "dir with stored reference traces (<name>.json + <name>.jsonl) to compare with")
+		record    = flag.String("record", "", "dir where generated programs and their reference traces are stored (no comparison)")
+		out       = flag.String("out", "divergences", "dir where minimized diverging programs and traces are saved")
+		fork      = flag.String("fork", "Cancun", "fork of state tests")
+		n         = flag.Int("n", 100, "amount of programs to generate")
+		size      = flag.Int("size", 64, "max amount of instructions in program")
+		seed      = flag.Int64("seed", time.Now().UnixNano(), "seed of program generator")
+	)
+	flag.Parse()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var err error
+	if *stored != "" {
+		err = checkStored(ctx, *stored, *fork)
+	} else {
+		err = fuzz(ctx, *reference, *record, *out, *fork, *n, *size, *seed)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func checkStored(ctx context.Context, dir, fork string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	h := &evmdiff.Harness{Fork: fork, Erigon: evmdiff.ErigonTracer{}, Reference: evmdiff.StoredTracer{}}
+	failed := 0
+	for _, file := range files {
+		res, err := h.CheckFile(ctx, file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if res.Divergence != nil {
+			failed++
+			fmt.Printf("FAIL %s: %s\n", file, res.Divergence)
+		}
+	}
+	fmt.Printf("checked %d stored traces, %d diverged\n", len(files), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d divergences", failed)
+	}
+	return nil
+}
+
+func fuzz(ctx context.Context, reference, record, out, fork string, n, size int, seed int64) error {
+	if reference == "" {
+		return fmt.Errorf("-reference or -stored is required")
+	}
+	fmt.Printf("seed %d\n", seed)
+	r := rand.New(rand.NewSource(seed)) //nolint:gosec
+	h := &evmdiff.Harness{Fork: fork, Erigon: evmdiff.ErigonTracer{}, Reference: evmdiff.ExternalTracer{Cmd: strings.Fields(reference)}}
+	diverged := 0
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		p := evmdiff.Generate(r, 1+r.Intn(size))
+		res, err := h.Check(ctx, p)
+		if err != nil {
+			fmt.Printf("program %d: %v\n", i, err)
+			continue
+		}
+		if record != "" {
+			if err := res.Save(record, fmt.Sprintf("%d_%d", seed, i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if res.Divergence == nil {
+			continue
+		}
+		diverged++
+		fmt.Printf("program %d diverged: %s, minimizing %d instructions\n", i, res.Divergence, len(p.Code))
+		minimized, minimizedRes, err := h.Minimize(ctx, p, res)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%d_%d", seed, i)
+		if err := minimizedRes.Save(out, name); err != nil {
+			return err
+		}
+		fmt.Printf("program %d minimized to %d instructions (%d runs): %s, saved %s\n", i, len(minimized.Code), minimizedRes.ProgramsChecked, minimizedRes.Divergence, filepath.Join(out, name+".json"))
+	}
+	fmt.Printf("checked %d programs, %d diverged\n", n, diverged)
+	return nil
+}
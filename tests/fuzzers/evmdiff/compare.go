@@ -0,0 +1,162 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package evmdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Fields of EIP-3155 lines which are compared. Other fields (memory, returnData, opName, error
+// messages) are client-specific or optional.
+var (
+	opFields      = []string{"pc", "op", "gas", "gasCost", "stack", "depth", "refund"}
+	summaryFields = []string{"output", "gasUsed"}
+	rootFields    = []string{"stateRoot"}
+
+	// bytesFields are compared byte by byte, other fields are quantities
+	bytesFields = map[string]bool{"output": true, "stateRoot": true}
+)
+
+// Divergence - first difference of two traces
+type Divergence struct {
+	Line      int // 0-based line of trace
+	Field     string
+	Erigon    string
+	Reference string
+}
+
+func (d *Divergence) String() string {
+	return fmt.Sprintf("line %d, field %q: erigon=%s, reference=%s", d.Line, d.Field, d.Erigon, d.Reference)
+}
+
+// Compare - returns first divergence of traces or nil if they are equal
+func Compare(erigon, reference []byte) (*Divergence, error) {
+	a, err := parseTrace(erigon)
+	if err != nil {
+		return nil, fmt.Errorf("erigon trace: %w", err)
+	}
+	b, err := parseTrace(reference)
+	if err != nil {
+		return nil, fmt.Errorf("reference trace: %w", err)
+	}
+	for i := 0; i < max(len(a), len(b)); i++ {
+		if i >= len(a) || i >= len(b) {
+			d := &Divergence{Line: i, Field: "<line>", Erigon: "<missing>", Reference: "<missing>"}
+			if i < len(a) {
+				d.Erigon = string(a[i].raw)
+			} else {
+				d.Reference = string(b[i].raw)
+			}
+			return d, nil
+		}
+		fields := opFields
+		switch {
+		case a[i].has("pc") || b[i].has("pc"):
+		case a[i].has("stateRoot") || b[i].has("stateRoot"):
+			fields = rootFields
+		default:
+			fields = summaryFields
+		}
+		for _, f := range fields {
+			va, vb := a[i].get(f), b[i].get(f)
+			if va != vb {
+				return &Divergence{Line: i, Field: f, Erigon: va, Reference: vb}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+type traceLine struct {
+	raw    []byte
+	fields map[string]json.RawMessage
+}
+
+func (l traceLine) has(field string) bool {
+	_, ok := l.fields[field]
+	return ok
+}
+
+// get - normalized value of field: quantities (json numbers or hex strings) as decimal, arrays element-wise,
+// bytes as lower-case hex without prefix (leading zeros are significant)
+func (l traceLine) get(field string) string {
+	v, ok := l.fields[field]
+	if !ok {
+		return "<missing>"
+	}
+	if bytesFields[field] {
+		return normalizeBytes(v)
+	}
+	return normalizeQuantity(v)
+}
+
+func normalizeBytes(v json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(v, &s); err != nil {
+		return string(v)
+	}
+	s = strings.ToLower(s)
+	return strings.TrimPrefix(s, "0x")
+}
+
+func normalizeQuantity(v json.RawMessage) string {
+	var arr []json.RawMessage
+	if err := json.Unmarshal(v, &arr); err == nil {
+		norm := make([]string, len(arr))
+		for i := range arr {
+			norm[i] = normalizeQuantity(arr[i])
+		}
+		return "[" + strings.Join(norm, ",") + "]"
+	}
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+			if s == "0x" || s == "0X" {
+				return "0"
+			}
+			if n, ok := new(big.Int).SetString(s[2:], 16); ok {
+				return n.String()
+			}
+		}
+		return strings.ToLower(s)
+	}
+	var n json.Number
+	if err := json.Unmarshal(v, &n); err == nil {
+		return n.String()
+	}
+	return string(v)
+}
+
+func parseTrace(trace []byte) ([]traceLine, error) {
+	var lines []traceLine
+	for _, raw := range bytes.Split(trace, []byte{'\n'}) {
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+		l := traceLine{raw: raw}
+		if err := json.Unmarshal(raw, &l.fields); err != nil {
+			return nil, fmt.Errorf("line %d: %w", len(lines), err)
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
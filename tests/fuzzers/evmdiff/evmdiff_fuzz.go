@@ -0,0 +1,62 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package evmdiff
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+const (
+	// ReferenceEnv - command of reference EVM for Fuzz, for example "evm --json statetest"
+	ReferenceEnv = "EVMDIFF_REFERENCE"
+	// ForkEnv - fork of Fuzz programs, Cancun by default
+	ForkEnv = "EVMDIFF_FORK"
+)
+
+// Fuzz is the basic entry point for the go-fuzz tool: input seeds program generator.
+// Reference is taken from EVMDIFF_REFERENCE env. Divergence panics with minimized program.
+func Fuzz(input []byte) int {
+	reference := strings.Fields(os.Getenv(ReferenceEnv))
+	if len(reference) == 0 || len(input) < 9 {
+		return 0
+	}
+	fork := os.Getenv(ForkEnv)
+	if fork == "" {
+		fork = "Cancun"
+	}
+	r := rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(input)))) //nolint:gosec
+	p := Generate(r, 1+int(input[8])%128)
+
+	h := &Harness{Fork: fork, Erigon: ErigonTracer{}, Reference: ExternalTracer{Cmd: reference}}
+	ctx := context.Background()
+	res, err := h.Check(ctx, p)
+	if err != nil {
+		return 0
+	}
+	if res.Divergence == nil {
+		return 1
+	}
+	if _, minimized, err := h.Minimize(ctx, p, res); err == nil {
+		res = minimized
+	}
+	panic(fmt.Sprintf("divergence: %s\nstate test:\n%s", res.Divergence, res.StateTest))
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package evmdiff
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon/core/vm"
+)
+
+func TestCompare(t *testing.T) {
+	erigon := []byte(`{"pc":0,"op":96,"gas":"0x5f5e100","gasCost":"0x3","stack":[],"depth":1,"refund":0,"opName":"PUSH1"}
+{"pc":2,"op":0,"gas":"0x5f5e0fd","gasCost":"0x0","stack":["0x1"],"depth":1,"refund":0,"opName":"STOP"}
+{"output":"","gasUsed":"0x3"}
+{"stateRoot": "0x000000000000000000000000000000000000000000000000000000000000000a"}
+`)
+	// same trace in other encoding: decimal numbers, other opName, no optional fields, other case of hex
+	reference := []byte(`{"pc":0,"op":96,"gas":100000000,"gasCost":3,"stack":[],"depth":1,"refund":0,"opName":"push1"}
+{"pc":2,"op":0,"gas":99999997,"gasCost":0,"stack":["0x01"],"depth":1,"refund":0}
+{"output":"0x","gasUsed":"0x3"}
+{"stateRoot":"0x000000000000000000000000000000000000000000000000000000000000000A"}
+`)
+	d, err := Compare(erigon, reference)
+	require.NoError(t, err)
+	require.Nil(t, d)
+
+	d, err = Compare(erigon, bytes.Replace(reference, []byte(`"gasCost":3`), []byte(`"gasCost":5`), 1))
+	require.NoError(t, err)
+	require.Equal(t, &Divergence{Line: 0, Field: "gasCost", Erigon: "3", Reference: "5"}, d)
+
+	d, err = Compare(erigon, bytes.Replace(reference, []byte(`["0x01"]`), []byte(`["0x02"]`), 1))
+	require.NoError(t, err)
+	require.Equal(t, 1, d.Line)
+	require.Equal(t, "stack", d.Field)
+
+	// bytes are compared as bytes: leading zeros are significant
+	d, err = Compare(erigon, bytes.Replace(reference, []byte(`"output":"0x"`), []byte(`"output":"0x00"`), 1))
+	require.NoError(t, err)
+	require.Equal(t, &Divergence{Line: 2, Field: "output", Erigon: "", Reference: "00"}, d)
+	d, err = Compare(erigon, bytes.Replace(reference, []byte(`"0x000000000000000000000000000000000000000000000000000000000000000A"`), []byte(`"0x0a"`), 1))
+	require.NoError(t, err)
+	require.Equal(t, 3, d.Line)
+	require.Equal(t, "stateRoot", d.Field)
+
+	lines := bytes.SplitAfter(erigon, []byte{'\n'})
+	d, err = Compare(erigon, bytes.Join(lines[:2], nil))
+	require.NoError(t, err)
+	require.Equal(t, 2, d.Line)
+	require.Equal(t, "<missing>", d.Reference)
+
+	_, err = Compare(erigon, []byte("not json\n"))
+	require.Error(t, err)
+}
+
+// brokenAddTracer - Erigon with wrong gas cost of ADD, stands for reference which diverges
+type brokenAddTracer struct{}
+
+func (brokenAddTracer) Trace(ctx context.Context, testFile string) ([]byte, error) {
+	trace, err := ErigonTracer{}.Trace(ctx, testFile)
+	if err != nil {
+		return nil, err
+	}
+	lines := bytes.Split(trace, []byte{'\n'})
+	for i, line := range lines {
+		if bytes.Contains(line, []byte(`"opName":"ADD"`)) {
+			lines[i] = bytes.Replace(line, []byte(`"gasCost":"0x3"`), []byte(`"gasCost":"0x4"`), 1)
+		}
+	}
+	return bytes.Join(lines, []byte{'\n'}), nil
+}
+
+func TestMinimize(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	h := &Harness{Fork: "Cancun", Erigon: ErigonTracer{}, Reference: brokenAddTracer{}, TmpDir: t.TempDir()}
+	ctx := context.Background()
+
+	// ADD comes first: random instructions before it could halt execution
+	p := Generate(rand.New(rand.NewSource(1)), 32)
+	p.Code = append([]Instruction{{Op: vm.PUSH1, Imm: []byte{1}}, {Op: vm.PUSH1, Imm: []byte{2}}, {Op: vm.ADD}}, p.Code...)
+
+	res, err := h.Check(ctx, p)
+	require.NoError(t, err)
+	require.NotNil(t, res.Divergence)
+	require.Equal(t, "gasCost", res.Divergence.Field)
+
+	minimized, minimizedRes, err := h.Minimize(ctx, p, res)
+	require.NoError(t, err)
+	require.NotNil(t, minimizedRes.Divergence)
+	require.LessOrEqual(t, len(minimized.Code), 3)
+	hasAdd := false
+	for _, ins := range minimized.Code {
+		hasAdd = hasAdd || ins.Op == vm.ADD
+	}
+	require.True(t, hasAdd)
+
+	dir := t.TempDir()
+	require.NoError(t, minimizedRes.Save(dir, "min"))
+	stored := &Harness{Fork: "Cancun", Erigon: brokenAddTracer{}, Reference: StoredTracer{}}
+	replayed, err := stored.CheckFile(ctx, filepath.Join(dir, "min.json"))
+	require.NoError(t, err)
+	require.Nil(t, replayed.Divergence)
+}
+
+func TestStoredTraces(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	h := &Harness{Fork: "Cancun", Erigon: ErigonTracer{}, Reference: StoredTracer{}}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			res, err := h.CheckFile(context.Background(), file)
+			require.NoError(t, err)
+			require.Nil(t, res.Divergence, "%s", res.Divergence)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package evmdiff
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Harness - runs programs on Erigon and on reference and compares their traces
+type Harness struct {
+	Fork      string
+	Erigon    Tracer
+	Reference Tracer
+	TmpDir    string // where state-test files are written, os.TempDir() if empty
+}
+
+// Result - state test and traces of program
+type Result struct {
+	StateTest       []byte
+	ErigonTrace     []byte
+	ReferenceTrace  []byte
+	Divergence      *Divergence
+	ProgramsChecked int // amount of programs run during minimization
+}
+
+// Check - runs program on both sides. Result.Divergence is nil if traces are equal.
+func (h *Harness) Check(ctx context.Context, p *Program) (*Result, error) {
+	test, err := p.StateTest(h.Fork)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(h.TmpDir, "evmdiff-*.json")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(test); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err = f.Close(); err != nil {
+		return nil, err
+	}
+	return h.CheckFile(ctx, f.Name())
+}
+
+// CheckFile - like Check, but for state-test file
+func (h *Harness) CheckFile(ctx context.Context, testFile string) (*Result, error) {
+	test, err := os.ReadFile(testFile)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{StateTest: test, ProgramsChecked: 1}
+	if res.ErigonTrace, err = h.Erigon.Trace(ctx, testFile); err != nil {
+		return nil, fmt.Errorf("erigon: %w", err)
+	}
+	if res.ReferenceTrace, err = h.Reference.Trace(ctx, testFile); err != nil {
+		return nil, fmt.Errorf("reference: %w", err)
+	}
+	if res.Divergence, err = Compare(res.ErigonTrace, res.ReferenceTrace); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Minimize - removes instructions of diverging program while it still diverges (delta debugging over
+// instructions). Reference must be able to run new programs (not StoredTracer).
+func (h *Harness) Minimize(ctx context.Context, p *Program, res *Result) (*Program, *Result, error) {
+	checked := res.ProgramsChecked
+	for n := 2; len(p.Code) >= 2; {
+		chunk := (len(p.Code) + n - 1) / n
+		reduced := false
+		for start := 0; start < len(p.Code); start += chunk {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			candidate := p.Without(start, min(start+chunk, len(p.Code)))
+			candidateRes, err := h.Check(ctx, candidate)
+			checked++
+			if err != nil || candidateRes.Divergence == nil {
+				continue // program became invalid or doesn't diverge anymore
+			}
+			p, res = candidate, candidateRes
+			n, reduced = max(n-1, 2), true
+			break
+		}
+		if !reduced {
+			if n >= len(p.Code) {
+				break
+			}
+			n = min(n*2, len(p.Code))
+		}
+	}
+	res.ProgramsChecked = checked
+	return p, res, nil
+}
+
+// Save - writes state test and both traces into dir as <name>.json, <name>.erigon.jsonl and <name>.jsonl
+// (reference), so the reference can be replayed later by StoredTracer.
+func (r *Result) Save(dir, name string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	testFile := filepath.Join(dir, name+".json")
+	if err := os.WriteFile(testFile, r.StateTest, 0644); err != nil { //nolint:gosec
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".erigon.jsonl"), r.ErigonTrace, 0644); err != nil { //nolint:gosec
+		return err
+	}
+	return os.WriteFile(TraceFile(testFile), r.ReferenceTrace, 0644) //nolint:gosec
+}
@@ -0,0 +1,179 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package evmdiff is a differential EVM fuzzing harness: it generates random programs, wraps them into
+// state tests, runs them on Erigon's EVM and on a reference (stored EIP-3155 traces or an external
+// binary emitting them, like `evm statetest --json`), compares traces and minimizes diverging programs.
+package evmdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/crypto"
+
+	"github.com/erigontech/erigon/core/vm"
+)
+
+const (
+	// TestName - name of the only test of generated state-test file
+	TestName = "evmdiff"
+
+	senderKey = "45a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8"
+	gasPrice  = 10
+)
+
+var (
+	// ContractAddress - address of generated program
+	ContractAddress = libcommon.HexToAddress("0x0000000000000000000000000000000000c0de00")
+	// HelperAddress - account with code which is called/read by generated programs, so they touch more state
+	HelperAddress = libcommon.HexToAddress("0x0000000000000000000000000000000000c0de01")
+	coinbase      = libcommon.HexToAddress("0x2adc25665018aa1fe0e6bc666dac8fc2697ff9ba")
+	helperCode    = []byte{byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0x00, byte(vm.RETURN)}
+)
+
+// Instruction - opcode with immediate bytes (only PUSHn has them)
+type Instruction struct {
+	Op  vm.OpCode
+	Imm []byte
+}
+
+// Program - code of contract called by the only txn of state test
+type Program struct {
+	Code     []Instruction
+	Calldata []byte
+	Gas      uint64
+}
+
+// definedOps - opcodes of latest fork, PUSH1..PUSH32 excluded (they are generated separately)
+var definedOps = func() []vm.OpCode {
+	var ops []vm.OpCode
+	for i := 0; i < 256; i++ {
+		op := vm.OpCode(i)
+		if op.IsPushWithImmediateArgs() || strings.Contains(op.String(), "not defined") {
+			continue
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}()
+
+// Generate - random program of n instructions. Programs are biased to be executable: they start with
+// few pushes (to not fail by stack underflow right away) and small pushes feed offsets/sizes of memory
+// ops and jump destinations.
+func Generate(r *rand.Rand, n int) *Program {
+	p := &Program{Gas: 1_000_000 + uint64(r.Intn(1_000_000))}
+	p.Calldata = make([]byte, r.Intn(65))
+	r.Read(p.Calldata)
+	for i := min(n, 4+r.Intn(13)); i > 0; i-- {
+		p.Code = append(p.Code, Instruction{Op: vm.PUSH1, Imm: []byte{byte(r.Intn(64))}})
+	}
+	for i := len(p.Code); i < n; i++ {
+		switch k := r.Intn(10); {
+		case k < 3: // small values: offsets, sizes, jump destinations
+			p.Code = append(p.Code, Instruction{Op: vm.PUSH1, Imm: []byte{byte(r.Intn(64))}})
+		case k < 4:
+			switch r.Intn(3) {
+			case 0:
+				p.Code = append(p.Code, Instruction{Op: vm.PUSH20, Imm: libcommon.CopyBytes(HelperAddress[:])})
+			case 1:
+				p.Code = append(p.Code, Instruction{Op: vm.PUSH2, Imm: []byte{0xff, 0xff}}) // gas of calls
+			default:
+				p.Code = append(p.Code, Instruction{Op: vm.PUSH32, Imm: bytes.Repeat([]byte{0xff}, 32)})
+			}
+		case k < 5:
+			size := 1 + r.Intn(32)
+			imm := make([]byte, size)
+			r.Read(imm)
+			p.Code = append(p.Code, Instruction{Op: vm.PUSH1 + vm.OpCode(size-1), Imm: imm})
+		default:
+			p.Code = append(p.Code, Instruction{Op: definedOps[r.Intn(len(definedOps))]})
+		}
+	}
+	return p
+}
+
+// Bytecode - code of program
+func (p *Program) Bytecode() []byte {
+	var code []byte
+	for _, ins := range p.Code {
+		code = append(code, byte(ins.Op))
+		code = append(code, ins.Imm...)
+	}
+	return code
+}
+
+// Without - copy of program without instructions [from, to)
+func (p *Program) Without(from, to int) *Program {
+	code := make([]Instruction, 0, len(p.Code)-(to-from))
+	code = append(code, p.Code[:from]...)
+	code = append(code, p.Code[to:]...)
+	return &Program{Code: code, Calldata: p.Calldata, Gas: p.Gas}
+}
+
+// StateTest - program as General State Test of given fork. Expected post-state is not known:
+// test runners must not verify it (traces and state roots are compared instead).
+func (p *Program) StateTest(fork string) ([]byte, error) {
+	key, err := crypto.HexToECDSA(senderKey)
+	if err != nil {
+		return nil, err
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	hexNum := func(n uint64) string { return fmt.Sprintf("0x%x", n) }
+	account := func(code []byte, balance uint64) map[string]any {
+		return map[string]any{"balance": hexNum(balance), "code": hexutil.Bytes(code), "nonce": "0x0", "storage": map[string]string{}}
+	}
+	test := map[string]any{
+		TestName: map[string]any{
+			"env": map[string]any{
+				"currentCoinbase":   coinbase,
+				"currentDifficulty": "0x20000",
+				"currentRandom":     "0x0000000000000000000000000000000000000000000000000000000000020000",
+				"currentGasLimit":   hexNum(30_000_000),
+				"currentNumber":     "0x1",
+				"currentTimestamp":  "0x3e8",
+				"currentBaseFee":    hexNum(gasPrice),
+			},
+			"pre": map[string]any{
+				sender.Hex():          account(nil, 1_000_000_000_000_000_000),
+				ContractAddress.Hex(): account(p.Bytecode(), 0),
+				HelperAddress.Hex():   account(helperCode, 1),
+			},
+			"transaction": map[string]any{
+				"data":      []hexutil.Bytes{p.Calldata},
+				"gasLimit":  []string{hexNum(p.Gas)},
+				"gasPrice":  hexNum(gasPrice),
+				"nonce":     "0x0",
+				"secretKey": "0x" + senderKey,
+				"to":        ContractAddress.Hex(),
+				"value":     []string{"0x0"},
+			},
+			"post": map[string]any{
+				fork: []map[string]any{{
+					"hash":    libcommon.Hash{}.Hex(),
+					"logs":    libcommon.Hash{}.Hex(),
+					"indexes": map[string]int{"data": 0, "gas": 0, "value": 0},
+				}},
+			},
+		},
+	}
+	return json.MarshalIndent(test, "", "  ")
+}
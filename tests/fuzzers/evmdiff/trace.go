@@ -0,0 +1,134 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package evmdiff
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/tracers/logger"
+	"github.com/erigontech/erigon/tests"
+)
+
+// Tracer - executes state-test file and returns its EIP-3155 trace: json line per opcode, summary line
+// (output, gasUsed) and stateRoot line - as `evm statetest --json` writes it to stderr.
+type Tracer interface {
+	Trace(ctx context.Context, testFile string) ([]byte, error)
+}
+
+// ErigonTracer - runs state test by Erigon's core/vm in-process
+type ErigonTracer struct{}
+
+func (ErigonTracer) Trace(ctx context.Context, testFile string) ([]byte, error) {
+	src, err := os.ReadFile(testFile)
+	if err != nil {
+		return nil, err
+	}
+	var stateTests map[string]tests.StateTest
+	if err = json.Unmarshal(src, &stateTests); err != nil {
+		return nil, err
+	}
+	test, ok := stateTests[TestName]
+	if !ok {
+		return nil, fmt.Errorf("test %q not found in %s", TestName, testFile)
+	}
+	subtests := test.Subtests()
+	if len(subtests) != 1 {
+		return nil, fmt.Errorf("expected 1 subtest, got %d", len(subtests))
+	}
+
+	tmpDir, err := os.MkdirTemp("", "evmdiff-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	dirs := datadir.New(tmpDir)
+	db, agg := temporaltest.NewTestDB(nil, dirs)
+	defer agg.Close()
+	defer db.Close()
+	tx, err := db.BeginRw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var trace bytes.Buffer
+	cfg := vm.Config{Tracer: logger.NewJSONLogger(&logger.LogConfig{DisableMemory: true, DisableReturnData: true}, &trace).Tracer().Hooks}
+	_, root, err := test.RunNoVerifyTraced(tx, subtests[0], cfg, dirs)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&trace, "{\"stateRoot\": \"%#x\"}\n", root.Bytes())
+	return trace.Bytes(), nil
+}
+
+// ExternalTracer - runs external binary with state-test file as last argument and takes trace from its
+// stderr (non-json lines are skipped). Example: Cmd = []string{"evm", "--json", "statetest"}.
+type ExternalTracer struct {
+	Cmd []string
+}
+
+func (t ExternalTracer) Trace(ctx context.Context, testFile string) ([]byte, error) {
+	if len(t.Cmd) == 0 {
+		return nil, errors.New("external tracer: empty command")
+	}
+	args := append(append([]string{}, t.Cmd[1:]...), testFile)
+	cmd := exec.CommandContext(ctx, t.Cmd[0], args...) //nolint:gosec
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Stdout = nil
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) { // reference may exit with non-zero code because post-state is not verified
+			return nil, fmt.Errorf("external tracer: %w", err)
+		}
+	}
+	var trace bytes.Buffer
+	scanner := bufio.NewScanner(&stderr)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) > 0 && line[0] == '{' {
+			trace.Write(line)
+			trace.WriteByte('\n')
+		}
+	}
+	return trace.Bytes(), scanner.Err()
+}
+
+// StoredTracer - reference traces recorded before: trace of `<name>.json` is in `<name>.jsonl`
+type StoredTracer struct{}
+
+func (StoredTracer) Trace(_ context.Context, testFile string) ([]byte, error) {
+	return os.ReadFile(TraceFile(testFile))
+}
+
+// TraceFile - file of stored trace of state-test file
+func TraceFile(testFile string) string {
+	return strings.TrimSuffix(testFile, ".json") + ".jsonl"
+}
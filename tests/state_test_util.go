@@ -69,6 +69,10 @@ func (t *StateTest) UnmarshalJSON(in []byte) error {
 	return json.Unmarshal(in, &t.json)
 }
 
+func (t *StateTest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&t.json)
+}
+
 type stJSON struct {
 	Env  stEnv                    `json:"env"`
 	Pre  types.GenesisAlloc       `json:"pre"`
@@ -185,6 +189,16 @@ func (t *StateTest) Run(tx kv.RwTx, subtest StateSubtest, vmconfig vm.Config, di
 
 // RunNoVerify runs a specific subtest and returns the statedb and post-state root
 func (t *StateTest) RunNoVerify(tx kv.RwTx, subtest StateSubtest, vmconfig vm.Config, dirs datadir.Dirs) (*state.IntraBlockState, libcommon.Hash, error) {
+	return t.runNoVerify(tx, subtest, vmconfig, dirs, false)
+}
+
+// RunNoVerifyTraced - like RunNoVerify, but starts the transaction in the tracer (OnTxStart) as block execution
+// does, for tracers which read the transaction environment (e.g. refund counter of EIP-3155 traces)
+func (t *StateTest) RunNoVerifyTraced(tx kv.RwTx, subtest StateSubtest, vmconfig vm.Config, dirs datadir.Dirs) (*state.IntraBlockState, libcommon.Hash, error) {
+	return t.runNoVerify(tx, subtest, vmconfig, dirs, true)
+}
+
+func (t *StateTest) runNoVerify(tx kv.RwTx, subtest StateSubtest, vmconfig vm.Config, dirs datadir.Dirs, txStart bool) (*state.IntraBlockState, libcommon.Hash, error) {
 	config, eips, err := GetChainConfig(subtest.Fork)
 	if err != nil {
 		return nil, libcommon.Hash{}, UnsupportedForkError{subtest.Fork}
@@ -264,6 +278,9 @@ func (t *StateTest) RunNoVerify(tx kv.RwTx, subtest StateSubtest, vmconfig vm.Co
 		}
 	}
 	evm := vm.NewEVM(context, txContext, statedb, config, vmconfig)
+	if txStart && vmconfig.Tracer != nil && vmconfig.Tracer.OnTxStart != nil {
+		vmconfig.Tracer.OnTxStart(evm.GetVMContext(), nil, msg.From())
+	}
 
 	// Execute the message.
 	snapshot := statedb.Snapshot()
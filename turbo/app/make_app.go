@@ -82,6 +82,8 @@ func MakeApp(name string, action cli.ActionFunc, cliFlags []cli.Flag) *cli.App {
 		&importCommand,
 		&snapshotCommand,
 		&supportCommand,
+		&scrubCommand,
+		&supportBundleCommand,
 		//&backupCommand,
 	}
 	return app
@@ -0,0 +1,163 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/erigontech/erigon-lib/chain/snapcfg"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/temporal"
+	"github.com/erigontech/erigon/cmd/hack/tool/fromdb"
+	"github.com/erigontech/erigon/cmd/utils"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/eth/ethconsensusconfig"
+	"github.com/erigontech/erigon/eth/integrity"
+	"github.com/erigontech/erigon/turbo/debug"
+)
+
+var (
+	scrubChecksFlag = cli.StringFlag{
+		Name:  "checks",
+		Usage: fmt.Sprintf("Comma separated list of checks to run, default all of: %s", integrity.AllScrubChecks),
+	}
+	scrubReportFlag = cli.StringFlag{
+		Name:  "report",
+		Usage: "Path of JSON report file. Report is printed to stdout if empty",
+	}
+	scrubKeysSampleFlag = cli.Uint64Flag{
+		Name:  "keys.sample",
+		Usage: "Check every N-th key of Domain files against their accessors (1 - check all keys)",
+		Value: 64,
+	}
+	scrubBlocksSampleFlag = cli.IntFlag{
+		Name:  "blocks.sample",
+		Usage: "Amount of random blocks to re-execute for receipts root check",
+		Value: 100,
+	}
+	scrubWorkersFlag = cli.IntFlag{
+		Name:  "workers",
+		Usage: "Amount of files hashed in parallel (0 - amount of CPUs)",
+	}
+)
+
+var scrubCommand = cli.Command{
+	Action: doScrub,
+	Name:   "scrub",
+	Usage:  "Verify datadir files (checksums, accessors, TxNums, receipts) and report which files to delete and re-download",
+	Flags: joinFlags([]cli.Flag{
+		&utils.DataDirFlag,
+		&scrubChecksFlag,
+		&scrubReportFlag,
+		&scrubKeysSampleFlag,
+		&scrubBlocksSampleFlag,
+		&scrubWorkersFlag,
+	}),
+	Description: `Reads whole datadir and writes JSON report. Files listed in "delete" must be removed while Erigon is stopped:
+accessors are re-built on next start, files listed in "redownload" are fetched again by downloader.
+Exit code is non-zero if any problem was found.`,
+}
+
+func doScrub(cliCtx *cli.Context) error {
+	logger, _, _, _, err := debug.Setup(cliCtx, true /* rootLogger */)
+	if err != nil {
+		return err
+	}
+	ctx := cliCtx.Context
+
+	checks := integrity.AllScrubChecks
+	if s := cliCtx.String(scrubChecksFlag.Name); s != "" {
+		checks = nil
+		for _, chk := range strings.Split(s, ",") {
+			chk := integrity.ScrubCheck(strings.TrimSpace(chk))
+			if !slices.Contains(integrity.AllScrubChecks, chk) {
+				return fmt.Errorf("unknown check: %s, expected one of: %s", chk, integrity.AllScrubChecks)
+			}
+			checks = append(checks, chk)
+		}
+	}
+
+	dirs, l, err := datadir.New(cliCtx.String(utils.DataDirFlag.Name)).MustFlock()
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+	chainDB := dbCfg(kv.ChainDB, dirs.Chaindata).MustOpen()
+	defer chainDB.Close()
+
+	chainConfig := fromdb.ChainConfig(chainDB)
+	cfg := ethconfig.NewSnapCfg(false, true, true, chainConfig.ChainName)
+	_, _, _, blockRetire, agg, clean, err := openSnaps(ctx, cfg, dirs, 0, chainDB, logger)
+	if err != nil {
+		return err
+	}
+	defer clean()
+
+	db, err := temporal.New(chainDB, agg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	blockReader, _ := blockRetire.IO()
+
+	report := &integrity.ScrubReport{Checks: checks}
+	for _, chk := range checks {
+		logger.Info("[scrub] start", "check", chk)
+		switch chk {
+		case integrity.Checksums:
+			preverified := snapcfg.KnownCfg(chainConfig.ChainName).Preverified
+			err = integrity.ScrubChecksums(ctx, dirs, preverified, cliCtx.Int(scrubWorkersFlag.Name), report)
+		case integrity.DomainAccessors:
+			err = integrity.ScrubAccessors(ctx, db, dirs, cliCtx.Uint64(scrubKeysSampleFlag.Name), report)
+		case integrity.TxNumsContinuity:
+			err = integrity.ScrubTxNums(ctx, db, blockReader, report)
+		case integrity.ReceiptsRoot:
+			engine := ethconsensusconfig.CreateConsensusEngineBareBones(ctx, chainConfig, logger)
+			err = integrity.ScrubReceipts(ctx, db, blockReader, chainConfig, engine, cliCtx.Int(scrubBlocksSampleFlag.Name), report)
+		}
+		if err != nil {
+			return fmt.Errorf("scrub %s: %w", chk, err)
+		}
+	}
+	report.Finish()
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if reportPath := cliCtx.String(scrubReportFlag.Name); reportPath != "" {
+		if err := os.WriteFile(reportPath, out, 0644); err != nil {
+			return err
+		}
+		logger.Info("[scrub] report written", "path", reportPath)
+	} else {
+		fmt.Println(string(out))
+	}
+
+	if !report.OK() {
+		return fmt.Errorf("scrub found %d problems, %d files to delete, %d to re-download", len(report.Problems), len(report.Delete), len(report.Redownload))
+	}
+	logger.Info("[scrub] no problems found", "files", report.FilesChecked, "unverifiable", len(report.Unverifiable))
+	return nil
+}
@@ -0,0 +1,277 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/urfave/cli/v2"
+
+	"github.com/erigontech/erigon-lib/common/datadir"
+	diaglib "github.com/erigontech/erigon-lib/diagnostics"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/mdbx"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/utils"
+	"github.com/erigontech/erigon/diagnostics/bundle"
+	"github.com/erigontech/erigon/eth/stagedsync/stages"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/debug"
+	"github.com/erigontech/erigon/turbo/logging"
+)
+
+var (
+	supportBundleOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "Path of the bundle file, erigon-support-<time>.tar.gz in the current directory if empty",
+	}
+	supportBundleWindowFlag = cli.DurationFlag{
+		Name:  "window",
+		Usage: "How long to sample the node diagnostics and metrics, and to record the CPU profile",
+		Value: time.Minute,
+	}
+	supportBundleIntervalFlag = cli.DurationFlag{
+		Name:  "interval",
+		Usage: "Time between the diagnostics and metrics samples",
+		Value: 10 * time.Second,
+	}
+	supportBundleLogsSinceFlag = cli.DurationFlag{
+		Name:  "logs.since",
+		Usage: "Collect log files modified within this time",
+		Value: 24 * time.Hour,
+	}
+	supportBundleLogsLimitFlag = cli.StringFlag{
+		Name:  "logs.limit",
+		Usage: "Max size of a single collected log file, the older lines are cut",
+		Value: "64mb",
+	}
+	supportBundleNoProfileFlag = cli.BoolFlag{
+		Name:  "no-cpu-profile",
+		Usage: "Don't record the CPU profile (it needs the node running with --pprof)",
+	}
+	supportBundleEntryFlag = cli.StringFlag{
+		Name:  "entry",
+		Usage: "Print the content of the bundle entry instead of the summary",
+	}
+	supportBundleExtractFlag = cli.StringFlag{
+		Name:  "extract",
+		Usage: "Extract all the bundle entries into the directory",
+	}
+)
+
+var supportBundleCommand = cli.Command{
+	Action: doSupportBundle,
+	Name:   "support-bundle",
+	Usage:  "Collect node diagnostics, logs, profiles and metrics into a single file for support",
+	Flags: joinFlags([]cli.Flag{
+		&utils.DataDirFlag,
+		&supportBundleOutputFlag,
+		&supportBundleWindowFlag,
+		&supportBundleIntervalFlag,
+		&supportBundleLogsSinceFlag,
+		&supportBundleLogsLimitFlag,
+		&supportBundleNoProfileFlag,
+	}),
+	Description: `Samples the diagnostics endpoint (--diagnostics.endpoint.addr/port) and the metrics (--metrics.addr/port)
+of the running node during --window, records its CPU (--pprof.addr/port) and heap profiles, and adds recent logs,
+flags, stage progress and the datadir file listing. Secrets (node key, JWT secret, passwords, credentials in URLs)
+are redacted. Sources which are not available, e.g. when the node is stopped, are listed in the bundle manifest.
+Use "erigon support-bundle view <file>" to read the bundle.`,
+	Subcommands: []*cli.Command{
+		{
+			Action:    doSupportBundleView,
+			Name:      "view",
+			Usage:     "Print the summary or the entries of a support bundle",
+			ArgsUsage: "<bundle file>",
+			Flags: []cli.Flag{
+				&supportBundleEntryFlag,
+				&supportBundleExtractFlag,
+			},
+		},
+	},
+}
+
+func doSupportBundle(cliCtx *cli.Context) error {
+	logger, _, _, _, err := debug.Setup(cliCtx, true /* rootLogger */)
+	if err != nil {
+		return err
+	}
+	ctx := cliCtx.Context
+
+	var logsLimit datasize.ByteSize
+	if err := logsLimit.UnmarshalText([]byte(cliCtx.String(supportBundleLogsLimitFlag.Name))); err != nil {
+		return fmt.Errorf("invalid %s: %w", supportBundleLogsLimitFlag.Name, err)
+	}
+
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	logDir := cliCtx.String(logging.LogDirPathFlag.Name)
+	if logDir == "" {
+		logDir = filepath.Join(dirs.DataDir, "logs")
+	}
+
+	cfg := bundle.Config{
+		DiagnosticsURL: fmt.Sprintf("http://%s:%d/debug/diag", cliCtx.String(utils.DiagEndpointAddrFlag.Name), cliCtx.Uint(utils.DiagEndpointPortFlag.Name)),
+		MetricsURL:     fmt.Sprintf("http://%s:%d/debug/metrics/prometheus", cliCtx.String(utils.MetricsHTTPFlag.Name), cliCtx.Int(utils.MetricsPortFlag.Name)),
+		PprofURL:       fmt.Sprintf("http://%s:%d/debug/pprof", cliCtx.String("pprof.addr"), cliCtx.Int("pprof.port")),
+		DataDir:        dirs.DataDir,
+		LogDir:         logDir,
+		Window:         cliCtx.Duration(supportBundleWindowFlag.Name),
+		Interval:       cliCtx.Duration(supportBundleIntervalFlag.Name),
+		LogsSince:      cliCtx.Duration(supportBundleLogsSinceFlag.Name),
+		LogsLimit:      int(logsLimit.Bytes()),
+		ErigonVersion:  params.VersionWithCommit(params.GitCommit),
+		Sources: []bundle.Source{
+			{Name: bundle.StageProgressName, Collect: func(ctx context.Context) ([]byte, error) {
+				return readStageProgress(ctx, dirs, logger)
+			}},
+			{Name: "diagnostics/saved.json", Collect: func(ctx context.Context) ([]byte, error) {
+				return readSavedDiagnostics(ctx, dirs, logger)
+			}},
+		},
+	}
+	if cliCtx.Bool(supportBundleNoProfileFlag.Name) {
+		cfg.PprofURL = ""
+	}
+
+	outPath := cliCtx.String(supportBundleOutputFlag.Name)
+	if outPath == "" {
+		outPath = fmt.Sprintf("erigon-support-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	}
+	out, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	logger.Info("[support-bundle] collecting", "node", cfg.DiagnosticsURL, "window", cfg.Window, "output", outPath)
+	manifest, err := bundle.Collect(ctx, cfg, out, logger)
+	if err != nil {
+		_ = os.Remove(outPath)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	failed := manifest.Failed()
+	for _, e := range failed {
+		logger.Warn("[support-bundle] not collected", "entry", e.Name, "err", e.Error)
+	}
+	logger.Info("[support-bundle] done", "output", outPath, "entries", len(manifest.Entries)-len(failed), "failed", len(failed), "redactions", manifest.Redactions)
+	return nil
+}
+
+func doSupportBundleView(cliCtx *cli.Context) error {
+	if cliCtx.NArg() != 1 {
+		return errors.New("expected the bundle file as the only argument")
+	}
+	f, err := os.Open(cliCtx.Args().First())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	b, err := bundle.Open(f)
+	if err != nil {
+		return err
+	}
+
+	if entry := cliCtx.String(supportBundleEntryFlag.Name); entry != "" {
+		data, ok := b.File(entry)
+		if !ok {
+			return fmt.Errorf("no entry %s in the bundle, see the summary for the content", entry)
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	if dir := cliCtx.String(supportBundleExtractFlag.Name); dir != "" {
+		for _, name := range b.Names() {
+			data, _ := b.File(name)
+			target := filepath.Join(dir, filepath.FromSlash(name))
+			if !filepath.IsLocal(filepath.FromSlash(name)) {
+				return fmt.Errorf("unsafe entry name %s", name)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(target, data, 0644); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Extracted %d entries into %s\n", len(b.Names()), dir)
+		return nil
+	}
+
+	return b.Summary(os.Stdout)
+}
+
+// readStageProgress reads the stages progress from chaindata, it works while the node is running.
+func readStageProgress(ctx context.Context, dirs datadir.Dirs, logger log.Logger) ([]byte, error) {
+	db, err := mdbx.New(kv.ChainDB, logger).Path(dirs.Chaindata).Accede(true).Readonly(true).Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var progress []bundle.StageProgress
+	if err := db.View(ctx, func(tx kv.Tx) error {
+		for _, stage := range stages.AllStages {
+			p, err := stages.GetStageProgress(tx, stage)
+			if err != nil {
+				return err
+			}
+			pp, err := stages.GetStagePruneProgress(tx, stage)
+			if err != nil {
+				return err
+			}
+			progress = append(progress, bundle.StageProgress{Stage: string(stage), Progress: p, PruneProgress: pp})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(progress, "", "  ")
+}
+
+// readSavedDiagnostics reads the stats persisted by the node diagnostics, they survive the node restarts.
+func readSavedDiagnostics(ctx context.Context, dirs datadir.Dirs, logger log.Logger) ([]byte, error) {
+	db, err := mdbx.New(kv.DiagnosticsDB, logger).
+		WithTableCfg(func(defaultBuckets kv.TableCfg) kv.TableCfg { return kv.DiagnosticsTablesCfg }).
+		Path(filepath.Join(dirs.DataDir, "diagnostics")).
+		Accede(true).
+		Readonly(true).
+		Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	hardware, syncStages, snapshotDownload, snapshotIndexing, snapshotFillDB := diaglib.ReadSavedData(db)
+	return json.MarshalIndent(map[string]interface{}{
+		"hardwareInfo":     hardware,
+		"syncStages":       syncStages,
+		"snapshotDownload": snapshotDownload,
+		"snapshotIndexing": snapshotIndexing,
+		"snapshotFillDB":   snapshotFillDB,
+	}, "", "  ")
+}
@@ -27,7 +27,7 @@ import (
 	"github.com/erigontech/erigon/core/types"
 )
 
-type BlockBuilderFunc func(param *core.BlockBuilderParameters, interrupt *int32) (*types.BlockWithReceipts, error)
+type BlockBuilderFunc func(param *core.BlockBuilderParameters, interrupt *int32, report *PayloadReport) (*types.BlockWithReceipts, error)
 
 // BlockBuilder wraps a goroutine that builds Proof-of-Stake payloads (PoS "mining")
 type BlockBuilder struct {
@@ -35,16 +35,19 @@ type BlockBuilder struct {
 	syncCond  *sync.Cond
 	result    *types.BlockWithReceipts
 	err       error
+	report    *PayloadReport
 }
 
 func NewBlockBuilder(build BlockBuilderFunc, param *core.BlockBuilderParameters) *BlockBuilder {
 	builder := new(BlockBuilder)
 	builder.syncCond = sync.NewCond(new(sync.Mutex))
+	builder.report = NewPayloadReport(param.PayloadId)
 
 	go func() {
 		log.Info("Building block...")
 		t := time.Now()
-		result, err := build(param, &builder.interrupt)
+		result, err := build(param, &builder.interrupt, builder.report)
+		builder.report.Finish(result, err)
 		if err != nil {
 			log.Warn("Failed to build a block", "err", err)
 		} else {
@@ -83,3 +86,8 @@ func (b *BlockBuilder) Block() *types.Block {
 	}
 	return b.result.Block
 }
+
+// Report - choices made while building the block, available while building is in progress
+func (b *BlockBuilder) Report() *PayloadReport {
+	return b.report
+}
@@ -0,0 +1,282 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package builder
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+)
+
+// SkipReason - why a candidate transaction didn't make it into the payload
+type SkipReason string
+
+const (
+	SkipBadChainId      SkipReason = "bad-chain-id"
+	SkipNoSender        SkipReason = "no-sender"
+	SkipNoAccount       SkipReason = "no-account"
+	SkipNonceTooLow     SkipReason = "nonce-too-low"
+	SkipNonceGap        SkipReason = "nonce-gap"
+	SkipNotEOA          SkipReason = "sender-not-eoa"
+	SkipFeeTooLow       SkipReason = "fee-too-low"
+	SkipBalanceTooLow   SkipReason = "balance-too-low"
+	SkipOverflow        SkipReason = "overflow"
+	SkipReplayProtected SkipReason = "replay-protected"
+	SkipGasLimit        SkipReason = "gas-limit"
+	SkipBlobLimit       SkipReason = "blob-limit"
+	SkipInvalid         SkipReason = "invalid"
+	SkipInterrupted     SkipReason = "interrupted"
+)
+
+// SkipReasonOf - classifies an error of core.ApplyTransaction
+func SkipReasonOf(err error) SkipReason {
+	switch {
+	case errors.Is(err, core.ErrGasLimitReached):
+		return SkipGasLimit
+	case errors.Is(err, core.ErrBlobGasLimitReached):
+		return SkipBlobLimit
+	case errors.Is(err, core.ErrNonceTooLow):
+		return SkipNonceTooLow
+	case errors.Is(err, core.ErrNonceTooHigh):
+		return SkipNonceGap
+	default:
+		return SkipInvalid
+	}
+}
+
+// PayloadReport collects the choices made while building a payload: every candidate transaction
+// with the reason it was skipped, fees and value of the included ones, and time spent in each phase.
+// Methods are safe to call on nil report, so building without a report needs no checks.
+type PayloadReport struct {
+	lock sync.Mutex
+
+	payloadId  uint64
+	start      time.Time
+	took       time.Duration
+	done       bool
+	err        error
+	header     *types.Header
+	phases     []PhaseResult
+	candidates []*CandidateResult
+	txns       map[libcommon.Hash]types.Transaction
+	fees       uint256.Int
+	value      uint256.Int
+}
+
+func NewPayloadReport(payloadId uint64) *PayloadReport {
+	return &PayloadReport{
+		payloadId: payloadId,
+		start:     time.Now(),
+		txns:      map[libcommon.Hash]types.Transaction{},
+	}
+}
+
+// Phase - adds time since start to the phase, phases entered several times are summed up
+func (r *PayloadReport) Phase(name string, start time.Time) {
+	if r == nil {
+		return
+	}
+	took := time.Since(start)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i := range r.phases {
+		if r.phases[i].Name == name {
+			r.phases[i].DurationNs += hexutil.Uint64(took)
+			return
+		}
+	}
+	r.phases = append(r.phases, PhaseResult{Name: name, DurationNs: hexutil.Uint64(took)})
+}
+
+// Skip - records the candidate which wasn't included
+func (r *PayloadReport) Skip(txn types.Transaction, sender libcommon.Address, reason SkipReason, err error) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.txns[txn.Hash()] = txn
+	r.candidates = append(r.candidates, skippedCandidate(txn, sender, reason, err))
+}
+
+// Include - records the candidate added to the payload, fees are the priority fees paid to coinbase
+func (r *PayloadReport) Include(txn types.Transaction, sender libcommon.Address, receipt *types.Receipt, baseFee *big.Int) {
+	if r == nil {
+		return
+	}
+	candidate, fee := includedCandidate(txn, sender, receipt, baseFee)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.txns[txn.Hash()] = txn
+	r.candidates = append(r.candidates, candidate)
+	r.fees.Add(&r.fees, fee)
+}
+
+// AddValue - records the coinbase balance increase caused by transactions, includes direct transfers
+func (r *PayloadReport) AddValue(value *uint256.Int) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.value.Add(&r.value, value)
+}
+
+// Finish - records the outcome of building
+func (r *PayloadReport) Finish(result *types.BlockWithReceipts, err error) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.done = true
+	r.took = time.Since(r.start)
+	r.err = err
+	if result != nil {
+		r.header = result.Block.Header()
+	}
+}
+
+// Header - header of the built payload, nil while building or if building failed
+func (r *PayloadReport) Header() *types.Header {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.header
+}
+
+// Transaction - candidate transaction by hash, nil if it wasn't considered
+func (r *PayloadReport) Transaction(hash libcommon.Hash) types.Transaction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.txns[hash]
+}
+
+// Result - snapshot of the report, may be taken while building is in progress
+func (r *PayloadReport) Result() *PayloadReportResult {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res := &PayloadReportResult{
+		PayloadId:  make(hexutil.Bytes, 8),
+		Done:       r.done,
+		Phases:     append([]PhaseResult{}, r.phases...),
+		Candidates: append([]*CandidateResult{}, r.candidates...),
+		Fees:       (*hexutil.Big)(r.fees.ToBig()),
+		Value:      (*hexutil.Big)(r.value.ToBig()),
+	}
+	binary.BigEndian.PutUint64(res.PayloadId, r.payloadId)
+	if r.done {
+		res.DurationNs = hexutil.Uint64(r.took)
+	} else {
+		res.DurationNs = hexutil.Uint64(time.Since(r.start))
+	}
+	if r.err != nil {
+		res.Error = r.err.Error()
+	}
+	if h := r.header; h != nil {
+		hash := h.Hash()
+		res.BlockHash = &hash
+		res.BlockNumber = (*hexutil.Big)(h.Number)
+		res.GasLimit = hexutil.Uint64(h.GasLimit)
+		res.GasUsed = hexutil.Uint64(h.GasUsed)
+		if h.BlobGasUsed != nil {
+			res.BlobGasUsed = (*hexutil.Uint64)(h.BlobGasUsed)
+		}
+	}
+	return res
+}
+
+func skippedCandidate(txn types.Transaction, sender libcommon.Address, reason SkipReason, err error) *CandidateResult {
+	c := &CandidateResult{
+		Hash:     txn.Hash(),
+		From:     sender,
+		Type:     hexutil.Uint64(txn.Type()),
+		Nonce:    hexutil.Uint64(txn.GetNonce()),
+		Gas:      hexutil.Uint64(txn.GetGasLimit()),
+		Skipped:  reason,
+		BlobGas:  hexutil.Uint64(txn.GetBlobGas()),
+		Included: false,
+	}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	return c
+}
+
+func includedCandidate(txn types.Transaction, sender libcommon.Address, receipt *types.Receipt, baseFee *big.Int) (*CandidateResult, *uint256.Int) {
+	c := skippedCandidate(txn, sender, "", nil)
+	c.Included = true
+	c.GasUsed = hexutil.Uint64(receipt.GasUsed)
+	c.Reverted = receipt.Status == types.ReceiptStatusFailed
+
+	tip := txn.GetTipCap()
+	if baseFee != nil {
+		tip = txn.GetEffectiveGasTip(uint256.MustFromBig(baseFee))
+	}
+	fee := new(uint256.Int).Mul(uint256.NewInt(receipt.GasUsed), tip)
+	c.EffectiveTip = (*hexutil.Big)(tip.ToBig())
+	c.Fee = (*hexutil.Big)(fee.ToBig())
+	return c, fee
+}
+
+// PayloadReportResult - json representation of PayloadReport returned by erigon_getPayloadReport
+type PayloadReportResult struct {
+	PayloadId   hexutil.Bytes      `json:"payloadId"`
+	Done        bool               `json:"done"`
+	Error       string             `json:"error,omitempty"`
+	BlockHash   *libcommon.Hash    `json:"blockHash,omitempty"`
+	BlockNumber *hexutil.Big       `json:"blockNumber,omitempty"`
+	GasLimit    hexutil.Uint64     `json:"gasLimit"`
+	GasUsed     hexutil.Uint64     `json:"gasUsed"`
+	BlobGasUsed *hexutil.Uint64    `json:"blobGasUsed,omitempty"`
+	Fees        *hexutil.Big       `json:"fees"`
+	Value       *hexutil.Big       `json:"value"`
+	DurationNs  hexutil.Uint64     `json:"durationNs"`
+	Phases      []PhaseResult      `json:"phases"`
+	Candidates  []*CandidateResult `json:"candidates"`
+	Simulation  *SimulationResult  `json:"simulation,omitempty"`
+}
+
+type PhaseResult struct {
+	Name       string         `json:"name"`
+	DurationNs hexutil.Uint64 `json:"durationNs"`
+}
+
+type CandidateResult struct {
+	Hash         libcommon.Hash    `json:"hash"`
+	From         libcommon.Address `json:"from"`
+	Type         hexutil.Uint64    `json:"type"`
+	Nonce        hexutil.Uint64    `json:"nonce"`
+	Gas          hexutil.Uint64    `json:"gas"`
+	BlobGas      hexutil.Uint64    `json:"blobGas"`
+	Included     bool              `json:"included"`
+	Skipped      SkipReason        `json:"skipped,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	GasUsed      hexutil.Uint64    `json:"gasUsed,omitempty"`
+	Reverted     bool              `json:"reverted,omitempty"`
+	EffectiveTip *hexutil.Big      `json:"effectiveTip,omitempty"`
+	Fee          *hexutil.Big      `json:"fee,omitempty"`
+}
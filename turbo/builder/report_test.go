@@ -0,0 +1,89 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+)
+
+func TestPayloadReport(t *testing.T) {
+	t.Parallel()
+	const gwei = 1_000_000_000
+	r := NewPayloadReport(0x0102)
+	sender := libcommon.Address{1}
+
+	included := types.NewTransaction(0, libcommon.Address{2}, uint256.NewInt(0), 21000, uint256.NewInt(3*gwei), nil)
+	r.Include(included, sender, &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 21000}, big.NewInt(gwei))
+	skipped := types.NewTransaction(2, libcommon.Address{2}, uint256.NewInt(0), 21000, uint256.NewInt(3*gwei), nil)
+	r.Skip(skipped, sender, SkipNonceGap, nil)
+	r.AddValue(uint256.NewInt(42000 * gwei))
+
+	start := time.Now().Add(-time.Millisecond)
+	r.Phase("execute", start)
+	r.Phase("execute", start)
+	r.Phase("finalize", start)
+
+	res := r.Result()
+	require.False(t, res.Done)
+	require.Equal(t, hexutil.Bytes{0, 0, 0, 0, 0, 0, 1, 2}, res.PayloadId)
+	require.Len(t, res.Phases, 2)
+	require.Equal(t, "execute", res.Phases[0].Name)
+	require.GreaterOrEqual(t, uint64(res.Phases[0].DurationNs), uint64(2*time.Millisecond))
+
+	require.Len(t, res.Candidates, 2)
+	require.True(t, res.Candidates[0].Included)
+	require.Equal(t, big.NewInt(2*gwei), res.Candidates[0].EffectiveTip.ToInt())
+	require.Equal(t, big.NewInt(42000*gwei), res.Fees.ToInt())
+	require.Equal(t, big.NewInt(42000*gwei), res.Value.ToInt())
+	require.Equal(t, SkipNonceGap, res.Candidates[1].Skipped)
+	require.Equal(t, skipped, r.Transaction(skipped.Hash()))
+	require.Nil(t, r.Transaction(libcommon.Hash{1}))
+
+	header := &types.Header{Number: big.NewInt(7), GasLimit: 30_000_000, GasUsed: 21000}
+	r.Finish(&types.BlockWithReceipts{Block: types.NewBlockWithHeader(header)}, nil)
+	res = r.Result()
+	require.True(t, res.Done)
+	require.Equal(t, header.Hash(), *res.BlockHash)
+	require.Equal(t, hexutil.Uint64(21000), res.GasUsed)
+
+	// building without a report
+	var nilReport *PayloadReport
+	nilReport.Phase("execute", start)
+	nilReport.Skip(skipped, sender, SkipGasLimit, nil)
+	nilReport.Finish(nil, errors.New("failed"))
+}
+
+func TestSkipReasonOf(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, SkipGasLimit, SkipReasonOf(core.ErrGasLimitReached))
+	require.Equal(t, SkipBlobLimit, SkipReasonOf(fmt.Errorf("%w: have 0", core.ErrBlobGasLimitReached)))
+	require.Equal(t, SkipNonceGap, SkipReasonOf(core.ErrNonceTooHigh))
+	require.Equal(t, SkipInvalid, SkipReasonOf(core.ErrInsufficientFunds))
+}
@@ -0,0 +1,195 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/holiman/uint256"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/rawdb"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/consensuschain"
+	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+var ErrPayloadNotBuilt = errors.New("payload is not built yet")
+
+// SimulationResult - outcome of executing candidates of a payload in an alternative order,
+// deltas are relative to the built payload (positive if the alternative is better)
+type SimulationResult struct {
+	Ordering    []libcommon.Hash   `json:"ordering"`
+	GasUsed     hexutil.Uint64     `json:"gasUsed"`
+	BlobGasUsed hexutil.Uint64     `json:"blobGasUsed"`
+	Fees        *hexutil.Big       `json:"fees"`
+	Value       *hexutil.Big       `json:"value"`
+	FeesDelta   *hexutil.Big       `json:"feesDelta"`
+	ValueDelta  *hexutil.Big       `json:"valueDelta"`
+	Candidates  []*CandidateResult `json:"candidates"`
+}
+
+// Simulator executes candidates of a built payload in an alternative order on top of its parent state
+type Simulator struct {
+	config      *chain.Config
+	db          kv.TemporalRoDB
+	engine      consensus.Engine
+	blockReader services.FullBlockReader
+	logger      log.Logger
+}
+
+func NewSimulator(config *chain.Config, db kv.TemporalRoDB, engine consensus.Engine, blockReader services.FullBlockReader, logger log.Logger) *Simulator {
+	return &Simulator{config: config, db: db, engine: engine, blockReader: blockReader, logger: logger}
+}
+
+func (s *Simulator) Simulate(ctx context.Context, report *PayloadReport, ordering []libcommon.Hash) (*SimulationResult, error) {
+	built := report.Header()
+	if built == nil {
+		return nil, ErrPayloadNotBuilt
+	}
+	txns := make([]types.Transaction, len(ordering))
+	for i, hash := range ordering {
+		if txns[i] = report.Transaction(hash); txns[i] == nil {
+			return nil, fmt.Errorf("transaction %x is not a candidate of the payload", hash)
+		}
+	}
+
+	tx, err := s.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	reader, err := s.parentStateReader(ctx, tx, built)
+	if err != nil {
+		return nil, err
+	}
+
+	header := types.CopyHeader(built)
+	header.GasUsed = 0
+	var blobGasUsed uint64
+	if header.BlobGasUsed != nil {
+		header.BlobGasUsed = &blobGasUsed
+	}
+	getHeader := func(hash libcommon.Hash, number uint64) *types.Header {
+		h, _ := s.blockReader.Header(ctx, tx, hash, number)
+		return h
+	}
+	blockHashFunc := core.GetHashFn(header, getHeader)
+	signer := types.MakeSigner(s.config, header.Number.Uint64(), header.Time)
+	coinbase := header.Coinbase
+	gasPool := new(core.GasPool).AddGas(header.GasLimit).AddBlobGas(s.config.GetMaxBlobGasPerBlock(header.Time))
+	noop := state.NewNoopWriter()
+
+	ibs := state.New(reader)
+	// system calls of the block (EIP-4788, EIP-2935) run before the transactions, as in block execution
+	chainReader := consensuschain.NewReader(s.config, tx, s.blockReader, s.logger)
+	if err := core.InitializeBlockExecution(s.engine, chainReader, header, s.config, ibs, noop, s.logger, nil); err != nil {
+		return nil, err
+	}
+	before, err := ibs.GetBalance(coinbase)
+	if err != nil {
+		return nil, err
+	}
+	before = before.Clone()
+
+	res := &SimulationResult{Ordering: ordering}
+	fees := new(uint256.Int)
+	for i, txn := range txns {
+		if err := libcommon.Stopped(ctx.Done()); err != nil {
+			return nil, err
+		}
+		sender, err := txn.Sender(*signer)
+		if err != nil {
+			res.Candidates = append(res.Candidates, skippedCandidate(txn, sender, SkipNoSender, err))
+			continue
+		}
+		ibs.SetTxContext(i)
+		gasSnap, blobGasSnap := gasPool.Gas(), gasPool.BlobGas()
+		snap := ibs.Snapshot()
+		receipt, _, err := core.ApplyTransaction(s.config, blockHashFunc, s.engine, &coinbase, gasPool, ibs, noop, header, txn, &header.GasUsed, header.BlobGasUsed, vm.Config{})
+		if err != nil {
+			ibs.RevertToSnapshot(snap)
+			gasPool = new(core.GasPool).AddGas(gasSnap).AddBlobGas(blobGasSnap)
+			res.Candidates = append(res.Candidates, skippedCandidate(txn, sender, SkipReasonOf(err), err))
+			continue
+		}
+		candidate, fee := includedCandidate(txn, sender, receipt, header.BaseFee)
+		res.Candidates = append(res.Candidates, candidate)
+		fees.Add(fees, fee)
+	}
+
+	after, err := ibs.GetBalance(coinbase)
+	if err != nil {
+		return nil, err
+	}
+	value := new(uint256.Int)
+	if after.Gt(before) {
+		value.Sub(after, before)
+	}
+
+	base := report.Result()
+	res.GasUsed = hexutil.Uint64(header.GasUsed)
+	res.BlobGasUsed = hexutil.Uint64(blobGasUsed)
+	res.Fees = (*hexutil.Big)(fees.ToBig())
+	res.Value = (*hexutil.Big)(value.ToBig())
+	res.FeesDelta = (*hexutil.Big)(new(big.Int).Sub(fees.ToBig(), base.Fees.ToInt()))
+	res.ValueDelta = (*hexutil.Big)(new(big.Int).Sub(value.ToBig(), base.Value.ToInt()))
+	return res, nil
+}
+
+// parentStateReader - latest state while the parent is the head, otherwise historical state
+// at the beginning of the block built on top of the parent
+func (s *Simulator) parentStateReader(ctx context.Context, tx kv.TemporalTx, header *types.Header) (state.StateReader, error) {
+	if rawdb.ReadHeadBlockHash(tx) == header.ParentHash {
+		return state.NewReaderV3(tx), nil
+	}
+	number := header.Number.Uint64()
+	canonical, ok, err := s.blockReader.CanonicalHash(ctx, tx, number-1)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || canonical != header.ParentHash {
+		return nil, fmt.Errorf("parent %x of payload is not canonical", header.ParentHash)
+	}
+	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, s.blockReader))
+	minTxNum, err := txNumsReader.Min(tx, number)
+	if err != nil {
+		return nil, err
+	}
+	reader := state.NewHistoryReaderV3()
+	reader.SetTx(tx)
+	if minTxNum < reader.StateHistoryStartFrom() {
+		return nil, state.PrunedError
+	}
+	reader.SetTxNum(minTxNum)
+	return reader, nil
+}
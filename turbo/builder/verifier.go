@@ -0,0 +1,91 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package builder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	libstate "github.com/erigontech/erigon-lib/state"
+
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/rawdb"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/tracing"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/consensuschain"
+	"github.com/erigontech/erigon/turbo/services"
+)
+
+// BlockVerifier executes blocks built by other nodes on top of the head block without
+// writing their state, e.g. the proposals QBFT validators vote on.
+type BlockVerifier struct {
+	config      *chain.Config
+	db          kv.TemporalRoDB
+	engine      consensus.Engine
+	blockReader services.FullBlockReader
+	logger      log.Logger
+}
+
+func NewBlockVerifier(config *chain.Config, db kv.TemporalRoDB, engine consensus.Engine, blockReader services.FullBlockReader, logger log.Logger) *BlockVerifier {
+	return &BlockVerifier{config: config, db: db, engine: engine, blockReader: blockReader, logger: logger}
+}
+
+// VerifyBlock executes block, whose parent must be the head block, and checks the gas used,
+// receipts root, logs bloom and state root of its header.
+func (v *BlockVerifier) VerifyBlock(ctx context.Context, block *types.Block) error {
+	tx, err := v.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if head := rawdb.ReadHeadBlockHash(tx); head != block.ParentHash() {
+		return fmt.Errorf("parent %x of block %d is not the head block %x", block.ParentHash(), block.NumberU64(), head)
+	}
+
+	domains, err := libstate.NewSharedDomains(tx, v.logger)
+	if err != nil {
+		return err
+	}
+	defer domains.Close()
+	domains.SetBlockNum(block.NumberU64())
+
+	getHeader := func(hash libcommon.Hash, number uint64) *types.Header {
+		h, _ := v.blockReader.Header(ctx, tx, hash, number)
+		return h
+	}
+	noTracer := func(int, libcommon.Hash) (*tracing.Hooks, error) { return nil, nil }
+	chainReader := consensuschain.NewReader(v.config, tx, v.blockReader, v.logger)
+	if _, err := core.ExecuteBlockEphemerally(v.config, &vm.Config{}, core.GetHashFn(block.HeaderNoCopy(), getHeader), v.engine, block,
+		state.NewReaderV3(domains), state.NewWriterV4(domains), chainReader, noTracer, v.logger); err != nil {
+		return err
+	}
+	root, err := domains.ComputeCommitment(ctx, false, block.NumberU64(), "")
+	if err != nil {
+		return err
+	}
+	if libcommon.BytesToHash(root) != block.Root() {
+		return fmt.Errorf("state root by execution: %x, in header: %x", root, block.Root())
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package builder_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/turbo/builder"
+	"github.com/erigontech/erigon/turbo/stages/mock"
+)
+
+func TestBlockVerifier(t *testing.T) {
+	m := mock.Mock(t)
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3, func(i int, b *core.BlockGen) {
+		txn, err := types.SignTx(types.NewTransaction(b.TxNonce(m.Address), libcommon.Address{byte(i + 1)}, uint256.NewInt(1000), 21000, uint256.NewInt(1_000_000_000), nil), *signer, m.Key)
+		require.NoError(t, err)
+		b.AddTx(txn)
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain.Slice(0, 2)))
+
+	ctx := context.Background()
+	verifier := builder.NewBlockVerifier(m.ChainConfig, m.DB, m.Engine, m.BlockReader, m.Log)
+	require.NoError(t, verifier.VerifyBlock(ctx, chain.Blocks[2]))
+
+	// blocks which aren't children of the head can't be executed
+	require.ErrorContains(t, verifier.VerifyBlock(ctx, chain.Blocks[1]), "not the head block")
+
+	tampered := func(mutate func(h *types.Header)) *types.Block {
+		header := chain.Blocks[2].Header()
+		mutate(header)
+		return chain.Blocks[2].WithSeal(header)
+	}
+	require.ErrorContains(t, verifier.VerifyBlock(ctx, tampered(func(h *types.Header) { h.Root = libcommon.Hash{1} })), "state root")
+	require.ErrorContains(t, verifier.VerifyBlock(ctx, tampered(func(h *types.Header) { h.ReceiptHash = libcommon.Hash{1} })), "receipt")
+	require.ErrorContains(t, verifier.VerifyBlock(ctx, tampered(func(h *types.Header) { h.GasUsed++ })), "gas used")
+}
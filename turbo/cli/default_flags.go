@@ -27,6 +27,12 @@ var DefaultFlags = []cli.Flag{
 	&utils.DataDirFlag,
 	&utils.EthashDatasetDirFlag,
 	&utils.ExternalConsensusFlag,
+	&utils.EngineRecordDirFlag,
+	&utils.EngineRecordMaxFileSizeFlag,
+	&utils.EngineRecordMaxFilesFlag,
+	&utils.LightProvidersFlag,
+	&utils.LightBeaconUrlFlag,
+	&utils.LightCheckpointFlag,
 	&utils.TxPoolDisableFlag,
 	&utils.TxPoolPriceLimitFlag,
 	&utils.TxPoolPriceBumpFlag,
@@ -41,6 +47,7 @@ var DefaultFlags = []cli.Flag{
 	&utils.TxPoolCommitEveryFlag,
 	&PruneDistanceFlag,
 	&PruneBlocksDistanceFlag,
+	&PruneExpiryFlag,
 	&PruneModeFlag,
 	&BatchSizeFlag,
 	&BodyCacheLimitFlag,
@@ -122,9 +129,11 @@ var DefaultFlags = []cli.Flag{
 	&utils.ListenPortFlag,
 	&utils.P2pProtocolVersionFlag,
 	&utils.P2pProtocolAllowedPorts,
+	&utils.P2pQUICPortFlag,
 	&utils.NATFlag,
 	&utils.NoDiscoverFlag,
 	&utils.DiscoveryV5Flag,
+	&utils.DiscoveryV5TopicsFlag,
 	&utils.NetrestrictFlag,
 	&utils.NodeKeyFileFlag,
 	&utils.NodeKeyHexFlag,
@@ -157,6 +166,10 @@ var DefaultFlags = []cli.Flag{
 	&utils.MinerRecommitIntervalFlag,
 	&utils.SentryAddrFlag,
 	&utils.SentryLogPeerInfoFlag,
+	&utils.SnapServeFlag,
+	&utils.SentryCaptureDirFlag,
+	&utils.SentryCaptureFileSizeFlag,
+	&utils.SentryCaptureFilesFlag,
 	&utils.DownloaderAddrFlag,
 	&utils.DisableIPV4,
 	&utils.DisableIPV6,
@@ -236,6 +249,10 @@ var DefaultFlags = []cli.Flag{
 	&SyncLoopBlockLimitFlag,
 	&SyncLoopBreakAfterFlag,
 	&SyncParallelStateFlushing,
+	&SyncBlockSTMFlag,
+	&SyncBlockSTMMinTxsFlag,
+	&SyncPrefetchFlag,
+	&SyncPrefetchWorkersFlag,
 
 	&utils.ChaosMonkeyFlag,
 
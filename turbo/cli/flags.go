@@ -99,6 +99,11 @@ var (
 		Name:  "prune.distance.blocks",
 		Usage: `Keep block history for the latest N blocks (default: everything)`,
 	}
+	PruneExpiryFlag = cli.Uint64Flag{
+		Name: "prune.expiry",
+		Usage: `EIP-4444 style history expiry: delete block bodies and transactions older than the latest N blocks, keeping only headers.
+				Expired segments are neither downloaded nor seeded, RPC answers requests for their blocks with error code 4444 (default: keep everything)`,
+	}
 	ExperimentsFlag = cli.StringFlag{
 		Name: "experiments",
 		Usage: `Enable some experimental stages:
@@ -156,6 +161,30 @@ var (
 		Value: true,
 	}
 
+	SyncBlockSTMFlag = cli.BoolFlag{
+		Name:  "sync.blockstm",
+		Usage: "Execute transactions of blocks at chain tip speculatively in parallel (Block-STM)",
+		Value: false,
+	}
+
+	SyncBlockSTMMinTxsFlag = cli.IntFlag{
+		Name:  "sync.blockstm.min-txs",
+		Usage: "Blocks with less transactions are executed serially when --sync.blockstm is enabled",
+		Value: ethconfig.Defaults.Sync.BlockSTMMinTxs,
+	}
+
+	SyncPrefetchFlag = cli.BoolFlag{
+		Name:  "sync.prefetch",
+		Usage: "Warm state reads (accounts, storage, code) of payloads received by Engine API before and during their execution",
+		Value: false,
+	}
+
+	SyncPrefetchWorkersFlag = cli.IntFlag{
+		Name:  "sync.prefetch.workers",
+		Usage: "Amount of concurrent readers used by --sync.prefetch",
+		Value: ethconfig.Defaults.Sync.PrefetchWorkers,
+	}
+
 	UploadLocationFlag = cli.StringFlag{
 		Name:  "upload.location",
 		Usage: "Location to upload snapshot segments to",
@@ -306,6 +335,14 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 		mode.History = prune.Distance(config3.DefaultPruneDistance)
 	}
 
+	if ctx.IsSet(PruneExpiryFlag.Name) {
+		expiry := ctx.Uint64(PruneExpiryFlag.Name)
+		if expiry < config3.FullImmutabilityThreshold {
+			utils.Fatalf("error: --prune.expiry must be at least %d blocks", config3.FullImmutabilityThreshold)
+		}
+		mode.Expiry = prune.Distance(expiry)
+	}
+
 	if err != nil {
 		utils.Fatalf(fmt.Sprintf("error while parsing mode: %v", err))
 	}
@@ -351,6 +388,10 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 		cfg.Sync.LoopBlockLimit = limit
 	}
 	cfg.Sync.ParallelStateFlushing = ctx.Bool(SyncParallelStateFlushing.Name)
+	cfg.Sync.BlockSTM = ctx.Bool(SyncBlockSTMFlag.Name)
+	cfg.Sync.BlockSTMMinTxs = ctx.Int(SyncBlockSTMMinTxsFlag.Name)
+	cfg.Sync.Prefetch = ctx.Bool(SyncPrefetchFlag.Name)
+	cfg.Sync.PrefetchWorkers = ctx.Int(SyncPrefetchWorkersFlag.Name)
 
 	if location := ctx.String(UploadLocationFlag.Name); len(location) > 0 {
 		cfg.Sync.UploadLocation = location
@@ -398,6 +439,7 @@ func ApplyFlagsForEthConfigCobra(f *pflag.FlagSet, cfg *ethconfig.Config) {
 	pruneMode := f.String(PruneModeFlag.Name, PruneModeFlag.DefaultText, PruneModeFlag.Usage)
 	pruneBlockDistance := f.Uint64(PruneBlocksDistanceFlag.Name, PruneBlocksDistanceFlag.Value, PruneBlocksDistanceFlag.Usage)
 	pruneDistance := f.Uint64(PruneDistanceFlag.Name, PruneDistanceFlag.Value, PruneDistanceFlag.Usage)
+	pruneExpiry := f.Uint64(PruneExpiryFlag.Name, PruneExpiryFlag.Value, PruneExpiryFlag.Usage)
 
 	chainId := cfg.NetworkID
 
@@ -436,6 +478,9 @@ func ApplyFlagsForEthConfigCobra(f *pflag.FlagSet, cfg *ethconfig.Config) {
 	}
 	mode.Blocks = prune.Distance(blockDistance)
 	mode.History = prune.Distance(distance)
+	if pruneExpiry != nil && *pruneExpiry > 0 {
+		mode.Expiry = prune.Distance(*pruneExpiry)
+	}
 
 	cfg.Prune = mode
 
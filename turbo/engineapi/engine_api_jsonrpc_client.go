@@ -207,3 +207,21 @@ func (c *JsonRpcClient) GetClientVersionV1(ctx context.Context, callerVersion *e
 	}
 	return result, nil
 }
+
+func (c *JsonRpcClient) GetBlobsV1(ctx context.Context, blobHashes []libcommon.Hash) ([]*enginetypes.BlobAndProofV1, error) {
+	var result []*enginetypes.BlobAndProofV1
+	err := c.rpcClient.CallContext(ctx, &result, "engine_getBlobsV1", blobHashes)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *JsonRpcClient) GetBlobsV2(ctx context.Context, blobHashes []libcommon.Hash) ([]*enginetypes.BlobAndProofV2, error) {
+	var result []*enginetypes.BlobAndProofV2
+	err := c.rpcClient.CallContext(ctx, &result, "engine_getBlobsV2", blobHashes)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -3,6 +3,7 @@ package engineapi
 import (
 	"context"
 	"encoding/binary"
+	"time"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/hexutil"
@@ -28,6 +29,7 @@ var ourCapabilities = []string{
 	"engine_getPayloadBodiesByRangeV1",
 	"engine_getClientVersionV1",
 	"engine_getBlobsV1",
+	"engine_getBlobsV2",
 }
 
 // Returns the most recent version of the payload(for the payloadID) at the time of receiving the call
@@ -39,39 +41,51 @@ func (e *EngineServer) GetPayloadV1(ctx context.Context, payloadId hexutil.Bytes
 	}
 	e.engineLogSpamer.RecordRequest()
 
+	start := time.Now()
 	decodedPayloadId := binary.BigEndian.Uint64(payloadId)
 	e.logger.Info("Received GetPayloadV1", "payloadId", decodedPayloadId)
 
 	response, err := e.getPayload(ctx, decodedPayloadId, clparams.BellatrixVersion)
 	if err != nil {
+		e.recorder.Record("engine_getPayloadV1", start, []any{payloadId}, nil, err)
 		return nil, err
 	}
 
+	e.recorder.Record("engine_getPayloadV1", start, []any{payloadId}, response.ExecutionPayload, nil)
 	return response.ExecutionPayload, nil
 }
 
 // Same as [GetPayloadV1] with addition of blockValue
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_getpayloadv2
 func (e *EngineServer) GetPayloadV2(ctx context.Context, payloadID hexutil.Bytes) (*engine_types.GetPayloadResponse, error) {
+	start := time.Now()
 	decodedPayloadId := binary.BigEndian.Uint64(payloadID)
 	e.logger.Info("Received GetPayloadV2", "payloadId", decodedPayloadId)
-	return e.getPayload(ctx, decodedPayloadId, clparams.CapellaVersion)
+	res, err := e.getPayload(ctx, decodedPayloadId, clparams.CapellaVersion)
+	e.recorder.Record("engine_getPayloadV2", start, []any{payloadID}, res, err)
+	return res, err
 }
 
 // Same as [GetPayloadV2], with addition of blobsBundle containing valid blobs, commitments, proofs
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/cancun.md#engine_getpayloadv3
 func (e *EngineServer) GetPayloadV3(ctx context.Context, payloadID hexutil.Bytes) (*engine_types.GetPayloadResponse, error) {
+	start := time.Now()
 	decodedPayloadId := binary.BigEndian.Uint64(payloadID)
 	e.logger.Info("Received GetPayloadV3", "payloadId", decodedPayloadId)
-	return e.getPayload(ctx, decodedPayloadId, clparams.DenebVersion)
+	res, err := e.getPayload(ctx, decodedPayloadId, clparams.DenebVersion)
+	e.recorder.Record("engine_getPayloadV3", start, []any{payloadID}, res, err)
+	return res, err
 }
 
 // Same as [GetPayloadV3], but returning ExecutionPayloadV4 (= ExecutionPayloadV3 + requests)
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/prague.md#engine_getpayloadv4
 func (e *EngineServer) GetPayloadV4(ctx context.Context, payloadID hexutil.Bytes) (*engine_types.GetPayloadResponse, error) {
+	start := time.Now()
 	decodedPayloadId := binary.BigEndian.Uint64(payloadID)
 	e.logger.Info("Received GetPayloadV4", "payloadId", decodedPayloadId)
-	return e.getPayload(ctx, decodedPayloadId, clparams.ElectraVersion)
+	res, err := e.getPayload(ctx, decodedPayloadId, clparams.ElectraVersion)
+	e.recorder.Record("engine_getPayloadV4", start, []any{payloadID}, res, err)
+	return res, err
 }
 
 // Updates the forkchoice state after validating the headBlockHash
@@ -79,38 +93,56 @@ func (e *EngineServer) GetPayloadV4(ctx context.Context, payloadID hexutil.Bytes
 // (asynchronously updated with transactions), if payloadAttributes is not nil and passes validation
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/paris.md#engine_forkchoiceupdatedv1
 func (e *EngineServer) ForkchoiceUpdatedV1(ctx context.Context, forkChoiceState *engine_types.ForkChoiceState, payloadAttributes *engine_types.PayloadAttributes) (*engine_types.ForkChoiceUpdatedResponse, error) {
-	return e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.BellatrixVersion)
+	start := time.Now()
+	res, err := e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.BellatrixVersion)
+	e.recorder.Record("engine_forkchoiceUpdatedV1", start, []any{forkChoiceState, payloadAttributes}, res, err)
+	return res, err
 }
 
 // Same as, and a replacement for, [ForkchoiceUpdatedV1], post Shanghai
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_forkchoiceupdatedv2
 func (e *EngineServer) ForkchoiceUpdatedV2(ctx context.Context, forkChoiceState *engine_types.ForkChoiceState, payloadAttributes *engine_types.PayloadAttributes) (*engine_types.ForkChoiceUpdatedResponse, error) {
-	return e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.CapellaVersion)
+	start := time.Now()
+	res, err := e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.CapellaVersion)
+	e.recorder.Record("engine_forkchoiceUpdatedV2", start, []any{forkChoiceState, payloadAttributes}, res, err)
+	return res, err
 }
 
 // Successor of [ForkchoiceUpdatedV2] post Cancun, with stricter check on params
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/cancun.md#engine_forkchoiceupdatedv3
 func (e *EngineServer) ForkchoiceUpdatedV3(ctx context.Context, forkChoiceState *engine_types.ForkChoiceState, payloadAttributes *engine_types.PayloadAttributes) (*engine_types.ForkChoiceUpdatedResponse, error) {
-	return e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.DenebVersion)
+	start := time.Now()
+	res, err := e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, clparams.DenebVersion)
+	e.recorder.Record("engine_forkchoiceUpdatedV3", start, []any{forkChoiceState, payloadAttributes}, res, err)
+	return res, err
 }
 
 // NewPayloadV1 processes new payloads (blocks) from the beacon chain without withdrawals.
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/paris.md#engine_newpayloadv1
 func (e *EngineServer) NewPayloadV1(ctx context.Context, payload *engine_types.ExecutionPayload) (*engine_types.PayloadStatus, error) {
-	return e.newPayload(ctx, payload, nil, nil, nil, clparams.BellatrixVersion)
+	start := time.Now()
+	res, err := e.newPayload(ctx, payload, nil, nil, nil, clparams.BellatrixVersion)
+	e.recorder.Record("engine_newPayloadV1", start, []any{payload}, res, err)
+	return res, err
 }
 
 // NewPayloadV2 processes new payloads (blocks) from the beacon chain with withdrawals.
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_newpayloadv2
 func (e *EngineServer) NewPayloadV2(ctx context.Context, payload *engine_types.ExecutionPayload) (*engine_types.PayloadStatus, error) {
-	return e.newPayload(ctx, payload, nil, nil, nil, clparams.CapellaVersion)
+	start := time.Now()
+	res, err := e.newPayload(ctx, payload, nil, nil, nil, clparams.CapellaVersion)
+	e.recorder.Record("engine_newPayloadV2", start, []any{payload}, res, err)
+	return res, err
 }
 
 // NewPayloadV3 processes new payloads (blocks) from the beacon chain with withdrawals & blob gas.
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/cancun.md#engine_newpayloadv3
 func (e *EngineServer) NewPayloadV3(ctx context.Context, payload *engine_types.ExecutionPayload,
 	expectedBlobHashes []libcommon.Hash, parentBeaconBlockRoot *libcommon.Hash) (*engine_types.PayloadStatus, error) {
-	return e.newPayload(ctx, payload, expectedBlobHashes, parentBeaconBlockRoot, nil, clparams.DenebVersion)
+	start := time.Now()
+	res, err := e.newPayload(ctx, payload, expectedBlobHashes, parentBeaconBlockRoot, nil, clparams.DenebVersion)
+	e.recorder.Record("engine_newPayloadV3", start, []any{payload, expectedBlobHashes, parentBeaconBlockRoot}, res, err)
+	return res, err
 }
 
 // NewPayloadV4 processes new payloads (blocks) from the beacon chain with withdrawals, blob gas and requests.
@@ -119,7 +151,10 @@ func (e *EngineServer) NewPayloadV4(ctx context.Context, payload *engine_types.E
 	expectedBlobHashes []libcommon.Hash, parentBeaconBlockRoot *libcommon.Hash, executionRequests []hexutil.Bytes) (*engine_types.PayloadStatus, error) {
 	// TODO(racytech): add proper version or refactor this part
 	// add all version ralated checks here so the newpayload doesn't have to deal with checks
-	return e.newPayload(ctx, payload, expectedBlobHashes, parentBeaconBlockRoot, executionRequests, clparams.ElectraVersion)
+	start := time.Now()
+	res, err := e.newPayload(ctx, payload, expectedBlobHashes, parentBeaconBlockRoot, executionRequests, clparams.ElectraVersion)
+	e.recorder.Record("engine_newPayloadV4", start, []any{payload, expectedBlobHashes, parentBeaconBlockRoot, executionRequests}, res, err)
+	return res, err
 }
 
 // Returns an array of execution payload bodies referenced by their block hashes
@@ -172,3 +207,10 @@ func (e *EngineServer) GetBlobsV1(ctx context.Context, blobHashes []libcommon.Ha
 	e.logger.Debug("[GetBlobsV1] Received Request", "hashes", len(blobHashes))
 	return e.getBlobs(ctx, blobHashes)
 }
+
+// Returns the blobs with their EIP-7594 cell proofs, or nil if any of them is not in the txpool
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/osaka.md#engine_getblobsv2
+func (e *EngineServer) GetBlobsV2(ctx context.Context, blobHashes []libcommon.Hash) ([]*engine_types.BlobAndProofV2, error) {
+	e.logger.Debug("[GetBlobsV2] Received Request", "hashes", len(blobHashes))
+	return e.getBlobsV2(ctx, blobHashes)
+}
@@ -0,0 +1,276 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package engine_recorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+const (
+	filePrefix = "engine-"
+	fileSuffix = ".jsonl"
+
+	DefaultMaxFileSize = 64 // MB
+	DefaultMaxFiles    = 10
+)
+
+// Entry is a single recorded Engine API call: the request parameters exactly as they were
+// received from the consensus layer, and the response (or error) that was sent back.
+type Entry struct {
+	Seq      uint64            `json:"seq"`
+	Time     time.Time         `json:"time"`
+	Duration time.Duration     `json:"duration"`
+	Method   string            `json:"method"`
+	Params   []json.RawMessage `json:"params"`
+	Result   json.RawMessage   `json:"result,omitempty"`
+	Error    *EntryError       `json:"error,omitempty"`
+}
+
+type EntryError struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// rpcError mirrors rpc.Error, so that error codes are preserved without depending on the rpc package.
+type rpcError interface {
+	Error() string
+	ErrorCode() int
+}
+
+// Recorder appends Engine API calls to a set of rotating files in a directory. Files are named
+// engine-<index>.jsonl; once a file exceeds maxFileSize a new one is started and the oldest
+// files beyond maxFiles are deleted. A nil *Recorder is valid and records nothing.
+type Recorder struct {
+	dir         string
+	maxFileSize int64
+	maxFiles    int
+	logger      log.Logger
+
+	mu      sync.Mutex
+	file    *os.File
+	w       *bufio.Writer
+	size    int64
+	index   uint64
+	seq     uint64
+	closed  bool
+	lastErr error
+}
+
+// NewRecorder opens a recorder in dir. maxFileSize is in megabytes.
+func NewRecorder(dir string, maxFileSize, maxFiles uint, logger log.Logger) (*Recorder, error) {
+	if maxFileSize == 0 {
+		maxFileSize = DefaultMaxFileSize
+	}
+	if maxFiles == 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	files, err := ListFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		dir:         dir,
+		maxFileSize: int64(maxFileSize) * 1024 * 1024,
+		maxFiles:    int(maxFiles),
+		logger:      logger,
+	}
+	if len(files) > 0 {
+		// never append to a file of a previous run: each run starts in a fresh file
+		r.index, _ = fileIndex(files[len(files)-1])
+		r.index++
+	}
+	if err := r.rotate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Record appends one call to the recording. Marshalling or IO failures are logged, but never
+// propagated to the caller: recording must not affect the Engine API itself.
+func (r *Recorder) Record(method string, start time.Time, params []any, result any, callErr error) {
+	if r == nil {
+		return
+	}
+	entry := Entry{
+		Time:     start,
+		Duration: time.Since(start),
+		Method:   method,
+		Params:   make([]json.RawMessage, len(params)),
+	}
+	for i, p := range params {
+		raw, err := json.Marshal(p)
+		if err != nil {
+			r.logger.Warn("[EngineRecorder] failed to marshal params", "method", method, "err", err)
+			return
+		}
+		entry.Params[i] = raw
+	}
+	if callErr != nil {
+		entry.Error = &EntryError{Message: callErr.Error()}
+		var codeErr rpcError
+		if errors.As(callErr, &codeErr) {
+			entry.Error.Code = codeErr.ErrorCode()
+		}
+	} else {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			r.logger.Warn("[EngineRecorder] failed to marshal result", "method", method, "err", err)
+			return
+		}
+		entry.Result = raw
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.seq++
+	entry.Seq = r.seq
+	if err := r.write(&entry); err != nil && r.lastErr == nil {
+		// only log the first failure, a full disk would otherwise spam the logs on every call
+		r.lastErr = err
+		r.logger.Warn("[EngineRecorder] failed to write entry", "dir", r.dir, "err", err)
+	}
+}
+
+func (r *Recorder) write(entry *Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if r.size > 0 && r.size+int64(len(line)) > r.maxFileSize {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := r.w.Write(line)
+	r.size += int64(n)
+	if err != nil {
+		return err
+	}
+	// flush every entry: the recording is most valuable right when the node crashes
+	return r.w.Flush()
+}
+
+func (r *Recorder) rotate() error {
+	if r.file != nil {
+		if err := r.closeFile(); err != nil {
+			return err
+		}
+		r.index++
+	}
+	f, err := os.OpenFile(filepath.Join(r.dir, fileName(r.index)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	r.file, r.w, r.size = f, bufio.NewWriter(f), 0
+	return r.removeOldFiles()
+}
+
+func (r *Recorder) removeOldFiles() error {
+	files, err := ListFiles(r.dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > r.maxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+func (r *Recorder) closeFile() error {
+	if err := r.w.Flush(); err != nil {
+		return err
+	}
+	return r.file.Close()
+}
+
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return r.closeFile()
+}
+
+func fileName(index uint64) string {
+	return fmt.Sprintf("%s%06d%s", filePrefix, index, fileSuffix)
+}
+
+func fileIndex(path string) (uint64, bool) {
+	name := filepath.Base(path)
+	if !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+		return 0, false
+	}
+	index, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return index, true
+}
+
+// ListFiles returns the recording files in dir, oldest first.
+func ListFiles(dir string) ([]string, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type indexedFile struct {
+		path  string
+		index uint64
+	}
+	var files []indexedFile
+	for _, e := range dirEntries {
+		if e.IsDir() {
+			continue
+		}
+		if index, ok := fileIndex(e.Name()); ok {
+			files = append(files, indexedFile{path: filepath.Join(dir, e.Name()), index: index})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].index < files[j].index })
+	res := make([]string, len(files))
+	for i := range files {
+		res[i] = files[i].path
+	}
+	return res, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package engine_recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+type codeErr struct{}
+
+func (codeErr) Error() string  { return "unsupported fork" }
+func (codeErr) ErrorCode() int { return -38005 }
+
+func TestRecorderRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, 1, 2, log.New())
+	require.NoError(t, err)
+
+	r.Record("engine_newPayloadV1", time.Now(), []any{map[string]string{"blockHash": "0x01"}}, map[string]string{"status": "VALID"}, nil)
+	r.Record("engine_forkchoiceUpdatedV3", time.Now(), []any{"state", nil}, nil, codeErr{})
+	r.Record("engine_getPayloadV1", time.Now(), []any{"0x01"}, nil, errors.New("plain"))
+	require.NoError(t, r.Close())
+
+	var entries []*Entry
+	require.NoError(t, ForEach(dir, func(entry *Entry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	require.Len(t, entries, 3)
+	require.Equal(t, uint64(1), entries[0].Seq)
+	require.Equal(t, "engine_newPayloadV1", entries[0].Method)
+	require.JSONEq(t, `{"blockHash":"0x01"}`, string(entries[0].Params[0]))
+	require.JSONEq(t, `{"status":"VALID"}`, string(entries[0].Result))
+	require.Nil(t, entries[0].Error)
+	require.Equal(t, -38005, entries[1].Error.Code)
+	require.Equal(t, json.RawMessage("null"), entries[1].Params[1])
+	require.Equal(t, 0, entries[2].Error.Code)
+	require.Equal(t, "plain", entries[2].Error.Message)
+
+	// nil recorder is a no-op
+	var nilRecorder *Recorder
+	nilRecorder.Record("engine_newPayloadV1", time.Now(), nil, nil, nil)
+	require.NoError(t, nilRecorder.Close())
+}
+
+func TestRecorderRotation(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, 1, 3, log.New())
+	require.NoError(t, err)
+
+	// ~300KB per entry, so 1MB files hold 3 entries each
+	big := string(bytes.Repeat([]byte{'a'}, 300*1024))
+	for i := 0; i < 20; i++ {
+		r.Record("engine_newPayloadV1", time.Now(), []any{big}, "VALID", nil)
+	}
+	require.NoError(t, r.Close())
+
+	files, err := ListFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	var seqs []uint64
+	require.NoError(t, ForEach(dir, func(entry *Entry) error {
+		seqs = append(seqs, entry.Seq)
+		return nil
+	}))
+	// only the newest entries survive, still in order
+	require.Equal(t, uint64(20), seqs[len(seqs)-1])
+	for i := 1; i < len(seqs); i++ {
+		require.Equal(t, seqs[i-1]+1, seqs[i])
+	}
+
+	// a restarted recorder never appends to the files of a previous run
+	r, err = NewRecorder(dir, 1, 3, log.New())
+	require.NoError(t, err)
+	r.Record("engine_newPayloadV1", time.Now(), nil, "VALID", nil)
+	require.NoError(t, r.Close())
+	newFiles, err := ListFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, newFiles, 3)
+	require.Equal(t, files[1:], newFiles[:2])
+}
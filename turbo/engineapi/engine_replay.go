@@ -0,0 +1,276 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package engineapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_recorder"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_types"
+)
+
+// ReplayMismatch describes a replayed call whose outcome differs from the recorded one.
+type ReplayMismatch struct {
+	Seq      uint64 `json:"seq"`
+	Method   string `json:"method"`
+	Recorded string `json:"recorded"`
+	Replayed string `json:"replayed"`
+}
+
+type ReplayReport struct {
+	Calls      int              `json:"calls"`
+	Skipped    int              `json:"skipped"`
+	Mismatches []ReplayMismatch `json:"mismatches"`
+}
+
+// EngineReplayer feeds a recording made by engine_recorder.Recorder back into an Engine API,
+// in the original order, and compares every outcome with the recorded one.
+//
+// Payload ids are assigned by the execution layer, so the ids returned by the replayed
+// forkchoiceUpdated calls are remembered and substituted into the following getPayload calls.
+type EngineReplayer struct {
+	api            EngineAPI
+	stopOnMismatch bool
+	logger         log.Logger
+
+	payloadIds map[string]hexutil.Bytes // recorded payload id -> replayed payload id
+	report     ReplayReport
+}
+
+func NewEngineReplayer(api EngineAPI, stopOnMismatch bool, logger log.Logger) *EngineReplayer {
+	return &EngineReplayer{
+		api:            api,
+		stopOnMismatch: stopOnMismatch,
+		logger:         logger,
+		payloadIds:     map[string]hexutil.Bytes{},
+	}
+}
+
+var errReplayMismatch = errors.New("replayed outcome differs from the recording")
+
+// Replay replays the recording at path, which is either a single file or a recorder directory.
+func (r *EngineReplayer) Replay(ctx context.Context, path string) (*ReplayReport, error) {
+	err := engine_recorder.ForEach(path, func(entry *engine_recorder.Entry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return r.replayEntry(ctx, entry)
+	})
+	if err != nil && !errors.Is(err, errReplayMismatch) {
+		return &r.report, err
+	}
+	return &r.report, nil
+}
+
+func (r *EngineReplayer) replayEntry(ctx context.Context, entry *engine_recorder.Entry) error {
+	result, callErr, ok, err := r.call(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("seq %d (%s): %w", entry.Seq, entry.Method, err)
+	}
+	if !ok {
+		r.report.Skipped++
+		r.logger.Debug("[EngineReplay] skipping unsupported method", "seq", entry.Seq, "method", entry.Method)
+		return nil
+	}
+	r.report.Calls++
+
+	var replayedErr *engine_recorder.EntryError
+	var replayedResult json.RawMessage
+	if callErr != nil {
+		replayedErr = &engine_recorder.EntryError{Message: callErr.Error()}
+		var codeErr rpc.Error
+		if errors.As(callErr, &codeErr) {
+			replayedErr.Code = codeErr.ErrorCode()
+		}
+	} else if replayedResult, err = json.Marshal(result); err != nil {
+		return err
+	}
+
+	recorded, err := summarizeOutcome(entry.Method, entry.Result, entry.Error)
+	if err != nil {
+		return fmt.Errorf("seq %d (%s): recorded result: %w", entry.Seq, entry.Method, err)
+	}
+	replayed, err := summarizeOutcome(entry.Method, replayedResult, replayedErr)
+	if err != nil {
+		return fmt.Errorf("seq %d (%s): replayed result: %w", entry.Seq, entry.Method, err)
+	}
+	r.rememberPayloadId(entry.Method, entry.Result, replayedResult)
+
+	if recorded == replayed {
+		r.logger.Debug("[EngineReplay] match", "seq", entry.Seq, "method", entry.Method, "outcome", replayed)
+		return nil
+	}
+	r.logger.Warn("[EngineReplay] mismatch", "seq", entry.Seq, "method", entry.Method, "recorded", recorded, "replayed", replayed)
+	r.report.Mismatches = append(r.report.Mismatches, ReplayMismatch{
+		Seq:      entry.Seq,
+		Method:   entry.Method,
+		Recorded: recorded,
+		Replayed: replayed,
+	})
+	if r.stopOnMismatch {
+		return errReplayMismatch
+	}
+	return nil
+}
+
+// call decodes the recorded params and invokes the corresponding method. ok is false for methods
+// the replayer does not know about.
+func (r *EngineReplayer) call(ctx context.Context, entry *engine_recorder.Entry) (result any, callErr error, ok bool, err error) {
+	switch entry.Method {
+	case "engine_newPayloadV1", "engine_newPayloadV2", "engine_newPayloadV3", "engine_newPayloadV4":
+		var payload *engine_types.ExecutionPayload
+		var blobHashes []libcommon.Hash
+		var beaconRoot *libcommon.Hash
+		var requests []hexutil.Bytes
+		if err := decodeParams(entry.Params, &payload, &blobHashes, &beaconRoot, &requests); err != nil {
+			return nil, nil, false, err
+		}
+		switch entry.Method {
+		case "engine_newPayloadV1":
+			result, callErr = r.api.NewPayloadV1(ctx, payload)
+		case "engine_newPayloadV2":
+			result, callErr = r.api.NewPayloadV2(ctx, payload)
+		case "engine_newPayloadV3":
+			result, callErr = r.api.NewPayloadV3(ctx, payload, blobHashes, beaconRoot)
+		default:
+			result, callErr = r.api.NewPayloadV4(ctx, payload, blobHashes, beaconRoot, requests)
+		}
+	case "engine_forkchoiceUpdatedV1", "engine_forkchoiceUpdatedV2", "engine_forkchoiceUpdatedV3":
+		var state *engine_types.ForkChoiceState
+		var attributes *engine_types.PayloadAttributes
+		if err := decodeParams(entry.Params, &state, &attributes); err != nil {
+			return nil, nil, false, err
+		}
+		switch entry.Method {
+		case "engine_forkchoiceUpdatedV1":
+			result, callErr = r.api.ForkchoiceUpdatedV1(ctx, state, attributes)
+		case "engine_forkchoiceUpdatedV2":
+			result, callErr = r.api.ForkchoiceUpdatedV2(ctx, state, attributes)
+		default:
+			result, callErr = r.api.ForkchoiceUpdatedV3(ctx, state, attributes)
+		}
+	case "engine_getPayloadV1", "engine_getPayloadV2", "engine_getPayloadV3", "engine_getPayloadV4":
+		var payloadId hexutil.Bytes
+		if err := decodeParams(entry.Params, &payloadId); err != nil {
+			return nil, nil, false, err
+		}
+		if replayedId, found := r.payloadIds[payloadId.String()]; found {
+			payloadId = replayedId
+		}
+		switch entry.Method {
+		case "engine_getPayloadV1":
+			result, callErr = r.api.GetPayloadV1(ctx, payloadId)
+		case "engine_getPayloadV2":
+			result, callErr = r.api.GetPayloadV2(ctx, payloadId)
+		case "engine_getPayloadV3":
+			result, callErr = r.api.GetPayloadV3(ctx, payloadId)
+		default:
+			result, callErr = r.api.GetPayloadV4(ctx, payloadId)
+		}
+	default:
+		return nil, nil, false, nil
+	}
+	return result, callErr, true, nil
+}
+
+func (r *EngineReplayer) rememberPayloadId(method string, recorded, replayed json.RawMessage) {
+	switch method {
+	case "engine_forkchoiceUpdatedV1", "engine_forkchoiceUpdatedV2", "engine_forkchoiceUpdatedV3":
+	default:
+		return
+	}
+	var recordedResp, replayedResp engine_types.ForkChoiceUpdatedResponse
+	if len(recorded) == 0 || len(replayed) == 0 {
+		return
+	}
+	if json.Unmarshal(recorded, &recordedResp) != nil || json.Unmarshal(replayed, &replayedResp) != nil {
+		return
+	}
+	if recordedResp.PayloadId != nil && replayedResp.PayloadId != nil {
+		r.payloadIds[recordedResp.PayloadId.String()] = *replayedResp.PayloadId
+	}
+}
+
+// decodeParams decodes positional params into the given pointers. Trailing params missing
+// from the recording are left at their zero value.
+func decodeParams(params []json.RawMessage, into ...any) error {
+	if len(params) > len(into) {
+		return fmt.Errorf("too many params: %d", len(params))
+	}
+	for i, raw := range params {
+		if err := json.Unmarshal(raw, into[i]); err != nil {
+			return fmt.Errorf("param %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// summarizeOutcome reduces a call outcome to the parts that must be reproduced deterministically:
+// statuses, latest valid hashes and built block numbers. Details depending on the timing or on the
+// txpool, such as the payload id values, validation error texts and built block hashes, are left out.
+func summarizeOutcome(method string, result json.RawMessage, callErr *engine_recorder.EntryError) (string, error) {
+	if callErr != nil {
+		return fmt.Sprintf("error(code=%d)", callErr.Code), nil
+	}
+	switch method {
+	case "engine_newPayloadV1", "engine_newPayloadV2", "engine_newPayloadV3", "engine_newPayloadV4":
+		var status engine_types.PayloadStatus
+		if err := json.Unmarshal(result, &status); err != nil {
+			return "", err
+		}
+		return summarizePayloadStatus(&status), nil
+	case "engine_forkchoiceUpdatedV1", "engine_forkchoiceUpdatedV2", "engine_forkchoiceUpdatedV3":
+		var resp engine_types.ForkChoiceUpdatedResponse
+		if err := json.Unmarshal(result, &resp); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s payloadId=%t", summarizePayloadStatus(resp.PayloadStatus), resp.PayloadId != nil), nil
+	case "engine_getPayloadV1":
+		var payload engine_types.ExecutionPayload
+		if err := json.Unmarshal(result, &payload); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("block=%d", payload.BlockNumber), nil
+	case "engine_getPayloadV2", "engine_getPayloadV3", "engine_getPayloadV4":
+		var resp engine_types.GetPayloadResponse
+		if err := json.Unmarshal(result, &resp); err != nil {
+			return "", err
+		}
+		if resp.ExecutionPayload == nil {
+			return "empty", nil
+		}
+		return fmt.Sprintf("block=%d", resp.ExecutionPayload.BlockNumber), nil
+	}
+	return string(result), nil
+}
+
+func summarizePayloadStatus(status *engine_types.PayloadStatus) string {
+	if status == nil {
+		return "nil"
+	}
+	if status.LatestValidHash == nil {
+		return fmt.Sprintf("%s latestValidHash=nil", status.Status)
+	}
+	return fmt.Sprintf("%s latestValidHash=%x", status.Status, *status.LatestValidHash)
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package engineapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_helpers"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_recorder"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_types"
+)
+
+// replayTarget answers newPayload with a fixed status and hands out its own payload ids.
+type replayTarget struct {
+	EngineAPI
+	newPayloadStatus engine_types.EngineStatus
+	nextPayloadId    uint64
+	built            map[uint64]common.Hash
+}
+
+func (t *replayTarget) NewPayloadV1(_ context.Context, payload *engine_types.ExecutionPayload) (*engine_types.PayloadStatus, error) {
+	return &engine_types.PayloadStatus{Status: t.newPayloadStatus, LatestValidHash: &payload.BlockHash}, nil
+}
+
+func (t *replayTarget) ForkchoiceUpdatedV1(_ context.Context, state *engine_types.ForkChoiceState, attributes *engine_types.PayloadAttributes) (*engine_types.ForkChoiceUpdatedResponse, error) {
+	resp := &engine_types.ForkChoiceUpdatedResponse{
+		PayloadStatus: &engine_types.PayloadStatus{Status: engine_types.ValidStatus, LatestValidHash: &state.HeadHash},
+	}
+	if attributes != nil {
+		t.nextPayloadId++
+		t.built[t.nextPayloadId] = common.Hash{0xbb}
+		resp.PayloadId = engine_types.ConvertPayloadId(t.nextPayloadId)
+	}
+	return resp, nil
+}
+
+func (t *replayTarget) GetPayloadV2(_ context.Context, payloadID hexutil.Bytes) (*engine_types.GetPayloadResponse, error) {
+	hash, ok := t.built[uint64(payloadID[7])]
+	if !ok {
+		return nil, &engine_helpers.UnknownPayloadErr
+	}
+	return &engine_types.GetPayloadResponse{ExecutionPayload: &engine_types.ExecutionPayload{BlockNumber: 2, BlockHash: hash}}, nil
+}
+
+func writeTestRecording(t *testing.T, dir string) {
+	r, err := engine_recorder.NewRecorder(dir, 0, 0, log.New())
+	require.NoError(t, err)
+	defer r.Close()
+
+	blockHash := common.Hash{0xaa}
+	payload := &engine_types.ExecutionPayload{BlockNumber: 1, BlockHash: blockHash, BaseFeePerGas: (*hexutil.Big)(common.Big1)}
+	r.Record("engine_newPayloadV1", time.Now(), []any{payload},
+		&engine_types.PayloadStatus{Status: engine_types.ValidStatus, LatestValidHash: &blockHash}, nil)
+
+	state := &engine_types.ForkChoiceState{HeadHash: blockHash}
+	attributes := &engine_types.PayloadAttributes{Timestamp: 12}
+	// the recorded node handed out payload id 42, the replay target will hand out 1
+	r.Record("engine_forkchoiceUpdatedV1", time.Now(), []any{state, attributes}, &engine_types.ForkChoiceUpdatedResponse{
+		PayloadStatus: &engine_types.PayloadStatus{Status: engine_types.ValidStatus, LatestValidHash: &blockHash},
+		PayloadId:     engine_types.ConvertPayloadId(42),
+	}, nil)
+	// the replay target builds from its own txpool, so the block hash differs
+	r.Record("engine_getPayloadV2", time.Now(), []any{engine_types.ConvertPayloadId(42)}, &engine_types.GetPayloadResponse{
+		ExecutionPayload: &engine_types.ExecutionPayload{BlockNumber: 2, BlockHash: common.Hash{0xcc}},
+	}, nil)
+	r.Record("engine_getBlobsV1", time.Now(), []any{[]common.Hash{}}, nil, nil)
+}
+
+func TestEngineReplay(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRecording(t, dir)
+
+	target := &replayTarget{newPayloadStatus: engine_types.ValidStatus, built: map[uint64]common.Hash{}}
+	report, err := NewEngineReplayer(target, false, log.New()).Replay(context.Background(), dir)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Calls)
+	require.Equal(t, 1, report.Skipped)
+	require.Empty(t, report.Mismatches)
+}
+
+func TestEngineReplayMismatch(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRecording(t, dir)
+
+	target := &replayTarget{newPayloadStatus: engine_types.InvalidStatus, built: map[uint64]common.Hash{}}
+	report, err := NewEngineReplayer(target, true, log.New()).Replay(context.Background(), dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Calls)
+	require.Len(t, report.Mismatches, 1)
+	require.Equal(t, uint64(1), report.Mismatches[0].Seq)
+	require.Equal(t, "engine_newPayloadV1", report.Mismatches[0].Method)
+}
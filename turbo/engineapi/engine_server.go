@@ -25,10 +25,13 @@ import (
 	"sync/atomic"
 	"time"
 
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+
 	"github.com/erigontech/erigon-lib/chain"
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon-lib/common/math"
+	libkzg "github.com/erigontech/erigon-lib/crypto/kzg"
 	"github.com/erigontech/erigon-lib/gointerfaces"
 	execution "github.com/erigontech/erigon-lib/gointerfaces/executionproto"
 	txpool "github.com/erigontech/erigon-lib/gointerfaces/txpoolproto"
@@ -41,12 +44,14 @@ import (
 	"github.com/erigontech/erigon/cmd/rpcdaemon/cli/httpcfg"
 	"github.com/erigontech/erigon/consensus"
 	"github.com/erigontech/erigon/consensus/merge"
+	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/eth/ethutils"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_block_downloader"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_helpers"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_logs_spammer"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_recorder"
 	"github.com/erigontech/erigon/turbo/engineapi/engine_types"
 	"github.com/erigontech/erigon/turbo/execution/eth1/eth1_chain_reader"
 	"github.com/erigontech/erigon/turbo/jsonrpc"
@@ -76,6 +81,12 @@ type EngineServer struct {
 	logger  log.Logger
 
 	engineLogSpamer *engine_logs_spammer.EngineLogsSpammer
+	// optional, records engine API calls for offline replay
+	recorder *engine_recorder.Recorder
+	// optional, warms state reads of new payloads
+	prefetcher *core.StatePrefetcher
+	// optional, serves reports of built payloads
+	payloadReportAPI *PayloadReportAPIImpl
 	// TODO Remove this on next release
 	printPectraBanner bool
 }
@@ -135,6 +146,14 @@ func (e *EngineServer) Start(
 			Service:   EngineAPI(e),
 			Version:   "1.0",
 		}}
+	if e.payloadReportAPI != nil {
+		apiList = append(apiList, rpc.API{
+			Namespace: "erigon",
+			Public:    true,
+			Service:   PayloadReportAPI(e.payloadReportAPI),
+			Version:   "1.0",
+		})
+	}
 
 	if err := cli.StartRpcServerWithJwtAuthentication(ctx, httpConfig, apiList, e.logger); err != nil {
 		e.logger.Error(err.Error())
@@ -775,6 +794,17 @@ func (e *EngineServer) HandleNewPayload(
 		}
 	}
 
+	if e.prefetcher != nil {
+		// registered before the block is sent to execution, so execution finds it
+		tracker := e.prefetcher.Track(block.Hash())
+		// runs concurrently with execution, until payload is validated
+		go func() {
+			if err := e.prefetcher.Prefetch(ctx, block, tracker); err != nil {
+				e.logger.Debug(fmt.Sprintf("[%s] prefetch stopped", logPrefix), "height", headerNumber, "err", err)
+			}
+		}()
+	}
+
 	if err := e.chainRW.InsertBlockAndWait(ctx, block); err != nil {
 		return nil, err
 	}
@@ -886,7 +916,23 @@ func (e *EngineServer) SetConsuming(consuming bool) {
 	e.consuming.Store(consuming)
 }
 
-func (e *EngineServer) getBlobs(ctx context.Context, blobHashes []libcommon.Hash) ([]*engine_types.BlobAndProofV1, error) {
+// SetRecorder enables recording of newPayload, forkchoiceUpdated and getPayload calls.
+// Must be called before Start.
+func (e *EngineServer) SetRecorder(recorder *engine_recorder.Recorder) {
+	e.recorder = recorder
+}
+
+// SetPrefetcher enables prefetching of state of new payloads. Must be called before Start.
+func (e *EngineServer) SetPrefetcher(prefetcher *core.StatePrefetcher) {
+	e.prefetcher = prefetcher
+}
+
+// SetPayloadReportAPI enables erigon_getPayloadReport. Must be called before Start.
+func (e *EngineServer) SetPayloadReportAPI(api *PayloadReportAPIImpl) {
+	e.payloadReportAPI = api
+}
+
+func (e *EngineServer) fetchBlobs(ctx context.Context, blobHashes []libcommon.Hash) (*txpool.GetBlobsReply, error) {
 	if len(blobHashes) > 128 {
 		return nil, &engine_helpers.TooLargeRequestErr
 	}
@@ -894,7 +940,11 @@ func (e *EngineServer) getBlobs(ctx context.Context, blobHashes []libcommon.Hash
 	for i := range blobHashes {
 		req.BlobHashes[i] = gointerfaces.ConvertHashToH256(blobHashes[i])
 	}
-	res, err := e.txpool.GetBlobs(ctx, req)
+	return e.txpool.GetBlobs(ctx, req)
+}
+
+func (e *EngineServer) getBlobs(ctx context.Context, blobHashes []libcommon.Hash) ([]*engine_types.BlobAndProofV1, error) {
+	res, err := e.fetchBlobs(ctx, blobHashes)
 	if err != nil {
 		return nil, err
 	}
@@ -905,7 +955,8 @@ func (e *EngineServer) getBlobs(ctx context.Context, blobHashes []libcommon.Hash
 	}
 	logLine := []string{}
 	for i := range res.Blobs {
-		if res.Blobs[i] != nil {
+		// blobs received with cell proofs only (EIP-7594 wrappers) have no blob proof to serve
+		if res.Blobs[i] != nil && res.Proofs[i] != nil {
 			ret[i] = &engine_types.BlobAndProofV1{Blob: res.Blobs[i], Proof: res.Proofs[i]}
 			logLine = append(logLine, fmt.Sprintf(" %d:", i), fmt.Sprintf(" hash=%x len(blob)=%d len(proof)=%d ", blobHashes[i], len(res.Blobs[i]), len(res.Proofs[i])))
 		} else {
@@ -916,6 +967,33 @@ func (e *EngineServer) getBlobs(ctx context.Context, blobHashes []libcommon.Hash
 	return ret, nil
 }
 
+// getBlobsV2 returns nil, rather than a partial list, unless every requested blob is available
+// together with its cell proofs.
+func (e *EngineServer) getBlobsV2(ctx context.Context, blobHashes []libcommon.Hash) ([]*engine_types.BlobAndProofV2, error) {
+	res, err := e.fetchBlobs(ctx, blobHashes)
+	if err != nil {
+		return nil, err
+	}
+	if len(blobHashes) != len(res.Blobs) || len(blobHashes) != len(res.CellProofs) {
+		log.Warn("[GetBlobsV2] txpool returned unexpected number of blobs and cell proofs in response, returning nil")
+		return nil, nil
+	}
+	ret := make([]*engine_types.BlobAndProofV2, len(blobHashes))
+	for i := range res.Blobs {
+		if res.Blobs[i] == nil || len(res.CellProofs[i]) != libkzg.CellsPerExtBlob*gokzg4844.CompressedG1Size {
+			e.logger.Debug("[GetBlobsV2] blob or cell proofs missing", "hash", blobHashes[i])
+			return nil, nil
+		}
+		proofs := make([]hexutil.Bytes, libkzg.CellsPerExtBlob)
+		for j := range proofs {
+			proofs[j] = res.CellProofs[i][j*gokzg4844.CompressedG1Size : (j+1)*gokzg4844.CompressedG1Size]
+		}
+		ret[i] = &engine_types.BlobAndProofV2{Blob: res.Blobs[i], CellProofs: proofs}
+	}
+	e.logger.Debug("[GetBlobsV2] all blobs found", "count", len(ret))
+	return ret, nil
+}
+
 func waitForStuff(maxWait time.Duration, waitCondnF func() (bool, error)) (bool, error) {
 	shouldWait, err := waitCondnF()
 	if err != nil || !shouldWait {
@@ -129,4 +129,9 @@ func TestGetBlobsV1(t *testing.T) {
 	require.Equal(blobsResp[2].Blob, hexutil.Bytes(wrappedTxn.Blobs[1][:]))
 	require.Equal(blobsResp[1].Proof, hexutil.Bytes(wrappedTxn.Proofs[0][:]))
 	require.Equal(blobsResp[2].Proof, hexutil.Bytes(wrappedTxn.Proofs[1][:]))
+
+	// no cell proofs before Osaka, so engine_getBlobsV2 has nothing to serve
+	blobsRespV2, err := engineServer.GetBlobsV2(ctx, blobHashes[1:])
+	require.NoError(err)
+	require.Nil(blobsRespV2)
 }
@@ -0,0 +1,71 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package engineapi
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/turbo/builder"
+	"github.com/erigontech/erigon/turbo/engineapi/engine_helpers"
+)
+
+// PayloadReports - source of reports of payloads being built, implemented by the execution module
+type PayloadReports interface {
+	PayloadReport(ctx context.Context, payloadId uint64) (*builder.PayloadReport, error)
+}
+
+// PayloadReportAPI - erigon namespace of the Engine API endpoint: payload ids are known only to its clients
+type PayloadReportAPI interface {
+	GetPayloadReport(ctx context.Context, payloadID hexutil.Bytes, ordering *[]common.Hash) (*builder.PayloadReportResult, error)
+}
+
+type PayloadReportAPIImpl struct {
+	reports   PayloadReports
+	simulator *builder.Simulator
+}
+
+func NewPayloadReportAPI(reports PayloadReports, simulator *builder.Simulator) *PayloadReportAPIImpl {
+	return &PayloadReportAPIImpl{reports: reports, simulator: simulator}
+}
+
+// GetPayloadReport implements erigon_getPayloadReport. Returns the candidate transactions considered
+// while building the payload, why each was skipped, fees, value and time spent per phase.
+// If ordering is given, the candidates are executed in that order and compared with the built payload.
+func (api *PayloadReportAPIImpl) GetPayloadReport(ctx context.Context, payloadID hexutil.Bytes, ordering *[]common.Hash) (*builder.PayloadReportResult, error) {
+	if len(payloadID) != 8 {
+		return nil, &rpc.InvalidParamsError{Message: "payload id must be 8 bytes"}
+	}
+	report, err := api.reports.PayloadReport(ctx, binary.BigEndian.Uint64(payloadID))
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, &engine_helpers.UnknownPayloadErr
+	}
+	res := report.Result()
+	if ordering == nil {
+		return res, nil
+	}
+	if res.Simulation, err = api.simulator.Simulate(ctx, report, *ordering); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
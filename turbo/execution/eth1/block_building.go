@@ -235,3 +235,16 @@ func (e *EthereumExecutionModule) GetAssembledBlock(ctx context.Context, req *ex
 		Busy: false,
 	}, nil
 }
+
+// PayloadReport - report of the payload being built or already built, nil if the payload is unknown or evicted
+func (e *EthereumExecutionModule) PayloadReport(ctx context.Context, payloadId uint64) (*builder.PayloadReport, error) {
+	if err := e.semaphore.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	defer e.semaphore.Release(1)
+	b, ok := e.builders[payloadId]
+	if !ok {
+		return nil, nil
+	}
+	return b.Report(), nil
+}
@@ -223,6 +223,9 @@ func (api *BaseAPI) blockByHashWithSenders(ctx context.Context, tx kv.Tx, hash c
 }
 
 func (api *BaseAPI) blockWithSenders(ctx context.Context, tx kv.Tx, hash common.Hash, number uint64) (*types.Block, error) {
+	if err := checkHistoryExpiry(tx, number); err != nil {
+		return nil, err
+	}
 	if api.blocksLRU != nil {
 		if it, ok := api.blocksLRU.Get(hash); ok && it != nil {
 			return it, nil
@@ -258,7 +261,18 @@ func (api *BaseAPI) chainConfigWithGenesis(ctx context.Context, tx kv.Tx) (*chai
 	}
 
 	genesisBlock, err := api.blockByRPCNumber(ctx, 0, tx)
-	if err != nil {
+	var expiredErr *rpc.HistoryExpiredError
+	if errors.As(err, &expiredErr) {
+		// the genesis body is expired with the first segments, its header is kept
+		genesisHeader, err := api.headerByRPCNumber(ctx, 0, tx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if genesisHeader == nil {
+			return nil, nil, errors.New("genesis header not found in database")
+		}
+		genesisBlock = types.NewBlockWithHeader(genesisHeader)
+	} else if err != nil {
 		return nil, nil, err
 	}
 	if genesisBlock == nil {
@@ -349,6 +363,18 @@ func (api *BaseAPI) checkPruneHistory(ctx context.Context, tx kv.Tx, block uint6
 	return nil
 }
 
+// checkHistoryExpiry - block bodies older than the history expiry horizon are deleted, only their headers are available
+func checkHistoryExpiry(tx kv.Tx, block uint64) error {
+	expiredTo, err := rawdb.ReadHistoryExpiredTo(tx)
+	if err != nil {
+		return err
+	}
+	if block < expiredTo {
+		return &rpc.HistoryExpiredError{Block: block, ExpiredTo: expiredTo}
+	}
+	return nil
+}
+
 func (api *BaseAPI) pruneMode(tx kv.Tx) (*prune.Mode, error) {
 	p := api._pruneMode.Load()
 	if p != nil {
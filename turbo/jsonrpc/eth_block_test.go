@@ -54,6 +54,43 @@ func TestGetBlockByNumberWithLatestTag(t *testing.T) {
 	assert.Equal(t, expected, b["hash"])
 }
 
+func TestGetBlockByNumberHistoryExpired(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	ctx := context.Background()
+	tx, err := m.DB.BeginRw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rawdb.WriteHistoryExpiredTo(tx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+	_, err = api.GetBlockByNumber(ctx, 4, false)
+	var expiredErr *rpc.HistoryExpiredError
+	if assert.ErrorAs(t, err, &expiredErr) {
+		assert.Equal(t, 4444, expiredErr.ErrorCode())
+		assert.EqualValues(t, 5, expiredErr.ExpiredTo)
+	}
+	b, err := api.GetBlockByNumber(ctx, 5, false)
+	assert.NoError(t, err)
+	assert.Equal(t, (*hexutil.Big)(big.NewInt(5)), b["number"])
+
+	// the expired genesis body doesn't hide the chain config
+	roTx, err := m.DB.BeginRo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer roTx.Rollback()
+	cc, genesis, err := api.chainConfigWithGenesis(ctx, roTx)
+	assert.NoError(t, err)
+	assert.NotNil(t, cc)
+	assert.Equal(t, m.Genesis.Hash(), genesis.Hash())
+}
+
 func TestGetBlockByNumberWithLatestTag_WithHeadHashInDb(t *testing.T) {
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
 	ctx := context.Background()
@@ -784,7 +784,7 @@ func (api *APIImpl) CreateAccessList(ctx context.Context, args ethapi2.CallArgs,
 	}
 
 	// Retrieve the precompiles since they don't need to be added to the access list
-	precompiles := vm.ActivePrecompiles(chainConfig.Rules(blockNumber, header.Time))
+	precompiles := vm.ActivePrecompilesAt(chainConfig, blockNumber, header.Time)
 	excl := make(map[libcommon.Address]struct{})
 	for _, pc := range precompiles {
 		excl[pc] = struct{}{}
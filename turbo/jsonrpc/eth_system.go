@@ -61,21 +61,33 @@ func (api *APIImpl) Syncing(ctx context.Context) (interface{}, error) {
 	highestBlock := reply.LastNewBlockSeen
 	currentBlock := reply.CurrentBlock
 	type S struct {
-		StageName   string         `json:"stage_name"`
-		BlockNumber hexutil.Uint64 `json:"block_number"`
+		StageName   string          `json:"stage_name"`
+		BlockNumber hexutil.Uint64  `json:"block_number"`
+		ETA         *hexutil.Uint64 `json:"eta,omitempty"` // seconds, if estimated
 	}
 	stagesMap := make([]S, len(reply.Stages))
 	for i, stage := range reply.Stages {
 		stagesMap[i].StageName = stage.StageName
 		stagesMap[i].BlockNumber = hexutil.Uint64(stage.BlockNumber)
+		if stage.EtaEstimated {
+			eta := hexutil.Uint64(stage.Eta)
+			stagesMap[i].ETA = &eta
+		}
 	}
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"startingBlock": "0x0", // 0x0 is a placeholder, I do not think it matters what we return here
 		"currentBlock":  hexutil.Uint64(currentBlock),
 		"highestBlock":  hexutil.Uint64(highestBlock),
 		"stages":        stagesMap,
-	}, nil
+	}
+	if reply.EtaEstimated {
+		result["eta"] = hexutil.Uint64(reply.Eta)
+	}
+	if reply.Bottleneck != "" {
+		result["bottleneck"] = reply.Bottleneck
+	}
+	return result, nil
 }
 
 // ChainId implements eth_chainId. Returns the current ethereum chainId.
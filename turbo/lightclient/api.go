@@ -0,0 +1,232 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package lightclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/holiman/uint256"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/rpc"
+	ethapi2 "github.com/erigontech/erigon/turbo/adapter/ethapi"
+)
+
+// EthAPI answers a subset of the eth namespace from verified proofs. Registered after the
+// regular eth API, its methods take precedence over the ones of the same name.
+type EthAPI struct {
+	tracker     *HeaderTracker
+	providers   []ProofProvider
+	chainConfig *chain.Config
+	gasCap      uint64
+	callTimeout time.Duration
+	logger      log.Logger
+}
+
+func NewEthAPI(tracker *HeaderTracker, providers []ProofProvider, chainConfig *chain.Config, gasCap uint64, callTimeout time.Duration, logger log.Logger) *EthAPI {
+	return &EthAPI{
+		tracker:     tracker,
+		providers:   providers,
+		chainConfig: chainConfig,
+		gasCap:      gasCap,
+		callTimeout: callTimeout,
+		logger:      logger,
+	}
+}
+
+// APIs returns the light eth API as an rpc.API to be appended to the node's API list.
+func (api *EthAPI) APIs() []rpc.API {
+	return []rpc.API{{
+		Namespace: "eth",
+		Public:    true,
+		Service:   api,
+		Version:   "1.0",
+	}}
+}
+
+// header resolves blockNrOrHash to a tracked finalized header. latest, safe and pending all
+// resolve to the newest finalized header: nothing newer can be verified.
+func (api *EthAPI) header(blockNrOrHash rpc.BlockNumberOrHash) (*Header, error) {
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		if header := api.tracker.HeaderByHash(hash); header != nil {
+			return header, nil
+		}
+		return nil, fmt.Errorf("block %x is not a tracked finalized block", hash)
+	}
+	number, ok := blockNrOrHash.Number()
+	if !ok {
+		number = rpc.LatestBlockNumber
+	}
+	finalized := api.tracker.Finalized()
+	if finalized == nil {
+		return nil, ErrNoFinalizedHeader
+	}
+	switch number {
+	case rpc.LatestBlockNumber, rpc.SafeBlockNumber, rpc.FinalizedBlockNumber, rpc.PendingBlockNumber, rpc.LatestExecutedBlockNumber:
+		return finalized, nil
+	}
+	if header := api.tracker.HeaderByNumber(uint64(number.Int64())); header != nil {
+		return header, nil
+	}
+	return nil, fmt.Errorf("block %d is not a tracked finalized block", number.Int64())
+}
+
+func (api *EthAPI) stateReader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*ProofStateReader, *Header, error) {
+	header, err := api.header(blockNrOrHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewProofStateReader(ctx, header, api.providers, api.logger), header, nil
+}
+
+// BlockNumber implements eth_blockNumber, returning the newest finalized block.
+func (api *EthAPI) BlockNumber(_ context.Context) (hexutil.Uint64, error) {
+	finalized := api.tracker.Finalized()
+	if finalized == nil {
+		return 0, ErrNoFinalizedHeader
+	}
+	return hexutil.Uint64(finalized.Number), nil
+}
+
+// GetBalance implements eth_getBalance.
+func (api *EthAPI) GetBalance(ctx context.Context, address libcommon.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	reader, _, err := api.stateReader(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	acc, err := reader.ReadAccountData(address)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil {
+		return (*hexutil.Big)(libcommon.Big0), nil
+	}
+	return (*hexutil.Big)(acc.Balance.ToBig()), nil
+}
+
+// GetTransactionCount implements eth_getTransactionCount.
+func (api *EthAPI) GetTransactionCount(ctx context.Context, address libcommon.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Uint64, error) {
+	reader, _, err := api.stateReader(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	acc, err := reader.ReadAccountData(address)
+	if err != nil {
+		return nil, err
+	}
+	var nonce hexutil.Uint64
+	if acc != nil {
+		nonce = hexutil.Uint64(acc.Nonce)
+	}
+	return &nonce, nil
+}
+
+// GetCode implements eth_getCode.
+func (api *EthAPI) GetCode(ctx context.Context, address libcommon.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	reader, _, err := api.stateReader(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return reader.ReadAccountCode(address, 0)
+}
+
+// GetStorageAt implements eth_getStorageAt.
+func (api *EthAPI) GetStorageAt(ctx context.Context, address libcommon.Address, index string, blockNrOrHash rpc.BlockNumberOrHash) (string, error) {
+	indexBytes := hexutil.FromHex(index)
+	if len(indexBytes) > 32 {
+		return "", hexutil.ErrTooBigHexString
+	}
+	reader, _, err := api.stateReader(ctx, blockNrOrHash)
+	if err != nil {
+		return "", err
+	}
+	location := libcommon.BytesToHash(indexBytes)
+	res, err := reader.ReadAccountStorage(address, 0, &location)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(libcommon.LeftPadBytes(res, 32)), nil
+}
+
+// Call implements eth_call, executing against state fetched on demand and verified account by account.
+func (api *EthAPI) Call(ctx context.Context, args ethapi2.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides) (hexutil.Bytes, error) {
+	if api.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, api.callTimeout)
+		defer cancel()
+	}
+	reader, header, err := api.stateReader(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	ibs := state.New(reader)
+	if overrides != nil {
+		if err := overrides.Override(ibs); err != nil {
+			return nil, err
+		}
+	}
+
+	if args.Gas == nil || uint64(*args.Gas) == 0 {
+		args.Gas = (*hexutil.Uint64)(&api.gasCap)
+	}
+	evmHeader := header.EVMHeader()
+	var baseFee *uint256.Int
+	if evmHeader.BaseFee != nil {
+		baseFee, _ = uint256.FromBig(evmHeader.BaseFee)
+	}
+	msg, err := args.ToMessage(api.gasCap, baseFee)
+	if err != nil {
+		return nil, err
+	}
+	getHash := func(n uint64) libcommon.Hash {
+		if h := api.tracker.HeaderByNumber(n); h != nil {
+			return h.Hash
+		}
+		return libcommon.Hash{}
+	}
+	blockCtx := core.NewEVMBlockContext(evmHeader, getHash, nil /* engine */, &header.Coinbase, api.chainConfig)
+	evm := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), ibs, api.chainConfig, vm.Config{NoBaseFee: true})
+	go func() {
+		<-ctx.Done()
+		evm.Cancel()
+	}()
+
+	gp := new(core.GasPool).AddGas(msg.Gas()).AddBlobGas(msg.BlobGas())
+	result, err := core.ApplyMessage(evm, msg, gp, true /* refunds */, false /* gasBailout */, nil /* engine */)
+	if err != nil {
+		return nil, err
+	}
+	if evm.Cancelled() {
+		return nil, fmt.Errorf("execution aborted (timeout = %v)", api.callTimeout)
+	}
+	// state that could not be proven must fail the call rather than read as empty
+	if err := ibs.Error(); err != nil {
+		return nil, err
+	}
+	if len(result.Revert()) > 0 {
+		return nil, ethapi2.NewRevertError(result)
+	}
+	return result.Return(), result.Err
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package lightclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+)
+
+const (
+	genesisPath        = "/eth/v1/beacon/genesis"
+	bootstrapPath      = "/eth/v1/beacon/light_client/bootstrap/"
+	updatesPath        = "/eth/v1/beacon/light_client/updates"
+	finalityUpdatePath = "/eth/v1/beacon/light_client/finality_update"
+)
+
+var errNotFound = errors.New("not found")
+
+// BeaconApiSource reads the light client objects from the beacon API of a Caplin node,
+// see GetEthV1BeaconLightClientBootstrap, GetEthV1BeaconLightClientUpdates and
+// GetEthV1BeaconLightClientFinalityUpdate.
+type BeaconApiSource struct {
+	url    string
+	client *http.Client
+	logger log.Logger
+}
+
+func NewBeaconApiSource(url string, logger log.Logger) *BeaconApiSource {
+	return &BeaconApiSource{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger,
+	}
+}
+
+func (s *BeaconApiSource) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", path, errNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+type versionedEnvelope struct {
+	Version string          `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func (e *versionedEnvelope) decode(newObject func(clparams.StateVersion) any) (any, error) {
+	version, err := clparams.StringToClVersion(e.Version)
+	if err != nil {
+		return nil, err
+	}
+	obj := newObject(version)
+	if err := json.Unmarshal(e.Data, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// GenesisValidatorsRoot fetches the genesis validators root of the signing domain. A wrong root
+// can only make valid signatures fail verification, it can't make forged ones pass.
+func (s *BeaconApiSource) GenesisValidatorsRoot(ctx context.Context) (libcommon.Hash, error) {
+	body, err := s.get(ctx, genesisPath)
+	if err != nil {
+		return libcommon.Hash{}, err
+	}
+	var genesis struct {
+		Data struct {
+			GenesisValidatorsRoot libcommon.Hash `json:"genesis_validators_root"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &genesis); err != nil {
+		return libcommon.Hash{}, err
+	}
+	return genesis.Data.GenesisValidatorsRoot, nil
+}
+
+func (s *BeaconApiSource) Bootstrap(ctx context.Context, blockRoot libcommon.Hash) (*cltypes.LightClientBootstrap, error) {
+	body, err := s.get(ctx, bootstrapPath+blockRoot.Hex())
+	if err != nil {
+		return nil, err
+	}
+	var envelope versionedEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	bootstrap, err := envelope.decode(func(v clparams.StateVersion) any { return cltypes.NewLightClientBootstrap(v) })
+	if err != nil {
+		return nil, err
+	}
+	return bootstrap.(*cltypes.LightClientBootstrap), nil
+}
+
+func (s *BeaconApiSource) Updates(ctx context.Context, startPeriod, count uint64) ([]*cltypes.LightClientUpdate, error) {
+	body, err := s.get(ctx, fmt.Sprintf("%s?start_period=%d&count=%d", updatesPath, startPeriod, count))
+	if err != nil {
+		return nil, err
+	}
+	var envelopes []versionedEnvelope
+	if err := json.Unmarshal(body, &envelopes); err != nil {
+		return nil, err
+	}
+	updates := make([]*cltypes.LightClientUpdate, 0, len(envelopes))
+	for i := range envelopes {
+		update, err := envelopes[i].decode(func(v clparams.StateVersion) any { return cltypes.NewLightClientUpdate(v) })
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, update.(*cltypes.LightClientUpdate))
+	}
+	return updates, nil
+}
+
+// FinalityUpdate fetches the newest finality update, converted to a LightClientUpdate without
+// next sync committee. It returns nil if the beacon node has none yet.
+func (s *BeaconApiSource) FinalityUpdate(ctx context.Context) (*cltypes.LightClientUpdate, error) {
+	body, err := s.get(ctx, finalityUpdatePath)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeFinalityUpdate(body)
+}
+
+func decodeFinalityUpdate(body []byte) (*cltypes.LightClientUpdate, error) {
+	var envelope versionedEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	obj, err := envelope.decode(func(v clparams.StateVersion) any { return cltypes.NewLightClientFinalityUpdate(v) })
+	if err != nil {
+		return nil, err
+	}
+	finalityUpdate := obj.(*cltypes.LightClientFinalityUpdate)
+	return &cltypes.LightClientUpdate{
+		AttestedHeader:  finalityUpdate.AttestedHeader,
+		FinalizedHeader: finalityUpdate.FinalizedHeader,
+		FinalityBranch:  finalityUpdate.FinalityBranch,
+		SyncAggregate:   finalityUpdate.SyncAggregate,
+		SignatureSlot:   finalityUpdate.SignatureSlot,
+	}, nil
+}
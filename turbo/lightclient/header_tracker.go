@@ -0,0 +1,278 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/consensus/merge"
+	"github.com/erigontech/erigon/core/types"
+)
+
+const (
+	// number of finalized headers kept for historical queries
+	defaultHeadersWindow = 8192
+	// MAX_REQUEST_LIGHT_CLIENT_UPDATES
+	maxRequestLightClientUpdates = 128
+)
+
+var ErrNoFinalizedHeader = errors.New("no finalized execution header yet")
+
+// UpdateSource serves the light client sync protocol objects of a beacon chain client,
+// BeaconApiSource reads them over the beacon API. None of them is trusted, everything
+// is verified by the Store against the trusted checkpoint.
+type UpdateSource interface {
+	GenesisValidatorsRoot(ctx context.Context) (libcommon.Hash, error)
+	Bootstrap(ctx context.Context, blockRoot libcommon.Hash) (*cltypes.LightClientBootstrap, error)
+	// Updates returns the best updates of the sync committee periods [startPeriod, startPeriod+count)
+	Updates(ctx context.Context, startPeriod, count uint64) ([]*cltypes.LightClientUpdate, error)
+	// FinalityUpdate returns the newest finality update, nil if there is none yet
+	FinalityUpdate(ctx context.Context) (*cltypes.LightClientUpdate, error)
+}
+
+// Header is the part of a finalized execution header needed to answer state queries.
+type Header struct {
+	Number        uint64
+	Hash          libcommon.Hash
+	ParentHash    libcommon.Hash
+	StateRoot     libcommon.Hash
+	Coinbase      libcommon.Address
+	PrevRandao    libcommon.Hash
+	Time          uint64
+	GasLimit      uint64
+	BaseFee       *big.Int
+	ExcessBlobGas *uint64
+}
+
+// EVMHeader builds the header used as block context of eth_call.
+func (h *Header) EVMHeader() *types.Header {
+	return &types.Header{
+		ParentHash:    h.ParentHash,
+		Coinbase:      h.Coinbase,
+		Root:          h.StateRoot,
+		Difficulty:    new(big.Int).Set(merge.ProofOfStakeDifficulty),
+		Number:        new(big.Int).SetUint64(h.Number),
+		GasLimit:      h.GasLimit,
+		Time:          h.Time,
+		MixDigest:     h.PrevRandao,
+		BaseFee:       h.BaseFee,
+		ExcessBlobGas: h.ExcessBlobGas,
+	}
+}
+
+func hashVectorToSlice(v solid.HashVectorSSZ) []libcommon.Hash {
+	if v == nil {
+		return nil
+	}
+	res := make([]libcommon.Hash, v.Length())
+	for i := range res {
+		res[i] = v.Get(i)
+	}
+	return res
+}
+
+func headerFromEth1Header(h *cltypes.Eth1Header, version clparams.StateVersion) *Header {
+	// BaseFeePerGas is little-endian in SSZ
+	baseFee := libcommon.Copy(h.BaseFeePerGas[:])
+	for i, j := 0, len(baseFee)-1; i < j; i, j = i+1, j-1 {
+		baseFee[i], baseFee[j] = baseFee[j], baseFee[i]
+	}
+	res := &Header{
+		Number:     h.BlockNumber,
+		Hash:       h.BlockHash,
+		ParentHash: h.ParentHash,
+		StateRoot:  h.StateRoot,
+		Coinbase:   h.FeeRecipient,
+		PrevRandao: h.PrevRandao,
+		Time:       h.Time,
+		GasLimit:   h.GasLimit,
+		BaseFee:    new(big.Int).SetBytes(baseFee),
+	}
+	if version >= clparams.DenebVersion {
+		excessBlobGas := h.ExcessBlobGas
+		res.ExcessBlobGas = &excessBlobGas
+	}
+	return res
+}
+
+// HeaderTracker follows the finalized execution headers of the beacon chain from a trusted
+// checkpoint. Only headers accepted by the light client Store are recorded.
+type HeaderTracker struct {
+	source     UpdateSource
+	cfg        *clparams.BeaconChainConfig
+	checkpoint libcommon.Hash
+	window     uint64
+	logger     log.Logger
+
+	updateMu sync.Mutex
+	store    *Store // nil until bootstrapped
+
+	mu        sync.RWMutex
+	finalized *Header
+	byNumber  map[uint64]*Header
+	byHash    map[libcommon.Hash]*Header
+}
+
+// NewHeaderTracker creates a tracker bootstrapping from the beacon block root checkpoint,
+// which must be trusted, e.g. a recent finalized block root taken from a block explorer.
+func NewHeaderTracker(source UpdateSource, cfg *clparams.BeaconChainConfig, checkpoint libcommon.Hash, logger log.Logger) *HeaderTracker {
+	return &HeaderTracker{
+		source:     source,
+		cfg:        cfg,
+		checkpoint: checkpoint,
+		window:     defaultHeadersWindow,
+		logger:     logger,
+		byNumber:   map[uint64]*Header{},
+		byHash:     map[libcommon.Hash]*Header{},
+	}
+}
+
+func (t *HeaderTracker) bootstrap(ctx context.Context) error {
+	genesisValidatorsRoot, err := t.source.GenesisValidatorsRoot(ctx)
+	if err != nil {
+		return fmt.Errorf("genesis validators root: %w", err)
+	}
+	bootstrap, err := t.source.Bootstrap(ctx, t.checkpoint)
+	if err != nil {
+		return fmt.Errorf("bootstrap: %w", err)
+	}
+	store, err := NewStore(t.cfg, genesisValidatorsRoot, t.checkpoint, bootstrap)
+	if err != nil {
+		return fmt.Errorf("bootstrap: %w", err)
+	}
+	t.store = store
+	t.logger.Info("[lightclient] bootstrapped", "checkpoint", t.checkpoint, "slot", store.Finalized().Beacon.Slot)
+	return nil
+}
+
+// Update bootstraps the store if needed, catches up with the sync committee periods up to the
+// newest finality update, applies it and records the finalized execution header.
+// It returns whether a new header was recorded.
+func (t *HeaderTracker) Update(ctx context.Context) (bool, error) {
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+	if t.store == nil {
+		if err := t.bootstrap(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	finalityUpdate, err := t.source.FinalityUpdate(ctx)
+	if err != nil {
+		return false, err
+	}
+	if finalityUpdate == nil {
+		return t.record(), nil
+	}
+	// The finality update is signed by the committee of its period, learn the committees
+	// up to it from the period updates first.
+	targetPeriod := t.cfg.SyncCommitteePeriod(finalityUpdate.SignatureSlot)
+	if period := t.store.Period(); period < targetPeriod || !t.store.HasNextSyncCommittee() {
+		count := min(max(targetPeriod, period)-period+1, maxRequestLightClientUpdates)
+		updates, err := t.source.Updates(ctx, period, count)
+		if err != nil {
+			return t.record(), fmt.Errorf("updates from period %d: %w", period, err)
+		}
+		for _, update := range updates {
+			if _, err := t.store.ProcessUpdate(update); err != nil {
+				return t.record(), fmt.Errorf("update of period %d: %w", t.cfg.SyncCommitteePeriod(update.SignatureSlot), err)
+			}
+		}
+	}
+	if _, err := t.store.ProcessUpdate(finalityUpdate); err != nil {
+		return t.record(), err
+	}
+	return t.record(), nil
+}
+
+// record stores the finalized execution header of the store, reporting whether it is new.
+func (t *HeaderTracker) record() bool {
+	if t.store == nil {
+		return false
+	}
+	finalized := t.store.Finalized()
+	header := headerFromEth1Header(finalized.ExecutionPayloadHeader, finalized.Version())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finalized != nil && header.Number <= t.finalized.Number {
+		return false
+	}
+	t.finalized = header
+	t.byNumber[header.Number] = header
+	t.byHash[header.Hash] = header
+	if header.Number >= t.window {
+		for n, h := range t.byNumber {
+			if n <= header.Number-t.window {
+				delete(t.byNumber, n)
+				delete(t.byHash, h.Hash)
+			}
+		}
+	}
+	return true
+}
+
+// Run polls the source until ctx is cancelled.
+func (t *HeaderTracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		updated, err := t.Update(ctx)
+		if err != nil {
+			t.logger.Warn("[lightclient] light client update failed", "err", err)
+		} else if updated {
+			finalized := t.Finalized()
+			t.logger.Info("[lightclient] new finalized header", "number", finalized.Number, "hash", finalized.Hash, "stateRoot", finalized.StateRoot)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Finalized returns the newest verified finalized header, nil until the first one arrives.
+func (t *HeaderTracker) Finalized() *Header {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.finalized
+}
+
+// HeaderByNumber returns a recorded finalized header. Finalized headers are only seen once per
+// epoch, so most block numbers are not available.
+func (t *HeaderTracker) HeaderByNumber(number uint64) *Header {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.byNumber[number]
+}
+
+func (t *HeaderTracker) HeaderByHash(hash libcommon.Hash) *Header {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.byHash[hash]
+}
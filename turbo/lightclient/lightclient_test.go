@@ -0,0 +1,458 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package lightclient
+
+import (
+	"context"
+	"math/big"
+	"math/bits"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/fork"
+	"github.com/erigontech/erigon/cl/utils"
+	"github.com/erigontech/erigon/cl/utils/bls"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/rpc"
+	ethapi2 "github.com/erigontech/erigon/turbo/adapter/ethapi"
+)
+
+var (
+	testEOA      = libcommon.HexToAddress("0x1000000000000000000000000000000000000001")
+	testContract = libcommon.HexToAddress("0x2000000000000000000000000000000000000002")
+	// PUSH1 0 SLOAD PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	testCode = hexutil.MustDecode("0x60005460005260206000f3")
+)
+
+func testAlloc() types.GenesisAlloc {
+	return types.GenesisAlloc{
+		testEOA: {Balance: big.NewInt(1_000_000), Nonce: 7},
+		testContract: {
+			Balance: big.NewInt(1),
+			Code:    testCode,
+			Storage: map[libcommon.Hash]libcommon.Hash{
+				{}:                         libcommon.HexToHash("0x2a"),
+				libcommon.HexToHash("0x1"): libcommon.HexToHash("0xff00"),
+			},
+		},
+	}
+}
+
+// merkleTree is a sparse tree of the given depth, the nodes not set are filled with their gindex.
+type merkleTree struct {
+	depth int
+	nodes map[uint64]libcommon.Hash
+}
+
+func (m *merkleTree) node(gindex uint64) libcommon.Hash {
+	if h, ok := m.nodes[gindex]; ok {
+		return h
+	}
+	if bits.Len64(gindex)-1 >= m.depth {
+		return libcommon.BigToHash(new(big.Int).SetUint64(gindex))
+	}
+	left, right := m.node(2*gindex), m.node(2*gindex+1)
+	return utils.Sha256(left[:], right[:])
+}
+
+func (m *merkleTree) branch(gindex uint64) []libcommon.Hash {
+	var res []libcommon.Hash
+	for ; gindex > 1; gindex /= 2 {
+		res = append(res, m.node(gindex^1))
+	}
+	return res
+}
+
+func setBranch(v solid.HashVectorSSZ, branch []libcommon.Hash) {
+	for i := range branch {
+		v.Set(i, branch[i])
+	}
+}
+
+func testBeaconConfig() *clparams.BeaconChainConfig {
+	cfg := clparams.MainnetBeaconConfig
+	return &cfg
+}
+
+var testGenesisValidatorsRoot = libcommon.HexToHash("0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95")
+
+// testHeader builds a light client header at slot of an execution block number with the given state root.
+func testHeader(t *testing.T, slot, number uint64, stateRoot libcommon.Hash) *cltypes.LightClientHeader {
+	t.Helper()
+	header := cltypes.NewLightClientHeader(clparams.DenebVersion)
+	payload := header.ExecutionPayloadHeader
+	payload.BlockNumber = number
+	payload.BlockHash = libcommon.BigToHash(new(big.Int).SetUint64(number))
+	payload.StateRoot = stateRoot
+	payload.GasLimit = 30_000_000
+	payload.Time = 1_700_000_000 + number*12
+	payload.BaseFeePerGas[0] = 7 // little-endian
+
+	payloadRoot, err := payload.HashSSZ()
+	require.NoError(t, err)
+	body := &merkleTree{depth: 4, nodes: map[uint64]libcommon.Hash{executionPayloadGindex: payloadRoot}}
+	setBranch(header.ExecutionBranch, body.branch(executionPayloadGindex))
+	header.Beacon.Slot = slot
+	header.Beacon.BodyRoot = body.node(1)
+	return header
+}
+
+// testCommittee is a sync committee made of a single key.
+type testCommittee struct {
+	key       *bls.PrivateKey
+	committee *solid.SyncCommittee
+}
+
+func newTestCommittee(t *testing.T, cfg *clparams.BeaconChainConfig) *testCommittee {
+	t.Helper()
+	key, err := bls.GenerateKey()
+	require.NoError(t, err)
+	var pubkey libcommon.Bytes48
+	copy(pubkey[:], bls.CompressPublicKey(key.PublicKey()))
+	keys := make([]libcommon.Bytes48, cfg.SyncCommitteeSize)
+	for i := range keys {
+		keys[i] = pubkey
+	}
+	committee := &solid.SyncCommittee{}
+	committee.SetCommittee(keys)
+	return &testCommittee{key: key, committee: committee}
+}
+
+// sign returns the aggregate of the first participants members signing attested at signatureSlot.
+func (c *testCommittee) sign(t *testing.T, cfg *clparams.BeaconChainConfig, attested *cltypes.BeaconBlockHeader, signatureSlot uint64, participants int) *cltypes.SyncAggregate {
+	t.Helper()
+	forkVersion := utils.Uint32ToBytes4(cfg.GetForkVersionByVersion(cfg.GetCurrentStateVersion((signatureSlot - 1) / cfg.SlotsPerEpoch)))
+	domain, err := fork.ComputeDomain(cfg.DomainSyncCommittee[:], forkVersion, testGenesisValidatorsRoot)
+	require.NoError(t, err)
+	signingRoot, err := fork.ComputeSigningRoot(attested, domain)
+	require.NoError(t, err)
+	signature := c.key.Sign(signingRoot[:]).Bytes()
+	signatures := make([][]byte, participants)
+	aggregate := &cltypes.SyncAggregate{}
+	for i := range signatures {
+		signatures[i] = signature
+		aggregate.SyncCommiteeBits[i/8] |= 1 << (i % 8)
+	}
+	signed, err := bls.AggregateSignatures(signatures)
+	require.NoError(t, err)
+	copy(aggregate.SyncCommiteeSignature[:], signed)
+	return aggregate
+}
+
+func testBootstrap(t *testing.T, header *cltypes.LightClientHeader, committee *testCommittee) (*cltypes.LightClientBootstrap, libcommon.Hash) {
+	t.Helper()
+	committeeRoot, err := committee.committee.HashSSZ()
+	require.NoError(t, err)
+	state := &merkleTree{depth: 6, nodes: map[uint64]libcommon.Hash{currentSyncCommitteeGindex: committeeRoot}}
+	header.Beacon.Root = state.node(1)
+	bootstrap := cltypes.NewLightClientBootstrap(clparams.DenebVersion)
+	bootstrap.Header = header
+	bootstrap.CurrentSyncCommittee = committee.committee
+	setBranch(bootstrap.CurrentSyncCommitteeBranch, state.branch(currentSyncCommitteeGindex))
+	root, err := header.Beacon.HashSSZ()
+	require.NoError(t, err)
+	return bootstrap, root
+}
+
+// testUpdate builds an update of finalized, attested at attestedSlot and signed by participants of
+// signer in the next slot. The next sync committee is announced if next is set.
+func testUpdate(t *testing.T, cfg *clparams.BeaconChainConfig, finalized *cltypes.LightClientHeader, attestedSlot uint64, next, signer *testCommittee, participants int) *cltypes.LightClientUpdate {
+	t.Helper()
+	finalizedRoot, err := finalized.Beacon.HashSSZ()
+	require.NoError(t, err)
+	state := &merkleTree{depth: 6, nodes: map[uint64]libcommon.Hash{finalizedRootGindex: finalizedRoot}}
+	update := cltypes.NewLightClientUpdate(clparams.DenebVersion)
+	if next != nil {
+		nextRoot, err := next.committee.HashSSZ()
+		require.NoError(t, err)
+		state.nodes[nextSyncCommitteeGindex] = nextRoot
+		update.NextSyncCommittee = next.committee
+		setBranch(update.NextSyncCommitteeBranch, state.branch(nextSyncCommitteeGindex))
+	}
+	update.AttestedHeader = testHeader(t, attestedSlot, finalized.ExecutionPayloadHeader.BlockNumber+64, libcommon.Hash{})
+	update.AttestedHeader.Beacon.Root = state.node(1)
+	update.FinalizedHeader = finalized
+	setBranch(update.FinalityBranch, state.branch(finalizedRootGindex))
+	update.SignatureSlot = attestedSlot + 1
+	update.SyncAggregate = signer.sign(t, cfg, update.AttestedHeader.Beacon, update.SignatureSlot, participants)
+	return update
+}
+
+func TestStore(t *testing.T) {
+	cfg := testBeaconConfig()
+	period := cfg.SlotsPerEpoch * cfg.EpochsPerSyncCommitteePeriod
+	full := int(cfg.SyncCommitteeSize)
+	committee0, committee1 := newTestCommittee(t, cfg), newTestCommittee(t, cfg)
+
+	bootstrap, root := testBootstrap(t, testHeader(t, 100, 100, libcommon.HexToHash("0x01")), committee0)
+	_, err := NewStore(cfg, testGenesisValidatorsRoot, libcommon.HexToHash("0xbad"), bootstrap)
+	require.ErrorContains(t, err, "trusted block root")
+	bootstrap.CurrentSyncCommittee = committee1.committee
+	_, err = NewStore(cfg, testGenesisValidatorsRoot, root, bootstrap)
+	require.ErrorContains(t, err, "current sync committee branch")
+	bootstrap.CurrentSyncCommittee = committee0.committee
+	store, err := NewStore(cfg, testGenesisValidatorsRoot, root, bootstrap)
+	require.NoError(t, err)
+	require.False(t, store.HasNextSyncCommittee())
+
+	// rejected updates leave the store untouched
+	finalized := testHeader(t, 150, 150, libcommon.HexToHash("0x02"))
+	update := testUpdate(t, cfg, finalized, 200, committee1, committee0, full)
+	update.FinalityBranch.Set(0, libcommon.Hash{})
+	_, err = store.ProcessUpdate(update)
+	require.ErrorContains(t, err, "finality branch")
+	update = testUpdate(t, cfg, finalized, 200, committee1, committee1, full)
+	_, err = store.ProcessUpdate(update)
+	require.ErrorContains(t, err, "signature")
+	update = testUpdate(t, cfg, finalized, 200, committee1, committee0, full*2/3-1)
+	_, err = store.ProcessUpdate(update)
+	require.ErrorContains(t, err, "participation")
+	update = testUpdate(t, cfg, finalized, 200, committee1, committee0, full)
+	update.FinalizedHeader.ExecutionPayloadHeader.StateRoot = libcommon.HexToHash("0x03")
+	_, err = store.ProcessUpdate(update)
+	require.ErrorContains(t, err, "execution branch")
+	update = testUpdate(t, cfg, testHeader(t, 150, 150, libcommon.HexToHash("0x02")), 200, committee1, committee0, full)
+	update.NextSyncCommitteeBranch.Set(0, libcommon.Hash{})
+	_, err = store.ProcessUpdate(update)
+	require.ErrorContains(t, err, "next sync committee branch")
+	require.EqualValues(t, 100, store.Finalized().Beacon.Slot)
+	require.False(t, store.HasNextSyncCommittee())
+
+	// 2/3 of the committee is enough
+	update = testUpdate(t, cfg, testHeader(t, 150, 150, libcommon.HexToHash("0x02")), 200, committee1, committee0, full*2/3+1)
+	updated, err := store.ProcessUpdate(update)
+	require.NoError(t, err)
+	require.True(t, updated)
+	require.EqualValues(t, 150, store.Finalized().Beacon.Slot)
+	require.True(t, store.HasNextSyncCommittee())
+
+	// older finalized headers are valid but don't advance the store
+	updated, err = store.ProcessUpdate(testUpdate(t, cfg, testHeader(t, 120, 120, libcommon.Hash{}), 200, nil, committee0, full))
+	require.NoError(t, err)
+	require.False(t, updated)
+
+	// the committee of the next period is the announced one
+	update = testUpdate(t, cfg, testHeader(t, period+10, period+10, libcommon.HexToHash("0x04")), period+20, nil, committee0, full)
+	_, err = store.ProcessUpdate(update)
+	require.ErrorContains(t, err, "signature")
+	update = testUpdate(t, cfg, testHeader(t, period+10, period+10, libcommon.HexToHash("0x04")), period+20, nil, committee1, full)
+	updated, err = store.ProcessUpdate(update)
+	require.NoError(t, err)
+	require.True(t, updated)
+	require.EqualValues(t, 1, store.Period())
+	require.True(t, store.current.Equal(committee1.committee))
+	require.False(t, store.HasNextSyncCommittee())
+
+	// no committee is known two periods ahead
+	update = testUpdate(t, cfg, testHeader(t, 2*period+10, 2*period+10, libcommon.Hash{}), 2*period+20, nil, committee1, full)
+	_, err = store.ProcessUpdate(update)
+	require.ErrorContains(t, err, "no sync committee known")
+}
+
+type fakeSource struct {
+	bootstrap      *cltypes.LightClientBootstrap
+	updates        []*cltypes.LightClientUpdate
+	finalityUpdate *cltypes.LightClientUpdate
+	cfg            *clparams.BeaconChainConfig
+}
+
+func (s *fakeSource) GenesisValidatorsRoot(context.Context) (libcommon.Hash, error) {
+	return testGenesisValidatorsRoot, nil
+}
+
+func (s *fakeSource) Bootstrap(_ context.Context, blockRoot libcommon.Hash) (*cltypes.LightClientBootstrap, error) {
+	return s.bootstrap, nil
+}
+
+func (s *fakeSource) Updates(_ context.Context, startPeriod, count uint64) ([]*cltypes.LightClientUpdate, error) {
+	var res []*cltypes.LightClientUpdate
+	for _, update := range s.updates {
+		if period := s.cfg.SyncCommitteePeriod(update.SignatureSlot); period >= startPeriod && period < startPeriod+count {
+			res = append(res, update)
+		}
+	}
+	return res, nil
+}
+
+func (s *fakeSource) FinalityUpdate(context.Context) (*cltypes.LightClientUpdate, error) {
+	return s.finalityUpdate, nil
+}
+
+func TestHeaderTracker(t *testing.T) {
+	cfg := testBeaconConfig()
+	period := cfg.SlotsPerEpoch * cfg.EpochsPerSyncCommitteePeriod
+	full := int(cfg.SyncCommitteeSize)
+	committee0, committee1 := newTestCommittee(t, cfg), newTestCommittee(t, cfg)
+	bootstrap, root := testBootstrap(t, testHeader(t, 100, 100, libcommon.HexToHash("0x01")), committee0)
+
+	ctx := context.Background()
+	source := &fakeSource{bootstrap: bootstrap, cfg: cfg}
+	_, err := NewHeaderTracker(source, cfg, libcommon.HexToHash("0xbad"), log.New()).Update(ctx)
+	require.ErrorContains(t, err, "trusted block root")
+
+	tracker := NewHeaderTracker(source, cfg, root, log.New())
+	tracker.window = 10
+
+	// the checkpoint is the first finalized header
+	updated, err := tracker.Update(ctx)
+	require.NoError(t, err)
+	require.True(t, updated)
+	finalized := tracker.Finalized()
+	require.EqualValues(t, 100, finalized.Number)
+	require.Equal(t, libcommon.HexToHash("0x01"), finalized.StateRoot)
+	require.EqualValues(t, 7, finalized.BaseFee.Uint64())
+	require.NotNil(t, finalized.ExcessBlobGas)
+	require.Equal(t, finalized, tracker.HeaderByHash(finalized.Hash))
+
+	// forged updates are not recorded
+	source.finalityUpdate = testUpdate(t, cfg, testHeader(t, 110, 110, libcommon.HexToHash("0x02")), 200, nil, committee1, full)
+	_, err = tracker.Update(ctx)
+	require.ErrorContains(t, err, "signature")
+	require.EqualValues(t, 100, tracker.Finalized().Number)
+
+	// headers out of the window are pruned
+	source.finalityUpdate = testUpdate(t, cfg, testHeader(t, 110, 110, libcommon.HexToHash("0x02")), 200, nil, committee0, full)
+	updated, err = tracker.Update(ctx)
+	require.NoError(t, err)
+	require.True(t, updated)
+	require.Nil(t, tracker.HeaderByNumber(100))
+	require.Nil(t, tracker.HeaderByHash(finalized.Hash))
+	require.NotNil(t, tracker.HeaderByNumber(110))
+
+	// the committee hand-over is learned from the period updates
+	source.finalityUpdate = testUpdate(t, cfg, testHeader(t, period+10, period+10, libcommon.HexToHash("0x03")), period+20, nil, committee1, full)
+	_, err = tracker.Update(ctx)
+	require.ErrorContains(t, err, "no sync committee known")
+	source.updates = []*cltypes.LightClientUpdate{testUpdate(t, cfg, testHeader(t, 120, 120, libcommon.HexToHash("0x04")), 300, committee1, committee0, full)}
+	updated, err = tracker.Update(ctx)
+	require.NoError(t, err)
+	require.True(t, updated)
+	require.EqualValues(t, period+10, tracker.Finalized().Number)
+	require.NotNil(t, tracker.HeaderByNumber(period+10))
+}
+
+// lyingProvider inflates balances and serves wrong code.
+type lyingProvider struct {
+	*MockProvider
+}
+
+func (p lyingProvider) GetProof(ctx context.Context, address libcommon.Address, storageKeys []libcommon.Hash, blockNumber uint64) (*accounts.AccProofResult, error) {
+	res, err := p.MockProvider.GetProof(ctx, address, storageKeys, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	res.Balance = (*hexutil.Big)(new(big.Int).Add(res.Balance.ToInt(), big.NewInt(1)))
+	for i := range res.StorageProof {
+		res.StorageProof[i].Value = (*hexutil.Big)(big.NewInt(0x1337))
+	}
+	return res, nil
+}
+
+func (p lyingProvider) GetCode(context.Context, libcommon.Address, uint64) ([]byte, error) {
+	return []byte{0x00}, nil
+}
+
+func (p lyingProvider) String() string { return "liar" }
+
+func newTestAPI(t *testing.T, providers ...ProofProvider) (*EthAPI, *MockProvider) {
+	t.Helper()
+	mock := NewMockProvider(testAlloc())
+	cfg := testBeaconConfig()
+	bootstrap, root := testBootstrap(t, testHeader(t, 100, 100, mock.StateRoot()), newTestCommittee(t, cfg))
+	tracker := NewHeaderTracker(&fakeSource{bootstrap: bootstrap, cfg: cfg}, cfg, root, log.New())
+	_, err := tracker.Update(context.Background())
+	require.NoError(t, err)
+	if len(providers) == 0 {
+		providers = []ProofProvider{mock}
+	}
+	return NewEthAPI(tracker, providers, params.TestChainConfig, 50_000_000, 5*time.Second, log.New()), mock
+}
+
+func TestEthAPI(t *testing.T) {
+	ctx := context.Background()
+	api, _ := newTestAPI(t)
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	number, err := api.BlockNumber(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 100, number)
+
+	balance, err := api.GetBalance(ctx, testEOA, latest)
+	require.NoError(t, err)
+	require.EqualValues(t, 1_000_000, balance.ToInt().Uint64())
+	nonce, err := api.GetTransactionCount(ctx, testEOA, rpc.BlockNumberOrHashWithNumber(100))
+	require.NoError(t, err)
+	require.EqualValues(t, 7, *nonce)
+
+	absent := libcommon.HexToAddress("0x3000000000000000000000000000000000000003")
+	balance, err = api.GetBalance(ctx, absent, latest)
+	require.NoError(t, err)
+	require.Zero(t, balance.ToInt().Sign())
+
+	code, err := api.GetCode(ctx, testContract, latest)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Bytes(testCode), code)
+
+	value, err := api.GetStorageAt(ctx, testContract, "0x1", latest)
+	require.NoError(t, err)
+	require.Equal(t, libcommon.HexToHash("0xff00").Hex(), value)
+	value, err = api.GetStorageAt(ctx, testContract, "0x5", latest)
+	require.NoError(t, err)
+	require.Equal(t, libcommon.Hash{}.Hex(), value)
+
+	_, err = api.GetBalance(ctx, testEOA, rpc.BlockNumberOrHashWithNumber(99))
+	require.Error(t, err)
+
+	res, err := api.Call(ctx, ethapi2.CallArgs{From: &testEOA, To: &testContract}, latest, nil)
+	require.NoError(t, err)
+	require.Equal(t, libcommon.HexToHash("0x2a").Bytes(), []byte(res))
+}
+
+func TestEthAPIRejectsInvalidProofs(t *testing.T) {
+	ctx := context.Background()
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	liar := lyingProvider{NewMockProvider(testAlloc())}
+	api, _ := newTestAPI(t, liar)
+	_, err := api.GetBalance(ctx, testEOA, latest)
+	require.ErrorIs(t, err, ErrNoValidProof)
+	_, err = api.Call(ctx, ethapi2.CallArgs{From: &testEOA, To: &testContract}, latest, nil)
+	require.ErrorIs(t, err, ErrNoValidProof)
+
+	// an honest provider behind the liar is used instead
+	api, mock := newTestAPI(t, liar, NewMockProvider(testAlloc()))
+	require.Equal(t, mock.StateRoot(), api.tracker.Finalized().StateRoot)
+	balance, err := api.GetBalance(ctx, testEOA, latest)
+	require.NoError(t, err)
+	require.EqualValues(t, 1_000_000, balance.ToInt().Uint64())
+	code, err := api.GetCode(ctx, testContract, latest)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Bytes(testCode), code)
+	res, err := api.Call(ctx, ethapi2.CallArgs{From: &testEOA, To: &testContract}, latest, nil)
+	require.NoError(t, err)
+	require.Equal(t, libcommon.HexToHash("0x2a").Bytes(), []byte(res))
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package lightclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/trie"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/core/types"
+)
+
+// MockProvider is an in-memory ProofProvider serving a single state, built from a genesis alloc.
+// It ignores the requested block number.
+type MockProvider struct {
+	accountTrie  *trie.Trie
+	storageTries map[libcommon.Address]*trie.Trie
+	alloc        types.GenesisAlloc
+	root         libcommon.Hash
+}
+
+func NewMockProvider(alloc types.GenesisAlloc) *MockProvider {
+	p := &MockProvider{
+		accountTrie:  trie.New(trie.EmptyRoot),
+		storageTries: map[libcommon.Address]*trie.Trie{},
+		alloc:        alloc,
+	}
+	for address, account := range alloc {
+		acc := accounts.NewAccount()
+		acc.Nonce = account.Nonce
+		if account.Balance != nil {
+			acc.Balance.SetFromBig(account.Balance)
+		}
+		acc.CodeHash = trie.EmptyCodeHash
+		if len(account.Code) > 0 {
+			acc.CodeHash = crypto.Keccak256Hash(account.Code)
+		}
+		acc.Root = trie.EmptyRoot
+		if len(account.Storage) > 0 {
+			storageTrie := trie.New(trie.EmptyRoot)
+			for key, value := range account.Storage {
+				v := uint256.NewInt(0).SetBytes(value[:])
+				if v.IsZero() {
+					continue
+				}
+				storageTrie.Update(crypto.Keccak256(key[:]), v.Bytes())
+			}
+			p.storageTries[address] = storageTrie
+			acc.Root = storageTrie.Hash()
+		}
+		p.accountTrie.UpdateAccount(crypto.Keccak256(address[:]), &acc)
+	}
+	p.root = p.accountTrie.Hash()
+	return p
+}
+
+// StateRoot is the root the served proofs verify against.
+func (p *MockProvider) StateRoot() libcommon.Hash { return p.root }
+
+func (p *MockProvider) GetProof(_ context.Context, address libcommon.Address, storageKeys []libcommon.Hash, _ uint64) (*accounts.AccProofResult, error) {
+	accountProof, err := p.accountTrie.Prove(crypto.Keccak256(address[:]), 0, false)
+	if err != nil {
+		return nil, err
+	}
+	res := &accounts.AccProofResult{
+		Address:      address,
+		AccountProof: toHexutilBytes(accountProof),
+		Balance:      new(hexutil.Big),
+		StorageProof: make([]accounts.StorProofResult, len(storageKeys)),
+	}
+	account, ok := p.alloc[address]
+	if ok {
+		if account.Balance != nil {
+			res.Balance = (*hexutil.Big)(new(big.Int).Set(account.Balance))
+		}
+		res.Nonce = hexutil.Uint64(account.Nonce)
+		res.CodeHash = trie.EmptyCodeHash
+		if len(account.Code) > 0 {
+			res.CodeHash = crypto.Keccak256Hash(account.Code)
+		}
+		res.StorageHash = trie.EmptyRoot
+		if storageTrie := p.storageTries[address]; storageTrie != nil {
+			res.StorageHash = storageTrie.Hash()
+		}
+	}
+	for i, key := range storageKeys {
+		res.StorageProof[i] = accounts.StorProofResult{Key: key.Hex(), Value: new(hexutil.Big)}
+		storageTrie := p.storageTries[address]
+		if storageTrie == nil {
+			continue
+		}
+		value := account.Storage[key]
+		res.StorageProof[i].Value = (*hexutil.Big)(new(big.Int).SetBytes(value[:]))
+		proof, err := storageTrie.Prove(crypto.Keccak256(key[:]), 0, false)
+		if err != nil {
+			return nil, err
+		}
+		res.StorageProof[i].Proof = toHexutilBytes(proof)
+	}
+	return res, nil
+}
+
+func (p *MockProvider) GetCode(_ context.Context, address libcommon.Address, _ uint64) ([]byte, error) {
+	return libcommon.Copy(p.alloc[address].Code), nil
+}
+
+func (p *MockProvider) String() string { return "mock" }
+
+func toHexutilBytes(proof [][]byte) []hexutil.Bytes {
+	res := make([]hexutil.Bytes, len(proof))
+	for i := range proof {
+		res[i] = proof[i]
+	}
+	return res
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package lightclient
+
+import (
+	"context"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/rpc"
+)
+
+// ProofProvider serves state with merkle proofs. Providers are not trusted: everything they
+// return is checked against a verified state root before use.
+type ProofProvider interface {
+	// GetProof returns the eth_getProof result for address and storageKeys at the given block.
+	GetProof(ctx context.Context, address libcommon.Address, storageKeys []libcommon.Hash, blockNumber uint64) (*accounts.AccProofResult, error)
+	// GetCode returns the code of address at the given block, checked against the proven code hash.
+	GetCode(ctx context.Context, address libcommon.Address, blockNumber uint64) ([]byte, error)
+	String() string
+}
+
+// RpcProvider is a ProofProvider backed by the JSON-RPC API of an execution client.
+type RpcProvider struct {
+	url    string
+	client *rpc.Client
+}
+
+func DialProvider(ctx context.Context, url string, logger log.Logger) (*RpcProvider, error) {
+	client, err := rpc.DialContext(ctx, url, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &RpcProvider{url: url, client: client}, nil
+}
+
+func (p *RpcProvider) GetProof(ctx context.Context, address libcommon.Address, storageKeys []libcommon.Hash, blockNumber uint64) (*accounts.AccProofResult, error) {
+	var result accounts.AccProofResult
+	if err := p.client.CallContext(ctx, &result, "eth_getProof", address, storageKeys, hexutil.Uint64(blockNumber)); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (p *RpcProvider) GetCode(ctx context.Context, address libcommon.Address, blockNumber uint64) ([]byte, error) {
+	var result hexutil.Bytes
+	if err := p.client.CallContext(ctx, &result, "eth_getCode", address, hexutil.Uint64(blockNumber)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (p *RpcProvider) String() string { return p.url }
+
+func (p *RpcProvider) Close() { p.client.Close() }
@@ -0,0 +1,220 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/trie"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/core/state"
+)
+
+var ErrNoValidProof = errors.New("no provider returned a valid proof")
+
+var _ state.StateReader = (*ProofStateReader)(nil)
+
+// ProofStateReader is a state.StateReader answering from eth_getProof results of untrusted
+// providers, each verified against the state root of a finalized header. Providers are tried
+// in order until one of them returns data that verifies.
+type ProofStateReader struct {
+	ctx       context.Context
+	header    *Header
+	providers []ProofProvider
+	logger    log.Logger
+
+	accounts map[libcommon.Address]*accounts.AccProofResult
+	storage  map[libcommon.Address]map[libcommon.Hash][]byte
+	code     map[libcommon.Hash][]byte
+}
+
+func NewProofStateReader(ctx context.Context, header *Header, providers []ProofProvider, logger log.Logger) *ProofStateReader {
+	return &ProofStateReader{
+		ctx:       ctx,
+		header:    header,
+		providers: providers,
+		logger:    logger,
+		accounts:  map[libcommon.Address]*accounts.AccProofResult{},
+		storage:   map[libcommon.Address]map[libcommon.Hash][]byte{},
+		code:      map[libcommon.Hash][]byte{},
+	}
+}
+
+func (r *ProofStateReader) proveAccount(address libcommon.Address) (*accounts.AccProofResult, error) {
+	if proof, ok := r.accounts[address]; ok {
+		return proof, nil
+	}
+	addrHash := crypto.Keccak256Hash(address[:])
+	var errs []error
+	for _, provider := range r.providers {
+		proof, err := provider.GetProof(r.ctx, address, nil, r.header.Number)
+		if err == nil {
+			if proof.Balance == nil {
+				err = errors.New("missing balance")
+			} else {
+				err = trie.VerifyAccountProofByHash(r.header.StateRoot, addrHash, proof)
+			}
+		}
+		if err != nil {
+			r.logger.Debug("[lightclient] rejected account proof", "provider", provider, "address", address, "block", r.header.Number, "err", err)
+			errs = append(errs, fmt.Errorf("%s: %w", provider, err))
+			continue
+		}
+		r.accounts[address] = proof
+		return proof, nil
+	}
+	return nil, fmt.Errorf("%w for account %x at block %d: %w", ErrNoValidProof, address, r.header.Number, errors.Join(errs...))
+}
+
+func isEmptyAccountProof(proof *accounts.AccProofResult) bool {
+	return proof.Nonce == 0 && proof.Balance.ToInt().Sign() == 0 &&
+		(proof.CodeHash == libcommon.Hash{} || proof.CodeHash == trie.EmptyCodeHash) &&
+		(proof.StorageHash == libcommon.Hash{} || proof.StorageHash == trie.EmptyRoot)
+}
+
+func (r *ProofStateReader) ReadAccountData(address libcommon.Address) (*accounts.Account, error) {
+	proof, err := r.proveAccount(address)
+	if err != nil {
+		return nil, err
+	}
+	if isEmptyAccountProof(proof) {
+		return nil, nil
+	}
+	acc := accounts.NewAccount()
+	acc.Nonce = uint64(proof.Nonce)
+	acc.Balance.SetFromBig(proof.Balance.ToInt())
+	acc.Root = proof.StorageHash
+	acc.CodeHash = proof.CodeHash
+	if acc.CodeHash == (libcommon.Hash{}) {
+		acc.CodeHash = trie.EmptyCodeHash
+	}
+	if acc.CodeHash != trie.EmptyCodeHash {
+		acc.Incarnation = 1
+	}
+	return &acc, nil
+}
+
+func (r *ProofStateReader) ReadAccountDataForDebug(address libcommon.Address) (*accounts.Account, error) {
+	return r.ReadAccountData(address)
+}
+
+func (r *ProofStateReader) ReadAccountStorage(address libcommon.Address, incarnation uint64, key *libcommon.Hash) ([]byte, error) {
+	if slots, ok := r.storage[address]; ok {
+		if v, ok := slots[*key]; ok {
+			return v, nil
+		}
+	}
+	accProof, err := r.proveAccount(address)
+	if err != nil {
+		return nil, err
+	}
+	var value []byte
+	if accProof.StorageHash != (libcommon.Hash{}) && accProof.StorageHash != trie.EmptyRoot {
+		if value, err = r.proveStorage(address, accProof.StorageHash, *key); err != nil {
+			return nil, err
+		}
+	}
+	if r.storage[address] == nil {
+		r.storage[address] = map[libcommon.Hash][]byte{}
+	}
+	r.storage[address][*key] = value
+	return value, nil
+}
+
+func (r *ProofStateReader) proveStorage(address libcommon.Address, storageRoot libcommon.Hash, key libcommon.Hash) ([]byte, error) {
+	keyHash := crypto.Keccak256Hash(key[:])
+	var errs []error
+	for _, provider := range r.providers {
+		proof, err := provider.GetProof(r.ctx, address, []libcommon.Hash{key}, r.header.Number)
+		if err == nil {
+			switch {
+			case len(proof.StorageProof) != 1:
+				err = fmt.Errorf("expected 1 storage proof, got %d", len(proof.StorageProof))
+			case proof.StorageHash != storageRoot:
+				err = fmt.Errorf("storage hash %x differs from the proven %x", proof.StorageHash, storageRoot)
+			case proof.StorageProof[0].Value == nil:
+				err = errors.New("missing storage value")
+			default:
+				err = trie.VerifyStorageProofByHash(storageRoot, keyHash, proof.StorageProof[0])
+			}
+		}
+		if err != nil {
+			r.logger.Debug("[lightclient] rejected storage proof", "provider", provider, "address", address, "key", key, "block", r.header.Number, "err", err)
+			errs = append(errs, fmt.Errorf("%s: %w", provider, err))
+			continue
+		}
+		value := proof.StorageProof[0].Value.ToInt()
+		if value.Sign() == 0 {
+			return nil, nil
+		}
+		v, overflow := uint256.FromBig(value)
+		if overflow {
+			return nil, fmt.Errorf("storage value of %x overflows", key)
+		}
+		return v.Bytes(), nil
+	}
+	return nil, fmt.Errorf("%w for storage %x of %x at block %d: %w", ErrNoValidProof, key, address, r.header.Number, errors.Join(errs...))
+}
+
+func (r *ProofStateReader) ReadAccountCode(address libcommon.Address, incarnation uint64) ([]byte, error) {
+	accProof, err := r.proveAccount(address)
+	if err != nil {
+		return nil, err
+	}
+	codeHash := accProof.CodeHash
+	if codeHash == (libcommon.Hash{}) || codeHash == trie.EmptyCodeHash {
+		return nil, nil
+	}
+	if code, ok := r.code[codeHash]; ok {
+		return code, nil
+	}
+	var errs []error
+	for _, provider := range r.providers {
+		code, err := provider.GetCode(r.ctx, address, r.header.Number)
+		if err == nil && crypto.Keccak256Hash(code) != codeHash {
+			err = fmt.Errorf("code hash %x differs from the proven %x", crypto.Keccak256Hash(code), codeHash)
+		}
+		if err != nil {
+			r.logger.Debug("[lightclient] rejected code", "provider", provider, "address", address, "block", r.header.Number, "err", err)
+			errs = append(errs, fmt.Errorf("%s: %w", provider, err))
+			continue
+		}
+		r.code[codeHash] = code
+		return code, nil
+	}
+	return nil, fmt.Errorf("%w for code of %x at block %d: %w", ErrNoValidProof, address, r.header.Number, errors.Join(errs...))
+}
+
+func (r *ProofStateReader) ReadAccountCodeSize(address libcommon.Address, incarnation uint64) (int, error) {
+	code, err := r.ReadAccountCode(address, incarnation)
+	return len(code), err
+}
+
+func (r *ProofStateReader) ReadAccountIncarnation(address libcommon.Address) (uint64, error) {
+	acc, err := r.ReadAccountData(address)
+	if err != nil || acc == nil {
+		return 0, err
+	}
+	return acc.Incarnation, nil
+}
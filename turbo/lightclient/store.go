@@ -0,0 +1,266 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package lightclient
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/fork"
+	"github.com/erigontech/erigon/cl/utils"
+	"github.com/erigontech/erigon/cl/utils/bls"
+)
+
+// Generalized indices of the light client proofs, see the altair and electra light client specs.
+const (
+	finalizedRootGindex               = 105
+	finalizedRootGindexElectra        = 169
+	currentSyncCommitteeGindex        = 54
+	currentSyncCommitteeGindexElectra = 86
+	nextSyncCommitteeGindex           = 55
+	nextSyncCommitteeGindexElectra    = 87
+	// execution_payload in BeaconBlockBody, the same from capella to electra
+	executionPayloadGindex = 25
+)
+
+func finalizedRootGindexAt(version clparams.StateVersion) uint64 {
+	if version >= clparams.ElectraVersion {
+		return finalizedRootGindexElectra
+	}
+	return finalizedRootGindex
+}
+
+func currentSyncCommitteeGindexAt(version clparams.StateVersion) uint64 {
+	if version >= clparams.ElectraVersion {
+		return currentSyncCommitteeGindexElectra
+	}
+	return currentSyncCommitteeGindex
+}
+
+func nextSyncCommitteeGindexAt(version clparams.StateVersion) uint64 {
+	if version >= clparams.ElectraVersion {
+		return nextSyncCommitteeGindexElectra
+	}
+	return nextSyncCommitteeGindex
+}
+
+// isValidBranch checks that branch proves leaf at the generalized index gindex of root.
+// The depth comes from gindex, so a branch of another length is rejected.
+func isValidBranch(leaf libcommon.Hash, branch solid.HashVectorSSZ, gindex uint64, root libcommon.Hash) bool {
+	depth := uint64(bits.Len64(gindex) - 1)
+	if branch == nil || uint64(branch.Length()) != depth {
+		return false
+	}
+	return utils.IsValidMerkleBranch(leaf, hashVectorToSlice(branch), depth, gindex-(1<<depth), root)
+}
+
+// verifyLightClientHeader checks that the execution payload header is committed to by the beacon
+// block body, as in is_valid_light_client_header.
+func verifyLightClientHeader(header *cltypes.LightClientHeader) error {
+	if header == nil || header.Beacon == nil {
+		return errors.New("incomplete light client header")
+	}
+	if header.Version() < clparams.CapellaVersion || header.ExecutionPayloadHeader == nil {
+		return fmt.Errorf("header has no execution payload header, version %s", clparams.ClVersionToString(header.Version()))
+	}
+	payloadRoot, err := header.ExecutionPayloadHeader.HashSSZ()
+	if err != nil {
+		return err
+	}
+	if !isValidBranch(payloadRoot, header.ExecutionBranch, executionPayloadGindex, header.Beacon.BodyRoot) {
+		return errors.New("invalid execution branch")
+	}
+	return nil
+}
+
+func isEmptySyncCommittee(committee *solid.SyncCommittee) bool {
+	return committee == nil || committee.Equal(&solid.SyncCommittee{})
+}
+
+// Store is the light client store of the sync protocol. It starts from a bootstrap of a trusted
+// block root and only accepts finalized headers signed by at least 2/3 of the sync committee of
+// their period, following the committee hand-overs announced by the updates.
+type Store struct {
+	cfg                   *clparams.BeaconChainConfig
+	genesisValidatorsRoot libcommon.Hash
+
+	finalized *cltypes.LightClientHeader
+	current   *solid.SyncCommittee
+	next      *solid.SyncCommittee // nil until an update of the current period announces it
+}
+
+// NewStore initializes the store from the bootstrap of trustedRoot, as initialize_light_client_store.
+func NewStore(cfg *clparams.BeaconChainConfig, genesisValidatorsRoot, trustedRoot libcommon.Hash, bootstrap *cltypes.LightClientBootstrap) (*Store, error) {
+	if bootstrap == nil || bootstrap.Header == nil || bootstrap.Header.Beacon == nil || bootstrap.CurrentSyncCommittee == nil {
+		return nil, errors.New("incomplete light client bootstrap")
+	}
+	if err := verifyLightClientHeader(bootstrap.Header); err != nil {
+		return nil, err
+	}
+	root, err := bootstrap.Header.Beacon.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	if root != trustedRoot {
+		return nil, fmt.Errorf("bootstrap header root %x doesn't match the trusted block root %x", root, trustedRoot)
+	}
+	committeeRoot, err := bootstrap.CurrentSyncCommittee.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	if !isValidBranch(committeeRoot, bootstrap.CurrentSyncCommitteeBranch, currentSyncCommitteeGindexAt(bootstrap.Header.Version()), bootstrap.Header.Beacon.Root) {
+		return nil, errors.New("invalid current sync committee branch")
+	}
+	return &Store{
+		cfg:                   cfg,
+		genesisValidatorsRoot: genesisValidatorsRoot,
+		finalized:             bootstrap.Header,
+		current:               bootstrap.CurrentSyncCommittee.Copy(),
+	}, nil
+}
+
+// Finalized returns the newest finalized header accepted by the store.
+func (s *Store) Finalized() *cltypes.LightClientHeader {
+	return s.finalized
+}
+
+// Period returns the sync committee period of the finalized header.
+func (s *Store) Period() uint64 {
+	return s.cfg.SyncCommitteePeriod(s.finalized.Beacon.Slot)
+}
+
+// HasNextSyncCommittee reports whether the committee of the next period is known.
+func (s *Store) HasNextSyncCommittee() bool {
+	return s.next != nil
+}
+
+// ProcessUpdate validates update as validate_light_client_update and applies it. Only updates
+// with a finalized header are accepted. It returns whether the finalized header advanced.
+func (s *Store) ProcessUpdate(update *cltypes.LightClientUpdate) (bool, error) {
+	if update == nil || update.AttestedHeader == nil || update.AttestedHeader.Beacon == nil ||
+		update.FinalizedHeader == nil || update.FinalizedHeader.Beacon == nil || update.SyncAggregate == nil {
+		return false, errors.New("incomplete light client update")
+	}
+	attested, finalized := update.AttestedHeader, update.FinalizedHeader
+
+	participants := update.SyncAggregate.Sum()
+	if participants < int(s.cfg.MinSyncCommitteeParticipants) || uint64(participants)*3 < s.cfg.SyncCommitteeSize*2 {
+		return false, fmt.Errorf("insufficient sync committee participation %d/%d", participants, s.cfg.SyncCommitteeSize)
+	}
+	if update.SignatureSlot <= attested.Beacon.Slot || attested.Beacon.Slot < finalized.Beacon.Slot {
+		return false, fmt.Errorf("inconsistent slots: signature %d, attested %d, finalized %d", update.SignatureSlot, attested.Beacon.Slot, finalized.Beacon.Slot)
+	}
+
+	storePeriod := s.Period()
+	var committee *solid.SyncCommittee
+	switch signaturePeriod := s.cfg.SyncCommitteePeriod(update.SignatureSlot); {
+	case signaturePeriod == storePeriod:
+		committee = s.current
+	case signaturePeriod == storePeriod+1 && s.next != nil:
+		committee = s.next
+	default:
+		return false, fmt.Errorf("no sync committee known for period %d, store period %d", signaturePeriod, storePeriod)
+	}
+
+	if err := verifyLightClientHeader(attested); err != nil {
+		return false, fmt.Errorf("attested header: %w", err)
+	}
+	if err := verifyLightClientHeader(finalized); err != nil {
+		return false, fmt.Errorf("finalized header: %w", err)
+	}
+	finalizedRoot, err := finalized.Beacon.HashSSZ()
+	if err != nil {
+		return false, err
+	}
+	if !isValidBranch(finalizedRoot, update.FinalityBranch, finalizedRootGindexAt(attested.Version()), attested.Beacon.Root) {
+		return false, errors.New("invalid finality branch")
+	}
+
+	attestedPeriod := s.cfg.SyncCommitteePeriod(attested.Beacon.Slot)
+	hasNext := !isEmptySyncCommittee(update.NextSyncCommittee)
+	if hasNext {
+		if attestedPeriod == storePeriod && s.next != nil && !s.next.Equal(update.NextSyncCommittee) {
+			return false, errors.New("next sync committee conflicts with the known one")
+		}
+		nextRoot, err := update.NextSyncCommittee.HashSSZ()
+		if err != nil {
+			return false, err
+		}
+		if !isValidBranch(nextRoot, update.NextSyncCommitteeBranch, nextSyncCommitteeGindexAt(attested.Version()), attested.Beacon.Root) {
+			return false, errors.New("invalid next sync committee branch")
+		}
+	}
+
+	if err := s.verifySyncAggregate(committee, update.SyncAggregate, attested, update.SignatureSlot); err != nil {
+		return false, err
+	}
+
+	// The update is valid, apply it as apply_light_client_update.
+	if hasNext && s.next == nil && attestedPeriod == storePeriod {
+		s.next = update.NextSyncCommittee.Copy()
+	}
+	if finalized.Beacon.Slot <= s.finalized.Beacon.Slot {
+		return false, nil
+	}
+	switch finalizedPeriod := s.cfg.SyncCommitteePeriod(finalized.Beacon.Slot); {
+	case finalizedPeriod == storePeriod:
+	case finalizedPeriod == storePeriod+1 && s.next != nil:
+		s.current, s.next = s.next, nil
+		if hasNext && attestedPeriod == finalizedPeriod {
+			s.next = update.NextSyncCommittee.Copy()
+		}
+	default:
+		return false, fmt.Errorf("finalized period %d skips the sync committee of period %d", finalizedPeriod, storePeriod+1)
+	}
+	s.finalized = finalized
+	return true, nil
+}
+
+// verifySyncAggregate checks the signature of the participants of committee over the attested
+// beacon header, signed in the fork of the slot before the signature slot.
+func (s *Store) verifySyncAggregate(committee *solid.SyncCommittee, aggregate *cltypes.SyncAggregate, attested *cltypes.LightClientHeader, signatureSlot uint64) error {
+	keys := committee.GetCommittee()
+	pubkeys := make([][]byte, 0, len(keys))
+	for i := range keys {
+		if aggregate.IsSet(uint64(i)) {
+			pubkeys = append(pubkeys, keys[i][:])
+		}
+	}
+	epoch := (max(signatureSlot, 1) - 1) / s.cfg.SlotsPerEpoch
+	forkVersion := utils.Uint32ToBytes4(s.cfg.GetForkVersionByVersion(s.cfg.GetCurrentStateVersion(epoch)))
+	domain, err := fork.ComputeDomain(s.cfg.DomainSyncCommittee[:], forkVersion, s.genesisValidatorsRoot)
+	if err != nil {
+		return err
+	}
+	signingRoot, err := fork.ComputeSigningRoot(attested.Beacon, domain)
+	if err != nil {
+		return err
+	}
+	valid, err := bls.VerifyAggregate(aggregate.SyncCommiteeSignature[:], signingRoot[:], pubkeys)
+	if err != nil {
+		return fmt.Errorf("sync committee signature: %w", err)
+	}
+	if !valid {
+		return errors.New("invalid sync committee signature")
+	}
+	return nil
+}
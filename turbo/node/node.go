@@ -98,6 +98,10 @@ func NewNodConfigUrfave(ctx *cli.Context, logger log.Logger) (*nodecfg.Config, e
 		logger.Info("Starting Erigon on Hoodi testnet...")
 	case networkname.Dev:
 		logger.Info("Starting Erigon in ephemeral dev mode...")
+	case networkname.QBFTDevnet:
+		logger.Info("Starting Erigon on a qbft devnet...")
+	case networkname.CliqueDevnet:
+		logger.Info("Starting Erigon on a clique devnet...")
 	case networkname.Amoy:
 		logger.Info("Starting Erigon on Amoy testnet...")
 	case networkname.BorMainnet:
@@ -26,6 +26,7 @@ import (
 
 	"github.com/erigontech/erigon-lib/chain"
 	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/diagnostics"
 	"github.com/erigontech/erigon-lib/direct"
 	"github.com/erigontech/erigon-lib/gointerfaces"
 	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
@@ -55,7 +56,8 @@ import (
 // 3.1.0 - add Subscribe to logs
 // 3.2.0 - add EngineGetBlobsBundleV1k
 // 3.3.0 - merge EngineGetBlobsBundleV1 into EngineGetPayload
-var EthBackendAPIVersion = &types2.VersionReply{Major: 3, Minor: 3, Patch: 0}
+// 3.4.0 - add sync ETA and bottleneck to SyncingReply
+var EthBackendAPIVersion = &types2.VersionReply{Major: 3, Minor: 4, Patch: 0}
 
 type EthBackendServer struct {
 	remote.UnimplementedETHBACKENDServer // must be embedded to have forward compatible implementations.
@@ -162,6 +164,17 @@ func (s *EthBackendServer) Syncing(ctx context.Context, _ *emptypb.Empty) (*remo
 		return reply, nil
 	}
 
+	syncETA := diagnostics.Client().SyncETA()
+	stagesETA := make(map[string]diagnostics.StageETA, len(syncETA.Stages))
+	for _, eta := range syncETA.Stages {
+		stagesETA[eta.Stage] = eta
+	}
+	if len(syncETA.Stages) > 0 { // diagnostics are enabled
+		reply.Eta = syncETA.ETA
+		reply.EtaEstimated = syncETA.Estimated
+		reply.Bottleneck = string(syncETA.Bottleneck)
+	}
+
 	reply.Stages = make([]*remote.SyncingReply_StageProgress, len(stages.AllStages))
 	for i, stage := range stages.AllStages {
 		progress, err := stages.GetStageProgress(tx, stage)
@@ -171,6 +184,10 @@ func (s *EthBackendServer) Syncing(ctx context.Context, _ *emptypb.Empty) (*remo
 		reply.Stages[i] = &remote.SyncingReply_StageProgress{}
 		reply.Stages[i].StageName = string(stage)
 		reply.Stages[i].BlockNumber = progress
+		if eta, ok := stagesETA[string(stage)]; ok {
+			reply.Stages[i].Eta = eta.ETA
+			reply.Stages[i].EtaEstimated = eta.Estimated
+		}
 	}
 
 	return reply, nil
@@ -500,6 +500,7 @@ type BlockSnapshots interface {
 	SegmentsMax() uint64
 	SegmentsMin() uint64
 	Delete(fileName string) error
+	RemoveOldFiles(filesToRemove []string)
 	Types() []snaptype.Type
 	Close()
 	SetSegmentsMin(uint64)
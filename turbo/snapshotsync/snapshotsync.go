@@ -39,6 +39,7 @@ import (
 	"github.com/erigontech/erigon-lib/state"
 	"google.golang.org/grpc"
 
+	"github.com/erigontech/erigon/core/rawdb"
 	coresnaptype "github.com/erigontech/erigon/core/snaptype"
 	"github.com/erigontech/erigon/eth/ethconfig"
 )
@@ -215,6 +216,41 @@ func buildBlackListForPruning(pruneMode bool, stepPrune, minBlockToDownload, blo
 	return blackList, nil
 }
 
+// buildBlackListForExpiry - bodies and transactions segments below the history expiry horizon are
+// neither downloaded nor seeded. Before execution catches up, the block the state files end at is
+// unknown, so one more merged range is kept above the horizon; expiredTo is the horizon already
+// applied by the snapshots stage.
+func buildBlackListForExpiry(expiry prune.BlockAmount, expiredTo uint64, preverified snapcfg.Preverified) map[string]struct{} {
+	blackList := make(map[string]struct{})
+	if expiry == nil || !expiry.Enabled() {
+		return blackList
+	}
+	var maxBlock uint64
+	for _, p := range preverified {
+		info, _, ok := snaptype.ParseFileName("", p.Name)
+		if ok && info.Type != nil && info.Type.Enum() == coresnaptype.Enums.Headers {
+			maxBlock = max(maxBlock, info.To)
+		}
+	}
+	expireTo := expiry.PruneTo(maxBlock)
+	if expireTo > snaptype.Erigon2MergeLimit {
+		expireTo -= snaptype.Erigon2MergeLimit
+	} else {
+		expireTo = 0
+	}
+	expireTo = max(expireTo, expiredTo)
+	for _, p := range preverified {
+		info, _, ok := snaptype.ParseFileName("", p.Name)
+		if !ok || info.Type == nil || info.To > expireTo {
+			continue
+		}
+		if e := info.Type.Enum(); e == coresnaptype.Enums.Bodies || e == coresnaptype.Enums.Transactions {
+			blackList[p.Name] = struct{}{}
+		}
+	}
+	return blackList
+}
+
 type blockReader interface {
 	Snapshots() BlockSnapshots
 	BorSnapshots() BlockSnapshots
@@ -330,6 +366,16 @@ func WaitForDownloader(ctx context.Context, logPrefix string, dirs datadir.Dirs,
 		}
 	}
 
+	if !headerchain {
+		expiredTo, err := rawdb.ReadHistoryExpiredTo(tx)
+		if err != nil {
+			return err
+		}
+		for name := range buildBlackListForExpiry(prune.Expiry, expiredTo, preverifiedBlockSnapshots) {
+			blackListForPruning[name] = struct{}{}
+		}
+	}
+
 	// build all download requests
 	for _, p := range preverifiedBlockSnapshots {
 		if caplin == NoCaplin && (strings.Contains(p.Name, "beaconblocks") || strings.Contains(p.Name, "blobsidecars") || strings.Contains(p.Name, "caplin")) {
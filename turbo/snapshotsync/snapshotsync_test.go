@@ -17,11 +17,13 @@
 package snapshotsync
 
 import (
+	"math"
 	"strings"
 	"testing"
 
 	"github.com/erigontech/erigon-lib/chain/snapcfg"
 	"github.com/erigontech/erigon-lib/downloader/snaptype"
+	"github.com/erigontech/erigon-lib/kv/prune"
 )
 
 func TestBlackListForPruning(t *testing.T) {
@@ -57,3 +59,47 @@ func TestBlackListForPruning(t *testing.T) {
 	}
 
 }
+
+func TestBlackListForExpiry(t *testing.T) {
+	preverified := snapcfg.Mainnet
+
+	blackList := buildBlackListForExpiry(prune.Distance(math.MaxUint64), 0, preverified)
+	if len(blackList) != 0 {
+		t.Errorf("Should not expire anything without expiry, got %d files", len(blackList))
+	}
+
+	blackList = buildBlackListForExpiry(prune.Distance(1_000_000), 0, preverified)
+	if len(blackList) == 0 {
+		t.Fatal("Should have expired old segments")
+	}
+	var maxBlock uint64
+	for _, p := range preverified {
+		if info, _, ok := snaptype.ParseFileName("", p.Name); ok && strings.Contains(p.Name, "headers") {
+			maxBlock = max(maxBlock, info.To)
+		}
+	}
+	for p := range blackList {
+		info, _, ok := snaptype.ParseFileName("", p)
+		if !ok {
+			t.Fatalf("unexpected file %s", p)
+		}
+		if !strings.Contains(p, "bodies") && !strings.Contains(p, "transactions") {
+			t.Errorf("Should not have expired %s", p)
+		}
+		if info.To+1_000_000+snaptype.Erigon2MergeLimit > maxBlock {
+			t.Errorf("Should not have expired recent %s", p)
+		}
+	}
+	if _, ok := blackList["v1-000000-000500-bodies.seg"]; !ok {
+		t.Error("Should have expired the first bodies segment")
+	}
+
+	// the horizon applied by the snapshots stage is never downloaded again
+	blackList = buildBlackListForExpiry(prune.Distance(maxBlock), 1_000_000, preverified)
+	if _, ok := blackList["v1-000500-001000-transactions.seg"]; !ok {
+		t.Error("Should have expired segments below the applied horizon")
+	}
+	if _, ok := blackList["v1-001000-001500-transactions.seg"]; ok {
+		t.Error("Should not have expired segments above the applied horizon")
+	}
+}
@@ -72,6 +72,7 @@ import (
 	"github.com/erigontech/erigon/eth/protocols/eth"
 	"github.com/erigontech/erigon/eth/stagedsync"
 	"github.com/erigontech/erigon/eth/stagedsync/stages"
+	"github.com/erigontech/erigon/eth/tracers"
 	"github.com/erigontech/erigon/p2p"
 	"github.com/erigontech/erigon/p2p/sentry"
 	"github.com/erigontech/erigon/p2p/sentry/sentry_multi_client"
@@ -153,9 +154,12 @@ func (ms *MockSentry) Close() {
 	if ms.DB != nil {
 		ms.DB.Close()
 	}
-	if err := ms.bgComponentsEg.Wait(); err != nil {
+	if err := ms.bgComponentsEg.Wait(); err != nil && ms.tb != nil {
 		require.Equal(ms.tb, context.Canceled, err) // upon waiting for clean exit we should get ctx cancelled
 	}
+	if ms.tb == nil {
+		_ = os.RemoveAll(ms.Dirs.DataDir)
+	}
 }
 
 // Stream returns stream, waiting if necessary
@@ -244,8 +248,18 @@ func MockWithGenesis(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateKey,
 }
 
 func MockWithGenesisEngine(tb testing.TB, gspec *types.Genesis, engine consensus.Engine, withPosDownloader, checkStateRoot bool) *MockSentry {
+	return MockWithGenesisEngineVMConfig(tb, gspec, engine, &vm.Config{}, withPosDownloader, checkStateRoot)
+}
+
+// MockWithGenesisEngineVMConfig - blocks are executed with given vm.Config (for example with tracer)
+func MockWithGenesisEngineVMConfig(tb testing.TB, gspec *types.Genesis, engine consensus.Engine, vmConfig *vm.Config, withPosDownloader, checkStateRoot bool) *MockSentry {
 	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
-	return MockWithEverything(tb, gspec, key, prune.DefaultMode, engine, blockBufferSize, false, withPosDownloader, checkStateRoot)
+	return mockWithEverything(tb, gspec, key, prune.DefaultMode, engine, vmConfig, nil, blockBufferSize, false, withPosDownloader, checkStateRoot)
+}
+
+// MockWithGenesisSyncConfig - blocks are executed with given sync settings (for example with Block-STM)
+func MockWithGenesisSyncConfig(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateKey, syncCfg ethconfig.Sync) *MockSentry {
+	return mockWithEverything(tb, gspec, key, prune.DefaultMode, ethash.NewFaker(), &vm.Config{}, &syncCfg, blockBufferSize, false, false, true)
 }
 
 func MockWithGenesisPruneMode(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateKey, blockBufferSize int, prune prune.Mode, withPosDownloader bool) *MockSentry {
@@ -265,15 +279,30 @@ func MockWithGenesisPruneMode(tb testing.TB, gspec *types.Genesis, key *ecdsa.Pr
 func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateKey, prune prune.Mode,
 	engine consensus.Engine, blockBufferSize int, withTxPool, withPosDownloader, checkStateRoot bool,
 ) *MockSentry {
-	tmpdir := os.TempDir()
+	return mockWithEverything(tb, gspec, key, prune, engine, &vm.Config{}, nil, blockBufferSize, withTxPool, withPosDownloader, checkStateRoot)
+}
+
+func mockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateKey, prune prune.Mode,
+	engine consensus.Engine, vmConfig *vm.Config, syncCfg *ethconfig.Sync, blockBufferSize int, withTxPool, withPosDownloader, checkStateRoot bool,
+) *MockSentry {
+	var tmpdir string
 	if tb != nil {
 		tmpdir = tb.TempDir()
+	} else {
+		// without tb (e.g. outside of `go test`) the directory is removed by Close
+		var err error
+		if tmpdir, err = os.MkdirTemp("", "mock-sentry-"); err != nil {
+			panic(err)
+		}
 	}
 	ctrl := gomock.NewController(tb)
 	dirs := datadir.New(tmpdir)
 	var err error
 
 	cfg := ethconfig.Defaults
+	if syncCfg != nil {
+		cfg.Sync = *syncCfg
+	}
 	cfg.StateStream = true
 	cfg.BatchSize = 1 * datasize.MB
 	cfg.Sync.BodyDownloadTimeoutSeconds = 10
@@ -474,9 +503,10 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 	mock.PendingBlocks = miner.PendingResultCh
 	mock.MinedBlocks = miner.MiningResultCh
 	// proof-of-stake mining
-	assembleBlockPOS := func(param *core.BlockBuilderParameters, interrupt *int32) (*types.BlockWithReceipts, error) {
+	assembleBlockPOS := func(param *core.BlockBuilderParameters, interrupt *int32, report *builder.PayloadReport) (*types.BlockWithReceipts, error) {
 		miningStatePos := stagedsync.NewMiningState(&cfg.Miner)
 		miningStatePos.MiningConfig.Etherbase = param.SuggestedFeeRecipient
+		miningStatePos.Report = report
 		proposingSync := stagedsync.New(
 			cfg.Sync,
 			stagedsync.MiningStages(mock.Ctx,
@@ -498,6 +528,7 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 					mock.gspec,
 					cfg.Sync,
 					nil,
+					nil,
 				),
 				stagedsync.StageSendersCfg(mock.DB, mock.ChainConfig, cfg.Sync, false, dirs.Tmp, prune, mock.BlockReader, mock.sentriesClient.Hd),
 				stagedsync.StageMiningExecCfg(mock.DB, miner, nil, *mock.ChainConfig, mock.Engine, &vm.Config{}, dirs.Tmp, nil, 0, mock.TxPool, mock.BlockReader),
@@ -526,7 +557,7 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 			cfg.BatchSize,
 			mock.ChainConfig,
 			mock.Engine,
-			&vm.Config{},
+			vmConfig,
 			mock.Notifications,
 			cfg.StateStream,
 			/*stateStream=*/ false,
@@ -536,6 +567,7 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 			mock.gspec,
 			cfg.Sync,
 			nil,
+			nil,
 		), stagedsync.StageTxLookupCfg(mock.DB, prune, dirs.Tmp, mock.ChainConfig.Bor, mock.BlockReader), stagedsync.StageFinishCfg(mock.DB, dirs.Tmp, forkValidator), !withPosDownloader),
 		stagedsync.DefaultUnwindOrder,
 		stagedsync.DefaultPruneOrder,
@@ -543,8 +575,12 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 	)
 
 	cfg.Genesis = gspec
+	var tracer *tracers.Tracer
+	if vmConfig.Tracer != nil {
+		tracer = &tracers.Tracer{Hooks: vmConfig.Tracer}
+	}
 	pipelineStages := stages2.NewPipelineStages(mock.Ctx, db, &cfg, p2p.Config{}, mock.sentriesClient, mock.Notifications,
-		snapDownloader, mock.BlockReader, blockRetire, nil, forkValidator, logger, nil, checkStateRoot)
+		snapDownloader, mock.BlockReader, blockRetire, nil, forkValidator, logger, tracer, checkStateRoot, nil)
 	mock.posStagedSync = stagedsync.New(cfg.Sync, pipelineStages, stagedsync.PipelineUnwindOrder, stagedsync.PipelinePruneOrder, logger, stages.ModeApplyingBlocks)
 
 	mock.Eth1ExecutionService = eth1.NewEthereumExecutionModule(mock.BlockReader, mock.DB, mock.posStagedSync, forkValidator, mock.ChainConfig, assembleBlockPOS, nil, mock.Notifications.Accumulator, mock.Notifications.RecentLogs, mock.Notifications.StateChangesConsumer, logger, engine, cfg.Sync, ctx)
@@ -572,6 +608,7 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 				mock.gspec,
 				cfg.Sync,
 				nil,
+				nil,
 			),
 			stagedsync.StageSendersCfg(mock.DB, mock.ChainConfig, cfg.Sync, false, dirs.Tmp, prune, mock.BlockReader, mock.sentriesClient.Hd),
 			stagedsync.StageMiningExecCfg(mock.DB, miner, nil, *mock.ChainConfig, mock.Engine, &vm.Config{}, dirs.Tmp, nil, 0, mock.TxPool, mock.BlockReader),
@@ -83,7 +83,7 @@ type Pool interface {
 	FilterKnownIdHashes(tx kv.Tx, hashes Hashes) (unknownHashes Hashes, err error)
 	Started() bool
 	GetRlp(tx kv.Tx, hash []byte) ([]byte, error)
-	GetBlobs(blobhashes []common.Hash) ([][]byte, [][]byte)
+	GetBlobs(blobhashes []common.Hash) ([][]byte, [][]byte, [][]byte)
 	AddNewGoodPeer(peerID PeerID)
 }
 
@@ -142,6 +142,8 @@ type TxPool struct {
 	isPostCancun            atomic.Bool
 	pragueTime              *uint64
 	isPostPrague            atomic.Bool
+	osakaTime               *uint64
+	isPostOsaka             atomic.Bool
 	blobSchedule            *chain.BlobSchedule
 	feeCalculator           FeeCalculator
 	p2pFetcher              *Fetch
@@ -177,6 +179,7 @@ func New(
 	agraBlock *big.Int,
 	cancunTime *big.Int,
 	pragueTime *big.Int,
+	osakaTime *big.Int,
 	blobSchedule *chain.BlobSchedule,
 	sentryClients []sentryproto.SentryClient,
 	stateChangesClient StateChangesClient,
@@ -270,6 +273,13 @@ func New(
 		pragueTimeU64 := pragueTime.Uint64()
 		res.pragueTime = &pragueTimeU64
 	}
+	if osakaTime != nil {
+		if !osakaTime.IsUint64() {
+			return nil, errors.New("osakaTime overflow")
+		}
+		osakaTimeU64 := osakaTime.Uint64()
+		res.osakaTime = &osakaTimeU64
+	}
 
 	res.p2pFetcher = NewFetch(ctx, sentryClients, res, stateChangesClient, poolDB, chainID, logger, opts...)
 	res.p2pSender = NewSend(ctx, sentryClients, logger, opts...)
@@ -957,8 +967,12 @@ func (p *TxPool) validateTx(txn *TxnSlot, isLocal bool, stateCache kvcache.Cache
 			return txpoolcfg.TooManyBlobs
 		}
 		equalNumber := len(txn.BlobHashes) == len(txn.Blobs) &&
-			len(txn.Blobs) == len(txn.Commitments) &&
-			len(txn.Commitments) == len(txn.Proofs)
+			len(txn.Blobs) == len(txn.Commitments)
+		if txn.BlobWrapperVersion == BlobWrapperVersionCellProofs {
+			equalNumber = equalNumber && len(txn.CellProofs) == len(txn.Blobs)*libkzg.CellsPerExtBlob
+		} else {
+			equalNumber = equalNumber && len(txn.Commitments) == len(txn.Proofs)
+		}
 
 		if !equalNumber {
 			return txpoolcfg.UnequalBlobTxExt
@@ -970,11 +984,28 @@ func (p *TxPool) validateTx(txn *TxnSlot, isLocal bool, stateCache kvcache.Cache
 			}
 		}
 
-		// https://github.com/ethereum/consensus-specs/blob/017a8495f7671f5fff2075a9bfc9238c1a0982f8/specs/deneb/polynomial-commitments.md#verify_blob_kzg_proof_batch
-		kzgCtx := libkzg.Ctx()
-		err := kzgCtx.VerifyBlobKZGProofBatch(toBlobs(txn.Blobs), txn.Commitments, txn.Proofs)
-		if err != nil {
-			return txpoolcfg.UnmatchedBlobTxExt
+		// EIP-7594: after Osaka peers must gossip cell proofs, computing them for a legacy wrapper is too
+		// expensive to do for remote transactions. Cell proofs of local legacy wrappers are computed by
+		// AddLocalTxns.
+		if p.isOsaka() && txn.BlobWrapperVersion != BlobWrapperVersionCellProofs && len(txn.CellProofs) == 0 {
+			return txpoolcfg.UnsupportedBlobWrapper
+		}
+
+		if txn.BlobWrapperVersion == BlobWrapperVersionCellProofs {
+			if !p.isOsaka() {
+				return txpoolcfg.UnsupportedBlobWrapper
+			}
+			// https://github.com/ethereum/consensus-specs/blob/dev/specs/fulu/polynomial-commitments-sampling.md#verify_cell_kzg_proof_batch
+			if err := libkzg.VerifyCellProofs(txn.Blobs, txn.Commitments, txn.CellProofs); err != nil {
+				return txpoolcfg.UnmatchedBlobTxExt
+			}
+		} else {
+			// https://github.com/ethereum/consensus-specs/blob/017a8495f7671f5fff2075a9bfc9238c1a0982f8/specs/deneb/polynomial-commitments.md#verify_blob_kzg_proof_batch
+			kzgCtx := libkzg.Ctx()
+			err := kzgCtx.VerifyBlobKZGProofBatch(toBlobs(txn.Blobs), txn.Commitments, txn.Proofs)
+			if err != nil {
+				return txpoolcfg.UnmatchedBlobTxExt
+			}
 		}
 
 		if !isLocal && (p.all.blobCount(txn.SenderID)+uint64(len(txn.BlobHashes))) > p.cfg.BlobSlots {
@@ -1280,6 +1311,10 @@ func (p *TxPool) isPrague() bool {
 	return isTimeBasedForkActivated(&p.isPostPrague, p.pragueTime)
 }
 
+func (p *TxPool) isOsaka() bool {
+	return isTimeBasedForkActivated(&p.isPostOsaka, p.osakaTime)
+}
+
 func (p *TxPool) GetMaxBlobsPerBlock() uint64 {
 	return p.blobSchedule.MaxBlobsPerBlock(p.isPrague())
 }
@@ -1420,6 +1455,19 @@ func (p *TxPool) AddLocalTxns(ctx context.Context, newTxns TxnSlots) ([]txpoolcf
 		return nil, err
 	}
 
+	if p.isOsaka() {
+		// computed without holding the pool lock, stored with the blobs on flush
+		for _, txn := range newTxns.Txns {
+			if txn.Type != BlobTxnType || txn.BlobWrapperVersion == BlobWrapperVersionCellProofs || len(txn.CellProofs) > 0 {
+				continue
+			}
+			// a malformed blob fails validation, leaving the wrapper without cell proofs rejects it
+			if txn.CellProofs, err = computeCellProofs(txn.Blobs); err != nil {
+				p.logger.Debug("[txpool] computing cell proofs", "idHash", common.Hash(txn.IDHash), "err", err)
+			}
+		}
+	}
+
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
@@ -1763,11 +1811,15 @@ func (p *TxPool) discardLocked(mt *metaTxn, reason txpoolcfg.DiscardReason) {
 	}
 }
 
-func (p *TxPool) getBlobsAndProofByBlobHashLocked(blobHashes []common.Hash) ([][]byte, [][]byte) {
+// GetBlobs returns, for every requested versioned hash, the blob, its EIP-4844 proof and its
+// EIP-7594 cell proofs concatenated (CellsPerExtBlob * 48 bytes). Entries are nil when unknown,
+// cell proofs are nil for legacy wrappers admitted before Osaka.
+func (p *TxPool) GetBlobs(blobHashes []common.Hash) ([][]byte, [][]byte, [][]byte) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	blobs := make([][]byte, len(blobHashes))
 	proofs := make([][]byte, len(blobHashes))
+	cellProofs := make([][]byte, len(blobHashes))
 	for i, h := range blobHashes {
 		th, ok := p.blobHashToTxn[h]
 		if !ok {
@@ -1778,13 +1830,46 @@ func (p *TxPool) getBlobsAndProofByBlobHashLocked(blobHashes []common.Hash) ([][
 			continue
 		}
 		blobs[i] = mt.TxnSlot.Blobs[th.index]
-		proofs[i] = mt.TxnSlot.Proofs[th.index][:]
+		if len(mt.TxnSlot.Proofs) > th.index {
+			proofs[i] = mt.TxnSlot.Proofs[th.index][:]
+		}
+		if len(mt.TxnSlot.CellProofs) >= (th.index+1)*libkzg.CellsPerExtBlob {
+			cellProofs[i] = encodeCellProofs(mt.TxnSlot.CellProofs[th.index*libkzg.CellsPerExtBlob : (th.index+1)*libkzg.CellsPerExtBlob])
+		}
+	}
+	return blobs, proofs, cellProofs
+}
+
+// computeCellProofs computes the cell proofs of all blobs of a legacy blob wrapper.
+func computeCellProofs(blobs [][]byte) ([]gokzg4844.KZGProof, error) {
+	all := make([]gokzg4844.KZGProof, 0, len(blobs)*libkzg.CellsPerExtBlob)
+	for _, blob := range blobs {
+		cellProofs, err := libkzg.ComputeCellProofs(blob)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, cellProofs...)
+	}
+	return all, nil
+}
+
+func encodeCellProofs(proofs []gokzg4844.KZGProof) []byte {
+	buf := make([]byte, 0, len(proofs)*len(gokzg4844.KZGProof{}))
+	for _, proof := range proofs {
+		buf = append(buf, proof[:]...)
 	}
-	return blobs, proofs
+	return buf
 }
 
-func (p *TxPool) GetBlobs(blobHashes []common.Hash) ([][]byte, [][]byte) {
-	return p.getBlobsAndProofByBlobHashLocked(blobHashes)
+func decodeCellProofs(buf []byte) []gokzg4844.KZGProof {
+	if len(buf) == 0 {
+		return nil
+	}
+	proofs := make([]gokzg4844.KZGProof, len(buf)/len(gokzg4844.KZGProof{}))
+	for i := range proofs {
+		copy(proofs[i][:], buf[i*len(gokzg4844.KZGProof{}):])
+	}
+	return proofs
 }
 
 // Cache recently mined blobs in anticipation of reorg, delete finalized ones
@@ -2358,6 +2443,11 @@ func (p *TxPool) flushLocked(tx kv.RwTx) (err error) {
 				return err
 			}
 		}
+		if mt.TxnSlot.Type == BlobTxnType {
+			if err := tx.Delete(kv.PoolBlobCellProofs, idHash); err != nil {
+				return err
+			}
+		}
 		p.deletedTxns[i] = nil // for gc
 	}
 
@@ -2398,6 +2488,12 @@ func (p *TxPool) flushLocked(tx kv.RwTx) (err error) {
 				return err
 			}
 		}
+		// cell proofs of EIP-7594 wrappers are part of the rlp, computed ones are stored aside
+		if metaTx.TxnSlot.BlobWrapperVersion != BlobWrapperVersionCellProofs && len(metaTx.TxnSlot.CellProofs) > 0 {
+			if err := tx.Put(kv.PoolBlobCellProofs, []byte(txHash), encodeCellProofs(metaTx.TxnSlot.CellProofs)); err != nil {
+				return err
+			}
+		}
 		metaTx.TxnSlot.Rlp = nil
 	}
 
@@ -2487,11 +2583,23 @@ func (p *TxPool) fromDB(ctx context.Context, tx kv.Tx, coreTx kv.Tx) error {
 			continue
 		}
 		txn.Rlp = nil // means that we don't need store it in db anymore
+		if txn.Type == BlobTxnType && txn.BlobWrapperVersion != BlobWrapperVersionCellProofs {
+			v, err := tx.GetOne(kv.PoolBlobCellProofs, k)
+			if err != nil {
+				return err
+			}
+			txn.CellProofs = decodeCellProofs(v)
+		}
 
 		txn.SenderID, txn.Traced = p.senders.getOrCreateID(addr, p.logger)
 		isLocalTx := p.isLocalLRU.Contains(string(k))
 
-		if reason := p.validateTx(txn, isLocalTx, cacheView); reason != txpoolcfg.NotSet && reason != txpoolcfg.Success {
+		reason := p.validateTx(txn, isLocalTx, cacheView)
+		if reason == txpoolcfg.UnsupportedBlobWrapper {
+			// legacy blob wrapper without cell proofs admitted before Osaka, drop just this one
+			continue
+		}
+		if reason != txpoolcfg.NotSet && reason != txpoolcfg.Success {
 			return nil // TODO: Clarify - if one of the txns has the wrong reason, no pooled txns!
 		}
 		txns.Resize(uint(i + 1))
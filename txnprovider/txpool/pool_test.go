@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"testing"
 
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
 	"github.com/erigontech/erigon-lib/state"
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
@@ -59,7 +60,7 @@ func TestNonceFromAddress(t *testing.T) {
 	db := memdb.NewTestPoolDB(t)
 	cfg := txpoolcfg.DefaultConfig
 	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
-	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
 	require.NoError(err)
 	require.True(pool != nil)
 	var stateVersionID uint64 = 0
@@ -185,7 +186,7 @@ func TestMultipleAuthorizations(t *testing.T) {
 
 	cfg := txpoolcfg.DefaultConfig
 	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
-	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, common.Big0 /* shanghaiTime */, nil /* agraBlock */, common.Big0 /* cancunTime */, common.Big0 /* pragueTime */, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, common.Big0 /* shanghaiTime */, nil /* agraBlock */, common.Big0 /* cancunTime */, common.Big0 /* pragueTime */, nil /* osakaTime */, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
 	require.NoError(t, err)
 	require.True(t, pool != nil)
 
@@ -443,7 +444,7 @@ func TestReplaceWithHigherFee(t *testing.T) {
 	t.Cleanup(cancel)
 	cfg := txpoolcfg.DefaultConfig
 	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
-	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
 	require.NoError(err)
 	require.NotEqual(nil, pool)
 	var stateVersionID uint64 = 0
@@ -566,7 +567,7 @@ func TestReverseNonces(t *testing.T) {
 	t.Cleanup(cancel)
 	cfg := txpoolcfg.DefaultConfig
 	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
-	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
 	require.NoError(err)
 	require.True(pool != nil)
 	var stateVersionID uint64 = 0
@@ -696,7 +697,7 @@ func TestTxnPoke(t *testing.T) {
 	t.Cleanup(cancel)
 	cfg := txpoolcfg.DefaultConfig
 	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
-	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
 	require.NoError(err)
 	require.True(pool != nil)
 	var stateVersionID uint64 = 0
@@ -933,7 +934,7 @@ func TestShanghaiValidateTxn(t *testing.T) {
 			asrt.NoError(err)
 			defer sd.Close()
 			cache := kvcache.NewDummy()
-			pool, err := New(ctx, ch, nil, coreDB, cfg, cache, *u256.N1, shanghaiTime, nil /* agraBlock */, nil /* cancunTime */, nil, nil, nil, nil, func() {}, nil, logger, WithFeeCalculator(nil))
+			pool, err := New(ctx, ch, nil, coreDB, cfg, cache, *u256.N1, shanghaiTime, nil /* agraBlock */, nil /* cancunTime */, nil, nil, nil, nil, nil, func() {}, nil, logger, WithFeeCalculator(nil))
 			asrt.NoError(err)
 
 			sndr := accounts3.Account{Nonce: 0, Balance: *uint256.NewInt(math.MaxUint64)}
@@ -979,7 +980,7 @@ func TestTooHighGasLimitTxnValidation(t *testing.T) {
 	t.Cleanup(cancel)
 	cfg := txpoolcfg.DefaultConfig
 	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
-	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
 	require.NoError(err)
 	require.True(pool != nil)
 	var stateVersionID uint64 = 0
@@ -1045,7 +1046,7 @@ func TestSetCodeTxnValidationWithLargeAuthorizationValues(t *testing.T) {
 	cache := kvcache.NewDummy()
 	logger := log.New()
 	pool, err := New(ctx, ch, nil, coreDB, cfg, cache, chainID, common.Big0 /* shanghaiTime */, nil, /* agraBlock */
-		common.Big0 /* cancunTime */, common.Big0 /* pragueTime */, nil, nil, nil, func() {}, nil, logger, WithFeeCalculator(nil))
+		common.Big0 /* cancunTime */, common.Big0 /* pragueTime */, nil /* osakaTime */, nil, nil, nil, func() {}, nil, logger, WithFeeCalculator(nil))
 	require.NoError(t, err)
 	pool.blockGasLimit.Store(30_000_000)
 	tx, err := coreDB.BeginRw(ctx)
@@ -1098,7 +1099,7 @@ func TestBlobTxnReplacement(t *testing.T) {
 	t.Cleanup(cancel)
 	cfg := txpoolcfg.DefaultConfig
 	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
-	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, common.Big0, nil, common.Big0, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, common.Big0, nil, common.Big0, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
 	require.NoError(err)
 
 	require.True(pool != nil)
@@ -1282,7 +1283,7 @@ func TestDropRemoteAtNoGossip(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
-	txnPool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, func() {}, nil, logger, WithFeeCalculator(nil))
+	txnPool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, func() {}, nil, logger, WithFeeCalculator(nil))
 	require.NoError(err)
 	require.True(txnPool != nil)
 
@@ -1393,7 +1394,7 @@ func TestBlobSlots(t *testing.T) {
 	cfg.TotalBlobPoolLimit = 20
 
 	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
-	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, common.Big0, nil, common.Big0, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, common.Big0, nil, common.Big0, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
 	require.NoError(err)
 	require.True(pool != nil)
 	var stateVersionID uint64 = 0
@@ -1476,7 +1477,7 @@ func TestGetBlobsV1(t *testing.T) {
 	cfg.TotalBlobPoolLimit = 20
 
 	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
-	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, common.Big0, nil, common.Big0, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, common.Big0, nil, common.Big0, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
 	require.NoError(err)
 	require.True(pool != nil)
 	pool.blockGasLimit.Store(30000000)
@@ -1534,12 +1535,121 @@ func TestGetBlobsV1(t *testing.T) {
 	}
 	blobHashes = append(blobHashes, blobTxn.BlobHashes...)
 
-	blobs, proofs := pool.GetBlobs(blobHashes)
+	blobs, proofs, cellProofs := pool.GetBlobs(blobHashes)
 	require.True(len(blobs) == len(blobHashes))
 	require.True(len(proofs) == len(blobHashes))
 	assert.Equal(blobTxn.Blobs, blobs)
 	assert.Equal(blobTxn.Proofs[0][:], proofs[0])
 	assert.Equal(blobTxn.Proofs[1][:], proofs[1])
+	// no cell proofs before Osaka
+	assert.Equal([][]byte{nil, nil}, cellProofs)
+}
+
+func TestGetBlobsV2(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+	ch := make(chan Announcements, 5)
+	coreDB, _ := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	db := memdb.NewTestPoolDB(t)
+	cfg := txpoolcfg.DefaultConfig
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	//Setting limits for blobs in the pool
+	cfg.TotalBlobPoolLimit = 20
+
+	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, common.Big0, nil, common.Big0, common.Big0, common.Big0, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	require.NoError(err)
+	require.True(pool != nil)
+	pool.blockGasLimit.Store(30000000)
+	var stateVersionID uint64 = 0
+
+	h1 := gointerfaces.ConvertHashToH256([32]byte{})
+	change := &remote.StateChangeBatch{
+		StateVersionId:       stateVersionID,
+		PendingBlockBaseFee:  200_000,
+		BlockGasLimit:        math.MaxUint64,
+		PendingBlobFeePerGas: 100_000,
+		ChangeBatch: []*remote.StateChange{
+			{BlockHeight: 0, BlockHash: h1},
+		},
+	}
+	var addr [20]byte
+
+	// Add 1 eth to the user account, as a part of change
+	acc := accounts3.Account{
+		Nonce:       0,
+		Balance:     *uint256.NewInt(1 * common.Ether),
+		CodeHash:    common.Hash{},
+		Incarnation: 1,
+	}
+	v := accounts3.SerialiseV3(&acc)
+
+	for i := 0; i < 11; i++ {
+		addr[0] = uint8(i + 1)
+		change.ChangeBatch[0].Changes = append(change.ChangeBatch[0].Changes, &remote.AccountChange{
+			Action:  remote.Action_UPSERT,
+			Address: gointerfaces.ConvertAddressToH160(addr),
+			Data:    v,
+		})
+	}
+
+	tx, err := db.BeginRw(ctx)
+	require.NoError(err)
+	defer tx.Rollback()
+	err = pool.OnNewBlock(ctx, change, TxnSlots{}, TxnSlots{}, TxnSlots{})
+	require.NoError(err)
+	blobHashes := make([]common.Hash, 0, 20)
+
+	//Adding 2 blobs with 1 txn
+	txnSlots := TxnSlots{}
+	addr[0] = uint8(1)
+	blobTxn := makeBlobTxn() // makes a txn with 2 blobs
+	blobTxn.IDHash[0] = uint8(3)
+	blobTxn.Nonce = 0
+	blobTxn.Gas = 50000
+	txnSlots.Append(&blobTxn, addr[:], true)
+	reasons, err := pool.AddLocalTxns(ctx, txnSlots)
+	require.NoError(err)
+	for _, reason := range reasons {
+		assert.Equal(txpoolcfg.Success, reason, reason.String())
+	}
+	blobHashes = append(blobHashes, blobTxn.BlobHashes...)
+
+	// a remote legacy wrapper is rejected after Osaka
+	remoteTxn := makeBlobTxn()
+	remoteTxn.IDHash[0] = uint8(4)
+	remoteTxn.Nonce = 0
+	remoteTxn.Gas = 50000
+	coreTx, err := coreDB.BeginTemporalRo(ctx)
+	require.NoError(err)
+	defer coreTx.Rollback()
+	view, err := sendersCache.View(ctx, coreTx)
+	require.NoError(err)
+	reason := pool.validateTx(&remoteTxn, false /* isLocal */, view)
+	assert.Equal(txpoolcfg.UnsupportedBlobWrapper, reason, reason.String())
+
+	// cell proofs of a local legacy wrapper are computed when it's added
+	require.Len(pool.byHash[string(blobTxn.IDHash[:])].TxnSlot.CellProofs, len(blobHashes)*kzg.CellsPerExtBlob)
+	blobs, _, cellProofs := pool.GetBlobs(blobHashes)
+	require.True(len(cellProofs) == len(blobHashes))
+	assert.Equal(blobTxn.Blobs, blobs)
+	var flatProofs []gokzg4844.KZGProof
+	for _, buf := range cellProofs {
+		require.Len(buf, kzg.CellsPerExtBlob*48)
+		flatProofs = append(flatProofs, decodeCellProofs(buf)...)
+	}
+	require.NoError(kzg.VerifyCellProofs(blobs, blobTxn.Commitments, flatProofs))
+
+	// and persisted, so they survive a restart
+	tx.Rollback()
+	_, err = pool.flush(ctx)
+	require.NoError(err)
+	require.NoError(db.View(ctx, func(tx kv.Tx) error {
+		v, err := tx.GetOne(kv.PoolBlobCellProofs, blobTxn.IDHash[:])
+		require.NoError(err)
+		assert.Equal(append(cellProofs[0], cellProofs[1]...), v)
+		return nil
+	}))
 }
 
 func TestGasLimitChanged(t *testing.T) {
@@ -1551,7 +1661,7 @@ func TestGasLimitChanged(t *testing.T) {
 	db := memdb.NewTestPoolDB(t)
 	cfg := txpoolcfg.DefaultConfig
 	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
-	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
 	require.NoError(err)
 	require.True(pool != nil)
 	var stateVersionID uint64 = 0
@@ -1635,7 +1745,7 @@ func BenchmarkProcessRemoteTxns(b *testing.B) {
 	b.Cleanup(cancel)
 	cfg := txpoolcfg.DefaultConfig
 	sendersCache := kvcache.New(kvcache.DefaultCoherentConfig)
-	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
+	pool, err := New(ctx, ch, db, coreDB, cfg, sendersCache, *u256.N1, nil, nil, nil, nil, nil, nil, nil, nil, func() {}, nil, log.New(), WithFeeCalculator(nil))
 	require.NoError(err)
 	require.True(pool != nil)
 
@@ -47,6 +47,10 @@ const (
 	AATxnType         byte = 5 // RIP-7560
 )
 
+// BlobWrapperVersionCellProofs is the wrapper_version of EIP-7594 blob txn wrappers,
+// which carry cell proofs instead of one proof per blob
+const BlobWrapperVersionCellProofs = 1
+
 var ErrParseTxn = fmt.Errorf("%w transaction", rlp.ErrParse)
 var ErrRejected = errors.New("rejected")
 var ErrAlreadyKnown = errors.New("already known")
@@ -194,6 +198,23 @@ func (ctx *TxnParseContext) ParseTransaction(payload []byte, pos int, slot *TxnS
 			return 0, fmt.Errorf("%w: unexpected leftover after blob txn body", ErrParseTxn)
 		}
 
+		// EIP-7594 wrappers put a wrapper_version between the txn body and the blobs
+		_, _, isList, err := rlp.Prefix(payload, p)
+		if err != nil {
+			return 0, fmt.Errorf("%w: blobs wrapper: %s", ErrParseTxn, err) //nolint
+		}
+		if !isList {
+			var version uint64
+			p, version, err = rlp.ParseU64(payload, p)
+			if err != nil {
+				return 0, fmt.Errorf("%w: wrapper version: %s", ErrParseTxn, err) //nolint
+			}
+			if version != BlobWrapperVersionCellProofs {
+				return 0, fmt.Errorf("%w: unknown blobs wrapper version: %d", ErrParseTxn, version)
+			}
+			slot.BlobWrapperVersion = byte(version)
+		}
+
 		dataPos, dataLen, err = rlp.ParseList(payload, p)
 		if err != nil {
 			return 0, fmt.Errorf("%w: blobs len: %s", ErrParseTxn, err) //nolint
@@ -244,7 +265,11 @@ func (ctx *TxnParseContext) ParseTransaction(payload []byte, pos int, slot *TxnS
 			}
 			var proof gokzg4844.KZGProof
 			copy(proof[:], payload[proofPos:proofPos+48])
-			slot.Proofs = append(slot.Proofs, proof)
+			if slot.BlobWrapperVersion == BlobWrapperVersionCellProofs {
+				slot.CellProofs = append(slot.CellProofs, proof)
+			} else {
+				slot.Proofs = append(slot.Proofs, proof)
+			}
 			proofPos += 48
 		}
 		if proofPos != dataPos+dataLen {
@@ -816,6 +841,10 @@ type TxnSlot struct {
 	Commitments []gokzg4844.KZGCommitment
 	Proofs      []gokzg4844.KZGProof
 
+	// EIP-7594: PeerDAS
+	BlobWrapperVersion byte                 // 0 for EIP-4844 blob wrappers, BlobWrapperVersionCellProofs for wrappers carrying cell proofs
+	CellProofs         []gokzg4844.KZGProof // libkzg.CellsPerExtBlob proofs per blob, either received in the wrapper or computed when a local legacy wrapper is added
+
 	// EIP-7702: set code tx
 	Authorizations []Signature
 	AuthRaw        [][]byte // rlp encoded chainID+address+nonce, used to recover authorization address in txpool
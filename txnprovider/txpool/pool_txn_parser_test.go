@@ -295,6 +295,57 @@ func TestBlobTxnParsing(t *testing.T) {
 	assert.Equal(t, proof1, fatTxn.Proofs[1])
 }
 
+func TestCellProofsBlobTxnParsing(t *testing.T) {
+	bodyRlp := hexutil.MustDecodeHex("f9012705078502540be4008506fc23ac008357b58494811a752c8cd697e3cb27" +
+		"279c330ed1ada745a8d7808204f7f872f85994de0b295669a9fd93d5f28d9ec85e40f4cb697b" +
+		"aef842a00000000000000000000000000000000000000000000000000000000000000003a000" +
+		"00000000000000000000000000000000000000000000000000000000000007d694bb9bc244d7" +
+		"98123fde783fcc1c72d3bb8c189413c07bf842a0c6bdd1de713471bd6cfa62dd8b5a5b42969e" +
+		"d09e26212d3377f3f8426d8ec210a08aaeccaf3873d07cef005aca28c39f8a9f8bdb1ec8d79f" +
+		"fc25afc0a4fa2ab73601a036b241b061a36a32ab7fe86c7aa9eb592dd59018cd0443adc09035" +
+		"90c16b02b0a05edcc541b4741c5cc6dd347c5ed9577ef293a62787b4510465fadbfe39ee4094")
+
+	blobs := make([][]byte, 2)
+	commitments := make([][]byte, 2)
+	for i := range blobs {
+		blobs[i] = make([]byte, fixedgas.BlobSize)
+		rand.Read(blobs[i])
+		commitments[i] = make([]byte, 48)
+		rand.Read(commitments[i])
+	}
+	cellProofs := make([][]byte, 2*128)
+	for i := range cellProofs {
+		cellProofs[i] = make([]byte, 48)
+		rand.Read(cellProofs[i])
+	}
+
+	makeWrapper := func(version uint64) []byte {
+		wrapper, err := rlp.EncodeToBytes([]any{rlp.RawValue(bodyRlp), version, blobs, commitments, cellProofs})
+		require.NoError(t, err)
+		return append([]byte{BlobTxnType}, wrapper...)
+	}
+
+	ctx := NewTxnParseContext(*uint256.NewInt(5))
+	ctx.withSender = false
+
+	wrapperRlp := makeWrapper(BlobWrapperVersionCellProofs)
+	var txn TxnSlot
+	p, err := ctx.ParseTransaction(wrapperRlp, 0, &txn, nil, false /* hasEnvelope */, true /* wrappedWithBlobs */, nil)
+	require.NoError(t, err)
+	assert.Equal(t, len(wrapperRlp), p)
+	assert.Equal(t, wrapperRlp, txn.Rlp)
+	assert.Equal(t, byte(BlobWrapperVersionCellProofs), txn.BlobWrapperVersion)
+	require.Equal(t, 2, len(txn.Blobs))
+	require.Equal(t, 2, len(txn.Commitments))
+	require.Equal(t, 0, len(txn.Proofs))
+	require.Equal(t, len(cellProofs), len(txn.CellProofs))
+	assert.Equal(t, blobs[1], txn.Blobs[1])
+	assert.Equal(t, cellProofs[255], txn.CellProofs[255][:])
+
+	_, err = ctx.ParseTransaction(makeWrapper(2), 0, &TxnSlot{}, nil, false /* hasEnvelope */, true /* wrappedWithBlobs */, nil)
+	require.ErrorIs(t, err, ErrParseTxn)
+}
+
 func TestSetCodeAuthRawParsing(t *testing.T) {
 	// generated using this in core/types/encdec_test.go
 	/*
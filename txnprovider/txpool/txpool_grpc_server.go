@@ -59,7 +59,7 @@ type txPool interface {
 	CountContent() (int, int, int)
 	IdHashKnown(tx kv.Tx, hash []byte) (bool, error)
 	NonceFromAddress(addr [20]byte) (nonce uint64, inPool bool)
-	GetBlobs(blobhashes []common.Hash) (blobs [][]byte, proofs [][]byte)
+	GetBlobs(blobhashes []common.Hash) (blobs [][]byte, proofs [][]byte, cellProofs [][]byte)
 }
 
 var _ txpool_proto.TxpoolServer = (*GrpcServer)(nil)   // compile-time interface check
@@ -238,8 +238,8 @@ func (s *GrpcServer) GetBlobs(ctx context.Context, in *txpool_proto.GetBlobsRequ
 	for i := range in.BlobHashes {
 		hashes[i] = gointerfaces.ConvertH256ToHash(in.BlobHashes[i])
 	}
-	blobs, proofs := s.txPool.GetBlobs(hashes)
-	reply := &txpool_proto.GetBlobsReply{Blobs: blobs, Proofs: proofs}
+	blobs, proofs, cellProofs := s.txPool.GetBlobs(hashes)
+	reply := &txpool_proto.GetBlobsReply{Blobs: blobs, Proofs: proofs, CellProofs: cellProofs}
 	return reply, nil
 }
 
@@ -86,43 +86,44 @@ var DefaultConfig = Config{
 type DiscardReason uint8
 
 const (
-	NotSet               DiscardReason = 0 // analog of "nil-value", means it will be set in future
-	Success              DiscardReason = 1
-	AlreadyKnown         DiscardReason = 2
-	Mined                DiscardReason = 3
-	ReplacedByHigherTip  DiscardReason = 4
-	UnderPriced          DiscardReason = 5
-	ReplaceUnderpriced   DiscardReason = 6 // if a transaction is attempted to be replaced with a different one without the required price bump.
-	FeeTooLow            DiscardReason = 7
-	OversizedData        DiscardReason = 8
-	InvalidSender        DiscardReason = 9
-	NegativeValue        DiscardReason = 10 // ensure no one is able to specify a transaction with a negative value.
-	Spammer              DiscardReason = 11
-	PendingPoolOverflow  DiscardReason = 12
-	BaseFeePoolOverflow  DiscardReason = 13
-	QueuedPoolOverflow   DiscardReason = 14
-	GasUintOverflow      DiscardReason = 15
-	IntrinsicGas         DiscardReason = 16
-	RLPTooLong           DiscardReason = 17
-	NonceTooLow          DiscardReason = 18
-	InsufficientFunds    DiscardReason = 19
-	NotReplaced          DiscardReason = 20 // There was an existing transaction with the same sender and nonce, not enough price bump to replace
-	DuplicateHash        DiscardReason = 21 // There was an existing transaction with the same hash
-	InitCodeTooLarge     DiscardReason = 22 // EIP-3860 - transaction init code is too large
-	TypeNotActivated     DiscardReason = 23 // For example, an EIP-4844 transaction is submitted before Cancun activation
-	InvalidCreateTxn     DiscardReason = 24 // EIP-4844 & 7702 transactions cannot have the form of a create transaction
-	NoBlobs              DiscardReason = 25 // Blob transactions must have at least one blob
-	TooManyBlobs         DiscardReason = 26 // There's a limit on how many blobs a block (and thus any transaction) may have
-	UnequalBlobTxExt     DiscardReason = 27 // blob_versioned_hashes, blobs, commitments and proofs must have equal number
-	BlobHashCheckFail    DiscardReason = 28 // KZGcommitment's versioned hash has to be equal to blob_versioned_hash at the same index
-	UnmatchedBlobTxExt   DiscardReason = 29 // KZGcommitments must match the corresponding blobs and proofs
-	BlobTxReplace        DiscardReason = 30 // Cannot replace type-3 blob txn with another type of txn
-	BlobPoolOverflow     DiscardReason = 31 // The total number of blobs (through blob txns) in the pool has reached its limit
-	NoAuthorizations     DiscardReason = 32 // EIP-7702 transactions with an empty authorization list are invalid
-	GasLimitTooHigh      DiscardReason = 33 // Gas limit is too high
-	ErrAuthorityReserved DiscardReason = 34 // EIP-7702 transaction with authority already reserved
-	InvalidAA            DiscardReason = 35 // Invalid RIP-7560 transaction
-	ErrGetCode           DiscardReason = 36 // Error getting code during AA validation
+	NotSet                 DiscardReason = 0 // analog of "nil-value", means it will be set in future
+	Success                DiscardReason = 1
+	AlreadyKnown           DiscardReason = 2
+	Mined                  DiscardReason = 3
+	ReplacedByHigherTip    DiscardReason = 4
+	UnderPriced            DiscardReason = 5
+	ReplaceUnderpriced     DiscardReason = 6 // if a transaction is attempted to be replaced with a different one without the required price bump.
+	FeeTooLow              DiscardReason = 7
+	OversizedData          DiscardReason = 8
+	InvalidSender          DiscardReason = 9
+	NegativeValue          DiscardReason = 10 // ensure no one is able to specify a transaction with a negative value.
+	Spammer                DiscardReason = 11
+	PendingPoolOverflow    DiscardReason = 12
+	BaseFeePoolOverflow    DiscardReason = 13
+	QueuedPoolOverflow     DiscardReason = 14
+	GasUintOverflow        DiscardReason = 15
+	IntrinsicGas           DiscardReason = 16
+	RLPTooLong             DiscardReason = 17
+	NonceTooLow            DiscardReason = 18
+	InsufficientFunds      DiscardReason = 19
+	NotReplaced            DiscardReason = 20 // There was an existing transaction with the same sender and nonce, not enough price bump to replace
+	DuplicateHash          DiscardReason = 21 // There was an existing transaction with the same hash
+	InitCodeTooLarge       DiscardReason = 22 // EIP-3860 - transaction init code is too large
+	TypeNotActivated       DiscardReason = 23 // For example, an EIP-4844 transaction is submitted before Cancun activation
+	InvalidCreateTxn       DiscardReason = 24 // EIP-4844 & 7702 transactions cannot have the form of a create transaction
+	NoBlobs                DiscardReason = 25 // Blob transactions must have at least one blob
+	TooManyBlobs           DiscardReason = 26 // There's a limit on how many blobs a block (and thus any transaction) may have
+	UnequalBlobTxExt       DiscardReason = 27 // blob_versioned_hashes, blobs, commitments and proofs must have equal number
+	BlobHashCheckFail      DiscardReason = 28 // KZGcommitment's versioned hash has to be equal to blob_versioned_hash at the same index
+	UnmatchedBlobTxExt     DiscardReason = 29 // KZGcommitments must match the corresponding blobs and proofs
+	BlobTxReplace          DiscardReason = 30 // Cannot replace type-3 blob txn with another type of txn
+	BlobPoolOverflow       DiscardReason = 31 // The total number of blobs (through blob txns) in the pool has reached its limit
+	NoAuthorizations       DiscardReason = 32 // EIP-7702 transactions with an empty authorization list are invalid
+	GasLimitTooHigh        DiscardReason = 33 // Gas limit is too high
+	ErrAuthorityReserved   DiscardReason = 34 // EIP-7702 transaction with authority already reserved
+	InvalidAA              DiscardReason = 35 // Invalid RIP-7560 transaction
+	ErrGetCode             DiscardReason = 36 // Error getting code during AA validation
+	UnsupportedBlobWrapper DiscardReason = 37 // Blob wrapper version doesn't match the fork: cell proofs before Osaka, legacy proofs from peers after
 )
 
 func (r DiscardReason) String() string {
@@ -201,6 +202,8 @@ func (r DiscardReason) String() string {
 		return "RIP-7560 transaction failed validation"
 	case ErrGetCode:
 		return "error getting account code during RIP-7560 validation"
+	case UnsupportedBlobWrapper:
+		return "blob wrapper version is not supported by the current fork"
 	default:
 		panic(fmt.Sprintf("discard reason: %d", r))
 	}